@@ -0,0 +1,28 @@
+package debug
+
+import (
+	"context"
+
+	ptypes "github.com/gogo/protobuf/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MaintenanceScheduler defines a service capable of putting the beacon node
+// into maintenance mode at a slot-aware point in time.
+type MaintenanceScheduler interface {
+	ScheduleMaintenance() (uint64, error)
+}
+
+// EnterMaintenanceMode stops the beacon node from accepting new validator client
+// connections and, once the current epoch has been served, checkpoints the database
+// so operators can restart the node with minimal impact on attached validators.
+func (ds *Server) EnterMaintenanceMode(ctx context.Context, _ *ptypes.Empty) (*ptypes.Empty, error) {
+	if ds.MaintenanceScheduler == nil {
+		return nil, status.Error(codes.Unimplemented, "Maintenance mode is not supported by this beacon node")
+	}
+	if _, err := ds.MaintenanceScheduler.ScheduleMaintenance(); err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "Could not schedule maintenance: %v", err)
+	}
+	return &ptypes.Empty{}, nil
+}
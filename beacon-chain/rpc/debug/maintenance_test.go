@@ -0,0 +1,73 @@
+package debug
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	ptypes "github.com/gogo/protobuf/types"
+	pbrpc "github.com/prysmaticlabs/prysm/proto/beacon/rpc/v1"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type mockMaintenanceScheduler struct {
+	calls int
+	err   error
+}
+
+func (m *mockMaintenanceScheduler) ScheduleMaintenance() (uint64, error) {
+	m.calls++
+	return 0, m.err
+}
+
+// Serves the debug server over gRPC and returns a client of the generated Debug service.
+func debugClient(t *testing.T, ds *Server) pbrpc.DebugClient {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	pbrpc.RegisterDebugServer(server, ds)
+	go func() {
+		if err := server.Serve(lis); err != nil {
+			t.Log(err)
+		}
+	}()
+	t.Cleanup(server.Stop)
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := conn.Close(); err != nil {
+			t.Log(err)
+		}
+	})
+	return pbrpc.NewDebugClient(conn)
+}
+
+func TestDebugServer_EnterMaintenanceMode(t *testing.T) {
+	scheduler := &mockMaintenanceScheduler{}
+	client := debugClient(t, &Server{MaintenanceScheduler: scheduler})
+
+	_, err := client.EnterMaintenanceMode(context.Background(), &ptypes.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, scheduler.calls, "Expected maintenance to be scheduled once")
+}
+
+func TestDebugServer_EnterMaintenanceMode_ScheduleFails(t *testing.T) {
+	scheduler := &mockMaintenanceScheduler{err: errors.New("already in maintenance")}
+	client := debugClient(t, &Server{MaintenanceScheduler: scheduler})
+
+	_, err := client.EnterMaintenanceMode(context.Background(), &ptypes.Empty{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.ErrorContains(t, "already in maintenance", err)
+}
+
+func TestDebugServer_EnterMaintenanceMode_NotSupported(t *testing.T) {
+	client := debugClient(t, &Server{})
+
+	_, err := client.EnterMaintenanceMode(context.Background(), &ptypes.Empty{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
@@ -24,12 +24,13 @@ import (
 // providing RPC endpoints for runtime debugging of a node, this server is
 // gated behind the feature flag --enable-debug-rpc-endpoints.
 type Server struct {
-	BeaconDB           db.NoHeadAccessDatabase
-	GenesisTimeFetcher blockchain.TimeFetcher
-	StateGen           *stategen.State
-	HeadFetcher        blockchain.HeadFetcher
-	PeerManager        p2p.PeerManager
-	PeersFetcher       p2p.PeersProvider
+	BeaconDB             db.NoHeadAccessDatabase
+	GenesisTimeFetcher   blockchain.TimeFetcher
+	StateGen             *stategen.State
+	HeadFetcher          blockchain.HeadFetcher
+	PeerManager          p2p.PeerManager
+	PeersFetcher         p2p.PeersProvider
+	MaintenanceScheduler MaintenanceScheduler
 }
 
 // SetLoggingLevel of a beacon node according to a request type,
@@ -0,0 +1,82 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/beacon-chain/core/helpers"
+	"github.com/prysmaticlabs/prysm/shared/slotutil"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Debug endpoints remain reachable by new clients during maintenance
+// so operators can keep inspecting the node.
+const debugServicePrefix = "/ethereum.beacon.rpc.v1.Debug/"
+
+var errMaintenanceScheduled = errors.New("maintenance mode has already been scheduled")
+
+// ScheduleMaintenance puts the beacon node into maintenance mode. New validator
+// client connections are rejected immediately, while clients already attached are
+// served until the end of the current epoch, at which point the database is checkpointed
+// and the node is safe to restart. It returns the slot at which maintenance begins.
+func (s *Service) ScheduleMaintenance() (uint64, error) {
+	s.clientConnectionLock.Lock()
+	defer s.clientConnectionLock.Unlock()
+	if s.maintenanceScheduled {
+		return 0, errMaintenanceScheduled
+	}
+	s.maintenanceScheduled = true
+	currentSlot := s.genesisTimeFetcher.CurrentSlot()
+	maintenanceSlot := helpers.StartSlot(helpers.SlotToEpoch(currentSlot) + 1)
+	log.WithFields(logrus.Fields{
+		"currentSlot":     currentSlot,
+		"maintenanceSlot": maintenanceSlot,
+	}).Info("Scheduled maintenance mode, no longer accepting new validator client connections")
+	go s.awaitMaintenance(s.ctx, maintenanceSlot)
+	return maintenanceSlot, nil
+}
+
+func (s *Service) awaitMaintenance(ctx context.Context, maintenanceSlot uint64) {
+	genesis := uint64(s.genesisTimeFetcher.GenesisTime().Unix())
+	timer := time.NewTimer(time.Until(slotutil.SlotStartTime(genesis, maintenanceSlot)))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		log.Debug("Context closed, exiting maintenance routine")
+		return
+	case <-timer.C:
+	}
+	if err := s.beaconDB.Backup(ctx); err != nil {
+		log.WithError(err).Error("Could not checkpoint database for maintenance")
+		return
+	}
+	s.clientConnectionLock.Lock()
+	s.maintenanceReady = true
+	s.clientConnectionLock.Unlock()
+	log.WithField("slot", maintenanceSlot).Info(
+		"Served the final epoch and checkpointed the database, the beacon node is safe to restart",
+	)
+}
+
+// Rejects requests from validator clients which were not yet connected
+// to the beacon node before maintenance mode was scheduled.
+func (s *Service) checkMaintenance(ctx context.Context, fullMethod string) error {
+	if strings.HasPrefix(fullMethod, debugServicePrefix) {
+		return nil
+	}
+	s.clientConnectionLock.Lock()
+	defer s.clientConnectionLock.Unlock()
+	if !s.maintenanceScheduled {
+		return nil
+	}
+	clientInfo, ok := peer.FromContext(ctx)
+	if s.maintenanceReady || !ok || !s.connectedRPCClients[clientInfo.Addr] {
+		return status.Error(codes.Unavailable, "Beacon node is in maintenance mode and not accepting new connections")
+	}
+	return nil
+}
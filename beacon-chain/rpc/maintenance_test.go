@@ -0,0 +1,63 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	mock "github.com/prysmaticlabs/prysm/beacon-chain/blockchain/testing"
+	"github.com/prysmaticlabs/prysm/beacon-chain/core/helpers"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+func TestScheduleMaintenance_NextEpochBoundary(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	chainService := &mock.ChainService{Genesis: time.Now()}
+	s := &Service{
+		ctx:                 ctx,
+		genesisTimeFetcher:  chainService,
+		connectedRPCClients: make(map[net.Addr]bool),
+	}
+	slot, err := s.ScheduleMaintenance()
+	require.NoError(t, err)
+	assert.Equal(t, helpers.StartSlot(1), slot)
+
+	_, err = s.ScheduleMaintenance()
+	assert.ErrorContains(t, errMaintenanceScheduled.Error(), err)
+}
+
+func TestValidatorUnaryConnectionInterceptor_Maintenance(t *testing.T) {
+	s := &Service{
+		connectedRPCClients:  make(map[net.Addr]bool),
+		maintenanceScheduled: true,
+	}
+	knownAddr := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 4000}
+	newAddr := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 4001}
+	s.connectedRPCClients[knownAddr] = true
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return req, nil
+	}
+	validatorInfo := &grpc.UnaryServerInfo{FullMethod: "/ethereum.eth.v1alpha1.BeaconNodeValidator/GetDuties"}
+	debugInfo := &grpc.UnaryServerInfo{FullMethod: "/ethereum.beacon.rpc.v1.Debug/GetPeer"}
+
+	knownCtx := peer.NewContext(context.Background(), &peer.Peer{Addr: knownAddr})
+	_, err := s.validatorUnaryConnectionInterceptor(knownCtx, nil, validatorInfo, handler)
+	require.NoError(t, err)
+
+	newCtx := peer.NewContext(context.Background(), &peer.Peer{Addr: newAddr})
+	_, err = s.validatorUnaryConnectionInterceptor(newCtx, nil, validatorInfo, handler)
+	assert.ErrorContains(t, "maintenance mode", err)
+	_, err = s.validatorUnaryConnectionInterceptor(newCtx, nil, debugInfo, handler)
+	require.NoError(t, err)
+
+	// Once the database has been checkpointed, no further validator requests are served.
+	s.maintenanceReady = true
+	_, err = s.validatorUnaryConnectionInterceptor(knownCtx, nil, validatorInfo, handler)
+	assert.ErrorContains(t, "maintenance mode", err)
+}
@@ -98,6 +98,8 @@ type Service struct {
 	stateGen                *stategen.State
 	connectedRPCClients     map[net.Addr]bool
 	clientConnectionLock    sync.Mutex
+	maintenanceScheduled    bool
+	maintenanceReady        bool
 }
 
 // Config options for the beacon node RPC server.
@@ -285,11 +287,12 @@ func (s *Service) Start() {
 	if s.enableDebugRPCEndpoints {
 		log.Info("Enabled debug RPC endpoints")
 		debugServer := &debug.Server{
-			GenesisTimeFetcher: s.genesisTimeFetcher,
-			StateGen:           s.stateGen,
-			HeadFetcher:        s.headFetcher,
-			PeerManager:        s.peerManager,
-			PeersFetcher:       s.peersFetcher,
+			GenesisTimeFetcher:   s.genesisTimeFetcher,
+			StateGen:             s.stateGen,
+			HeadFetcher:          s.headFetcher,
+			PeerManager:          s.peerManager,
+			PeersFetcher:         s.peersFetcher,
+			MaintenanceScheduler: s,
 		}
 		pbrpc.RegisterDebugServer(s.grpcServer, debugServer)
 	}
@@ -375,9 +378,12 @@ func (s *Service) Status() error {
 func (s *Service) validatorStreamConnectionInterceptor(
 	srv interface{},
 	ss grpc.ServerStream,
-	_ *grpc.StreamServerInfo,
+	info *grpc.StreamServerInfo,
 	handler grpc.StreamHandler,
 ) error {
+	if err := s.checkMaintenance(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
 	s.logNewClientConnection(ss.Context())
 	return handler(srv, ss)
 }
@@ -386,26 +392,29 @@ func (s *Service) validatorStreamConnectionInterceptor(
 func (s *Service) validatorUnaryConnectionInterceptor(
 	ctx context.Context,
 	req interface{},
-	_ *grpc.UnaryServerInfo,
+	info *grpc.UnaryServerInfo,
 	handler grpc.UnaryHandler,
 ) (interface{}, error) {
+	if err := s.checkMaintenance(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
 	s.logNewClientConnection(ctx)
 	return handler(ctx, req)
 }
 
 func (s *Service) logNewClientConnection(ctx context.Context) {
-	if featureconfig.Get().DisableGRPCConnectionLogs {
-		return
-	}
 	if clientInfo, ok := peer.FromContext(ctx); ok {
 		// Check if we have not yet observed this grpc client connection
-		// in the running beacon node.
+		// in the running beacon node. Connections are tracked even if logs
+		// are disabled, as maintenance mode relies on knowing attached clients.
 		s.clientConnectionLock.Lock()
 		defer s.clientConnectionLock.Unlock()
 		if !s.connectedRPCClients[clientInfo.Addr] {
-			log.WithFields(logrus.Fields{
-				"addr": clientInfo.Addr.String(),
-			}).Infof("New gRPC client connected to beacon node")
+			if !featureconfig.Get().DisableGRPCConnectionLogs {
+				log.WithFields(logrus.Fields{
+					"addr": clientInfo.Addr.String(),
+				}).Infof("New gRPC client connected to beacon node")
+			}
 			s.connectedRPCClients[clientInfo.Addr] = true
 		}
 	}
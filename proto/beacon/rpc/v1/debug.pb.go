@@ -723,79 +723,79 @@ func init() {
 func init() { proto.RegisterFile("proto/beacon/rpc/v1/debug.proto", fileDescriptor_851e5cb2de3d61dd) }
 
 var fileDescriptor_851e5cb2de3d61dd = []byte{
-	// 1150 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x56, 0xcd, 0x6e, 0x1c, 0x45,
-	0x10, 0xce, 0xd8, 0xbb, 0xb6, 0xa7, 0x76, 0x59, 0x3b, 0x9d, 0xc8, 0x59, 0x36, 0x89, 0xe3, 0x4c,
-	0xa2, 0xfc, 0x21, 0x66, 0xe4, 0x85, 0x03, 0x8a, 0x90, 0x90, 0xff, 0xe2, 0x58, 0x32, 0x49, 0x18,
-	0x27, 0x1c, 0x88, 0xd0, 0xa8, 0x3d, 0x53, 0xbb, 0x3b, 0x78, 0xdc, 0x3d, 0xe9, 0xee, 0x31, 0x38,
-	0xdc, 0x22, 0x44, 0x8e, 0x1c, 0x78, 0x19, 0x1e, 0x81, 0x23, 0x12, 0x2f, 0x80, 0x2c, 0x9e, 0x82,
-	0x13, 0xea, 0xee, 0x9d, 0xfd, 0x91, 0x77, 0x83, 0x41, 0xdc, 0xba, 0xbe, 0xaa, 0xfa, 0xaa, 0xbb,
-	0xaa, 0xba, 0xab, 0xe1, 0x46, 0x2e, 0xb8, 0xe2, 0xc1, 0x01, 0xd2, 0x98, 0xb3, 0x40, 0xe4, 0x71,
-	0x70, 0xbc, 0x16, 0x24, 0x78, 0x50, 0x74, 0x7d, 0xa3, 0x21, 0xcb, 0xa8, 0x7a, 0x28, 0xb0, 0x38,
-	0xf2, 0xad, 0x8d, 0x2f, 0xf2, 0xd8, 0x3f, 0x5e, 0x6b, 0x5d, 0x41, 0xd5, 0x0b, 0x8e, 0xd7, 0x68,
-	0x96, 0xf7, 0xe8, 0x5a, 0xc0, 0x78, 0x82, 0xd6, 0xa1, 0xe5, 0x8d, 0x31, 0xe6, 0xed, 0x5c, 0x33,
-	0x1e, 0xa1, 0x94, 0xb4, 0x8b, 0xb2, 0x6f, 0x73, 0xad, 0xcb, 0x79, 0x37, 0xc3, 0x80, 0xe6, 0x69,
-	0x40, 0x19, 0xe3, 0x8a, 0xaa, 0x94, 0xb3, 0x52, 0x7b, 0xb5, 0xaf, 0x35, 0xd2, 0x41, 0xd1, 0x09,
-	0xf0, 0x28, 0x57, 0x27, 0x56, 0xe9, 0xbd, 0x04, 0xb2, 0x61, 0xa8, 0xf7, 0x15, 0x55, 0x18, 0xe2,
-	0xab, 0x02, 0xa5, 0x22, 0x97, 0xa1, 0x22, 0x33, 0xae, 0x9a, 0xce, 0xaa, 0x73, 0xaf, 0xf2, 0xf8,
-	0x42, 0x68, 0x24, 0x72, 0x03, 0xe0, 0x20, 0xe3, 0xf1, 0x61, 0x24, 0x38, 0x57, 0xcd, 0x99, 0x55,
-	0xe7, 0x5e, 0xfd, 0xf1, 0x85, 0xd0, 0x35, 0x58, 0xc8, 0xb9, 0xda, 0x68, 0x40, 0xfd, 0x55, 0x81,
-	0xe2, 0x24, 0xea, 0xa4, 0x99, 0x42, 0xe1, 0x7d, 0x08, 0xf5, 0x0d, 0xa3, 0xec, 0xd3, 0x5e, 0x1f,
-	0x23, 0xd0, 0xe4, 0xf5, 0x11, 0x77, 0xef, 0x2e, 0xd4, 0xf6, 0xf7, 0xbf, 0x0a, 0x51, 0xe6, 0x9c,
-	0x49, 0x24, 0x4d, 0x98, 0x47, 0x16, 0xf3, 0x04, 0x93, 0xbe, 0x69, 0x29, 0x7a, 0x6f, 0x1d, 0xb8,
-	0xb4, 0xc7, 0xbb, 0xdd, 0x94, 0x75, 0xf7, 0xf0, 0x18, 0xb3, 0x92, 0x7f, 0x07, 0xaa, 0x99, 0x96,
-	0x8d, 0x7d, 0xa3, 0xbd, 0xe6, 0x4f, 0x4e, 0xb6, 0x3f, 0xc1, 0xd7, 0xb7, 0x82, 0xf5, 0xf7, 0xee,
-	0x42, 0xd5, 0xc8, 0x64, 0x01, 0x2a, 0xbb, 0x4f, 0x1e, 0x3d, 0x5d, 0xba, 0x40, 0x5c, 0xa8, 0x6e,
-	0x6d, 0x6f, 0xbc, 0xd8, 0x59, 0x72, 0xf4, 0xf2, 0x79, 0xb8, 0xbe, 0xb9, 0xbd, 0x34, 0xe3, 0xfd,
-	0x38, 0x0b, 0xd7, 0x9e, 0xe9, 0x44, 0xae, 0x0b, 0x41, 0x4f, 0x1e, 0x71, 0x71, 0xb8, 0xd9, 0xe3,
-	0x69, 0x8c, 0x83, 0x43, 0xdc, 0x85, 0xc5, 0x5c, 0x14, 0x0c, 0x23, 0xd5, 0x13, 0x28, 0x7b, 0x3c,
-	0xb3, 0x87, 0xa9, 0x84, 0x0d, 0x03, 0x3f, 0x2f, 0x51, 0x6d, 0xf8, 0x4d, 0x21, 0x55, 0xda, 0x49,
-	0x31, 0x89, 0x30, 0xe7, 0x71, 0xcf, 0x64, 0xb8, 0x12, 0x36, 0x06, 0xf0, 0xb6, 0x46, 0xb5, 0x61,
-	0x27, 0x65, 0x34, 0x4b, 0x5f, 0x0f, 0x0c, 0x67, 0xad, 0xe1, 0x00, 0xb6, 0x86, 0x21, 0x5c, 0x34,
-	0x35, 0x8e, 0xa8, 0xde, 0x5b, 0xa4, 0x7b, 0x4a, 0x36, 0x2b, 0xab, 0xb3, 0xf7, 0x6a, 0xed, 0x3b,
-	0xd3, 0x32, 0x33, 0x3c, 0xcb, 0x13, 0x9e, 0x60, 0xb8, 0x98, 0x8f, 0xc9, 0x92, 0xbc, 0x84, 0xf9,
-	0x94, 0x25, 0x69, 0x8c, 0xb2, 0x59, 0x35, 0x4c, 0xeb, 0xff, 0xcc, 0x74, 0x36, 0x2b, 0xfe, 0xae,
-	0xe5, 0xd8, 0x66, 0x4a, 0x9c, 0x84, 0x25, 0x63, 0xeb, 0x21, 0xd4, 0x47, 0x15, 0x64, 0x09, 0x66,
-	0x0f, 0xf1, 0xc4, 0xe4, 0xcb, 0x0d, 0xf5, 0x92, 0x5c, 0x86, 0xea, 0x31, 0xcd, 0x0a, 0xec, 0xa7,
-	0xc6, 0x0a, 0x0f, 0x67, 0x3e, 0x71, 0xbc, 0x37, 0x33, 0xd0, 0x18, 0xdf, 0x3c, 0x21, 0xa3, 0x4d,
-	0xdc, 0x6f, 0x61, 0x02, 0x95, 0x61, 0xf3, 0x86, 0x66, 0x4d, 0x96, 0x61, 0x2e, 0xa7, 0x02, 0x99,
-	0xea, 0xe7, 0xb1, 0x2f, 0x4d, 0xaa, 0x48, 0xe5, 0xbc, 0x15, 0xa9, 0x4e, 0xac, 0xc8, 0x32, 0xcc,
-	0x7d, 0x8b, 0x69, 0xb7, 0xa7, 0x9a, 0x73, 0x36, 0x92, 0x95, 0xcc, 0xbd, 0x40, 0xa9, 0xa2, 0xb8,
-	0x97, 0x66, 0x49, 0x73, 0xde, 0xe8, 0x5c, 0x8d, 0x6c, 0x6a, 0x40, 0xf3, 0x1b, 0x75, 0x82, 0x32,
-	0x46, 0x96, 0x50, 0xa6, 0x9a, 0x0b, 0x96, 0x5f, 0xc3, 0x5b, 0x03, 0xd4, 0xfb, 0x1a, 0xc8, 0x96,
-	0x7e, 0x6b, 0x9e, 0x21, 0x8a, 0x32, 0xd7, 0x92, 0xec, 0x80, 0x2b, 0x4a, 0xa1, 0xe9, 0x98, 0xaa,
-	0xdd, 0x9f, 0x56, 0xb5, 0x33, 0xee, 0xe1, 0xd0, 0xd7, 0xfb, 0xa5, 0x0a, 0x17, 0xcf, 0x18, 0x90,
-	0x00, 0x2e, 0x65, 0xa9, 0x54, 0xc8, 0x52, 0xd6, 0x8d, 0x68, 0x92, 0x08, 0x94, 0x65, 0x20, 0x37,
-	0x24, 0x03, 0xd5, 0x7a, 0xa9, 0x21, 0x1b, 0xe0, 0x26, 0xa9, 0xc0, 0x58, 0xbf, 0x51, 0xa6, 0x10,
-	0x8d, 0xf6, 0xed, 0xe1, 0x7e, 0x50, 0xf5, 0xfc, 0xf2, 0x1d, 0xf4, 0x75, 0xa0, 0xad, 0xd2, 0x36,
-	0x1c, 0xba, 0x91, 0x2f, 0x60, 0x29, 0xe6, 0x8c, 0x59, 0x29, 0x92, 0xfa, 0xed, 0x32, 0xd5, 0x6b,
-	0x8c, 0xb6, 0xf6, 0x18, 0xd5, 0xe6, 0xc0, 0xdc, 0xbe, 0x74, 0x8b, 0xf1, 0x38, 0x40, 0xae, 0xc0,
-	0x7c, 0x8e, 0x28, 0xa2, 0x34, 0x31, 0x65, 0x76, 0xc3, 0x39, 0x2d, 0xee, 0x26, 0xba, 0x0d, 0x91,
-	0x09, 0x53, 0x52, 0x37, 0xd4, 0x4b, 0xf2, 0x14, 0x5c, 0x6b, 0xca, 0x3a, 0xdc, 0x94, 0xb2, 0xd6,
-	0x6e, 0x9f, 0x3b, 0xa3, 0xe6, 0x50, 0xbb, 0xac, 0xc3, 0xc3, 0x85, 0xbc, 0xbf, 0x22, 0x9f, 0x41,
-	0xcd, 0x10, 0xea, 0x83, 0x14, 0xd2, 0x74, 0x40, 0xad, 0xbd, 0x72, 0x86, 0x32, 0x6f, 0xe7, 0x9a,
-	0x72, 0xdf, 0x58, 0x85, 0xa0, 0x5d, 0xec, 0x9a, 0xdc, 0x84, 0x7a, 0x46, 0xa5, 0x8a, 0x8a, 0x3c,
-	0xa1, 0x0a, 0x93, 0x7e, 0x7f, 0xd4, 0x34, 0xf6, 0xc2, 0x42, 0xad, 0xbf, 0x1c, 0x58, 0x28, 0x43,
-	0x93, 0x4f, 0x61, 0xe1, 0x08, 0x15, 0x4d, 0xa8, 0xa2, 0xe6, 0x7e, 0xd4, 0xda, 0xab, 0xd3, 0xa2,
-	0x7d, 0x8e, 0x8a, 0x6e, 0x51, 0x45, 0xc3, 0x81, 0x07, 0xb9, 0x06, 0xae, 0x79, 0x18, 0x62, 0x9e,
-	0xc9, 0xe6, 0x8c, 0x29, 0xf4, 0x10, 0x20, 0x37, 0xa0, 0xd6, 0xa1, 0x45, 0xa6, 0xa2, 0x98, 0x17,
-	0x83, 0x4b, 0x05, 0x06, 0xda, 0xd4, 0x08, 0xb9, 0x0f, 0x4b, 0xa5, 0x75, 0x74, 0x8c, 0x42, 0xea,
-	0x3e, 0xb0, 0x29, 0x5f, 0x2c, 0xf1, 0x2f, 0x2d, 0x4c, 0x6e, 0xc1, 0x7b, 0xb4, 0x8b, 0x4c, 0x0d,
-	0xec, 0x6c, 0x15, 0xea, 0x06, 0x2c, 0x8d, 0x6e, 0x42, 0xdd, 0x64, 0x2f, 0xa3, 0x0a, 0x59, 0x7c,
-	0xd2, 0xbf, 0x5c, 0x26, 0xa3, 0x7b, 0x16, 0x6a, 0xbf, 0x9d, 0x83, 0xaa, 0xa9, 0x04, 0xf9, 0xc1,
-	0x81, 0xc6, 0x0e, 0xaa, 0x91, 0xa1, 0x47, 0x1e, 0x4c, 0xab, 0xdd, 0xd9, 0xc9, 0xd8, 0xba, 0x35,
-	0xcd, 0x76, 0x64, 0x72, 0x79, 0x37, 0xdf, 0xfc, 0xfe, 0xe7, 0xcf, 0x33, 0x57, 0xc9, 0xfb, 0xc1,
-	0xd8, 0x54, 0x37, 0xff, 0x80, 0xc0, 0x34, 0x2b, 0xf9, 0x0e, 0x16, 0xf4, 0x2e, 0xf4, 0xec, 0x23,
-	0xb7, 0xa7, 0xc6, 0x1f, 0x19, 0x9e, 0xff, 0x43, 0x64, 0x33, 0x69, 0xc9, 0xf7, 0xb0, 0xb8, 0x8f,
-	0x6a, 0x74, 0x04, 0x92, 0x0f, 0xfe, 0xc5, 0xa0, 0x6c, 0x2d, 0xfb, 0xf6, 0x3f, 0xe1, 0x97, 0xff,
-	0x09, 0x7f, 0x5b, 0xff, 0x27, 0xbc, 0x5b, 0x26, 0xf4, 0x75, 0xef, 0xea, 0xa4, 0xd0, 0x99, 0x25,
-	0x22, 0x3f, 0x39, 0x70, 0x65, 0x07, 0xd5, 0xa4, 0xe1, 0x40, 0xa6, 0x10, 0xb7, 0x3e, 0xfe, 0x2f,
-	0x23, 0xc6, 0xbb, 0x63, 0xb6, 0xb3, 0x4a, 0x56, 0x26, 0x6d, 0xa7, 0xc3, 0xc5, 0x61, 0x6c, 0xa3,
-	0x0a, 0x70, 0xf7, 0x52, 0xa9, 0xf4, 0xcd, 0x90, 0x53, 0xb7, 0xf0, 0xe0, 0xdc, 0xb7, 0x5b, 0xbe,
-	0xbb, 0x04, 0xb9, 0x09, 0xf3, 0x1a, 0xe6, 0x75, 0x12, 0x10, 0x05, 0xf1, 0xde, 0xf1, 0xf2, 0x95,
-	0x19, 0x3f, 0xff, 0x6b, 0xed, 0xad, 0x9a, 0xe0, 0x2d, 0xd2, 0x9c, 0x16, 0x7c, 0xa3, 0xfe, 0xeb,
-	0xe9, 0x8a, 0xf3, 0xdb, 0xe9, 0x8a, 0xf3, 0xc7, 0xe9, 0x8a, 0x73, 0x30, 0x67, 0x0e, 0xfa, 0xd1,
-	0xdf, 0x01, 0x00, 0x00, 0xff, 0xff, 0xb0, 0xb3, 0x9f, 0x03, 0xb8, 0x0a, 0x00, 0x00,
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xad, 0x56, 0x5f, 0x6f, 0xdb, 0x54,
+	0x14, 0x5f, 0xda, 0xa4, 0x8d, 0x4f, 0x42, 0x92, 0xdd, 0x4d, 0x6d, 0x48, 0xbb, 0x36, 0x75, 0xa7,
+	0x75, 0x1b, 0xc2, 0x56, 0x03, 0x0f, 0x68, 0x42, 0x42, 0x4d, 0x9b, 0x95, 0x4a, 0xed, 0x36, 0xdc,
+	0x8d, 0x07, 0x26, 0x64, 0xb9, 0xf6, 0x4d, 0x62, 0xea, 0x5e, 0x1b, 0xff, 0x29, 0x64, 0xbc, 0x4d,
+	0x08, 0x1e, 0x79, 0xe0, 0xcb, 0xf0, 0x11, 0x78, 0x44, 0xe2, 0x0b, 0x20, 0xc4, 0x87, 0x40, 0x3c,
+	0x71, 0xee, 0xbd, 0x76, 0xfe, 0xa8, 0xf1, 0x28, 0x88, 0x87, 0x28, 0xf7, 0x9c, 0xf3, 0x3b, 0xff,
+	0xcf, 0xbd, 0xc7, 0xb0, 0x19, 0x84, 0x7e, 0xec, 0xeb, 0x67, 0xd4, 0xb2, 0x7d, 0xa6, 0x87, 0x81,
+	0xad, 0x5f, 0xee, 0xea, 0x0e, 0x3d, 0x4b, 0x06, 0x9a, 0x90, 0x90, 0x15, 0x1a, 0x0f, 0x69, 0x48,
+	0x93, 0x0b, 0x4d, 0x62, 0x34, 0xc4, 0x68, 0x97, 0xbb, 0xad, 0x55, 0xe4, 0x23, 0xd6, 0xf2, 0x82,
+	0xa1, 0xb5, 0xab, 0x33, 0xdf, 0xa1, 0x52, 0xa1, 0xa5, 0xce, 0x58, 0x0c, 0x3a, 0x01, 0xb7, 0x78,
+	0x41, 0xa3, 0xc8, 0x1a, 0xd0, 0x28, 0xc5, 0xac, 0x0f, 0x7c, 0x7f, 0xe0, 0x51, 0xdd, 0x0a, 0x5c,
+	0xdd, 0x62, 0xcc, 0x8f, 0xad, 0xd8, 0xf5, 0x59, 0x26, 0x5d, 0x4b, 0xa5, 0x82, 0x3a, 0x4b, 0xfa,
+	0x3a, 0xbd, 0x08, 0xe2, 0x91, 0x14, 0xaa, 0x2f, 0x81, 0x74, 0x85, 0xe9, 0x53, 0x54, 0xa2, 0x06,
+	0xfd, 0x32, 0xa1, 0x51, 0x4c, 0x6e, 0x43, 0x31, 0xf2, 0xfc, 0xb8, 0x59, 0x68, 0x17, 0xee, 0x17,
+	0x3f, 0xbe, 0x61, 0x08, 0x8a, 0x6c, 0x02, 0x9c, 0x79, 0xbe, 0x7d, 0x6e, 0x86, 0x3e, 0xca, 0x16,
+	0x50, 0x56, 0x45, 0x99, 0x22, 0x78, 0x06, 0xb2, 0xba, 0x35, 0xa8, 0xa2, 0x7e, 0x38, 0x32, 0xfb,
+	0xae, 0x17, 0xd3, 0x50, 0x7d, 0x17, 0xaa, 0x5d, 0x21, 0x4c, 0xcd, 0xde, 0x99, 0x31, 0xc0, 0x8d,
+	0x57, 0xa7, 0xd4, 0xd5, 0x1d, 0xa8, 0x9c, 0x9e, 0x7e, 0x66, 0xd0, 0x28, 0xc0, 0xe0, 0x29, 0x69,
+	0xc2, 0x32, 0x65, 0x36, 0x56, 0xc2, 0x49, 0xa1, 0x19, 0xa9, 0x7e, 0x5f, 0x80, 0x5b, 0xc7, 0xfe,
+	0x60, 0xe0, 0xb2, 0xc1, 0x31, 0xbd, 0xa4, 0x5e, 0x66, 0xff, 0x10, 0x4a, 0x1e, 0xa7, 0x05, 0xbe,
+	0xd6, 0xd9, 0xd5, 0xe6, 0x17, 0x5b, 0x9b, 0xa3, 0xab, 0x49, 0x42, 0xea, 0x63, 0x24, 0x25, 0x41,
+	0x93, 0x32, 0x14, 0x8f, 0x9e, 0x3c, 0x7e, 0xda, 0xb8, 0x41, 0x14, 0x28, 0x1d, 0xf4, 0xba, 0x2f,
+	0x0e, 0x1b, 0x05, 0x7e, 0x7c, 0x6e, 0xec, 0xed, 0xf7, 0x1a, 0x0b, 0xea, 0x77, 0x8b, 0xb0, 0xfe,
+	0x8c, 0x17, 0x72, 0x2f, 0x0c, 0xad, 0xd1, 0x63, 0x3f, 0x3c, 0xdf, 0x1f, 0xfa, 0xae, 0x4d, 0xc7,
+	0x49, 0xec, 0x40, 0x3d, 0x08, 0x13, 0x46, 0xcd, 0x78, 0x18, 0xd2, 0x68, 0xe8, 0x7b, 0x32, 0x99,
+	0xa2, 0x51, 0x13, 0xec, 0xe7, 0x19, 0x97, 0x03, 0xbf, 0x48, 0xa2, 0xd8, 0xed, 0xbb, 0xd4, 0x31,
+	0x69, 0xe0, 0xdb, 0x43, 0x51, 0x61, 0x04, 0x8e, 0xd9, 0x3d, 0xce, 0xe5, 0xc0, 0xbe, 0xcb, 0x2c,
+	0xcf, 0x7d, 0x35, 0x06, 0x2e, 0x4a, 0xe0, 0x98, 0x2d, 0x81, 0x06, 0xdc, 0x14, 0x3d, 0x36, 0x2d,
+	0x1e, 0x9b, 0xc9, 0x67, 0x2a, 0x6a, 0x16, 0xdb, 0x8b, 0xf7, 0x2b, 0x9d, 0x7b, 0x79, 0x95, 0x99,
+	0xe4, 0xf2, 0x04, 0xe1, 0x46, 0x3d, 0x98, 0xa1, 0x23, 0xf2, 0x12, 0x96, 0x5d, 0xe6, 0x60, 0x82,
+	0x51, 0xb3, 0x24, 0x2c, 0xed, 0xfd, 0xb3, 0xa5, 0xab, 0x55, 0xd1, 0x8e, 0xa4, 0x8d, 0x1e, 0x8b,
+	0xc3, 0x91, 0x91, 0x59, 0x6c, 0x3d, 0x82, 0xea, 0xb4, 0x80, 0x34, 0x60, 0xf1, 0x9c, 0x8e, 0x44,
+	0xbd, 0x14, 0x83, 0x1f, 0x71, 0x2e, 0x4b, 0x97, 0x96, 0x97, 0xd0, 0xb4, 0x34, 0x92, 0x78, 0xb4,
+	0xf0, 0x41, 0x41, 0x7d, 0xbd, 0x00, 0xb5, 0xd9, 0xe0, 0x09, 0x99, 0x1e, 0xe2, 0x74, 0x84, 0x91,
+	0x37, 0x19, 0x5e, 0x43, 0x9c, 0xc9, 0x0a, 0x2c, 0x05, 0x56, 0x48, 0x59, 0x9c, 0xd6, 0x31, 0xa5,
+	0xe6, 0x75, 0xa4, 0x78, 0xdd, 0x8e, 0x94, 0xe6, 0x76, 0x04, 0x3d, 0x7d, 0x45, 0xdd, 0xc1, 0x30,
+	0x6e, 0x2e, 0x49, 0x4f, 0x92, 0x12, 0xf7, 0x02, 0x67, 0xd0, 0xb4, 0x87, 0x2e, 0xce, 0xc7, 0xb2,
+	0x90, 0x29, 0x9c, 0xb3, 0xcf, 0x19, 0xdc, 0xbe, 0x10, 0x63, 0x03, 0x6c, 0xca, 0x1c, 0x0b, 0x23,
+	0x2d, 0x4b, 0xfb, 0x9c, 0x7d, 0x30, 0xe6, 0xaa, 0x9f, 0x03, 0x39, 0xe0, 0x6f, 0xcd, 0x33, 0x4a,
+	0xc3, 0xac, 0xd6, 0x11, 0xde, 0x0a, 0x25, 0xcc, 0x08, 0x2c, 0x06, 0xef, 0xda, 0x83, 0xbc, 0xae,
+	0x5d, 0x51, 0x37, 0x26, 0xba, 0xea, 0x4f, 0x25, 0xb8, 0x79, 0x05, 0x40, 0x74, 0xb8, 0xe5, 0xb9,
+	0x51, 0x4c, 0x19, 0xde, 0x28, 0xd3, 0x72, 0x1c, 0xc4, 0x67, 0x8e, 0x14, 0x83, 0x8c, 0x45, 0x7b,
+	0x99, 0x84, 0x74, 0x41, 0x71, 0xdc, 0x90, 0xda, 0xfc, 0x8d, 0x12, 0x8d, 0xa8, 0x75, 0xee, 0x4e,
+	0xe2, 0xc1, 0x83, 0x96, 0xbd, 0x83, 0x1a, 0x77, 0x74, 0x90, 0x61, 0x8d, 0x89, 0x1a, 0xf9, 0x04,
+	0x1a, 0x18, 0x35, 0x93, 0x94, 0x19, 0xf1, 0xb7, 0x4b, 0x74, 0xaf, 0x36, 0x3d, 0xda, 0x33, 0xa6,
+	0xf6, 0xc7, 0x70, 0xf9, 0xd2, 0xd5, 0xed, 0x59, 0x06, 0x59, 0x85, 0xe5, 0x00, 0xdd, 0x99, 0xae,
+	0x23, 0xda, 0xac, 0xe0, 0x1c, 0x20, 0x79, 0xe4, 0xf0, 0x31, 0xa4, 0x2c, 0x14, 0x2d, 0xc5, 0x31,
+	0xc4, 0x23, 0x79, 0x0a, 0x8a, 0x84, 0xb2, 0xbe, 0x2f, 0x5a, 0x59, 0xe9, 0x74, 0xae, 0x5d, 0x51,
+	0x91, 0xd4, 0x11, 0x6a, 0x1a, 0xe5, 0x20, 0x3d, 0x91, 0x8f, 0xa0, 0x22, 0x0c, 0xf2, 0x44, 0x92,
+	0x48, 0x4c, 0x40, 0xa5, 0xb3, 0x71, 0xc5, 0x24, 0xbe, 0xfe, 0xdc, 0xe4, 0xa9, 0x40, 0x19, 0xc0,
+	0x55, 0xe4, 0x99, 0x6c, 0x41, 0xd5, 0xb3, 0x70, 0x44, 0x92, 0xc0, 0xc1, 0x5c, 0x9c, 0x74, 0x3e,
+	0x2a, 0x9c, 0xf7, 0x42, 0xb2, 0x5a, 0x7f, 0x15, 0xa0, 0x9c, 0xb9, 0x26, 0x1f, 0x42, 0xf9, 0x82,
+	0xc6, 0x16, 0x4a, 0x2c, 0x71, 0x3f, 0x2a, 0x9d, 0x76, 0x9e, 0xb7, 0x13, 0xc4, 0x1d, 0x20, 0xce,
+	0x18, 0x6b, 0x90, 0x75, 0xcc, 0x9f, 0xdf, 0x35, 0xdb, 0xf7, 0x22, 0xec, 0x20, 0x6f, 0xf4, 0x84,
+	0x81, 0x6b, 0xa2, 0xd2, 0xb7, 0x12, 0x0f, 0xc7, 0xd9, 0x4f, 0xc6, 0x97, 0x0a, 0x04, 0x6b, 0x9f,
+	0x73, 0xc8, 0x03, 0x68, 0x64, 0x68, 0xf3, 0x92, 0x86, 0x11, 0x9f, 0x03, 0x59, 0xf2, 0x7a, 0xc6,
+	0xff, 0x54, 0xb2, 0xc9, 0x36, 0xbc, 0x85, 0x7b, 0x8e, 0xc5, 0x63, 0x9c, 0xec, 0x42, 0x55, 0x30,
+	0x33, 0x10, 0x26, 0x2f, 0xaa, 0xe7, 0x61, 0x9e, 0xcc, 0x1e, 0xa5, 0x97, 0x4b, 0x54, 0xf4, 0x58,
+	0xb2, 0x3a, 0x7f, 0x2e, 0xe1, 0xf3, 0xcd, 0x3b, 0x41, 0xbe, 0x2d, 0x40, 0xed, 0x90, 0xc6, 0x53,
+	0x4b, 0x8f, 0x3c, 0xcc, 0xeb, 0xdd, 0xd5, 0xcd, 0xd8, 0xda, 0xce, 0xc3, 0x4e, 0x6d, 0x2e, 0x75,
+	0xeb, 0xf5, 0xaf, 0x7f, 0xfc, 0xb8, 0xb0, 0x46, 0xde, 0xd6, 0x67, 0xb6, 0xba, 0xf8, 0x0e, 0xd0,
+	0xc5, 0xb0, 0x92, 0xaf, 0xa1, 0xcc, 0xa3, 0xe0, 0xbb, 0x8f, 0xdc, 0xcd, 0xf5, 0x3f, 0xb5, 0x3c,
+	0xff, 0x07, 0xcf, 0x62, 0xd3, 0x92, 0x6f, 0xa0, 0x7e, 0x4a, 0xe3, 0xe9, 0x15, 0x48, 0xde, 0xf9,
+	0x17, 0x8b, 0xb2, 0xb5, 0xa2, 0xc9, 0xef, 0x09, 0x2d, 0xfb, 0x9e, 0xd0, 0x7a, 0xfc, 0x7b, 0x42,
+	0xdd, 0x16, 0xae, 0xef, 0xa8, 0x6b, 0xf3, 0x5c, 0x7b, 0xd2, 0x10, 0xf9, 0xa1, 0x00, 0xab, 0x98,
+	0xf7, 0xbc, 0xe5, 0x40, 0x72, 0x0c, 0xb7, 0xde, 0xff, 0x2f, 0x2b, 0x46, 0xbd, 0x27, 0xc2, 0x69,
+	0x93, 0x8d, 0x79, 0xe1, 0xf4, 0x11, 0x6f, 0x4b, 0xaf, 0x21, 0x28, 0xc7, 0xf8, 0x46, 0xf1, 0x9b,
+	0x11, 0xe5, 0x86, 0xf0, 0xf0, 0xda, 0xb7, 0x3b, 0x7a, 0x73, 0x0b, 0x02, 0xe1, 0xe6, 0x15, 0x2c,
+	0xf3, 0x22, 0xe0, 0x99, 0xa8, 0x6f, 0x78, 0xf9, 0xb2, 0x8a, 0x5f, 0xff, 0xb5, 0x56, 0xdb, 0xc2,
+	0x79, 0x8b, 0x34, 0xf3, 0x9c, 0x13, 0x1f, 0x6e, 0xe3, 0x76, 0xa5, 0xe1, 0x89, 0xe5, 0xe2, 0x1f,
+	0xb3, 0x98, 0x4d, 0x4f, 0xf8, 0xb6, 0xcc, 0x4b, 0x3d, 0xaf, 0xdd, 0x3b, 0xc2, 0xd3, 0x96, 0xba,
+	0x39, 0xcf, 0xd3, 0xc5, 0xc4, 0x78, 0xb7, 0xfa, 0xf3, 0xef, 0x1b, 0x85, 0x5f, 0xf0, 0xf7, 0x1b,
+	0xfe, 0xce, 0x96, 0x84, 0x99, 0xf7, 0xfe, 0x06, 0x57, 0x50, 0xcd, 0x72, 0x29, 0x0b, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -816,6 +816,7 @@ type DebugClient interface {
 	GetProtoArrayForkChoice(ctx context.Context, in *types.Empty, opts ...grpc.CallOption) (*ProtoArrayForkChoiceResponse, error)
 	ListPeers(ctx context.Context, in *types.Empty, opts ...grpc.CallOption) (*DebugPeerResponses, error)
 	GetPeer(ctx context.Context, in *v1alpha1.PeerRequest, opts ...grpc.CallOption) (*DebugPeerResponse, error)
+	EnterMaintenanceMode(ctx context.Context, in *types.Empty, opts ...grpc.CallOption) (*types.Empty, error)
 }
 
 type debugClient struct {
@@ -880,6 +881,15 @@ func (c *debugClient) GetPeer(ctx context.Context, in *v1alpha1.PeerRequest, opt
 	return out, nil
 }
 
+func (c *debugClient) EnterMaintenanceMode(ctx context.Context, in *types.Empty, opts ...grpc.CallOption) (*types.Empty, error) {
+	out := new(types.Empty)
+	err := c.cc.Invoke(ctx, "/ethereum.beacon.rpc.v1.Debug/EnterMaintenanceMode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DebugServer is the server API for Debug service.
 type DebugServer interface {
 	GetBeaconState(context.Context, *BeaconStateRequest) (*SSZResponse, error)
@@ -888,6 +898,7 @@ type DebugServer interface {
 	GetProtoArrayForkChoice(context.Context, *types.Empty) (*ProtoArrayForkChoiceResponse, error)
 	ListPeers(context.Context, *types.Empty) (*DebugPeerResponses, error)
 	GetPeer(context.Context, *v1alpha1.PeerRequest) (*DebugPeerResponse, error)
+	EnterMaintenanceMode(context.Context, *types.Empty) (*types.Empty, error)
 }
 
 // UnimplementedDebugServer can be embedded to have forward compatible implementations.
@@ -912,6 +923,9 @@ func (*UnimplementedDebugServer) ListPeers(ctx context.Context, req *types.Empty
 func (*UnimplementedDebugServer) GetPeer(ctx context.Context, req *v1alpha1.PeerRequest) (*DebugPeerResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetPeer not implemented")
 }
+func (*UnimplementedDebugServer) EnterMaintenanceMode(ctx context.Context, req *types.Empty) (*types.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnterMaintenanceMode not implemented")
+}
 
 func RegisterDebugServer(s *grpc.Server, srv DebugServer) {
 	s.RegisterService(&_Debug_serviceDesc, srv)
@@ -1025,6 +1039,24 @@ func _Debug_GetPeer_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Debug_EnterMaintenanceMode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(types.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebugServer).EnterMaintenanceMode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ethereum.beacon.rpc.v1.Debug/EnterMaintenanceMode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebugServer).EnterMaintenanceMode(ctx, req.(*types.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Debug_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "ethereum.beacon.rpc.v1.Debug",
 	HandlerType: (*DebugServer)(nil),
@@ -1053,6 +1085,10 @@ var _Debug_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetPeer",
 			Handler:    _Debug_GetPeer_Handler,
 		},
+		{
+			MethodName: "EnterMaintenanceMode",
+			Handler:    _Debug_EnterMaintenanceMode_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/beacon/rpc/v1/debug.proto",
@@ -649,78 +649,78 @@ func init() {
 func init() { proto.RegisterFile("proto/beacon/rpc/v1/debug.proto", fileDescriptor_851e5cb2de3d61dd) }
 
 var fileDescriptor_851e5cb2de3d61dd = []byte{
-	// 1132 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x56, 0xcd, 0x6e, 0x1b, 0xb7,
-	0x13, 0x8f, 0x6c, 0xc9, 0xf6, 0x8e, 0xf4, 0x97, 0x15, 0x26, 0x70, 0xf4, 0x57, 0xbe, 0x94, 0x4d,
-	0x90, 0x38, 0x29, 0xba, 0x0b, 0xab, 0x3d, 0x14, 0x41, 0x81, 0xc2, 0x5f, 0x71, 0x0c, 0xb8, 0x49,
-	0xba, 0x4e, 0x7a, 0x68, 0x50, 0x2c, 0xe8, 0xdd, 0x91, 0xb4, 0xf5, 0x9a, 0xdc, 0x90, 0x5c, 0xb5,
-	0x4a, 0x6f, 0x41, 0xd1, 0x1c, 0x7b, 0xe8, 0xcb, 0xf4, 0x3d, 0xfa, 0x0a, 0x7d, 0x8a, 0x9e, 0x0a,
-	0x92, 0x5a, 0x7d, 0xc0, 0x52, 0xea, 0x16, 0xbd, 0x71, 0x7e, 0x33, 0xf3, 0x1b, 0x72, 0x66, 0xc8,
-	0x21, 0xdc, 0xce, 0x04, 0x57, 0xdc, 0x3f, 0x41, 0x1a, 0x71, 0xe6, 0x8b, 0x2c, 0xf2, 0x07, 0x5b,
-	0x7e, 0x8c, 0x27, 0x79, 0xcf, 0x33, 0x1a, 0xb2, 0x81, 0xaa, 0x8f, 0x02, 0xf3, 0x33, 0xcf, 0xda,
-	0x78, 0x22, 0x8b, 0xbc, 0xc1, 0x56, 0xeb, 0x1a, 0xaa, 0xbe, 0x3f, 0xd8, 0xa2, 0x69, 0xd6, 0xa7,
-	0x5b, 0x3e, 0xe3, 0x31, 0x5a, 0x87, 0x96, 0x3b, 0xc3, 0x98, 0x75, 0x32, 0xcd, 0x78, 0x86, 0x52,
-	0xd2, 0x1e, 0xca, 0x91, 0xcd, 0x8d, 0x1e, 0xe7, 0xbd, 0x14, 0x7d, 0x9a, 0x25, 0x3e, 0x65, 0x8c,
-	0x2b, 0xaa, 0x12, 0xce, 0x0a, 0xed, 0xf5, 0x91, 0xd6, 0x48, 0x27, 0x79, 0xd7, 0xc7, 0xb3, 0x4c,
-	0x0d, 0xad, 0xd2, 0x7d, 0x0d, 0x64, 0xc7, 0x50, 0x1f, 0x2b, 0xaa, 0x30, 0xc0, 0x37, 0x39, 0x4a,
-	0x45, 0xae, 0x42, 0x59, 0xa6, 0x5c, 0x35, 0x4b, 0xed, 0xd2, 0x66, 0xf9, 0xe9, 0xa5, 0xc0, 0x48,
-	0xe4, 0x36, 0xc0, 0x49, 0xca, 0xa3, 0xd3, 0x50, 0x70, 0xae, 0x9a, 0x4b, 0xed, 0xd2, 0x66, 0xed,
-	0xe9, 0xa5, 0xc0, 0x31, 0x58, 0xc0, 0xb9, 0xda, 0xa9, 0x43, 0xed, 0x4d, 0x8e, 0x62, 0x18, 0x76,
-	0x93, 0x54, 0xa1, 0x70, 0x3f, 0x86, 0xda, 0x8e, 0x51, 0x8e, 0x68, 0x6f, 0xce, 0x10, 0x68, 0xf2,
-	0xda, 0x94, 0xbb, 0xfb, 0x00, 0xaa, 0xc7, 0xc7, 0xdf, 0x04, 0x28, 0x33, 0xce, 0x24, 0x92, 0x26,
-	0xac, 0x22, 0x8b, 0x78, 0x8c, 0xf1, 0xc8, 0xb4, 0x10, 0xdd, 0xf7, 0x25, 0xb8, 0x72, 0xc4, 0x7b,
-	0xbd, 0x84, 0xf5, 0x8e, 0x70, 0x80, 0x69, 0xc1, 0x7f, 0x00, 0x95, 0x54, 0xcb, 0xc6, 0xbe, 0xde,
-	0xd9, 0xf2, 0xe6, 0x27, 0xdb, 0x9b, 0xe3, 0xeb, 0x59, 0xc1, 0xfa, 0xbb, 0x0f, 0xa0, 0x62, 0x64,
-	0xb2, 0x06, 0xe5, 0xc3, 0x67, 0x4f, 0x9e, 0x37, 0x2e, 0x11, 0x07, 0x2a, 0x7b, 0xfb, 0x3b, 0xaf,
-	0x0e, 0x1a, 0x25, 0xbd, 0x7c, 0x19, 0x6c, 0xef, 0xee, 0x37, 0x96, 0xdc, 0x9f, 0x97, 0xe1, 0xc6,
-	0x0b, 0x9d, 0xc8, 0x6d, 0x21, 0xe8, 0xf0, 0x09, 0x17, 0xa7, 0xbb, 0x7d, 0x9e, 0x44, 0x38, 0x3e,
-	0xc4, 0x03, 0x58, 0xcf, 0x44, 0xce, 0x30, 0x54, 0x7d, 0x81, 0xb2, 0xcf, 0x53, 0x7b, 0x98, 0x72,
-	0x50, 0x37, 0xf0, 0xcb, 0x02, 0xd5, 0x86, 0xdf, 0xe5, 0x52, 0x25, 0xdd, 0x04, 0xe3, 0x10, 0x33,
-	0x1e, 0xf5, 0x4d, 0x86, 0xcb, 0x41, 0x7d, 0x0c, 0xef, 0x6b, 0x54, 0x1b, 0x76, 0x13, 0x46, 0xd3,
-	0xe4, 0xed, 0xd8, 0x70, 0xd9, 0x1a, 0x8e, 0x61, 0x6b, 0x18, 0xc0, 0x65, 0x53, 0xe3, 0x90, 0xea,
-	0xbd, 0x85, 0xba, 0xa7, 0x64, 0xb3, 0xdc, 0x5e, 0xde, 0xac, 0x76, 0xee, 0x2f, 0xca, 0xcc, 0xe4,
-	0x2c, 0xcf, 0x78, 0x8c, 0xc1, 0x7a, 0x36, 0x23, 0x4b, 0xf2, 0x1a, 0x56, 0x13, 0x16, 0x27, 0x11,
-	0xca, 0x66, 0xc5, 0x30, 0x6d, 0xff, 0x3d, 0xd3, 0xf9, 0xac, 0x78, 0x87, 0x96, 0x63, 0x9f, 0x29,
-	0x31, 0x0c, 0x0a, 0xc6, 0xd6, 0x63, 0xa8, 0x4d, 0x2b, 0x48, 0x03, 0x96, 0x4f, 0x71, 0x68, 0xf2,
-	0xe5, 0x04, 0x7a, 0x49, 0xae, 0x42, 0x65, 0x40, 0xd3, 0x1c, 0x47, 0xa9, 0xb1, 0xc2, 0xe3, 0xa5,
-	0xcf, 0x4a, 0xee, 0xbb, 0x25, 0xa8, 0xcf, 0x6e, 0x9e, 0x90, 0xe9, 0x26, 0x1e, 0xb5, 0x30, 0x81,
-	0xf2, 0xa4, 0x79, 0x03, 0xb3, 0x26, 0x1b, 0xb0, 0x92, 0x51, 0x81, 0x4c, 0x8d, 0xf2, 0x38, 0x92,
-	0xe6, 0x55, 0xa4, 0x7c, 0xd1, 0x8a, 0x54, 0xe6, 0x56, 0x64, 0x03, 0x56, 0xbe, 0xc7, 0xa4, 0xd7,
-	0x57, 0xcd, 0x15, 0x1b, 0xc9, 0x4a, 0xe6, 0x5e, 0xa0, 0x54, 0x61, 0xd4, 0x4f, 0xd2, 0xb8, 0xb9,
-	0x6a, 0x74, 0x8e, 0x46, 0x76, 0x35, 0xa0, 0xf9, 0x8d, 0x3a, 0x46, 0x19, 0x21, 0x8b, 0x29, 0x53,
-	0xcd, 0x35, 0xcb, 0xaf, 0xe1, 0xbd, 0x31, 0xea, 0x7e, 0x0b, 0x64, 0x4f, 0xbf, 0x35, 0x2f, 0x10,
-	0x45, 0x91, 0x6b, 0x49, 0x0e, 0xc0, 0x11, 0x85, 0xd0, 0x2c, 0x99, 0xaa, 0x3d, 0x5c, 0x54, 0xb5,
-	0x73, 0xee, 0xc1, 0xc4, 0xd7, 0xfd, 0xad, 0x02, 0x97, 0xcf, 0x19, 0x10, 0x1f, 0xae, 0xa4, 0x89,
-	0x54, 0xc8, 0x12, 0xd6, 0x0b, 0x69, 0x1c, 0x0b, 0x94, 0x45, 0x20, 0x27, 0x20, 0x63, 0xd5, 0x76,
-	0xa1, 0x21, 0x3b, 0xe0, 0xc4, 0x89, 0xc0, 0x48, 0xbf, 0x51, 0xa6, 0x10, 0xf5, 0xce, 0xbd, 0xc9,
-	0x7e, 0x50, 0xf5, 0xbd, 0xe2, 0x1d, 0xf4, 0x74, 0xa0, 0xbd, 0xc2, 0x36, 0x98, 0xb8, 0x91, 0xaf,
-	0xa0, 0x11, 0x71, 0xc6, 0xac, 0x14, 0x4a, 0xfd, 0x76, 0x99, 0xea, 0xd5, 0xa7, 0x5b, 0x7b, 0x86,
-	0x6a, 0x77, 0x6c, 0x6e, 0x5f, 0xba, 0xf5, 0x68, 0x16, 0x20, 0xd7, 0x60, 0x35, 0x43, 0x14, 0x61,
-	0x12, 0x9b, 0x32, 0x3b, 0xc1, 0x8a, 0x16, 0x0f, 0x63, 0xdd, 0x86, 0xc8, 0x84, 0x29, 0xa9, 0x13,
-	0xe8, 0x25, 0x79, 0x0e, 0x8e, 0x35, 0x65, 0x5d, 0x6e, 0x4a, 0x59, 0xed, 0x74, 0x2e, 0x9c, 0x51,
-	0x73, 0xa8, 0x43, 0xd6, 0xe5, 0xc1, 0x5a, 0x36, 0x5a, 0x91, 0x2f, 0xa0, 0x6a, 0x08, 0xf5, 0x41,
-	0x72, 0x69, 0x3a, 0xa0, 0xda, 0xb9, 0x75, 0x8e, 0x32, 0xeb, 0x64, 0x9a, 0xf2, 0xd8, 0x58, 0x05,
-	0xa0, 0x5d, 0xec, 0x9a, 0xdc, 0x81, 0x5a, 0x4a, 0xa5, 0x0a, 0xf3, 0x2c, 0xa6, 0x0a, 0xe3, 0x51,
-	0x7f, 0x54, 0x35, 0xf6, 0xca, 0x42, 0xad, 0x3f, 0x4b, 0xb0, 0x56, 0x84, 0x26, 0x9f, 0xc3, 0xda,
-	0x19, 0x2a, 0x1a, 0x53, 0x45, 0xcd, 0xfd, 0xa8, 0x76, 0xda, 0x8b, 0xa2, 0x7d, 0x89, 0x8a, 0xee,
-	0x51, 0x45, 0x83, 0xb1, 0x07, 0xb9, 0x01, 0x8e, 0x79, 0x18, 0x22, 0x9e, 0xca, 0xe6, 0x92, 0x29,
-	0xf4, 0x04, 0x20, 0xb7, 0xa1, 0xda, 0xa5, 0x79, 0xaa, 0xc2, 0x88, 0xe7, 0xe3, 0x4b, 0x05, 0x06,
-	0xda, 0xd5, 0x08, 0x79, 0x08, 0x8d, 0xc2, 0x3a, 0x1c, 0xa0, 0x90, 0xba, 0x0f, 0x6c, 0xca, 0xd7,
-	0x0b, 0xfc, 0x6b, 0x0b, 0x93, 0xbb, 0xf0, 0x3f, 0xda, 0x43, 0xa6, 0xc6, 0x76, 0xb6, 0x0a, 0x35,
-	0x03, 0x16, 0x46, 0x77, 0xa0, 0x66, 0xb2, 0x97, 0x52, 0x85, 0x2c, 0x1a, 0x8e, 0x2e, 0x97, 0xc9,
-	0xe8, 0x91, 0x85, 0x3a, 0xef, 0x57, 0xa0, 0x62, 0x2a, 0x41, 0x7e, 0x2a, 0x41, 0xfd, 0x00, 0xd5,
-	0xd4, 0xd0, 0x23, 0x8f, 0x16, 0xd5, 0xee, 0xfc, 0x64, 0x6c, 0xdd, 0x5d, 0x64, 0x3b, 0x35, 0xb9,
-	0xdc, 0x3b, 0xef, 0x7e, 0xff, 0xe3, 0xd7, 0xa5, 0xeb, 0xe4, 0xff, 0xfe, 0xcc, 0x54, 0x37, 0xff,
-	0x00, 0xdf, 0x34, 0x2b, 0xf9, 0x01, 0xd6, 0xf4, 0x2e, 0xf4, 0xec, 0x23, 0xf7, 0x16, 0xc6, 0x9f,
-	0x1a, 0x9e, 0xff, 0x41, 0x64, 0x33, 0x69, 0xc9, 0x8f, 0xb0, 0x7e, 0x8c, 0x6a, 0x7a, 0x04, 0x92,
-	0x8f, 0xfe, 0xc1, 0xa0, 0x6c, 0x6d, 0x78, 0xf6, 0x3f, 0xe1, 0x15, 0xff, 0x09, 0x6f, 0x5f, 0xff,
-	0x27, 0xdc, 0xbb, 0x26, 0xf4, 0x4d, 0xf7, 0xfa, 0xbc, 0xd0, 0xa9, 0x25, 0x22, 0xbf, 0x94, 0xe0,
-	0xda, 0x01, 0xaa, 0x79, 0xc3, 0x81, 0x2c, 0x20, 0x6e, 0x7d, 0xfa, 0x6f, 0x46, 0x8c, 0x7b, 0xdf,
-	0x6c, 0xa7, 0x4d, 0x6e, 0xcd, 0xdb, 0x4e, 0x97, 0x8b, 0xd3, 0xc8, 0x46, 0x15, 0xe0, 0x1c, 0x25,
-	0x52, 0xe9, 0x9b, 0x21, 0x17, 0x6e, 0xe1, 0xd1, 0x85, 0x6f, 0xb7, 0xfc, 0x70, 0x09, 0x32, 0x13,
-	0xe6, 0x2d, 0xac, 0xea, 0x24, 0x20, 0x0a, 0xe2, 0x7e, 0xe0, 0xe5, 0x2b, 0x32, 0x7e, 0xf1, 0xd7,
-	0xda, 0x6d, 0x9b, 0xe0, 0x2d, 0xd2, 0x5c, 0x14, 0xfc, 0x64, 0xc5, 0x1c, 0xed, 0x93, 0xbf, 0x02,
-	0x00, 0x00, 0xff, 0xff, 0x6f, 0x05, 0xb2, 0xc5, 0xaa, 0x0a, 0x00, 0x00,
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xad, 0x56, 0x4b, 0x6f, 0xdb, 0x46,
+	0x10, 0x8e, 0x64, 0xc9, 0x96, 0x46, 0xaa, 0xac, 0x6c, 0x02, 0x5b, 0x95, 0x9d, 0xd8, 0xa6, 0x83,
+	0x38, 0x49, 0x51, 0x12, 0x56, 0x7b, 0x28, 0x82, 0x02, 0x85, 0xf5, 0x88, 0x6b, 0xc0, 0x4e, 0x52,
+	0x2a, 0xe9, 0x21, 0x41, 0x40, 0x50, 0xe4, 0x4a, 0x62, 0x4d, 0x2f, 0x59, 0x3e, 0xdc, 0x2a, 0xbd,
+	0x05, 0x45, 0x7b, 0xec, 0xa1, 0x7f, 0xa6, 0xff, 0xa3, 0x7f, 0xa1, 0x3f, 0xa2, 0xe8, 0xa9, 0xb3,
+	0xbb, 0xa4, 0x1e, 0xb0, 0x98, 0xba, 0x45, 0x4f, 0xdc, 0x99, 0xf9, 0xe6, 0x3d, 0xbb, 0x43, 0xd8,
+	0xf1, 0x03, 0x2f, 0xf2, 0xb4, 0x01, 0x35, 0x2d, 0x8f, 0x69, 0x81, 0x6f, 0x69, 0x97, 0x87, 0x9a,
+	0x4d, 0x07, 0xf1, 0x48, 0x15, 0x12, 0xb2, 0x41, 0xa3, 0x31, 0x0d, 0x68, 0x7c, 0xa1, 0x4a, 0x8c,
+	0x8a, 0x18, 0xf5, 0xf2, 0xb0, 0xb9, 0x89, 0x7c, 0xc4, 0x9a, 0xae, 0x3f, 0x36, 0x0f, 0x35, 0xe6,
+	0xd9, 0x54, 0x2a, 0x34, 0x95, 0x05, 0x8b, 0x7e, 0xcb, 0xe7, 0x16, 0x2f, 0x68, 0x18, 0x9a, 0x23,
+	0x1a, 0x26, 0x98, 0xed, 0x91, 0xe7, 0x8d, 0x5c, 0xaa, 0x99, 0xbe, 0xa3, 0x99, 0x8c, 0x79, 0x91,
+	0x19, 0x39, 0x1e, 0x4b, 0xa5, 0x5b, 0x89, 0x54, 0x50, 0x83, 0x78, 0xa8, 0xd1, 0x0b, 0x3f, 0x9a,
+	0x48, 0xa1, 0xf2, 0x1a, 0x48, 0x5b, 0x98, 0xee, 0xa3, 0x12, 0xd5, 0xe9, 0xb7, 0x31, 0x0d, 0x23,
+	0x72, 0x1b, 0x0a, 0xa1, 0xeb, 0x45, 0x8d, 0xdc, 0x6e, 0xee, 0x41, 0xe1, 0xcb, 0x1b, 0xba, 0xa0,
+	0xc8, 0x0e, 0xc0, 0xc0, 0xf5, 0xac, 0x73, 0x23, 0xf0, 0x50, 0x96, 0x47, 0x59, 0x15, 0x65, 0x65,
+	0xc1, 0xd3, 0x91, 0xd5, 0xae, 0x41, 0x15, 0xf5, 0x83, 0x89, 0x31, 0x74, 0xdc, 0x88, 0x06, 0xca,
+	0xc7, 0x50, 0x6d, 0x0b, 0x61, 0x62, 0xf6, 0xce, 0x82, 0x01, 0x6e, 0xbc, 0x3a, 0xa7, 0xae, 0x1c,
+	0x40, 0xa5, 0xdf, 0x7f, 0xa5, 0xd3, 0xd0, 0xc7, 0xe0, 0x29, 0x69, 0xc0, 0x1a, 0x65, 0x16, 0x56,
+	0xc2, 0x4e, 0xa0, 0x29, 0xa9, 0xfc, 0x9c, 0x83, 0x5b, 0xa7, 0xde, 0x68, 0xe4, 0xb0, 0xd1, 0x29,
+	0xbd, 0xa4, 0x6e, 0x6a, 0xff, 0x18, 0x8a, 0x2e, 0xa7, 0x05, 0xbe, 0xd6, 0x3a, 0x54, 0x97, 0x17,
+	0x5b, 0x5d, 0xa2, 0xab, 0x4a, 0x42, 0xea, 0x63, 0x24, 0x45, 0x41, 0x93, 0x12, 0x14, 0x4e, 0x9e,
+	0x3e, 0x79, 0x56, 0xbf, 0x41, 0xca, 0x50, 0xec, 0xf6, 0xda, 0x2f, 0x8f, 0xeb, 0x39, 0x7e, 0x7c,
+	0xa1, 0x1f, 0x75, 0x7a, 0xf5, 0xbc, 0xf2, 0xd3, 0x0a, 0x6c, 0x3f, 0xe7, 0x85, 0x3c, 0x0a, 0x02,
+	0x73, 0xf2, 0xc4, 0x0b, 0xce, 0x3b, 0x63, 0xcf, 0xb1, 0xe8, 0x34, 0x89, 0x03, 0x58, 0xf7, 0x83,
+	0x98, 0x51, 0x23, 0x1a, 0x07, 0x34, 0x1c, 0x7b, 0xae, 0x4c, 0xa6, 0xa0, 0xd7, 0x04, 0xfb, 0x45,
+	0xca, 0xe5, 0xc0, 0x6f, 0xe2, 0x30, 0x72, 0x86, 0x0e, 0xb5, 0x0d, 0xea, 0x7b, 0xd6, 0x58, 0x54,
+	0x18, 0x81, 0x53, 0x76, 0x8f, 0x73, 0x39, 0x70, 0xe8, 0x30, 0xd3, 0x75, 0xde, 0x4e, 0x81, 0x2b,
+	0x12, 0x38, 0x65, 0x4b, 0xa0, 0x0e, 0x37, 0x45, 0x8f, 0x0d, 0x93, 0xc7, 0x66, 0xf0, 0x99, 0x0a,
+	0x1b, 0x85, 0xdd, 0x95, 0x07, 0x95, 0xd6, 0xfd, 0xac, 0xca, 0xcc, 0x72, 0x79, 0x8a, 0x70, 0x7d,
+	0xdd, 0x5f, 0xa0, 0x43, 0xf2, 0x1a, 0xd6, 0x1c, 0x66, 0x63, 0x82, 0x61, 0xa3, 0x28, 0x2c, 0x1d,
+	0xfd, 0xb3, 0xa5, 0xab, 0x55, 0x51, 0x4f, 0xa4, 0x8d, 0x1e, 0x8b, 0x82, 0x89, 0x9e, 0x5a, 0x6c,
+	0x3e, 0x86, 0xea, 0xbc, 0x80, 0xd4, 0x61, 0xe5, 0x9c, 0x4e, 0x44, 0xbd, 0xca, 0x3a, 0x3f, 0xe2,
+	0x5c, 0x16, 0x2f, 0x4d, 0x37, 0xa6, 0x49, 0x69, 0x24, 0xf1, 0x38, 0xff, 0x59, 0x4e, 0x79, 0x97,
+	0x87, 0xda, 0x62, 0xf0, 0x84, 0xcc, 0x0f, 0x71, 0x32, 0xc2, 0xc8, 0x9b, 0x0d, 0xaf, 0x2e, 0xce,
+	0x64, 0x03, 0x56, 0x7d, 0x33, 0xa0, 0x2c, 0x4a, 0xea, 0x98, 0x50, 0xcb, 0x3a, 0x52, 0xb8, 0x6e,
+	0x47, 0x8a, 0x4b, 0x3b, 0x82, 0x9e, 0xbe, 0xa3, 0xce, 0x68, 0x1c, 0x35, 0x56, 0xa5, 0x27, 0x49,
+	0x89, 0x7b, 0x81, 0x33, 0x68, 0x58, 0x63, 0x07, 0xe7, 0x63, 0x4d, 0xc8, 0xca, 0x9c, 0xd3, 0xe1,
+	0x0c, 0x6e, 0x5f, 0x88, 0xb1, 0x01, 0x16, 0x65, 0xb6, 0x89, 0x91, 0x96, 0xa4, 0x7d, 0xce, 0xee,
+	0x4e, 0xb9, 0xca, 0x1b, 0x20, 0x5d, 0xfe, 0xd6, 0x3c, 0xa7, 0x34, 0x48, 0x6b, 0x1d, 0xe2, 0xad,
+	0x28, 0x07, 0x29, 0x81, 0xc5, 0xe0, 0x5d, 0x7b, 0x98, 0xd5, 0xb5, 0x2b, 0xea, 0xfa, 0x4c, 0x57,
+	0xf9, 0xad, 0x08, 0x37, 0xaf, 0x00, 0x88, 0x06, 0xb7, 0x5c, 0x27, 0x8c, 0x28, 0xc3, 0x1b, 0x65,
+	0x98, 0xb6, 0x8d, 0xf8, 0xd4, 0x51, 0x59, 0x27, 0x53, 0xd1, 0x51, 0x2a, 0x21, 0x6d, 0x28, 0xdb,
+	0x4e, 0x40, 0x2d, 0xfe, 0x46, 0x89, 0x46, 0xd4, 0x5a, 0xf7, 0x66, 0xf1, 0xe0, 0x41, 0x4d, 0xdf,
+	0x41, 0x95, 0x3b, 0xea, 0xa6, 0x58, 0x7d, 0xa6, 0x46, 0xbe, 0x82, 0x3a, 0x46, 0xcd, 0x24, 0x65,
+	0x84, 0xfc, 0xed, 0x12, 0xdd, 0xab, 0xcd, 0x8f, 0xf6, 0x82, 0xa9, 0xce, 0x14, 0x2e, 0x5f, 0xba,
+	0x75, 0x6b, 0x91, 0x41, 0x36, 0x61, 0xcd, 0x47, 0x77, 0x86, 0x63, 0x8b, 0x36, 0x97, 0x71, 0x0e,
+	0x90, 0x3c, 0xb1, 0xf9, 0x18, 0x52, 0x16, 0x88, 0x96, 0xe2, 0x18, 0xe2, 0x91, 0x3c, 0x83, 0xb2,
+	0x84, 0xb2, 0xa1, 0x27, 0x5a, 0x59, 0x69, 0xb5, 0xae, 0x5d, 0x51, 0x91, 0xd4, 0x09, 0x6a, 0xea,
+	0x25, 0x3f, 0x39, 0x91, 0x2f, 0xa0, 0x22, 0x0c, 0xf2, 0x44, 0xe2, 0x50, 0x4c, 0x40, 0xa5, 0x75,
+	0xf7, 0x8a, 0x49, 0x7c, 0xfd, 0xb9, 0xc9, 0xbe, 0x40, 0xe9, 0xc0, 0x55, 0xe4, 0x99, 0xec, 0x41,
+	0xd5, 0x35, 0x71, 0x44, 0x62, 0xdf, 0xc6, 0x5c, 0xec, 0x64, 0x3e, 0x2a, 0x9c, 0xf7, 0x52, 0xb2,
+	0x9a, 0x7f, 0xe5, 0xa0, 0x94, 0xba, 0x26, 0x9f, 0x43, 0xe9, 0x82, 0x46, 0x26, 0x4a, 0x4c, 0x71,
+	0x3f, 0x2a, 0xad, 0xdd, 0x2c, 0x6f, 0x67, 0x88, 0xeb, 0x22, 0x4e, 0x9f, 0x6a, 0x90, 0x6d, 0xcc,
+	0x9f, 0xdf, 0x35, 0xcb, 0x73, 0x43, 0xec, 0x20, 0x6f, 0xf4, 0x8c, 0x81, 0x6b, 0xa2, 0x32, 0x34,
+	0x63, 0x17, 0xc7, 0xd9, 0x8b, 0xa7, 0x97, 0x0a, 0x04, 0xab, 0xc3, 0x39, 0xe4, 0x21, 0xd4, 0x53,
+	0xb4, 0x71, 0x49, 0x83, 0x90, 0xcf, 0x81, 0x2c, 0xf9, 0x7a, 0xca, 0xff, 0x5a, 0xb2, 0xc9, 0x3e,
+	0x7c, 0x80, 0x7b, 0x8e, 0x45, 0x53, 0x9c, 0xec, 0x42, 0x55, 0x30, 0x53, 0x10, 0x26, 0x2f, 0xaa,
+	0xe7, 0x62, 0x9e, 0xcc, 0x9a, 0x24, 0x97, 0x4b, 0x54, 0xf4, 0x54, 0xb2, 0x5a, 0x7f, 0xae, 0xe2,
+	0xf3, 0xcd, 0x3b, 0x41, 0x7e, 0xcc, 0x41, 0xed, 0x98, 0x46, 0x73, 0x4b, 0x8f, 0x3c, 0xca, 0xea,
+	0xdd, 0xd5, 0xcd, 0xd8, 0xdc, 0xcf, 0xc2, 0xce, 0x6d, 0x2e, 0x65, 0xef, 0xdd, 0xef, 0x7f, 0xfc,
+	0x9a, 0xdf, 0x22, 0x1f, 0x6a, 0x0b, 0x5b, 0x5d, 0xfc, 0x07, 0x68, 0x62, 0x58, 0xc9, 0xf7, 0x50,
+	0xe2, 0x51, 0xf0, 0xdd, 0x47, 0xee, 0x65, 0xfa, 0x9f, 0x5b, 0x9e, 0xff, 0x83, 0x67, 0xb1, 0x69,
+	0xc9, 0x0f, 0xb0, 0xde, 0xa7, 0xd1, 0xfc, 0x0a, 0x24, 0x1f, 0xfd, 0x8b, 0x45, 0xd9, 0xdc, 0x50,
+	0xe5, 0xff, 0x84, 0x9a, 0xfe, 0x4f, 0xa8, 0x3d, 0xfe, 0x3f, 0xa1, 0xec, 0x0b, 0xd7, 0x77, 0x94,
+	0xad, 0x65, 0xae, 0x5d, 0x69, 0x88, 0xfc, 0x92, 0x83, 0x4d, 0xcc, 0x7b, 0xd9, 0x72, 0x20, 0x19,
+	0x86, 0x9b, 0x9f, 0xfe, 0x97, 0x15, 0xa3, 0xdc, 0x17, 0xe1, 0xec, 0x92, 0xbb, 0xcb, 0xc2, 0x19,
+	0x22, 0xde, 0x92, 0x5e, 0x03, 0x28, 0x9f, 0xe2, 0x1b, 0xc5, 0x6f, 0x46, 0x98, 0x19, 0xc2, 0xa3,
+	0x6b, 0xdf, 0xee, 0xf0, 0xfd, 0x2d, 0xf0, 0x85, 0x9b, 0xb7, 0xb0, 0xc6, 0x8b, 0x80, 0x67, 0xa2,
+	0xbc, 0xe7, 0xe5, 0x4b, 0x2b, 0x7e, 0xfd, 0xd7, 0x5a, 0xd9, 0x15, 0xce, 0x9b, 0xa4, 0x91, 0xe5,
+	0x9c, 0x78, 0x70, 0x1b, 0xb7, 0x2b, 0x0d, 0xce, 0x4c, 0x07, 0x3f, 0xcc, 0x64, 0x16, 0x3d, 0xe3,
+	0xdb, 0x32, 0x2b, 0xf5, 0xac, 0x76, 0x1f, 0x08, 0x4f, 0x7b, 0xca, 0xce, 0x32, 0x4f, 0x17, 0x33,
+	0xe3, 0x83, 0x55, 0xa1, 0xf8, 0xc9, 0xdf, 0xa0, 0x31, 0xcd, 0x1c, 0x1b, 0x0b, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -741,6 +741,7 @@ type DebugClient interface {
 	GetProtoArrayForkChoice(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ProtoArrayForkChoiceResponse, error)
 	ListPeers(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*DebugPeerResponses, error)
 	GetPeer(ctx context.Context, in *v1alpha1.PeerRequest, opts ...grpc.CallOption) (*DebugPeerResponse, error)
+	EnterMaintenanceMode(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error)
 }
 
 type debugClient struct {
@@ -805,6 +806,15 @@ func (c *debugClient) GetPeer(ctx context.Context, in *v1alpha1.PeerRequest, opt
 	return out, nil
 }
 
+func (c *debugClient) EnterMaintenanceMode(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/ethereum.beacon.rpc.v1.Debug/EnterMaintenanceMode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DebugServer is the server API for Debug service.
 type DebugServer interface {
 	GetBeaconState(context.Context, *BeaconStateRequest) (*SSZResponse, error)
@@ -813,6 +823,7 @@ type DebugServer interface {
 	GetProtoArrayForkChoice(context.Context, *empty.Empty) (*ProtoArrayForkChoiceResponse, error)
 	ListPeers(context.Context, *empty.Empty) (*DebugPeerResponses, error)
 	GetPeer(context.Context, *v1alpha1.PeerRequest) (*DebugPeerResponse, error)
+	EnterMaintenanceMode(context.Context, *empty.Empty) (*empty.Empty, error)
 }
 
 // UnimplementedDebugServer can be embedded to have forward compatible implementations.
@@ -837,6 +848,9 @@ func (*UnimplementedDebugServer) ListPeers(ctx context.Context, req *empty.Empty
 func (*UnimplementedDebugServer) GetPeer(ctx context.Context, req *v1alpha1.PeerRequest) (*DebugPeerResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetPeer not implemented")
 }
+func (*UnimplementedDebugServer) EnterMaintenanceMode(ctx context.Context, req *empty.Empty) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnterMaintenanceMode not implemented")
+}
 
 func RegisterDebugServer(s *grpc.Server, srv DebugServer) {
 	s.RegisterService(&_Debug_serviceDesc, srv)
@@ -950,6 +964,24 @@ func _Debug_GetPeer_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Debug_EnterMaintenanceMode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebugServer).EnterMaintenanceMode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ethereum.beacon.rpc.v1.Debug/EnterMaintenanceMode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebugServer).EnterMaintenanceMode(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Debug_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "ethereum.beacon.rpc.v1.Debug",
 	HandlerType: (*DebugServer)(nil),
@@ -978,6 +1010,10 @@ var _Debug_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetPeer",
 			Handler:    _Debug_GetPeer_Handler,
 		},
+		{
+			MethodName: "EnterMaintenanceMode",
+			Handler:    _Debug_EnterMaintenanceMode_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/beacon/rpc/v1/debug.proto",
@@ -13,6 +13,16 @@ func SecretKeyFromBytes(privKey []byte) (SecretKey, error) {
 	return herumi.SecretKeyFromBytes(privKey)
 }
 
+// SecretKeyMemorySize is the size of the memory a secret key is deserialized into by
+// SecretKeyFromBytesInto.
+var SecretKeyMemorySize = herumi.SecretKeyMemorySize
+
+// SecretKeyFromBytesInto creates a BLS private key from a BigEndian byte slice, deserialized into
+// the given memory, such as memory locked against being swapped to disk.
+func SecretKeyFromBytesInto(mem []byte, privKey []byte) (SecretKey, error) {
+	return herumi.SecretKeyFromBytesInto(mem, privKey)
+}
+
 // PublicKeyFromBytes creates a BLS public key from a  BigEndian byte slice.
 func PublicKeyFromBytes(pubKey []byte) (PublicKey, error) {
 	return herumi.PublicKeyFromBytes(pubKey)
@@ -2,6 +2,7 @@ package herumi
 
 import (
 	"fmt"
+	"unsafe"
 
 	bls12 "github.com/herumi/bls-eth-go-binary/bls"
 	"github.com/pkg/errors"
@@ -35,6 +36,28 @@ func SecretKeyFromBytes(privKey []byte) (iface.SecretKey, error) {
 	return &bls12SecretKey{p: secKey}, err
 }
 
+// SecretKeyMemorySize is the size of the memory a secret key is deserialized into by
+// SecretKeyFromBytesInto.
+var SecretKeyMemorySize = int(unsafe.Sizeof(bls12.SecretKey{}))
+
+// SecretKeyFromBytesInto creates a BLS private key from a BigEndian byte slice, deserialized into
+// the given memory rather than memory of the Go heap, such as memory locked against being swapped
+// to disk. The memory must be at least SecretKeyMemorySize bytes, 8-byte aligned and outlive the
+// key, and the key is signed with in place, without being copied.
+func SecretKeyFromBytesInto(mem []byte, privKey []byte) (iface.SecretKey, error) {
+	if len(privKey) != params.BeaconConfig().BLSSecretKeyLength {
+		return nil, fmt.Errorf("secret key must be %d bytes", params.BeaconConfig().BLSSecretKeyLength)
+	}
+	if len(mem) < SecretKeyMemorySize || uintptr(unsafe.Pointer(&mem[0]))%8 != 0 {
+		return nil, fmt.Errorf("secret key memory must be at least %d bytes and 8-byte aligned", SecretKeyMemorySize)
+	}
+	secKey := (*bls12.SecretKey)(unsafe.Pointer(&mem[0]))
+	if err := secKey.Deserialize(privKey); err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal bytes into secret key")
+	}
+	return &bls12SecretKey{p: secKey}, nil
+}
+
 // PublicKey obtains the public key corresponding to the BLS secret key.
 func (s *bls12SecretKey) PublicKey() iface.PublicKey {
 	return &PublicKey{p: s.p.GetPublicKey()}
@@ -88,3 +88,17 @@ func TestSerialize(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestSecretKeyFromBytesInto(t *testing.T) {
+	secretKey := herumi.RandKey()
+	mem := make([]byte, herumi.SecretKeyMemorySize)
+	key, err := herumi.SecretKeyFromBytesInto(mem, secretKey.Marshal())
+	require.NoError(t, err)
+	assert.DeepEqual(t, secretKey.Marshal(), key.Marshal())
+	msg := []byte("hello world")
+	assert.DeepEqual(t, secretKey.Sign(msg).Marshal(), key.Sign(msg).Marshal())
+	assert.Equal(t, false, bytes.Equal(make([]byte, len(mem)), mem), "Expected key to be deserialized into memory")
+
+	_, err = herumi.SecretKeyFromBytesInto(mem[:len(mem)-1], secretKey.Marshal())
+	assert.ErrorContains(t, "8-byte aligned", err)
+}
@@ -3,7 +3,9 @@
 package depositutil
 
 import (
+	"encoding/hex"
 	"fmt"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/pkg/errors"
@@ -38,9 +40,22 @@ func DepositInput(
 	withdrawalKey bls.SecretKey,
 	amountInGwei uint64,
 ) (*ethpb.Deposit_Data, [32]byte, error) {
+	return DepositInputWithCredentials(depositKey, WithdrawalCredentialsHash(withdrawalKey), amountInGwei)
+}
+
+// DepositInputWithCredentials is like DepositInput, for withdrawal credentials computed
+// elsewhere, such as from the withdrawal public key of a key held offline.
+func DepositInputWithCredentials(
+	depositKey bls.SecretKey,
+	withdrawalCredentials []byte,
+	amountInGwei uint64,
+) (*ethpb.Deposit_Data, [32]byte, error) {
+	if len(withdrawalCredentials) != 32 {
+		return nil, [32]byte{}, fmt.Errorf("withdrawal credentials must be 32 bytes, got %d", len(withdrawalCredentials))
+	}
 	di := &ethpb.Deposit_Data{
 		PublicKey:             depositKey.PublicKey().Marshal(),
-		WithdrawalCredentials: WithdrawalCredentialsHash(withdrawalKey),
+		WithdrawalCredentials: withdrawalCredentials,
 		Amount:                amountInGwei,
 	}
 
@@ -71,15 +86,91 @@ func DepositInput(
 	return di, dr, nil
 }
 
+// DepositDataJSON is the canonical JSON representation of deposit data as
+// consumed by eth2 tooling. Byte fields are hex-encoded without a 0x prefix.
+type DepositDataJSON struct {
+	PubKey                string `json:"pubkey"`
+	WithdrawalCredentials string `json:"withdrawal_credentials"`
+	Amount                uint64 `json:"amount"`
+	Signature             string `json:"signature"`
+	DepositMessageRoot    string `json:"deposit_message_root"`
+	DepositDataRoot       string `json:"deposit_data_root"`
+	ForkVersion           string `json:"fork_version,omitempty"`
+}
+
+// DepositDataJSONFromProto converts deposit data into its canonical JSON form,
+// computing the deposit message root and the deposit data root along the way.
+func DepositDataJSONFromProto(depositData *ethpb.Deposit_Data) (*DepositDataJSON, error) {
+	messageRoot, err := ssz.SigningRoot(depositData)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not compute deposit message root")
+	}
+	dataRoot, err := ssz.HashTreeRoot(depositData)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not compute deposit data root")
+	}
+	return &DepositDataJSON{
+		PubKey:                hex.EncodeToString(depositData.PublicKey),
+		WithdrawalCredentials: hex.EncodeToString(depositData.WithdrawalCredentials),
+		Amount:                depositData.Amount,
+		Signature:             hex.EncodeToString(depositData.Signature),
+		DepositMessageRoot:    hex.EncodeToString(messageRoot[:]),
+		DepositDataRoot:       hex.EncodeToString(dataRoot[:]),
+	}, nil
+}
+
+// DepositDataFromJSON converts canonical JSON deposit data, such as the entries of the
+// deposit_data-*.json files written by the eth2.0-deposit-cli, back into deposit data.
+// The deposit data root, if present, must match the decoded deposit data.
+func DepositDataFromJSON(enc *DepositDataJSON) (*ethpb.Deposit_Data, error) {
+	pubKey, err := hex.DecodeString(strings.TrimPrefix(enc.PubKey, "0x"))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode public key")
+	}
+	withdrawalCredentials, err := hex.DecodeString(strings.TrimPrefix(enc.WithdrawalCredentials, "0x"))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode withdrawal credentials")
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(enc.Signature, "0x"))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode signature")
+	}
+	depositData := &ethpb.Deposit_Data{
+		PublicKey:             pubKey,
+		WithdrawalCredentials: withdrawalCredentials,
+		Amount:                enc.Amount,
+		Signature:             signature,
+	}
+	if enc.DepositDataRoot == "" {
+		return depositData, nil
+	}
+	dataRoot, err := ssz.HashTreeRoot(depositData)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not compute deposit data root")
+	}
+	if hex.EncodeToString(dataRoot[:]) != strings.TrimPrefix(enc.DepositDataRoot, "0x") {
+		return nil, fmt.Errorf("deposit data root %s does not match deposit data for public key %s", enc.DepositDataRoot, enc.PubKey)
+	}
+	return depositData, nil
+}
+
 // WithdrawalCredentialsHash forms a 32 byte hash of the withdrawal public
 // address.
 //
 // The specification is as follows:
-//   withdrawal_credentials[:1] == BLS_WITHDRAWAL_PREFIX_BYTE
-//   withdrawal_credentials[1:] == hash(withdrawal_pubkey)[1:]
+//
+//	withdrawal_credentials[:1] == BLS_WITHDRAWAL_PREFIX_BYTE
+//	withdrawal_credentials[1:] == hash(withdrawal_pubkey)[1:]
+//
 // where withdrawal_credentials is of type bytes32.
 func WithdrawalCredentialsHash(withdrawalKey bls.SecretKey) []byte {
-	h := hashutil.Hash(withdrawalKey.PublicKey().Marshal())
+	return WithdrawalCredentialsFromPublicKey(withdrawalKey.PublicKey())
+}
+
+// WithdrawalCredentialsFromPublicKey forms the withdrawal credentials of a withdrawal
+// public key, as WithdrawalCredentialsHash does for its secret key.
+func WithdrawalCredentialsFromPublicKey(withdrawalPubKey bls.PublicKey) []byte {
+	h := hashutil.Hash(withdrawalPubKey.Marshal())
 	return append([]byte{params.BeaconConfig().BLSWithdrawalPrefixByte}, h[1:]...)[:32]
 }
 
@@ -122,8 +213,17 @@ func GenerateDepositTransaction(
 	validatingKey bls.SecretKey,
 	withdrawalKey bls.SecretKey,
 ) (*types.Transaction, *ethpb.Deposit_Data, error) {
-	depositData, depositRoot, err := DepositInput(
-		validatingKey, withdrawalKey, params.BeaconConfig().MaxEffectiveBalance,
+	return GenerateDepositTransactionWithCredentials(validatingKey, WithdrawalCredentialsHash(withdrawalKey))
+}
+
+// GenerateDepositTransactionWithCredentials is like GenerateDepositTransaction, for
+// withdrawal credentials computed elsewhere.
+func GenerateDepositTransactionWithCredentials(
+	validatingKey bls.SecretKey,
+	withdrawalCredentials []byte,
+) (*types.Transaction, *ethpb.Deposit_Data, error) {
+	depositData, depositRoot, err := DepositInputWithCredentials(
+		validatingKey, withdrawalCredentials, params.BeaconConfig().MaxEffectiveBalance,
 	)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "could not generate deposit input")
@@ -1,6 +1,7 @@
 package depositutil_test
 
 import (
+	"encoding/hex"
 	"testing"
 
 	"github.com/prysmaticlabs/go-ssz"
@@ -39,6 +40,23 @@ func TestDepositInput_GeneratesPb(t *testing.T) {
 	assert.Equal(t, true, sig.Verify(k1.PublicKey(), root[:]))
 }
 
+func TestDepositInputWithCredentials(t *testing.T) {
+	k1 := bls.RandKey()
+	k2 := bls.RandKey()
+
+	credentials := depositutil.WithdrawalCredentialsFromPublicKey(k2.PublicKey())
+	assert.DeepEqual(t, depositutil.WithdrawalCredentialsHash(k2), credentials)
+	result, root, err := depositutil.DepositInputWithCredentials(k1, credentials, 0)
+	require.NoError(t, err)
+	expected, expectedRoot, err := depositutil.DepositInput(k1, k2, 0)
+	require.NoError(t, err)
+	assert.DeepEqual(t, expected, result)
+	assert.Equal(t, expectedRoot, root)
+
+	_, _, err = depositutil.DepositInputWithCredentials(k1, credentials[1:], 0)
+	assert.ErrorContains(t, "withdrawal credentials must be 32 bytes", err)
+}
+
 func TestVerifyDepositSignature_ValidSig(t *testing.T) {
 	deposits, _, err := testutil.DeterministicDepositsAndKeys(1)
 	if err != nil {
@@ -79,3 +97,38 @@ func TestVerifyDepositSignature_InvalidSig(t *testing.T) {
 		t.Fatal("Deposit Verification succeeds with a invalid signature")
 	}
 }
+
+func TestDepositDataJSONFromProto(t *testing.T) {
+	k1 := bls.RandKey()
+	k2 := bls.RandKey()
+	depositData, depositRoot, err := depositutil.DepositInput(k1, k2, params.BeaconConfig().MaxEffectiveBalance)
+	require.NoError(t, err)
+
+	enc, err := depositutil.DepositDataJSONFromProto(depositData)
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(k1.PublicKey().Marshal()), enc.PubKey)
+	assert.Equal(t, hex.EncodeToString(depositData.WithdrawalCredentials), enc.WithdrawalCredentials)
+	assert.Equal(t, params.BeaconConfig().MaxEffectiveBalance, enc.Amount)
+	assert.Equal(t, hex.EncodeToString(depositData.Signature), enc.Signature)
+	assert.Equal(t, hex.EncodeToString(depositRoot[:]), enc.DepositDataRoot)
+	messageRoot, err := ssz.SigningRoot(depositData)
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(messageRoot[:]), enc.DepositMessageRoot)
+}
+
+func TestDepositDataFromJSON(t *testing.T) {
+	k1 := bls.RandKey()
+	k2 := bls.RandKey()
+	depositData, _, err := depositutil.DepositInput(k1, k2, params.BeaconConfig().MaxEffectiveBalance)
+	require.NoError(t, err)
+	enc, err := depositutil.DepositDataJSONFromProto(depositData)
+	require.NoError(t, err)
+
+	decoded, err := depositutil.DepositDataFromJSON(enc)
+	require.NoError(t, err)
+	assert.DeepEqual(t, depositData, decoded)
+
+	enc.Amount--
+	_, err = depositutil.DepositDataFromJSON(enc)
+	assert.ErrorContains(t, "does not match deposit data", err)
+}
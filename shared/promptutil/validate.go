@@ -2,6 +2,7 @@ package promptutil
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
 	"unicode"
 
@@ -11,11 +12,25 @@ import (
 const (
 	// Constants for passwords.
 	minPasswordLength = 8
-	// Min password score of 3 out of 5 based on the https://github.com/nbutton23/zxcvbn-go
+	// Min password score of 3 out of 4 based on the https://github.com/nbutton23/zxcvbn-go
 	// library for strong-entropy password computation.
 	minPasswordScore = 3
 )
 
+// passwordUserInputs are words a validator password is likely to be made of, which the strength
+// estimate treats as dictionary words.
+var passwordUserInputs = []string{
+	"prysm",
+	"prysmatic",
+	"validator",
+	"ethereum",
+	"eth2",
+	"wallet",
+	"keystore",
+	"staking",
+	"password",
+}
+
 // NotEmpty is a validation function to make sure the input given isn't empty and is valid unicode.
 func NotEmpty(input string) error {
 	if input == "" {
@@ -93,10 +108,19 @@ func ValidatePasswordInput(input string) error {
 			"password must have more than 8 characters, at least 1 special character, and 1 number",
 		)
 	}
-	strength := strongPasswords.PasswordStrength(input, nil)
+	return ValidatePasswordStrength(input)
+}
+
+// ValidatePasswordStrength rejects passwords which are easy to guess by the zxcvbn strength
+// estimate, which looks for dictionary words, names, keyboard patterns, repeats, sequences and
+// dates rather than counting character classes.
+func ValidatePasswordStrength(input string) error {
+	strength := strongPasswords.PasswordStrength(input, passwordUserInputs)
 	if strength.Score < minPasswordScore {
-		return errors.New(
-			"password is too easy to guess, try a stronger password",
+		return fmt.Errorf(
+			"password is too easy to guess, scoring %d out of 4 with an estimated time to crack of %s, try a stronger password",
+			strength.Score,
+			strength.CrackTimeDisplay,
 		)
 	}
 	return nil
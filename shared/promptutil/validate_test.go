@@ -1,6 +1,9 @@
 package promptutil
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestValidatePasswordInput(t *testing.T) {
 	tests := []struct {
@@ -38,6 +41,16 @@ func TestValidatePasswordInput(t *testing.T) {
 			input:   "x*329293@aAJSD i22903saj",
 			wantErr: false,
 		},
+		{
+			name:    "dictionary word and sequence",
+			input:   "Password123!",
+			wantErr: true,
+		},
+		{
+			name:    "validator words",
+			input:   "prysmvalidator1!",
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -83,3 +96,13 @@ func TestIsValidUnicode(t *testing.T) {
 		})
 	}
 }
+
+func TestValidatePasswordStrength(t *testing.T) {
+	err := ValidatePasswordStrength("qwerty1234")
+	if err == nil || !strings.Contains(err.Error(), "too easy to guess") {
+		t.Errorf("ValidatePasswordStrength() error = %v, want too easy to guess", err)
+	}
+	if err := ValidatePasswordStrength("correct horse battery staple 97!"); err != nil {
+		t.Errorf("ValidatePasswordStrength() error = %v, want nil", err)
+	}
+}
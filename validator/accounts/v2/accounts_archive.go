@@ -0,0 +1,216 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/urfave/cli/v2"
+)
+
+// ArchiveAccounts moves the selected --accounts of a non-HD wallet, or with --exited every account
+// which exit was submitted with accounts-v2 exit, to the cold archive of the wallet. Archived
+// accounts are no longer loaded by the keymanager, so their keystores are never decrypted, until
+// they are restored with accounts-v2 unarchive.
+func ArchiveAccounts(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	wallet, err := openDirectWallet(cliCtx)
+	if err != nil {
+		return err
+	}
+	accounts, err := wallet.accountPubKeys(ctx)
+	if err != nil {
+		return err
+	}
+	if len(accounts) == 0 {
+		return errors.New("wallet has no accounts to archive")
+	}
+	exits, err := wallet.readAccountExits()
+	if err != nil {
+		return err
+	}
+	var toArchive []string
+	switch {
+	case cliCtx.Bool(flags.ArchiveExitedFlag.Name):
+		for name, pubKey := range accounts {
+			if exits.of(pubKey) != nil {
+				toArchive = append(toArchive, name)
+			}
+		}
+		sort.Strings(toArchive)
+	case cliCtx.IsSet(flags.AccountsFlag.Name):
+		names, pubKeys := sortedAccounts(accounts)
+		toArchive, err = selectAccounts(cliCtx, names, pubKeys)
+		if err != nil {
+			return errors.Wrap(err, "could not select accounts")
+		}
+	default:
+		return fmt.Errorf("select the accounts to archive with --%s or --%s", flags.AccountsFlag.Name, flags.ArchiveExitedFlag.Name)
+	}
+	if len(toArchive) == 0 {
+		return errors.New("no accounts selected to archive")
+	}
+	archivedPubKeys := make([][48]byte, len(toArchive))
+	for i, name := range toArchive {
+		archivedPubKeys[i] = accounts[name]
+	}
+	if err := wallet.checkApproval(cliCtx, approvalActionArchiveAccounts, pubKeySubjects(archivedPubKeys)); err != nil {
+		return err
+	}
+	if err := wallet.checkTOTP(cliCtx, "archiving accounts"); err != nil {
+		return err
+	}
+	for _, name := range toArchive {
+		if exits.of(accounts[name]) == nil {
+			log.Warnf("Account %s has no recorded exit, its validator will no longer be run once archived", name)
+		}
+		if err := wallet.archiveAccount(name); err != nil {
+			return err
+		}
+		fmt.Printf("Archived account %s\n", au.BrightGreen(name).Bold())
+	}
+	return nil
+}
+
+// UnarchiveAccounts moves the selected --accounts of the cold archive of a non-HD wallet back
+// into the wallet.
+func UnarchiveAccounts(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	wallet, err := openDirectWallet(cliCtx)
+	if err != nil {
+		return err
+	}
+	accounts, err := wallet.archivedAccountPubKeys(ctx)
+	if err != nil {
+		return err
+	}
+	if len(accounts) == 0 {
+		return errors.New("wallet has no archived accounts")
+	}
+	if !cliCtx.IsSet(flags.AccountsFlag.Name) {
+		return fmt.Errorf("select the accounts to restore from the archive with --%s", flags.AccountsFlag.Name)
+	}
+	names, pubKeys := sortedAccounts(accounts)
+	toRestore, err := selectAccounts(cliCtx, names, pubKeys)
+	if err != nil {
+		return errors.Wrap(err, "could not select accounts")
+	}
+	for _, name := range toRestore {
+		if err := wallet.unarchiveAccount(name); err != nil {
+			return err
+		}
+		fmt.Printf("Restored account %s from the archive\n", au.BrightGreen(name).Bold())
+	}
+	return nil
+}
+
+// Opens the wallet of the command, which must be a non-HD wallet.
+func openDirectWallet(cliCtx *cli.Context) (*Wallet, error) {
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return nil, errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return nil, errors.Wrap(err, "could not open wallet")
+	}
+	if wallet.KeymanagerKind() != v2keymanager.Direct {
+		return nil, fmt.Errorf("only non-HD wallets are supported, not %s wallets", wallet.KeymanagerKind())
+	}
+	// Commands managing the accounts of non-HD wallets work on their directories.
+	if wallet.storageURL != "" {
+		return nil, fmt.Errorf("only wallets stored on disk are supported, not wallets stored in %s", wallet.storageURL)
+	}
+	return wallet, nil
+}
+
+// Moves the directory of an account into the archive of the wallet.
+func (w *Wallet) archiveAccount(accountName string) error {
+	if err := w.checkWritable(); err != nil {
+		return err
+	}
+	if err := w.checkOnDisk("archiving accounts"); err != nil {
+		return err
+	}
+	archiveDir := filepath.Join(w.AccountsDir(), archiveDirName)
+	if err := os.MkdirAll(archiveDir, DirectoryPermissions); err != nil {
+		return errors.Wrap(err, "could not create archive directory")
+	}
+	archivedPath := filepath.Join(archiveDir, accountName)
+	if _, err := os.Stat(archivedPath); err == nil {
+		return fmt.Errorf("account %s is already in the archive", accountName)
+	}
+	if err := os.Rename(filepath.Join(w.AccountsDir(), accountName), archivedPath); err != nil {
+		return errors.Wrapf(err, "could not move account %s to the archive", accountName)
+	}
+	return w.updateManifest(context.Background(), nil, []string{accountName}, nil)
+}
+
+// Moves the directory of an archived account back into the wallet.
+func (w *Wallet) unarchiveAccount(accountName string) error {
+	if err := w.checkWritable(); err != nil {
+		return err
+	}
+	if err := w.checkOnDisk("unarchiving accounts"); err != nil {
+		return err
+	}
+	accountPath := filepath.Join(w.AccountsDir(), accountName)
+	if _, err := os.Stat(accountPath); err == nil {
+		return fmt.Errorf("account %s is already in the wallet", accountName)
+	}
+	if err := os.Rename(filepath.Join(w.AccountsDir(), archiveDirName, accountName), accountPath); err != nil {
+		return errors.Wrapf(err, "could not move account %s out of the archive", accountName)
+	}
+	return w.updateManifest(context.Background(), nil, nil, []string{accountName})
+}
+
+// Reads the public keys of the archived accounts of the wallet from their keystores, by
+// account name.
+func (w *Wallet) archivedAccountPubKeys(ctx context.Context) (map[string][48]byte, error) {
+	archiveDir := filepath.Join(w.AccountsDir(), archiveDirName)
+	entries, err := ioutil.ReadDir(archiveDir)
+	if os.IsNotExist(err) {
+		return make(map[string][48]byte), nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list archived accounts")
+	}
+	pubKeys := make(map[string][48]byte, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(archiveDir, entry.Name(), w.keystoreFileGlob()))
+		if err != nil || len(matches) == 0 {
+			return nil, fmt.Errorf("no keystore found for archived account %s", entry.Name())
+		}
+		encoded, err := ioutil.ReadFile(matches[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read keystore of archived account %s", entry.Name())
+		}
+		pubKey, err := keystorePubKey(encoded)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read public key of archived account %s", entry.Name())
+		}
+		pubKeys[entry.Name()] = pubKey
+	}
+	return pubKeys, nil
+}
+
+// Splits accounts by name into their names in alphabetical order and the matching public keys.
+func sortedAccounts(accounts map[string][48]byte) ([]string, [][48]byte) {
+	names := make([]string, 0, len(accounts))
+	for name := range accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pubKeys := make([][48]byte, len(names))
+	for i, name := range names {
+		pubKeys[i] = accounts[name]
+	}
+	return names, pubKeys
+}
@@ -0,0 +1,72 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestArchiveAccounts(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFilePath,
+		keymanagerKind:     v2keymanager.Direct,
+	}
+	wallet, err := NewWallet(setupWalletCtx(t, cfg), v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	names := make([]string, 3)
+	pubKeys := make([][48]byte, 3)
+	for i := range names {
+		names[i], err = keymanager.CreateAccount(ctx, password)
+		require.NoError(t, err)
+		pubKeys[i], err = keymanager.PublicKeyForAccount(names[i])
+		require.NoError(t, err)
+	}
+	exits, err := wallet.readAccountExits()
+	require.NoError(t, err)
+	exits.Accounts[fmt.Sprintf("%#x", pubKeys[0])] = &accountExit{ValidatorIndex: 3, Epoch: 10}
+	require.NoError(t, wallet.writeAccountExits(ctx, exits))
+
+	assert.ErrorContains(t, "select the accounts to archive", ArchiveAccounts(setupWalletCtx(t, cfg)))
+	cfg.archiveExited = true
+	require.NoError(t, ArchiveAccounts(setupWalletCtx(t, cfg)))
+	cfg.archiveExited = false
+	cfg.accountsToExport = names[1]
+	require.NoError(t, ArchiveAccounts(setupWalletCtx(t, cfg)))
+
+	// Archived accounts are no longer accounts of the wallet, nor loaded by the keymanager.
+	accountNames, err := wallet.ListDirs()
+	require.NoError(t, err)
+	assert.DeepEqual(t, []string{names[2]}, accountNames)
+	keymanager, err = direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	validatingKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	assert.DeepEqual(t, [][48]byte{pubKeys[2]}, validatingKeys)
+	archived, err := wallet.archivedAccountPubKeys(ctx)
+	require.NoError(t, err)
+	assert.DeepEqual(t, map[string][48]byte{names[0]: pubKeys[0], names[1]: pubKeys[1]}, archived)
+
+	cfg.accountsToExport = fmt.Sprintf("%#x", pubKeys[1])
+	require.NoError(t, UnarchiveAccounts(setupWalletCtx(t, cfg)))
+	accounts, err := wallet.accountPubKeys(ctx)
+	require.NoError(t, err)
+	assert.DeepEqual(t, map[string][48]byte{names[1]: pubKeys[1], names[2]: pubKeys[2]}, accounts)
+	archived, err = wallet.archivedAccountPubKeys(ctx)
+	require.NoError(t, err)
+	assert.DeepEqual(t, map[string][48]byte{names[0]: pubKeys[0]}, archived)
+}
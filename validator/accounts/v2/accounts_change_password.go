@@ -0,0 +1,86 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/urfave/cli/v2"
+)
+
+const currentAccountPasswordPromptText = "Current password of account %s"
+
+// ChangeAccountPassword re-encrypts the keystores of the selected --accounts of a non-HD wallet
+// with a new password, given by --new-account-password-file or entered at the prompt, and updates
+// their password files. Each keystore must first decrypt with the current password of its account,
+// given by --account-password-file or entered at the prompt. The keys of the accounts are unchanged.
+func ChangeAccountPassword(cliCtx *cli.Context) error {
+	ctx := withJournalAction(context.Background(), journalActionPasswordChanged)
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	if wallet.KeymanagerKind() != v2keymanager.Direct {
+		return errors.New("only passwords of accounts of non-HD wallets can be changed")
+	}
+	keymanager, err := wallet.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+	if err != nil {
+		return errors.Wrap(err, "could not initialize keymanager")
+	}
+	km, ok := keymanager.(*direct.Keymanager)
+	if !ok {
+		return errors.New("could not assert keymanager interface to concrete type")
+	}
+	accounts, err := wallet.accountPubKeys(ctx)
+	if err != nil {
+		return err
+	}
+	if len(accounts) == 0 {
+		return errors.New("wallet has no accounts")
+	}
+	names, pubKeys := sortedAccounts(accounts)
+	selected, err := selectAccounts(cliCtx, names, pubKeys)
+	if err != nil {
+		return errors.Wrap(err, "could not select accounts")
+	}
+	if len(selected) == 0 {
+		return errors.New("no accounts selected")
+	}
+
+	oldPasswords := make(map[string]string, len(selected))
+	for _, name := range selected {
+		oldPassword, err := inputWeakPassword(
+			cliCtx,
+			flags.AccountPasswordFileFlag,
+			fmt.Sprintf(currentAccountPasswordPromptText, name),
+		)
+		if err != nil {
+			return errors.Wrap(err, "could not input current password")
+		}
+		err = wallet.checkPasswordForAccount(name, oldPassword)
+		if err != nil && strings.Contains(err.Error(), "invalid checksum") {
+			return fmt.Errorf("invalid current password for account %s", name)
+		}
+		if err != nil {
+			return err
+		}
+		oldPasswords[name] = oldPassword
+	}
+	newPassword, err := inputPassword(cliCtx, flags.NewAccountPasswordFileFlag, newAccountPasswordPromptText, confirmPass)
+	if err != nil {
+		return errors.Wrap(err, "could not input new password")
+	}
+	for _, name := range selected {
+		if err := km.ChangePassword(ctx, name, oldPasswords[name], newPassword); err != nil {
+			return err
+		}
+		fmt.Printf("Changed password of account %s\n", au.BrightGreen(name).Bold())
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package v2
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestChangeAccountPassword(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	newPassword := "Sh1nyN3wPassw0rd!"
+	newPasswordFilePath := filepath.Join(filepath.Dir(passwordFilePath), "new-password.txt")
+	require.NoError(t, ioutil.WriteFile(newPasswordFilePath, []byte(newPassword), os.ModePerm))
+	cfg := &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		walletPasswordFile:  passwordFilePath,
+		accountPasswordFile: passwordFilePath,
+		newAccountPassword:  newPasswordFilePath,
+		keymanagerKind:      v2keymanager.Direct,
+	}
+	wallet, err := NewWallet(setupWalletCtx(t, cfg), v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	names := make([]string, 2)
+	pubKeys := make([][48]byte, 2)
+	for i := range names {
+		names[i], err = keymanager.CreateAccount(ctx, password)
+		require.NoError(t, err)
+		pubKeys[i], err = keymanager.PublicKeyForAccount(names[i])
+		require.NoError(t, err)
+	}
+	keystoreFileName, err := wallet.FileNameAtPath(ctx, names[0], direct.KeystoreFileName)
+	require.NoError(t, err)
+
+	cfg.accountsToExport = names[0]
+	require.NoError(t, ChangeAccountPassword(setupWalletCtx(t, cfg)))
+	require.NoError(t, wallet.checkPasswordForAccount(names[0], newPassword))
+	assert.ErrorContains(t, "invalid checksum", wallet.checkPasswordForAccount(names[0], password))
+	storedPassword, err := wallet.ReadPasswordFromDisk(ctx, names[0]+direct.PasswordFileSuffix)
+	require.NoError(t, err)
+	assert.Equal(t, newPassword, storedPassword)
+	changedFileName, err := wallet.FileNameAtPath(ctx, names[0], direct.KeystoreFileName)
+	require.NoError(t, err)
+	assert.Equal(t, keystoreFileName, changedFileName)
+	pubKey, err := keymanager.PublicKeyForAccount(names[0])
+	require.NoError(t, err)
+	assert.Equal(t, pubKeys[0], pubKey)
+	require.NoError(t, wallet.checkPasswordForAccount(names[1], password))
+
+	// The keymanager still unlocks every account with the passwords on disk.
+	keymanager, err = direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	validatingKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(validatingKeys))
+
+	// The current password no longer decrypts the changed account.
+	assert.ErrorContains(t, "invalid current password", ChangeAccountPassword(setupWalletCtx(t, cfg)))
+}
@@ -0,0 +1,159 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/urfave/cli/v2"
+)
+
+// CopyAccountMetadata copies the names, labels, notes and creation times of the accounts of the
+// non-HD wallet at --source-wallet-dir to the accounts of the same validating public keys in the
+// wallet at --wallet-dir, such as after importing the keys of a wallet on a new machine. Keys are
+// never copied, accounts of the source wallet missing from the wallet are skipped.
+func CopyAccountMetadata(cliCtx *cli.Context) error {
+	if !cliCtx.IsSet(flags.SourceWalletDirFlag.Name) {
+		return fmt.Errorf("no wallet to copy account metadata from given with --%s", flags.SourceWalletDirFlag.Name)
+	}
+	ctx := context.Background()
+	source, err := OpenWalletAtPath(
+		cliCtx.String(flags.SourceWalletDirFlag.Name),
+		cliCtx.String(flags.SourceWalletPasswordFileFlag.Name),
+		true, /* readOnly */
+	)
+	if err != nil {
+		return errors.Wrap(err, "could not open source wallet")
+	}
+	if source.KeymanagerKind() != v2keymanager.Direct {
+		return fmt.Errorf("only non-HD wallets are supported, not %s wallets", source.KeymanagerKind())
+	}
+	wallet, err := openDirectWallet(cliCtx)
+	if err != nil {
+		return err
+	}
+	if source.AccountsDir() == wallet.AccountsDir() {
+		return errors.New("cannot copy account metadata from a wallet to itself")
+	}
+	copied, err := wallet.copyAccountMetadata(ctx, source)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Copied the metadata of %s accounts\n", au.BrightMagenta(copied))
+	return nil
+}
+
+// Copies the metadata of the accounts of the source wallet to the accounts of the same public keys
+// in the wallet, returning how many accounts it copied the metadata of.
+func (w *Wallet) copyAccountMetadata(ctx context.Context, source *Wallet) (int, error) {
+	sourceAccounts, err := source.accountPubKeys(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not read accounts of source wallet")
+	}
+	accounts, err := w.accountPubKeys(ctx)
+	if err != nil {
+		return 0, err
+	}
+	byPubKey := make(map[[48]byte]string, len(accounts))
+	for name, pubKey := range accounts {
+		byPubKey[pubKey] = name
+	}
+	sourceLabels, err := source.readAccountLabels()
+	if err != nil {
+		return 0, errors.Wrap(err, "could not read labels of source wallet")
+	}
+	labels, err := w.readAccountLabels()
+	if err != nil {
+		return 0, err
+	}
+	sourceNames, _ := sortedAccounts(sourceAccounts)
+	copied := 0
+	for _, sourceName := range sourceNames {
+		pubKey := sourceAccounts[sourceName]
+		name, ok := byPubKey[pubKey]
+		if !ok {
+			log.WithField("account", sourceName).Warnf("No account of public key %#x in wallet, skipping", pubKey)
+			continue
+		}
+		if name != sourceName {
+			if _, taken := accounts[sourceName]; taken {
+				log.WithField("account", name).Warnf("Name %s is taken by another account, keeping the current name", sourceName)
+			} else {
+				if err := w.renameAccount(name, sourceName); err != nil {
+					return copied, err
+				}
+				delete(accounts, name)
+				accounts[sourceName] = pubKey
+				name = sourceName
+			}
+		}
+		sourceMetadata, err := source.readAccountMetadata(sourceName)
+		if err != nil {
+			return copied, err
+		}
+		createdAt, err := source.accountCreationTime(ctx, sourceName, sourceMetadata)
+		if err != nil {
+			return copied, errors.Wrapf(err, "could not get creation time of source account %s", sourceName)
+		}
+		if err := w.setAccountCreationTime(ctx, name, createdAt); err != nil {
+			return copied, err
+		}
+		if sourceMetadata.Notes != "" {
+			metadata, err := w.readAccountMetadata(name)
+			if err != nil {
+				return copied, err
+			}
+			metadata.Notes = sourceMetadata.Notes
+			if err := w.writeAccountMetadata(ctx, name, metadata); err != nil {
+				return copied, err
+			}
+		}
+		if accountLabels := sourceLabels.of(pubKey); len(accountLabels) > 0 {
+			labels.Accounts[fmt.Sprintf("%#x", pubKey)] = accountLabels
+		}
+		fmt.Printf("Copied metadata of account %s\n", au.BrightGreen(name).Bold())
+		copied++
+	}
+	if err := w.writeAccountLabels(ctx, labels); err != nil {
+		return copied, err
+	}
+	return copied, nil
+}
+
+// Sets the creation time of an account in its metadata, and in the name of its keystore file for
+// tools reading it from there.
+func (w *Wallet) setAccountCreationTime(ctx context.Context, name string, createdAt time.Time) error {
+	metadata, err := w.readAccountMetadata(name)
+	if err != nil {
+		return err
+	}
+	metadata.CreatedAt = createdAt.UTC().Format(time.RFC3339Nano)
+	if err := w.writeAccountMetadata(ctx, name, metadata); err != nil {
+		return err
+	}
+	fileName, err := w.FileNameAtPath(ctx, name, w.keystoreFileGlob())
+	if err != nil {
+		return errors.Wrapf(err, "could not get keystore file name of account %s", name)
+	}
+	newFileName := fmt.Sprintf(w.keystoreFileNameFormat(), createdAt.Unix())
+	if fileName == newFileName {
+		return nil
+	}
+	// The keystore is written under its new name before the old one is removed, so the account
+	// always has a keystore.
+	storage := w.files()
+	keystore, err := storage.readFile(ctx, name+"/"+fileName)
+	if err != nil {
+		return errors.Wrapf(err, "could not read keystore of account %s", name)
+	}
+	if err := storage.writeFile(ctx, name+"/"+newFileName, keystore); err != nil {
+		return errors.Wrapf(err, "could not set creation time of account %s", name)
+	}
+	if err := storage.removeFile(ctx, name+"/"+fileName); err != nil {
+		return errors.Wrapf(err, "could not remove previous keystore of account %s", name)
+	}
+	return w.updateManifest(ctx, map[string]string{name + "/" + newFileName: hashOfData(keystore)}, []string{name + "/" + fileName}, nil)
+}
@@ -0,0 +1,85 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestCopyAccountMetadata(t *testing.T) {
+	ctx := context.Background()
+	newWallet := func() (*Wallet, *direct.Keymanager) {
+		walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+		cfg := &testWalletConfig{
+			walletDir:          walletDir,
+			passwordsDir:       passwordsDir,
+			walletPasswordFile: passwordFilePath,
+			keymanagerKind:     v2keymanager.Direct,
+		}
+		wallet, err := NewWallet(setupWalletCtx(t, cfg), v2keymanager.Direct)
+		require.NoError(t, err)
+		require.NoError(t, wallet.SaveWallet())
+		encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+		require.NoError(t, err)
+		require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+		keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+		require.NoError(t, err)
+		return wallet, keymanager
+	}
+	source, sourceKeymanager := newWallet()
+	wallet, keymanager := newWallet()
+
+	copiedKey, skippedKey := bls.RandKey(), bls.RandKey()
+	var pubKey [48]byte
+	copy(pubKey[:], copiedKey.PublicKey().Marshal())
+	name, err := sourceKeymanager.ImportSecretKey(ctx, copiedKey, password)
+	require.NoError(t, err)
+	_, err = sourceKeymanager.ImportSecretKey(ctx, skippedKey, password)
+	require.NoError(t, err)
+	require.NoError(t, source.renameAccount(name, "validator-one"))
+	keystoreFileName, err := source.FileNameAtPath(ctx, "validator-one", direct.KeystoreFileName)
+	require.NoError(t, err)
+	createdAt := time.Unix(1600000000, 0)
+	accountDir := filepath.Join(source.AccountsDir(), "validator-one")
+	require.NoError(t, os.Rename(
+		filepath.Join(accountDir, keystoreFileName),
+		filepath.Join(accountDir, fmt.Sprintf(direct.KeystoreFileNameFormat, createdAt.Unix())),
+	))
+	require.NoError(t, source.writeAccountMetadata(ctx, "validator-one", &accountMetadata{Notes: "rack 4"}))
+	sourceLabels := &accountLabels{Accounts: map[string]map[string]string{
+		fmt.Sprintf("%#x", pubKey): {"customer": "acme"},
+	}}
+	require.NoError(t, source.writeAccountLabels(ctx, sourceLabels))
+
+	// Only the key of one of the accounts of the source wallet is in the wallet.
+	_, err = keymanager.ImportSecretKey(ctx, copiedKey, password)
+	require.NoError(t, err)
+	copied, err := wallet.copyAccountMetadata(ctx, source)
+	require.NoError(t, err)
+	assert.Equal(t, 1, copied)
+
+	accounts, err := wallet.accountPubKeys(ctx)
+	require.NoError(t, err)
+	assert.DeepEqual(t, map[string][48]byte{"validator-one": pubKey}, accounts)
+	assert.Equal(t, true, fileExists(filepath.Join(wallet.passwordsDir, "validator-one"+direct.PasswordFileSuffix)))
+	keystoreFileName, err = wallet.FileNameAtPath(ctx, "validator-one", direct.KeystoreFileName)
+	require.NoError(t, err)
+	walletCreatedAt, err := AccountTimestamp(keystoreFileName)
+	require.NoError(t, err)
+	assert.Equal(t, createdAt, walletCreatedAt)
+	metadata, err := wallet.readAccountMetadata("validator-one")
+	require.NoError(t, err)
+	assert.Equal(t, "rack 4", metadata.Notes)
+	labels, err := wallet.readAccountLabels()
+	require.NoError(t, err)
+	assert.DeepEqual(t, map[string]string{"customer": "acme"}, labels.of(pubKey))
+}
@@ -19,8 +19,19 @@ var log = logrus.WithField("prefix", "accounts-v2")
 // CreateAccount creates a new validator account from user input by opening
 // a wallet from the user's specified path.
 func CreateAccount(cliCtx *cli.Context) error {
-	ctx := context.Background()
-	wallet, err := createOrOpenWallet(cliCtx, CreateWallet)
+	ctx := withJournalAction(context.Background(), journalActionAccountCreated)
+	dryRun := cliCtx.Bool(flags.DryRunFlag.Name)
+	var wallet *Wallet
+	var err error
+	if dryRun {
+		// A dry run must not create a wallet, so it only plans accounts of an existing one.
+		wallet, err = OpenWallet(cliCtx)
+		if errors.Is(err, ErrNoWalletFound) {
+			return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+		}
+	} else {
+		wallet, err = createOrOpenWallet(cliCtx, CreateWallet)
+	}
 	if err != nil {
 		return err
 	}
@@ -38,12 +49,60 @@ func CreateAccount(cliCtx *cli.Context) error {
 		if !ok {
 			return errors.New("not a direct keymanager")
 		}
+		// The deposit data format flag overrides the wallet's configured format for this run.
+		if cliCtx.IsSet(flags.DepositDataFormatFlag.Name) {
+			format := cliCtx.String(flags.DepositDataFormatFlag.Name)
+			if err := direct.ValidateDepositDataFormat(format); err != nil {
+				return err
+			}
+			km.Config().DepositDataFormat = format
+		}
+		if cliCtx.Bool(flags.StoreWithdrawalKeyFlag.Name) && cliCtx.Bool(flags.PrintWithdrawalKeyFlag.Name) {
+			return fmt.Errorf(
+				"only one of --%s and --%s can be given", flags.StoreWithdrawalKeyFlag.Name, flags.PrintWithdrawalKeyFlag.Name,
+			)
+		}
+		// Accounts withdrawing to given withdrawal credentials have no withdrawal key of their own.
+		if cliCtx.IsSet(flags.WithdrawalCredentialsFlag.Name) {
+			for _, flag := range []string{flags.StoreWithdrawalKeyFlag.Name, flags.WithdrawalMnemonicFileFlag.Name} {
+				if cliCtx.IsSet(flag) {
+					return fmt.Errorf("only one of --%s and --%s can be given", flags.WithdrawalCredentialsFlag.Name, flag)
+				}
+			}
+		}
 		password, err := inputPassword(cliCtx, flags.AccountPasswordFileFlag, newAccountPasswordPromptText, confirmPass)
 		if err != nil {
 			return errors.Wrap(err, "could not input new account password")
 		}
+		if cliCtx.Bool(flags.StoreWithdrawalKeyFlag.Name) {
+			withdrawalPassword, err := inputPassword(
+				cliCtx, flags.WithdrawalPasswordFileFlag, withdrawalPasswordPromptText, confirmPass,
+			)
+			if err != nil {
+				return errors.Wrap(err, "could not input withdrawal password")
+			}
+			// The withdrawal key must not be unlockable with the password stored next to the wallet.
+			if withdrawalPassword == password {
+				return errors.New("the withdrawal password must differ from the account password")
+			}
+			km.StoreWithdrawalKeys(withdrawalPassword)
+		}
+		count := cliCtx.Int(flags.CreateCountFlag.Name)
+		if count < 1 {
+			return errors.New("at least 1 account must be created")
+		}
+		if dryRun {
+			return dryRunDirectAccounts(cliCtx, wallet, km, password, count)
+		}
+		if count > 1 {
+			accounts, err := createDirectAccounts(ctx, cliCtx, wallet, km, password, count)
+			if err != nil {
+				return errors.Wrap(err, "could not create accounts in wallet")
+			}
+			return summarizeBatch(cliCtx, accounts)
+		}
 		// Create a new validator account using the specified keymanager.
-		if _, err := km.CreateAccount(ctx, password); err != nil {
+		if _, err := createDirectAccount(ctx, cliCtx, wallet, km, password); err != nil {
 			return errors.Wrap(err, "could not create account in wallet")
 		}
 	case v2keymanager.Derived:
@@ -51,8 +110,41 @@ func CreateAccount(cliCtx *cli.Context) error {
 		if !ok {
 			return errors.New("not a derived keymanager")
 		}
+		for _, flag := range []string{
+			flags.StoreWithdrawalKeyFlag.Name,
+			flags.PrintWithdrawalKeyFlag.Name,
+			flags.WithdrawalMnemonicFileFlag.Name,
+			flags.WithdrawalCredentialsFlag.Name,
+		} {
+			if cliCtx.IsSet(flag) {
+				log.Warnf("Withdrawal keys of HD wallets are derived from their mnemonic, ignoring --%s", flag)
+			}
+		}
+		if cliCtx.IsSet(flags.AccountIndicesFlag.Name) {
+			indices, err := parseAccountIndices(cliCtx.String(flags.AccountIndicesFlag.Name))
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				return dryRunDerivedAccounts(cliCtx, wallet, km, indices)
+			}
+			return createDerivedAccountsAtIndices(cliCtx, km, indices)
+		}
 		startNum := km.NextAccountNumber(ctx)
 		numAccounts := cliCtx.Int64(flags.NumAccountsFlag.Name)
+		if cliCtx.IsSet(flags.CreateCountFlag.Name) {
+			numAccounts = int64(cliCtx.Int(flags.CreateCountFlag.Name))
+		}
+		if numAccounts < 1 {
+			return errors.New("at least 1 account must be created")
+		}
+		if dryRun {
+			indices := make([]uint64, numAccounts)
+			for i := range indices {
+				indices[i] = startNum + uint64(i)
+			}
+			return dryRunDerivedAccounts(cliCtx, wallet, km, indices)
+		}
 		if numAccounts == 1 {
 			if _, err := km.CreateAccount(ctx, true /*logAccountInfo*/); err != nil {
 				return errors.Wrap(err, "could not create account in wallet")
@@ -64,6 +156,15 @@ func CreateAccount(cliCtx *cli.Context) error {
 				}
 			}
 			log.Infof("Successfully created %d accounts. Please use accounts-v2 list to view details for accounts %d through %d.", numAccounts, startNum, startNum+uint64(numAccounts)-1)
+			indices := make([]uint64, numAccounts)
+			for i := range indices {
+				indices[i] = startNum + uint64(i)
+			}
+			accounts, err := derivedBatchAccounts(ctx, km, indices)
+			if err != nil {
+				return err
+			}
+			return summarizeBatch(cliCtx, accounts)
 		}
 	default:
 		return fmt.Errorf("keymanager kind %s not supported", wallet.KeymanagerKind())
@@ -0,0 +1,330 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/depositutil"
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/derived"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/urfave/cli/v2"
+)
+
+// batchAccount describes an account created in a batch, for the summary of the batch.
+type batchAccount struct {
+	name        string
+	pubKey      [48]byte
+	withdrawal  string
+	depositData *depositutil.DepositDataJSON
+}
+
+// Creates count accounts in a non-HD wallet in parallel, all protected by the same password, and
+// records their withdrawal credentials. Withdrawal keys are derived from consecutive accounts of
+// the --withdrawal-mnemonic-file if given, and random otherwise, unless all accounts withdraw to
+// the --withdrawal-credentials.
+func createDirectAccounts(
+	ctx context.Context,
+	cliCtx *cli.Context,
+	wallet *Wallet,
+	km *direct.Keymanager,
+	password string,
+	count int,
+) ([]*batchAccount, error) {
+	records, err := wallet.readWithdrawalCredentials()
+	if err != nil {
+		return nil, err
+	}
+	withdrawalKeys, newRecords, withdrawals, err := batchWithdrawalKeys(cliCtx, records, count)
+	if err != nil {
+		return nil, err
+	}
+
+	var accountNames []string
+	if cliCtx.IsSet(flags.WithdrawalCredentialsFlag.Name) {
+		withdrawalCredentials, _, err := inputWithdrawalCredentials(cliCtx)
+		if err != nil {
+			return nil, err
+		}
+		accountNames, err = km.CreateAccountsWithWithdrawalCredentials(ctx, password, withdrawalCredentials, count)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// Withdrawal keystores are written before the accounts, so a withdrawal key is never lost
+		// with the deposit data of an account withdrawing to it.
+		if newRecords[0].Source == withdrawalSourceKeystoreFile {
+			keystores, err := inputWithdrawalKeystoreWriter(cliCtx, wallet, password)
+			if err != nil {
+				return nil, err
+			}
+			for _, withdrawalKey := range withdrawalKeys {
+				if _, err := keystores.write(withdrawalKey); err != nil {
+					return nil, err
+				}
+			}
+			log.WithField("path", keystores.dir).Info(
+				"Wrote the withdrawal keys of the accounts to keystores encrypted with the withdrawal password",
+			)
+		}
+		accountNames, err = km.CreateAccounts(ctx, password, withdrawalKeys)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Accounts created in parallel are recorded as created in the order of the batch.
+	accounts := make([]*batchAccount, count)
+	for i, name := range accountNames {
+		if err := wallet.recordAccountCreation(ctx, name, roughtime.Now()); err != nil {
+			return nil, err
+		}
+		pubKey, err := km.PublicKeyForAccount(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not get public key for account %s", name)
+		}
+		depositData, err := directAccountDepositData(ctx, wallet, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read deposit data of account %s", name)
+		}
+		if depositData == nil {
+			return nil, fmt.Errorf("no deposit data written for account %s", name)
+		}
+		newRecords[i].WithdrawalCredentials = "0x" + depositData.WithdrawalCredentials
+		records.Accounts[fmt.Sprintf("%#x", pubKey)] = newRecords[i]
+		accounts[i] = &batchAccount{
+			name:        name,
+			pubKey:      pubKey,
+			withdrawal:  withdrawals[i],
+			depositData: depositData,
+		}
+	}
+	if newRecords[0].Source == withdrawalSourceMnemonic {
+		records.NextMnemonicAccount += uint64(count)
+	}
+	if err := wallet.writeWithdrawalCredentials(ctx, records); err != nil {
+		return nil, err
+	}
+	switch newRecords[0].Source {
+	case withdrawalSourceRandom:
+		log.Info(
+			"Write down the private keys in the withdrawal key column, as they are the unique " +
+				"withdrawal private keys of your accounts for eth2",
+		)
+	case withdrawalSourceKeystore:
+		log.Warn(direct.StoredWithdrawalKeyWarning)
+	case withdrawalSourceKeystoreFile:
+		log.Warn(withdrawalKeystoreWarning)
+	}
+	return accounts, nil
+}
+
+// Generates the withdrawal keys of count accounts created in a batch, along with the withdrawal
+// credential records of the accounts and how each withdrawal key is displayed. Withdrawal keys
+// are derived from consecutive accounts of the --withdrawal-mnemonic-file if given, and random
+// otherwise. There are no withdrawal keys for accounts withdrawing to the --withdrawal-credentials,
+// whose keys are nil.
+func batchWithdrawalKeys(
+	cliCtx *cli.Context,
+	records *withdrawalCredentialRecords,
+	count int,
+) ([]bls.SecretKey, []*withdrawalCredentialRecord, []string, error) {
+	withdrawalKeys := make([]bls.SecretKey, count)
+	newRecords := make([]*withdrawalCredentialRecord, count)
+	withdrawals := make([]string, count)
+	if cliCtx.IsSet(flags.WithdrawalMnemonicFileFlag.Name) {
+		mnemonic, err := inputWithdrawalMnemonic(cliCtx)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		for i := range withdrawalKeys {
+			accountNumber := records.NextMnemonicAccount + uint64(i)
+			withdrawalKeys[i], err = derived.WithdrawalKeyFromMnemonic(mnemonic, accountNumber)
+			if err != nil {
+				return nil, nil, nil, errors.Wrap(err, "could not derive withdrawal key")
+			}
+			newRecords[i] = &withdrawalCredentialRecord{
+				WithdrawalPublicKey: fmt.Sprintf("%#x", withdrawalKeys[i].PublicKey().Marshal()),
+				Source:              withdrawalSourceMnemonic,
+				MnemonicAccount:     &accountNumber,
+			}
+			withdrawals[i] = fmt.Sprintf(derived.WithdrawalKeyDerivationPathTemplate, accountNumber)
+		}
+	} else if cliCtx.IsSet(flags.WithdrawalCredentialsFlag.Name) {
+		withdrawalCredentials, withdrawalPubKey, err := inputWithdrawalCredentials(cliCtx)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		for i := range withdrawalKeys {
+			newRecords[i] = &withdrawalCredentialRecord{Source: withdrawalSourceExternal}
+			if withdrawalPubKey != nil {
+				newRecords[i].WithdrawalPublicKey = fmt.Sprintf("%#x", withdrawalPubKey.Marshal())
+			}
+			withdrawals[i] = fmt.Sprintf("credentials %#x", withdrawalCredentials)
+		}
+	} else {
+		storeWithdrawalKeys := cliCtx.Bool(flags.StoreWithdrawalKeyFlag.Name)
+		printWithdrawalKeys := cliCtx.Bool(flags.PrintWithdrawalKeyFlag.Name)
+		for i := range withdrawalKeys {
+			withdrawalKeys[i] = bls.RandKey()
+			switch {
+			case storeWithdrawalKeys:
+				newRecords[i] = &withdrawalCredentialRecord{Source: withdrawalSourceKeystore}
+				withdrawals[i] = "stored in wallet"
+			case printWithdrawalKeys:
+				newRecords[i] = &withdrawalCredentialRecord{Source: withdrawalSourceRandom}
+				withdrawals[i] = fmt.Sprintf("%#x", withdrawalKeys[i].Marshal())
+			default:
+				newRecords[i] = &withdrawalCredentialRecord{
+					WithdrawalPublicKey: fmt.Sprintf("%#x", withdrawalKeys[i].PublicKey().Marshal()),
+					Source:              withdrawalSourceKeystoreFile,
+				}
+				withdrawals[i] = "keystore " + withdrawalKeyFingerprint(withdrawalKeys[i])
+			}
+		}
+	}
+	return withdrawalKeys, newRecords, withdrawals, nil
+}
+
+// Collects the HD accounts of a batch created at the given derivation indices, with the deposit
+// data regenerated from the wallet seed.
+func derivedBatchAccounts(
+	ctx context.Context,
+	km *derived.Keymanager,
+	indices []uint64,
+) ([]*batchAccount, error) {
+	accountNames, err := km.ValidatingAccountNames(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch account names")
+	}
+	// Account names are in the order of the indices of the wallet.
+	positions := make(map[uint64]int, len(accountNames))
+	for i, index := range km.AccountIndices() {
+		positions[index] = i
+	}
+	accounts := make([]*batchAccount, len(indices))
+	for i, index := range indices {
+		pos, ok := positions[index]
+		if !ok || pos >= len(accountNames) {
+			return nil, fmt.Errorf("wallet has no account of index %d", index)
+		}
+		name := accountNames[pos]
+		pubKey, err := km.PublicKeyForAccount(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not get public key for account %s", name)
+		}
+		depositData, err := derivedAccountDepositData(km, accountNames, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not derive deposit data of account %s", name)
+		}
+		accounts[i] = &batchAccount{
+			name:        name,
+			pubKey:      pubKey,
+			withdrawal:  fmt.Sprintf(derived.WithdrawalKeyDerivationPathTemplate, index),
+			depositData: depositData,
+		}
+	}
+	return accounts, nil
+}
+
+// Parses derivation indices given as a comma separated list of indices and inclusive ranges,
+// such as 100-149,200.
+func parseAccountIndices(s string) ([]uint64, error) {
+	indices := make([]uint64, 0)
+	seen := make(map[uint64]bool)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		bounds := strings.SplitN(part, "-", 2)
+		first, err := strconv.ParseUint(strings.TrimSpace(bounds[0]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid account index in %q", part)
+		}
+		last := first
+		if len(bounds) == 2 {
+			last, err = strconv.ParseUint(strings.TrimSpace(bounds[1]), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid account index in %q", part)
+			}
+			if last < first {
+				return nil, fmt.Errorf("account index range %q is reversed", part)
+			}
+		}
+		for index := first; ; index++ {
+			if seen[index] {
+				return nil, fmt.Errorf("account index %d is given more than once", index)
+			}
+			seen[index] = true
+			indices = append(indices, index)
+			if index == last {
+				break
+			}
+		}
+	}
+	if len(indices) == 0 {
+		return nil, errors.New("no account indices given")
+	}
+	return indices, nil
+}
+
+// Creates the accounts of the given derivation indices in an HD wallet. A single account is
+// displayed like any new account, several ones are summarized in a table.
+func createDerivedAccountsAtIndices(cliCtx *cli.Context, km *derived.Keymanager, indices []uint64) error {
+	ctx := withJournalAction(context.Background(), journalActionAccountCreated)
+	if len(indices) == 1 {
+		if _, err := km.CreateAccountAtIndex(ctx, indices[0], true /*logAccountInfo*/); err != nil {
+			return errors.Wrap(err, "could not create account in wallet")
+		}
+		return nil
+	}
+	for _, index := range indices {
+		if _, err := km.CreateAccountAtIndex(ctx, index, false /*logAccountInfo*/); err != nil {
+			return errors.Wrapf(err, "could not create account of index %d in wallet", index)
+		}
+	}
+	accounts, err := derivedBatchAccounts(ctx, km, indices)
+	if err != nil {
+		return err
+	}
+	return summarizeBatch(cliCtx, accounts)
+}
+
+// Prints a table of the accounts of a batch and writes their deposit data to a single
+// deposit_data-<timestamp>.json in the --deposit-data-output-dir.
+func summarizeBatch(cliCtx *cli.Context, accounts []*batchAccount) error {
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "#\tNAME\tPUBLIC KEY\tWITHDRAWAL KEY")
+	for i, account := range accounts {
+		fmt.Fprintf(table, "%d\t%s\t%#x\t%s\n", i+1, account.name, account.pubKey, account.withdrawal)
+	}
+	if err := table.Flush(); err != nil {
+		return errors.Wrap(err, "could not print accounts")
+	}
+
+	outputDir, err := expandPath(cliCtx.String(flags.DepositDataOutputDirFlag.Name))
+	if err != nil {
+		return errors.Wrap(err, "could not parse deposit data output directory")
+	}
+	entries := make([]*depositutil.DepositDataJSON, len(accounts))
+	for i, account := range accounts {
+		entries[i] = account.depositData
+	}
+	filePath, err := writeLaunchpadDepositData(outputDir, entries)
+	if err != nil {
+		return err
+	}
+	fmt.Printf(
+		"Successfully created %s accounts and wrote their deposit data to %s, upload it to the eth2 launchpad to make your deposits\n",
+		au.BrightMagenta(len(accounts)),
+		au.BrightGreen(filePath),
+	)
+	return nil
+}
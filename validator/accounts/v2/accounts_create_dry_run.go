@@ -0,0 +1,151 @@
+package v2
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+	"github.com/prysmaticlabs/prysm/shared/depositutil"
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/derived"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/urfave/cli/v2"
+)
+
+// plannedAccount is an account accounts-v2 create --dry-run would create, with the files
+// creating it would write.
+type plannedAccount struct {
+	name       string
+	pubKey     [48]byte
+	withdrawal string
+	rootHex    string
+	files      []string
+}
+
+// Generates the keys and deposit data of count accounts of a non-HD wallet as CreateAccount
+// would, and prints what creating them would write without writing anything to disk.
+func dryRunDirectAccounts(
+	cliCtx *cli.Context,
+	wallet *Wallet,
+	km *direct.Keymanager,
+	password string,
+	count int,
+) error {
+	records, err := wallet.readWithdrawalCredentials()
+	if err != nil {
+		return err
+	}
+	withdrawalKeys, newRecords, withdrawals, err := batchWithdrawalKeys(cliCtx, records, count)
+	if err != nil {
+		return err
+	}
+	var withdrawalCredentials []byte
+	if cliCtx.IsSet(flags.WithdrawalCredentialsFlag.Name) {
+		withdrawalCredentials, _, err = inputWithdrawalCredentials(cliCtx)
+		if err != nil {
+			return err
+		}
+	}
+	accounts := make([]*plannedAccount, count)
+	for i, withdrawalKey := range withdrawalKeys {
+		var planned *direct.PlannedAccount
+		if withdrawalKey == nil {
+			planned, err = km.PlanAccountWithWithdrawalCredentials(password, withdrawalCredentials)
+		} else {
+			planned, err = km.PlanAccount(password, withdrawalKey)
+		}
+		if err != nil {
+			return errors.Wrap(err, "could not plan account")
+		}
+		rootHex, err := depositDataRootHex(planned.DepositData)
+		if err != nil {
+			return err
+		}
+		// Random withdrawal keys are discarded with the rest of the dry run, so they are not shown.
+		if newRecords[i].Source == withdrawalSourceRandom || newRecords[i].Source == withdrawalSourceKeystoreFile {
+			withdrawals[i] = "random"
+		}
+		files := append(planned.Files, filepath.Join(wallet.passwordsDir, planned.Name+direct.PasswordFileSuffix))
+		accounts[i] = &plannedAccount{
+			name:       planned.Name,
+			pubKey:     planned.PublicKey,
+			withdrawal: withdrawals[i],
+			rootHex:    rootHex,
+			files:      files,
+		}
+	}
+	walletFiles := []string{filepath.Join(wallet.AccountsDir(), withdrawalCredentialsFileName)}
+	return printDryRun(cliCtx, accounts, walletFiles)
+}
+
+// Derives the keys and deposit data of the accounts of the given derivation indices of an HD
+// wallet, and prints what creating them would write without writing anything to disk.
+func dryRunDerivedAccounts(cliCtx *cli.Context, wallet *Wallet, km *derived.Keymanager, indices []uint64) error {
+	planned, err := km.PlanAccounts(indices)
+	if err != nil {
+		return errors.Wrap(err, "could not plan accounts")
+	}
+	accounts := make([]*plannedAccount, len(planned))
+	for i, account := range planned {
+		rootHex, err := depositDataRootHex(account.DepositData)
+		if err != nil {
+			return err
+		}
+		accounts[i] = &plannedAccount{
+			name:       account.Name,
+			pubKey:     account.PublicKey,
+			withdrawal: fmt.Sprintf(derived.WithdrawalKeyDerivationPathTemplate, account.Index),
+			rootHex:    rootHex,
+		}
+	}
+	walletFiles := []string{filepath.Join(wallet.AccountsDir(), derived.EncryptedSeedFileName)}
+	return printDryRun(cliCtx, accounts, walletFiles)
+}
+
+func depositDataRootHex(depositData *ethpb.Deposit_Data) (string, error) {
+	encoded, err := depositutil.DepositDataJSONFromProto(depositData)
+	if err != nil {
+		return "", errors.Wrap(err, "could not compute deposit data root")
+	}
+	return "0x" + encoded.DepositDataRoot, nil
+}
+
+// Prints the accounts a dry run would create, the files of each account and the wallet files
+// creating them would write, and the launchpad deposit data file of a batch.
+func printDryRun(cliCtx *cli.Context, accounts []*plannedAccount, walletFiles []string) error {
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "#\tNAME\tPUBLIC KEY\tWITHDRAWAL KEY\tDEPOSIT DATA ROOT")
+	for i, account := range accounts {
+		fmt.Fprintf(table, "%d\t%s\t%#x\t%s\t%s\n", i+1, account.name, account.pubKey, account.withdrawal, account.rootHex)
+	}
+	if err := table.Flush(); err != nil {
+		return errors.Wrap(err, "could not print accounts")
+	}
+
+	fmt.Println("\nCreating these accounts would write:")
+	for _, account := range accounts {
+		for _, file := range account.files {
+			fmt.Printf("  %s\n", file)
+		}
+	}
+	for _, file := range walletFiles {
+		fmt.Printf("  %s\n", file)
+	}
+	if len(accounts) > 1 {
+		outputDir, err := expandPath(cliCtx.String(flags.DepositDataOutputDirFlag.Name))
+		if err != nil {
+			return errors.Wrap(err, "could not parse deposit data output directory")
+		}
+		fmt.Printf("  %s\n", filepath.Join(outputDir, fmt.Sprintf(launchpadDepositDataFileNameFormat, roughtime.Now().Unix())))
+	}
+	fmt.Printf(
+		"\n%s nothing was written to disk. Run again without --%s to create the accounts\n",
+		au.BrightYellow("Dry run:").Bold(),
+		flags.DryRunFlag.Name,
+	)
+	return nil
+}
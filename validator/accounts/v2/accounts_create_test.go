@@ -2,12 +2,19 @@ package v2
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/prysmaticlabs/prysm/shared/depositutil"
 	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
 	"github.com/prysmaticlabs/prysm/shared/testutil/require"
 	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
 	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/derived"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
 )
 
 func TestCreateAccount_Derived(t *testing.T) {
@@ -20,6 +27,7 @@ func TestCreateAccount_Derived(t *testing.T) {
 		accountPasswordFile: passwordFile,
 		keymanagerKind:      v2keymanager.Derived,
 		numAccounts:         numAccounts,
+		depositDataDir:      filepath.Join(filepath.Dir(walletDir), "deposits"),
 	})
 
 	// We attempt to create the wallet.
@@ -34,7 +42,7 @@ func TestCreateAccount_Derived(t *testing.T) {
 	// We read the keymanager config for the newly created wallet.
 	encoded, err := wallet.ReadKeymanagerConfigFromDisk(ctx)
 	assert.NoError(t, err)
-	cfg, err := derived.UnmarshalConfigFile(encoded)
+	cfg, err := derived.UnmarshalConfigFile(encoded, "" /* password */)
 	assert.NoError(t, err)
 
 	// We assert the created configuration was as desired.
@@ -52,3 +60,141 @@ func TestCreateAccount_Derived(t *testing.T) {
 	assert.NoError(t, err)
 	require.Equal(t, len(names), int(numAccounts))
 }
+
+func TestCreateAccount_DirectBatch(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	depositDataDir := filepath.Join(filepath.Dir(walletDir), "deposits")
+	count := 4
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		walletPasswordFile:  passwordFile,
+		accountPasswordFile: passwordFile,
+		keymanagerKind:      v2keymanager.Direct,
+		depositDataDir:      depositDataDir,
+		createCount:         count,
+	})
+	_, err := CreateWallet(cliCtx)
+	require.NoError(t, err)
+	require.NoError(t, CreateAccount(cliCtx))
+
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	names, err := keymanager.ValidatingAccountNames()
+	require.NoError(t, err)
+	require.Equal(t, count, len(names))
+
+	// The deposit data of every account is written to a single launchpad file.
+	files, err := filepath.Glob(filepath.Join(depositDataDir, "deposit_data-*.json"))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(files))
+	encoded, err := ioutil.ReadFile(files[0])
+	require.NoError(t, err)
+	var entries []*depositutil.DepositDataJSON
+	require.NoError(t, json.Unmarshal(encoded, &entries))
+	require.Equal(t, count, len(entries))
+
+	records, err := wallet.readWithdrawalCredentials()
+	require.NoError(t, err)
+	assert.Equal(t, count, len(records.Accounts))
+	for _, entry := range entries {
+		record, ok := records.Accounts["0x"+entry.PubKey]
+		require.Equal(t, true, ok, "Expected withdrawal credentials recorded for %s", entry.PubKey)
+		assert.Equal(t, withdrawalSourceRandom, record.Source)
+		assert.Equal(t, fmt.Sprintf("0x%s", entry.WithdrawalCredentials), record.WithdrawalCredentials)
+	}
+}
+
+func TestCreateAccount_DerivedAtIndices(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFile,
+		keymanagerKind:     v2keymanager.Derived,
+		depositDataDir:     filepath.Join(filepath.Dir(walletDir), "deposits"),
+		accountIndices:     "100-102,7",
+	}
+	cliCtx := setupWalletCtx(t, cfg)
+	_, err := CreateWallet(cliCtx)
+	require.NoError(t, err)
+	require.NoError(t, CreateAccount(cliCtx))
+
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	keymanager, err := wallet.InitializeKeymanager(ctx, true)
+	require.NoError(t, err)
+	km, ok := keymanager.(*derived.Keymanager)
+	require.Equal(t, true, ok, "Expected a derived keymanager")
+	assert.DeepEqual(t, []uint64{7, 100, 101, 102}, km.AccountIndices())
+
+	// Accounts of indices already in the wallet cannot be created again.
+	cfg.accountIndices = "102"
+	assert.ErrorContains(t, "already has the account of index 102", CreateAccount(setupWalletCtx(t, cfg)))
+}
+
+func TestParseAccountIndices(t *testing.T) {
+	indices, err := parseAccountIndices("100-103, 7,200")
+	require.NoError(t, err)
+	assert.DeepEqual(t, []uint64{100, 101, 102, 103, 7, 200}, indices)
+	_, err = parseAccountIndices("5-3")
+	assert.ErrorContains(t, "reversed", err)
+	_, err = parseAccountIndices("1-3,2")
+	assert.ErrorContains(t, "more than once", err)
+	_, err = parseAccountIndices("a-3")
+	assert.ErrorContains(t, "invalid account index", err)
+	_, err = parseAccountIndices(" , ")
+	assert.ErrorContains(t, "no account indices", err)
+}
+
+func TestCreateAccount_DryRun(t *testing.T) {
+	for _, kind := range []v2keymanager.Kind{v2keymanager.Direct, v2keymanager.Derived} {
+		t.Run(kind.String(), func(t *testing.T) {
+			walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+			cfg := &testWalletConfig{
+				walletDir:           walletDir,
+				passwordsDir:        passwordsDir,
+				walletPasswordFile:  passwordFile,
+				accountPasswordFile: passwordFile,
+				keymanagerKind:      kind,
+				depositDataDir:      filepath.Join(filepath.Dir(walletDir), "deposits"),
+				numAccounts:         1,
+				createCount:         3,
+				dryRun:              true,
+			}
+			// A dry run does not create a wallet.
+			assert.ErrorContains(t, "no wallet found", CreateAccount(setupWalletCtx(t, cfg)))
+			_, err := os.Stat(walletDir)
+			assert.Equal(t, true, os.IsNotExist(err))
+
+			cfg.dryRun = false
+			cfg.createCount = 1
+			_, err = CreateWallet(setupWalletCtx(t, cfg))
+			require.NoError(t, err)
+			require.NoError(t, CreateAccount(setupWalletCtx(t, cfg)))
+			before := listFiles(t, filepath.Dir(walletDir))
+
+			cfg.dryRun = true
+			cfg.createCount = 3
+			require.NoError(t, CreateAccount(setupWalletCtx(t, cfg)))
+			assert.DeepEqual(t, before, listFiles(t, filepath.Dir(walletDir)))
+		})
+	}
+}
+
+// Lists the files under a directory with their sizes and modification times.
+func listFiles(t *testing.T, dir string) map[string]string {
+	files := make(map[string]string)
+	require.NoError(t, filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		files[path] = fmt.Sprintf("%d %d", info.Size(), info.ModTime().UnixNano())
+		return nil
+	}))
+	return files
+}
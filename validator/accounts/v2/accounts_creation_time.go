@@ -0,0 +1,45 @@
+package v2
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Records the creation time of a new account of a non-HD wallet in its metadata, unless it is
+// already recorded, along with the deposit data written for the account. Unlike the timestamp in
+// the name of its keystore file, the recorded creation time has nanosecond precision and does not
+// depend on how the keystore was copied into the wallet.
+func (w *Wallet) recordAccountCreation(ctx context.Context, accountName string, createdAt time.Time) error {
+	metadata, err := w.readAccountMetadata(accountName)
+	if err != nil {
+		return err
+	}
+	if metadata.CreatedAt == "" {
+		metadata.CreatedAt = createdAt.UTC().Format(time.RFC3339Nano)
+	}
+	return w.consolidateAccountMetadata(ctx, accountName, metadata)
+}
+
+// Returns the creation time of an account of a non-HD wallet recorded in its metadata, or the
+// timestamp in the name of its keystore file for accounts created before creation times were
+// recorded.
+func (w *Wallet) accountCreationTime(ctx context.Context, accountName string, metadata *accountMetadata) (time.Time, error) {
+	if metadata.CreatedAt != "" {
+		createdAt, err := time.Parse(time.RFC3339Nano, metadata.CreatedAt)
+		if err != nil {
+			return time.Time{}, errors.Wrapf(err, "invalid creation time of account %s", accountName)
+		}
+		return createdAt, nil
+	}
+	keystoreFileName, err := w.FileNameAtPath(ctx, accountName, w.keystoreFileGlob())
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "could not get keystore file name for account: %s", accountName)
+	}
+	createdAt, err := w.keystoreFileTimestamp(keystoreFileName)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "could not get timestamp from keystore file name")
+	}
+	return createdAt, nil
+}
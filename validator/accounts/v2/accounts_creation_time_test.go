@@ -0,0 +1,59 @@
+package v2
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestAccountCreationTime(t *testing.T) {
+	walletDir, passwordsDir, _ := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:      walletDir,
+		passwordsDir:   passwordsDir,
+		keymanagerKind: v2keymanager.Direct,
+	}
+	wallet, err := NewWallet(setupWalletCtx(t, cfg), v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	names := make([]string, 3)
+	for i := range names {
+		names[i], err = keymanager.CreateAccount(ctx, "hello world")
+		require.NoError(t, err)
+	}
+
+	// Accounts without a recorded creation time fall back to the timestamp of their keystore file.
+	metadata, err := wallet.readAccountMetadata(names[0])
+	require.NoError(t, err)
+	keystoreFileName, err := wallet.FileNameAtPath(ctx, names[0], direct.KeystoreFileName)
+	require.NoError(t, err)
+	keystoreCreatedAt, err := AccountTimestamp(keystoreFileName)
+	require.NoError(t, err)
+	createdAt, err := wallet.accountCreationTime(ctx, names[0], metadata)
+	require.NoError(t, err)
+	assert.Equal(t, keystoreCreatedAt, createdAt)
+
+	// Recorded creation times order accounts created within the same second, and are never
+	// overwritten once recorded.
+	base := time.Unix(1600000000, 0)
+	for i, name := range names {
+		require.NoError(t, wallet.recordAccountCreation(ctx, name, base.Add(time.Duration(len(names)-i)*time.Millisecond)))
+		require.NoError(t, wallet.recordAccountCreation(ctx, name, base.Add(time.Hour)))
+	}
+	inventory, err := directInventory(ctx, wallet)
+	require.NoError(t, err)
+	require.NoError(t, inventory.sort(createdListSort))
+	require.Equal(t, len(names), len(inventory.Accounts))
+	for i, account := range inventory.Accounts {
+		assert.Equal(t, names[len(names)-1-i], account.Name)
+		assert.Equal(t, base.UTC().Format(time.RFC3339), account.CreatedAt)
+	}
+}
@@ -0,0 +1,195 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/urfave/cli/v2"
+)
+
+// accountDeactivationsFileName is the file in the accounts directory of a wallet holding the
+// scheduled deactivations of its accounts.
+const accountDeactivationsFileName = "account-deactivations.json"
+
+// accountDeactivations are the scheduled deactivations of the accounts of a wallet, by 0x-prefixed
+// validating public key so they follow an account when it is renamed.
+type accountDeactivations struct {
+	Accounts map[string]*v2keymanager.Deactivation `json:"accounts"`
+}
+
+// DeactivateAccounts schedules the selected --accounts of a wallet to stop being served by the
+// validator client after the --deactivate-after-epoch or the --deactivate-after date, such as
+// keys being handed off to another operator or exited at a planned time. With
+// --cancel-deactivation, the scheduled deactivations of the accounts are removed instead.
+func DeactivateAccounts(cliCtx *cli.Context) error {
+	cancel := cliCtx.Bool(flags.CancelDeactivationFlag.Name)
+	deactivation, err := deactivationFromCli(cliCtx)
+	if err != nil {
+		return err
+	}
+	if cancel == (deactivation != nil) {
+		return fmt.Errorf(
+			"either --%s, --%s or --%s must be given",
+			flags.DeactivateAfterEpochFlag.Name,
+			flags.DeactivateAfterFlag.Name,
+			flags.CancelDeactivationFlag.Name,
+		)
+	}
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	keymanager, err := wallet.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+	if err != nil {
+		return errors.Wrap(err, "could not initialize keymanager")
+	}
+	inventory, err := inventoryAccounts(ctx, wallet, keymanager)
+	if err != nil {
+		return errors.Wrap(err, "could not build account inventory")
+	}
+	if len(inventory.Accounts) == 0 {
+		return errors.New("wallet has no accounts to deactivate")
+	}
+	accountNames := make([]string, len(inventory.Accounts))
+	pubKeys := make([][48]byte, len(inventory.Accounts))
+	byName := make(map[string][48]byte, len(inventory.Accounts))
+	for i, account := range inventory.Accounts {
+		accountNames[i] = account.Name
+		pubKeys[i], err = parsePubKey(account.PublicKey)
+		if err != nil {
+			return errors.Wrapf(err, "invalid public key of account %s", account.Name)
+		}
+		byName[account.Name] = pubKeys[i]
+	}
+	selectedAccounts, err := selectAccounts(cliCtx, accountNames, pubKeys)
+	if err != nil {
+		return errors.Wrap(err, "could not select accounts")
+	}
+	if len(selectedAccounts) == 0 {
+		return errors.New("no accounts selected to deactivate")
+	}
+	deactivations, err := wallet.readAccountDeactivations()
+	if err != nil {
+		return err
+	}
+	for _, name := range selectedAccounts {
+		key := fmt.Sprintf("%#x", byName[name])
+		if cancel {
+			delete(deactivations.Accounts, key)
+			fmt.Printf("Canceled the deactivation of account %s\n", au.BrightGreen(name).Bold())
+			continue
+		}
+		deactivations.Accounts[key] = deactivation
+		fmt.Printf("%s will be deactivated %s\n", au.BrightGreen(name).Bold(), formatDeactivation(deactivation))
+	}
+	if err := wallet.writeAccountDeactivations(ctx, deactivations); err != nil {
+		return err
+	}
+	fmt.Println("Restart the validator client for the schedule to take effect")
+	return nil
+}
+
+// DeactivationSchedule returns the scheduled deactivations of the accounts of the wallet, by
+// validating public key.
+func (w *Wallet) DeactivationSchedule() (map[[48]byte]*v2keymanager.Deactivation, error) {
+	deactivations, err := w.readAccountDeactivations()
+	if err != nil {
+		return nil, err
+	}
+	schedule := make(map[[48]byte]*v2keymanager.Deactivation, len(deactivations.Accounts))
+	for key, deactivation := range deactivations.Accounts {
+		pubKey, err := parsePubKey(key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid public key %s in account deactivations", key)
+		}
+		schedule[pubKey] = deactivation
+	}
+	return schedule, nil
+}
+
+// Parses the --deactivate-after-epoch and --deactivate-after date, returning nil if neither is
+// given.
+func deactivationFromCli(cliCtx *cli.Context) (*v2keymanager.Deactivation, error) {
+	var deactivation *v2keymanager.Deactivation
+	if cliCtx.IsSet(flags.DeactivateAfterEpochFlag.Name) {
+		epoch := cliCtx.Uint64(flags.DeactivateAfterEpochFlag.Name)
+		deactivation = &v2keymanager.Deactivation{AfterEpoch: &epoch}
+	}
+	if cliCtx.IsSet(flags.DeactivateAfterFlag.Name) {
+		after, err := time.Parse(time.RFC3339, cliCtx.String(flags.DeactivateAfterFlag.Name))
+		if err != nil {
+			return nil, errors.Wrapf(err, "--%s must be an RFC 3339 date", flags.DeactivateAfterFlag.Name)
+		}
+		after = after.UTC()
+		if deactivation == nil {
+			deactivation = &v2keymanager.Deactivation{}
+		}
+		deactivation.After = &after
+	}
+	return deactivation, nil
+}
+
+func formatDeactivation(deactivation *v2keymanager.Deactivation) string {
+	switch {
+	case deactivation.AfterEpoch != nil && deactivation.After != nil:
+		return fmt.Sprintf("after epoch %d or after %s, whichever comes first", *deactivation.AfterEpoch, deactivation.After.Format(time.RFC3339))
+	case deactivation.AfterEpoch != nil:
+		return fmt.Sprintf("after epoch %d", *deactivation.AfterEpoch)
+	default:
+		return fmt.Sprintf("after %s", deactivation.After.Format(time.RFC3339))
+	}
+}
+
+// Reads the scheduled deactivations of the accounts of the wallet, which are empty until an
+// account is scheduled for deactivation.
+func (w *Wallet) readAccountDeactivations() (*accountDeactivations, error) {
+	deactivations := &accountDeactivations{Accounts: make(map[string]*v2keymanager.Deactivation)}
+	encoded, err := w.files().readFile(context.Background(), accountDeactivationsFileName)
+	if os.IsNotExist(err) {
+		return deactivations, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read account deactivations")
+	}
+	if err := json.Unmarshal(encoded, deactivations); err != nil {
+		return nil, errors.Wrap(err, "could not decode account deactivations")
+	}
+	if deactivations.Accounts == nil {
+		deactivations.Accounts = make(map[string]*v2keymanager.Deactivation)
+	}
+	return deactivations, nil
+}
+
+func (w *Wallet) writeAccountDeactivations(ctx context.Context, deactivations *accountDeactivations) error {
+	encoded, err := json.MarshalIndent(deactivations, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "could not marshal account deactivations")
+	}
+	if err := w.WriteFileAtPath(ctx, "" /* accounts dir */, accountDeactivationsFileName, encoded); err != nil {
+		return errors.Wrap(err, "could not write account deactivations")
+	}
+	return nil
+}
+
+// Removes the scheduled deactivations of accounts deleted from the wallet, returning whether any
+// of them had one.
+func (d *accountDeactivations) forget(pubKeys [][48]byte) bool {
+	forgotten := false
+	for _, pubKey := range pubKeys {
+		key := fmt.Sprintf("%#x", pubKey)
+		if _, ok := d.Accounts[key]; ok {
+			delete(d.Accounts, key)
+			forgotten = true
+		}
+	}
+	return forgotten
+}
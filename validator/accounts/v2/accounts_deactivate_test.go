@@ -0,0 +1,64 @@
+package v2
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestDeactivateAccounts(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFilePath,
+		keymanagerKind:     v2keymanager.Direct,
+	}
+	wallet, err := NewWallet(setupWalletCtx(t, cfg), v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	names := make([]string, 2)
+	pubKeys := make([][48]byte, len(names))
+	for i := range names {
+		names[i], err = keymanager.CreateAccount(ctx, password)
+		require.NoError(t, err)
+		pubKeys[i], err = keymanager.PublicKeyForAccount(names[i])
+		require.NoError(t, err)
+	}
+
+	cfg.accountsToExport = names[0]
+	assert.ErrorContains(t, "must be given", DeactivateAccounts(setupWalletCtx(t, cfg)))
+	cfg.deactivateAfter = "next tuesday"
+	assert.ErrorContains(t, "must be an RFC 3339 date", DeactivateAccounts(setupWalletCtx(t, cfg)))
+
+	cfg.deactivateAfter = "2020-10-01T12:00:00+02:00"
+	cfg.deactivateEpoch = "12000"
+	require.NoError(t, DeactivateAccounts(setupWalletCtx(t, cfg)))
+	schedule, err := wallet.DeactivationSchedule()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(schedule))
+	deactivation, ok := schedule[pubKeys[0]]
+	require.Equal(t, true, ok)
+	assert.Equal(t, uint64(12000), *deactivation.AfterEpoch)
+	assert.Equal(t, time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC), *deactivation.After)
+
+	// Both flags and --cancel-deactivation cannot be given together.
+	cfg.cancelDeactivation = true
+	assert.ErrorContains(t, "must be given", DeactivateAccounts(setupWalletCtx(t, cfg)))
+	cfg.deactivateAfter, cfg.deactivateEpoch = "", ""
+	require.NoError(t, DeactivateAccounts(setupWalletCtx(t, cfg)))
+	schedule, err = wallet.DeactivationSchedule()
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(schedule))
+}
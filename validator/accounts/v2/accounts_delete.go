@@ -0,0 +1,171 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/promptutil"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/urfave/cli/v2"
+)
+
+const deleteAccountPromptText = "Type the public key %#x to delete account %s"
+
+// DeleteAccount removes the accounts of the public keys given by --delete-public-keys or listed
+// in the --pubkeys-file, and the accounts having the --with-labels, from a non-HD wallet along
+// with their password files and labels. The deletion of every account must be confirmed by typing
+// its full public key, and approved by the --approval-tokens of its operators if the wallet has an
+// approval policy. Deleted accounts are moved to the trash of the wallet, from which they can
+// be restored with accounts-v2 restore until they are erased once the --trash-retention expires.
+// The slashing protection history of the accounts is kept in the tombstones of the wallet.
+func DeleteAccount(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	if wallet.KeymanagerKind() != v2keymanager.Direct {
+		return errors.New("only accounts of non-HD wallets can be deleted")
+	}
+	entered := cliCtx.StringSlice(flags.DeletePublicKeysFlag.Name)
+	var listed [][48]byte
+	if cliCtx.IsSet(flags.PubKeysFileFlag.Name) {
+		listed, err = readPubKeysFile(cliCtx)
+		if err != nil {
+			return err
+		}
+	}
+	labelFilter, err := wallet.labelFilterFromCli(cliCtx)
+	if err != nil {
+		return err
+	}
+	if len(entered) == 0 && len(listed) == 0 && !labelFilter.active() {
+		return fmt.Errorf(
+			"the accounts to delete must be given with --%s, --%s or --%s",
+			flags.DeletePublicKeysFlag.Name,
+			flags.PubKeysFileFlag.Name,
+			flags.WithLabelsFlag.Name,
+		)
+	}
+	keymanager, err := wallet.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+	if err != nil {
+		return errors.Wrap(err, "could not initialize keymanager")
+	}
+	km, ok := keymanager.(*direct.Keymanager)
+	if !ok {
+		return errors.New("could not assert keymanager interface to concrete type")
+	}
+	accounts, err := wallet.accountPubKeys(ctx)
+	if err != nil {
+		return err
+	}
+	byPubKey := make(map[[48]byte]string, len(accounts))
+	for name, pubKey := range accounts {
+		byPubKey[pubKey] = name
+	}
+	pubKeys := make([][48]byte, 0, len(entered)+len(listed))
+	selected := make(map[[48]byte]bool, len(entered)+len(listed))
+	for _, s := range entered {
+		pubKey, err := parsePubKey(s)
+		if err != nil {
+			return err
+		}
+		listed = append(listed, pubKey)
+	}
+	for _, pubKey := range listed {
+		if _, ok := byPubKey[pubKey]; !ok {
+			return fmt.Errorf("no account found in wallet for public key %#x", pubKey)
+		}
+		if selected[pubKey] {
+			continue
+		}
+		selected[pubKey] = true
+		pubKeys = append(pubKeys, pubKey)
+	}
+	if labelFilter.active() {
+		labeled := make([][48]byte, 0)
+		for pubKey := range byPubKey {
+			if labelFilter.allows(pubKey) && !selected[pubKey] {
+				labeled = append(labeled, pubKey)
+			}
+		}
+		if len(labeled) == 0 && len(pubKeys) == 0 {
+			return errors.New("no accounts of the wallet have the selected labels")
+		}
+		sort.Slice(labeled, func(i, j int) bool {
+			return byPubKey[labeled[i]] < byPubKey[labeled[j]]
+		})
+		pubKeys = append(pubKeys, labeled...)
+	}
+
+	if err := wallet.checkApproval(cliCtx, approvalActionDeleteAccounts, pubKeySubjects(pubKeys)); err != nil {
+		return err
+	}
+	if err := wallet.checkTOTP(cliCtx, "deleting accounts"); err != nil {
+		return err
+	}
+
+	retention := cliCtx.Duration(flags.TrashRetentionFlag.Name)
+	log.Warnf("Deleted accounts can be restored from the trash of the wallet for %s, then only from a backup", retention)
+	if !cliCtx.Bool(flags.SkipDeleteConfirmFlag.Name) {
+		for _, pubKey := range pubKeys {
+			promptText := fmt.Sprintf(deleteAccountPromptText, pubKey, byPubKey[pubKey])
+			if _, err := promptutil.ValidatePrompt(promptText, confirmTypedPublicKey(pubKey)); err != nil {
+				return errors.Wrapf(err, "deletion of account %s not confirmed", byPubKey[pubKey])
+			}
+		}
+	}
+	if err := wallet.tombstoneSlashingProtection(ctx, cliCtx, pubKeys); err != nil {
+		return err
+	}
+	if err := km.DeleteAccounts(ctx, pubKeys); err != nil {
+		return errors.Wrap(err, "could not delete accounts")
+	}
+	if labelFilter.labels.forget(pubKeys) {
+		if err := wallet.writeAccountLabels(ctx, labelFilter.labels); err != nil {
+			return err
+		}
+	}
+	deactivations, err := wallet.readAccountDeactivations()
+	if err != nil {
+		return err
+	}
+	if deactivations.forget(pubKeys) {
+		if err := wallet.writeAccountDeactivations(ctx, deactivations); err != nil {
+			return err
+		}
+	}
+	for _, pubKey := range pubKeys {
+		fmt.Printf(
+			"Deleted account %s %#x\n",
+			au.BrightGreen(byPubKey[pubKey]).Bold(),
+			au.BrightMagenta(bytesutil.Trunc(pubKey[:])),
+		)
+	}
+	purged, err := wallet.purgeTrash(retention)
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		log.WithField("retention", retention).Infof("Erased %d accounts deleted longer ago than the retention period from the trash", purged)
+	}
+	return nil
+}
+
+// Validates the typed input is the full public key of the account to delete.
+func confirmTypedPublicKey(pubKey [48]byte) func(string) error {
+	return func(input string) error {
+		if !strings.EqualFold(strings.TrimSpace(input), fmt.Sprintf("%#x", pubKey)) {
+			return errors.New("entered public key does not match the account to delete")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,71 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestDeleteAccount(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFilePath,
+		keymanagerKind:     v2keymanager.Direct,
+	}
+	wallet, err := NewWallet(setupWalletCtx(t, cfg), v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	names := make([]string, 2)
+	pubKeys := make([][48]byte, 2)
+	for i := range names {
+		names[i], err = keymanager.CreateAccount(ctx, password)
+		require.NoError(t, err)
+		pubKeys[i], err = keymanager.PublicKeyForAccount(names[i])
+		require.NoError(t, err)
+	}
+
+	cfg.deletePublicKeys = []string{fmt.Sprintf("%#x", pubKeys[0])}
+	require.NoError(t, DeleteAccount(setupWalletCtx(t, cfg)))
+	accounts, err := wallet.accountPubKeys(ctx)
+	require.NoError(t, err)
+	assert.DeepEqual(t, map[string][48]byte{names[1]: pubKeys[1]}, accounts)
+	assert.Equal(t, false, fileExists(filepath.Join(passwordsDir, names[0]+direct.PasswordFileSuffix)))
+	assert.Equal(t, true, fileExists(filepath.Join(passwordsDir, names[1]+direct.PasswordFileSuffix)))
+	trashed, err := wallet.trashedAccounts()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(trashed), "Expected deleted account to be moved to the trash")
+	assert.Equal(t, names[0], trashed[0].name)
+	assert.Equal(t, true, fileExists(filepath.Join(trashed[0].entryDir, names[0]+direct.PasswordFileSuffix)))
+
+	// An account already deleted, or never in the wallet, is rejected before anything is deleted.
+	cfg.deletePublicKeys = []string{fmt.Sprintf("%#x", pubKeys[1]), fmt.Sprintf("%#x", pubKeys[0])}
+	assert.ErrorContains(t, "no account found in wallet", DeleteAccount(setupWalletCtx(t, cfg)))
+	accounts, err = wallet.accountPubKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(accounts))
+}
+
+func TestConfirmTypedPublicKey(t *testing.T) {
+	pubKey := [48]byte{}
+	copy(pubKey[:], bls.RandKey().PublicKey().Marshal())
+	confirm := confirmTypedPublicKey(pubKey)
+	assert.NoError(t, confirm(fmt.Sprintf("%#x", pubKey)))
+	assert.NoError(t, confirm(fmt.Sprintf(" 0x%X\n", pubKey)))
+	assert.ErrorContains(t, "does not match", confirm(fmt.Sprintf("%#x", pubKey[:47])))
+	assert.ErrorContains(t, "does not match", confirm(""))
+}
@@ -0,0 +1,201 @@
+package v2
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+	"github.com/prysmaticlabs/go-ssz"
+	"github.com/prysmaticlabs/prysm/shared/depositutil"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/derived"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/urfave/cli/v2"
+)
+
+// launchpadDepositDataFileNameFormat matches the file names of the eth2.0-deposit-cli, which
+// are the ones the eth2 launchpad expects to be uploaded.
+const launchpadDepositDataFileNameFormat = "deposit_data-%d.json"
+
+// ExportDepositData aggregates the deposit data of the selected accounts of a wallet into a single
+// deposit_data-<timestamp>.json file, in the format accepted by the eth2 launchpad.
+func ExportDepositData(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	keymanager, err := wallet.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+	if err != nil {
+		return errors.Wrap(err, "could not initialize keymanager")
+	}
+	var accountNames []string
+	switch km := keymanager.(type) {
+	case *direct.Keymanager:
+		accountNames, err = km.ValidatingAccountNames()
+	case *derived.Keymanager:
+		accountNames, err = km.ValidatingAccountNames(ctx)
+	default:
+		return fmt.Errorf("deposit data is not available for %s wallets", wallet.KeymanagerKind())
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not fetch account names")
+	}
+	if len(accountNames) == 0 {
+		return errors.New("wallet has no accounts to export deposit data for")
+	}
+	exporter, ok := keymanager.(keystoreExporter)
+	if !ok {
+		return fmt.Errorf("deposit data is not available for %s wallets", wallet.KeymanagerKind())
+	}
+	pubKeys := make([][48]byte, len(accountNames))
+	for i, name := range accountNames {
+		pubKeys[i], err = exporter.PublicKeyForAccount(name)
+		if err != nil {
+			return errors.Wrapf(err, "could not get public key for account %s", name)
+		}
+	}
+	selectedAccounts, err := selectAccounts(cliCtx, accountNames, pubKeys)
+	if err != nil {
+		return errors.Wrap(err, "could not select accounts")
+	}
+	if len(selectedAccounts) == 0 {
+		return errors.New("no accounts selected to export deposit data for")
+	}
+	outputDir, err := inputDirectory(cliCtx, depositDataDirPromptText, flags.DepositDataOutputDirFlag)
+	if err != nil {
+		return errors.Wrap(err, "could not parse output directory")
+	}
+
+	entries := make([]*depositutil.DepositDataJSON, 0, len(selectedAccounts))
+	for _, name := range selectedAccounts {
+		var entry *depositutil.DepositDataJSON
+		switch km := keymanager.(type) {
+		case *direct.Keymanager:
+			entry, err = directAccountDepositData(ctx, wallet, name)
+		case *derived.Keymanager:
+			entry, err = derivedAccountDepositData(km, accountNames, name)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "could not read deposit data for account %s", name)
+		}
+		if entry == nil {
+			log.WithField("name", name).Warn("No deposit data found for account, skipping")
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return errors.New("none of the selected accounts have deposit data")
+	}
+
+	filePath, err := writeLaunchpadDepositData(outputDir, entries)
+	if err != nil {
+		return err
+	}
+	fmt.Printf(
+		"Successfully wrote the deposit data of %s accounts to %s, upload it to the eth2 launchpad to make your deposits\n",
+		au.BrightMagenta(len(entries)),
+		au.BrightGreen(filePath),
+	)
+	return nil
+}
+
+// Writes deposit data entries to a new deposit_data-<timestamp>.json in the output directory,
+// returning the path of the file.
+func writeLaunchpadDepositData(outputDir string, entries []*depositutil.DepositDataJSON) (string, error) {
+	for _, entry := range entries {
+		if entry.ForkVersion == "" {
+			entry.ForkVersion = hex.EncodeToString(params.BeaconConfig().GenesisForkVersion)
+		}
+	}
+	encoded, err := json.MarshalIndent(entries, "", "\t")
+	if err != nil {
+		return "", errors.Wrap(err, "could not marshal deposit data")
+	}
+	if err := os.MkdirAll(outputDir, params.BeaconIoConfig().ReadWriteExecutePermissions); err != nil {
+		return "", errors.Wrap(err, "could not create output directory")
+	}
+	filePath := filepath.Join(outputDir, fmt.Sprintf(launchpadDepositDataFileNameFormat, roughtime.Now().Unix()))
+	if err := ioutil.WriteFile(filePath, encoded, params.BeaconIoConfig().ReadWritePermissions); err != nil {
+		return "", errors.Wrapf(err, "could not write %s", filePath)
+	}
+	return filePath, nil
+}
+
+// Reads the deposit data stored with a non-HD account, preferring its metadata document and
+// falling back to its deposit data files. Returns nil if the account has none.
+func directAccountDepositData(ctx context.Context, wallet *Wallet, accountName string) (*depositutil.DepositDataJSON, error) {
+	metadata, err := wallet.readAccountMetadata(accountName)
+	if err != nil {
+		return nil, err
+	}
+	if metadata.DepositData != nil {
+		return metadata.DepositData, nil
+	}
+	return readDepositDataFiles(ctx, wallet, accountName)
+}
+
+// Reads the deposit data files of a non-HD account, preferring its deposit_data.json and falling
+// back to its deposit_data.ssz. Returns nil if the account has neither.
+func readDepositDataFiles(ctx context.Context, wallet *Wallet, accountName string) (*depositutil.DepositDataJSON, error) {
+	if enc, err := wallet.ReadFileAtPath(ctx, accountName, direct.DepositDataJSONFileName); err == nil {
+		stored := &depositutil.DepositDataJSON{}
+		if err := json.Unmarshal(enc, stored); err != nil {
+			return nil, errors.Wrapf(err, "could not decode %s", direct.DepositDataJSONFileName)
+		}
+		// Recompute every field from the decoded deposit data, so entries written by other
+		// tools end up complete, and keep the fork version they were signed for.
+		depositData, err := depositutil.DepositDataFromJSON(stored)
+		if err != nil {
+			return nil, err
+		}
+		entry, err := depositutil.DepositDataJSONFromProto(depositData)
+		if err != nil {
+			return nil, err
+		}
+		entry.ForkVersion = stored.ForkVersion
+		return entry, nil
+	}
+	enc, err := wallet.ReadFileAtPath(ctx, accountName, direct.DepositDataFileName)
+	if err != nil {
+		return nil, nil
+	}
+	depositData := &ethpb.Deposit_Data{}
+	if err := ssz.Unmarshal(enc, depositData); err != nil {
+		return nil, errors.Wrapf(err, "could not decode %s", direct.DepositDataFileName)
+	}
+	return depositutil.DepositDataJSONFromProto(depositData)
+}
+
+// Regenerates the deposit data of an HD account from the wallet seed.
+func derivedAccountDepositData(
+	keymanager *derived.Keymanager,
+	accountNames []string,
+	accountName string,
+) (*depositutil.DepositDataJSON, error) {
+	indices := keymanager.AccountIndices()
+	for i, name := range accountNames {
+		if name != accountName {
+			continue
+		}
+		enc, err := keymanager.DepositDataForAccount(indices[i])
+		if err != nil {
+			return nil, err
+		}
+		depositData := &ethpb.Deposit_Data{}
+		if err := ssz.Unmarshal(enc, depositData); err != nil {
+			return nil, errors.Wrap(err, "could not decode deposit data")
+		}
+		return depositutil.DepositDataJSONFromProto(depositData)
+	}
+	return nil, fmt.Errorf("no account found with name %s", accountName)
+}
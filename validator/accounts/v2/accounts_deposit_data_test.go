@@ -0,0 +1,76 @@
+package v2
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/beacon-chain/core/helpers"
+	"github.com/prysmaticlabs/prysm/shared/depositutil"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+)
+
+func TestExportDepositData_Derived(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	outputDir := filepath.Join(testutil.TempDir(), t.Name())
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(outputDir), "Failed to remove directory")
+	})
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		walletPasswordFile:  passwordFile,
+		accountPasswordFile: passwordFile,
+		keymanagerKind:      v2keymanager.Derived,
+		numAccounts:         2,
+	})
+	_, err := CreateWallet(cliCtx)
+	require.NoError(t, err)
+	require.NoError(t, CreateAccount(cliCtx))
+
+	cliCtx = setupWalletCtx(t, &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFile,
+		accountsToExport:   "all",
+		depositDataDir:     outputDir,
+		keymanagerKind:     v2keymanager.Derived,
+	})
+	require.NoError(t, ExportDepositData(cliCtx))
+
+	matches, err := filepath.Glob(filepath.Join(outputDir, depositCLIDepositDataFileName))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(matches))
+	enc, err := ioutil.ReadFile(matches[0])
+	require.NoError(t, err)
+	var entries []*depositutil.DepositDataJSON
+	require.NoError(t, json.Unmarshal(enc, &entries))
+	require.Equal(t, 2, len(entries))
+
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	keymanager, err := wallet.InitializeKeymanager(ctx, true)
+	require.NoError(t, err)
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	domain, err := helpers.ComputeDomain(params.BeaconConfig().DomainDeposit, nil, nil)
+	require.NoError(t, err)
+	for i, entry := range entries {
+		assert.Equal(t, hex.EncodeToString(pubKeys[i][:]), entry.PubKey)
+		assert.Equal(t, params.BeaconConfig().MaxEffectiveBalance, entry.Amount)
+		assert.Equal(t, hex.EncodeToString(params.BeaconConfig().GenesisForkVersion), entry.ForkVersion)
+		assert.NotEqual(t, "", entry.DepositMessageRoot)
+		depositData, err := depositutil.DepositDataFromJSON(entry)
+		require.NoError(t, err)
+		require.NoError(t, depositutil.VerifyDepositSignature(depositData, domain))
+	}
+}
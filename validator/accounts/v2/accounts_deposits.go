@@ -0,0 +1,318 @@
+package v2
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+	"github.com/prysmaticlabs/go-ssz"
+	depositcontract "github.com/prysmaticlabs/prysm/contracts/deposit-contract"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// The deposit of an account was not found in the logs of the deposit contract.
+	depositStatusPending = "pending"
+	// The deposit of an account was found in the logs of the deposit contract.
+	depositStatusIncluded = "included"
+	// The validator of an account with an included deposit is active on the beacon chain.
+	depositStatusActive = "active"
+	// depositLogsBlockRange bounds the blocks the logs of the deposit contract are queried for at
+	// once, as eth1 nodes limit the size of log queries.
+	depositLogsBlockRange = 10000
+	// depositTrackingTimeout bounds querying the eth1 node and the beacon node.
+	depositTrackingTimeout = 10 * time.Minute
+)
+
+// depositInclusion records where the deposit of an account was found in the logs of the deposit
+// contract, in the metadata of the account.
+type depositInclusion struct {
+	Status          string `json:"status"`
+	BlockNumber     uint64 `json:"block_number,omitempty"`
+	TransactionHash string `json:"transaction_hash,omitempty"`
+	DepositIndex    uint64 `json:"deposit_index,omitempty"`
+}
+
+// depositLog is a DepositEvent log of the deposit contract, by the root of its deposit data.
+type depositLog struct {
+	depositDataRoot [32]byte
+	blockNumber     uint64
+	txHash          common.Hash
+	index           uint64
+}
+
+// trackedAccount is an account of a non-HD wallet whose deposit is tracked.
+type trackedAccount struct {
+	name     string
+	pubKey   [48]byte
+	metadata *accountMetadata
+}
+
+// TrackDeposits looks for the deposits of the accounts of a non-HD wallet in the DepositEvent logs
+// of the --deposit-contract, queried from the eth1 node at --http-web3provider from the
+// --deposit-from-block on. Deposits are matched by the deposit data root stored for every account,
+// and accounts are marked as deposit pending or included in their metadata. If --beacon-rpc-provider
+// is given, accounts with an included deposit whose validator is active are marked as active.
+func TrackDeposits(cliCtx *cli.Context) error {
+	endpoint := cliCtx.String(flags.HTTPWeb3ProviderFlag.Name)
+	if endpoint == "" {
+		return fmt.Errorf("an eth1 node must be given with --%s", flags.HTTPWeb3ProviderFlag.Name)
+	}
+	contract := cliCtx.String(flags.DepositContractFlag.Name)
+	if contract == "" {
+		contract = params.BeaconNetworkConfig().DepositContractAddress
+	}
+	if !common.IsHexAddress(contract) {
+		return fmt.Errorf("%q is not a deposit contract address", contract)
+	}
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	if wallet.KeymanagerKind() != v2keymanager.Direct {
+		return errors.New("only the deposits of accounts of non-HD wallets can be tracked")
+	}
+	accounts, err := wallet.trackedAccounts(ctx)
+	if err != nil {
+		return err
+	}
+	if len(accounts) == 0 {
+		return errors.New("wallet has no accounts with deposit data to track")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, depositTrackingTimeout)
+	defer cancel()
+	client, err := ethclient.DialContext(ctx, endpoint)
+	if err != nil {
+		return errors.Wrap(err, "could not connect to eth1 node")
+	}
+	defer client.Close()
+	logs, err := fetchDepositLogs(ctx, client, common.HexToAddress(contract), cliCtx.Uint64(flags.DepositFromBlockFlag.Name))
+	if err != nil {
+		return err
+	}
+	matchDepositLogs(accounts, logs)
+	if cliCtx.IsSet(flags.BeaconRPCProviderFlag.Name) {
+		conn, err := dialBeaconNode(ctx, cliCtx)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := conn.Close(); err != nil {
+				log.WithError(err).Error("Could not close connection to beacon node")
+			}
+		}()
+		if err := markActiveDeposits(ctx, ethpb.NewBeaconNodeValidatorClient(conn), accounts); err != nil {
+			return err
+		}
+	}
+	for _, account := range accounts {
+		if err := wallet.writeAccountMetadata(ctx, account.name, account.metadata); err != nil {
+			return err
+		}
+	}
+	return printDepositInclusions(accounts)
+}
+
+// Lists the accounts of the wallet with deposit data, storing the deposit data root of every
+// account in its metadata the first time it is tracked.
+func (w *Wallet) trackedAccounts(ctx context.Context) ([]*trackedAccount, error) {
+	pubKeys, err := w.accountPubKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names, _ := sortedAccounts(pubKeys)
+	accounts := make([]*trackedAccount, 0, len(names))
+	for _, name := range names {
+		metadata, err := w.readAccountMetadata(name)
+		if err != nil {
+			return nil, err
+		}
+		if metadata.DepositDataRoot == "" {
+			depositData, err := directAccountDepositData(ctx, w, name)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not read deposit data of account %s", name)
+			}
+			if depositData == nil {
+				log.WithField("account", name).Warn("Account has no deposit data, its deposit is not tracked")
+				continue
+			}
+			metadata.DepositDataRoot = "0x" + depositData.DepositDataRoot
+		}
+		accounts = append(accounts, &trackedAccount{
+			name:     name,
+			pubKey:   pubKeys[name],
+			metadata: metadata,
+		})
+	}
+	return accounts, nil
+}
+
+// Reads the DepositEvent logs of the deposit contract from a block on, a range of blocks at a time.
+func fetchDepositLogs(
+	ctx context.Context,
+	client *ethclient.Client,
+	contract common.Address,
+	fromBlock uint64,
+) ([]*depositLog, error) {
+	filterer, err := depositcontract.NewDepositContractFilterer(contract, client)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not bind deposit contract")
+	}
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read eth1 chain head")
+	}
+	latest := head.Number.Uint64()
+	var logs []*depositLog
+	for start := fromBlock; start <= latest; start += depositLogsBlockRange {
+		end := start + depositLogsBlockRange - 1
+		if end > latest {
+			end = latest
+		}
+		it, err := filterer.FilterDepositEvent(&bind.FilterOpts{Start: start, End: &end, Context: ctx})
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not filter deposit logs of blocks %d to %d", start, end)
+		}
+		for it.Next() {
+			depositData := &ethpb.Deposit_Data{
+				PublicKey:             it.Event.Pubkey,
+				WithdrawalCredentials: it.Event.WithdrawalCredentials,
+				Amount:                bytesutil.FromBytes8(it.Event.Amount),
+				Signature:             it.Event.Signature,
+			}
+			root, err := ssz.HashTreeRoot(depositData)
+			if err != nil {
+				return nil, errors.Wrap(err, "could not compute deposit data root of deposit log")
+			}
+			logs = append(logs, &depositLog{
+				depositDataRoot: root,
+				blockNumber:     it.Event.Raw.BlockNumber,
+				txHash:          it.Event.Raw.TxHash,
+				index:           bytesutil.FromBytes8(it.Event.Index),
+			})
+		}
+		if err := it.Error(); err != nil {
+			return nil, errors.Wrap(err, "could not read deposit logs")
+		}
+		if err := it.Close(); err != nil {
+			return nil, errors.Wrap(err, "could not close deposit logs")
+		}
+	}
+	return logs, nil
+}
+
+// Marks the accounts whose deposit data root is found in the deposit logs as included, and the
+// others as pending. Accounts already marked as active stay active.
+func matchDepositLogs(accounts []*trackedAccount, logs []*depositLog) {
+	byRoot := make(map[[32]byte]*depositLog, len(logs))
+	for _, l := range logs {
+		if _, ok := byRoot[l.depositDataRoot]; !ok {
+			byRoot[l.depositDataRoot] = l
+		}
+	}
+	for _, account := range accounts {
+		root, err := hex.DecodeString(strings.TrimPrefix(account.metadata.DepositDataRoot, "0x"))
+		if err != nil || len(root) != 32 {
+			log.WithField("account", account.name).Warn("Account has an invalid deposit data root, its deposit is not tracked")
+			continue
+		}
+		l, ok := byRoot[bytesutil.ToBytes32(root)]
+		if !ok {
+			if account.metadata.Deposit == nil || account.metadata.Deposit.Status == depositStatusPending {
+				account.metadata.Deposit = &depositInclusion{Status: depositStatusPending}
+			}
+			continue
+		}
+		status := depositStatusIncluded
+		if account.metadata.Deposit != nil && account.metadata.Deposit.Status == depositStatusActive {
+			status = depositStatusActive
+		}
+		account.metadata.Deposit = &depositInclusion{
+			Status:          status,
+			BlockNumber:     l.blockNumber,
+			TransactionHash: l.txHash.Hex(),
+			DepositIndex:    l.index,
+		}
+	}
+}
+
+// Marks the accounts with an included deposit whose validator is active on the beacon node as active.
+func markActiveDeposits(ctx context.Context, validatorClient ethpb.BeaconNodeValidatorClient, accounts []*trackedAccount) error {
+	included := make(map[[48]byte]*trackedAccount)
+	pubKeys := make([][]byte, 0)
+	for _, account := range accounts {
+		if account.metadata.Deposit == nil || account.metadata.Deposit.Status != depositStatusIncluded {
+			continue
+		}
+		pubKey := account.pubKey
+		included[pubKey] = account
+		pubKeys = append(pubKeys, pubKey[:])
+	}
+	for start := 0; start < len(pubKeys); start += statusBatchSize {
+		end := start + statusBatchSize
+		if end > len(pubKeys) {
+			end = len(pubKeys)
+		}
+		resp, err := validatorClient.MultipleValidatorStatus(ctx, &ethpb.MultipleValidatorStatusRequest{
+			PublicKeys: pubKeys[start:end],
+		})
+		if err != nil {
+			return errors.Wrap(err, "could not fetch validator statuses")
+		}
+		if len(resp.PublicKeys) != len(resp.Statuses) {
+			return errors.New("beacon node returned a malformed validator status response")
+		}
+		for i, status := range resp.Statuses {
+			account, ok := included[bytesutil.ToBytes48(resp.PublicKeys[i])]
+			if !ok || status == nil {
+				continue
+			}
+			switch status.Status {
+			case ethpb.ValidatorStatus_ACTIVE, ethpb.ValidatorStatus_EXITING, ethpb.ValidatorStatus_SLASHING:
+				account.metadata.Deposit.Status = depositStatusActive
+			}
+		}
+	}
+	return nil
+}
+
+func printDepositInclusions(accounts []*trackedAccount) error {
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].name < accounts[j].name
+	})
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "NAME\tPUBLIC KEY\tDEPOSIT\tBLOCK\tTRANSACTION")
+	for _, account := range accounts {
+		deposit := account.metadata.Deposit
+		if deposit == nil || deposit.Status == depositStatusPending {
+			fmt.Fprintf(table, "%s\t%#x\t%s\t\t\n", account.name, bytesutil.Trunc(account.pubKey[:]), depositStatusPending)
+			continue
+		}
+		fmt.Fprintf(
+			table, "%s\t%#x\t%s\t%d\t%s\n",
+			account.name, bytesutil.Trunc(account.pubKey[:]), deposit.Status, deposit.BlockNumber, deposit.TransactionHash,
+		)
+	}
+	if err := table.Flush(); err != nil {
+		return errors.Wrap(err, "could not print deposits")
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/golang/mock/gomock"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/mock"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestTrackedAccounts_StoresDepositDataRoots(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFilePath,
+		keymanagerKind:     v2keymanager.Direct,
+	}
+	wallet, err := NewWallet(setupWalletCtx(t, cfg), v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	name, err := keymanager.CreateAccount(ctx, password)
+	require.NoError(t, err)
+
+	accounts, err := wallet.trackedAccounts(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(accounts))
+	assert.Equal(t, name, accounts[0].name)
+	depositData, err := directAccountDepositData(ctx, wallet, name)
+	require.NoError(t, err)
+	assert.Equal(t, "0x"+depositData.DepositDataRoot, accounts[0].metadata.DepositDataRoot)
+}
+
+func TestMatchDepositLogs(t *testing.T) {
+	accounts := make([]*trackedAccount, 4)
+	roots := make([][32]byte, len(accounts))
+	for i := range accounts {
+		roots[i] = bytesutil.ToBytes32([]byte(fmt.Sprintf("deposit-data-root-%d", i)))
+		accounts[i] = &trackedAccount{
+			name:     fmt.Sprintf("account-%d", i),
+			metadata: &accountMetadata{DepositDataRoot: fmt.Sprintf("%#x", roots[i])},
+		}
+	}
+	// The validator of the last account was already found active.
+	accounts[3].metadata.Deposit = &depositInclusion{Status: depositStatusActive}
+	txHash := common.HexToHash("0xabcd")
+	logs := []*depositLog{
+		{depositDataRoot: roots[1], blockNumber: 100, txHash: txHash, index: 7},
+		{depositDataRoot: roots[3], blockNumber: 90, txHash: txHash, index: 2},
+		{depositDataRoot: bytesutil.ToBytes32([]byte("someone else")), blockNumber: 95, index: 5},
+	}
+
+	matchDepositLogs(accounts, logs)
+	assert.DeepEqual(t, &depositInclusion{Status: depositStatusPending}, accounts[0].metadata.Deposit)
+	assert.DeepEqual(t, &depositInclusion{
+		Status:          depositStatusIncluded,
+		BlockNumber:     100,
+		TransactionHash: txHash.Hex(),
+		DepositIndex:    7,
+	}, accounts[1].metadata.Deposit)
+	assert.DeepEqual(t, &depositInclusion{Status: depositStatusPending}, accounts[2].metadata.Deposit)
+	assert.Equal(t, depositStatusActive, accounts[3].metadata.Deposit.Status)
+	assert.Equal(t, uint64(90), accounts[3].metadata.Deposit.BlockNumber)
+}
+
+func TestMarkActiveDeposits(t *testing.T) {
+	accounts := make([]*trackedAccount, 3)
+	pubKeys := make([][]byte, 0, len(accounts))
+	for i := range accounts {
+		pubKey := bls.RandKey().PublicKey().Marshal()
+		accounts[i] = &trackedAccount{
+			name:     fmt.Sprintf("account-%d", i),
+			pubKey:   bytesutil.ToBytes48(pubKey),
+			metadata: &accountMetadata{Deposit: &depositInclusion{Status: depositStatusIncluded}},
+		}
+		if i < 2 {
+			pubKeys = append(pubKeys, pubKey)
+		}
+	}
+	// Only accounts with an included deposit are queried.
+	accounts[2].metadata.Deposit.Status = depositStatusPending
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	validatorClient := mock.NewMockBeaconNodeValidatorClient(ctrl)
+	validatorClient.EXPECT().MultipleValidatorStatus(
+		gomock.Any(),
+		&ethpb.MultipleValidatorStatusRequest{PublicKeys: pubKeys},
+	).Return(&ethpb.MultipleValidatorStatusResponse{
+		PublicKeys: pubKeys,
+		Statuses: []*ethpb.ValidatorStatusResponse{
+			{Status: ethpb.ValidatorStatus_ACTIVE},
+			{Status: ethpb.ValidatorStatus_PENDING},
+		},
+	}, nil /*err*/)
+
+	require.NoError(t, markActiveDeposits(context.Background(), validatorClient, accounts))
+	assert.Equal(t, depositStatusActive, accounts[0].metadata.Deposit.Status)
+	assert.Equal(t, depositStatusIncluded, accounts[1].metadata.Deposit.Status)
+	assert.Equal(t, depositStatusPending, accounts[2].metadata.Deposit.Status)
+}
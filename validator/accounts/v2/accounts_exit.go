@@ -0,0 +1,245 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	ptypes "github.com/gogo/protobuf/types"
+	"github.com/pkg/errors"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+	"github.com/prysmaticlabs/prysm/beacon-chain/core/helpers"
+	validatorpb "github.com/prysmaticlabs/prysm/proto/validator/accounts/v2"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/shared/promptutil"
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// accountExitsFileName is the file in the accounts directory of a wallet recording the
+	// voluntary exits submitted for its accounts.
+	accountExitsFileName = "account-exits.json"
+	// exitBeaconNodeTimeout bounds connecting to the beacon node and submitting the exits.
+	exitBeaconNodeTimeout = 2 * time.Minute
+	// exitConfirmPhrase must be typed to confirm voluntary exits, which cannot be reversed.
+	exitConfirmPhrase = "Exit my validators"
+)
+
+// accountExits are the voluntary exits submitted for the accounts of a wallet, by 0x-prefixed
+// validating public key.
+type accountExits struct {
+	Accounts map[string]*accountExit `json:"accounts"`
+}
+
+// accountExit records a voluntary exit submitted to a beacon node.
+type accountExit struct {
+	ValidatorIndex uint64 `json:"validator_index"`
+	Epoch          uint64 `json:"epoch"`
+	SubmittedAt    string `json:"submitted_at"`
+}
+
+// ExitAccounts submits a signed voluntary exit for every selected account of a wallet to the
+// beacon node at --beacon-rpc-provider, and records the exits in the wallet. Exits are signed by
+// the keymanager of the wallet and cannot be reversed.
+func ExitAccounts(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	keymanager, err := wallet.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+	if err != nil {
+		return errors.Wrap(err, "could not initialize keymanager")
+	}
+	inventory, err := inventoryAccounts(ctx, wallet, keymanager)
+	if err != nil {
+		return errors.Wrap(err, "could not build account inventory")
+	}
+	if len(inventory.Accounts) == 0 {
+		return errors.New("wallet has no accounts to exit")
+	}
+	accountNames := make([]string, len(inventory.Accounts))
+	pubKeys := make([][48]byte, len(inventory.Accounts))
+	byName := make(map[string][48]byte, len(inventory.Accounts))
+	for i, account := range inventory.Accounts {
+		accountNames[i] = account.Name
+		pubKeys[i], err = parsePubKey(account.PublicKey)
+		if err != nil {
+			return errors.Wrapf(err, "invalid public key of account %s", account.Name)
+		}
+		byName[account.Name] = pubKeys[i]
+	}
+	selectedAccounts, err := selectAccounts(cliCtx, accountNames, pubKeys)
+	if err != nil {
+		return errors.Wrap(err, "could not select accounts")
+	}
+	exits, err := wallet.readAccountExits()
+	if err != nil {
+		return err
+	}
+	toExit := make([]string, 0, len(selectedAccounts))
+	for _, name := range selectedAccounts {
+		if exit := exits.of(byName[name]); exit != nil {
+			log.Warnf(
+				"Skipping account %s, an exit of validator %d was already submitted at %s",
+				name,
+				exit.ValidatorIndex,
+				exit.SubmittedAt,
+			)
+			continue
+		}
+		toExit = append(toExit, name)
+	}
+	if len(toExit) == 0 {
+		return errors.New("no accounts selected to exit")
+	}
+	if err := wallet.checkTOTP(cliCtx, "exiting accounts"); err != nil {
+		return err
+	}
+
+	log.Warn(
+		"Exited validators can no longer propose or attest, and their stake cannot be withdrawn " +
+			"until withdrawals are enabled. Exits cannot be reversed",
+	)
+	if !cliCtx.Bool(flags.SkipExitConfirmFlag.Name) {
+		promptText := fmt.Sprintf("Type %q to exit the validators of %d accounts", exitConfirmPhrase, len(toExit))
+		if _, err := promptutil.ValidatePrompt(promptText, confirmExitPhrase); err != nil {
+			return errors.Wrap(err, "exit not confirmed")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, exitBeaconNodeTimeout)
+	defer cancel()
+	conn, err := dialBeaconNode(ctx, cliCtx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.WithError(err).Error("Could not close connection to beacon node")
+		}
+	}()
+	validatorClient := ethpb.NewBeaconNodeValidatorClient(conn)
+	head, err := ethpb.NewBeaconChainClient(conn).GetChainHead(ctx, &ptypes.Empty{})
+	if err != nil {
+		return errors.Wrap(err, "could not fetch chain head")
+	}
+	for i, name := range toExit {
+		exit, err := submitExit(ctx, keymanager, validatorClient, byName[name], head.HeadEpoch)
+		if err != nil {
+			// The exits already submitted are still recorded.
+			if i > 0 {
+				if err := wallet.writeAccountExits(ctx, exits); err != nil {
+					log.WithError(err).Error("Could not record submitted exits")
+				}
+			}
+			return errors.Wrapf(err, "could not exit account %s", name)
+		}
+		exits.Accounts[fmt.Sprintf("%#x", byName[name])] = exit
+		fmt.Printf(
+			"Submitted exit of account %s, validator %d, at epoch %d\n",
+			au.BrightGreen(name).Bold(),
+			exit.ValidatorIndex,
+			exit.Epoch,
+		)
+	}
+	return wallet.writeAccountExits(ctx, exits)
+}
+
+// Builds the voluntary exit of the validator of a public key at the given epoch, signs it with
+// the keymanager and proposes it to the beacon node.
+func submitExit(
+	ctx context.Context,
+	keymanager v2keymanager.IKeymanager,
+	validatorClient ethpb.BeaconNodeValidatorClient,
+	pubKey [48]byte,
+	epoch uint64,
+) (*accountExit, error) {
+	indexResp, err := validatorClient.ValidatorIndex(ctx, &ethpb.ValidatorIndexRequest{PublicKey: pubKey[:]})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch validator index")
+	}
+	exit := &ethpb.VoluntaryExit{
+		Epoch:          epoch,
+		ValidatorIndex: indexResp.Index,
+	}
+	domain, err := validatorClient.DomainData(ctx, &ethpb.DomainRequest{
+		Epoch:  epoch,
+		Domain: params.BeaconConfig().DomainVoluntaryExit[:],
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get domain data")
+	}
+	signingRoot, err := helpers.ComputeSigningRoot(exit, domain.SignatureDomain)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get signing root")
+	}
+	sig, err := keymanager.Sign(ctx, &validatorpb.SignRequest{
+		PublicKey:   pubKey[:],
+		SigningRoot: signingRoot[:],
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not sign voluntary exit")
+	}
+	signedExit := &ethpb.SignedVoluntaryExit{
+		Exit:      exit,
+		Signature: sig.Marshal(),
+	}
+	if _, err := validatorClient.ProposeExit(ctx, signedExit); err != nil {
+		return nil, errors.Wrap(err, "could not propose voluntary exit")
+	}
+	return &accountExit{
+		ValidatorIndex: exit.ValidatorIndex,
+		Epoch:          exit.Epoch,
+		SubmittedAt:    roughtime.Now().UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// Reads the voluntary exits submitted for the accounts of the wallet.
+func (w *Wallet) readAccountExits() (*accountExits, error) {
+	exits := &accountExits{Accounts: make(map[string]*accountExit)}
+	encoded, err := w.files().readFile(context.Background(), accountExitsFileName)
+	if os.IsNotExist(err) {
+		return exits, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read account exits")
+	}
+	if err := json.Unmarshal(encoded, exits); err != nil {
+		return nil, errors.Wrap(err, "could not decode account exits")
+	}
+	if exits.Accounts == nil {
+		exits.Accounts = make(map[string]*accountExit)
+	}
+	return exits, nil
+}
+
+func (w *Wallet) writeAccountExits(ctx context.Context, exits *accountExits) error {
+	encoded, err := json.MarshalIndent(exits, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "could not marshal account exits")
+	}
+	if err := w.WriteFileAtPath(ctx, "" /* accounts dir */, accountExitsFileName, encoded); err != nil {
+		return errors.Wrap(err, "could not write account exits")
+	}
+	return nil
+}
+
+func (e *accountExits) of(pubKey [48]byte) *accountExit {
+	return e.Accounts[fmt.Sprintf("%#x", pubKey)]
+}
+
+func confirmExitPhrase(input string) error {
+	if strings.TrimSpace(input) != exitConfirmPhrase {
+		return fmt.Errorf("enter %q to confirm the exit", exitConfirmPhrase)
+	}
+	return nil
+}
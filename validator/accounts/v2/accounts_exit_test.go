@@ -0,0 +1,84 @@
+package v2
+
+import (
+	"context"
+	"testing"
+
+	ptypes "github.com/gogo/protobuf/types"
+	"github.com/golang/mock/gomock"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+	"github.com/prysmaticlabs/prysm/beacon-chain/core/helpers"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/mock"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestSubmitExit(t *testing.T) {
+	walletDir, passwordsDir, _ := setupWalletAndPasswordsDir(t)
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:      walletDir,
+		passwordsDir:   passwordsDir,
+		keymanagerKind: v2keymanager.Direct,
+	})
+	wallet, err := NewWallet(cliCtx, v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	accountName, err := keymanager.CreateAccount(ctx, password)
+	require.NoError(t, err)
+	pubKey, err := keymanager.PublicKeyForAccount(accountName)
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	validatorClient := mock.NewMockBeaconNodeValidatorClient(ctrl)
+	validatorClient.EXPECT().ValidatorIndex(
+		gomock.Any(),
+		&ethpb.ValidatorIndexRequest{PublicKey: pubKey[:]},
+	).Return(&ethpb.ValidatorIndexResponse{Index: 12}, nil /*err*/)
+	domain := make([]byte, 32)
+	domain[0] = 4
+	validatorClient.EXPECT().DomainData(
+		gomock.Any(),
+		gomock.Any(),
+	).Return(&ethpb.DomainResponse{SignatureDomain: domain}, nil /*err*/)
+	var proposed *ethpb.SignedVoluntaryExit
+	validatorClient.EXPECT().ProposeExit(
+		gomock.Any(),
+		gomock.Any(),
+	).DoAndReturn(func(_ context.Context, exit *ethpb.SignedVoluntaryExit) (*ptypes.Empty, error) {
+		proposed = exit
+		return &ptypes.Empty{}, nil
+	})
+
+	exit, err := submitExit(ctx, keymanager, validatorClient, pubKey, 300 /* epoch */)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(12), exit.ValidatorIndex)
+	assert.Equal(t, uint64(300), exit.Epoch)
+	require.NotNil(t, proposed)
+	assert.DeepEqual(t, &ethpb.VoluntaryExit{Epoch: 300, ValidatorIndex: 12}, proposed.Exit)
+	signingRoot, err := helpers.ComputeSigningRoot(proposed.Exit, domain)
+	require.NoError(t, err)
+	sig, err := bls.SignatureFromBytes(proposed.Signature)
+	require.NoError(t, err)
+	blsPubKey, err := bls.PublicKeyFromBytes(pubKey[:])
+	require.NoError(t, err)
+	assert.Equal(t, true, sig.Verify(blsPubKey, signingRoot[:]), "Expected exit signed by the validating key")
+
+	exits, err := wallet.readAccountExits()
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(exits.Accounts))
+	exits.Accounts["0x01"] = exit
+	require.NoError(t, wallet.writeAccountExits(ctx, exits))
+	read, err := wallet.readAccountExits()
+	require.NoError(t, err)
+	assert.DeepEqual(t, exits, read)
+
+	assert.NoError(t, confirmExitPhrase(exitConfirmPhrase+"\n"))
+	assert.ErrorContains(t, "to confirm the exit", confirmExitPhrase("yes"))
+}
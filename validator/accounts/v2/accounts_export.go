@@ -2,8 +2,11 @@ package v2
 
 import (
 	"archive/zip"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"sort"
@@ -14,6 +17,8 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prysmaticlabs/prysm/shared/params"
 	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/derived"
 	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
 	"github.com/urfave/cli/v2"
 )
@@ -21,13 +26,200 @@ import (
 const allAccountsText = "All accounts"
 const archiveFilename = "backup.zip"
 
-// ExportAccount creates a zip archive of the selected accounts to be used in the future for importing accounts.
+// exportedKeystoreFileNameFormat is the filename of an exported keystore for an account name.
+const exportedKeystoreFileNameFormat = "keystore-%s.json"
+
+// keystoreExporter defines a keymanager capable of re-encrypting its
+// accounts into standalone EIP-2335 keystores.
+type keystoreExporter interface {
+	PublicKeyForAccount(accountName string) ([48]byte, error)
+	ExportKeystores(ctx context.Context, publicKeys [][48]byte, exportPassword string) ([]*v2keymanager.Keystore, error)
+}
+
+// ExportAccount re-encrypts the selected accounts of a wallet into standalone EIP-2335
+// keystore files protected by an export password, for migrating to other eth2 clients. Exports from
+// wallets with an approval policy must be approved by the --approval-tokens of its operators.
 func ExportAccount(cliCtx *cli.Context) error {
-	// TODO(#6777): Re-enable export command.
-	return errors.New("this feature is unimplemented")
+	ctx := context.Background()
+	exportFormat := cliCtx.String(flags.ExportFormatFlag.Name)
+	if err := validateExportFormat(exportFormat); err != nil {
+		return err
+	}
+	wallet, err := OpenWallet(cliCtx)
+	if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	keymanager, err := wallet.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+	if err != nil {
+		return errors.Wrap(err, "could not initialize keymanager")
+	}
+	var accountNames []string
+	switch km := keymanager.(type) {
+	case *direct.Keymanager:
+		accountNames, err = km.ValidatingAccountNames()
+	case *derived.Keymanager:
+		accountNames, err = km.ValidatingAccountNames(ctx)
+	default:
+		return fmt.Errorf("exporting accounts is not supported for %s wallets", wallet.KeymanagerKind())
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not fetch account names")
+	}
+	if len(accountNames) == 0 {
+		return errors.New("wallet has no accounts to export")
+	}
+	exporter, ok := keymanager.(keystoreExporter)
+	if !ok {
+		return fmt.Errorf("exporting accounts is not supported for %s wallets", wallet.KeymanagerKind())
+	}
+	pubKeys := make([][48]byte, len(accountNames))
+	for i, name := range accountNames {
+		pubKeys[i], err = exporter.PublicKeyForAccount(name)
+		if err != nil {
+			return errors.Wrapf(err, "could not get public key for account %s", name)
+		}
+	}
+	labelFilter, err := wallet.labelFilterFromCli(cliCtx)
+	if err != nil {
+		return err
+	}
+	var selectedAccounts []string
+	if labelFilter.active() {
+		accountNames, pubKeys = filterAccountsByLabels(accountNames, pubKeys, labelFilter)
+		if len(accountNames) == 0 {
+			return errors.New("no accounts of the wallet have the selected labels")
+		}
+	}
+	if labelFilter.active() && !cliCtx.IsSet(flags.AccountsFlag.Name) && !cliCtx.IsSet(flags.PubKeysFileFlag.Name) {
+		selectedAccounts = accountNames
+	} else {
+		selectedAccounts, err = selectAccounts(cliCtx, accountNames, pubKeys)
+		if err != nil {
+			return errors.Wrap(err, "could not select accounts")
+		}
+	}
+	if len(selectedAccounts) == 0 {
+		return errors.New("no accounts selected for export")
+	}
+	exportDir, err := inputDirectory(cliCtx, exportDirPromptText, flags.BackupDirFlag)
+	if err != nil {
+		return errors.Wrap(err, "could not parse output directory")
+	}
+	exportPassword, err := inputPassword(cliCtx, flags.ExportPasswordFileFlag, exportPasswordPromptText, confirmPass)
+	if err != nil {
+		return errors.Wrap(err, "could not get export password")
+	}
+	selectedPubKeys := make([][48]byte, len(selectedAccounts))
+	for i, name := range selectedAccounts {
+		for j := range accountNames {
+			if accountNames[j] == name {
+				selectedPubKeys[i] = pubKeys[j]
+			}
+		}
+	}
+	if err := wallet.checkApproval(cliCtx, approvalActionExportAccounts, pubKeySubjects(selectedPubKeys)); err != nil {
+		return err
+	}
+	if err := wallet.checkTOTP(cliCtx, "exporting accounts"); err != nil {
+		return err
+	}
+	if err := checkExportPaths(cliCtx, exportDir, exportFormat, selectedAccounts, selectedPubKeys); err != nil {
+		return err
+	}
+	// Write the slashing protection history first, so keystores are never exported without it.
+	if cliCtx.String(flags.SlashingProtectionFileFlag.Name) != "" {
+		if err := exportSlashingProtection(ctx, cliCtx, selectedPubKeys); err != nil {
+			return err
+		}
+	}
+	if exportFormat == webExportFormat {
+		return wallet.exportWebAccounts(ctx, exporter, exportDir, selectedAccounts, selectedPubKeys, exportPassword)
+	}
+	keystores, err := exporter.ExportKeystores(ctx, selectedPubKeys, exportPassword)
+	if err != nil {
+		return errors.Wrap(err, "could not export keystores")
+	}
+	if err := writeExportedKeystores(exportDir, selectedAccounts, keystores); err != nil {
+		return err
+	}
+	if exportFormat == web3SignerExportFormat {
+		if err := writeSignerKeyConfigs(exportDir, selectedAccounts, selectedPubKeys); err != nil {
+			return err
+		}
+	}
+	return logAccountsExported(exportDir, selectedAccounts, selectedPubKeys)
+}
+
+// Checks none of the files an export writes already exists, and that no two of them have the
+// same path, before any of them is written.
+func checkExportPaths(
+	cliCtx *cli.Context,
+	exportDir string,
+	exportFormat string,
+	accountNames []string,
+	pubKeys [][48]byte,
+) error {
+	var filePaths []string
+	if cliCtx.String(flags.SlashingProtectionFileFlag.Name) != "" {
+		filePath, err := expandPath(cliCtx.String(flags.SlashingProtectionFileFlag.Name))
+		if err != nil {
+			return errors.Wrap(err, "could not parse slashing protection file path")
+		}
+		filePaths = append(filePaths, filePath)
+	}
+	if exportFormat == webExportFormat {
+		filePaths = append(filePaths, filepath.Join(exportDir, webAccountsFileName))
+	} else {
+		for _, name := range accountNames {
+			filePaths = append(filePaths, filepath.Join(exportDir, fmt.Sprintf(exportedKeystoreFileNameFormat, name)))
+		}
+	}
+	if exportFormat == web3SignerExportFormat {
+		for _, pubKey := range pubKeys {
+			filePaths = append(filePaths, filepath.Join(exportDir, fmt.Sprintf(signerKeyConfigFileNameFormat, pubKey)))
+		}
+	}
+	seen := make(map[string]bool, len(filePaths))
+	for _, filePath := range filePaths {
+		filePath = filepath.Clean(filePath)
+		if seen[filePath] {
+			return fmt.Errorf("export would write %s more than once", filePath)
+		}
+		seen[filePath] = true
+		if fileExists(filePath) {
+			return fmt.Errorf("file already exists at path %s, nothing was exported", filePath)
+		}
+	}
+	return nil
 }
 
-func selectAccounts(cliCtx *cli.Context, accounts []string) ([]string, error) {
+func writeExportedKeystores(exportDir string, accountNames []string, keystores []*v2keymanager.Keystore) error {
+	if err := os.MkdirAll(exportDir, params.BeaconIoConfig().ReadWriteExecutePermissions); err != nil {
+		return errors.Wrap(err, "could not create export directory")
+	}
+	for i, keystore := range keystores {
+		filePath := filepath.Join(exportDir, fmt.Sprintf(exportedKeystoreFileNameFormat, accountNames[i]))
+		if fileExists(filePath) {
+			return fmt.Errorf("keystore file already exists at path: %s", filePath)
+		}
+		encoded, err := json.MarshalIndent(keystore, "", "\t")
+		if err != nil {
+			return errors.Wrap(err, "could not marshal keystore")
+		}
+		if err := ioutil.WriteFile(filePath, encoded, params.BeaconIoConfig().ReadWritePermissions); err != nil {
+			return errors.Wrapf(err, "could not write %s", filePath)
+		}
+	}
+	return nil
+}
+
+// selectAccounts from the flag values, either account names or 0x-prefixed
+// public keys, or the public keys listed in a file, or interactively if neither
+// flag is set.
+func selectAccounts(cliCtx *cli.Context, accounts []string, pubKeys [][48]byte) ([]string, error) {
+	if cliCtx.IsSet(flags.PubKeysFileFlag.Name) {
+		return selectAccountsFromFile(cliCtx, accounts, pubKeys)
+	}
 	if len(accounts) == 1 {
 		return accounts, nil
 	}
@@ -36,13 +228,21 @@ func selectAccounts(cliCtx *cli.Context, accounts []string) ([]string, error) {
 		if len(enteredAccounts) == 1 && enteredAccounts[0] == "all" {
 			return accounts, nil
 		}
-		allAccountsStr := strings.Join(accounts, " ")
-		for _, accountName := range enteredAccounts {
-			if !strings.Contains(allAccountsStr, accountName) {
-				return nil, fmt.Errorf("entered account %s not found in given wallet directory", accountName)
+		selected := make([]string, 0, len(enteredAccounts))
+		for _, entered := range enteredAccounts {
+			found := false
+			for i, accountName := range accounts {
+				if entered == accountName || strings.EqualFold(entered, fmt.Sprintf("%#x", pubKeys[i])) {
+					selected = append(selected, accountName)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("entered account %s not found in given wallet directory", entered)
 			}
 		}
-		return enteredAccounts, nil
+		return selected, nil
 	}
 	templates := &promptui.SelectTemplates{
 		Label:    "{{ . }}",
@@ -179,7 +379,7 @@ func copyFileFromZip(archive *zip.Writer, sourcePath string, info os.FileInfo, p
 	return err
 }
 
-func logAccountsExported(wallet *Wallet, keymanager *direct.Keymanager, accountNames []string) error {
+func logAccountsExported(exportDir string, accountNames []string, pubKeys [][48]byte) error {
 	au := aurora.NewAurora(true)
 
 	numAccounts := au.BrightYellow(len(accountNames))
@@ -189,18 +389,13 @@ func logAccountsExported(wallet *Wallet, keymanager *direct.Keymanager, accountN
 	} else {
 		fmt.Printf("Exported %d validator accounts\n", numAccounts)
 	}
-	for _, accountName := range accountNames {
+	for i, accountName := range accountNames {
 		fmt.Println("")
 		fmt.Printf("%s\n", au.BrightGreen(accountName).Bold())
+		fmt.Printf("%s %#x\n", au.BrightMagenta("[public key]").Bold(), pubKeys[i])
 
-		publicKey, err := keymanager.PublicKeyForAccount(accountName)
-		if err != nil {
-			return errors.Wrap(err, "could not get public key")
-		}
-		fmt.Printf("%s %#x\n", au.BrightMagenta("[public key]").Bold(), publicKey)
-
-		dirPath := au.BrightCyan("(wallet dir)")
-		fmt.Printf("%s %s\n", dirPath, filepath.Join(wallet.AccountsDir(), accountName))
+		keystorePath := au.BrightCyan("(keystore path)")
+		fmt.Printf("%s %s\n", keystorePath, filepath.Join(exportDir, fmt.Sprintf(exportedKeystoreFileNameFormat, accountName)))
 	}
 	return nil
 }
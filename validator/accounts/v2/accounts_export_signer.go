@@ -0,0 +1,72 @@
+package v2
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/params"
+)
+
+const (
+	// Standalone EIP-2335 keystores, as imported by eth2 clients.
+	prysmExportFormat = "prysm"
+	// EIP-2335 keystores with a key configuration file each, as provisioned into Web3Signer,
+	// the remote signer fronting HSMs and other dedicated signing hardware.
+	web3SignerExportFormat = "web3signer"
+	// A single file of accounts protected by passwords derived from the export password, for the web UI.
+	webExportFormat = "web"
+)
+
+const (
+	// signerKeyConfigFileNameFormat is the key configuration file name for a public key.
+	signerKeyConfigFileNameFormat = "%#x.yaml"
+	// signerPasswordFileName is the file every key configuration reads the export password from.
+	// It is never written by the export, the password has to be provisioned on the signer itself.
+	signerPasswordFileName = "keystore-password.txt"
+)
+
+const web3SignerKeyConfigTemplate = `type: "file-keystore"
+keyType: "BLS"
+keystoreFile: %q
+keystorePasswordFile: %q
+`
+
+// Checks an export format is one of the supported ones.
+func validateExportFormat(format string) error {
+	switch format {
+	case "", prysmExportFormat, web3SignerExportFormat, webExportFormat:
+		return nil
+	default:
+		return fmt.Errorf(
+			"unknown export format %q, expected one of %s, %s, %s",
+			format,
+			prysmExportFormat,
+			web3SignerExportFormat,
+			webExportFormat,
+		)
+	}
+}
+
+// Writes a Web3Signer key configuration next to every exported keystore, so the export
+// directory can be used as the key store of the signer as is.
+func writeSignerKeyConfigs(exportDir string, accountNames []string, pubKeys [][48]byte) error {
+	passwordPath := filepath.Join(exportDir, signerPasswordFileName)
+	for i, accountName := range accountNames {
+		keystorePath := filepath.Join(exportDir, fmt.Sprintf(exportedKeystoreFileNameFormat, accountName))
+		filePath := filepath.Join(exportDir, fmt.Sprintf(signerKeyConfigFileNameFormat, pubKeys[i]))
+		if fileExists(filePath) {
+			return fmt.Errorf("key configuration file already exists at path: %s", filePath)
+		}
+		encoded := fmt.Sprintf(web3SignerKeyConfigTemplate, keystorePath, passwordPath)
+		if err := ioutil.WriteFile(filePath, []byte(encoded), params.BeaconIoConfig().ReadWritePermissions); err != nil {
+			return errors.Wrapf(err, "could not write %s", filePath)
+		}
+	}
+	log.WithField("path", passwordPath).Info(
+		"Wrote signer key configurations, provision the export password at this path on the signer, " +
+			"or edit the key configurations if the bundle is moved",
+	)
+	return nil
+}
@@ -0,0 +1,141 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/derived"
+	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+)
+
+func TestExportAccount_Derived(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	exportDir := filepath.Join(testutil.TempDir(), exportDirName, t.Name())
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(exportDir), "Failed to remove directory")
+	})
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		walletPasswordFile:  passwordFile,
+		accountPasswordFile: passwordFile,
+		keymanagerKind:      v2keymanager.Derived,
+		numAccounts:         3,
+	})
+	_, err := CreateWallet(cliCtx)
+	require.NoError(t, err)
+	require.NoError(t, CreateAccount(cliCtx))
+
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	keymanager, err := wallet.InitializeKeymanager(ctx, true)
+	require.NoError(t, err)
+	km, ok := keymanager.(*derived.Keymanager)
+	require.Equal(t, true, ok)
+	names, err := km.ValidatingAccountNames(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(names))
+	pubKey, err := km.PublicKeyForAccount(names[1])
+	require.NoError(t, err)
+
+	// We select the account to export by its public key.
+	cliCtx = setupWalletCtx(t, &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFile,
+		exportPasswordFile: passwordFile,
+		exportDir:          exportDir,
+		accountsToExport:   fmt.Sprintf("%#x", pubKey),
+		keymanagerKind:     v2keymanager.Derived,
+	})
+	require.NoError(t, ExportAccount(cliCtx))
+
+	encoded, err := ioutil.ReadFile(filepath.Join(exportDir, fmt.Sprintf(exportedKeystoreFileNameFormat, names[1])))
+	require.NoError(t, err)
+	keystore := &v2keymanager.Keystore{}
+	require.NoError(t, json.Unmarshal(encoded, keystore))
+	assert.Equal(t, fmt.Sprintf("%x", pubKey), keystore.Pubkey)
+	assert.Equal(t, fmt.Sprintf(derived.ValidatingKeyDerivationPathTemplate, 1), keystore.Path)
+	_, err = keystorev4.New().Decrypt(keystore.Crypto, password)
+	require.NoError(t, err)
+
+	// Exporting the same account again must not overwrite the existing keystore.
+	assert.ErrorContains(t, "already exists", ExportAccount(cliCtx))
+}
+
+func TestExportAccount_Web3Signer(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	exportDir := filepath.Join(testutil.TempDir(), exportDirName, t.Name())
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(exportDir), "Failed to remove directory")
+	})
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		walletPasswordFile:  passwordFile,
+		accountPasswordFile: passwordFile,
+		keymanagerKind:      v2keymanager.Derived,
+		numAccounts:         2,
+	})
+	_, err := CreateWallet(cliCtx)
+	require.NoError(t, err)
+	require.NoError(t, CreateAccount(cliCtx))
+
+	cliCtx = setupWalletCtx(t, &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFile,
+		exportPasswordFile: passwordFile,
+		exportDir:          exportDir,
+		exportFormat:       web3SignerExportFormat,
+		accountsToExport:   "all",
+		keymanagerKind:     v2keymanager.Derived,
+	})
+	require.NoError(t, ExportAccount(cliCtx))
+
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	keymanager, err := wallet.InitializeKeymanager(ctx, true)
+	require.NoError(t, err)
+	km, ok := keymanager.(*derived.Keymanager)
+	require.Equal(t, true, ok)
+	names, err := km.ValidatingAccountNames(ctx)
+	require.NoError(t, err)
+	for _, name := range names {
+		pubKey, err := km.PublicKeyForAccount(name)
+		require.NoError(t, err)
+		encoded, err := ioutil.ReadFile(filepath.Join(exportDir, fmt.Sprintf(signerKeyConfigFileNameFormat, pubKey)))
+		require.NoError(t, err)
+		keystorePath := filepath.Join(exportDir, fmt.Sprintf(exportedKeystoreFileNameFormat, name))
+		assert.Equal(t, fmt.Sprintf(web3SignerKeyConfigTemplate, keystorePath, filepath.Join(exportDir, signerPasswordFileName)), string(encoded))
+		assert.Equal(t, true, fileExists(keystorePath))
+	}
+	assert.Equal(t, false, fileExists(filepath.Join(exportDir, signerPasswordFileName)), "Export password must not be written")
+
+	// Nothing is written if a key configuration would overwrite an existing file.
+	pubKey, err := km.PublicKeyForAccount(names[0])
+	require.NoError(t, err)
+	keystorePath := filepath.Join(exportDir, fmt.Sprintf(exportedKeystoreFileNameFormat, names[0]))
+	require.NoError(t, os.Remove(keystorePath))
+	assert.ErrorContains(t, "already exists", ExportAccount(cliCtx))
+	assert.Equal(t, false, fileExists(keystorePath))
+	assert.Equal(t, true, fileExists(filepath.Join(exportDir, fmt.Sprintf(signerKeyConfigFileNameFormat, pubKey))))
+}
+
+func TestExportAccount_UnknownFormat(t *testing.T) {
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		exportFormat: "yubihsm",
+	})
+	assert.ErrorContains(t, "unknown export format", ExportAccount(cliCtx))
+}
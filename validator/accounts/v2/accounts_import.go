@@ -1,43 +1,53 @@
 package v2
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/logrusorgru/aurora"
 	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/bls"
 	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/depositutil"
 	"github.com/prysmaticlabs/prysm/shared/petnames"
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
 	"github.com/prysmaticlabs/prysm/validator/flags"
 	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
 	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
 	"github.com/urfave/cli/v2"
+	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+)
+
+const (
+	// Keystores exported by Prysm or the eth2 deposit cli, with passwords entered by the user.
+	prysmImportFormat = "prysm"
+	// Keystores in a directory with a parallel directory of password files, as used by Teku.
+	tekuImportFormat = "teku"
+	// A data directory with validators/ and secrets/ directories keyed by public key, as used by Nimbus.
+	nimbusImportFormat = "nimbus"
+	// A validators directory keyed by public key with a secrets directory next to it, as used by Lighthouse.
+	lighthouseImportFormat = "lighthouse"
+	// Wealdtech filesystem wallets, of the nd or hd type, as created by ethdo.
+	ethdoImportFormat = "ethdo"
+	// A single file of accounts protected by passwords derived from the wallet password of the web UI.
+	webImportFormat = "web"
 )
 
 // ImportAccount uses the archived account made from ExportAccount to import an account and
 // asks the users for account passwords.
 func ImportAccount(cliCtx *cli.Context) error {
-	ctx := context.Background()
-	wallet, err := createOrOpenWallet(cliCtx, func(cliCtx *cli.Context) (*Wallet, error) {
-		w, err := NewWallet(cliCtx, v2keymanager.Direct)
-		if err != nil && !errors.Is(err, ErrWalletExists) {
-			return nil, errors.Wrap(err, "could not create new wallet")
-		}
-		if err = createDirectKeymanagerWallet(cliCtx, w); err != nil {
-			return nil, errors.Wrap(err, "could not initialize wallet")
-		}
-		log.WithField("wallet-path", w.walletDir).Info(
-			"Successfully created new wallet",
-		)
-		return w, err
-	})
+	ctx := withJournalAction(context.Background(), journalActionAccountImported)
+	wallet, err := createOrOpenWallet(cliCtx, createDirectWallet)
 	if err != nil {
 		return errors.Wrap(err, "could not initialize wallet")
 	}
@@ -46,22 +56,116 @@ func ImportAccount(cliCtx *cli.Context) error {
 			"only non-HD wallets can import accounts, try creating a new wallet with wallet-v2 create",
 		)
 	}
-	keysDir, err := inputDirectory(cliCtx, importKeysDirPromptText, flags.KeysDirFlag)
+	filter, err := pubKeyFilterFromCli(cliCtx)
 	if err != nil {
-		return errors.Wrap(err, "could not parse keys directory")
+		return err
+	}
+	defer func() {
+		for _, pubKey := range filter.missingIncludes() {
+			log.WithField("publicKey", fmt.Sprintf("%#x", pubKey)).Warn(
+				"Included public key was not found in any of the keystores to import",
+			)
+		}
+	}()
+	if cliCtx.String(flags.SlashingProtectionFileFlag.Name) != "" {
+		if err := importSlashingProtection(ctx, cliCtx, filter); err != nil {
+			return err
+		}
+	}
+	if err := wallet.restoreTombstones(ctx, cliCtx); err != nil {
+		return err
+	}
+	if cliCtx.IsSet(flags.PrivateKeyFileFlag.Name) {
+		return importPrivateKeyFile(ctx, cliCtx, wallet, filter)
+	}
+	switch format := cliCtx.String(flags.ImportFormatFlag.Name); format {
+	case "", prysmImportFormat:
+	case tekuImportFormat:
+		return importTekuAccounts(ctx, cliCtx, wallet, filter)
+	case nimbusImportFormat:
+		return importNimbusAccounts(ctx, cliCtx, wallet, filter)
+	case lighthouseImportFormat:
+		return importLighthouseAccounts(ctx, cliCtx, wallet, filter)
+	case ethdoImportFormat:
+		return importEthdoAccounts(ctx, cliCtx, wallet, filter)
+	case webImportFormat:
+		return importWebAccounts(ctx, cliCtx, wallet, filter)
+	default:
+		return fmt.Errorf(
+			"unknown import format %q, expected one of %s, %s, %s, %s, %s, %s",
+			format,
+			prysmImportFormat,
+			tekuImportFormat,
+			nimbusImportFormat,
+			lighthouseImportFormat,
+			ethdoImportFormat,
+			webImportFormat,
+		)
+	}
+	var keysDir string
+	if cliCtx.String(flags.KeystoresURLFlag.Name) != "" {
+		downloadPath, cleanup, err := downloadKeystores(ctx, cliCtx)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		keysDir = downloadPath
+	} else {
+		keysDir, err = inputDirectory(cliCtx, importKeysDirPromptText, flags.KeysDirFlag)
+		if err != nil {
+			return errors.Wrap(err, "could not parse keys directory")
+		}
 	}
 	if err := wallet.SaveWallet(); err != nil {
 		return errors.Wrap(err, "could not save wallet")
 	}
+	existing, err := walletKeysFromCli(ctx, cliCtx, wallet)
+	if err != nil {
+		return err
+	}
+	defer existing.reportSkipped()
 	accountsImported := make([]string, 0)
 	pubKeysImported := make([][]byte, 0)
 	isDir, err := hasDir(keysDir)
 	if err != nil {
 		return errors.Wrap(err, "could not determine if path is a directory")
 	}
+	passwords, err := keystorePasswordsFromCli(cliCtx)
+	if err != nil {
+		return err
+	}
+	if passwords != nil && passwords.byKey != nil && !isDir {
+		return fmt.Errorf("--%s can only be used to import a directory of keystores", flags.KeystorePasswordsFileFlag.Name)
+	}
 
 	// Consider that the keysDir might be a path to a specific file and handle accordingly.
+	var deposits map[[48]byte]*depositutil.DepositDataJSON
 	if isDir {
+		// An eth2.0-deposit-cli output folder also holds the deposit data of its keystores.
+		deposits, err = readDepositCLIDepositData(keysDir)
+		if err != nil {
+			return errors.Wrap(err, "could not read deposit data")
+		}
+		if len(deposits) > 0 {
+			log.Info("Detected eth2.0-deposit-cli output, linking deposit data to the imported keystores")
+		}
+		// With the account passwords known upfront, keystores can be streamed into the wallet
+		// instead of being held in memory until every password has been entered.
+		if passwords != nil {
+			imported, linked, err := wallet.streamKeystoresDir(ctx, keysDir, passwords, existing, filter, deposits)
+			if err != nil {
+				existing.restoreReplaced(wallet)
+				return err
+			}
+			if len(deposits) > 0 {
+				log.Infof("Linked deposit data to %d imported accounts", linked)
+			}
+			fmt.Printf(
+				"Successfully imported %s accounts, view all of them by running accounts-v2 list\n",
+				aurora.NewAurora(true).BrightMagenta(strconv.Itoa(imported)),
+			)
+			return nil
+		}
 		files, err := ioutil.ReadDir(keysDir)
 		if err != nil {
 			return errors.Wrap(err, "could not read dir")
@@ -73,20 +177,32 @@ func ImportAccount(cliCtx *cli.Context) error {
 			if !strings.HasPrefix(files[i].Name(), "keystore") {
 				continue
 			}
-			accountName, pubKey, err := wallet.importKeystore(ctx, filepath.Join(keysDir, files[i].Name()))
+			accountName, pubKey, err := wallet.importKeystore(ctx, filepath.Join(keysDir, files[i].Name()), existing, filter)
 			if err != nil {
+				existing.restoreReplaced(wallet)
 				return errors.Wrap(err, "could not import keystore")
 			}
+			if pubKey == nil {
+				continue
+			}
 			accountsImported = append(accountsImported, accountName)
 			pubKeysImported = append(pubKeysImported, pubKey)
 		}
+	} else if isKeystoreArchive(keysDir) {
+		accountsImported, pubKeysImported, err = wallet.importKeystoreArchive(ctx, keysDir, existing, filter)
+		if err != nil {
+			existing.restoreReplaced(wallet)
+			return errors.Wrap(err, "could not import keystore archive")
+		}
 	} else {
-		accountName, pubKey, err := wallet.importKeystore(ctx, keysDir)
+		accountName, pubKey, err := wallet.importKeystore(ctx, keysDir, existing, filter)
 		if err != nil {
 			return errors.Wrap(err, "could not import keystore")
 		}
-		accountsImported = append(accountsImported, accountName)
-		pubKeysImported = append(pubKeysImported, pubKey)
+		if pubKey != nil {
+			accountsImported = append(accountsImported, accountName)
+			pubKeysImported = append(pubKeysImported, pubKey)
+		}
 	}
 
 	au := aurora.NewAurora(true)
@@ -96,8 +212,18 @@ func ImportAccount(cliCtx *cli.Context) error {
 	}
 	fmt.Printf("Importing accounts: %s\n", au.BrightGreen(strings.Join(formattedPubkeys, ", ")))
 	if err := wallet.enterPasswordForAllAccounts(cliCtx, accountsImported, pubKeysImported); err != nil {
+		// Accounts overwritten with --reimport are only replaced once the password of the imported
+		// keystores is verified.
+		existing.restoreReplaced(wallet)
 		return errors.Wrap(err, "could not verify password for keystore")
 	}
+	if len(deposits) > 0 {
+		linked, err := wallet.linkDepositData(ctx, accountsImported, pubKeysImported, deposits)
+		if err != nil {
+			return errors.Wrap(err, "could not link deposit data")
+		}
+		log.Infof("Linked deposit data to %d imported accounts", linked)
+	}
 	fmt.Printf(
 		"Successfully imported %s accounts, view all of them by running accounts-v2 list\n",
 		au.BrightMagenta(strconv.Itoa(len(pubKeysImported))),
@@ -105,11 +231,234 @@ func ImportAccount(cliCtx *cli.Context) error {
 	return nil
 }
 
-func (w *Wallet) importKeystore(ctx context.Context, keystoreFilePath string) (string, []byte, error) {
+// Imports a keystore whose password is already known, storing the keystore unchanged
+// with the password as its account password and createdAt as its creation time. It returns
+// the public key of the imported account, or nil if the account is skipped as the wallet
+// already holds it or it is filtered out.
+func (w *Wallet) importKeystoreWithPassword(
+	ctx context.Context,
+	keystorePath string,
+	password string,
+	createdAt time.Time,
+	existing *walletKeys,
+	filter *pubKeyFilter,
+) ([]byte, error) {
+	keystoreBytes, err := ioutil.ReadFile(keystorePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read keystore file")
+	}
+	return w.importKeystoreBytesWithPassword(ctx, keystoreBytes, password, createdAt, existing, filter)
+}
+
+// Imports the bytes of a keystore whose password is already known, as importKeystoreWithPassword.
+func (w *Wallet) importKeystoreBytesWithPassword(
+	ctx context.Context,
+	keystoreBytes []byte,
+	password string,
+	createdAt time.Time,
+	existing *walletKeys,
+	filter *pubKeyFilter,
+) ([]byte, error) {
+	var err error
+	if isEthV3Keystore(keystoreBytes) {
+		keystoreBytes, err = convertEthV3Keystore(keystoreBytes, password)
+		if err != nil {
+			return nil, err
+		}
+	}
+	keystoreFile := &v2keymanager.Keystore{}
+	if err := json.Unmarshal(keystoreBytes, keystoreFile); err != nil {
+		return nil, errors.Wrap(err, "could not decode keystore json")
+	}
+	pubKeyBytes, err := hex.DecodeString(strings.TrimPrefix(keystoreFile.Pubkey, "0x"))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode public key string in keystore")
+	}
+	if !filter.allows(pubKeyBytes) {
+		return nil, nil
+	}
+
+	// Make sure the password unlocks the keystore and that it holds the key it claims to.
+	decryptor := keystorev4.New()
+	rawSigningKey, err := decryptor.Decrypt(keystoreFile.Crypto, password)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decrypt keystore")
+	}
+	secretKey, err := bls.SecretKeyFromBytes(rawSigningKey)
+	v2keymanager.ZeroSecret(rawSigningKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not determine signing key")
+	}
+	if !bytes.Equal(secretKey.PublicKey().Marshal(), pubKeyBytes) {
+		return nil, fmt.Errorf("keystore public key %#x does not match its signing key", bytesutil.Trunc(pubKeyBytes))
+	}
+	if ok, err := existing.claim(w, pubKeyBytes); err != nil || !ok {
+		return nil, err
+	}
+
+	accountName := petnames.DeterministicName(pubKeyBytes, "-")
+	keystoreBytes, password, err = w.keystoreForAccount(accountName, keystoreBytes, password)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encrypt keystore with the master password")
+	}
+	if err := w.WritePasswordToDisk(ctx, accountName+direct.PasswordFileSuffix, password); err != nil {
+		return nil, errors.Wrap(err, "could not write password to disk")
+	}
+	keystoreFileName := fmt.Sprintf(w.keystoreFileNameFormat(), createdAt.Unix())
+	if err := w.WriteFileAtPath(ctx, accountName, keystoreFileName, keystoreBytes); err != nil {
+		return nil, errors.Wrap(err, "could not write keystore to account dir")
+	}
+	if err := w.recordAccountCreation(ctx, accountName, createdAt); err != nil {
+		return nil, err
+	}
+	return pubKeyBytes, nil
+}
+
+// Imports keystores laid out as <validatorsDir>/0x<pubkey>/<keystoreFileName>, with the password
+// of each one stored as <secretsDir>/0x<pubkey>, which is the layout used by Lighthouse and Nimbus.
+// If keepCreationTime is set, the modification time of each keystore is kept as its creation time.
+func (w *Wallet) importPubKeyDirs(
+	ctx context.Context,
+	validatorsDir string,
+	secretsDir string,
+	keystoreFileName string,
+	keepCreationTime bool,
+	existing *walletKeys,
+	filter *pubKeyFilter,
+) ([][]byte, error) {
+	entries, err := ioutil.ReadDir(validatorsDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read validators directory")
+	}
+	pubKeysImported := make([][]byte, 0)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		keystorePath := filepath.Join(validatorsDir, entry.Name(), keystoreFileName)
+		info, err := os.Stat(keystorePath)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "could not read keystore for validator %s", entry.Name())
+		}
+		createdAt := roughtime.Now()
+		if keepCreationTime {
+			createdAt = info.ModTime()
+		}
+		secret, err := ioutil.ReadFile(filepath.Join(secretsDir, entry.Name()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read password for validator %s", entry.Name())
+		}
+		password := strings.TrimRight(string(secret), "\r\n")
+		pubKey, err := w.importKeystoreWithPassword(ctx, keystorePath, password, createdAt, existing, filter)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not import validator %s", entry.Name())
+		}
+		if pubKey != nil {
+			pubKeysImported = append(pubKeysImported, pubKey)
+		}
+	}
+	return pubKeysImported, nil
+}
+
+// Stores secret keys as new accounts of a non-HD wallet, all protected by the same password.
+// Keys filtered out, or already held by the wallet without --reimport, are skipped. It returns
+// the public keys of the new accounts.
+func (w *Wallet) importSecretKeys(
+	ctx context.Context,
+	secretKeys map[[48]byte]bls.SecretKey,
+	password string,
+	existing *walletKeys,
+	filter *pubKeyFilter,
+) ([][48]byte, error) {
+	keymanager, err := w.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not initialize keymanager")
+	}
+	km, ok := keymanager.(*direct.Keymanager)
+	if !ok {
+		return nil, errors.New("not a direct keymanager")
+	}
+	imported := make([][48]byte, 0, len(secretKeys))
+	for pubKey, secretKey := range secretKeys {
+		if !filter.allows(pubKey[:]) {
+			continue
+		}
+		if ok, err := existing.claim(w, pubKey[:]); err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
+		accountName, err := km.ImportSecretKey(ctx, secretKey, password)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not import key %#x", bytesutil.Trunc(pubKey[:]))
+		}
+		if err := w.recordAccountCreation(ctx, accountName, roughtime.Now()); err != nil {
+			return nil, err
+		}
+		log.WithField("name", accountName).Debug("Imported validating key")
+		imported = append(imported, pubKey)
+	}
+	return imported, nil
+}
+
+func printKeystoresImported(pubKeys [][]byte) {
+	formattedPubkeys := make([]string, len(pubKeys))
+	for i, pk := range pubKeys {
+		formattedPubkeys[i] = fmt.Sprintf("%#x", bytesutil.Trunc(pk))
+	}
+	fmt.Printf("Imported accounts: %s\n", au.BrightGreen(strings.Join(formattedPubkeys, ", ")))
+	fmt.Printf(
+		"Successfully imported %s accounts, view all of them by running accounts-v2 list\n",
+		au.BrightMagenta(strconv.Itoa(len(pubKeys))),
+	)
+}
+
+// Creates a new non-HD wallet for commands which import existing keys.
+func createDirectWallet(cliCtx *cli.Context) (*Wallet, error) {
+	w, err := NewWallet(cliCtx, v2keymanager.Direct)
+	if err != nil && !errors.Is(err, ErrWalletExists) {
+		return nil, errors.Wrap(err, "could not create new wallet")
+	}
+	if err = createDirectKeymanagerWallet(cliCtx, w); err != nil {
+		return nil, errors.Wrap(err, "could not initialize wallet")
+	}
+	log.WithField("wallet-path", w.walletDir).Info(
+		"Successfully created new wallet",
+	)
+	return w, err
+}
+
+func (w *Wallet) importKeystore(
+	ctx context.Context,
+	keystoreFilePath string,
+	existing *walletKeys,
+	filter *pubKeyFilter,
+) (string, []byte, error) {
 	keystoreBytes, err := ioutil.ReadFile(keystoreFilePath)
 	if err != nil {
 		return "", nil, errors.Wrap(err, "could not read keystore file")
 	}
+	return w.importKeystoreBytes(ctx, filepath.Base(keystoreFilePath), keystoreBytes, existing, filter)
+}
+
+// Stores a keystore in the wallet, returning its account name and public key, or a nil public
+// key if the keystore is filtered out or skipped as the wallet already holds it.
+func (w *Wallet) importKeystoreBytes(
+	ctx context.Context,
+	keystoreFileName string,
+	keystoreBytes []byte,
+	existing *walletKeys,
+	filter *pubKeyFilter,
+) (string, []byte, error) {
+	if isEthV3Keystore(keystoreBytes) {
+		return "", nil, fmt.Errorf(
+			"%s is an Ethereum v3 keystore, which can only be converted when importing a keystore directory with --%s",
+			keystoreFileName,
+			flags.AccountPasswordFileFlag.Name,
+		)
+	}
 	keystoreFile := &v2keymanager.Keystore{}
 	if err := json.Unmarshal(keystoreBytes, keystoreFile); err != nil {
 		return "", nil, errors.Wrap(err, "could not decode keystore json")
@@ -118,8 +467,13 @@ func (w *Wallet) importKeystore(ctx context.Context, keystoreFilePath string) (s
 	if err != nil {
 		return "", nil, errors.Wrap(err, "could not decode public key string in keystore")
 	}
+	if !filter.allows(pubKeyBytes) {
+		return "", nil, nil
+	}
+	if ok, err := existing.claim(w, pubKeyBytes); err != nil || !ok {
+		return "", nil, err
+	}
 	accountName := petnames.DeterministicName(pubKeyBytes, "-")
-	keystoreFileName := filepath.Base(keystoreFilePath)
 	if err := w.WriteFileAtPath(ctx, accountName, keystoreFileName, keystoreBytes); err != nil {
 		return "", nil, errors.Wrap(err, "could not write keystore to account dir")
 	}
@@ -139,11 +493,11 @@ func logAccountsImported(ctx context.Context, wallet *Wallet, keymanager *direct
 	for _, accountName := range accountNames {
 		fmt.Println("")
 		// Retrieve the account creation timestamp.
-		keystoreFileName, err := wallet.FileNameAtPath(ctx, accountName, direct.KeystoreFileName)
+		keystoreFileName, err := wallet.FileNameAtPath(ctx, accountName, wallet.keystoreFileGlob())
 		if err != nil {
 			return errors.Wrapf(err, "could not get keystore file name for account: %s", accountName)
 		}
-		unixTimestamp, err := AccountTimestamp(keystoreFileName)
+		unixTimestamp, err := wallet.keystoreFileTimestamp(keystoreFileName)
 		if err != nil {
 			return errors.Wrap(err, "could not get timestamp from keystore file name")
 		}
@@ -0,0 +1,162 @@
+package v2
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// Keystores are only a few kilobytes, anything larger in an archive is not a keystore.
+	maxArchivedKeystoreSize = 1 << 20
+	zipArchiveExtension     = ".zip"
+)
+
+var tarGzArchiveExtensions = []string{".tar.gz", ".tgz"}
+
+func isKeystoreArchive(filePath string) bool {
+	lower := strings.ToLower(filePath)
+	if strings.HasSuffix(lower, zipArchiveExtension) {
+		return true
+	}
+	for _, ext := range tarGzArchiveExtensions {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// Imports the keystore-*.json entries of a .zip or .tar.gz archive. Entries are read one at a
+// time straight from the archive, so it never has to be extracted or loaded into memory at once.
+func (w *Wallet) importKeystoreArchive(
+	ctx context.Context,
+	archivePath string,
+	existing *walletKeys,
+	filter *pubKeyFilter,
+) ([]string, [][]byte, error) {
+	if strings.HasSuffix(strings.ToLower(archivePath), zipArchiveExtension) {
+		return w.importZipKeystores(ctx, archivePath, existing, filter)
+	}
+	return w.importTarGzKeystores(ctx, archivePath, existing, filter)
+}
+
+func (w *Wallet) importZipKeystores(
+	ctx context.Context,
+	archivePath string,
+	existing *walletKeys,
+	filter *pubKeyFilter,
+) ([]string, [][]byte, error) {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not open zip archive")
+	}
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.WithError(err).Error("Could not close zip archive")
+		}
+	}()
+	accountNames := make([]string, 0)
+	pubKeys := make([][]byte, 0)
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() || !isArchivedKeystore(file.Name) {
+			continue
+		}
+		entry, err := file.Open()
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "could not open archived file %s", file.Name)
+		}
+		keystoreBytes, err := readArchivedKeystore(entry, file.Name)
+		if closeErr := entry.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		accountName, pubKey, err := w.importKeystoreBytes(ctx, path.Base(file.Name), keystoreBytes, existing, filter)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "could not import archived keystore %s", file.Name)
+		}
+		if pubKey == nil {
+			continue
+		}
+		accountNames = append(accountNames, accountName)
+		pubKeys = append(pubKeys, pubKey)
+	}
+	return accountNames, pubKeys, nil
+}
+
+func (w *Wallet) importTarGzKeystores(
+	ctx context.Context,
+	archivePath string,
+	existing *walletKeys,
+	filter *pubKeyFilter,
+) ([]string, [][]byte, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not open tar.gz archive")
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.WithError(err).Error("Could not close tar.gz archive")
+		}
+	}()
+	gzipReader, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not decompress archive")
+	}
+	tarReader := tar.NewReader(gzipReader)
+	accountNames := make([]string, 0)
+	pubKeys := make([][]byte, 0)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "could not read archive entry")
+		}
+		if header.Typeflag != tar.TypeReg || !isArchivedKeystore(header.Name) {
+			continue
+		}
+		keystoreBytes, err := readArchivedKeystore(tarReader, header.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		accountName, pubKey, err := w.importKeystoreBytes(ctx, path.Base(header.Name), keystoreBytes, existing, filter)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "could not import archived keystore %s", header.Name)
+		}
+		if pubKey == nil {
+			continue
+		}
+		accountNames = append(accountNames, accountName)
+		pubKeys = append(pubKeys, pubKey)
+	}
+	return accountNames, pubKeys, nil
+}
+
+func isArchivedKeystore(name string) bool {
+	base := path.Base(name)
+	return strings.HasPrefix(base, "keystore") && strings.HasSuffix(base, ".json")
+}
+
+func readArchivedKeystore(r io.Reader, name string) ([]byte, error) {
+	keystoreBytes, err := ioutil.ReadAll(io.LimitReader(r, maxArchivedKeystoreSize+1))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read archived file %s", name)
+	}
+	if len(keystoreBytes) > maxArchivedKeystoreSize {
+		return nil, fmt.Errorf("archived file %s is larger than %d bytes", name, maxArchivedKeystoreSize)
+	}
+	return keystoreBytes, nil
+}
@@ -0,0 +1,119 @@
+package v2
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+)
+
+func archivedKeystores(t *testing.T, numKeys int) map[string][]byte {
+	files := make(map[string][]byte, numKeys+1)
+	for i := 0; i < numKeys; i++ {
+		keystore, err := v2keymanager.NewKeystore(bls.RandKey(), "" /* path */, password)
+		require.NoError(t, err)
+		encoded, err := json.Marshal(keystore)
+		require.NoError(t, err)
+		files[fmt.Sprintf("validator_keys/keystore-%d.json", 1600000000+i)] = encoded
+	}
+	// Files which are not keystores must be skipped.
+	files["validator_keys/README.txt"] = []byte("not a keystore")
+	return files
+}
+
+func TestImport_ZipArchive(t *testing.T) {
+	archiveDir := filepath.Join(testutil.TempDir(), t.Name())
+	require.NoError(t, os.MkdirAll(archiveDir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(archiveDir), "Failed to remove directory")
+	})
+	archivePath := filepath.Join(archiveDir, "keys.zip")
+	f, err := os.Create(archivePath)
+	require.NoError(t, err)
+	writer := zip.NewWriter(f)
+	for name, data := range archivedKeystores(t, 2) {
+		entry, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = entry.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	require.NoError(t, f.Close())
+
+	assertArchiveImported(t, archivePath, 2)
+}
+
+func TestImport_TarGzArchive(t *testing.T) {
+	archiveDir := filepath.Join(testutil.TempDir(), t.Name())
+	require.NoError(t, os.MkdirAll(archiveDir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(archiveDir), "Failed to remove directory")
+	})
+	archivePath := filepath.Join(archiveDir, "keys.tar.gz")
+	f, err := os.Create(archivePath)
+	require.NoError(t, err)
+	gzipWriter := gzip.NewWriter(f)
+	writer := tar.NewWriter(gzipWriter)
+	for name, data := range archivedKeystores(t, 3) {
+		require.NoError(t, writer.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0600,
+			Size:     int64(len(data)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err = writer.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	require.NoError(t, gzipWriter.Close())
+	require.NoError(t, f.Close())
+
+	assertArchiveImported(t, archivePath, 3)
+}
+
+func assertArchiveImported(t *testing.T, archivePath string, numKeys int) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		keysDir:             archivePath,
+		keymanagerKind:      v2keymanager.Direct,
+		walletPasswordFile:  passwordFilePath,
+		accountPasswordFile: passwordFilePath,
+	})
+	require.NoError(t, ImportAccount(cliCtx))
+
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	km, err := wallet.InitializeKeymanager(ctx, true)
+	require.NoError(t, err)
+	keys, err := km.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, numKeys, len(keys))
+}
+
+func TestReadArchivedKeystore_TooLarge(t *testing.T) {
+	_, err := readArchivedKeystore(&zeroReader{}, "keystore-huge.json")
+	assert.ErrorContains(t, "is larger than", err)
+}
+
+type zeroReader struct{}
+
+func (*zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
@@ -0,0 +1,153 @@
+package v2
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+)
+
+// importCheckpointFileNameFormat names the checkpoint of an import by a digest of the absolute
+// path of the keystores it imports, so interrupted imports of different sources never clash.
+const importCheckpointFileNameFormat = "import-%x.checkpoint"
+
+// importCheckpointInterval is the number of accounts an import writes between two writes of its
+// checkpoint. Accounts written since the last checkpoint are decrypted again when the import is
+// resumed, and skipped as the wallet already holds them.
+const importCheckpointInterval = 64
+
+// importCheckpoint records, one per line, the public keys of the accounts an import has fully
+// written to the wallet. When an import is interrupted, the next import of the same keystores
+// skips those keys before decrypting their keystores, which is the expensive part of an import.
+// The checkpoint is written atomically every importCheckpointInterval accounts and when the
+// import stops, and removed once an import completes. It is safe for concurrent use by the
+// import workers.
+type importCheckpoint struct {
+	mu       sync.Mutex
+	path     string
+	done     map[[48]byte]bool
+	ordered  [][48]byte
+	recorded int
+	resumed  int
+}
+
+// Opens the checkpoint of an import of the keystores at source, reading the public keys an
+// interrupted import of the same keystores already wrote to the wallet.
+func (w *Wallet) openImportCheckpoint(source string) (*importCheckpoint, error) {
+	absSource, err := filepath.Abs(source)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not determine absolute path of keystores")
+	}
+	digest := sha256.Sum256([]byte(absSource))
+	checkpoint := &importCheckpoint{
+		path: filepath.Join(w.AccountsDir(), fmt.Sprintf(importCheckpointFileNameFormat, digest[:8])),
+		done: make(map[[48]byte]bool),
+	}
+	if err := checkpoint.read(); err != nil {
+		return nil, err
+	}
+	if len(checkpoint.done) > 0 {
+		log.WithField("alreadyImported", len(checkpoint.done)).Info(
+			"Resuming an interrupted import of the same keystores",
+		)
+	}
+	return checkpoint, nil
+}
+
+func (c *importCheckpoint) read() error {
+	f, err := os.Open(c.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not open import checkpoint")
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.WithError(err).Error("Could not close import checkpoint")
+		}
+	}()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Lines which are not public keys are ignored, the keys of such lines are imported again.
+		pubKey, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(scanner.Text()), "0x"))
+		if err != nil || len(pubKey) != 48 {
+			continue
+		}
+		key := bytesutil.ToBytes48(pubKey)
+		if !c.done[key] {
+			c.done[key] = true
+			c.ordered = append(c.ordered, key)
+		}
+	}
+	return errors.Wrap(scanner.Err(), "could not read import checkpoint")
+}
+
+// Reports whether an interrupted import already wrote the account of the public key.
+func (c *importCheckpoint) isDone(pubKey []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.done[bytesutil.ToBytes48(pubKey)] {
+		return false
+	}
+	c.resumed++
+	return true
+}
+
+// Records that the account of the public key is fully written to the wallet.
+func (c *importCheckpoint) record(pubKey []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := bytesutil.ToBytes48(pubKey)
+	if c.done[key] {
+		return nil
+	}
+	c.done[key] = true
+	c.ordered = append(c.ordered, key)
+	c.recorded++
+	if c.recorded%importCheckpointInterval != 0 {
+		return nil
+	}
+	return c.write()
+}
+
+// Writes the public keys recorded so far to the checkpoint file. The caller holds the lock.
+func (c *importCheckpoint) write() error {
+	var buf bytes.Buffer
+	for _, pubKey := range c.ordered {
+		fmt.Fprintf(&buf, "%#x\n", pubKey)
+	}
+	if err := writeFileAtomic(c.path, buf.Bytes(), 0600); err != nil {
+		return errors.Wrap(err, "could not write import checkpoint")
+	}
+	return nil
+}
+
+// Closes the checkpoint, removing it if the import completed so a later import of the same
+// keystores starts over.
+func (c *importCheckpoint) close(completed bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.resumed > 0 {
+		log.WithField("skipped", c.resumed).Info("Skipped keystores already imported before the interruption")
+	}
+	if !completed {
+		if err := c.write(); err != nil {
+			return err
+		}
+		log.WithField("path", c.path).Info("Import checkpoint kept, run the same import again to resume it")
+		return nil
+	}
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "could not remove import checkpoint")
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package v2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+)
+
+func checkpointPath(t *testing.T, walletDir string, keysDir string) string {
+	absKeysDir, err := filepath.Abs(keysDir)
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte(absKeysDir))
+	return filepath.Join(
+		walletDir,
+		v2keymanager.Direct.String(),
+		fmt.Sprintf(importCheckpointFileNameFormat, digest[:8]),
+	)
+}
+
+func TestImport_ResumesFromCheckpoint(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	keysDir := filepath.Join(testutil.TempDir(), t.Name())
+	require.NoError(t, os.MkdirAll(keysDir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(keysDir), "Failed to remove directory")
+	})
+	writeKeystores(t, keysDir, 3, password)
+	encoded, err := ioutil.ReadFile(filepath.Join(keysDir, "keystore-0.json"))
+	require.NoError(t, err)
+	keystore := &v2keymanager.Keystore{}
+	require.NoError(t, json.Unmarshal(encoded, keystore))
+
+	// An interrupted import wrote the first keystore, the line after it is not a public key.
+	checkpoint := checkpointPath(t, walletDir, keysDir)
+	require.NoError(t, os.MkdirAll(filepath.Dir(checkpoint), os.ModePerm))
+	require.NoError(t, ioutil.WriteFile(checkpoint, []byte("0x"+keystore.Pubkey+"\n0xa1b2"), os.ModePerm))
+
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		keysDir:             keysDir,
+		keymanagerKind:      v2keymanager.Direct,
+		walletPasswordFile:  passwordFilePath,
+		accountPasswordFile: passwordFilePath,
+	})
+	require.NoError(t, ImportAccount(cliCtx))
+
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	accounts, err := wallet.accountPubKeys(context.Background())
+	require.NoError(t, err)
+	// The checkpointed keystore was skipped rather than decrypted again.
+	assert.Equal(t, 2, len(accounts))
+	assert.Equal(t, false, fileExists(checkpoint), "Expected the checkpoint of a completed import to be removed")
+}
+
+func TestImport_InterruptedImportKeepsCheckpoint(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	keysDir := filepath.Join(testutil.TempDir(), t.Name())
+	require.NoError(t, os.MkdirAll(keysDir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(keysDir), "Failed to remove directory")
+	})
+	writeKeystores(t, keysDir, 2, "some-other-password")
+
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		keysDir:             keysDir,
+		keymanagerKind:      v2keymanager.Direct,
+		walletPasswordFile:  passwordFilePath,
+		accountPasswordFile: passwordFilePath,
+	})
+	assert.ErrorContains(t, "invalid password", ImportAccount(cliCtx))
+	assert.Equal(t, true, fileExists(checkpointPath(t, walletDir, keysDir)))
+}
@@ -0,0 +1,188 @@
+package v2
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	s3Scheme  = "s3"
+	gcsScheme = "gs"
+	// encryptedBundleSuffix is trimmed from the file name of keystore bundles encrypted client-side.
+	encryptedBundleSuffix = ".enc"
+	// keystoresEncryptionKeySize is the size of the AES-256 keys keystore bundles are encrypted with.
+	keystoresEncryptionKeySize = 32
+)
+
+// cloudObjectOpener opens an object of a cloud storage bucket, passing the customer supplied key
+// it is encrypted with server-side if not nil. Credentials are resolved by the default credential
+// chain of the cloud SDK, from the environment, shared configuration files or instance metadata.
+type cloudObjectOpener func(ctx context.Context, bucket, object string, serverSideKey []byte) (io.ReadCloser, error)
+
+// cloudObjectOpeners by url scheme, they are replaced in tests to avoid reaching cloud storage.
+var cloudObjectOpeners = map[string]cloudObjectOpener{
+	s3Scheme:  openS3Object,
+	gcsScheme: openGCSObject,
+}
+
+// keystoresEncryption holds the keys a downloaded keystore bundle is encrypted with.
+type keystoresEncryption struct {
+	// serverSideKey is the customer supplied key of an SSE-C encrypted s3 object or a CSEK
+	// encrypted gcs object. Objects encrypted with keys managed by the cloud provider need none.
+	serverSideKey []byte
+	// clientSideKey is the AES-256-GCM key the bundle was encrypted with before being uploaded,
+	// stored as a 12 byte nonce followed by the ciphertext.
+	clientSideKey []byte
+}
+
+func keystoresEncryptionFromCli(cliCtx *cli.Context, isCloudURL bool) (*keystoresEncryption, error) {
+	encryption := &keystoresEncryption{}
+	if keyFile := cliCtx.String(flags.KeystoresSSEKeyFileFlag.Name); keyFile != "" {
+		if !isCloudURL {
+			return nil, fmt.Errorf(
+				"--%s can only be used with an %s:// or %s:// url", flags.KeystoresSSEKeyFileFlag.Name, s3Scheme, gcsScheme,
+			)
+		}
+		key, err := readKeystoresEncryptionKey(keyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read server-side encryption key")
+		}
+		encryption.serverSideKey = key
+	}
+	if keyFile := cliCtx.String(flags.KeystoresDecryptionKeyFileFlag.Name); keyFile != "" {
+		key, err := readKeystoresEncryptionKey(keyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read client-side decryption key")
+		}
+		encryption.clientSideKey = key
+	}
+	return encryption, nil
+}
+
+// Reads a hex encoded 256-bit key from a file.
+func readKeystoresEncryptionKey(keyFile string) ([]byte, error) {
+	expanded, err := expandPath(keyFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not expand path %s", keyFile)
+	}
+	enc, err := ioutil.ReadFile(expanded)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read key file %s", expanded)
+	}
+	key, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(enc)), "0x"))
+	if err != nil || len(key) != keystoresEncryptionKeySize {
+		return nil, fmt.Errorf("%s must hold a hex encoded %d byte key", expanded, keystoresEncryptionKeySize)
+	}
+	return key, nil
+}
+
+func openCloudObject(ctx context.Context, bundleURL *url.URL, serverSideKey []byte) (io.ReadCloser, error) {
+	bucket := bundleURL.Host
+	object := strings.TrimPrefix(bundleURL.Path, "/")
+	if bucket == "" || object == "" {
+		return nil, fmt.Errorf("%s is not a url of an object of a bucket", bundleURL)
+	}
+	body, err := cloudObjectOpeners[bundleURL.Scheme](ctx, bucket, object, serverSideKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not download keystores from %s", bundleURL)
+	}
+	return body, nil
+}
+
+func openS3Object(ctx context.Context, bucket, object string, serverSideKey []byte) (io.ReadCloser, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{SharedConfigState: session.SharedConfigEnable})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create aws session")
+	}
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(object),
+	}
+	if serverSideKey != nil {
+		// The sdk computes the MD5 digest of the key the request must also carry.
+		input.SSECustomerAlgorithm = aws.String(s3.ServerSideEncryptionAes256)
+		input.SSECustomerKey = aws.String(string(serverSideKey))
+	}
+	out, err := s3.New(sess).GetObjectWithContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
+
+// gcsObjectReader closes the storage client along with the object it reads.
+type gcsObjectReader struct {
+	*storage.Reader
+	client *storage.Client
+}
+
+func (r *gcsObjectReader) Close() error {
+	if err := r.Reader.Close(); err != nil {
+		return err
+	}
+	return r.client.Close()
+}
+
+func openGCSObject(ctx context.Context, bucket, object string, serverSideKey []byte) (io.ReadCloser, error) {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create gcs client")
+	}
+	handle := client.Bucket(bucket).Object(object)
+	if serverSideKey != nil {
+		handle = handle.Key(serverSideKey)
+	}
+	reader, err := handle.NewReader(ctx)
+	if err != nil {
+		if closeErr := client.Close(); closeErr != nil {
+			log.WithError(closeErr).Error("Could not close gcs client")
+		}
+		return nil, err
+	}
+	return &gcsObjectReader{Reader: reader, client: client}, nil
+}
+
+// Reads a keystore bundle encrypted client-side with AES-256-GCM and decrypts it.
+func decryptKeystoresDownload(body io.Reader, key []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create cipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create cipher")
+	}
+	// Read one byte past the limit to tell a bundle of exactly the maximum size from a larger one.
+	maxSize := int64(maxKeystoreDownloadSize + aead.NonceSize() + aead.Overhead())
+	enc, err := ioutil.ReadAll(io.LimitReader(body, maxSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not download keystores")
+	}
+	if int64(len(enc)) > maxSize {
+		return nil, fmt.Errorf("keystores download is larger than %d bytes", maxKeystoreDownloadSize)
+	}
+	if len(enc) < aead.NonceSize() {
+		return nil, errors.New("encrypted keystores are too short to hold a nonce")
+	}
+	nonce, ciphertext := enc[:aead.NonceSize()], enc[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decrypt keystores, is the decryption key correct")
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,144 @@
+package v2
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/rand"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+)
+
+// Writes a hex encoded random 256-bit key to a file, returning the key and the file path.
+func writeTestEncryptionKey(t *testing.T, name string) ([]byte, string) {
+	keyDir := filepath.Join(testutil.TempDir(), t.Name())
+	require.NoError(t, os.MkdirAll(keyDir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(keyDir), "Failed to remove directory")
+	})
+	key := make([]byte, keystoresEncryptionKeySize)
+	_, err := rand.NewGenerator().Read(key)
+	require.NoError(t, err)
+	keyFile := filepath.Join(keyDir, name)
+	require.NoError(t, ioutil.WriteFile(keyFile, []byte(fmt.Sprintf("%x\n", key)), 0600))
+	return key, keyFile
+}
+
+func encryptTestBundle(t *testing.T, bundle, key []byte) []byte {
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.NewGenerator().Read(nonce)
+	require.NoError(t, err)
+	return aead.Seal(nonce, nonce, bundle, nil)
+}
+
+func TestImport_CloudKeystores(t *testing.T) {
+	keystore := encodedTestKeystore(t, nil)
+	digest := fmt.Sprintf("%x", sha256.Sum256(keystore))
+	sseKey, sseKeyFile := writeTestEncryptionKey(t, "sse.key")
+	clientKey, clientKeyFile := writeTestEncryptionKey(t, "client.key")
+	objects := map[string][]byte{
+		"s3://fleet/keystore-0.json":      keystore,
+		"gs://fleet/keys/keystore-0.json": keystore,
+		"s3://fleet/keystore-0.json.enc":  encryptTestBundle(t, keystore, clientKey),
+	}
+	defer func(openers map[string]cloudObjectOpener) {
+		cloudObjectOpeners = openers
+	}(cloudObjectOpeners)
+	cloudObjectOpeners = make(map[string]cloudObjectOpener)
+	for _, scheme := range []string{s3Scheme, gcsScheme} {
+		scheme := scheme
+		cloudObjectOpeners[scheme] = func(_ context.Context, bucket, object string, serverSideKey []byte) (io.ReadCloser, error) {
+			if serverSideKey != nil && !bytes.Equal(serverSideKey, sseKey) {
+				return nil, errors.New("wrong customer supplied key")
+			}
+			enc, ok := objects[fmt.Sprintf("%s://%s/%s", scheme, bucket, object)]
+			if !ok {
+				return nil, errors.New("no such key")
+			}
+			return ioutil.NopCloser(bytes.NewReader(enc)), nil
+		}
+	}
+
+	tests := []struct {
+		name              string
+		url               string
+		sseKeyFile        string
+		decryptionKeyFile string
+		wantErr           string
+	}{
+		{
+			name:       "s3 with customer supplied key",
+			url:        "s3://fleet/keystore-0.json",
+			sseKeyFile: sseKeyFile,
+		},
+		{
+			name: "gcs",
+			url:  "gs://fleet/keys/keystore-0.json",
+		},
+		{
+			name:              "client-side encrypted",
+			url:               "s3://fleet/keystore-0.json.enc",
+			decryptionKeyFile: clientKeyFile,
+		},
+		{
+			name:              "wrong decryption key",
+			url:               "s3://fleet/keystore-0.json.enc",
+			decryptionKeyFile: sseKeyFile,
+			wantErr:           "could not decrypt keystores",
+		},
+		{
+			name:    "missing object",
+			url:     "gs://fleet/keystore-1.json",
+			wantErr: "no such key",
+		},
+		{
+			name:       "customer supplied key over https",
+			url:        "https://localhost/keystore-0.json",
+			sseKeyFile: sseKeyFile,
+			wantErr:    "can only be used with an s3:// or gs:// url",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+			cliCtx := setupWalletCtx(t, &testWalletConfig{
+				walletDir:           walletDir,
+				passwordsDir:        passwordsDir,
+				keymanagerKind:      v2keymanager.Direct,
+				walletPasswordFile:  passwordFilePath,
+				accountPasswordFile: passwordFilePath,
+				keystoresURL:        tt.url,
+				keystoresSHA256:     digest,
+				sseKeyFile:          tt.sseKeyFile,
+				decryptionKeyFile:   tt.decryptionKeyFile,
+			})
+			err := ImportAccount(cliCtx)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, tt.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+			wallet, err := OpenWallet(cliCtx)
+			require.NoError(t, err)
+			accounts, err := wallet.accountPubKeys(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, 1, len(accounts))
+		})
+	}
+}
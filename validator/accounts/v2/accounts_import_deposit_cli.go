@@ -0,0 +1,111 @@
+package v2
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-ssz"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/depositutil"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+// The eth2.0-deposit-cli writes a deposit_data-<timestamp>.json file with the deposit
+// data of all its keystore-m_12381_3600_*.json files into the same folder.
+const depositCLIDepositDataFileName = "deposit_data-*.json"
+
+// Reads the deposit data files of a deposit-cli output folder, keyed by validating public key.
+// It returns an empty map if the folder contains no deposit data.
+func readDepositCLIDepositData(keysDir string) (map[[48]byte]*depositutil.DepositDataJSON, error) {
+	matches, err := filepath.Glob(filepath.Join(keysDir, depositCLIDepositDataFileName))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not search for deposit data files")
+	}
+	deposits := make(map[[48]byte]*depositutil.DepositDataJSON)
+	for _, match := range matches {
+		encoded, err := ioutil.ReadFile(match)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read deposit data file %s", match)
+		}
+		var entries []*depositutil.DepositDataJSON
+		if err := json.Unmarshal(encoded, &entries); err != nil {
+			return nil, errors.Wrapf(err, "could not decode deposit data file %s", match)
+		}
+		for _, entry := range entries {
+			pubKey, err := hex.DecodeString(strings.TrimPrefix(entry.PubKey, "0x"))
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not decode public key in deposit data file %s", match)
+			}
+			deposits[bytesutil.ToBytes48(pubKey)] = entry
+		}
+	}
+	return deposits, nil
+}
+
+// Stores the deposit data matching each imported account in its account directory, both
+// ssz-encoded and as canonical JSON, returning the number of accounts it was linked to.
+func (w *Wallet) linkDepositData(
+	ctx context.Context,
+	accountNames []string,
+	pubKeys [][]byte,
+	deposits map[[48]byte]*depositutil.DepositDataJSON,
+) (int, error) {
+	var linked int
+	for i, accountName := range accountNames {
+		ok, err := w.linkDepositDataForAccount(ctx, accountName, pubKeys[i], deposits)
+		if err != nil {
+			return linked, err
+		}
+		if ok {
+			linked++
+		}
+	}
+	return linked, nil
+}
+
+// Stores the deposit data matching a single imported account, reporting whether any was found.
+func (w *Wallet) linkDepositDataForAccount(
+	ctx context.Context,
+	accountName string,
+	pubKey []byte,
+	deposits map[[48]byte]*depositutil.DepositDataJSON,
+) (bool, error) {
+	entry, ok := deposits[bytesutil.ToBytes48(pubKey)]
+	if !ok {
+		log.WithField("name", accountName).Warn("No deposit data found for imported account")
+		return false, nil
+	}
+	depositData, err := depositutil.DepositDataFromJSON(entry)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid deposit data for account %s", accountName)
+	}
+	encodedSSZ, err := ssz.Marshal(depositData)
+	if err != nil {
+		return false, errors.Wrap(err, "could not marshal deposit data")
+	}
+	encodedJSON, err := json.MarshalIndent(entry, "", "\t")
+	if err != nil {
+		return false, errors.Wrap(err, "could not marshal deposit data json")
+	}
+	if err := w.WriteFilesAtPath(ctx, accountName, map[string][]byte{
+		direct.DepositDataFileName:     encodedSSZ,
+		direct.DepositDataJSONFileName: encodedJSON,
+	}); err != nil {
+		return false, errors.Wrapf(err, "could not write deposit data for account %s", accountName)
+	}
+	metadata, err := w.readAccountMetadata(accountName)
+	if err != nil {
+		return false, err
+	}
+	metadata.DepositData = entry
+	metadata.DepositDataRoot = ""
+	if err := w.consolidateAccountMetadata(ctx, accountName, metadata); err != nil {
+		return false, err
+	}
+	return true, nil
+}
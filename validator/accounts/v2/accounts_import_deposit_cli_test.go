@@ -0,0 +1,82 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+	"github.com/prysmaticlabs/go-ssz"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/depositutil"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/shared/petnames"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestImport_DepositCLIOutput(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	keysDir := filepath.Join(testutil.TempDir(), t.Name(), "validator_keys")
+	require.NoError(t, os.MkdirAll(keysDir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(filepath.Dir(keysDir)), "Failed to remove directory")
+	})
+
+	// Lay out the keystores and deposit data the way the eth2.0-deposit-cli writes them.
+	secretKeys := []bls.SecretKey{bls.RandKey(), bls.RandKey()}
+	deposits := make([]*depositutil.DepositDataJSON, len(secretKeys))
+	depositData := make([]*ethpb.Deposit_Data, len(secretKeys))
+	for i, secretKey := range secretKeys {
+		keystore, err := v2keymanager.NewKeystore(secretKey, fmt.Sprintf("m/12381/3600/%d/0/0", i), password)
+		require.NoError(t, err)
+		encoded, err := json.MarshalIndent(keystore, "", "\t")
+		require.NoError(t, err)
+		fileName := fmt.Sprintf("keystore-m_12381_3600_%d_0_0-1600000000.json", i)
+		require.NoError(t, ioutil.WriteFile(filepath.Join(keysDir, fileName), encoded, os.ModePerm))
+
+		depositData[i], _, err = depositutil.DepositInput(secretKey, bls.RandKey(), params.BeaconConfig().MaxEffectiveBalance)
+		require.NoError(t, err)
+		deposits[i], err = depositutil.DepositDataJSONFromProto(depositData[i])
+		require.NoError(t, err)
+	}
+	encodedDeposits, err := json.Marshal(deposits)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(keysDir, "deposit_data-1600000000.json"), encodedDeposits, os.ModePerm))
+
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		keysDir:             keysDir,
+		keymanagerKind:      v2keymanager.Direct,
+		walletPasswordFile:  passwordFilePath,
+		accountPasswordFile: passwordFilePath,
+	})
+	require.NoError(t, ImportAccount(cliCtx))
+
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	for i, secretKey := range secretKeys {
+		accountName := petnames.DeterministicName(secretKey.PublicKey().Marshal(), "-")
+		encoded, err := wallet.ReadFileAtPath(ctx, accountName, direct.DepositDataFileName)
+		require.NoError(t, err)
+		stored := &ethpb.Deposit_Data{}
+		require.NoError(t, ssz.Unmarshal(encoded, stored))
+		assert.DeepEqual(t, depositData[i].PublicKey, stored.PublicKey)
+		assert.DeepEqual(t, depositData[i].Signature, stored.Signature)
+
+		encoded, err = wallet.ReadFileAtPath(ctx, accountName, direct.DepositDataJSONFileName)
+		require.NoError(t, err)
+		storedJSON := &depositutil.DepositDataJSON{}
+		require.NoError(t, json.Unmarshal(encoded, storedJSON))
+		assert.DeepEqual(t, deposits[i], storedJSON)
+	}
+}
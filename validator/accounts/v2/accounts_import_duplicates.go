@@ -0,0 +1,154 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/urfave/cli/v2"
+)
+
+// walletKeys tracks the validating public keys of a non-HD wallet during an import. Keys the
+// wallet already holds are skipped and reported once the import is done, unless --reimport is
+// set, in which case their accounts are moved to the trash of the wallet so the imported keystores
+// replace them. It is safe for concurrent use by the import workers.
+type walletKeys struct {
+	mu       sync.Mutex
+	accounts map[[48]byte]string
+	imported map[[48]byte]bool
+	skipped  [][48]byte
+	// replaced maps the accounts moved to the trash to their passwords in the password store of
+	// the wallet, which stay in it.
+	replaced map[string]string
+	reimport bool
+}
+
+// Reads the public keys of the accounts of a non-HD wallet, without decrypting their keystores.
+func walletKeysFromCli(ctx context.Context, cliCtx *cli.Context, w *Wallet) (*walletKeys, error) {
+	keys := &walletKeys{
+		accounts: make(map[[48]byte]string),
+		imported: make(map[[48]byte]bool),
+		replaced: make(map[string]string),
+		reimport: cliCtx.Bool(flags.ReimportFlag.Name),
+	}
+	if keys.reimport && w.storage != nil {
+		return nil, fmt.Errorf("--%s is not supported for wallets stored in %s", flags.ReimportFlag.Name, w.storageURL)
+	}
+	ok, err := hasDir(w.AccountsDir())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read accounts directory")
+	}
+	if !ok {
+		return keys, nil
+	}
+	accounts, err := w.accountPubKeys(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read existing validating public keys")
+	}
+	for name, pubKey := range accounts {
+		keys.accounts[pubKey] = name
+	}
+	return keys, nil
+}
+
+// Reports whether a public key should be imported. A key is imported at most once per import,
+// and a key already held by the wallet only with --reimport, in which case the account holding
+// it is moved to the trash so the imported keystore replaces it, and can be restored from there.
+func (k *walletKeys) claim(w *Wallet, pubKey []byte) (bool, error) {
+	key := bytesutil.ToBytes48(pubKey)
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.imported[key] {
+		return false, nil
+	}
+	accountName, exists := k.accounts[key]
+	if exists && !k.reimport {
+		log.WithField("publicKey", fmt.Sprintf("%#x", bytesutil.Trunc(pubKey))).Info(
+			"Account already exists in wallet, skipping",
+		)
+		k.skipped = append(k.skipped, key)
+		return false, nil
+	}
+	if exists {
+		if err := w.DeleteAccountFiles(context.Background(), accountName, accountName+direct.PasswordFileSuffix); err != nil {
+			return false, errors.Wrapf(err, "could not overwrite account %s", accountName)
+		}
+		log.WithField("publicKey", fmt.Sprintf("%#x", bytesutil.Trunc(pubKey))).Warn(
+			"Overwriting account already in wallet, the previous one is kept in the trash",
+		)
+		delete(k.accounts, key)
+		k.replaced[accountName] = w.passwords[accountName+direct.PasswordFileSuffix]
+	}
+	k.imported[key] = true
+	return true, nil
+}
+
+// Prints the public keys which were skipped as the wallet already held them.
+func (k *walletKeys) reportSkipped() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if len(k.skipped) == 0 {
+		return
+	}
+	fmt.Printf(
+		"Skipped %s accounts already in the wallet, pass --%s to overwrite them:\n",
+		au.BrightYellow(len(k.skipped)),
+		flags.ReimportFlag.Name,
+	)
+	for _, pubKey := range k.skipped {
+		fmt.Printf("%#x\n", pubKey)
+	}
+}
+
+// Moves the accounts overwritten by an import which failed back from the trash, removing the
+// accounts imported in their place.
+func (k *walletKeys) restoreReplaced(w *Wallet) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if len(k.replaced) == 0 {
+		return
+	}
+	trashed, err := w.trashedAccounts()
+	if err != nil {
+		log.WithError(err).Error("Could not restore overwritten accounts, restore them with accounts-v2 restore")
+		return
+	}
+	latest := make(map[string]*trashedAccount, len(trashed))
+	for _, account := range trashed {
+		if current, ok := latest[account.name]; !ok || account.deletedAt.After(current.deletedAt) {
+			latest[account.name] = account
+		}
+	}
+	for accountName, storedPassword := range k.replaced {
+		account, ok := latest[accountName]
+		if !ok {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(w.AccountsDir(), accountName)); err != nil {
+			log.WithError(err).Errorf("Could not remove imported account %s", accountName)
+			continue
+		}
+		passwordPath := filepath.Join(w.passwordsDir, accountName+direct.PasswordFileSuffix)
+		if err := os.Remove(passwordPath); err != nil && !os.IsNotExist(err) {
+			log.WithError(err).Errorf("Could not remove password of imported account %s", accountName)
+			continue
+		}
+		if storedPassword != "" {
+			if err := w.WritePasswordToDisk(context.Background(), accountName+direct.PasswordFileSuffix, storedPassword); err != nil {
+				log.WithError(err).Errorf("Could not restore password of account %s", accountName)
+			}
+		}
+		if err := w.restoreTrashedAccount(account); err != nil {
+			log.WithError(err).Errorf("Could not restore account %s, restore it with accounts-v2 restore", accountName)
+			continue
+		}
+		log.WithField("account", accountName).Info("Restored overwritten account from the trash")
+	}
+	k.replaced = make(map[string]string)
+}
@@ -0,0 +1,80 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/petnames"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	logTest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestImport_DuplicateKeys(t *testing.T) {
+	hook := logTest.NewGlobal()
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	keysDir := filepath.Join(testutil.TempDir(), t.Name())
+	require.NoError(t, os.MkdirAll(keysDir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(keysDir), "Failed to remove directory")
+	})
+	secretKey := bls.RandKey()
+	writeKeystore := func(keystorePassword string) []byte {
+		keystore, err := v2keymanager.NewKeystore(secretKey, "" /* path */, keystorePassword)
+		require.NoError(t, err)
+		encoded, err := json.Marshal(keystore)
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(filepath.Join(keysDir, "keystore-0.json"), encoded, os.ModePerm))
+		return encoded
+	}
+	writeKeystore(password)
+
+	cfg := &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		keysDir:             keysDir,
+		keymanagerKind:      v2keymanager.Direct,
+		walletPasswordFile:  passwordFile,
+		accountPasswordFile: passwordFile,
+	}
+	require.NoError(t, ImportAccount(setupWalletCtx(t, cfg)))
+	require.NoError(t, ImportAccount(setupWalletCtx(t, cfg)))
+	assert.LogsContain(t, hook, "Account already exists in wallet, skipping")
+
+	ctx := context.Background()
+	wallet, err := OpenWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	accounts, err := wallet.accountPubKeys(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(accounts))
+
+	// A re-encrypted keystore of the same key only replaces the account with --reimport.
+	reencrypted := writeKeystore(password)
+	cfg.reimport = true
+	require.NoError(t, ImportAccount(setupWalletCtx(t, cfg)))
+	assert.LogsContain(t, hook, "Overwriting account already in wallet")
+	accounts, err = wallet.accountPubKeys(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(accounts))
+	accountName := petnames.DeterministicName(secretKey.PublicKey().Marshal(), "-")
+	stored, err := wallet.ReadFileAtPath(ctx, accountName, direct.KeystoreFileName)
+	require.NoError(t, err)
+	assert.DeepEqual(t, reencrypted, stored)
+
+	// The account is restored from the trash if the password of the keystore replacing it is wrong.
+	writeKeystore("an0ther-Passw0rd!")
+	assert.ErrorContains(t, "invalid password", ImportAccount(setupWalletCtx(t, cfg)))
+	assert.LogsContain(t, hook, "Restored overwritten account from the trash")
+	stored, err = wallet.ReadFileAtPath(ctx, accountName, direct.KeystoreFileName)
+	require.NoError(t, err)
+	assert.DeepEqual(t, reencrypted, stored)
+	require.NoError(t, wallet.checkPasswordForAccount(accountName, password))
+}
@@ -0,0 +1,72 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v1 "github.com/prysmaticlabs/prysm/validator/keymanager/v1"
+	"github.com/urfave/cli/v2"
+)
+
+// Imports the accounts of ethdo wallets, which are wealdtech filesystem wallets of either the
+// nd or hd type. The wallet store is read from --keys-dir, or from the default ethdo location
+// if it is not set, and each account keeps its ethdo passphrase as its account password.
+func importEthdoAccounts(ctx context.Context, cliCtx *cli.Context, wallet *Wallet, filter *pubKeyFilter) error {
+	accounts := cliCtx.StringSlice(flags.EthdoAccountsFlag.Name)
+	if len(accounts) == 0 {
+		return fmt.Errorf("at least one ethdo account must be specified with --%s", flags.EthdoAccountsFlag.Name)
+	}
+	location := ""
+	if cliCtx.IsSet(flags.KeysDirFlag.Name) {
+		var err error
+		location, err = expandPath(cliCtx.String(flags.KeysDirFlag.Name))
+		if err != nil {
+			return errors.Wrap(err, "could not parse ethdo wallet location")
+		}
+	}
+	passphrase, err := inputPassword(cliCtx, flags.AccountPasswordFileFlag, ethdoPassphrasePromptText, noConfirmPass)
+	if err != nil {
+		return errors.Wrap(err, "could not input ethdo passphrase")
+	}
+	opts, err := json.Marshal(map[string]interface{}{
+		"location":    location,
+		"accounts":    accounts,
+		"passphrases": []string{passphrase},
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not encode ethdo wallet options")
+	}
+	ethdoKeymanager, _, err := v1.NewWallet(string(opts))
+	if err != nil {
+		return errors.Wrap(err, "could not open ethdo wallet")
+	}
+	exporter, ok := ethdoKeymanager.(v1.SecretKeyExporter)
+	if !ok {
+		return errors.New("ethdo wallet keys cannot be exported")
+	}
+	secretKeys, err := exporter.SecretKeys()
+	if err != nil {
+		return errors.Wrap(err, "could not read ethdo account keys")
+	}
+	if len(secretKeys) == 0 {
+		return errors.New("no ethdo accounts could be unlocked with the given passphrase")
+	}
+	existing, err := walletKeysFromCli(ctx, cliCtx, wallet)
+	if err != nil {
+		return err
+	}
+	defer existing.reportSkipped()
+	imported, err := wallet.importSecretKeys(ctx, secretKeys, passphrase, existing, filter)
+	if err != nil {
+		return err
+	}
+	pubKeys := make([][]byte, len(imported))
+	for i := range imported {
+		pubKeys[i] = imported[i][:]
+	}
+	printKeystoresImported(pubKeys)
+	return nil
+}
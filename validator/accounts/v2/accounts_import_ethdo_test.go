@@ -0,0 +1,69 @@
+package v2
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+	nd "github.com/wealdtech/go-eth2-wallet-nd/v2"
+	filesystem "github.com/wealdtech/go-eth2-wallet-store-filesystem"
+	e2wtypes "github.com/wealdtech/go-eth2-wallet-types/v2"
+)
+
+func TestImport_EthdoFormat(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	ethdoDir := filepath.Join(testutil.TempDir(), t.Name())
+	require.NoError(t, os.MkdirAll(ethdoDir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(ethdoDir), "Failed to remove directory")
+	})
+
+	// Create an ethdo wallet with two accounts sharing the passphrase and one which does not.
+	ctx := context.Background()
+	store := filesystem.New(filesystem.WithLocation(ethdoDir))
+	ethdoWallet, err := nd.CreateWallet(ctx, "Validators", store, keystorev4.New())
+	require.NoError(t, err)
+	locker, ok := ethdoWallet.(e2wtypes.WalletLocker)
+	require.Equal(t, true, ok)
+	require.NoError(t, locker.Unlock(ctx, nil))
+	creator, ok := ethdoWallet.(e2wtypes.WalletAccountCreator)
+	require.Equal(t, true, ok)
+	wanted := make(map[[48]byte]bool)
+	for _, name := range []string{"Validator 1", "Validator 2"} {
+		account, err := creator.CreateAccount(ctx, name, []byte(password))
+		require.NoError(t, err)
+		wanted[bytesutil.ToBytes48(account.PublicKey().Marshal())] = true
+	}
+	_, err = creator.CreateAccount(ctx, "Other", []byte("another passphrase"))
+	require.NoError(t, err)
+
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		keysDir:             ethdoDir,
+		importFormat:        ethdoImportFormat,
+		ethdoAccounts:       "Validators/Validator.*",
+		keymanagerKind:      v2keymanager.Direct,
+		walletPasswordFile:  passwordFilePath,
+		accountPasswordFile: passwordFilePath,
+	})
+	require.NoError(t, ImportAccount(cliCtx))
+
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	km, err := wallet.InitializeKeymanager(ctx, true)
+	require.NoError(t, err)
+	keys, err := km.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	require.Equal(t, len(wanted), len(keys))
+	for _, key := range keys {
+		assert.Equal(t, true, wanted[key], "Unexpected key %#x imported", key)
+	}
+}
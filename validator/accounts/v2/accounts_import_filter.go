@@ -0,0 +1,171 @@
+package v2
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/urfave/cli/v2"
+)
+
+// pubKeyFilter selects which validating public keys an import stores in the wallet, as given
+// by --include-pubkeys and --exclude-pubkeys. A nil filter selects every key. It is safe for
+// concurrent use by the import workers.
+type pubKeyFilter struct {
+	mu      sync.Mutex
+	include map[[48]byte]bool
+	exclude map[[48]byte]bool
+	seen    map[[48]byte]bool
+}
+
+// Builds the public key filter from the cli flags, or returns nil if neither flag is set.
+func pubKeyFilterFromCli(cliCtx *cli.Context) (*pubKeyFilter, error) {
+	includes := cliCtx.StringSlice(flags.IncludePubKeysFlag.Name)
+	excludes := cliCtx.StringSlice(flags.ExcludePubKeysFlag.Name)
+	if len(includes) == 0 && len(excludes) == 0 {
+		return nil, nil
+	}
+	filter := &pubKeyFilter{seen: make(map[[48]byte]bool)}
+	var err error
+	if len(includes) > 0 {
+		filter.include, err = parsePubKeyList(includes)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse --%s", flags.IncludePubKeysFlag.Name)
+		}
+	}
+	filter.exclude, err = parsePubKeyList(excludes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse --%s", flags.ExcludePubKeysFlag.Name)
+	}
+	return filter, nil
+}
+
+// Reports whether a public key should be imported, logging the keys it filters out.
+func (f *pubKeyFilter) allows(pubKey []byte) bool {
+	if f == nil {
+		return true
+	}
+	key := bytesutil.ToBytes48(pubKey)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.seen[key] = true
+	if f.exclude[key] || (f.include != nil && !f.include[key]) {
+		log.WithField("publicKey", fmt.Sprintf("%#x", bytesutil.Trunc(pubKey))).Info(
+			"Account filtered out by the public key filters, skipping",
+		)
+		return false
+	}
+	return true
+}
+
+// Returns the public keys the filter selects, without logging or recording the others as seen
+// in a keystore.
+func (f *pubKeyFilter) selected(pubKeys [][48]byte) [][48]byte {
+	if f == nil {
+		return pubKeys
+	}
+	selected := make([][48]byte, 0, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		if f.exclude[pubKey] || (f.include != nil && !f.include[pubKey]) {
+			continue
+		}
+		selected = append(selected, pubKey)
+	}
+	return selected
+}
+
+// Returns the included public keys which were not found in any of the imported keystores.
+func (f *pubKeyFilter) missingIncludes() [][48]byte {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	missing := make([][48]byte, 0)
+	for pubKey := range f.include {
+		if !f.seen[pubKey] {
+			missing = append(missing, pubKey)
+		}
+	}
+	return missing
+}
+
+// Parses entries that are either 0x-prefixed hex public keys or paths to files listing one
+// such public key per line, where blank lines and lines starting with # are ignored.
+func parsePubKeyList(entries []string) (map[[48]byte]bool, error) {
+	pubKeys := make(map[[48]byte]bool)
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if strings.HasPrefix(entry, "0x") {
+			pubKey, err := parsePubKey(entry)
+			if err != nil {
+				return nil, err
+			}
+			pubKeys[pubKey] = true
+			continue
+		}
+		listed, err := readPubKeyFile(entry)
+		if err != nil {
+			return nil, err
+		}
+		for _, pubKey := range listed {
+			pubKeys[pubKey] = true
+		}
+	}
+	return pubKeys, nil
+}
+
+// Reads the public keys listed in a file in the order of the file, once each.
+func readPubKeyFile(filePath string) ([][48]byte, error) {
+	fullPath, err := expandPath(filePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse public key file path %s", filePath)
+	}
+	f, err := os.Open(fullPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open public key file %s", filePath)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.WithError(err).Error("Could not close public key file")
+		}
+	}()
+	var pubKeys [][48]byte
+	seen := make(map[[48]byte]bool)
+	scanner := bufio.NewScanner(f)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pubKey, err := parsePubKey(line)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid entry on line %d of public key file %s", lineNumber, filePath)
+		}
+		if !seen[pubKey] {
+			seen[pubKey] = true
+			pubKeys = append(pubKeys, pubKey)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "could not read public key file %s", filePath)
+	}
+	return pubKeys, nil
+}
+
+func parsePubKey(s string) ([48]byte, error) {
+	enc, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return [48]byte{}, errors.Wrapf(err, "could not decode public key %s", s)
+	}
+	if len(enc) != 48 {
+		return [48]byte{}, fmt.Errorf("public key %s is %d bytes long, expected 48", s, len(enc))
+	}
+	return bytesutil.ToBytes48(enc), nil
+}
@@ -0,0 +1,101 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	logTest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestImport_PubKeyFilters(t *testing.T) {
+	hook := logTest.NewGlobal()
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	keysDir := filepath.Join(testutil.TempDir(), t.Name())
+	require.NoError(t, os.MkdirAll(keysDir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(keysDir), "Failed to remove directory")
+	})
+	secretKeys := []bls.SecretKey{bls.RandKey(), bls.RandKey(), bls.RandKey()}
+	lines := make([]string, len(secretKeys))
+	for i, secretKey := range secretKeys {
+		lines[i] = fmt.Sprintf("%#x", secretKey.Marshal())
+	}
+	privateKeyFile := filepath.Join(keysDir, "keys.txt")
+	require.NoError(t, ioutil.WriteFile(privateKeyFile, []byte(strings.Join(lines, "\n")), 0600))
+
+	// Include the first two keys from a file along with a key which is not being imported,
+	// then exclude the second key inline.
+	missing := bls.RandKey().PublicKey().Marshal()
+	includeFile := filepath.Join(keysDir, "include.txt")
+	includes := []string{
+		"# validators for this wallet",
+		fmt.Sprintf("%#x", secretKeys[0].PublicKey().Marshal()),
+		fmt.Sprintf("%#x", secretKeys[1].PublicKey().Marshal()),
+		"",
+	}
+	require.NoError(t, ioutil.WriteFile(includeFile, []byte(strings.Join(includes, "\n")), 0600))
+
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		walletPasswordFile:  passwordFile,
+		accountPasswordFile: passwordFile,
+		privateKeyFile:      privateKeyFile,
+		includePubKeys:      []string{includeFile, fmt.Sprintf("%#x", missing)},
+		excludePubKeys:      []string{fmt.Sprintf("%#x", secretKeys[1].PublicKey().Marshal())},
+		keymanagerKind:      v2keymanager.Direct,
+	})
+	require.NoError(t, ImportAccount(cliCtx))
+	testutil.AssertLogsContain(t, hook, "Account filtered out by the public key filters, skipping")
+	testutil.AssertLogsContain(t, hook, "Included public key was not found in any of the keystores to import")
+
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	keymanager, err := wallet.InitializeKeymanager(ctx, true)
+	require.NoError(t, err)
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(pubKeys))
+	assert.Equal(t, bytesutil.ToBytes48(secretKeys[0].PublicKey().Marshal()), pubKeys[0])
+}
+
+func TestParsePubKeyList_Invalid(t *testing.T) {
+	_, err := parsePubKeyList([]string{"0x1234"})
+	assert.ErrorContains(t, "expected 48", err)
+	_, err = parsePubKeyList([]string{"0xzz"})
+	assert.ErrorContains(t, "could not decode public key", err)
+	_, err = parsePubKeyList([]string{filepath.Join(testutil.TempDir(), t.Name(), "missing.txt")})
+	assert.ErrorContains(t, "could not open public key file", err)
+}
+
+func TestPubKeyFilter_Nil(t *testing.T) {
+	var filter *pubKeyFilter
+	assert.Equal(t, true, filter.allows(bls.RandKey().PublicKey().Marshal()))
+	assert.Equal(t, 0, len(filter.missingIncludes()))
+}
+
+func TestPubKeyFilter_Selected(t *testing.T) {
+	included := bytesutil.ToBytes48(bls.RandKey().PublicKey().Marshal())
+	excluded := bytesutil.ToBytes48(bls.RandKey().PublicKey().Marshal())
+	other := bytesutil.ToBytes48(bls.RandKey().PublicKey().Marshal())
+	filter := &pubKeyFilter{
+		include: map[[48]byte]bool{included: true, excluded: true},
+		exclude: map[[48]byte]bool{excluded: true},
+		seen:    make(map[[48]byte]bool),
+	}
+	assert.DeepEqual(t, [][48]byte{included}, filter.selected([][48]byte{included, excluded, other}))
+	// Selecting the keys of a slashing protection file does not count them as found in a keystore.
+	assert.Equal(t, 2, len(filter.missingIncludes()))
+}
@@ -0,0 +1,53 @@
+package v2
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// Lighthouse stores each validator's keystore as validators/0x<pubkey>/voting-keystore.json.
+	lighthouseKeystoreFileName = "voting-keystore.json"
+	// Lighthouse stores each keystore password as secrets/0x<pubkey>.
+	lighthouseSecretsDirName = "secrets"
+)
+
+// Imports the voting keystores of a Lighthouse validators directory given with --keys-dir,
+// unlocking each one with its password from the Lighthouse secrets directory. The keystores are
+// stored unchanged and keep their Lighthouse password as account password.
+func importLighthouseAccounts(ctx context.Context, cliCtx *cli.Context, wallet *Wallet, filter *pubKeyFilter) error {
+	validatorsDir, err := inputDirectory(cliCtx, lighthouseDirPromptText, flags.KeysDirFlag)
+	if err != nil {
+		return errors.Wrap(err, "could not parse lighthouse validators directory")
+	}
+	secretsDir := filepath.Join(filepath.Dir(validatorsDir), lighthouseSecretsDirName)
+	if cliCtx.IsSet(flags.LighthouseSecretsDirFlag.Name) {
+		secretsDir, err = expandPath(cliCtx.String(flags.LighthouseSecretsDirFlag.Name))
+		if err != nil {
+			return errors.Wrap(err, "could not parse lighthouse secrets directory")
+		}
+	}
+	existing, err := walletKeysFromCli(ctx, cliCtx, wallet)
+	if err != nil {
+		return err
+	}
+	defer existing.reportSkipped()
+	pubKeysImported, err := wallet.importPubKeyDirs(
+		ctx,
+		validatorsDir,
+		secretsDir,
+		lighthouseKeystoreFileName,
+		false, /* keep creation time */
+		existing,
+		filter,
+	)
+	if err != nil {
+		return errors.Wrap(err, "could not import lighthouse validators")
+	}
+	printKeystoresImported(pubKeysImported)
+	return nil
+}
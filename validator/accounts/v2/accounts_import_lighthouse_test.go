@@ -0,0 +1,110 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	logTest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestImportLighthouseAccounts(t *testing.T) {
+	hook := logTest.NewGlobal()
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	lighthouseDir := filepath.Join(testutil.TempDir(), t.Name())
+	validatorsDir := filepath.Join(lighthouseDir, "validators")
+	secretsDir := filepath.Join(lighthouseDir, lighthouseSecretsDirName)
+	require.NoError(t, os.MkdirAll(secretsDir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(lighthouseDir), "Failed to remove directory")
+	})
+
+	// Lay out two validators the way Lighthouse stores them on disk.
+	secretKeys := []bls.SecretKey{bls.RandKey(), bls.RandKey()}
+	for i, secretKey := range secretKeys {
+		password := fmt.Sprintf("lighthouse-secret-%d", i)
+		keystore, err := v2keymanager.NewKeystore(secretKey, "" /* path */, password)
+		require.NoError(t, err)
+		encoded, err := json.MarshalIndent(keystore, "", "\t")
+		require.NoError(t, err)
+		pubKeyHex := fmt.Sprintf("%#x", secretKey.PublicKey().Marshal())
+		require.NoError(t, os.MkdirAll(filepath.Join(validatorsDir, pubKeyHex), os.ModePerm))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(validatorsDir, pubKeyHex, lighthouseKeystoreFileName), encoded, os.ModePerm))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(secretsDir, pubKeyHex), []byte(password), os.ModePerm))
+	}
+
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFile,
+		keysDir:            validatorsDir,
+		importFormat:       lighthouseImportFormat,
+		keymanagerKind:     v2keymanager.Direct,
+	})
+	require.NoError(t, ImportAccount(cliCtx))
+
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	keymanager, err := wallet.InitializeKeymanager(ctx, true)
+	require.NoError(t, err)
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	require.Equal(t, len(secretKeys), len(pubKeys))
+	imported := make(map[[48]byte]bool, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		imported[pubKey] = true
+	}
+	for _, secretKey := range secretKeys {
+		assert.Equal(t, true, imported[bytesutil.ToBytes48(secretKey.PublicKey().Marshal())])
+	}
+
+	// Importing the same directory again must not duplicate accounts.
+	require.NoError(t, ImportAccount(cliCtx))
+	testutil.AssertLogsContain(t, hook, "Account already exists in wallet, skipping")
+	names, err := wallet.ListDirs()
+	require.NoError(t, err)
+	assert.Equal(t, len(secretKeys), len(names))
+}
+
+func TestImportLighthouseAccounts_WrongSecret(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	lighthouseDir := filepath.Join(testutil.TempDir(), t.Name())
+	validatorsDir := filepath.Join(lighthouseDir, "validators")
+	secretsDir := filepath.Join(lighthouseDir, lighthouseSecretsDirName)
+	require.NoError(t, os.MkdirAll(secretsDir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(lighthouseDir), "Failed to remove directory")
+	})
+
+	secretKey := bls.RandKey()
+	keystore, err := v2keymanager.NewKeystore(secretKey, "" /* path */, "right-password")
+	require.NoError(t, err)
+	encoded, err := json.Marshal(keystore)
+	require.NoError(t, err)
+	pubKeyHex := fmt.Sprintf("%#x", secretKey.PublicKey().Marshal())
+	require.NoError(t, os.MkdirAll(filepath.Join(validatorsDir, pubKeyHex), os.ModePerm))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(validatorsDir, pubKeyHex, lighthouseKeystoreFileName), encoded, os.ModePerm))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(secretsDir, pubKeyHex), []byte("wrong-password"), os.ModePerm))
+
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFile,
+		keysDir:            validatorsDir,
+		importFormat:       lighthouseImportFormat,
+		keymanagerKind:     v2keymanager.Direct,
+	})
+	err = ImportAccount(cliCtx)
+	assert.ErrorContains(t, "could not decrypt keystore", err)
+}
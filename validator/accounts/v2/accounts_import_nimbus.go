@@ -0,0 +1,46 @@
+package v2
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// Nimbus stores each validator's keystore as validators/0x<pubkey>/keystore.json
+	// and its password as secrets/0x<pubkey> within its data directory.
+	nimbusValidatorsDirName = "validators"
+	nimbusSecretsDirName    = "secrets"
+	nimbusKeystoreFileName  = "keystore.json"
+)
+
+// Imports the keystores of a Nimbus data directory given with --keys-dir, keeping the
+// public keys and the creation times of the original keystores.
+func importNimbusAccounts(ctx context.Context, cliCtx *cli.Context, wallet *Wallet, filter *pubKeyFilter) error {
+	dataDir, err := inputDirectory(cliCtx, nimbusDirPromptText, flags.KeysDirFlag)
+	if err != nil {
+		return errors.Wrap(err, "could not parse nimbus data directory")
+	}
+	existing, err := walletKeysFromCli(ctx, cliCtx, wallet)
+	if err != nil {
+		return err
+	}
+	defer existing.reportSkipped()
+	pubKeysImported, err := wallet.importPubKeyDirs(
+		ctx,
+		filepath.Join(dataDir, nimbusValidatorsDirName),
+		filepath.Join(dataDir, nimbusSecretsDirName),
+		nimbusKeystoreFileName,
+		true, /* keep creation time */
+		existing,
+		filter,
+	)
+	if err != nil {
+		return errors.Wrap(err, "could not import nimbus validators")
+	}
+	printKeystoresImported(pubKeysImported)
+	return nil
+}
@@ -0,0 +1,74 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/petnames"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestImport_NimbusFormat(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	nimbusDir := filepath.Join(testutil.TempDir(), t.Name())
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(nimbusDir), "Failed to remove directory")
+	})
+
+	secretKey := bls.RandKey()
+	password := "nimbus-secret"
+	keystore, err := v2keymanager.NewKeystore(secretKey, "" /* path */, password)
+	require.NoError(t, err)
+	encoded, err := json.Marshal(keystore)
+	require.NoError(t, err)
+	pubKey := secretKey.PublicKey().Marshal()
+	pubKeyHex := fmt.Sprintf("%#x", pubKey)
+	validatorDir := filepath.Join(nimbusDir, nimbusValidatorsDirName, pubKeyHex)
+	secretsDir := filepath.Join(nimbusDir, nimbusSecretsDirName)
+	require.NoError(t, os.MkdirAll(validatorDir, os.ModePerm))
+	require.NoError(t, os.MkdirAll(secretsDir, os.ModePerm))
+	keystorePath := filepath.Join(validatorDir, nimbusKeystoreFileName)
+	require.NoError(t, ioutil.WriteFile(keystorePath, encoded, os.ModePerm))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(secretsDir, pubKeyHex), []byte(password), os.ModePerm))
+	createdAt := time.Unix(1600000000, 0)
+	require.NoError(t, os.Chtimes(keystorePath, createdAt, createdAt))
+
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFile,
+		keysDir:            nimbusDir,
+		importFormat:       nimbusImportFormat,
+		keymanagerKind:     v2keymanager.Direct,
+	})
+	require.NoError(t, ImportAccount(cliCtx))
+
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	keymanager, err := wallet.InitializeKeymanager(ctx, true)
+	require.NoError(t, err)
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(pubKeys))
+	assert.DeepEqual(t, pubKey, pubKeys[0][:])
+
+	// The account keeps the creation time of the Nimbus keystore.
+	accountName := petnames.DeterministicName(pubKey, "-")
+	keystoreFileName, err := wallet.FileNameAtPath(ctx, accountName, direct.KeystoreFileName)
+	require.NoError(t, err)
+	timestamp, err := AccountTimestamp(keystoreFileName)
+	require.NoError(t, err)
+	assert.Equal(t, createdAt.Unix(), timestamp.Unix())
+}
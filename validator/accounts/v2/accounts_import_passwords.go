@@ -0,0 +1,146 @@
+package v2
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/urfave/cli/v2"
+)
+
+// keystorePasswords gives the password of each keystore to import when they are known upfront,
+// either one password for every keystore, or a password per validating public key.
+type keystorePasswords struct {
+	all   string
+	byKey map[[48]byte]string
+}
+
+// Reads the keystore passwords from --account-password-file or --keystore-passwords-file, or
+// returns nil if neither is set and passwords must be entered interactively.
+func keystorePasswordsFromCli(cliCtx *cli.Context) (*keystorePasswords, error) {
+	passwordFile := cliCtx.String(flags.AccountPasswordFileFlag.Name)
+	passwordsFile := cliCtx.String(flags.KeystorePasswordsFileFlag.Name)
+	switch {
+	case passwordFile != "" && passwordsFile != "":
+		return nil, fmt.Errorf(
+			"--%s and --%s cannot be used together",
+			flags.AccountPasswordFileFlag.Name,
+			flags.KeystorePasswordsFileFlag.Name,
+		)
+	case passwordFile != "":
+		password, err := ioutil.ReadFile(passwordFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read account password file")
+		}
+		return &keystorePasswords{all: string(password)}, nil
+	case passwordsFile != "":
+		filePath, err := expandPath(passwordsFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not parse keystore passwords file path")
+		}
+		byKey, err := readKeystorePasswordsFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		return &keystorePasswords{byKey: byKey}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// Returns the password of a keystore, looked up by the public key it declares when passwords
+// are given per public key.
+func (p *keystorePasswords) forKeystore(keystoreBytes []byte) (string, error) {
+	if p.byKey == nil {
+		return p.all, nil
+	}
+	keystore := &struct {
+		Pubkey string `json:"pubkey"`
+	}{}
+	if err := json.Unmarshal(keystoreBytes, keystore); err != nil {
+		return "", errors.Wrap(err, "could not decode keystore json")
+	}
+	if keystore.Pubkey == "" {
+		return "", errors.New("keystore does not declare its public key, so its password cannot be looked up")
+	}
+	pubKey, err := parsePubKey(keystore.Pubkey)
+	if err != nil {
+		return "", errors.Wrap(err, "could not decode public key string in keystore")
+	}
+	password, ok := p.byKey[pubKey]
+	if !ok {
+		return "", fmt.Errorf(
+			"no password for public key %#x in --%s",
+			bytesutil.Trunc(pubKey[:]),
+			flags.KeystorePasswordsFileFlag.Name,
+		)
+	}
+	return password, nil
+}
+
+// Reads a mapping of validating public key to keystore password, either from a .json file
+// holding a single object, or from a .csv file of pubkey,password records with an optional
+// header. Errors never include passwords.
+func readKeystorePasswordsFile(filePath string) (map[[48]byte]string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open keystore passwords file")
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.WithError(err).Error("Could not close keystore passwords file")
+		}
+	}()
+	passwords := make(map[[48]byte]string)
+	if strings.EqualFold(filepath.Ext(filePath), ".json") {
+		entries := make(map[string]string)
+		if err := json.NewDecoder(f).Decode(&entries); err != nil {
+			return nil, errors.Wrap(err, "could not decode keystore passwords json")
+		}
+		for pubKeyHex, password := range entries {
+			pubKey, err := parsePubKey(pubKeyHex)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid public key %q in keystore passwords file", pubKeyHex)
+			}
+			passwords[pubKey] = password
+		}
+		return passwords, nil
+	}
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = 2
+	reader.Comment = '#'
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read record %d of keystore passwords file", line)
+		}
+		pubKeyHex := strings.TrimSpace(record[0])
+		if line == 1 && strings.EqualFold(pubKeyHex, "pubkey") {
+			continue
+		}
+		pubKey, err := parsePubKey(pubKeyHex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key in record %d of keystore passwords file", line)
+		}
+		if _, ok := passwords[pubKey]; ok {
+			return nil, fmt.Errorf("public key %#x appears more than once in keystore passwords file", bytesutil.Trunc(pubKey[:]))
+		}
+		passwords[pubKey] = record[1]
+	}
+	if len(passwords) == 0 {
+		return nil, errors.New("keystore passwords file holds no passwords")
+	}
+	return passwords, nil
+}
@@ -0,0 +1,131 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/petnames"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestReadKeystorePasswordsFile(t *testing.T) {
+	dir := filepath.Join(testutil.TempDir(), t.Name())
+	require.NoError(t, os.MkdirAll(dir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dir), "Failed to remove directory")
+	})
+	first := bytesutil.ToBytes48(bls.RandKey().PublicKey().Marshal())
+	second := bytesutil.ToBytes48(bls.RandKey().PublicKey().Marshal())
+	want := map[[48]byte]string{first: "first, password", second: "second-password"}
+
+	csvPath := filepath.Join(dir, "passwords.csv")
+	csvContents := fmt.Sprintf("pubkey,password\n# comment\n%#x,\"first, password\"\n%x,second-password\n", first, second)
+	require.NoError(t, ioutil.WriteFile(csvPath, []byte(csvContents), os.ModePerm))
+	passwords, err := readKeystorePasswordsFile(csvPath)
+	require.NoError(t, err)
+	assert.DeepEqual(t, want, passwords)
+
+	jsonPath := filepath.Join(dir, "passwords.json")
+	encoded, err := json.Marshal(map[string]string{
+		fmt.Sprintf("%#x", first):  "first, password",
+		fmt.Sprintf("%#x", second): "second-password",
+	})
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(jsonPath, encoded, os.ModePerm))
+	passwords, err = readKeystorePasswordsFile(jsonPath)
+	require.NoError(t, err)
+	assert.DeepEqual(t, want, passwords)
+
+	tests := []struct {
+		name     string
+		contents string
+		wantErr  string
+	}{
+		{
+			name:     "duplicate public key",
+			contents: fmt.Sprintf("%#x,a\n%#x,b\n", first, first),
+			wantErr:  "appears more than once",
+		},
+		{
+			name:     "invalid public key",
+			contents: "0x1234,password\n",
+			wantErr:  "invalid public key in record 1",
+		},
+		{
+			name:     "missing password",
+			contents: fmt.Sprintf("%#x\n", first),
+			wantErr:  "could not read record 1",
+		},
+		{
+			name:     "empty",
+			contents: "pubkey,password\n",
+			wantErr:  "holds no passwords",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, ioutil.WriteFile(csvPath, []byte(tt.contents), os.ModePerm))
+			_, err := readKeystorePasswordsFile(csvPath)
+			assert.ErrorContains(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestImport_KeystorePasswordsFile(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	keysDir := filepath.Join(testutil.TempDir(), t.Name(), "keys")
+	require.NoError(t, os.MkdirAll(keysDir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(filepath.Dir(keysDir)), "Failed to remove directory")
+	})
+	secretKeys := []bls.SecretKey{bls.RandKey(), bls.RandKey()}
+	keystorePasswords := []string{"FirstPassword42!$", "SecondPassword42!$"}
+	csvContents := ""
+	for i, secretKey := range secretKeys {
+		keystore, err := v2keymanager.NewKeystore(secretKey, "" /* path */, keystorePasswords[i])
+		require.NoError(t, err)
+		encoded, err := json.Marshal(keystore)
+		require.NoError(t, err)
+		fileName := fmt.Sprintf("keystore-%d.json", i)
+		require.NoError(t, ioutil.WriteFile(filepath.Join(keysDir, fileName), encoded, os.ModePerm))
+		csvContents += fmt.Sprintf("%#x,%s\n", secretKey.PublicKey().Marshal(), keystorePasswords[i])
+	}
+	csvPath := filepath.Join(filepath.Dir(keysDir), "passwords.csv")
+
+	cfg := &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		keysDir:            keysDir,
+		keymanagerKind:     v2keymanager.Direct,
+		walletPasswordFile: passwordFile,
+		keystorePasswords:  csvPath,
+	}
+	// Every keystore needs a password in the file.
+	require.NoError(t, ioutil.WriteFile(csvPath, []byte(fmt.Sprintf("%#x,%s\n", secretKeys[0].PublicKey().Marshal(), keystorePasswords[0])), os.ModePerm))
+	assert.ErrorContains(t, "no password for public key", ImportAccount(setupWalletCtx(t, cfg)))
+
+	require.NoError(t, ioutil.WriteFile(csvPath, []byte(csvContents), os.ModePerm))
+	require.NoError(t, ImportAccount(setupWalletCtx(t, cfg)))
+	wallet, err := OpenWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	accounts, err := wallet.accountPubKeys(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, len(secretKeys), len(accounts))
+	for i, secretKey := range secretKeys {
+		accountName := petnames.DeterministicName(secretKey.PublicKey().Marshal(), "-")
+		stored, err := ioutil.ReadFile(filepath.Join(passwordsDir, accountName+direct.PasswordFileSuffix))
+		require.NoError(t, err)
+		assert.Equal(t, keystorePasswords[i], string(stored))
+	}
+}
@@ -0,0 +1,113 @@
+package v2
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/promptutil"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/urfave/cli/v2"
+)
+
+const privateKeyImportWarningText = `WARNING: you are importing raw, unencrypted private keys. Anyone who has
+seen this file can sign with these keys, and running them on two machines at once will get you slashed.
+The file is not modified or erased by this import, you are responsible for securely erasing it afterwards.`
+
+const privateKeyImportConfirmText = "Confirm you understand the risks of importing raw private keys (y/n)"
+
+// Imports the raw BLS secret keys of the file given by --private-key-file, one hex encoded
+// key per line, as new accounts of a non-HD wallet protected by a new account password.
+// The import has to be confirmed, and the source file is never modified.
+func importPrivateKeyFile(ctx context.Context, cliCtx *cli.Context, wallet *Wallet, filter *pubKeyFilter) error {
+	filePath, err := expandPath(cliCtx.String(flags.PrivateKeyFileFlag.Name))
+	if err != nil {
+		return errors.Wrap(err, "could not parse private key file path")
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return errors.Wrap(err, "could not read private key file")
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		log.WithField("path", filePath).Warnf(
+			"Private key file is accessible by other users of this machine (permissions %#o)", info.Mode().Perm(),
+		)
+	}
+	secretKeys, err := readPrivateKeyFile(filePath)
+	if err != nil {
+		return err
+	}
+	if len(secretKeys) == 0 {
+		return errors.New("no private keys found in private key file")
+	}
+
+	fmt.Println(au.BrightRed(privateKeyImportWarningText).Bold())
+	if !cliCtx.Bool(flags.SkipPrivateKeyImportConfirmFlag.Name) {
+		if _, err := promptutil.ValidatePrompt(privateKeyImportConfirmText, promptutil.ValidateConfirmation); err != nil {
+			return errors.Wrap(err, "private key import not confirmed")
+		}
+	}
+	password, err := inputPassword(cliCtx, flags.AccountPasswordFileFlag, newAccountPasswordPromptText, confirmPass)
+	if err != nil {
+		return errors.Wrap(err, "could not input new account password")
+	}
+	existing, err := walletKeysFromCli(ctx, cliCtx, wallet)
+	if err != nil {
+		return err
+	}
+	defer existing.reportSkipped()
+	imported, err := wallet.importSecretKeys(ctx, secretKeys, password, existing, filter)
+	if err != nil {
+		return err
+	}
+	pubKeys := make([][]byte, len(imported))
+	for i := range imported {
+		pubKeys[i] = imported[i][:]
+	}
+	printKeystoresImported(pubKeys)
+	log.WithField("path", filePath).Warn(
+		"The private key file was left untouched, securely erase it now that its keys are encrypted in the wallet",
+	)
+	return nil
+}
+
+// Reads hex encoded BLS secret keys, with or without 0x prefix, one per line. Blank lines and
+// lines starting with # are ignored. Errors only ever refer to line numbers, never to contents.
+func readPrivateKeyFile(filePath string) (map[[48]byte]bls.SecretKey, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open private key file")
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.WithError(err).Error("Could not close private key file")
+		}
+	}()
+	secretKeys := make(map[[48]byte]bls.SecretKey)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		enc, err := hex.DecodeString(strings.TrimPrefix(entry, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d of private key file is not a hex encoded key", line)
+		}
+		secretKey, err := bls.SecretKeyFromBytes(enc)
+		if err != nil {
+			return nil, fmt.Errorf("line %d of private key file is not a valid BLS secret key", line)
+		}
+		secretKeys[bytesutil.ToBytes48(secretKey.PublicKey().Marshal())] = secretKey
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "could not read private key file")
+	}
+	return secretKeys, nil
+}
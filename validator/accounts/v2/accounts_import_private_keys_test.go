@@ -0,0 +1,83 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+)
+
+func TestImportPrivateKeyFile(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	keysDir := filepath.Join(testutil.TempDir(), t.Name())
+	require.NoError(t, os.MkdirAll(keysDir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(keysDir), "Failed to remove directory")
+	})
+	secretKeys := []bls.SecretKey{bls.RandKey(), bls.RandKey()}
+	lines := []string{
+		"# validator keys",
+		fmt.Sprintf("%#x", secretKeys[0].Marshal()),
+		"",
+		fmt.Sprintf("%x", secretKeys[1].Marshal()),
+	}
+	privateKeyFile := filepath.Join(keysDir, "keys.txt")
+	contents := []byte(strings.Join(lines, "\n"))
+	require.NoError(t, ioutil.WriteFile(privateKeyFile, contents, 0600))
+
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		walletPasswordFile:  passwordFile,
+		accountPasswordFile: passwordFile,
+		privateKeyFile:      privateKeyFile,
+		keymanagerKind:      v2keymanager.Direct,
+	})
+	require.NoError(t, ImportAccount(cliCtx))
+
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	keymanager, err := wallet.InitializeKeymanager(ctx, true)
+	require.NoError(t, err)
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	require.Equal(t, len(secretKeys), len(pubKeys))
+	imported := make(map[[48]byte]bool, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		imported[pubKey] = true
+	}
+	for _, secretKey := range secretKeys {
+		assert.Equal(t, true, imported[bytesutil.ToBytes48(secretKey.PublicKey().Marshal())])
+	}
+
+	// The private key file must be left untouched.
+	remaining, err := ioutil.ReadFile(privateKeyFile)
+	require.NoError(t, err)
+	assert.DeepEqual(t, contents, remaining)
+}
+
+func TestReadPrivateKeyFile_InvalidKey(t *testing.T) {
+	keysDir := filepath.Join(testutil.TempDir(), t.Name())
+	require.NoError(t, os.MkdirAll(keysDir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(keysDir), "Failed to remove directory")
+	})
+	privateKeyFile := filepath.Join(keysDir, "keys.txt")
+	validKey := fmt.Sprintf("%#x", bls.RandKey().Marshal())
+	require.NoError(t, ioutil.WriteFile(privateKeyFile, []byte(validKey+"\nnot-a-key-secret"), 0600))
+
+	_, err := readPrivateKeyFile(privateKeyFile)
+	assert.ErrorContains(t, "line 2 of private key file is not a hex encoded key", err)
+	assert.Equal(t, false, strings.Contains(err.Error(), "not-a-key-secret"), "Error must not leak file contents")
+}
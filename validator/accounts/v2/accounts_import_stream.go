@@ -0,0 +1,227 @@
+package v2
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/depositutil"
+	"github.com/prysmaticlabs/prysm/shared/petnames"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+)
+
+const (
+	// Number of directory entries read at a time while listing the keystores to import.
+	streamImportListBatchSize = 256
+	// Number of imported keystores between two progress log lines.
+	streamImportLogInterval = 1000
+)
+
+// Decrypting a keystore is CPU bound, so we run one decryption worker per core.
+var streamImportWorkers = runtime.NumCPU()
+
+// Imports every keystore of a directory whose password is known upfront. Keystores
+// are listed in batches and streamed through a bounded pool of decryption workers, and each
+// worker writes its account to the wallet as soon as the keystore is verified, so memory use
+// does not grow with the number of keystores. Progress is checkpointed, so an interrupted
+// import resumes where it left off when run again. It returns the number of accounts imported
+// and the number of those which deposit data was linked to.
+func (w *Wallet) streamKeystoresDir(
+	ctx context.Context,
+	keysDir string,
+	passwords *keystorePasswords,
+	existing *walletKeys,
+	filter *pubKeyFilter,
+	deposits map[[48]byte]*depositutil.DepositDataJSON,
+) (int, int, error) {
+	checkpoint, err := w.openImportCheckpoint(keysDir)
+	if err != nil {
+		return 0, 0, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+		imported int64
+		linked   int64
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+	paths := make(chan string, streamImportWorkers)
+	for i := 0; i < streamImportWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for keystorePath := range paths {
+				if ctx.Err() != nil {
+					continue
+				}
+				pubKey, hasDepositData, err := w.importStreamedKeystore(
+					ctx, keystorePath, passwords, checkpoint, existing, filter, deposits,
+				)
+				if err != nil {
+					fail(errors.Wrapf(err, "could not import keystore %s", filepath.Base(keystorePath)))
+					continue
+				}
+				if pubKey == nil {
+					continue
+				}
+				if err := checkpoint.record(pubKey); err != nil {
+					fail(err)
+					continue
+				}
+				if hasDepositData {
+					atomic.AddInt64(&linked, 1)
+				}
+				if n := atomic.AddInt64(&imported, 1); n%streamImportLogInterval == 0 {
+					log.WithField("imported", n).Info("Importing keystores")
+				}
+			}
+		}()
+	}
+	err = listKeystores(ctx, keysDir, paths)
+	close(paths)
+	wg.Wait()
+	if err != nil {
+		fail(err)
+	}
+	if err := checkpoint.close(firstErr == nil); err != nil && firstErr == nil {
+		return 0, 0, err
+	}
+	if firstErr != nil {
+		return 0, 0, firstErr
+	}
+	return int(imported), int(linked), nil
+}
+
+// Sends the path of every keystore file in a directory, reading the directory in batches
+// rather than all at once.
+func listKeystores(ctx context.Context, keysDir string, paths chan<- string) error {
+	dir, err := os.Open(keysDir)
+	if err != nil {
+		return errors.Wrap(err, "could not open dir")
+	}
+	defer func() {
+		if err := dir.Close(); err != nil {
+			log.WithError(err).Error("Could not close keys directory")
+		}
+	}()
+	for {
+		names, err := dir.Readdirnames(streamImportListBatchSize)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "could not read dir")
+		}
+		for _, name := range names {
+			if !strings.HasPrefix(name, "keystore") {
+				continue
+			}
+			select {
+			case paths <- filepath.Join(keysDir, name):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// Checks a keystore unlocks with its password, then stores it in the wallet together with its
+// account password and deposit data. It returns the public key of the account, or nil if the
+// path is not a keystore file, the keystore is filtered out, the wallet already holds it or an
+// interrupted import already wrote it, and whether deposit data was linked to the account.
+func (w *Wallet) importStreamedKeystore(
+	ctx context.Context,
+	keystorePath string,
+	passwords *keystorePasswords,
+	checkpoint *importCheckpoint,
+	existing *walletKeys,
+	filter *pubKeyFilter,
+	deposits map[[48]byte]*depositutil.DepositDataJSON,
+) ([]byte, bool, error) {
+	info, err := os.Stat(keystorePath)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "could not stat keystore file")
+	}
+	if info.IsDir() {
+		return nil, false, nil
+	}
+	keystoreBytes, err := ioutil.ReadFile(keystorePath)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "could not read keystore file")
+	}
+	password, err := passwords.forKeystore(keystoreBytes)
+	if err != nil {
+		return nil, false, err
+	}
+	if isEthV3Keystore(keystoreBytes) {
+		keystoreBytes, err = convertEthV3Keystore(keystoreBytes, password)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	keystoreFile := &v2keymanager.Keystore{}
+	if err := json.Unmarshal(keystoreBytes, keystoreFile); err != nil {
+		return nil, false, errors.Wrap(err, "could not decode keystore json")
+	}
+	pubKeyBytes, err := hex.DecodeString(strings.TrimPrefix(keystoreFile.Pubkey, "0x"))
+	if err != nil {
+		return nil, false, errors.Wrap(err, "could not decode public key string in keystore")
+	}
+	if !filter.allows(pubKeyBytes) || checkpoint.isDone(pubKeyBytes) {
+		return nil, false, nil
+	}
+	decryptor := keystorev4.New()
+	rawSigningKey, err := decryptor.Decrypt(keystoreFile.Crypto, password)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid checksum") {
+			return nil, false, fmt.Errorf("invalid password for account with public key %#x", pubKeyBytes)
+		}
+		return nil, false, errors.Wrap(err, "could not decrypt keystore")
+	}
+	v2keymanager.ZeroSecret(rawSigningKey)
+	if ok, err := existing.claim(w, pubKeyBytes); err != nil || !ok {
+		return nil, false, err
+	}
+	accountName := petnames.DeterministicName(pubKeyBytes, "-")
+	keystoreBytes, password, err = w.keystoreForAccount(accountName, keystoreBytes, password)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "could not encrypt keystore with the master password")
+	}
+	if err := w.WriteFileAtPath(ctx, accountName, filepath.Base(keystorePath), keystoreBytes); err != nil {
+		return nil, false, errors.Wrap(err, "could not write keystore to account dir")
+	}
+	if err := w.WritePasswordToDisk(ctx, accountName+direct.PasswordFileSuffix, password); err != nil {
+		return nil, false, errors.Wrap(err, "could not write password to disk")
+	}
+	log.WithField("publicKey", fmt.Sprintf("%#x", bytesutil.Trunc(pubKeyBytes))).Debug("Imported keystore")
+	if len(deposits) == 0 {
+		return pubKeyBytes, false, nil
+	}
+	hasDepositData, err := w.linkDepositDataForAccount(ctx, accountName, pubKeyBytes, deposits)
+	if err != nil {
+		return nil, false, err
+	}
+	return pubKeyBytes, hasDepositData, nil
+}
@@ -0,0 +1,87 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+)
+
+func writeKeystores(t *testing.T, keysDir string, numKeys int, keystorePassword string) {
+	for i := 0; i < numKeys; i++ {
+		keystore, err := v2keymanager.NewKeystore(bls.RandKey(), "" /* path */, keystorePassword)
+		require.NoError(t, err)
+		encoded, err := json.Marshal(keystore)
+		require.NoError(t, err)
+		fileName := fmt.Sprintf("keystore-%d.json", i)
+		require.NoError(t, ioutil.WriteFile(filepath.Join(keysDir, fileName), encoded, os.ModePerm))
+	}
+}
+
+func TestImport_StreamsKeystores(t *testing.T) {
+	defer func(workers int) {
+		streamImportWorkers = workers
+	}(streamImportWorkers)
+	streamImportWorkers = 3
+
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	keysDir := filepath.Join(testutil.TempDir(), t.Name())
+	require.NoError(t, os.MkdirAll(keysDir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(keysDir), "Failed to remove directory")
+	})
+	numKeys := 2*streamImportWorkers + 1
+	writeKeystores(t, keysDir, numKeys, password)
+	// Directories and files which are not keystores must be skipped.
+	require.NoError(t, os.MkdirAll(filepath.Join(keysDir, "keystore-dir"), os.ModePerm))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(keysDir, "README.txt"), []byte("not a keystore"), os.ModePerm))
+
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		keysDir:             keysDir,
+		keymanagerKind:      v2keymanager.Direct,
+		walletPasswordFile:  passwordFilePath,
+		accountPasswordFile: passwordFilePath,
+	})
+	require.NoError(t, ImportAccount(cliCtx))
+
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	keymanager, err := wallet.InitializeKeymanager(ctx, true)
+	require.NoError(t, err)
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, numKeys, len(pubKeys))
+}
+
+func TestImport_StreamsKeystores_WrongPassword(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	keysDir := filepath.Join(testutil.TempDir(), t.Name())
+	require.NoError(t, os.MkdirAll(keysDir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(keysDir), "Failed to remove directory")
+	})
+	writeKeystores(t, keysDir, 4, "some-other-password")
+
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		keysDir:             keysDir,
+		keymanagerKind:      v2keymanager.Direct,
+		walletPasswordFile:  passwordFilePath,
+		accountPasswordFile: passwordFilePath,
+	})
+	err := ImportAccount(cliCtx)
+	assert.ErrorContains(t, "invalid password for account with public key", err)
+}
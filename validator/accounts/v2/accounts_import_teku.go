@@ -0,0 +1,124 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// Teku expects keystores as <name>.json with their password stored as <name>.txt.
+	tekuKeystoreFileExtension = ".json"
+	tekuPasswordFileExtension = ".txt"
+)
+
+// Imports keystores following Teku's --validator-keys=<keys>:<passwords> convention, given as
+// the --teku-keys and --teku-passwords in the same position rather than separated by a colon,
+// which paths may hold. Keys are either a directory of keystores with a parallel directory of
+// password files sharing their names, or a single keystore file together with its password file.
+func importTekuAccounts(ctx context.Context, cliCtx *cli.Context, wallet *Wallet, filter *pubKeyFilter) error {
+	keys := cliCtx.StringSlice(flags.TekuKeysFlag.Name)
+	passwords := cliCtx.StringSlice(flags.TekuPasswordsFlag.Name)
+	if len(keys) == 0 || len(keys) != len(passwords) {
+		return fmt.Errorf(
+			"teku keys must be specified as --%s=<keys> --%s=<passwords>, once for each set of keys",
+			flags.TekuKeysFlag.Name,
+			flags.TekuPasswordsFlag.Name,
+		)
+	}
+	existing, err := walletKeysFromCli(ctx, cliCtx, wallet)
+	if err != nil {
+		return err
+	}
+	defer existing.reportSkipped()
+	pubKeysImported := make([][]byte, 0)
+	for i := range keys {
+		keysPath, err := expandPath(keys[i])
+		if err != nil {
+			return errors.Wrap(err, "could not parse teku keys path")
+		}
+		passwordsPath, err := expandPath(passwords[i])
+		if err != nil {
+			return errors.Wrap(err, "could not parse teku passwords path")
+		}
+		imported, err := wallet.importTekuKeys(ctx, keysPath, passwordsPath, existing, filter)
+		if err != nil {
+			return err
+		}
+		pubKeysImported = append(pubKeysImported, imported...)
+	}
+	printKeystoresImported(pubKeysImported)
+	return nil
+}
+
+// Imports a Teku keystore file with its password file, or a directory of keystores with the
+// directory of their password files, returning the public keys imported.
+func (w *Wallet) importTekuKeys(
+	ctx context.Context,
+	keysPath string,
+	passwordsPath string,
+	existing *walletKeys,
+	filter *pubKeyFilter,
+) ([][]byte, error) {
+	isDir, err := hasDir(keysPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not determine if path is a directory")
+	}
+	pubKeysImported := make([][]byte, 0)
+	if !isDir {
+		pubKey, err := w.importTekuKeystore(ctx, keysPath, passwordsPath, existing, filter)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not import teku keystore")
+		}
+		if pubKey != nil {
+			pubKeysImported = append(pubKeysImported, pubKey)
+		}
+		return pubKeysImported, nil
+	}
+	files, err := ioutil.ReadDir(keysPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read teku keys directory")
+	}
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != tekuKeystoreFileExtension {
+			continue
+		}
+		passwordFileName := strings.TrimSuffix(file.Name(), tekuKeystoreFileExtension) + tekuPasswordFileExtension
+		pubKey, err := w.importTekuKeystore(
+			ctx,
+			filepath.Join(keysPath, file.Name()),
+			filepath.Join(passwordsPath, passwordFileName),
+			existing,
+			filter,
+		)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not import teku keystore %s", file.Name())
+		}
+		if pubKey != nil {
+			pubKeysImported = append(pubKeysImported, pubKey)
+		}
+	}
+	return pubKeysImported, nil
+}
+
+func (w *Wallet) importTekuKeystore(
+	ctx context.Context,
+	keystorePath string,
+	passwordPath string,
+	existing *walletKeys,
+	filter *pubKeyFilter,
+) ([]byte, error) {
+	data, err := ioutil.ReadFile(passwordPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read keystore password file")
+	}
+	password := strings.TrimRight(string(data), "\r\n")
+	return w.importKeystoreWithPassword(ctx, keystorePath, password, roughtime.Now(), existing, filter)
+}
@@ -0,0 +1,83 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+)
+
+func TestImport_TekuFormat(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	tekuDir := filepath.Join(testutil.TempDir(), t.Name())
+	keysDir := filepath.Join(tekuDir, "keys")
+	tekuPasswordsDir := filepath.Join(tekuDir, "passwords")
+	require.NoError(t, os.MkdirAll(keysDir, os.ModePerm))
+	require.NoError(t, os.MkdirAll(tekuPasswordsDir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(tekuDir), "Failed to remove directory")
+	})
+
+	secretKeys := []bls.SecretKey{bls.RandKey(), bls.RandKey()}
+	for i, secretKey := range secretKeys {
+		password := fmt.Sprintf("teku-password-%d", i)
+		keystore, err := v2keymanager.NewKeystore(secretKey, "" /* path */, password)
+		require.NoError(t, err)
+		encoded, err := json.Marshal(keystore)
+		require.NoError(t, err)
+		name := fmt.Sprintf("validator_%d", i)
+		require.NoError(t, ioutil.WriteFile(filepath.Join(keysDir, name+".json"), encoded, os.ModePerm))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(tekuPasswordsDir, name+".txt"), []byte(password+"\n"), os.ModePerm))
+	}
+
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFile,
+		tekuKeys:           []string{keysDir},
+		tekuPasswords:      []string{tekuPasswordsDir},
+		importFormat:       tekuImportFormat,
+		keymanagerKind:     v2keymanager.Direct,
+	})
+	require.NoError(t, ImportAccount(cliCtx))
+
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	keymanager, err := wallet.InitializeKeymanager(ctx, true)
+	require.NoError(t, err)
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	require.Equal(t, len(secretKeys), len(pubKeys))
+	imported := make(map[[48]byte]bool, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		imported[pubKey] = true
+	}
+	for _, secretKey := range secretKeys {
+		assert.Equal(t, true, imported[bytesutil.ToBytes48(secretKey.PublicKey().Marshal())])
+	}
+}
+
+func TestImport_TekuFormat_MissingPasswords(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFile,
+		tekuKeys:           []string{"/tmp/keys"},
+		importFormat:       tekuImportFormat,
+		keymanagerKind:     v2keymanager.Direct,
+	})
+	err := ImportAccount(cliCtx)
+	assert.ErrorContains(t, "teku keys must be specified", err)
+}
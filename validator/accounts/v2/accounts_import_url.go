@@ -0,0 +1,145 @@
+package v2
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/urfave/cli/v2"
+)
+
+// Keystore bundles are small, anything larger than this is not a bundle we should be importing.
+const maxKeystoreDownloadSize = 256 << 20
+
+// keystoreDownloadClient fetches keystore bundles, it is replaced in tests to trust a test server.
+var keystoreDownloadClient = http.DefaultClient
+
+// Downloads the keystore bundle at --url into a temporary directory and verifies it matches the
+// --sha256 digest, returning the path of the downloaded file and a function removing it. The
+// bundle is either a zip or tar.gz archive of keystores, or a single keystore json file, served
+// over https or stored in an s3:// or gs:// bucket. Bundles encrypted client-side are decrypted
+// before their digest is verified.
+func downloadKeystores(ctx context.Context, cliCtx *cli.Context) (string, func(), error) {
+	rawURL := cliCtx.String(flags.KeystoresURLFlag.Name)
+	bundleURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "could not parse keystores url")
+	}
+	isCloudURL := bundleURL.Scheme == s3Scheme || bundleURL.Scheme == gcsScheme
+	if bundleURL.Scheme != "https" && !isCloudURL {
+		return "", nil, fmt.Errorf(
+			"keystores can only be downloaded over https or from an %s:// or %s:// bucket, received %s",
+			s3Scheme, gcsScheme, bundleURL.Scheme,
+		)
+	}
+	wantDigest, err := hex.DecodeString(strings.TrimPrefix(cliCtx.String(flags.KeystoresSHA256Flag.Name), "0x"))
+	if err != nil || len(wantDigest) != sha256.Size {
+		return "", nil, fmt.Errorf("--%s must be the hex encoded sha256 digest of the keystores", flags.KeystoresSHA256Flag.Name)
+	}
+	encryption, err := keystoresEncryptionFromCli(cliCtx, isCloudURL)
+	if err != nil {
+		return "", nil, err
+	}
+	fileName := path.Base(bundleURL.Path)
+	if encryption.clientSideKey != nil {
+		fileName = strings.TrimSuffix(fileName, encryptedBundleSuffix)
+	}
+	if !isKeystoreArchive(fileName) && filepath.Ext(fileName) != ".json" {
+		return "", nil, fmt.Errorf("%s is neither a keystore archive nor a keystore json file", fileName)
+	}
+
+	var body io.ReadCloser
+	if isCloudURL {
+		body, err = openCloudObject(ctx, bundleURL, encryption.serverSideKey)
+	} else {
+		body, err = openHTTPSDownload(ctx, bundleURL)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	defer func() {
+		if err := body.Close(); err != nil {
+			log.WithError(err).Error("Could not close keystores download")
+		}
+	}()
+	var bundle io.Reader = body
+	if encryption.clientSideKey != nil {
+		plaintext, err := decryptKeystoresDownload(body, encryption.clientSideKey)
+		if err != nil {
+			return "", nil, err
+		}
+		bundle = bytes.NewReader(plaintext)
+	}
+
+	downloadDir, err := ioutil.TempDir("", "prysm-keystores")
+	if err != nil {
+		return "", nil, errors.Wrap(err, "could not create download directory")
+	}
+	cleanup := func() {
+		if err := os.RemoveAll(downloadDir); err != nil {
+			log.WithError(err).Error("Could not remove downloaded keystores")
+		}
+	}
+	downloadPath := filepath.Join(downloadDir, fileName)
+	if err := writeVerifiedDownload(bundle, downloadPath, wantDigest); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	log.WithField("host", bundleURL.Host).Info("Downloaded keystores and verified their sha256 digest")
+	return downloadPath, cleanup, nil
+}
+
+func openHTTPSDownload(ctx context.Context, bundleURL *url.URL) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, bundleURL.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create request")
+	}
+	resp, err := keystoreDownloadClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not download keystores")
+	}
+	if resp.StatusCode != http.StatusOK {
+		if err := resp.Body.Close(); err != nil {
+			log.WithError(err).Error("Could not close response body")
+		}
+		return nil, fmt.Errorf("could not download keystores: %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func writeVerifiedDownload(body io.Reader, downloadPath string, wantDigest []byte) error {
+	f, err := os.OpenFile(downloadPath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return errors.Wrap(err, "could not create downloaded keystores file")
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.WithError(err).Error("Could not close downloaded keystores file")
+		}
+	}()
+	hasher := sha256.New()
+	// Read one byte past the limit to tell a bundle of exactly the maximum size from a larger one.
+	n, err := io.Copy(io.MultiWriter(f, hasher), io.LimitReader(body, maxKeystoreDownloadSize+1))
+	if err != nil {
+		return errors.Wrap(err, "could not download keystores")
+	}
+	if n > maxKeystoreDownloadSize {
+		return fmt.Errorf("keystores download is larger than %d bytes", maxKeystoreDownloadSize)
+	}
+	if digest := hasher.Sum(nil); !bytes.Equal(digest, wantDigest) {
+		return fmt.Errorf("downloaded keystores have sha256 digest %#x, expected %#x", digest, wantDigest)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package v2
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+)
+
+func TestImport_KeystoresURL(t *testing.T) {
+	keystore := encodedTestKeystore(t, nil)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/keystore-0.json" {
+			http.NotFound(w, r)
+			return
+		}
+		_, err := w.Write(keystore)
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+	defer func(client *http.Client) {
+		keystoreDownloadClient = client
+	}(keystoreDownloadClient)
+	keystoreDownloadClient = srv.Client()
+	digest := sha256.Sum256(keystore)
+
+	tests := []struct {
+		name    string
+		url     string
+		digest  string
+		wantErr string
+	}{
+		{
+			name:   "verified",
+			url:    srv.URL + "/keystore-0.json",
+			digest: fmt.Sprintf("%x", digest),
+		},
+		{
+			name:    "digest mismatch",
+			url:     srv.URL + "/keystore-0.json",
+			digest:  fmt.Sprintf("%x", sha256.Sum256([]byte("something else"))),
+			wantErr: "downloaded keystores have sha256 digest",
+		},
+		{
+			name:    "missing digest",
+			url:     srv.URL + "/keystore-0.json",
+			wantErr: "must be the hex encoded sha256 digest",
+		},
+		{
+			name:    "plain http",
+			url:     "http://localhost/keystore-0.json",
+			digest:  fmt.Sprintf("%x", digest),
+			wantErr: "can only be downloaded over https",
+		},
+		{
+			name:    "not found",
+			url:     srv.URL + "/keystore-1.json",
+			digest:  fmt.Sprintf("%x", digest),
+			wantErr: "404 Not Found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+			cliCtx := setupWalletCtx(t, &testWalletConfig{
+				walletDir:           walletDir,
+				passwordsDir:        passwordsDir,
+				keymanagerKind:      v2keymanager.Direct,
+				walletPasswordFile:  passwordFilePath,
+				accountPasswordFile: passwordFilePath,
+				keystoresURL:        tt.url,
+				keystoresSHA256:     tt.digest,
+			})
+			err := ImportAccount(cliCtx)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, tt.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+			ctx := context.Background()
+			wallet, err := OpenWallet(cliCtx)
+			require.NoError(t, err)
+			keymanager, err := wallet.InitializeKeymanager(ctx, true)
+			require.NoError(t, err)
+			pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, 1, len(pubKeys))
+		})
+	}
+}
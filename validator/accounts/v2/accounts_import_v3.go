@@ -0,0 +1,169 @@
+package v2
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/hashutil"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Version of the Web3 Secret Storage keystores created by geth and other eth1 tooling.
+const ethV3KeystoreVersion = 3
+
+// ethV3Keystore is a Web3 Secret Storage (version 3) keystore. Some tooling wraps BLS secret
+// keys in this format, giving the BLS public key in the pubkey field.
+type ethV3Keystore struct {
+	Version int          `json:"version"`
+	ID      string       `json:"id"`
+	Address string       `json:"address"`
+	Pubkey  string       `json:"pubkey"`
+	Path    string       `json:"path"`
+	Crypto  *ethV3Crypto `json:"crypto"`
+}
+
+type ethV3Crypto struct {
+	Cipher       string `json:"cipher"`
+	CipherText   string `json:"ciphertext"`
+	CipherParams struct {
+		IV string `json:"iv"`
+	} `json:"cipherparams"`
+	KDF       string                 `json:"kdf"`
+	KDFParams map[string]interface{} `json:"kdfparams"`
+	MAC       string                 `json:"mac"`
+}
+
+// Reports whether a keystore file is an Ethereum v3 keystore rather than an EIP-2335 one.
+func isEthV3Keystore(keystoreBytes []byte) bool {
+	keystore := &struct {
+		Version int `json:"version"`
+	}{}
+	if err := json.Unmarshal(keystoreBytes, keystore); err != nil {
+		return false
+	}
+	return keystore.Version == ethV3KeystoreVersion
+}
+
+// Decrypts an Ethereum v3 keystore and re-encrypts its secret key, with the same password, into
+// an EIP-2335 keystore. The keystore must hold a BLS secret key and give its BLS public key: a
+// keystore without a public key, such as one which only identifies an eth1 address, or a payload
+// which is not a valid BLS secret key or does not match the public key, is rejected.
+func convertEthV3Keystore(keystoreBytes []byte, password string) ([]byte, error) {
+	keystore := &ethV3Keystore{}
+	if err := json.Unmarshal(keystoreBytes, keystore); err != nil {
+		return nil, errors.Wrap(err, "could not decode v3 keystore json")
+	}
+	if keystore.Crypto == nil {
+		return nil, errors.New("v3 keystore has no crypto section")
+	}
+	if keystore.Pubkey == "" && keystore.Address != "" {
+		return nil, fmt.Errorf(
+			"v3 keystore for eth1 address %s holds a secp256k1 key, only BLS validating keys can be imported",
+			keystore.Address,
+		)
+	}
+	if keystore.Pubkey == "" {
+		return nil, errors.New("v3 keystore has no BLS public key")
+	}
+	pubKey, err := hex.DecodeString(strings.TrimPrefix(keystore.Pubkey, "0x"))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode public key string in v3 keystore")
+	}
+	secret, err := keystore.Crypto.decrypt(password)
+	if err != nil {
+		return nil, err
+	}
+	secretKey, err := bls.SecretKeyFromBytes(secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "v3 keystore does not hold a BLS secret key")
+	}
+	if !bytes.Equal(secretKey.PublicKey().Marshal(), pubKey) {
+		return nil, fmt.Errorf("v3 keystore public key %#x does not match its BLS secret key", bytesutil.Trunc(pubKey))
+	}
+	converted, err := v2keymanager.NewKeystore(secretKey, keystore.Path, password)
+	if err != nil {
+		return nil, err
+	}
+	log.WithField("publicKey", fmt.Sprintf("%#x", bytesutil.Trunc(secretKey.PublicKey().Marshal()))).Info(
+		"Converted Ethereum v3 keystore to an EIP-2335 keystore",
+	)
+	return json.MarshalIndent(converted, "", "\t")
+}
+
+func (c *ethV3Crypto) decrypt(password string) ([]byte, error) {
+	if c.Cipher != "aes-128-ctr" {
+		return nil, fmt.Errorf("unsupported v3 keystore cipher %q", c.Cipher)
+	}
+	salt, err := hex.DecodeString(stringParam(c.KDFParams, "salt"))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode v3 keystore salt")
+	}
+	dkLen, ok := intParam(c.KDFParams, "dklen")
+	if !ok || dkLen < 32 {
+		return nil, errors.New("v3 keystore kdf dklen must be at least 32")
+	}
+	var derivedKey []byte
+	switch c.KDF {
+	case "scrypt":
+		n, okN := intParam(c.KDFParams, "n")
+		r, okR := intParam(c.KDFParams, "r")
+		p, okP := intParam(c.KDFParams, "p")
+		if !okN || !okR || !okP {
+			return nil, errors.New("v3 keystore scrypt params must include n, r and p")
+		}
+		derivedKey, err = scrypt.Key([]byte(password), salt, n, r, p, dkLen)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not derive v3 keystore scrypt key")
+		}
+	case "pbkdf2":
+		iterations, ok := intParam(c.KDFParams, "c")
+		if !ok {
+			return nil, errors.New("v3 keystore pbkdf2 params must include c")
+		}
+		if prf := stringParam(c.KDFParams, "prf"); prf != "hmac-sha256" {
+			return nil, fmt.Errorf("unsupported v3 keystore pbkdf2 prf %q", prf)
+		}
+		derivedKey = pbkdf2.Key([]byte(password), salt, iterations, dkLen, sha256.New)
+	default:
+		return nil, fmt.Errorf("unsupported v3 keystore kdf %q", c.KDF)
+	}
+	cipherText, err := hex.DecodeString(c.CipherText)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode v3 keystore ciphertext")
+	}
+	mac, err := hex.DecodeString(c.MAC)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode v3 keystore mac")
+	}
+	wantMAC := hashutil.HashKeccak256(append(append([]byte{}, derivedKey[16:32]...), cipherText...))
+	if !bytes.Equal(mac, wantMAC[:]) {
+		return nil, errors.New("could not decrypt v3 keystore: invalid mac, wrong password")
+	}
+	iv, err := hex.DecodeString(c.CipherParams.IV)
+	if err != nil || len(iv) != aes.BlockSize {
+		return nil, errors.New("v3 keystore iv must be a hex encoded 16 byte value")
+	}
+	block, err := aes.NewCipher(derivedKey[:16])
+	if err != nil {
+		return nil, errors.Wrap(err, "could not initialize cipher")
+	}
+	secret := make([]byte, len(cipherText))
+	cipher.NewCTR(block, iv).XORKeyStream(secret, cipherText)
+	return secret, nil
+}
+
+func stringParam(params map[string]interface{}, key string) string {
+	s, _ := params[key].(string)
+	return s
+}
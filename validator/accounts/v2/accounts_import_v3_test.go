@@ -0,0 +1,173 @@
+package v2
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/hashutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Encrypts a secret into a v3 keystore with light scrypt parameters, as geth does.
+func encryptEthV3Keystore(t *testing.T, secret []byte, keystorePassword string, pubKey string, address string) []byte {
+	salt := make([]byte, 32)
+	_, err := rand.Read(salt)
+	require.NoError(t, err)
+	iv := make([]byte, aes.BlockSize)
+	_, err = rand.Read(iv)
+	require.NoError(t, err)
+	derivedKey, err := scrypt.Key([]byte(keystorePassword), salt, 4096, 8, 1, 32)
+	require.NoError(t, err)
+	block, err := aes.NewCipher(derivedKey[:16])
+	require.NoError(t, err)
+	cipherText := make([]byte, len(secret))
+	cipher.NewCTR(block, iv).XORKeyStream(cipherText, secret)
+	mac := hashutil.HashKeccak256(append(append([]byte{}, derivedKey[16:32]...), cipherText...))
+
+	keystore := map[string]interface{}{
+		"version": 3,
+		"id":      "3198bc9c-6672-5ab3-d995-4942343ae5b6",
+		"crypto": map[string]interface{}{
+			"cipher":       "aes-128-ctr",
+			"ciphertext":   hex.EncodeToString(cipherText),
+			"cipherparams": map[string]interface{}{"iv": hex.EncodeToString(iv)},
+			"kdf":          "scrypt",
+			"kdfparams": map[string]interface{}{
+				"dklen": 32,
+				"n":     4096,
+				"r":     8,
+				"p":     1,
+				"salt":  hex.EncodeToString(salt),
+			},
+			"mac": hex.EncodeToString(mac[:]),
+		},
+	}
+	if pubKey != "" {
+		keystore["pubkey"] = pubKey
+	}
+	if address != "" {
+		keystore["address"] = address
+	}
+	encoded, err := json.Marshal(keystore)
+	require.NoError(t, err)
+	return encoded
+}
+
+func TestConvertEthV3Keystore(t *testing.T) {
+	secretKey := bls.RandKey()
+	pubKeyHex := fmt.Sprintf("%x", secretKey.PublicKey().Marshal())
+	encoded := encryptEthV3Keystore(t, secretKey.Marshal(), password, pubKeyHex, "")
+	require.Equal(t, true, isEthV3Keystore(encoded))
+
+	converted, err := convertEthV3Keystore(encoded, password)
+	require.NoError(t, err)
+	assert.Equal(t, false, isEthV3Keystore(converted))
+	keystore := &v2keymanager.Keystore{}
+	require.NoError(t, json.Unmarshal(converted, keystore))
+	assert.Equal(t, pubKeyHex, keystore.Pubkey)
+	pw := password
+	report := validateKeystore("converted.json", converted, &pw)
+	assert.Equal(t, true, report.Valid, "Unexpected errors %v", report.Errors)
+}
+
+func TestConvertEthV3Keystore_Rejected(t *testing.T) {
+	secretKey := bls.RandKey()
+	pubKeyHex := fmt.Sprintf("%x", secretKey.PublicKey().Marshal())
+	otherPubKeyHex := fmt.Sprintf("%x", bls.RandKey().PublicKey().Marshal())
+	// Larger than the order of the BLS12-381 curve, so not a BLS secret key.
+	notBLS := make([]byte, 32)
+	for i := range notBLS {
+		notBLS[i] = 0xff
+	}
+	tests := []struct {
+		name     string
+		encoded  []byte
+		password string
+		wantErr  string
+	}{
+		{
+			name:     "eth1 account",
+			encoded:  encryptEthV3Keystore(t, secretKey.Marshal(), password, "", "008aeeda4d805471df9b2a5b0f38a0c3bcba786b"),
+			password: password,
+			wantErr:  "holds a secp256k1 key",
+		},
+		{
+			name:     "wrong password",
+			encoded:  encryptEthV3Keystore(t, secretKey.Marshal(), password, pubKeyHex, ""),
+			password: "wrong-password",
+			wantErr:  "invalid mac",
+		},
+		{
+			name:     "no pubkey",
+			encoded:  encryptEthV3Keystore(t, secretKey.Marshal(), password, "", ""),
+			password: password,
+			wantErr:  "has no BLS public key",
+		},
+		{
+			name:     "not a BLS key",
+			encoded:  encryptEthV3Keystore(t, notBLS, password, otherPubKeyHex, ""),
+			password: password,
+			wantErr:  "does not hold a BLS secret key",
+		},
+		{
+			name:     "mismatched pubkey",
+			encoded:  encryptEthV3Keystore(t, secretKey.Marshal(), password, otherPubKeyHex, ""),
+			password: password,
+			wantErr:  "does not match its BLS secret key",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := convertEthV3Keystore(tt.encoded, tt.password)
+			assert.ErrorContains(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestImport_EthV3Keystores(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	keysDir := filepath.Join(testutil.TempDir(), t.Name())
+	require.NoError(t, os.MkdirAll(keysDir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(keysDir), "Failed to remove directory")
+	})
+	secretKey := bls.RandKey()
+	pubKeyHex := fmt.Sprintf("0x%x", secretKey.PublicKey().Marshal())
+	encoded := encryptEthV3Keystore(t, secretKey.Marshal(), password, pubKeyHex, "")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(keysDir, "keystore-0.json"), encoded, os.ModePerm))
+
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		keysDir:             keysDir,
+		keymanagerKind:      v2keymanager.Direct,
+		walletPasswordFile:  passwordFilePath,
+		accountPasswordFile: passwordFilePath,
+	})
+	require.NoError(t, ImportAccount(cliCtx))
+
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	keymanager, err := wallet.InitializeKeymanager(ctx, true)
+	require.NoError(t, err)
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(pubKeys))
+	assert.Equal(t, bytesutil.ToBytes48(secretKey.PublicKey().Marshal()), pubKeys[0])
+}
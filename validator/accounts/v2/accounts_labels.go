@@ -0,0 +1,224 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/urfave/cli/v2"
+)
+
+// accountLabelsFileName is the file in the accounts directory of a wallet holding the labels of
+// its accounts.
+const accountLabelsFileName = "account-labels.json"
+
+// accountLabels are the key=value labels attached to the accounts of a wallet, by 0x-prefixed
+// validating public key so they follow an account when it is renamed.
+type accountLabels struct {
+	Accounts map[string]map[string]string `json:"accounts"`
+}
+
+// accountLabelFilter selects the accounts having every label of its selector, an empty selector
+// selecting every account.
+type accountLabelFilter struct {
+	labels   *accountLabels
+	selector map[string]string
+}
+
+// LabelAccounts adds the --add-labels to and removes the --remove-labels from the selected
+// accounts of a wallet.
+func LabelAccounts(cliCtx *cli.Context) error {
+	added, err := parseLabels(cliCtx.StringSlice(flags.AddLabelsFlag.Name))
+	if err != nil {
+		return err
+	}
+	removed := cliCtx.StringSlice(flags.RemoveLabelsFlag.Name)
+	if len(added) == 0 && len(removed) == 0 {
+		return fmt.Errorf("no labels given with --%s or --%s", flags.AddLabelsFlag.Name, flags.RemoveLabelsFlag.Name)
+	}
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	keymanager, err := wallet.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+	if err != nil {
+		return errors.Wrap(err, "could not initialize keymanager")
+	}
+	inventory, err := inventoryAccounts(ctx, wallet, keymanager)
+	if err != nil {
+		return errors.Wrap(err, "could not build account inventory")
+	}
+	if len(inventory.Accounts) == 0 {
+		return errors.New("wallet has no accounts to label")
+	}
+	accountNames := make([]string, len(inventory.Accounts))
+	pubKeys := make([][48]byte, len(inventory.Accounts))
+	byName := make(map[string][48]byte, len(inventory.Accounts))
+	for i, account := range inventory.Accounts {
+		accountNames[i] = account.Name
+		pubKeys[i], err = parsePubKey(account.PublicKey)
+		if err != nil {
+			return errors.Wrapf(err, "invalid public key of account %s", account.Name)
+		}
+		byName[account.Name] = pubKeys[i]
+	}
+	selectedAccounts, err := selectAccounts(cliCtx, accountNames, pubKeys)
+	if err != nil {
+		return errors.Wrap(err, "could not select accounts")
+	}
+	if len(selectedAccounts) == 0 {
+		return errors.New("no accounts selected to label")
+	}
+	labels, err := wallet.readAccountLabels()
+	if err != nil {
+		return err
+	}
+	for _, name := range selectedAccounts {
+		labels.update(byName[name], added, removed)
+	}
+	if err := wallet.writeAccountLabels(ctx, labels); err != nil {
+		return err
+	}
+	for _, name := range selectedAccounts {
+		fmt.Printf("%s %s\n", au.BrightGreen(name).Bold(), formatLabels(labels.of(byName[name])))
+	}
+	return nil
+}
+
+// Reads the labels of the accounts of the wallet, which are empty until an account is labeled.
+func (w *Wallet) readAccountLabels() (*accountLabels, error) {
+	labels := &accountLabels{Accounts: make(map[string]map[string]string)}
+	encoded, err := w.files().readFile(context.Background(), accountLabelsFileName)
+	if os.IsNotExist(err) {
+		return labels, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read account labels")
+	}
+	if err := json.Unmarshal(encoded, labels); err != nil {
+		return nil, errors.Wrap(err, "could not decode account labels")
+	}
+	if labels.Accounts == nil {
+		labels.Accounts = make(map[string]map[string]string)
+	}
+	return labels, nil
+}
+
+func (w *Wallet) writeAccountLabels(ctx context.Context, labels *accountLabels) error {
+	encoded, err := json.MarshalIndent(labels, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "could not marshal account labels")
+	}
+	if err := w.WriteFileAtPath(ctx, "" /* accounts dir */, accountLabelsFileName, encoded); err != nil {
+		return errors.Wrap(err, "could not write account labels")
+	}
+	return nil
+}
+
+// Reads the labels of the wallet and the --with-labels selector.
+func (w *Wallet) labelFilterFromCli(cliCtx *cli.Context) (*accountLabelFilter, error) {
+	selector, err := parseLabels(cliCtx.StringSlice(flags.WithLabelsFlag.Name))
+	if err != nil {
+		return nil, err
+	}
+	labels, err := w.readAccountLabels()
+	if err != nil {
+		return nil, err
+	}
+	return &accountLabelFilter{labels: labels, selector: selector}, nil
+}
+
+func (l *accountLabels) of(pubKey [48]byte) map[string]string {
+	return l.Accounts[fmt.Sprintf("%#x", pubKey)]
+}
+
+func (l *accountLabels) update(pubKey [48]byte, added map[string]string, removed []string) {
+	key := fmt.Sprintf("%#x", pubKey)
+	current := l.Accounts[key]
+	if current == nil {
+		current = make(map[string]string, len(added))
+	}
+	for _, name := range removed {
+		delete(current, name)
+	}
+	for name, value := range added {
+		current[name] = value
+	}
+	if len(current) == 0 {
+		delete(l.Accounts, key)
+		return
+	}
+	l.Accounts[key] = current
+}
+
+// Removes the labels of accounts deleted from the wallet, returning whether any of them had labels.
+func (l *accountLabels) forget(pubKeys [][48]byte) bool {
+	forgotten := false
+	for _, pubKey := range pubKeys {
+		key := fmt.Sprintf("%#x", pubKey)
+		if _, ok := l.Accounts[key]; ok {
+			delete(l.Accounts, key)
+			forgotten = true
+		}
+	}
+	return forgotten
+}
+
+func (f *accountLabelFilter) active() bool {
+	return len(f.selector) > 0
+}
+
+func (f *accountLabelFilter) allows(pubKey [48]byte) bool {
+	held := f.labels.of(pubKey)
+	for name, value := range f.selector {
+		if got, ok := held[name]; !ok || got != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Keeps the accounts the label filter allows, given by name along with their public keys.
+func filterAccountsByLabels(accountNames []string, pubKeys [][48]byte, labelFilter *accountLabelFilter) ([]string, [][48]byte) {
+	keptNames := make([]string, 0, len(accountNames))
+	keptPubKeys := make([][48]byte, 0, len(pubKeys))
+	for i, name := range accountNames {
+		if labelFilter.allows(pubKeys[i]) {
+			keptNames = append(keptNames, name)
+			keptPubKeys = append(keptPubKeys, pubKeys[i])
+		}
+	}
+	return keptNames, keptPubKeys
+}
+
+// Parses labels given as key=value.
+func parseLabels(entries []string) (map[string]string, error) {
+	labels := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		parts := strings.SplitN(entry, "=", 2)
+		name := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || name == "" || strings.ContainsAny(name, " ,") {
+			return nil, fmt.Errorf("label %q must be given as key=value, with a key without spaces or commas", entry)
+		}
+		labels[name] = strings.TrimSpace(parts[1])
+	}
+	return labels, nil
+}
+
+// Formats labels as key=value pairs sorted by key.
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for name, value := range labels {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
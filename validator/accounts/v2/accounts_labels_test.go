@@ -0,0 +1,94 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestLabelAccounts(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFilePath,
+		keymanagerKind:     v2keymanager.Direct,
+	}
+	wallet, err := NewWallet(setupWalletCtx(t, cfg), v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	names := make([]string, 3)
+	pubKeys := make([][48]byte, 3)
+	for i := range names {
+		names[i], err = keymanager.CreateAccount(ctx, password)
+		require.NoError(t, err)
+		pubKeys[i], err = keymanager.PublicKeyForAccount(names[i])
+		require.NoError(t, err)
+	}
+
+	cfg.accountsToExport = "all"
+	cfg.addLabels = []string{"customer=acme", "batch=2020-09"}
+	require.NoError(t, LabelAccounts(setupWalletCtx(t, cfg)))
+	cfg.accountsToExport = fmt.Sprintf("%#x", pubKeys[1])
+	cfg.addLabels = []string{"batch=2020-10"}
+	require.NoError(t, LabelAccounts(setupWalletCtx(t, cfg)))
+	cfg.accountsToExport = names[2]
+	cfg.addLabels = nil
+	cfg.removeLabels = []string{"customer"}
+	require.NoError(t, LabelAccounts(setupWalletCtx(t, cfg)))
+
+	labels, err := wallet.readAccountLabels()
+	require.NoError(t, err)
+	assert.DeepEqual(t, map[string]string{"customer": "acme", "batch": "2020-09"}, labels.of(pubKeys[0]))
+	assert.DeepEqual(t, map[string]string{"customer": "acme", "batch": "2020-10"}, labels.of(pubKeys[1]))
+	assert.DeepEqual(t, map[string]string{"batch": "2020-09"}, labels.of(pubKeys[2]))
+
+	cfg.withLabels = []string{"customer=acme", "batch=2020-09"}
+	labelFilter, err := wallet.labelFilterFromCli(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	assert.Equal(t, true, labelFilter.active())
+	keptNames, keptPubKeys := filterAccountsByLabels(names, pubKeys, labelFilter)
+	assert.DeepEqual(t, []string{names[0]}, keptNames)
+	assert.DeepEqual(t, [][48]byte{pubKeys[0]}, keptPubKeys)
+
+	inventory, err := inventoryAccounts(ctx, wallet, keymanager)
+	require.NoError(t, err)
+	require.NoError(t, inventory.filter(labelFilter))
+	require.Equal(t, 1, len(inventory.Accounts))
+	assert.Equal(t, names[0], inventory.Accounts[0].Name)
+	assert.DeepEqual(t, labels.of(pubKeys[0]), inventory.Accounts[0].Labels)
+
+	// Deleting the accounts having a label forgets their labels along with them.
+	cfg.withLabels = []string{"batch=2020-09"}
+	require.NoError(t, DeleteAccount(setupWalletCtx(t, cfg)))
+	accounts, err := wallet.accountPubKeys(ctx)
+	require.NoError(t, err)
+	assert.DeepEqual(t, map[string][48]byte{names[1]: pubKeys[1]}, accounts)
+	labels, err = wallet.readAccountLabels()
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(labels.Accounts))
+	assert.DeepEqual(t, map[string]string{"customer": "acme", "batch": "2020-10"}, labels.of(pubKeys[1]))
+}
+
+func TestParseLabels(t *testing.T) {
+	labels, err := parseLabels([]string{"customer=acme", " batch = 2020-09", "note=a=b", "empty="})
+	require.NoError(t, err)
+	assert.DeepEqual(t, map[string]string{"customer": "acme", "batch": "2020-09", "note": "a=b", "empty": ""}, labels)
+	assert.Equal(t, "batch=2020-09, customer=acme, empty=, note=a=b", formatLabels(labels))
+
+	for _, entry := range []string{"customer", "=acme", "my customer=acme"} {
+		_, err := parseLabels([]string{entry})
+		assert.ErrorContains(t, "must be given as key=value", err)
+	}
+}
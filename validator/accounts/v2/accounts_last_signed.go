@@ -0,0 +1,97 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/validator/db/kv"
+)
+
+// lastSigned is the last block and attestation an account signed, as recorded in the slashing
+// protection history of the validator database. Either is nil if the account never signed one.
+type lastSigned struct {
+	ProposedSlot  *uint64 `json:"proposed_slot,omitempty" yaml:"proposed_slot,omitempty"`
+	AttestedEpoch *uint64 `json:"attested_epoch,omitempty" yaml:"attested_epoch,omitempty"`
+}
+
+// Sets the last block and attestation signed by the accounts of the inventory, read from the
+// validator database in the data directory. The validator client records both in its slashing
+// protection history before signing, so they are never later than what it actually signed.
+func inventoryLastSigned(ctx context.Context, dataDir string, inventory *accountInventory) error {
+	store, err := kv.GetKVStore(dataDir)
+	if err != nil {
+		return errors.Wrap(err, "could not open validator database")
+	}
+	if store == nil {
+		return fmt.Errorf("no validator database found in %s to read the last signatures of accounts from", dataDir)
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			log.WithError(err).Error("Could not close validator database")
+		}
+	}()
+	return readLastSigned(ctx, store, inventory)
+}
+
+func readLastSigned(ctx context.Context, validatorDB *kv.Store, inventory *accountInventory) error {
+	pubKeys := make([][48]byte, len(inventory.Accounts))
+	for i, account := range inventory.Accounts {
+		pubKey, err := parsePubKey(account.PublicKey)
+		if err != nil {
+			return errors.Wrapf(err, "invalid public key of account %s", account.Name)
+		}
+		pubKeys[i] = pubKey
+	}
+	attestationHistory, err := validatorDB.AttestationHistoryForPubKeys(ctx, pubKeys)
+	if err != nil {
+		return errors.Wrap(err, "could not read attestation history")
+	}
+	slotsPerEpoch := params.BeaconConfig().SlotsPerEpoch
+	for i, account := range inventory.Accounts {
+		signed := &lastSigned{}
+		proposalHistory, err := validatorDB.ProposalHistoryForPubKey(ctx, pubKeys[i][:])
+		if err != nil {
+			return errors.Wrapf(err, "could not read proposal history of account %s", account.Name)
+		}
+		for epoch, slotBits := range proposalHistory {
+			for j := uint64(0); j < slotsPerEpoch && j < slotBits.Len(); j++ {
+				slot := epoch*slotsPerEpoch + j
+				if slotBits.BitAt(j) && (signed.ProposedSlot == nil || slot > *signed.ProposedSlot) {
+					signed.ProposedSlot = &slot
+				}
+			}
+		}
+		// The latest written target epoch of a history without attestations is 0, with no source.
+		if history := attestationHistory[pubKeys[i]]; history != nil {
+			target := history.LatestEpochWritten
+			source, ok := history.TargetToSource[target%params.BeaconConfig().WeakSubjectivityPeriod]
+			if ok && source != params.BeaconConfig().FarFutureEpoch {
+				signed.AttestedEpoch = &target
+			}
+		}
+		account.LastSigned = signed
+	}
+	return nil
+}
+
+func printAccountLastSigned(account *inventoryAccount) {
+	if account.LastSigned == nil {
+		return
+	}
+	proposed, attested := "never", "never"
+	if account.LastSigned.ProposedSlot != nil {
+		proposed = fmt.Sprintf("slot %d", *account.LastSigned.ProposedSlot)
+	}
+	if account.LastSigned.AttestedEpoch != nil {
+		attested = fmt.Sprintf("epoch %d", *account.LastSigned.AttestedEpoch)
+	}
+	fmt.Printf(
+		"%s proposed %s, attested %s\n",
+		aurora.NewAurora(true).BrightYellow("[last signed]").Bold(),
+		proposed,
+		attested,
+	)
+}
@@ -0,0 +1,64 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/go-bitfield"
+	slashpb "github.com/prysmaticlabs/prysm/proto/slashing"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	"github.com/prysmaticlabs/prysm/validator/db/kv"
+)
+
+func TestInventoryLastSigned(t *testing.T) {
+	ctx := context.Background()
+	dataDir := filepath.Join(testutil.TempDir(), t.Name())
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dataDir), "Failed to remove directory")
+	})
+	signing := [48]byte{1}
+	quiet := [48]byte{2}
+	inventory := &accountInventory{
+		Accounts: []*inventoryAccount{
+			{Name: "signing", PublicKey: fmt.Sprintf("%#x", signing)},
+			{Name: "quiet", PublicKey: fmt.Sprintf("%#x", quiet)},
+		},
+	}
+	assert.ErrorContains(t, "no validator database found", inventoryLastSigned(ctx, dataDir, inventory))
+
+	store, err := kv.NewKVStore(dataDir, [][48]byte{signing, quiet})
+	require.NoError(t, err)
+	slotsPerEpoch := params.BeaconConfig().SlotsPerEpoch
+	for epoch, slot := range map[uint64]uint64{2: 1, 4: 3} {
+		slotBits := bitfield.NewBitlist(slotsPerEpoch)
+		slotBits.SetBitAt(slot, true)
+		require.NoError(t, store.SaveProposalHistoryForEpoch(ctx, signing[:], epoch, slotBits))
+	}
+	require.NoError(t, store.SaveAttestationHistoryForPubKeys(ctx, map[[48]byte]*slashpb.AttestationHistory{
+		signing: {
+			TargetToSource:     map[uint64]uint64{0: params.BeaconConfig().FarFutureEpoch, 6: 5, 7: 6},
+			LatestEpochWritten: 7,
+		},
+	}))
+	require.NoError(t, store.Close())
+
+	require.NoError(t, inventoryLastSigned(ctx, dataDir, inventory))
+	signed := inventory.Accounts[0].LastSigned
+	require.NotNil(t, signed)
+	require.NotNil(t, signed.ProposedSlot)
+	assert.Equal(t, 4*slotsPerEpoch+3, *signed.ProposedSlot)
+	require.NotNil(t, signed.AttestedEpoch)
+	assert.Equal(t, uint64(7), *signed.AttestedEpoch)
+
+	// Accounts which never signed have an empty record rather than none, so they stand out.
+	signed = inventory.Accounts[1].LastSigned
+	require.NotNil(t, signed)
+	assert.Equal(t, true, signed.ProposedSlot == nil)
+	assert.Equal(t, true, signed.AttestedEpoch == nil)
+}
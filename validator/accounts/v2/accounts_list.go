@@ -3,12 +3,13 @@ package v2
 import (
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/logrusorgru/aurora"
 	"github.com/pkg/errors"
-	"github.com/prysmaticlabs/prysm/shared/petnames"
 	"github.com/prysmaticlabs/prysm/validator/flags"
 	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
 	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/derived"
@@ -17,8 +18,16 @@ import (
 	"github.com/urfave/cli/v2"
 )
 
-// ListAccounts displays all available validator accounts in a Prysm wallet.
+// ListAccounts displays the validator accounts in a Prysm wallet, optionally sorted and paginated.
 func ListAccounts(cliCtx *cli.Context) error {
+	outputFormat := cliCtx.String(flags.ListOutputFlag.Name)
+	if err := validateListFormat(outputFormat); err != nil {
+		return err
+	}
+	listOpts, err := listOptionsFromCli(cliCtx)
+	if err != nil {
+		return err
+	}
 	// Read the wallet from the specified path.
 	ctx := context.Background()
 	wallet, err := OpenWallet(cliCtx)
@@ -27,18 +36,51 @@ func ListAccounts(cliCtx *cli.Context) error {
 	} else if err != nil {
 		return errors.Wrap(err, "could not open wallet")
 	}
-	keymanager, err := wallet.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+	labelFilter, err := wallet.labelFilterFromCli(cliCtx)
+	if err != nil {
+		return err
+	}
+	var keymanager v2keymanager.IKeymanager
+	var inventory *accountInventory
+	if wallet.KeymanagerKind() == v2keymanager.Direct {
+		// The accounts of a non-HD wallet are listed from their keystores without decrypting them.
+		inventory, err = directInventory(ctx, wallet)
+	} else {
+		keymanager, err = wallet.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+		if err != nil {
+			return errors.Wrap(err, "could not initialize keymanager")
+		}
+		inventory, err = inventoryAccounts(ctx, wallet, keymanager)
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not build account inventory")
+	}
+	if err := inventory.filter(labelFilter); err != nil {
+		return err
+	}
+	if listOpts.countOnly {
+		fmt.Println(len(inventory.Accounts))
+		return nil
+	}
+	if err := inventory.sort(listOpts.sortBy); err != nil {
+		return err
+	}
+	offset, err := inventory.paginate(listOpts.page, listOpts.pageSize)
 	if err != nil {
-		return errors.Wrap(err, "could not initialize keymanager")
+		return err
+	}
+	if cliCtx.Bool(flags.ShowLastSignedFlag.Name) {
+		if err := inventoryLastSigned(ctx, validatorDataDir(cliCtx), inventory); err != nil {
+			return err
+		}
+	}
+	if outputFormat == jsonListFormat || outputFormat == yamlListFormat {
+		return writeStructuredOutput(os.Stdout, inventory, outputFormat)
 	}
 	showDepositData := cliCtx.Bool(flags.ShowDepositDataFlag.Name)
 	switch wallet.KeymanagerKind() {
 	case v2keymanager.Direct:
-		km, ok := keymanager.(*direct.Keymanager)
-		if !ok {
-			return errors.New("could not assert keymanager interface to concrete type")
-		}
-		if err := listDirectKeymanagerAccounts(showDepositData, wallet, km); err != nil {
+		if err := listDirectKeymanagerAccounts(showDepositData, wallet, inventory, offset); err != nil {
 			return errors.Wrap(err, "could not list validator accounts with direct keymanager")
 		}
 	case v2keymanager.Derived:
@@ -46,7 +88,7 @@ func ListAccounts(cliCtx *cli.Context) error {
 		if !ok {
 			return errors.New("could not assert keymanager interface to concrete type")
 		}
-		if err := listDerivedKeymanagerAccounts(showDepositData, wallet, km); err != nil {
+		if err := listDerivedKeymanagerAccounts(showDepositData, km, inventory); err != nil {
 			return errors.Wrap(err, "could not list validator accounts with derived keymanager")
 		}
 	case v2keymanager.Remote:
@@ -54,7 +96,7 @@ func ListAccounts(cliCtx *cli.Context) error {
 		if !ok {
 			return errors.New("could not assert keymanager interface to concrete type")
 		}
-		if err := listRemoteKeymanagerAccounts(wallet, km, km.Config()); err != nil {
+		if err := listRemoteKeymanagerAccounts(wallet, km.Config(), inventory); err != nil {
 			return errors.Wrap(err, "could not list validator accounts with remote keymanager")
 		}
 	default:
@@ -66,49 +108,46 @@ func ListAccounts(cliCtx *cli.Context) error {
 func listDirectKeymanagerAccounts(
 	showDepositData bool,
 	wallet *Wallet,
-	keymanager *direct.Keymanager,
+	inventory *accountInventory,
+	offset int,
 ) error {
-	// We initialize the wallet's keymanager.
-	accountNames, err := keymanager.ValidatingAccountNames()
-	if err != nil {
-		return errors.Wrap(err, "could not fetch account names")
-	}
 	au := aurora.NewAurora(true)
-	numAccounts := au.BrightYellow(len(accountNames))
 	fmt.Println("")
-	if len(accountNames) == 1 {
-		fmt.Printf("Showing %d validator account\n", numAccounts)
-	} else {
-		fmt.Printf("Showing %d validator accounts\n", numAccounts)
-	}
+	printShownAccounts(inventory, offset)
 	fmt.Println(
 		au.BrightRed("View the eth1 deposit transaction data for your accounts " +
 			"by running `validator accounts-v2 list --show-deposit-data"),
 	)
 
 	ctx := context.Background()
-	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
-	if err != nil {
-		return errors.Wrap(err, "could not fetch validating public keys")
-	}
-	for i := 0; i < len(accountNames); i++ {
+	for i, account := range inventory.Accounts {
 		fmt.Println("")
 
-		// Retrieve the account creation timestamp.
-		keystoreFileName, err := wallet.FileNameAtPath(ctx, accountNames[i], direct.KeystoreFileName)
-		if err != nil {
-			return errors.Wrapf(err, "could not get keystore file name for account: %s", accountNames[i])
-		}
-		unixTimestamp, err := AccountTimestamp(keystoreFileName)
+		createdAt, err := time.Parse(time.RFC3339, account.CreatedAt)
 		if err != nil {
-			return errors.Wrap(err, "could not get timestamp from keystore file name")
+			return errors.Wrapf(err, "could not parse creation time of account: %s", account.Name)
 		}
-		fmt.Printf("%s | %s | Created %s\n", au.BrightBlue(fmt.Sprintf("Account %d", i)).Bold(), au.BrightGreen(accountNames[i]).Bold(), humanize.Time(unixTimestamp))
-		fmt.Printf("%s %#x\n", au.BrightMagenta("[validating public key]").Bold(), pubKeys[i])
+		fmt.Printf("%s | %s | Created %s\n", au.BrightBlue(fmt.Sprintf("Account %d", offset+i)).Bold(), au.BrightGreen(account.Name).Bold(), humanize.Time(createdAt))
+		fmt.Printf("%s %s\n", au.BrightMagenta("[validating public key]").Bold(), account.PublicKey)
+		printAccountLabels(account.Labels)
+		printAccountLastSigned(account)
+		printAccountNotes(account.Notes)
 		if !showDepositData {
 			continue
 		}
-		enc, err := wallet.ReadFileAtPath(ctx, accountNames[i], direct.DepositDataFileName)
+		_, err = wallet.ReadFileAtPath(ctx, account.Name, direct.DepositDataJSONFileName)
+		hasDepositJSON := err == nil
+		if hasDepositJSON {
+			fmt.Printf(
+				"%s %s\n",
+				"(deposit_data.json file)",
+				filepath.Join(wallet.AccountsDir(), account.Name, direct.DepositDataJSONFileName),
+			)
+		}
+		enc, err := wallet.ReadFileAtPath(ctx, account.Name, direct.DepositDataFileName)
+		if err != nil && hasDepositJSON {
+			continue
+		}
 		if err != nil {
 			fmt.Printf(
 				"%s\n",
@@ -120,7 +159,7 @@ func listDirectKeymanagerAccounts(
 		fmt.Printf(
 			"%s %s\n",
 			"(deposit_data.ssz file)",
-			filepath.Join(wallet.AccountsDir(), accountNames[i], direct.DepositDataFileName),
+			filepath.Join(wallet.AccountsDir(), account.Name, direct.DepositDataFileName),
 		)
 		fmt.Printf(`
 ======================SSZ Deposit Data=====================
@@ -136,8 +175,8 @@ func listDirectKeymanagerAccounts(
 
 func listDerivedKeymanagerAccounts(
 	showDepositData bool,
-	wallet *Wallet,
 	keymanager *derived.Keymanager,
+	inventory *accountInventory,
 ) error {
 	au := aurora.NewAurora(true)
 	fmt.Println(
@@ -147,44 +186,43 @@ func listDerivedKeymanagerAccounts(
 	fmt.Printf("(keymanager kind) %s\n", au.BrightGreen("derived, (HD) hierarchical-deterministic").Bold())
 	fmt.Printf("(derivation format) %s\n", au.BrightGreen(keymanager.Config().DerivedPathStructure).Bold())
 	ctx := context.Background()
-	validatingPubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
-	if err != nil {
-		return errors.Wrap(err, "could not fetch validating public keys")
-	}
 	withdrawalPublicKeys, err := keymanager.FetchWithdrawalPublicKeys(ctx)
 	if err != nil {
 		return errors.Wrap(err, "could not fetch validating public keys")
 	}
-	nextAccountNumber := keymanager.NextAccountNumber(ctx)
-	currentAccountNumber := nextAccountNumber
-	if nextAccountNumber > 0 {
-		currentAccountNumber--
-	}
 	accountNames, err := keymanager.ValidatingAccountNames(ctx)
 	if err != nil {
 		return err
 	}
-	if len(accountNames) == 1 {
-		fmt.Print("Showing 1 validator account\n")
-	} else if len(accountNames) == 0 {
-		fmt.Print("No accounts found\n")
+	// Account names and withdrawal public keys are in derivation order.
+	indices := keymanager.AccountIndices()
+	accountPositions := make(map[string]int, len(accountNames))
+	for i, name := range accountNames {
+		accountPositions[name] = i
+	}
+	if !printShownAccounts(inventory, 0) {
 		return nil
-	} else {
-		fmt.Printf("Showing %d validator accounts\n", len(accountNames))
 	}
-	for i := uint64(0); i <= currentAccountNumber; i++ {
+	for _, account := range inventory.Accounts {
+		pos, ok := accountPositions[account.Name]
+		if !ok || pos >= len(withdrawalPublicKeys) || pos >= len(indices) {
+			return fmt.Errorf("could not find derivation index of account: %s", account.Name)
+		}
+		i := indices[pos]
 		fmt.Println("")
 		validatingKeyPath := fmt.Sprintf(derived.ValidatingKeyDerivationPathTemplate, i)
 		withdrawalKeyPath := fmt.Sprintf(derived.WithdrawalKeyDerivationPathTemplate, i)
 
 		// Retrieve the withdrawal key account metadata.
-		fmt.Printf("%s | %s\n", au.BrightBlue(fmt.Sprintf("Account %d", i)).Bold(), au.BrightGreen(accountNames[i]).Bold())
-		fmt.Printf("%s %#x\n", au.BrightMagenta("[withdrawal public key]").Bold(), withdrawalPublicKeys[i])
+		fmt.Printf("%s | %s\n", au.BrightBlue(fmt.Sprintf("Account %d", i)).Bold(), au.BrightGreen(account.Name).Bold())
+		fmt.Printf("%s %#x\n", au.BrightMagenta("[withdrawal public key]").Bold(), withdrawalPublicKeys[pos])
 		fmt.Printf("%s %s\n", au.BrightMagenta("[derivation path]").Bold(), withdrawalKeyPath)
 
 		// Retrieve the validating key account metadata.
-		fmt.Printf("%s %#x\n", au.BrightCyan("[validating public key]").Bold(), validatingPubKeys[i])
+		fmt.Printf("%s %s\n", au.BrightCyan("[validating public key]").Bold(), account.PublicKey)
 		fmt.Printf("%s %s\n", au.BrightCyan("[derivation path]").Bold(), validatingKeyPath)
+		printAccountLabels(account.Labels)
+		printAccountLastSigned(account)
 
 		if !showDepositData {
 			continue
@@ -206,8 +244,8 @@ func listDerivedKeymanagerAccounts(
 
 func listRemoteKeymanagerAccounts(
 	wallet *Wallet,
-	keymanager v2keymanager.IKeymanager,
 	cfg *remote.Config,
+	inventory *accountInventory,
 ) error {
 	au := aurora.NewAurora(true)
 	fmt.Printf("(keymanager kind) %s\n", au.BrightGreen("remote signer").Bold())
@@ -215,30 +253,56 @@ func listRemoteKeymanagerAccounts(
 		"(configuration file path) %s\n",
 		au.BrightGreen(filepath.Join(wallet.AccountsDir(), KeymanagerConfigFileName)).Bold(),
 	)
-	ctx := context.Background()
 	fmt.Println(" ")
 	fmt.Printf("%s\n", au.BrightGreen("Configuration options").Bold())
 	fmt.Println(cfg)
-	validatingPubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
-	if err != nil {
-		return errors.Wrap(err, "could not fetch validating public keys")
-	}
-	if len(validatingPubKeys) == 1 {
-		fmt.Print("Showing 1 validator account\n")
-	} else if len(validatingPubKeys) == 0 {
-		fmt.Print("No accounts found\n")
+	if !printShownAccounts(inventory, 0) {
 		return nil
-	} else {
-		fmt.Printf("Showing %d validator accounts\n", len(validatingPubKeys))
 	}
-	for i := 0; i < len(validatingPubKeys); i++ {
+	for _, account := range inventory.Accounts {
 		fmt.Println("")
-		fmt.Printf(
-			"%s\n", au.BrightGreen(petnames.DeterministicName(validatingPubKeys[i][:], "-")).Bold(),
-		)
+		fmt.Printf("%s\n", au.BrightGreen(account.Name).Bold())
 		// Retrieve the validating key account metadata.
-		fmt.Printf("%s %#x\n", au.BrightCyan("[validating public key]").Bold(), validatingPubKeys[i])
+		fmt.Printf("%s %s\n", au.BrightCyan("[validating public key]").Bold(), account.PublicKey)
+		if account.CreatedAt != "" {
+			fmt.Printf("%s %s\n", au.BrightCyan("[synced]").Bold(), account.CreatedAt)
+		}
+		printAccountLabels(account.Labels)
+		printAccountLastSigned(account)
 		fmt.Println(" ")
 	}
 	return nil
 }
+
+// Prints how many accounts a listing shows, and which of them if it is paginated, returning
+// whether it shows any.
+func printShownAccounts(inventory *accountInventory, offset int) bool {
+	numShown := len(inventory.Accounts)
+	switch {
+	case numShown == 0:
+		fmt.Print("No accounts found\n")
+		return false
+	case inventory.Pagination != nil:
+		page := inventory.Pagination
+		fmt.Printf(
+			"Showing validator accounts %d to %d of %d, page %d of %d\n",
+			offset+1,
+			offset+numShown,
+			page.TotalAccounts,
+			page.Page,
+			page.TotalPages,
+		)
+	case numShown == 1:
+		fmt.Print("Showing 1 validator account\n")
+	default:
+		fmt.Printf("Showing %d validator accounts\n", numShown)
+	}
+	return true
+}
+
+func printAccountLabels(labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+	fmt.Printf("%s %s\n", aurora.NewAurora(true).BrightYellow("[labels]").Bold(), formatLabels(labels))
+}
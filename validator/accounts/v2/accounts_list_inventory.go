@@ -0,0 +1,237 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/petnames"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/derived"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/remote"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// The human readable listing of accounts.
+	textListFormat = "text"
+	// A public key inventory for monitoring systems and configuration management.
+	jsonListFormat = "json"
+	yamlListFormat = "yaml"
+)
+
+// accountInventory is the machine readable listing of the accounts of a wallet.
+type accountInventory struct {
+	KeymanagerKind string              `json:"keymanager_kind" yaml:"keymanager_kind"`
+	Accounts       []*inventoryAccount `json:"accounts" yaml:"accounts"`
+	Pagination     *inventoryPage      `json:"pagination,omitempty" yaml:"pagination,omitempty"`
+}
+
+// inventoryAccount describes an account of a wallet in an account inventory. The creation time
+// is only known for accounts of non-HD wallets and synced accounts of remote wallets, the notes
+// for accounts of non-HD wallets, and the derivation path for HD wallets.
+type inventoryAccount struct {
+	Name           string            `json:"name" yaml:"name"`
+	PublicKey      string            `json:"public_key" yaml:"public_key"`
+	CreatedAt      string            `json:"created_at,omitempty" yaml:"created_at,omitempty"`
+	DerivationPath string            `json:"derivation_path,omitempty" yaml:"derivation_path,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Notes          string            `json:"notes,omitempty" yaml:"notes,omitempty"`
+	LastSigned     *lastSigned       `json:"last_signed,omitempty" yaml:"last_signed,omitempty"`
+	// createdAt is the creation time at its full precision, to sort accounts created within the
+	// same second.
+	createdAt time.Time
+}
+
+// Checks a list output format is one of the supported ones.
+func validateListFormat(format string) error {
+	switch format {
+	case "", textListFormat, jsonListFormat, yamlListFormat:
+		return nil
+	default:
+		return fmt.Errorf(
+			"unknown output format %q, expected one of %s, %s, %s",
+			format,
+			textListFormat,
+			jsonListFormat,
+			yamlListFormat,
+		)
+	}
+}
+
+// Builds the inventory of the accounts of a wallet from its initialized keymanager.
+func inventoryAccounts(
+	ctx context.Context,
+	wallet *Wallet,
+	keymanager v2keymanager.IKeymanager,
+) (*accountInventory, error) {
+	inventory := &accountInventory{
+		KeymanagerKind: wallet.KeymanagerKind().String(),
+		Accounts:       make([]*inventoryAccount, 0),
+	}
+	switch km := keymanager.(type) {
+	case *direct.Keymanager:
+		accounts, err := wallet.directInventoryAccounts(ctx)
+		if err != nil {
+			return nil, err
+		}
+		inventory.Accounts = accounts
+	case *derived.Keymanager:
+		accountNames, err := km.ValidatingAccountNames(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not fetch account names")
+		}
+		// Account names are in derivation order, the public keys of the keymanager are not.
+		indices := km.AccountIndices()
+		for i, name := range accountNames {
+			pubKey, err := km.PublicKeyForAccount(name)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not get public key for account: %s", name)
+			}
+			inventory.Accounts = append(inventory.Accounts, &inventoryAccount{
+				Name:           name,
+				PublicKey:      fmt.Sprintf("%#x", pubKey),
+				DerivationPath: fmt.Sprintf(derived.ValidatingKeyDerivationPathTemplate, indices[i]),
+			})
+		}
+	case *remote.Keymanager:
+		pubKeys, err := km.FetchValidatingPublicKeys(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not fetch validating public keys")
+		}
+		synced, err := wallet.readRemoteAccounts()
+		if err != nil {
+			return nil, err
+		}
+		syncedAccounts := make(map[string]*remoteAccount, len(synced.Accounts))
+		for _, account := range synced.Accounts {
+			syncedAccounts[account.PublicKey] = account
+		}
+		for _, pubKey := range pubKeys {
+			account := &inventoryAccount{
+				Name:      petnames.DeterministicName(pubKey[:], "-"),
+				PublicKey: fmt.Sprintf("%#x", pubKey),
+			}
+			// Accounts synced with accounts-v2 sync-remote keep their registered name and time.
+			if remoteAccount, ok := syncedAccounts[account.PublicKey]; ok {
+				account.Name = remoteAccount.Name
+				account.createdAt = time.Unix(remoteAccount.AddedAt, 0)
+				account.CreatedAt = account.createdAt.UTC().Format(time.RFC3339)
+			}
+			inventory.Accounts = append(inventory.Accounts, account)
+		}
+	default:
+		return nil, fmt.Errorf("keymanager kind %s not yet supported", wallet.KeymanagerKind().String())
+	}
+	if err := wallet.labelInventory(inventory); err != nil {
+		return nil, err
+	}
+	return inventory, nil
+}
+
+// Builds the inventory of the accounts of a non-HD wallet from the public keys and file names of
+// their keystores, which unlike initializing its keymanager does not decrypt any of them.
+func directInventory(ctx context.Context, wallet *Wallet) (*accountInventory, error) {
+	accounts, err := wallet.directInventoryAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	inventory := &accountInventory{
+		KeymanagerKind: wallet.KeymanagerKind().String(),
+		Accounts:       accounts,
+	}
+	if err := wallet.labelInventory(inventory); err != nil {
+		return nil, err
+	}
+	return inventory, nil
+}
+
+func (w *Wallet) directInventoryAccounts(ctx context.Context) ([]*inventoryAccount, error) {
+	accountNames, err := w.ListDirs()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch account names")
+	}
+	accounts := make([]*inventoryAccount, 0, len(accountNames))
+	for _, name := range accountNames {
+		encoded, err := w.ReadFileAtPath(ctx, name, w.keystoreFileGlob())
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read keystore of account %s", name)
+		}
+		pubKey, err := keystorePubKey(encoded)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read public key of account %s", name)
+		}
+		metadata, err := w.readAccountMetadata(name)
+		if err != nil {
+			return nil, err
+		}
+		createdAt, err := w.accountCreationTime(ctx, name, metadata)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, &inventoryAccount{
+			Name:      name,
+			PublicKey: fmt.Sprintf("%#x", pubKey),
+			CreatedAt: createdAt.UTC().Format(time.RFC3339),
+			Notes:     metadata.Notes,
+			createdAt: createdAt,
+		})
+	}
+	return accounts, nil
+}
+
+func (w *Wallet) labelInventory(inventory *accountInventory) error {
+	labels, err := w.readAccountLabels()
+	if err != nil {
+		return err
+	}
+	for _, account := range inventory.Accounts {
+		account.Labels = labels.Accounts[account.PublicKey]
+	}
+	return nil
+}
+
+// Keeps the accounts of the inventory the label filter allows.
+func (inv *accountInventory) filter(labelFilter *accountLabelFilter) error {
+	if !labelFilter.active() {
+		return nil
+	}
+	kept := make([]*inventoryAccount, 0, len(inv.Accounts))
+	for _, account := range inv.Accounts {
+		pubKey, err := parsePubKey(account.PublicKey)
+		if err != nil {
+			return errors.Wrapf(err, "invalid public key of account %s", account.Name)
+		}
+		if labelFilter.allows(pubKey) {
+			kept = append(kept, account)
+		}
+	}
+	inv.Accounts = kept
+	return nil
+}
+
+// Writes a listing of accounts, such as an account inventory, to out in the json or yaml format.
+func writeStructuredOutput(out io.Writer, listing interface{}, format string) error {
+	var enc []byte
+	var err error
+	switch format {
+	case jsonListFormat:
+		enc, err = json.MarshalIndent(listing, "", "  ")
+		enc = append(enc, '\n')
+	case yamlListFormat:
+		enc, err = yaml.Marshal(listing)
+	default:
+		return fmt.Errorf("accounts cannot be written in the %s format", format)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "could not encode accounts as %s", format)
+	}
+	if _, err := out.Write(enc); err != nil {
+		return errors.Wrap(err, "could not write accounts")
+	}
+	return nil
+}
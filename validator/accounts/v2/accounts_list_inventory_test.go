@@ -0,0 +1,64 @@
+package v2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"gopkg.in/yaml.v2"
+)
+
+func TestListAccounts_Inventory(t *testing.T) {
+	walletDir, passwordsDir, _ := setupWalletAndPasswordsDir(t)
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:      walletDir,
+		passwordsDir:   passwordsDir,
+		keymanagerKind: v2keymanager.Direct,
+	})
+	wallet, err := NewWallet(cliCtx, v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+
+	numAccounts := 3
+	want := make(map[string]string, numAccounts)
+	for i := 0; i < numAccounts; i++ {
+		accountName, err := keymanager.CreateAccount(ctx, "hello world")
+		require.NoError(t, err)
+		pubKey, err := keymanager.PublicKeyForAccount(accountName)
+		require.NoError(t, err)
+		want[accountName] = fmt.Sprintf("%#x", pubKey)
+	}
+	inventory, err := inventoryAccounts(ctx, wallet, keymanager)
+	require.NoError(t, err)
+	assert.Equal(t, v2keymanager.Direct.String(), inventory.KeymanagerKind)
+	require.Equal(t, numAccounts, len(inventory.Accounts))
+	for _, account := range inventory.Accounts {
+		assert.Equal(t, want[account.Name], account.PublicKey)
+		_, err := time.Parse(time.RFC3339, account.CreatedAt)
+		assert.NoError(t, err)
+	}
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, writeStructuredOutput(buf, inventory, jsonListFormat))
+	fromJSON := &accountInventory{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), fromJSON))
+	assert.DeepEqual(t, inventory, fromJSON)
+
+	buf.Reset()
+	require.NoError(t, writeStructuredOutput(buf, inventory, yamlListFormat))
+	fromYAML := &accountInventory{}
+	require.NoError(t, yaml.Unmarshal(buf.Bytes(), fromYAML))
+	assert.DeepEqual(t, inventory, fromYAML)
+
+	assert.ErrorContains(t, "unknown output format", validateListFormat("csv"))
+}
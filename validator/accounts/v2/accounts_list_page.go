@@ -0,0 +1,127 @@
+package v2
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// Accounts are listed in the order of the keymanager, the derivation order for HD wallets.
+	defaultListSort = ""
+	nameListSort    = "name"
+	createdListSort = "created"
+	pubKeyListSort  = "pubkey"
+)
+
+// accountListOptions select the page of accounts a listing shows and the order they are shown in.
+type accountListOptions struct {
+	sortBy    string
+	page      int
+	pageSize  int
+	countOnly bool
+}
+
+// inventoryPage describes the page of accounts an account inventory holds.
+type inventoryPage struct {
+	Page          int `json:"page" yaml:"page"`
+	PageSize      int `json:"page_size" yaml:"page_size"`
+	TotalPages    int `json:"total_pages" yaml:"total_pages"`
+	TotalAccounts int `json:"total_accounts" yaml:"total_accounts"`
+}
+
+// Reads the --sort, --page, --page-size and --count options of a listing.
+func listOptionsFromCli(cliCtx *cli.Context) (*accountListOptions, error) {
+	opts := &accountListOptions{
+		sortBy:    cliCtx.String(flags.ListSortFlag.Name),
+		page:      cliCtx.Int(flags.ListPageFlag.Name),
+		pageSize:  cliCtx.Int(flags.ListPageSizeFlag.Name),
+		countOnly: cliCtx.Bool(flags.ListCountFlag.Name),
+	}
+	switch opts.sortBy {
+	case defaultListSort, nameListSort, createdListSort, pubKeyListSort:
+	default:
+		return nil, fmt.Errorf(
+			"unknown sort order %q, expected one of %s, %s, %s",
+			opts.sortBy,
+			nameListSort,
+			createdListSort,
+			pubKeyListSort,
+		)
+	}
+	if opts.pageSize < 0 {
+		return nil, errors.New("page size cannot be negative")
+	}
+	if opts.page < 1 {
+		return nil, errors.New("pages are numbered from 1")
+	}
+	return opts, nil
+}
+
+// Sorts the accounts of the inventory. Accounts of HD wallets are created in derivation order, so
+// sorting them by creation time keeps the derivation order of the keymanager.
+func (inv *accountInventory) sort(sortBy string) error {
+	var less func(a, b *inventoryAccount) bool
+	switch sortBy {
+	case defaultListSort:
+		return nil
+	case nameListSort:
+		less = func(a, b *inventoryAccount) bool {
+			return a.Name < b.Name
+		}
+	case pubKeyListSort:
+		less = func(a, b *inventoryAccount) bool {
+			return a.PublicKey < b.PublicKey
+		}
+	case createdListSort:
+		switch inv.KeymanagerKind {
+		case v2keymanager.Derived.String():
+			return nil
+		case v2keymanager.Direct.String():
+		default:
+			return fmt.Errorf("creation times of accounts are not known for %s wallets", inv.KeymanagerKind)
+		}
+		less = func(a, b *inventoryAccount) bool {
+			if a.createdAt.Equal(b.createdAt) {
+				return a.Name < b.Name
+			}
+			return a.createdAt.Before(b.createdAt)
+		}
+	default:
+		return fmt.Errorf("unknown sort order %q", sortBy)
+	}
+	sort.SliceStable(inv.Accounts, func(i, j int) bool {
+		return less(inv.Accounts[i], inv.Accounts[j])
+	})
+	return nil
+}
+
+// Keeps the given page of the accounts of the inventory, returning the position of its first
+// account among all accounts. A page size of 0 keeps every account on a single page.
+func (inv *accountInventory) paginate(page, pageSize int) (int, error) {
+	if pageSize == 0 {
+		return 0, nil
+	}
+	total := len(inv.Accounts)
+	totalPages := (total + pageSize - 1) / pageSize
+	if page > totalPages && !(page == 1 && total == 0) {
+		return 0, fmt.Errorf("page %d is out of range, there are %d pages of %d accounts", page, totalPages, pageSize)
+	}
+	start := (page - 1) * pageSize
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+	inv.Accounts = inv.Accounts[start:end]
+	inv.Pagination = &inventoryPage{
+		Page:          page,
+		PageSize:      pageSize,
+		TotalPages:    totalPages,
+		TotalAccounts: total,
+	}
+	return start, nil
+}
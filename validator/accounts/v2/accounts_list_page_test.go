@@ -0,0 +1,82 @@
+package v2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestListAccounts_SortAndPaginate(t *testing.T) {
+	walletDir, passwordsDir, _ := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:      walletDir,
+		passwordsDir:   passwordsDir,
+		keymanagerKind: v2keymanager.Direct,
+		listSort:       nameListSort,
+		listPage:       2,
+		listPageSize:   2,
+	}
+	wallet, err := NewWallet(setupWalletCtx(t, cfg), v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	numAccounts := 5
+	for i := 0; i < numAccounts; i++ {
+		_, err := keymanager.CreateAccount(ctx, "hello world")
+		require.NoError(t, err)
+	}
+
+	listOpts, err := listOptionsFromCli(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	inventory, err := directInventory(ctx, wallet)
+	require.NoError(t, err)
+	require.NoError(t, inventory.sort(listOpts.sortBy))
+	sortedNames := make([]string, numAccounts)
+	for i, account := range inventory.Accounts {
+		sortedNames[i] = account.Name
+	}
+	for i := 1; i < numAccounts; i++ {
+		assert.Equal(t, true, sortedNames[i-1] <= sortedNames[i], "Expected accounts sorted by name")
+	}
+
+	offset, err := inventory.paginate(listOpts.page, listOpts.pageSize)
+	require.NoError(t, err)
+	assert.Equal(t, 2, offset)
+	require.Equal(t, 2, len(inventory.Accounts))
+	assert.Equal(t, sortedNames[2], inventory.Accounts[0].Name)
+	assert.Equal(t, sortedNames[3], inventory.Accounts[1].Name)
+	assert.DeepEqual(t, &inventoryPage{Page: 2, PageSize: 2, TotalPages: 3, TotalAccounts: 5}, inventory.Pagination)
+
+	// The last page holds the remaining accounts, and there is no page after it.
+	inventory, err = directInventory(ctx, wallet)
+	require.NoError(t, err)
+	offset, err = inventory.paginate(3, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 4, offset)
+	assert.Equal(t, 1, len(inventory.Accounts))
+	_, err = inventory.paginate(4, 2)
+	assert.ErrorContains(t, "out of range", err)
+
+	inventory, err = directInventory(ctx, wallet)
+	require.NoError(t, err)
+	require.NoError(t, inventory.sort(createdListSort))
+	for i := 1; i < numAccounts; i++ {
+		assert.Equal(t, true, inventory.Accounts[i-1].CreatedAt <= inventory.Accounts[i].CreatedAt, "Expected accounts sorted by creation time")
+	}
+	inventory.KeymanagerKind = v2keymanager.Remote.String()
+	assert.ErrorContains(t, "creation times of accounts are not known", inventory.sort(createdListSort))
+
+	cfg.listSort = "size"
+	_, err = listOptionsFromCli(setupWalletCtx(t, cfg))
+	assert.ErrorContains(t, "unknown sort order", err)
+	cfg.listSort = ""
+	cfg.listPage = 0
+	_, err = listOptionsFromCli(setupWalletCtx(t, cfg))
+	assert.ErrorContains(t, "numbered from 1", err)
+}
@@ -11,8 +11,6 @@ import (
 	"time"
 
 	"github.com/dustin/go-humanize"
-	validatorpb "github.com/prysmaticlabs/prysm/proto/validator/accounts/v2"
-	"github.com/prysmaticlabs/prysm/shared/bls"
 	"github.com/prysmaticlabs/prysm/shared/bytesutil"
 	"github.com/prysmaticlabs/prysm/shared/petnames"
 	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
@@ -23,18 +21,6 @@ import (
 	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/remote"
 )
 
-type mockKeymanager struct {
-	publicKeys [][48]byte
-}
-
-func (m *mockKeymanager) FetchValidatingPublicKeys(ctx context.Context) ([][48]byte, error) {
-	return m.publicKeys, nil
-}
-
-func (m *mockKeymanager) Sign(context.Context, *validatorpb.SignRequest) (bls.Signature, error) {
-	return nil, nil
-}
-
 func TestListAccounts_DirectKeymanager(t *testing.T) {
 	walletDir, passwordsDir, _ := setupWalletAndPasswordsDir(t)
 	cliCtx := setupWalletCtx(t, &testWalletConfig{
@@ -74,7 +60,9 @@ func TestListAccounts_DirectKeymanager(t *testing.T) {
 	os.Stdout = w
 
 	// We call the list direct keymanager accounts function.
-	require.NoError(t, listDirectKeymanagerAccounts(true /* show deposit data */, wallet, keymanager))
+	inventory, err := directInventory(ctx, wallet)
+	require.NoError(t, err)
+	require.NoError(t, listDirectKeymanagerAccounts(true /* show deposit data */, wallet, inventory, 0 /* offset */))
 
 	require.NoError(t, w.Close())
 	out, err := ioutil.ReadAll(r)
@@ -169,8 +157,10 @@ func TestListAccounts_DerivedKeymanager(t *testing.T) {
 	require.NoError(t, err)
 	os.Stdout = w
 
-	// We call the list direct keymanager accounts function.
-	require.NoError(t, listDerivedKeymanagerAccounts(true /* show deposit data */, wallet, keymanager))
+	// We call the list derived keymanager accounts function.
+	inventory, err := inventoryAccounts(ctx, wallet, keymanager)
+	require.NoError(t, err)
+	require.NoError(t, listDerivedKeymanagerAccounts(true /* show deposit data */, keymanager, inventory))
 
 	require.NoError(t, w.Close())
 	out, err := ioutil.ReadAll(r)
@@ -231,8 +221,12 @@ func TestListAccounts_RemoteKeymanager(t *testing.T) {
 		copy(key, strconv.Itoa(i))
 		pubKeys[i] = bytesutil.ToBytes48(key)
 	}
-	km := &mockKeymanager{
-		publicKeys: pubKeys,
+	inventory := &accountInventory{KeymanagerKind: v2keymanager.Remote.String()}
+	for _, pubKey := range pubKeys {
+		inventory.Accounts = append(inventory.Accounts, &inventoryAccount{
+			Name:      petnames.DeterministicName(pubKey[:], "-"),
+			PublicKey: fmt.Sprintf("%#x", pubKey),
+		})
 	}
 	// We call the list remote keymanager accounts function.
 	cfg := &remote.Config{
@@ -243,7 +237,7 @@ func TestListAccounts_RemoteKeymanager(t *testing.T) {
 		},
 		RemoteAddr: "localhost:4000",
 	}
-	require.NoError(t, listRemoteKeymanagerAccounts(wallet, km, cfg))
+	require.NoError(t, listRemoteKeymanagerAccounts(wallet, cfg, inventory))
 
 	require.NoError(t, w.Close())
 	out, err := ioutil.ReadAll(r)
@@ -0,0 +1,181 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/depositutil"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// accountMetadataFileName is the file in the directory of an account of a non-HD wallet
+	// holding metadata about the account, next to its keystore.
+	accountMetadataFileName = "account-metadata.json"
+	// accountMetadataVersion is the version of the metadata documents of accounts holding their
+	// creation time and deposit data. Documents without a version were written before, when
+	// those were only kept in separate files of the account directory.
+	accountMetadataVersion = 1
+)
+
+// accountMetadata is the metadata document of an account of a non-HD wallet. It is stored in the
+// directory of the account, so it follows the account when it is renamed or archived, and together
+// with the keystore it holds everything known about the account but its password, which is kept
+// in the passwords directory of the wallet.
+type accountMetadata struct {
+	Version         int                          `json:"version,omitempty"`
+	Notes           string                       `json:"notes,omitempty"`
+	CreatedAt       string                       `json:"created_at,omitempty"`
+	DepositData     *depositutil.DepositDataJSON `json:"deposit_data,omitempty"`
+	DepositDataRoot string                       `json:"deposit_data_root,omitempty"`
+	Deposit         *depositInclusion            `json:"deposit,omitempty"`
+}
+
+// ConsolidateAccountMetadata migrates the accounts of a non-HD wallet to versioned metadata
+// documents, gathering their creation time and deposit data from the separate files of their
+// directories, so copying an account keystore and its metadata document is enough to keep it.
+func ConsolidateAccountMetadata(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	wallet, err := openDirectWallet(cliCtx)
+	if err != nil {
+		return err
+	}
+	migrated, total, err := wallet.consolidateAllAccountMetadata(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf(
+		"Migrated the metadata of %s accounts, %d accounts were already up to date\n",
+		au.BrightMagenta(migrated),
+		total-migrated,
+	)
+	return nil
+}
+
+// Migrates the metadata of every account of a non-HD wallet which is not of the current version,
+// returning how many were migrated out of how many accounts.
+func (w *Wallet) consolidateAllAccountMetadata(ctx context.Context) (int, int, error) {
+	accounts, err := w.accountPubKeys(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	names, _ := sortedAccounts(accounts)
+	migrated := 0
+	for _, name := range names {
+		metadata, err := w.readAccountMetadata(name)
+		if err != nil {
+			return 0, 0, err
+		}
+		if metadata.Version >= accountMetadataVersion {
+			continue
+		}
+		if err := w.consolidateAccountMetadata(ctx, name, metadata); err != nil {
+			return 0, 0, err
+		}
+		migrated++
+	}
+	return migrated, len(names), nil
+}
+
+// Fills the metadata document of an account with the creation time and deposit data of the
+// separate files of its directory, and writes it at the current version. The deposit data files
+// are kept for the tools reading them, while the legacy creation time file is removed once its
+// timestamp is in the document.
+func (w *Wallet) consolidateAccountMetadata(ctx context.Context, accountName string, metadata *accountMetadata) error {
+	legacyTimestampPath := filepath.Join(w.AccountsDir(), accountName, direct.TimestampFileName)
+	hasLegacyTimestamp := fileExists(legacyTimestampPath)
+	if metadata.CreatedAt == "" {
+		createdAt, err := w.legacyAccountCreationTime(ctx, accountName, legacyTimestampPath, hasLegacyTimestamp)
+		if err != nil {
+			return err
+		}
+		metadata.CreatedAt = createdAt.UTC().Format(time.RFC3339Nano)
+	}
+	if metadata.DepositData == nil {
+		depositData, err := readDepositDataFiles(ctx, w, accountName)
+		if err != nil {
+			return errors.Wrapf(err, "could not read deposit data of account %s", accountName)
+		}
+		metadata.DepositData = depositData
+	}
+	if metadata.DepositData != nil && metadata.DepositDataRoot == "" {
+		metadata.DepositDataRoot = "0x" + metadata.DepositData.DepositDataRoot
+	}
+	metadata.Version = accountMetadataVersion
+	if err := w.writeAccountMetadata(ctx, accountName, metadata); err != nil {
+		return err
+	}
+	if hasLegacyTimestamp {
+		if err := w.files().removeFile(ctx, accountName+"/"+direct.TimestampFileName); err != nil {
+			return errors.Wrapf(err, "could not remove %s of account %s", direct.TimestampFileName, accountName)
+		}
+	}
+	return nil
+}
+
+// Reads the creation time of an account from its legacy creation time file, a unix timestamp, or
+// else from the name of its keystore file.
+func (w *Wallet) legacyAccountCreationTime(
+	ctx context.Context,
+	accountName string,
+	legacyTimestampPath string,
+	hasLegacyTimestamp bool,
+) (time.Time, error) {
+	if hasLegacyTimestamp {
+		encoded, err := ioutil.ReadFile(legacyTimestampPath)
+		if err != nil {
+			return time.Time{}, errors.Wrapf(err, "could not read %s of account %s", direct.TimestampFileName, accountName)
+		}
+		unixTimestamp, err := strconv.ParseInt(strings.TrimSpace(string(encoded)), 10, 64)
+		if err != nil {
+			return time.Time{}, errors.Wrapf(err, "invalid %s of account %s", direct.TimestampFileName, accountName)
+		}
+		return time.Unix(unixTimestamp, 0), nil
+	}
+	return w.accountCreationTime(ctx, accountName, &accountMetadata{})
+}
+
+// Reads the metadata of an account of a non-HD wallet, which is empty until it is first written.
+func (w *Wallet) readAccountMetadata(accountName string) (*accountMetadata, error) {
+	metadata := &accountMetadata{}
+	encoded, err := w.files().readFile(context.Background(), path.Join(accountName, accountMetadataFileName))
+	if os.IsNotExist(err) {
+		return metadata, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read metadata of account %s", accountName)
+	}
+	if err := json.Unmarshal(encoded, metadata); err != nil {
+		return nil, errors.Wrapf(err, "could not decode metadata of account %s", accountName)
+	}
+	if metadata.Version > accountMetadataVersion {
+		return nil, fmt.Errorf(
+			"metadata of account %s is of version %d, this version of Prysm only supports up to %d",
+			accountName,
+			metadata.Version,
+			accountMetadataVersion,
+		)
+	}
+	return metadata, nil
+}
+
+func (w *Wallet) writeAccountMetadata(ctx context.Context, accountName string, metadata *accountMetadata) error {
+	encoded, err := json.MarshalIndent(metadata, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "could not marshal account metadata")
+	}
+	if err := w.WriteFileAtPath(ctx, accountName, accountMetadataFileName, encoded); err != nil {
+		return errors.Wrapf(err, "could not write metadata of account %s", accountName)
+	}
+	return nil
+}
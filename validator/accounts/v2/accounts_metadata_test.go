@@ -0,0 +1,66 @@
+package v2
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestConsolidateAccountMetadata(t *testing.T) {
+	walletDir, passwordsDir, _ := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:      walletDir,
+		passwordsDir:   passwordsDir,
+		keymanagerKind: v2keymanager.Direct,
+	}
+	cliCtx := setupWalletCtx(t, cfg)
+	wallet, err := NewWallet(cliCtx, v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	name, err := keymanager.CreateAccount(ctx, "hello world")
+	require.NoError(t, err)
+	depositData, err := readDepositDataFiles(ctx, wallet, name)
+	require.NoError(t, err)
+	require.NotNil(t, depositData)
+
+	// Accounts of the legacy layout keep their creation time in created_at.txt.
+	require.NoError(t, wallet.WriteFileAtPath(ctx, name, direct.TimestampFileName, []byte("1600000000\n")))
+	require.NoError(t, wallet.writeAccountMetadata(ctx, name, &accountMetadata{Notes: "hello"}))
+	require.NoError(t, ConsolidateAccountMetadata(cliCtx))
+
+	metadata, err := wallet.readAccountMetadata(name)
+	require.NoError(t, err)
+	assert.Equal(t, accountMetadataVersion, metadata.Version)
+	assert.Equal(t, "hello", metadata.Notes)
+	assert.Equal(t, time.Unix(1600000000, 0).UTC().Format(time.RFC3339Nano), metadata.CreatedAt)
+	require.NotNil(t, metadata.DepositData)
+	assert.DeepEqual(t, depositData, metadata.DepositData)
+	assert.Equal(t, "0x"+depositData.DepositDataRoot, metadata.DepositDataRoot)
+	assert.Equal(t, false, fileExists(filepath.Join(wallet.AccountsDir(), name, direct.TimestampFileName)))
+
+	// Deposit data survives a partial copy of the account holding only its keystore and metadata.
+	for _, fileName := range []string{direct.DepositDataFileName, direct.DepositDataJSONFileName} {
+		require.NoError(t, os.Remove(filepath.Join(wallet.AccountsDir(), name, fileName)))
+	}
+	fromMetadata, err := directAccountDepositData(ctx, wallet, name)
+	require.NoError(t, err)
+	assert.DeepEqual(t, depositData, fromMetadata)
+
+	// Metadata of a newer version is refused rather than silently rewritten.
+	require.NoError(t, wallet.writeAccountMetadata(ctx, name, &accountMetadata{Version: accountMetadataVersion + 1}))
+	_, err = wallet.readAccountMetadata(name)
+	assert.ErrorContains(t, "only supports up to", err)
+}
@@ -0,0 +1,161 @@
+package v2
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	validatorpb "github.com/prysmaticlabs/prysm/proto/validator/accounts/v2"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/validator/db/kv"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v1 "github.com/prysmaticlabs/prysm/validator/keymanager/v1"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/urfave/cli/v2"
+)
+
+// migrationSigningRoot is signed by both the legacy and the migrated keys to
+// verify they hold the same secret.
+var migrationSigningRoot = [32]byte{'m', 'i', 'g', 'r', 'a', 't', 'e'}
+
+// MigrateFromV1 reads the validating keys from a legacy v1 keymanager, as selected by
+// the --keymanager and --keymanageropts flags, and stores them as EIP-2335 keystores in a
+// non-HD wallet. The legacy keys are only ever read, and every migrated account is verified
+// against its legacy key after being reloaded from the wallet on disk.
+func MigrateFromV1(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	legacyKeys, err := readV1SecretKeys(cliCtx)
+	if err != nil {
+		return err
+	}
+	if len(legacyKeys) == 0 {
+		return errors.New("no validating keys found in the v1 keymanager")
+	}
+	wallet, err := createOrOpenWallet(cliCtx, createDirectWallet)
+	if err != nil {
+		return errors.Wrap(err, "could not initialize wallet")
+	}
+	if wallet.KeymanagerKind() != v2keymanager.Direct {
+		return errors.New(
+			"only non-HD wallets can hold migrated accounts, try creating a new wallet with wallet-v2 create",
+		)
+	}
+	password, err := inputPassword(cliCtx, flags.AccountPasswordFileFlag, newAccountPasswordPromptText, confirmPass)
+	if err != nil {
+		return errors.Wrap(err, "could not input new account password")
+	}
+	existing, err := walletKeysFromCli(ctx, cliCtx, wallet)
+	if err != nil {
+		return err
+	}
+	defer existing.reportSkipped()
+	migratedKeys, err := wallet.importSecretKeys(ctx, legacyKeys, password, existing, nil /* filter */)
+	if err != nil {
+		return err
+	}
+	if err := verifyMigratedAccounts(ctx, wallet, legacyKeys); err != nil {
+		return errors.Wrap(err, "could not verify migrated accounts")
+	}
+	if err := verifySlashingProtectionHistory(ctx, cliCtx, migratedKeys); err != nil {
+		return errors.Wrap(err, "could not verify slashing protection history")
+	}
+	fmt.Printf(
+		"Successfully migrated %s accounts (%d already present), view all of them by running accounts-v2 list\n",
+		au.BrightMagenta(len(migratedKeys)),
+		len(legacyKeys)-len(migratedKeys),
+	)
+	return nil
+}
+
+// Reads the secret keys held by the v1 keymanager selected through the cli flags.
+func readV1SecretKeys(cliCtx *cli.Context) (map[[48]byte]bls.SecretKey, error) {
+	manager, opts, err := v1KeymanagerOpts(cliCtx)
+	if err != nil {
+		return nil, err
+	}
+	legacyKeymanager, help, err := v1.NewKeyManager(manager, opts)
+	if err != nil {
+		if help != "" {
+			fmt.Println(help)
+		}
+		return nil, errors.Wrapf(err, "could not open %s keymanager", manager)
+	}
+	exporter, ok := legacyKeymanager.(v1.SecretKeyExporter)
+	if !ok {
+		return nil, fmt.Errorf("keys held by the %s keymanager cannot be migrated", manager)
+	}
+	return exporter.SecretKeys()
+}
+
+// Returns the v1 keymanager and its options as selected through the --keymanager and
+// --keymanageropts flags, reading the options from a file if they are not inline JSON.
+func v1KeymanagerOpts(cliCtx *cli.Context) (string, string, error) {
+	manager := strings.ToLower(cliCtx.String(flags.KeyManager.Name))
+	if manager == "" {
+		return "", "", errors.New("the --keymanager flag is required to select the v1 keymanager to migrate from")
+	}
+	opts := cliCtx.String(flags.KeyManagerOpts.Name)
+	if opts == "" {
+		opts = "{}"
+	} else if !strings.HasPrefix(opts, "{") {
+		fileOpts, err := ioutil.ReadFile(opts)
+		if err != nil {
+			return "", "", errors.Wrap(err, "could not read keymanager options file")
+		}
+		opts = string(fileOpts)
+	}
+	return manager, opts, nil
+}
+
+// Reloads the wallet's keystores from disk and checks every legacy key signs
+// identically to its migrated counterpart.
+func verifyMigratedAccounts(ctx context.Context, wallet *Wallet, legacyKeys map[[48]byte]bls.SecretKey) error {
+	keymanager, err := wallet.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+	if err != nil {
+		return errors.Wrap(err, "could not reload keymanager")
+	}
+	for pubKey, secretKey := range legacyKeys {
+		sig, err := keymanager.Sign(ctx, &validatorpb.SignRequest{
+			PublicKey:   pubKey[:],
+			SigningRoot: migrationSigningRoot[:],
+		})
+		if err != nil {
+			return errors.Wrapf(err, "could not sign with migrated key %#x", bytesutil.Trunc(pubKey[:]))
+		}
+		if !bytes.Equal(sig.Marshal(), secretKey.Sign(migrationSigningRoot[:]).Marshal()) {
+			return fmt.Errorf("migrated key %#x does not match its v1 key", bytesutil.Trunc(pubKey[:]))
+		}
+	}
+	return nil
+}
+
+// Slashing protection history is keyed by public key in the validator database, so the
+// migrated accounts keep using it as long as the validator runs with the same --datadir.
+// We only read it here to make sure it is intact.
+func verifySlashingProtectionHistory(ctx context.Context, cliCtx *cli.Context, pubKeys [][48]byte) error {
+	dataDir := validatorDataDir(cliCtx)
+	store, err := kv.GetKVStore(dataDir)
+	if err != nil {
+		return errors.Wrap(err, "could not open validator database")
+	}
+	if store == nil {
+		log.WithField("datadir", dataDir).Warn("No validator database found, no slashing protection history to keep")
+		return nil
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			log.WithError(err).Error("Could not close validator database")
+		}
+	}()
+	if _, err := store.AttestationHistoryForPubKeys(ctx, pubKeys); err != nil {
+		return errors.Wrap(err, "could not read attestation history")
+	}
+	log.WithField("datadir", dataDir).Info(
+		"Slashing protection history is readable for the migrated accounts, keep using this --datadir with the validator",
+	)
+	return nil
+}
@@ -0,0 +1,62 @@
+package v2
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/interop"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	logTest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestMigrateFromV1_Interop(t *testing.T) {
+	hook := logTest.NewGlobal()
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	dataDir := filepath.Join(testutil.TempDir(), t.Name())
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dataDir), "Failed to remove directory")
+	})
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		accountPasswordFile: passwordFile,
+		dataDir:             dataDir,
+		keymanagerKind:      v2keymanager.Direct,
+		v1Keymanager:        "interop",
+		v1KeymanagerOpts:    `{"keys":3,"offset":0}`,
+	})
+	require.NoError(t, MigrateFromV1(cliCtx))
+	testutil.AssertLogsContain(t, hook, "No validator database found")
+
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	keymanager, err := wallet.InitializeKeymanager(ctx, true)
+	require.NoError(t, err)
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(pubKeys))
+
+	_, wantedPubKeys, err := interop.DeterministicallyGenerateKeys(0, 3)
+	require.NoError(t, err)
+	migrated := make(map[[48]byte]bool, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		migrated[pubKey] = true
+	}
+	for _, wanted := range wantedPubKeys {
+		assert.Equal(t, true, migrated[bytesutil.ToBytes48(wanted.Marshal())], "Missing migrated key %#x", wanted.Marshal())
+	}
+
+	// Running the migration again must not duplicate any accounts.
+	require.NoError(t, MigrateFromV1(cliCtx))
+	testutil.AssertLogsContain(t, hook, "Account already exists in wallet, skipping")
+	names, err := wallet.ListDirs()
+	require.NoError(t, err)
+	assert.Equal(t, 3, len(names))
+}
@@ -0,0 +1,70 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/urfave/cli/v2"
+)
+
+// NoteAccounts sets the --notes of the selected --accounts of a non-HD wallet, replacing their
+// previous notes. Empty notes remove the notes of the accounts.
+func NoteAccounts(cliCtx *cli.Context) error {
+	if !cliCtx.IsSet(flags.AccountNotesFlag.Name) {
+		return fmt.Errorf("no notes given with --%s", flags.AccountNotesFlag.Name)
+	}
+	notes := strings.TrimSpace(cliCtx.String(flags.AccountNotesFlag.Name))
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	if wallet.KeymanagerKind() != v2keymanager.Direct {
+		return errors.New("only accounts of non-HD wallets can have notes, use accounts-v2 label for other wallets")
+	}
+	accounts, err := wallet.accountPubKeys(ctx)
+	if err != nil {
+		return err
+	}
+	if len(accounts) == 0 {
+		return errors.New("wallet has no accounts")
+	}
+	names, pubKeys := sortedAccounts(accounts)
+	selected, err := selectAccounts(cliCtx, names, pubKeys)
+	if err != nil {
+		return errors.Wrap(err, "could not select accounts")
+	}
+	if len(selected) == 0 {
+		return errors.New("no accounts selected")
+	}
+	for _, name := range selected {
+		metadata, err := wallet.readAccountMetadata(name)
+		if err != nil {
+			return err
+		}
+		metadata.Notes = notes
+		if err := wallet.writeAccountMetadata(ctx, name, metadata); err != nil {
+			return err
+		}
+		if notes == "" {
+			fmt.Printf("Removed notes of account %s\n", au.BrightGreen(name).Bold())
+		} else {
+			fmt.Printf("%s %s\n", au.BrightGreen(name).Bold(), notes)
+		}
+	}
+	return nil
+}
+
+func printAccountNotes(notes string) {
+	if notes == "" {
+		return
+	}
+	fmt.Printf("%s %s\n", aurora.NewAurora(true).BrightYellow("[notes]").Bold(), notes)
+}
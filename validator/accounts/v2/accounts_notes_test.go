@@ -0,0 +1,59 @@
+package v2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestNoteAccounts(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFilePath,
+		keymanagerKind:     v2keymanager.Direct,
+	}
+	wallet, err := NewWallet(setupWalletCtx(t, cfg), v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	names := make([]string, 2)
+	for i := range names {
+		names[i], err = keymanager.CreateAccount(ctx, password)
+		require.NoError(t, err)
+	}
+	notesOf := func() map[string]string {
+		inventory, err := directInventory(ctx, wallet)
+		require.NoError(t, err)
+		notes := make(map[string]string)
+		for _, account := range inventory.Accounts {
+			notes[account.Name] = account.Notes
+		}
+		return notes
+	}
+
+	cfg.accountsToExport = names[0]
+	assert.ErrorContains(t, "no notes given", NoteAccounts(setupWalletCtx(t, cfg)))
+	cfg.accountNotes = "Acme Corp, deployment eu-west-1"
+	require.NoError(t, NoteAccounts(setupWalletCtx(t, cfg)))
+	assert.DeepEqual(t, map[string]string{names[0]: cfg.accountNotes, names[1]: ""}, notesOf())
+
+	// Notes follow the account when it is renamed.
+	require.NoError(t, wallet.renameAccount(names[0], "acme-0"))
+	assert.DeepEqual(t, map[string]string{"acme-0": cfg.accountNotes, names[1]: ""}, notesOf())
+
+	cfg.accountsToExport = "acme-0"
+	cfg.accountNotes = " "
+	require.NoError(t, NoteAccounts(setupWalletCtx(t, cfg)))
+	assert.DeepEqual(t, map[string]string{"acme-0": "", names[1]: ""}, notesOf())
+}
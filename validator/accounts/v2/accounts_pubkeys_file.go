@@ -0,0 +1,57 @@
+package v2
+
+import (
+	"fmt"
+
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/urfave/cli/v2"
+)
+
+// Reads the public keys listed in the --pubkeys-file, one 0x-prefixed validating public key per
+// line.
+func readPubKeysFile(cliCtx *cli.Context) ([][48]byte, error) {
+	filePath := cliCtx.String(flags.PubKeysFileFlag.Name)
+	pubKeys, err := readPubKeyFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if len(pubKeys) == 0 {
+		return nil, fmt.Errorf("public key file %s lists no public keys", filePath)
+	}
+	return pubKeys, nil
+}
+
+// Selects the accounts of the public keys listed in the --pubkeys-file, in the order of the file.
+// Every public key of the file must belong to one of the given accounts, so a typo never leaves a
+// validator out of a bulk operation unnoticed.
+func selectAccountsFromFile(cliCtx *cli.Context, accounts []string, pubKeys [][48]byte) ([]string, error) {
+	if cliCtx.IsSet(flags.AccountsFlag.Name) {
+		return nil, fmt.Errorf("only one of --%s and --%s can be given", flags.AccountsFlag.Name, flags.PubKeysFileFlag.Name)
+	}
+	listed, err := readPubKeysFile(cliCtx)
+	if err != nil {
+		return nil, err
+	}
+	byPubKey := make(map[[48]byte]string, len(accounts))
+	for i, name := range accounts {
+		byPubKey[pubKeys[i]] = name
+	}
+	selected := make([]string, 0, len(listed))
+	var missing [][48]byte
+	for _, pubKey := range listed {
+		name, ok := byPubKey[pubKey]
+		if !ok {
+			missing = append(missing, pubKey)
+			continue
+		}
+		selected = append(selected, name)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf(
+			"%d public keys of the public keys file belong to no selectable account, such as %#x",
+			len(missing),
+			missing[0],
+		)
+	}
+	return selected, nil
+}
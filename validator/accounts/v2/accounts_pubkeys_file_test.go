@@ -0,0 +1,93 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestSelectAccountsFromFile(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	pubKeysFile := filepath.Join(filepath.Dir(passwordFilePath), "pubkeys.txt")
+	cfg := &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFilePath,
+		pubKeysFile:        pubKeysFile,
+	}
+	names := []string{"first", "second", "third"}
+	pubKeys := make([][48]byte, len(names))
+	for i := range pubKeys {
+		copy(pubKeys[i][:], bls.RandKey().PublicKey().Marshal())
+	}
+
+	contents := fmt.Sprintf("# validators handed off to acme\n%#x\n\n  %#x  \n%#x\n", pubKeys[2], pubKeys[0], pubKeys[2])
+	require.NoError(t, ioutil.WriteFile(pubKeysFile, []byte(contents), os.ModePerm))
+	selected, err := selectAccounts(setupWalletCtx(t, cfg), names, pubKeys)
+	require.NoError(t, err)
+	assert.DeepEqual(t, []string{"third", "first"}, selected)
+
+	// Every listed public key must belong to an account.
+	unknown := bls.RandKey().PublicKey().Marshal()
+	contents = fmt.Sprintf("%#x\n%#x\n", pubKeys[1], unknown)
+	require.NoError(t, ioutil.WriteFile(pubKeysFile, []byte(contents), os.ModePerm))
+	_, err = selectAccounts(setupWalletCtx(t, cfg), names, pubKeys)
+	assert.ErrorContains(t, "1 public keys of the public keys file belong to no selectable account", err)
+
+	require.NoError(t, ioutil.WriteFile(pubKeysFile, []byte("0xabcd\n"), os.ModePerm))
+	_, err = selectAccounts(setupWalletCtx(t, cfg), names, pubKeys)
+	assert.ErrorContains(t, "invalid entry on line 1", err)
+
+	require.NoError(t, ioutil.WriteFile(pubKeysFile, []byte("# nothing\n"), os.ModePerm))
+	_, err = selectAccounts(setupWalletCtx(t, cfg), names, pubKeys)
+	assert.ErrorContains(t, "lists no public keys", err)
+
+	cfg.accountsToExport = "first"
+	_, err = selectAccounts(setupWalletCtx(t, cfg), names, pubKeys)
+	assert.ErrorContains(t, "only one of", err)
+}
+
+func TestDeleteAccount_PubKeysFile(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	pubKeysFile := filepath.Join(filepath.Dir(passwordFilePath), "pubkeys.txt")
+	cfg := &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFilePath,
+		keymanagerKind:     v2keymanager.Direct,
+	}
+	wallet, err := NewWallet(setupWalletCtx(t, cfg), v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	names := make([]string, 3)
+	pubKeys := make([][48]byte, len(names))
+	for i := range names {
+		names[i], err = keymanager.CreateAccount(ctx, password)
+		require.NoError(t, err)
+		pubKeys[i], err = keymanager.PublicKeyForAccount(names[i])
+		require.NoError(t, err)
+	}
+
+	contents := fmt.Sprintf("%#x\n%#x\n", pubKeys[0], pubKeys[2])
+	require.NoError(t, ioutil.WriteFile(pubKeysFile, []byte(contents), os.ModePerm))
+	cfg.pubKeysFile = pubKeysFile
+	require.NoError(t, DeleteAccount(setupWalletCtx(t, cfg)))
+	accounts, err := wallet.accountPubKeys(ctx)
+	require.NoError(t, err)
+	assert.DeepEqual(t, map[string][48]byte{names[1]: pubKeys[1]}, accounts)
+}
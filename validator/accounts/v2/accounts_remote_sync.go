@@ -0,0 +1,243 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/petnames"
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/remote"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// remoteAccountsFileName is the file of a remote wallet mirroring the keys of its signer.
+	remoteAccountsFileName = "remote-accounts.json"
+	// web3SignerPublicKeysPath is the endpoint of the Web3Signer API listing its eth2 keys.
+	web3SignerPublicKeysPath = "/api/v1/eth2/publicKeys"
+	// A signer key list is a few hundred bytes per key, anything larger is not a key list.
+	maxSignerKeyListSize = 64 << 20
+)
+
+// remoteSignerClient queries the key list of a Web3Signer, it is replaced in tests.
+var remoteSignerClient = &http.Client{Timeout: 30 * time.Second}
+
+// remoteAccounts is the local account list of a remote wallet, mirroring the keys its remote
+// signer holds as of the last sync. Times are unix timestamps.
+type remoteAccounts struct {
+	Source   string           `json:"source"`
+	SyncedAt int64            `json:"synced_at"`
+	Accounts []*remoteAccount `json:"accounts"`
+}
+
+// remoteAccount is a key of the remote signer registered as an account of the wallet.
+type remoteAccount struct {
+	Name      string `json:"name"`
+	PublicKey string `json:"public_key"`
+	AddedAt   int64  `json:"added_at"`
+}
+
+// SyncRemoteAccounts queries the remote signer of a remote wallet for its key list, from the
+// configured remote signer or from the Web3Signer at --web3signer-url, and registers the keys
+// as the accounts of the wallet. Keys the signer no longer holds are removed from the accounts.
+// Once synced, the remote keymanager only validates with the keys registered as accounts.
+func SyncRemoteAccounts(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	if wallet.KeymanagerKind() != v2keymanager.Remote {
+		return errors.New("only remote wallets can sync their accounts with a remote signer")
+	}
+	var source string
+	var pubKeys [][48]byte
+	if signerURL := cliCtx.String(flags.Web3SignerURLFlag.Name); signerURL != "" {
+		source = signerURL
+		pubKeys, err = fetchWeb3SignerPublicKeys(ctx, signerURL)
+		if err != nil {
+			return err
+		}
+	} else {
+		keymanager, err := wallet.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+		if err != nil {
+			return errors.Wrap(err, "could not initialize keymanager")
+		}
+		km, ok := keymanager.(*remote.Keymanager)
+		if !ok {
+			return errors.New("could not assert keymanager interface to concrete type")
+		}
+		source = km.Config().RemoteAddr
+		pubKeys, err = km.FetchSignerPublicKeys(ctx)
+		if err != nil {
+			return errors.Wrap(err, "could not fetch validating public keys")
+		}
+	}
+	added, removed, err := wallet.syncRemoteAccounts(ctx, source, pubKeys)
+	if err != nil {
+		return err
+	}
+	log.WithFields(logrus.Fields{
+		"signer":   source,
+		"accounts": len(pubKeys),
+		"added":    added,
+		"removed":  removed,
+	}).Info("Synced wallet accounts with remote signer")
+	return nil
+}
+
+// Registers the public keys as the accounts of the wallet, keeping the name and the time each
+// key was first seen for keys which were already registered. It returns the number of accounts
+// added and removed.
+func (w *Wallet) syncRemoteAccounts(ctx context.Context, source string, pubKeys [][48]byte) (int, int, error) {
+	current, err := w.readRemoteAccounts()
+	if err != nil {
+		return 0, 0, err
+	}
+	previous := make(map[string]*remoteAccount, len(current.Accounts))
+	for _, account := range current.Accounts {
+		previous[account.PublicKey] = account
+	}
+	now := roughtime.Now().Unix()
+	synced := &remoteAccounts{
+		Source:   source,
+		SyncedAt: now,
+		Accounts: make([]*remoteAccount, 0, len(pubKeys)),
+	}
+	seen := make(map[string]bool, len(pubKeys))
+	added := 0
+	for _, pubKey := range pubKeys {
+		pubKeyHex := fmt.Sprintf("%#x", pubKey)
+		if seen[pubKeyHex] {
+			continue
+		}
+		seen[pubKeyHex] = true
+		if account, ok := previous[pubKeyHex]; ok {
+			synced.Accounts = append(synced.Accounts, account)
+			continue
+		}
+		account := &remoteAccount{
+			Name:      petnames.DeterministicName(pubKey[:], "-"),
+			PublicKey: pubKeyHex,
+			AddedAt:   now,
+		}
+		log.WithFields(logrus.Fields{
+			"name":      account.Name,
+			"publicKey": fmt.Sprintf("%#x", bytesutil.Trunc(pubKey[:])),
+		}).Info("Registered remote signer key as account")
+		synced.Accounts = append(synced.Accounts, account)
+		added++
+	}
+	removed := 0
+	for _, account := range current.Accounts {
+		if seen[account.PublicKey] {
+			continue
+		}
+		log.WithFields(logrus.Fields{
+			"name":      account.Name,
+			"publicKey": account.PublicKey,
+		}).Warn("Remote signer no longer holds key, removed its account")
+		removed++
+	}
+	sort.Slice(synced.Accounts, func(i, j int) bool {
+		return synced.Accounts[i].Name < synced.Accounts[j].Name
+	})
+	encoded, err := json.MarshalIndent(synced, "", "\t")
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "could not marshal remote accounts")
+	}
+	if err := w.WriteFileAtPath(ctx, "" /* accounts dir */, remoteAccountsFileName, encoded); err != nil {
+		return 0, 0, errors.Wrap(err, "could not write remote accounts")
+	}
+	return added, removed, nil
+}
+
+// Reads the local account list of a remote wallet, which is empty before the first sync.
+func (w *Wallet) readRemoteAccounts() (*remoteAccounts, error) {
+	encoded, err := w.files().readFile(context.Background(), remoteAccountsFileName)
+	if os.IsNotExist(err) {
+		return &remoteAccounts{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read remote accounts")
+	}
+	accounts := &remoteAccounts{}
+	if err := json.Unmarshal(encoded, accounts); err != nil {
+		return nil, errors.Wrap(err, "could not decode remote accounts")
+	}
+	return accounts, nil
+}
+
+// Reads the names of the accounts of a remote wallet by public key, which is empty before the
+// first sync.
+func (w *Wallet) remoteAccountsByPubKey() (map[[48]byte]string, error) {
+	synced, err := w.readRemoteAccounts()
+	if err != nil {
+		return nil, err
+	}
+	accounts := make(map[[48]byte]string, len(synced.Accounts))
+	for _, account := range synced.Accounts {
+		pubKey, err := parsePubKey(account.PublicKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid public key of remote account %s", account.Name)
+		}
+		accounts[pubKey] = account.Name
+	}
+	return accounts, nil
+}
+
+// Lists the eth2 keys held by the Web3Signer at the given base url.
+func fetchWeb3SignerPublicKeys(ctx context.Context, signerURL string) ([][48]byte, error) {
+	baseURL, err := url.Parse(signerURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse web3signer url")
+	}
+	if baseURL.Scheme != "http" && baseURL.Scheme != "https" {
+		return nil, fmt.Errorf("web3signer url must be http or https, received %q", baseURL.Scheme)
+	}
+	baseURL.Path = path.Join(baseURL.Path, web3SignerPublicKeysPath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create request")
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := remoteSignerClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not query web3signer keys")
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.WithError(err).Error("Could not close response body")
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not query web3signer keys: %s", resp.Status)
+	}
+	var keys []string
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxSignerKeyListSize)).Decode(&keys); err != nil {
+		return nil, errors.Wrap(err, "could not decode web3signer key list")
+	}
+	pubKeys := make([][48]byte, len(keys))
+	for i, key := range keys {
+		pubKeys[i], err = parsePubKey(key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "web3signer returned invalid public key %q", key)
+		}
+	}
+	return pubKeys, nil
+}
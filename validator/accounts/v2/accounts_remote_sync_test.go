@@ -0,0 +1,96 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/petnames"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+)
+
+func TestSyncRemoteAccounts(t *testing.T) {
+	walletDir, passwordsDir, _ := setupWalletAndPasswordsDir(t)
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:      walletDir,
+		passwordsDir:   passwordsDir,
+		keymanagerKind: v2keymanager.Remote,
+	})
+	wallet, err := NewWallet(cliCtx, v2keymanager.Remote)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+
+	pubKeys := make([][48]byte, 3)
+	for i := range pubKeys {
+		pubKeys[i] = bytesutil.ToBytes48(bls.RandKey().PublicKey().Marshal())
+	}
+	added, removed, err := wallet.syncRemoteAccounts(ctx, "localhost:4000", pubKeys[:2])
+	require.NoError(t, err)
+	assert.Equal(t, 2, added)
+	assert.Equal(t, 0, removed)
+	first, err := wallet.readRemoteAccounts()
+	require.NoError(t, err)
+	require.Equal(t, 2, len(first.Accounts))
+
+	// The signer dropped a key and gained another since the last sync.
+	added, removed, err = wallet.syncRemoteAccounts(ctx, "localhost:4000", pubKeys[1:])
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+	assert.Equal(t, 1, removed)
+	synced, err := wallet.readRemoteAccounts()
+	require.NoError(t, err)
+	require.Equal(t, 2, len(synced.Accounts))
+	registered := make(map[string]*remoteAccount)
+	for _, account := range synced.Accounts {
+		registered[account.PublicKey] = account
+	}
+	_, ok := registered[fmt.Sprintf("%#x", pubKeys[0])]
+	assert.Equal(t, false, ok, "Expected the dropped key to be removed")
+	for _, pubKey := range pubKeys[1:] {
+		account, ok := registered[fmt.Sprintf("%#x", pubKey)]
+		require.Equal(t, true, ok)
+		assert.Equal(t, petnames.DeterministicName(pubKey[:], "-"), account.Name)
+	}
+
+	// The remote keymanager is given the synced accounts by public key.
+	accounts, err := wallet.remoteAccountsByPubKey()
+	require.NoError(t, err)
+	require.Equal(t, 2, len(accounts))
+	for _, pubKey := range pubKeys[1:] {
+		assert.Equal(t, petnames.DeterministicName(pubKey[:], "-"), accounts[pubKey])
+	}
+}
+
+func TestFetchWeb3SignerPublicKeys(t *testing.T) {
+	pubKeys := [][48]byte{
+		bytesutil.ToBytes48(bls.RandKey().PublicKey().Marshal()),
+		bytesutil.ToBytes48(bls.RandKey().PublicKey().Marshal()),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != web3SignerPublicKeysPath {
+			http.NotFound(w, r)
+			return
+		}
+		keys := make([]string, len(pubKeys))
+		for i, pubKey := range pubKeys {
+			keys[i] = fmt.Sprintf("%#x", pubKey)
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(keys))
+	}))
+	defer srv.Close()
+
+	fetched, err := fetchWeb3SignerPublicKeys(context.Background(), srv.URL)
+	require.NoError(t, err)
+	assert.DeepEqual(t, pubKeys, fetched)
+
+	_, err = fetchWeb3SignerPublicKeys(context.Background(), srv.URL+"/missing")
+	assert.ErrorContains(t, "404", err)
+}
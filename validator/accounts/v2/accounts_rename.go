@@ -0,0 +1,201 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/urfave/cli/v2"
+)
+
+// RenameAccount renames an account of a non-HD wallet, given as `rename <old> <new>`. The account
+// directory and its password file are renamed, leaving its keystore and deposit data untouched,
+// except for the keystore of a wallet with a master password, which is re-encrypted with the
+// password derived with the new name, and encrypted artifacts, which are bound to their path.
+func RenameAccount(cliCtx *cli.Context) error {
+	if cliCtx.NArg() != 2 {
+		return errors.New("expected the current and the new name of the account, as rename <old> <new>")
+	}
+	oldName, newName := cliCtx.Args().Get(0), cliCtx.Args().Get(1)
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	if wallet.KeymanagerKind() != v2keymanager.Direct {
+		return errors.New("only accounts of non-HD wallets can be renamed")
+	}
+	if err := wallet.renameAccount(oldName, newName); err != nil {
+		return err
+	}
+	fmt.Printf("Renamed account %s to %s\n", au.BrightGreen(oldName).Bold(), au.BrightGreen(newName).Bold())
+	return nil
+}
+
+// Renames the directory and the password file of an account, failing if either is already taken
+// by another account.
+func (w *Wallet) renameAccount(oldName, newName string) error {
+	if err := w.checkWritable(); err != nil {
+		return err
+	}
+	if err := w.checkOnDisk("renaming accounts"); err != nil {
+		return err
+	}
+	if err := validateAccountName(newName); err != nil {
+		return err
+	}
+	if oldName == newName {
+		return fmt.Errorf("account is already named %s", newName)
+	}
+	oldPath := filepath.Join(w.accountsPath, oldName)
+	ok, err := hasDir(oldPath)
+	if err != nil {
+		return errors.Wrapf(err, "could not read account directory %s", oldPath)
+	}
+	if !ok || validateAccountName(oldName) != nil {
+		return fmt.Errorf("account %s not found in wallet", oldName)
+	}
+	newPath := filepath.Join(w.accountsPath, newName)
+	if _, err := os.Stat(newPath); !os.IsNotExist(err) {
+		return fmt.Errorf("an account named %s already exists in the wallet", newName)
+	}
+	oldPasswordPath := filepath.Join(w.passwordsDir, oldName+direct.PasswordFileSuffix)
+	newPasswordPath := filepath.Join(w.passwordsDir, newName+direct.PasswordFileSuffix)
+	hasPassword := fileExists(oldPasswordPath)
+	if hasPassword {
+		if _, err := os.Stat(newPasswordPath); !os.IsNotExist(err) {
+			return fmt.Errorf("a password file for an account named %s already exists in %s", newName, w.passwordsDir)
+		}
+	}
+	oldPasswordFileName := filepath.Base(oldPasswordPath)
+	newPasswordFileName := filepath.Base(newPasswordPath)
+	_, hasStoredPassword := w.passwords[oldPasswordFileName]
+	hasStoredPassword = hasStoredPassword && w.passwordStore
+	if _, ok := w.passwords[newPasswordFileName]; ok && hasStoredPassword {
+		return fmt.Errorf("the password store already holds a password for an account named %s", newName)
+	}
+	// Encrypted artifacts are bound to their path, so those of the account are encrypted again
+	// for its new name.
+	artifacts, err := w.reencryptRenamedArtifacts(oldName, newName)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return errors.Wrapf(err, "could not rename account %s", oldName)
+	}
+	if err := replaceWalletFiles(artifacts); err != nil {
+		if err := os.Rename(newPath, oldPath); err != nil {
+			log.WithError(err).Errorf("Could not restore account %s, its files are in %s", oldName, newPath)
+		}
+		return errors.Wrapf(err, "could not re-encrypt artifacts of account %s", oldName)
+	}
+	// The password of an account is derived with its name, so its keystore is re-encrypted with
+	// the password derived with the new name.
+	if w.masterPasswordSalt != nil {
+		if err := w.reencryptRenamedAccount(oldName, newName); err != nil {
+			for _, artifact := range artifacts {
+				if err := restoreFile(artifact); err != nil {
+					log.WithError(err).Errorf("Could not restore %s", artifact.path)
+				}
+			}
+			if err := os.Rename(newPath, oldPath); err != nil {
+				log.WithError(err).Errorf("Could not restore account %s, its files are in %s", oldName, newPath)
+			}
+			return errors.Wrapf(err, "could not re-encrypt keystore of account %s", oldName)
+		}
+	}
+	if hasStoredPassword {
+		w.passwords[newPasswordFileName] = w.passwords[oldPasswordFileName]
+		delete(w.passwords, oldPasswordFileName)
+		if err := w.writePasswordStore(w.passwords, w.walletPassword); err != nil {
+			w.passwords[oldPasswordFileName] = w.passwords[newPasswordFileName]
+			delete(w.passwords, newPasswordFileName)
+			if err := os.Rename(newPath, oldPath); err != nil {
+				log.WithError(err).Errorf("Could not restore account %s, its files are in %s", oldName, newPath)
+			}
+			return errors.Wrapf(err, "could not rename password of account %s", oldName)
+		}
+	}
+	if hasPassword {
+		if err := os.Rename(oldPasswordPath, newPasswordPath); err != nil {
+			if err := os.Rename(newPath, oldPath); err != nil {
+				log.WithError(err).Errorf("Could not restore account %s, its files are in %s", oldName, newPath)
+			}
+			return errors.Wrapf(err, "could not rename password file of account %s", oldName)
+		}
+	}
+	return w.updateManifest(context.Background(), nil, []string{oldName}, []string{newName})
+}
+
+// Re-encrypts the keystore of a renamed account of a wallet with a master password from the
+// password derived with its old name to the one derived with its new name.
+func (w *Wallet) reencryptRenamedAccount(oldName, newName string) error {
+	oldPassword, err := deriveAccountPassword(w.walletPassword, w.masterPasswordSalt, oldName)
+	if err != nil {
+		return err
+	}
+	newPassword, err := deriveAccountPassword(w.walletPassword, w.masterPasswordSalt, newName)
+	if err != nil {
+		return err
+	}
+	file, err := w.reencryptAccountKeystore(context.Background(), newName, oldPassword, newPassword)
+	if err != nil {
+		return err
+	}
+	return replaceFile(file.path, file.data)
+}
+
+// Encrypts the encrypted artifacts of an account again for the new name of the account, in memory,
+// as the files they replace once the directory of the account is renamed.
+func (w *Wallet) reencryptRenamedArtifacts(oldName, newName string) ([]*reencryptedFile, error) {
+	if w.artifactKey == nil {
+		return nil, nil
+	}
+	ctx := context.Background()
+	storage := w.storageWithoutArtifactEncryption()
+	withdrawalKeystores, err := storage.glob(ctx, oldName, withdrawalKeystoreFilePattern)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not list withdrawal keystores of account %s", oldName)
+	}
+	fileNames := withdrawalKeystores
+	for fileName := range artifactFileNames {
+		fileNames = append(fileNames, fileName)
+	}
+	files := make([]*reencryptedFile, 0)
+	for _, fileName := range fileNames {
+		enc, err := storage.readFile(ctx, path.Join(oldName, fileName))
+		if err != nil || !isEncryptedArtifact(enc) {
+			continue
+		}
+		decrypted, err := decryptArtifact(w.artifactKey, path.Join(oldName, fileName), enc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not decrypt %s of account %s", fileName, oldName)
+		}
+		encrypted, err := encryptArtifact(w.artifactKey, path.Join(newName, fileName), decrypted)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not encrypt %s of account %s", fileName, oldName)
+		}
+		files = append(files, &reencryptedFile{
+			path: filepath.Join(w.accountsPath, newName, fileName), previous: enc, data: encrypted,
+		})
+	}
+	return files, nil
+}
+
+// Checks an account name can be used as the name of its directory in the wallet.
+func validateAccountName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("account name cannot be empty")
+	}
+	if name == "." || name == ".." || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("%q is not a valid account name, it cannot be a path or start with a dot", name)
+	}
+	return nil
+}
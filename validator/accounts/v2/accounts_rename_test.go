@@ -0,0 +1,85 @@
+package v2
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestRenameAccount(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFilePath,
+		keymanagerKind:     v2keymanager.Direct,
+	})
+	wallet, err := NewWallet(cliCtx, v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	names := make([]string, 2)
+	for i := range names {
+		names[i], err = keymanager.CreateAccount(ctx, password)
+		require.NoError(t, err)
+	}
+	pubKey, err := keymanager.PublicKeyForAccount(names[0])
+	require.NoError(t, err)
+
+	require.NoError(t, wallet.renameAccount(names[0], "staking-0"))
+	renamed, err := keymanager.PublicKeyForAccount("staking-0")
+	require.NoError(t, err)
+	assert.Equal(t, pubKey, renamed)
+	assert.Equal(t, true, fileExists(filepath.Join(wallet.AccountsDir(), "staking-0", direct.DepositDataFileName)))
+	assert.Equal(t, true, fileExists(filepath.Join(passwordsDir, "staking-0"+direct.PasswordFileSuffix)))
+	assert.Equal(t, false, fileExists(filepath.Join(passwordsDir, names[0]+direct.PasswordFileSuffix)))
+	accounts, err := wallet.accountPubKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(accounts))
+	_, ok := accounts[names[0]]
+	assert.Equal(t, false, ok)
+
+	tests := []struct {
+		name    string
+		oldName string
+		newName string
+		wantErr string
+	}{
+		{
+			name:    "collision",
+			oldName: "staking-0",
+			newName: names[1],
+			wantErr: "already exists in the wallet",
+		},
+		{
+			name:    "missing account",
+			oldName: names[0],
+			newName: "staking-1",
+			wantErr: "not found in wallet",
+		},
+		{
+			name:    "path",
+			oldName: "staking-0",
+			newName: "../staking-0",
+			wantErr: "is not a valid account name",
+		},
+		{
+			name:    "empty",
+			oldName: "staking-0",
+			newName: " ",
+			wantErr: "cannot be empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, tt.wantErr, wallet.renameAccount(tt.oldName, tt.newName))
+		})
+	}
+}
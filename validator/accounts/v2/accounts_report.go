@@ -0,0 +1,257 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+	"github.com/prysmaticlabs/prysm/shared/cmd"
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
+	"github.com/prysmaticlabs/prysm/validator/client"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/derived"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/urfave/cli/v2"
+	"google.golang.org/grpc"
+)
+
+const (
+	// reportBeaconNodeTimeout bounds connecting to the beacon node and querying deposit statuses.
+	reportBeaconNodeTimeout = 30 * time.Second
+	// depositStatusUnavailable is the deposit status of accounts when the beacon node could not
+	// be queried.
+	depositStatusUnavailable = "UNAVAILABLE"
+)
+
+// accountReport describes every account of a wallet: when it was created, whether its deposit
+// is visible on chain, and where its files are on disk.
+type accountReport struct {
+	WalletDir      string                `json:"wallet_dir" yaml:"wallet_dir"`
+	KeymanagerKind string                `json:"keymanager_kind" yaml:"keymanager_kind"`
+	GeneratedAt    string                `json:"generated_at" yaml:"generated_at"`
+	Accounts       []*accountReportEntry `json:"accounts" yaml:"accounts"`
+}
+
+// accountReportEntry is the report of a single account. The deposit status is the validator
+// status reported by the beacon node, whose deposit details are only set once it saw the deposit.
+type accountReportEntry struct {
+	*inventoryAccount      `yaml:",inline"`
+	DepositStatus          string            `json:"deposit_status" yaml:"deposit_status"`
+	DepositVisible         bool              `json:"deposit_visible" yaml:"deposit_visible"`
+	ValidatorIndex         *uint64           `json:"validator_index,omitempty" yaml:"validator_index,omitempty"`
+	Eth1DepositBlockNumber uint64            `json:"eth1_deposit_block_number,omitempty" yaml:"eth1_deposit_block_number,omitempty"`
+	DepositInclusionSlot   uint64            `json:"deposit_inclusion_slot,omitempty" yaml:"deposit_inclusion_slot,omitempty"`
+	Files                  map[string]string `json:"files,omitempty" yaml:"files,omitempty"`
+}
+
+// ReportAccounts writes a report of every account of a wallet to stdout, as text or in the json
+// or yaml format given by --output. Deposit statuses are queried from the beacon node at
+// --beacon-rpc-provider, and reported as unavailable if it cannot be reached.
+func ReportAccounts(cliCtx *cli.Context) error {
+	outputFormat := cliCtx.String(flags.ListOutputFlag.Name)
+	if err := validateListFormat(outputFormat); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	keymanager, err := wallet.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+	if err != nil {
+		return errors.Wrap(err, "could not initialize keymanager")
+	}
+	report, err := wallet.reportAccounts(ctx, keymanager)
+	if err != nil {
+		return err
+	}
+	if err := reportDepositStatuses(ctx, cliCtx, report); err != nil {
+		log.WithError(err).Warn("Could not query deposit statuses from the beacon node, they are reported as unavailable")
+	}
+	if outputFormat == jsonListFormat || outputFormat == yamlListFormat {
+		return writeStructuredOutput(os.Stdout, report, outputFormat)
+	}
+	printAccountReport(report)
+	return nil
+}
+
+// Builds the report of the accounts of a wallet, without their deposit statuses.
+func (w *Wallet) reportAccounts(ctx context.Context, keymanager v2keymanager.IKeymanager) (*accountReport, error) {
+	inventory, err := inventoryAccounts(ctx, w, keymanager)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not build account inventory")
+	}
+	report := &accountReport{
+		WalletDir:      w.walletDir,
+		KeymanagerKind: inventory.KeymanagerKind,
+		GeneratedAt:    roughtime.Now().UTC().Format(time.RFC3339),
+		Accounts:       make([]*accountReportEntry, len(inventory.Accounts)),
+	}
+	for i, account := range inventory.Accounts {
+		files, err := w.accountFiles(ctx, account.Name)
+		if err != nil {
+			return nil, err
+		}
+		report.Accounts[i] = &accountReportEntry{
+			inventoryAccount: account,
+			DepositStatus:    depositStatusUnavailable,
+			Files:            files,
+		}
+	}
+	return report, nil
+}
+
+// Lists the files on disk an account of the wallet is stored in, by kind of file.
+func (w *Wallet) accountFiles(ctx context.Context, accountName string) (map[string]string, error) {
+	files := make(map[string]string)
+	switch w.KeymanagerKind() {
+	case v2keymanager.Direct:
+		keystoreFileName, err := w.FileNameAtPath(ctx, accountName, w.keystoreFileGlob())
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not get keystore file name for account: %s", accountName)
+		}
+		files["keystore"] = filepath.Join(w.AccountsDir(), accountName, keystoreFileName)
+		passwordPath := filepath.Join(w.passwordsDir, accountName+direct.PasswordFileSuffix)
+		if fileExists(passwordPath) {
+			files["password"] = passwordPath
+		}
+		for kind, fileName := range map[string]string{
+			"deposit_data":        direct.DepositDataFileName,
+			"deposit_data_json":   direct.DepositDataJSONFileName,
+			"withdrawal_keystore": direct.WithdrawalKeystoreFileName,
+		} {
+			filePath := filepath.Join(w.AccountsDir(), accountName, fileName)
+			if fileExists(filePath) {
+				files[kind] = filePath
+			}
+		}
+	case v2keymanager.Derived:
+		files["seed"] = filepath.Join(w.AccountsDir(), derived.EncryptedSeedFileName)
+	case v2keymanager.Remote:
+		files["keymanager_config"] = filepath.Join(w.AccountsDir(), KeymanagerConfigFileName)
+	}
+	return files, nil
+}
+
+// Queries the beacon node for the validator status of every account of the report.
+func reportDepositStatuses(ctx context.Context, cliCtx *cli.Context, report *accountReport) error {
+	if len(report.Accounts) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, reportBeaconNodeTimeout)
+	defer cancel()
+	conn, err := dialBeaconNode(ctx, cliCtx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.WithError(err).Error("Could not close connection to beacon node")
+		}
+	}()
+	return report.addDepositStatuses(ctx, ethpb.NewBeaconNodeValidatorClient(conn))
+}
+
+// Dials the beacon node at --beacon-rpc-provider, blocking until connected or ctx is done.
+func dialBeaconNode(ctx context.Context, cliCtx *cli.Context) (*grpc.ClientConn, error) {
+	dialOpts := client.ConstructDialOptions(
+		cliCtx.Int(cmd.GrpcMaxCallRecvMsgSizeFlag.Name),
+		cliCtx.String(flags.CertFlag.Name),
+		strings.Split(cliCtx.String(flags.GrpcHeadersFlag.Name), ","),
+		cliCtx.Uint(flags.GrpcRetriesFlag.Name),
+		cliCtx.Duration(flags.GrpcRetryDelayFlag.Name),
+		grpc.WithBlock(),
+	)
+	if dialOpts == nil {
+		return nil, errors.New("could not construct gRPC dial options")
+	}
+	endpoint := cliCtx.String(flags.BeaconRPCProviderFlag.Name)
+	conn, err := grpc.DialContext(ctx, endpoint, dialOpts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not dial beacon node at %s", endpoint)
+	}
+	return conn, nil
+}
+
+// Sets the deposit status of every account of the report from the validator statuses of the
+// beacon node.
+func (r *accountReport) addDepositStatuses(ctx context.Context, beaconClient ethpb.BeaconNodeValidatorClient) error {
+	byPubKey := make(map[string]*accountReportEntry, len(r.Accounts))
+	pubKeys := make([][]byte, 0, len(r.Accounts))
+	for _, account := range r.Accounts {
+		pubKey, err := parsePubKey(account.PublicKey)
+		if err != nil {
+			return errors.Wrapf(err, "invalid public key of account %s", account.Name)
+		}
+		byPubKey[fmt.Sprintf("%#x", pubKey)] = account
+		pubKeys = append(pubKeys, pubKey[:])
+	}
+	resp, err := beaconClient.MultipleValidatorStatus(ctx, &ethpb.MultipleValidatorStatusRequest{PublicKeys: pubKeys})
+	if err != nil {
+		return errors.Wrap(err, "could not fetch validator statuses")
+	}
+	if len(resp.PublicKeys) != len(resp.Statuses) || len(resp.Indices) != len(resp.Statuses) {
+		return errors.New("beacon node returned a malformed validator status response")
+	}
+	for i, status := range resp.Statuses {
+		account, ok := byPubKey[fmt.Sprintf("%#x", resp.PublicKeys[i])]
+		if !ok || status == nil {
+			continue
+		}
+		account.DepositStatus = status.Status.String()
+		account.DepositVisible = status.Status != ethpb.ValidatorStatus_UNKNOWN_STATUS
+		account.Eth1DepositBlockNumber = status.Eth1DepositBlockNumber
+		account.DepositInclusionSlot = status.DepositInclusionSlot
+		// Validators only have an index once their deposit was processed into the beacon state.
+		switch status.Status {
+		case ethpb.ValidatorStatus_UNKNOWN_STATUS, ethpb.ValidatorStatus_DEPOSITED, ethpb.ValidatorStatus_INVALID:
+		default:
+			index := resp.Indices[i]
+			account.ValidatorIndex = &index
+		}
+	}
+	return nil
+}
+
+func printAccountReport(report *accountReport) {
+	fmt.Printf("%s %s\n", au.BrightMagenta("(wallet directory)").Bold(), report.WalletDir)
+	fmt.Printf("%s %s\n", au.BrightMagenta("(keymanager kind)").Bold(), report.KeymanagerKind)
+	fmt.Printf("%s %s\n", au.BrightMagenta("(generated at)").Bold(), report.GeneratedAt)
+	for _, account := range report.Accounts {
+		fmt.Println("")
+		fmt.Printf("%s %s\n", au.BrightGreen(account.Name).Bold(), account.PublicKey)
+		if account.CreatedAt != "" {
+			fmt.Printf("%s %s\n", au.BrightCyan("[created at]").Bold(), account.CreatedAt)
+		}
+		if account.DerivationPath != "" {
+			fmt.Printf("%s %s\n", au.BrightCyan("[derivation path]").Bold(), account.DerivationPath)
+		}
+		deposit := account.DepositStatus
+		if account.DepositVisible {
+			deposit = fmt.Sprintf(
+				"%s, deposit in eth1 block %d, included at slot %d",
+				account.DepositStatus,
+				account.Eth1DepositBlockNumber,
+				account.DepositInclusionSlot,
+			)
+		}
+		fmt.Printf("%s %s\n", au.BrightCyan("[deposit status]").Bold(), deposit)
+		if account.ValidatorIndex != nil {
+			fmt.Printf("%s %d\n", au.BrightCyan("[validator index]").Bold(), *account.ValidatorIndex)
+		}
+		for _, kind := range []string{"keystore", "password", "deposit_data", "deposit_data_json", "seed", "keymanager_config"} {
+			if filePath, ok := account.Files[kind]; ok {
+				fmt.Printf("%s %s\n", au.BrightCyan(fmt.Sprintf("(%s)", strings.Replace(kind, "_", " ", -1))), filePath)
+			}
+		}
+	}
+	fmt.Println("")
+}
@@ -0,0 +1,93 @@
+package v2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+	"github.com/prysmaticlabs/prysm/shared/mock"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestReportAccounts_DepositStatuses(t *testing.T) {
+	walletDir, passwordsDir, _ := setupWalletAndPasswordsDir(t)
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:      walletDir,
+		passwordsDir:   passwordsDir,
+		keymanagerKind: v2keymanager.Direct,
+	})
+	wallet, err := NewWallet(cliCtx, v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	pubKeys := make([][]byte, 3)
+	names := make(map[string]int, len(pubKeys))
+	for i := range pubKeys {
+		accountName, err := keymanager.CreateAccount(ctx, password)
+		require.NoError(t, err)
+		pubKey, err := keymanager.PublicKeyForAccount(accountName)
+		require.NoError(t, err)
+		pubKeys[i] = pubKey[:]
+		names[accountName] = i
+	}
+
+	report, err := wallet.reportAccounts(ctx, keymanager)
+	require.NoError(t, err)
+	require.Equal(t, len(pubKeys), len(report.Accounts))
+	for _, account := range report.Accounts {
+		assert.Equal(t, depositStatusUnavailable, account.DepositStatus)
+		keystoreDir := filepath.Join(wallet.AccountsDir(), account.Name)
+		assert.Equal(t, keystoreDir, filepath.Dir(account.Files["keystore"]))
+		assert.Equal(t, filepath.Join(keystoreDir, direct.DepositDataFileName), account.Files["deposit_data"])
+		assert.Equal(t, filepath.Join(passwordsDir, account.Name+direct.PasswordFileSuffix), account.Files["password"])
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	beaconClient := mock.NewMockBeaconNodeValidatorClient(ctrl)
+	beaconClient.EXPECT().MultipleValidatorStatus(
+		gomock.Any(),
+		gomock.Any(),
+	).Return(&ethpb.MultipleValidatorStatusResponse{
+		PublicKeys: pubKeys,
+		Statuses: []*ethpb.ValidatorStatusResponse{
+			{Status: ethpb.ValidatorStatus_ACTIVE, Eth1DepositBlockNumber: 100, DepositInclusionSlot: 10},
+			{Status: ethpb.ValidatorStatus_DEPOSITED, Eth1DepositBlockNumber: 200, DepositInclusionSlot: 20},
+			{Status: ethpb.ValidatorStatus_UNKNOWN_STATUS},
+		},
+		Indices: []uint64{7, 0, 0},
+	}, nil /*err*/)
+	require.NoError(t, report.addDepositStatuses(ctx, beaconClient))
+	for _, account := range report.Accounts {
+		switch names[account.Name] {
+		case 0:
+			assert.Equal(t, ethpb.ValidatorStatus_ACTIVE.String(), account.DepositStatus)
+			assert.Equal(t, true, account.DepositVisible)
+			assert.Equal(t, uint64(100), account.Eth1DepositBlockNumber)
+			require.NotNil(t, account.ValidatorIndex)
+			assert.Equal(t, uint64(7), *account.ValidatorIndex)
+		case 1:
+			assert.Equal(t, true, account.DepositVisible)
+			assert.Equal(t, uint64(20), account.DepositInclusionSlot)
+			assert.Equal(t, (*uint64)(nil), account.ValidatorIndex)
+		case 2:
+			assert.Equal(t, ethpb.ValidatorStatus_UNKNOWN_STATUS.String(), account.DepositStatus)
+			assert.Equal(t, false, account.DepositVisible)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, writeStructuredOutput(buf, report, jsonListFormat))
+	fromJSON := &accountReport{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), fromJSON))
+	assert.DeepEqual(t, report, fromJSON)
+}
@@ -0,0 +1,130 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-ssz"
+	"github.com/prysmaticlabs/prysm/beacon-chain/core/helpers"
+	p2ppb "github.com/prysmaticlabs/prysm/proto/beacon/p2p/v1"
+	validatorpb "github.com/prysmaticlabs/prysm/proto/validator/accounts/v2"
+	"github.com/prysmaticlabs/prysm/shared/hashutil"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/urfave/cli/v2"
+)
+
+// messageDomainType is the domain of messages signed with accounts-v2 sign-message. It is outside
+// of the domain types of the beacon chain, so a signed message can never be mistaken for a
+// block, an attestation or any other consensus message.
+var messageDomainType = [4]byte{0xff, 'm', 's', 'g'}
+
+// signedMessage is a message signed with a validating key, proving control of its public key.
+type signedMessage struct {
+	PublicKey   string `json:"public_key"`
+	Message     string `json:"message"`
+	Domain      string `json:"domain"`
+	SigningRoot string `json:"signing_root"`
+	Signature   string `json:"signature"`
+}
+
+// SignMessage signs the --message with the validating key of the selected account through the
+// keymanager of the wallet, so operators can prove control of its public key to staking pools or
+// exchanges. The signed message is printed as JSON.
+func SignMessage(cliCtx *cli.Context) error {
+	if !cliCtx.IsSet(flags.MessageFlag.Name) {
+		return fmt.Errorf("no message to sign given with --%s", flags.MessageFlag.Name)
+	}
+	message := cliCtx.String(flags.MessageFlag.Name)
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	keymanager, err := wallet.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+	if err != nil {
+		return errors.Wrap(err, "could not initialize keymanager")
+	}
+	inventory, err := inventoryAccounts(ctx, wallet, keymanager)
+	if err != nil {
+		return errors.Wrap(err, "could not build account inventory")
+	}
+	if len(inventory.Accounts) == 0 {
+		return errors.New("wallet has no accounts to sign with")
+	}
+	accountNames := make([]string, len(inventory.Accounts))
+	pubKeys := make([][48]byte, len(inventory.Accounts))
+	byName := make(map[string][48]byte, len(inventory.Accounts))
+	for i, account := range inventory.Accounts {
+		accountNames[i] = account.Name
+		pubKeys[i], err = parsePubKey(account.PublicKey)
+		if err != nil {
+			return errors.Wrapf(err, "invalid public key of account %s", account.Name)
+		}
+		byName[account.Name] = pubKeys[i]
+	}
+	selectedAccounts, err := selectAccounts(cliCtx, accountNames, pubKeys)
+	if err != nil {
+		return errors.Wrap(err, "could not select accounts")
+	}
+	if len(selectedAccounts) != 1 {
+		return fmt.Errorf("a message is signed with a single account, %d selected", len(selectedAccounts))
+	}
+	signed, err := signMessage(ctx, keymanager, byName[selectedAccounts[0]], message)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.MarshalIndent(signed, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "could not marshal signed message")
+	}
+	fmt.Println(string(encoded))
+	return nil
+}
+
+// Signs a message in the message domain with the validating key of a public key.
+func signMessage(
+	ctx context.Context,
+	keymanager v2keymanager.IKeymanager,
+	pubKey [48]byte,
+	message string,
+) (*signedMessage, error) {
+	domain, signingRoot, err := messageSigningRoot([]byte(message))
+	if err != nil {
+		return nil, err
+	}
+	sig, err := keymanager.Sign(ctx, &validatorpb.SignRequest{
+		PublicKey:   pubKey[:],
+		SigningRoot: signingRoot[:],
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not sign message")
+	}
+	return &signedMessage{
+		PublicKey:   fmt.Sprintf("%#x", pubKey),
+		Message:     message,
+		Domain:      fmt.Sprintf("%#x", domain),
+		SigningRoot: fmt.Sprintf("%#x", signingRoot),
+		Signature:   fmt.Sprintf("%#x", sig.Marshal()),
+	}, nil
+}
+
+// Computes the domain and the signing root of a message, the root of the SHA-256 hash of the
+// message in the message domain. The domain is computed with a zero fork version and genesis
+// validators root, so signed messages verify the same on every network.
+func messageSigningRoot(message []byte) ([]byte, [32]byte, error) {
+	domain, err := helpers.ComputeDomain(messageDomainType, make([]byte, 4), make([]byte, 32))
+	if err != nil {
+		return nil, [32]byte{}, errors.Wrap(err, "could not compute message domain")
+	}
+	messageRoot := hashutil.Hash(message)
+	signingRoot, err := ssz.HashTreeRoot(&p2ppb.SigningData{ObjectRoot: messageRoot[:], Domain: domain})
+	if err != nil {
+		return nil, [32]byte{}, errors.Wrap(err, "could not compute signing root")
+	}
+	return domain, signingRoot, nil
+}
@@ -0,0 +1,70 @@
+package v2
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestSignMessage(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFilePath,
+		keymanagerKind:     v2keymanager.Direct,
+	}
+	wallet, err := NewWallet(setupWalletCtx(t, cfg), v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	name, err := keymanager.CreateAccount(ctx, password)
+	require.NoError(t, err)
+	pubKey, err := keymanager.PublicKeyForAccount(name)
+	require.NoError(t, err)
+
+	message := "validator operated by acme, 2020-10-01"
+	signed, err := signMessage(ctx, keymanager, pubKey, message)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%#x", pubKey), signed.PublicKey)
+	assert.Equal(t, message, signed.Message)
+	_, signingRoot, err := messageSigningRoot([]byte(message))
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%#x", signingRoot), signed.SigningRoot)
+	rawSig, err := hex.DecodeString(strings.TrimPrefix(signed.Signature, "0x"))
+	require.NoError(t, err)
+	sig, err := bls.SignatureFromBytes(rawSig)
+	require.NoError(t, err)
+	blsPubKey, err := bls.PublicKeyFromBytes(pubKey[:])
+	require.NoError(t, err)
+	assert.Equal(t, true, sig.Verify(blsPubKey, signingRoot[:]))
+
+	// Messages are signed in a domain of their own.
+	domainType := fmt.Sprintf("%#x", messageDomainType)
+	assert.Equal(t, true, strings.HasPrefix(signed.Domain, domainType))
+	for _, consensusDomain := range [][4]byte{
+		params.BeaconConfig().DomainBeaconProposer,
+		params.BeaconConfig().DomainBeaconAttester,
+		params.BeaconConfig().DomainRandao,
+		params.BeaconConfig().DomainDeposit,
+		params.BeaconConfig().DomainVoluntaryExit,
+		params.BeaconConfig().DomainSelectionProof,
+		params.BeaconConfig().DomainAggregateAndProof,
+	} {
+		assert.NotEqual(t, messageDomainType, consensusDomain)
+	}
+}
@@ -0,0 +1,143 @@
+package v2
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/cmd"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/validator/db/kv"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/prysmaticlabs/prysm/validator/slashing-protection/interchange"
+	"github.com/urfave/cli/v2"
+)
+
+// Writes the slashing protection history of the exported public keys, read from the validator
+// database in --datadir, to the EIP-3076 interchange file given by --slashing-protection-file.
+func exportSlashingProtection(ctx context.Context, cliCtx *cli.Context, pubKeys [][48]byte) error {
+	filePath, err := expandPath(cliCtx.String(flags.SlashingProtectionFileFlag.Name))
+	if err != nil {
+		return errors.Wrap(err, "could not parse slashing protection file path")
+	}
+	if cliCtx.String(flags.GenesisValidatorsRootFlag.Name) == "" {
+		return fmt.Errorf("--%s is required to export slashing protection history", flags.GenesisValidatorsRootFlag.Name)
+	}
+	genesisValidatorsRoot, err := parseGenesisValidatorsRoot(cliCtx.String(flags.GenesisValidatorsRootFlag.Name))
+	if err != nil {
+		return err
+	}
+	dataDir := validatorDataDir(cliCtx)
+	store, err := kv.GetKVStore(dataDir)
+	if err != nil {
+		return errors.Wrap(err, "could not open validator database")
+	}
+	if store == nil {
+		return fmt.Errorf("no validator database found in %s to export slashing protection history from", dataDir)
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			log.WithError(err).Error("Could not close validator database")
+		}
+	}()
+	doc, err := interchange.ExportHistory(ctx, store, genesisValidatorsRoot, pubKeys)
+	if err != nil {
+		return errors.Wrap(err, "could not export slashing protection history")
+	}
+	if err := writeInterchangeFile(filePath, doc); err != nil {
+		return err
+	}
+	log.WithField("path", filePath).Infof("Exported slashing protection history of %d accounts", len(pubKeys))
+	return nil
+}
+
+func writeInterchangeFile(filePath string, doc *interchange.Interchange) error {
+	enc, err := json.MarshalIndent(doc, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "could not encode slashing protection history")
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), params.BeaconIoConfig().ReadWriteExecutePermissions); err != nil {
+		return errors.Wrap(err, "could not create slashing protection file directory")
+	}
+	if err := ioutil.WriteFile(filePath, enc, params.BeaconIoConfig().ReadWritePermissions); err != nil {
+		return errors.Wrap(err, "could not write slashing protection file")
+	}
+	return nil
+}
+
+// Merges the slashing protection history of the EIP-3076 interchange file given by
+// --slashing-protection-file into the validator database in --datadir. This is done before
+// any keystore is imported, so keys never land in the wallet without their history. Only the
+// history of the public keys the filter selects is merged.
+func importSlashingProtection(ctx context.Context, cliCtx *cli.Context, filter *pubKeyFilter) error {
+	filePath, err := expandPath(cliCtx.String(flags.SlashingProtectionFileFlag.Name))
+	if err != nil {
+		return errors.Wrap(err, "could not parse slashing protection file path")
+	}
+	enc, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return errors.Wrap(err, "could not read slashing protection file")
+	}
+	doc, err := interchange.ParseInterchange(enc)
+	if err != nil {
+		return err
+	}
+	if cliCtx.String(flags.GenesisValidatorsRootFlag.Name) != "" {
+		genesisValidatorsRoot, err := parseGenesisValidatorsRoot(cliCtx.String(flags.GenesisValidatorsRootFlag.Name))
+		if err != nil {
+			return err
+		}
+		if err := doc.CheckGenesisValidatorsRoot(genesisValidatorsRoot); err != nil {
+			return err
+		}
+	}
+	pubKeys, err := doc.PublicKeys()
+	if err != nil {
+		return err
+	}
+	pubKeys = filter.selected(pubKeys)
+	dataDir := validatorDataDir(cliCtx)
+	store, err := kv.NewKVStore(dataDir, pubKeys)
+	if err != nil {
+		return errors.Wrap(err, "could not open validator database")
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			log.WithError(err).Error("Could not close validator database")
+		}
+	}()
+	imported, err := interchange.ImportHistory(ctx, store, doc, pubKeys)
+	if err != nil {
+		return errors.Wrap(err, "could not import slashing protection history")
+	}
+	log.WithField("datadir", dataDir).Infof("Imported slashing protection history of %d accounts", len(imported))
+	return nil
+}
+
+// Returns the directory holding the validator database, as given by --datadir.
+func validatorDataDir(cliCtx *cli.Context) string {
+	dataDir := cliCtx.String(cmd.DataDirFlag.Name)
+	if dataDir == "" {
+		dataDir = cmd.DefaultDataDir()
+	}
+	return dataDir
+}
+
+func parseGenesisValidatorsRoot(s string) ([32]byte, error) {
+	var root [32]byte
+	enc, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return root, errors.Wrap(err, "could not decode genesis validators root")
+	}
+	if len(enc) != len(root) {
+		return root, fmt.Errorf("genesis validators root must be %d bytes, received %d", len(root), len(enc))
+	}
+	copy(root[:], enc)
+	return root, nil
+}
@@ -0,0 +1,82 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	slashpb "github.com/prysmaticlabs/prysm/proto/slashing"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	"github.com/prysmaticlabs/prysm/validator/db/kv"
+)
+
+func TestExportImportSlashingProtection(t *testing.T) {
+	ctx := context.Background()
+	baseDir := filepath.Join(testutil.TempDir(), t.Name())
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(baseDir), "Failed to remove directory")
+	})
+	sourceDataDir := filepath.Join(baseDir, "source")
+	targetDataDir := filepath.Join(baseDir, "target")
+	protectionFile := filepath.Join(baseDir, "backup", "slashing_protection.json")
+	genesisRoot := fmt.Sprintf("%#x", [32]byte{'g', 'e', 'n', 'e', 's', 'i', 's'})
+	pubKey := [48]byte{1, 2, 3}
+	wsPeriod := params.BeaconConfig().WeakSubjectivityPeriod
+
+	store, err := kv.NewKVStore(sourceDataDir, [][48]byte{pubKey})
+	require.NoError(t, err)
+	require.NoError(t, store.SaveAttestationHistoryForPubKeys(ctx, map[[48]byte]*slashpb.AttestationHistory{
+		pubKey: {
+			TargetToSource:     map[uint64]uint64{0: params.BeaconConfig().FarFutureEpoch, 1: 0, 2: 1},
+			LatestEpochWritten: 2,
+		},
+	}))
+	require.NoError(t, store.Close())
+
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		dataDir:            sourceDataDir,
+		slashingProtection: protectionFile,
+		genesisRoot:        genesisRoot,
+	})
+	require.NoError(t, exportSlashingProtection(ctx, cliCtx, [][48]byte{pubKey}))
+
+	// History for another network must not be imported.
+	cliCtx = setupWalletCtx(t, &testWalletConfig{
+		dataDir:            targetDataDir,
+		slashingProtection: protectionFile,
+		genesisRoot:        fmt.Sprintf("%#x", [32]byte{'o', 't', 'h', 'e', 'r'}),
+	})
+	assert.ErrorContains(t, "slashing protection history is for genesis validators root", importSlashingProtection(ctx, cliCtx, nil /* filter */))
+
+	cliCtx = setupWalletCtx(t, &testWalletConfig{
+		dataDir:            targetDataDir,
+		slashingProtection: protectionFile,
+		genesisRoot:        genesisRoot,
+	})
+	require.NoError(t, importSlashingProtection(ctx, cliCtx, nil /* filter */))
+	store, err = kv.GetKVStore(targetDataDir)
+	require.NoError(t, err)
+	require.NotNil(t, store)
+	defer func() {
+		require.NoError(t, store.Close())
+	}()
+	histories, err := store.AttestationHistoryForPubKeys(ctx, [][48]byte{pubKey})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), histories[pubKey].LatestEpochWritten)
+	assert.Equal(t, uint64(0), histories[pubKey].TargetToSource[1%wsPeriod])
+	assert.Equal(t, uint64(1), histories[pubKey].TargetToSource[2%wsPeriod])
+}
+
+func TestExportSlashingProtection_RequiresGenesisValidatorsRoot(t *testing.T) {
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		dataDir:            filepath.Join(testutil.TempDir(), t.Name()),
+		slashingProtection: filepath.Join(testutil.TempDir(), t.Name(), "slashing_protection.json"),
+	})
+	err := exportSlashingProtection(context.Background(), cliCtx, [][48]byte{{1}})
+	assert.ErrorContains(t, "--genesis-validators-root is required", err)
+}
@@ -0,0 +1,237 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// statusBeaconNodeTimeout bounds connecting to the beacon node and querying validators.
+	statusBeaconNodeTimeout = 2 * time.Minute
+	// statusBatchSize bounds the public keys queried from the beacon node at once, so every
+	// query fits a single page of results.
+	statusBatchSize = 100
+)
+
+// accountStatus is the state of the validator of an account on the beacon node. Epochs which are
+// not yet known, the far future epoch of the beacon state, are left out.
+type accountStatus struct {
+	Name              string  `json:"name" yaml:"name"`
+	PublicKey         string  `json:"public_key" yaml:"public_key"`
+	Status            string  `json:"status" yaml:"status"`
+	ValidatorIndex    *uint64 `json:"validator_index,omitempty" yaml:"validator_index,omitempty"`
+	Balance           *uint64 `json:"balance_gwei,omitempty" yaml:"balance_gwei,omitempty"`
+	EffectiveBalance  *uint64 `json:"effective_balance_gwei,omitempty" yaml:"effective_balance_gwei,omitempty"`
+	ActivationEpoch   *uint64 `json:"activation_epoch,omitempty" yaml:"activation_epoch,omitempty"`
+	ExitEpoch         *uint64 `json:"exit_epoch,omitempty" yaml:"exit_epoch,omitempty"`
+	WithdrawableEpoch *uint64 `json:"withdrawable_epoch,omitempty" yaml:"withdrawable_epoch,omitempty"`
+	Slashed           bool    `json:"slashed" yaml:"slashed"`
+	pubKey            [48]byte
+}
+
+// StatusAccounts queries the beacon node at --beacon-rpc-provider for the validator of every
+// account of a wallet, and writes their status, balance, activation and exit epochs and whether
+// they were slashed to stdout, as a table or in the json or yaml format given by --output.
+func StatusAccounts(cliCtx *cli.Context) error {
+	outputFormat := cliCtx.String(flags.ListOutputFlag.Name)
+	if err := validateListFormat(outputFormat); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	keymanager, err := wallet.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+	if err != nil {
+		return errors.Wrap(err, "could not initialize keymanager")
+	}
+	inventory, err := inventoryAccounts(ctx, wallet, keymanager)
+	if err != nil {
+		return errors.Wrap(err, "could not build account inventory")
+	}
+	if len(inventory.Accounts) == 0 {
+		return errors.New("wallet has no accounts")
+	}
+	statuses := make([]*accountStatus, len(inventory.Accounts))
+	for i, account := range inventory.Accounts {
+		pubKey, err := parsePubKey(account.PublicKey)
+		if err != nil {
+			return errors.Wrapf(err, "invalid public key of account %s", account.Name)
+		}
+		statuses[i] = &accountStatus{
+			Name:      account.Name,
+			PublicKey: account.PublicKey,
+			pubKey:    pubKey,
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, statusBeaconNodeTimeout)
+	defer cancel()
+	conn, err := dialBeaconNode(ctx, cliCtx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.WithError(err).Error("Could not close connection to beacon node")
+		}
+	}()
+	err = queryAccountStatuses(
+		ctx,
+		ethpb.NewBeaconNodeValidatorClient(conn),
+		ethpb.NewBeaconChainClient(conn),
+		statuses,
+	)
+	if err != nil {
+		return err
+	}
+	if outputFormat == jsonListFormat || outputFormat == yamlListFormat {
+		return writeStructuredOutput(os.Stdout, statuses, outputFormat)
+	}
+	return printAccountStatuses(statuses)
+}
+
+// Fills the statuses of the accounts from the beacon node, in batches of public keys.
+func queryAccountStatuses(
+	ctx context.Context,
+	validatorClient ethpb.BeaconNodeValidatorClient,
+	beaconClient ethpb.BeaconChainClient,
+	statuses []*accountStatus,
+) error {
+	byPubKey := make(map[[48]byte]*accountStatus, len(statuses))
+	for _, status := range statuses {
+		byPubKey[status.pubKey] = status
+	}
+	farFutureEpoch := params.BeaconConfig().FarFutureEpoch
+	knownEpoch := func(epoch uint64) *uint64 {
+		if epoch == farFutureEpoch {
+			return nil
+		}
+		return &epoch
+	}
+	for start := 0; start < len(statuses); start += statusBatchSize {
+		end := start + statusBatchSize
+		if end > len(statuses) {
+			end = len(statuses)
+		}
+		pubKeys := make([][]byte, 0, end-start)
+		for _, status := range statuses[start:end] {
+			pubKey := status.pubKey
+			pubKeys = append(pubKeys, pubKey[:])
+		}
+
+		statusResp, err := validatorClient.MultipleValidatorStatus(ctx, &ethpb.MultipleValidatorStatusRequest{
+			PublicKeys: pubKeys,
+		})
+		if err != nil {
+			return errors.Wrap(err, "could not fetch validator statuses")
+		}
+		if len(statusResp.PublicKeys) != len(statusResp.Statuses) {
+			return errors.New("beacon node returned a malformed validator status response")
+		}
+		for i, resp := range statusResp.Statuses {
+			status, ok := byPubKey[bytesutil.ToBytes48(statusResp.PublicKeys[i])]
+			if !ok || resp == nil {
+				continue
+			}
+			status.Status = resp.Status.String()
+		}
+
+		validatorsResp, err := beaconClient.ListValidators(ctx, &ethpb.ListValidatorsRequest{
+			PublicKeys: pubKeys,
+			PageSize:   int32(len(pubKeys)),
+		})
+		if err != nil {
+			return errors.Wrap(err, "could not list validators")
+		}
+		indices := make([]uint64, 0, len(validatorsResp.ValidatorList))
+		byIndex := make(map[uint64]*accountStatus, len(validatorsResp.ValidatorList))
+		for _, container := range validatorsResp.ValidatorList {
+			if container.Validator == nil {
+				continue
+			}
+			status, ok := byPubKey[bytesutil.ToBytes48(container.Validator.PublicKey)]
+			if !ok {
+				continue
+			}
+			index := container.Index
+			effectiveBalance := container.Validator.EffectiveBalance
+			status.ValidatorIndex = &index
+			status.EffectiveBalance = &effectiveBalance
+			status.ActivationEpoch = knownEpoch(container.Validator.ActivationEpoch)
+			status.ExitEpoch = knownEpoch(container.Validator.ExitEpoch)
+			status.WithdrawableEpoch = knownEpoch(container.Validator.WithdrawableEpoch)
+			status.Slashed = container.Validator.Slashed
+			indices = append(indices, index)
+			byIndex[index] = status
+		}
+		// Balances are queried by index, as the beacon node fails the whole query for public
+		// keys it does not know.
+		if len(indices) == 0 {
+			continue
+		}
+		balancesResp, err := beaconClient.ListValidatorBalances(ctx, &ethpb.ListValidatorBalancesRequest{
+			Indices:  indices,
+			PageSize: int32(len(indices)),
+		})
+		if err != nil {
+			return errors.Wrap(err, "could not list validator balances")
+		}
+		for _, balance := range balancesResp.Balances {
+			status, ok := byIndex[balance.Index]
+			if !ok {
+				continue
+			}
+			amount := balance.Balance
+			status.Balance = &amount
+		}
+	}
+	return nil
+}
+
+func printAccountStatuses(statuses []*accountStatus) error {
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "NAME\tPUBLIC KEY\tINDEX\tSTATUS\tBALANCE (ETH)\tACTIVATION EPOCH\tEXIT EPOCH\tSLASHED")
+	for _, status := range statuses {
+		fmt.Fprintf(
+			table,
+			"%s\t%s\t%s\t%s\t%s\t%s\t%s\t%t\n",
+			status.Name,
+			status.PublicKey,
+			optionalUint(status.ValidatorIndex),
+			status.Status,
+			gweiToEth(status.Balance),
+			optionalUint(status.ActivationEpoch),
+			optionalUint(status.ExitEpoch),
+			status.Slashed,
+		)
+	}
+	return table.Flush()
+}
+
+func optionalUint(v *uint64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%d", *v)
+}
+
+func gweiToEth(gwei *uint64) string {
+	if gwei == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%d.%09d", *gwei/1e9, *gwei%1e9)
+}
@@ -0,0 +1,109 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/mock"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+)
+
+func TestQueryAccountStatuses(t *testing.T) {
+	pubKeys := make([][]byte, 3)
+	statuses := make([]*accountStatus, len(pubKeys))
+	for i := range pubKeys {
+		pubKeys[i] = bls.RandKey().PublicKey().Marshal()
+		statuses[i] = &accountStatus{
+			Name:      fmt.Sprintf("account-%d", i),
+			PublicKey: fmt.Sprintf("%#x", pubKeys[i]),
+		}
+		copy(statuses[i].pubKey[:], pubKeys[i])
+	}
+	farFutureEpoch := params.BeaconConfig().FarFutureEpoch
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	validatorClient := mock.NewMockBeaconNodeValidatorClient(ctrl)
+	beaconClient := mock.NewMockBeaconChainClient(ctrl)
+	validatorClient.EXPECT().MultipleValidatorStatus(
+		gomock.Any(),
+		&ethpb.MultipleValidatorStatusRequest{PublicKeys: pubKeys},
+	).Return(&ethpb.MultipleValidatorStatusResponse{
+		PublicKeys: pubKeys,
+		Statuses: []*ethpb.ValidatorStatusResponse{
+			{Status: ethpb.ValidatorStatus_ACTIVE},
+			{Status: ethpb.ValidatorStatus_EXITED},
+			{Status: ethpb.ValidatorStatus_UNKNOWN_STATUS},
+		},
+		Indices: []uint64{4, 9, 0},
+	}, nil /*err*/)
+	// The beacon node does not know the validator of the last account.
+	beaconClient.EXPECT().ListValidators(
+		gomock.Any(),
+		gomock.Any(),
+	).Return(&ethpb.Validators{
+		ValidatorList: []*ethpb.Validators_ValidatorContainer{
+			{
+				Index: 4,
+				Validator: &ethpb.Validator{
+					PublicKey:         pubKeys[0],
+					EffectiveBalance:  32e9,
+					ActivationEpoch:   5,
+					ExitEpoch:         farFutureEpoch,
+					WithdrawableEpoch: farFutureEpoch,
+				},
+			},
+			{
+				Index: 9,
+				Validator: &ethpb.Validator{
+					PublicKey:         pubKeys[1],
+					EffectiveBalance:  31e9,
+					Slashed:           true,
+					ActivationEpoch:   2,
+					ExitEpoch:         40,
+					WithdrawableEpoch: 8232,
+				},
+			},
+		},
+	}, nil /*err*/)
+	beaconClient.EXPECT().ListValidatorBalances(
+		gomock.Any(),
+		&ethpb.ListValidatorBalancesRequest{Indices: []uint64{4, 9}, PageSize: 2},
+	).Return(&ethpb.ValidatorBalances{
+		Balances: []*ethpb.ValidatorBalances_Balance{
+			{PublicKey: pubKeys[0], Index: 4, Balance: 32000000123},
+			{PublicKey: pubKeys[1], Index: 9, Balance: 30500000000},
+		},
+	}, nil /*err*/)
+
+	require.NoError(t, queryAccountStatuses(context.Background(), validatorClient, beaconClient, statuses))
+
+	active := statuses[0]
+	assert.Equal(t, ethpb.ValidatorStatus_ACTIVE.String(), active.Status)
+	require.NotNil(t, active.ValidatorIndex)
+	assert.Equal(t, uint64(4), *active.ValidatorIndex)
+	require.NotNil(t, active.Balance)
+	assert.Equal(t, "32.000000123", gweiToEth(active.Balance))
+	require.NotNil(t, active.ActivationEpoch)
+	assert.Equal(t, uint64(5), *active.ActivationEpoch)
+	assert.Equal(t, (*uint64)(nil), active.ExitEpoch)
+	assert.Equal(t, false, active.Slashed)
+
+	slashed := statuses[1]
+	assert.Equal(t, ethpb.ValidatorStatus_EXITED.String(), slashed.Status)
+	require.NotNil(t, slashed.ExitEpoch)
+	assert.Equal(t, uint64(40), *slashed.ExitEpoch)
+	assert.Equal(t, "30.500000000", gweiToEth(slashed.Balance))
+	assert.Equal(t, true, slashed.Slashed)
+
+	unknown := statuses[2]
+	assert.Equal(t, ethpb.ValidatorStatus_UNKNOWN_STATUS.String(), unknown.Status)
+	assert.Equal(t, (*uint64)(nil), unknown.ValidatorIndex)
+	assert.Equal(t, "-", gweiToEth(unknown.Balance))
+}
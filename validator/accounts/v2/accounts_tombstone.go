@@ -0,0 +1,140 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/validator/db/kv"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/prysmaticlabs/prysm/validator/slashing-protection/interchange"
+	"github.com/urfave/cli/v2"
+)
+
+// tombstoneFileNameFormat names the EIP-3076 interchange file holding the slashing protection
+// history of a deleted account, by hex encoded public key.
+const tombstoneFileNameFormat = "%x.json"
+
+// Keeps the slashing protection history of accounts about to be deleted, read from the validator
+// database in --datadir, in the tombstones of the wallet as one EIP-3076 interchange file per
+// public key, and exports it to the --slashing-protection-file if given. The history of the
+// tombstones is merged back into the validator database whenever accounts are imported into the
+// wallet, so keys of deleted accounts never come back with a clean history.
+func (w *Wallet) tombstoneSlashingProtection(ctx context.Context, cliCtx *cli.Context, pubKeys [][48]byte) error {
+	dataDir := validatorDataDir(cliCtx)
+	store, err := kv.GetKVStore(dataDir)
+	if err != nil {
+		return errors.Wrap(err, "could not open validator database")
+	}
+	if store == nil {
+		log.WithField("datadir", dataDir).Warn(
+			"No validator database found, the deleted accounts have no slashing protection history to keep",
+		)
+		return nil
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			log.WithError(err).Error("Could not close validator database")
+		}
+	}()
+	if cliCtx.String(flags.GenesisValidatorsRootFlag.Name) == "" {
+		return fmt.Errorf(
+			"--%s is required to keep the slashing protection history of deleted accounts",
+			flags.GenesisValidatorsRootFlag.Name,
+		)
+	}
+	genesisValidatorsRoot, err := parseGenesisValidatorsRoot(cliCtx.String(flags.GenesisValidatorsRootFlag.Name))
+	if err != nil {
+		return err
+	}
+	for _, pubKey := range pubKeys {
+		doc, err := interchange.ExportHistory(ctx, store, genesisValidatorsRoot, [][48]byte{pubKey})
+		if err != nil {
+			return errors.Wrapf(err, "could not export slashing protection history of %#x", pubKey)
+		}
+		filePath := filepath.Join(w.AccountsDir(), tombstoneDirName, fmt.Sprintf(tombstoneFileNameFormat, pubKey))
+		if err := writeInterchangeFile(filePath, doc); err != nil {
+			return errors.Wrapf(err, "could not keep slashing protection history of %#x", pubKey)
+		}
+	}
+	if cliCtx.String(flags.SlashingProtectionFileFlag.Name) != "" {
+		filePath, err := expandPath(cliCtx.String(flags.SlashingProtectionFileFlag.Name))
+		if err != nil {
+			return errors.Wrap(err, "could not parse slashing protection file path")
+		}
+		doc, err := interchange.ExportHistory(ctx, store, genesisValidatorsRoot, pubKeys)
+		if err != nil {
+			return errors.Wrap(err, "could not export slashing protection history")
+		}
+		if err := writeInterchangeFile(filePath, doc); err != nil {
+			return err
+		}
+		log.WithField("path", filePath).Infof("Exported slashing protection history of %d accounts", len(pubKeys))
+	}
+	return nil
+}
+
+// Merges the slashing protection history kept in the tombstones of the wallet into the validator
+// database in --datadir. This is done before any keystore is imported, so an account deleted from
+// the wallet and imported again keeps the history it had before it was deleted.
+func (w *Wallet) restoreTombstones(ctx context.Context, cliCtx *cli.Context) error {
+	tombstoneDir := filepath.Join(w.AccountsDir(), tombstoneDirName)
+	entries, err := ioutil.ReadDir(tombstoneDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not list tombstones")
+	}
+	docs := make([]*interchange.Interchange, 0, len(entries))
+	var pubKeys [][48]byte
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		enc, err := ioutil.ReadFile(filepath.Join(tombstoneDir, entry.Name()))
+		if err != nil {
+			return errors.Wrapf(err, "could not read tombstone %s", entry.Name())
+		}
+		doc, err := interchange.ParseInterchange(enc)
+		if err != nil {
+			return errors.Wrapf(err, "could not parse tombstone %s", entry.Name())
+		}
+		keys, err := doc.PublicKeys()
+		if err != nil {
+			return errors.Wrapf(err, "could not parse tombstone %s", entry.Name())
+		}
+		docs = append(docs, doc)
+		pubKeys = append(pubKeys, keys...)
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+	dataDir := validatorDataDir(cliCtx)
+	store, err := kv.NewKVStore(dataDir, pubKeys)
+	if err != nil {
+		return errors.Wrap(err, "could not open validator database")
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			log.WithError(err).Error("Could not close validator database")
+		}
+	}()
+	for _, doc := range docs {
+		keys, err := doc.PublicKeys()
+		if err != nil {
+			return err
+		}
+		if _, err := interchange.ImportHistory(ctx, store, doc, keys); err != nil {
+			return errors.Wrap(err, "could not restore slashing protection history of deleted accounts")
+		}
+	}
+	log.WithField("datadir", dataDir).Infof(
+		"Restored slashing protection history of %d deleted accounts from the wallet", len(pubKeys),
+	)
+	return nil
+}
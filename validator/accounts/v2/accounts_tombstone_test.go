@@ -0,0 +1,80 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	slashpb "github.com/prysmaticlabs/prysm/proto/slashing"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	"github.com/prysmaticlabs/prysm/validator/db/kv"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestDeleteAccount_KeepsSlashingProtection(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	baseDir := filepath.Dir(walletDir)
+	cfg := &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFilePath,
+		keymanagerKind:     v2keymanager.Direct,
+		dataDir:            filepath.Join(baseDir, "source"),
+	}
+	wallet, err := NewWallet(setupWalletCtx(t, cfg), v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	name, err := keymanager.CreateAccount(ctx, password)
+	require.NoError(t, err)
+	pubKey, err := keymanager.PublicKeyForAccount(name)
+	require.NoError(t, err)
+
+	store, err := kv.NewKVStore(cfg.dataDir, [][48]byte{pubKey})
+	require.NoError(t, err)
+	require.NoError(t, store.SaveAttestationHistoryForPubKeys(ctx, map[[48]byte]*slashpb.AttestationHistory{
+		pubKey: {
+			TargetToSource:     map[uint64]uint64{0: params.BeaconConfig().FarFutureEpoch, 1: 0, 2: 1},
+			LatestEpochWritten: 2,
+		},
+	}))
+	require.NoError(t, store.Close())
+
+	// The history cannot be kept without the genesis validators root, so nothing is deleted.
+	cfg.deletePublicKeys = []string{fmt.Sprintf("%#x", pubKey)}
+	assert.ErrorContains(t, "--genesis-validators-root is required", DeleteAccount(setupWalletCtx(t, cfg)))
+	accounts, err := wallet.accountPubKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(accounts))
+
+	cfg.genesisRoot = fmt.Sprintf("%#x", [32]byte{'g', 'e', 'n', 'e', 's', 'i', 's'})
+	cfg.slashingProtection = filepath.Join(baseDir, "slashing_protection.json")
+	require.NoError(t, DeleteAccount(setupWalletCtx(t, cfg)))
+	accounts, err = wallet.accountPubKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(accounts))
+	assert.Equal(t, true, fileExists(cfg.slashingProtection))
+	assert.Equal(t, true, fileExists(filepath.Join(walletDir, "direct", tombstoneDirName, fmt.Sprintf("%x.json", pubKey))))
+
+	// Importing into the wallet merges the kept history into a fresh validator database.
+	cfg.dataDir = filepath.Join(baseDir, "target")
+	require.NoError(t, wallet.restoreTombstones(ctx, setupWalletCtx(t, cfg)))
+	store, err = kv.GetKVStore(cfg.dataDir)
+	require.NoError(t, err)
+	require.NotNil(t, store)
+	defer func() {
+		require.NoError(t, store.Close())
+	}()
+	histories, err := store.AttestationHistoryForPubKeys(ctx, [][48]byte{pubKey})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), histories[pubKey].LatestEpochWritten)
+}
@@ -0,0 +1,200 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/urfave/cli/v2"
+)
+
+// trashedAccount is an account deleted from a non-HD wallet and kept in its trash.
+type trashedAccount struct {
+	entryDir  string
+	name      string
+	pubKey    [48]byte
+	deletedAt time.Time
+}
+
+// RestoreAccounts moves the selected --accounts deleted from a non-HD wallet back from its trash
+// into the wallet, along with their password files. Without --accounts, the accounts in the trash
+// are listed with the time they are kept until. An account deleted several times is restored from
+// its latest deletion.
+func RestoreAccounts(cliCtx *cli.Context) error {
+	wallet, err := openDirectWallet(cliCtx)
+	if err != nil {
+		return err
+	}
+	trashed, err := wallet.trashedAccounts()
+	if err != nil {
+		return err
+	}
+	if len(trashed) == 0 {
+		return errors.New("the trash of the wallet is empty")
+	}
+	retention := cliCtx.Duration(flags.TrashRetentionFlag.Name)
+	if !cliCtx.IsSet(flags.AccountsFlag.Name) {
+		return printTrash(trashed, retention)
+	}
+	latest := make(map[string]*trashedAccount, len(trashed))
+	for _, account := range trashed {
+		if current, ok := latest[account.name]; !ok || account.deletedAt.After(current.deletedAt) {
+			latest[account.name] = account
+		}
+	}
+	accounts := make(map[string][48]byte, len(latest))
+	for name, account := range latest {
+		accounts[name] = account.pubKey
+	}
+	names, pubKeys := sortedAccounts(accounts)
+	toRestore, err := selectAccounts(cliCtx, names, pubKeys)
+	if err != nil {
+		return errors.Wrap(err, "could not select accounts")
+	}
+	for _, name := range toRestore {
+		if err := wallet.restoreTrashedAccount(latest[name]); err != nil {
+			return err
+		}
+		fmt.Printf("Restored account %s from the trash\n", au.BrightGreen(name).Bold())
+	}
+	return nil
+}
+
+// Lists the accounts in the trash of the wallet, by order of deletion.
+func (w *Wallet) trashedAccounts() ([]*trashedAccount, error) {
+	trashDir := filepath.Join(w.AccountsDir(), trashDirName)
+	entries, err := ioutil.ReadDir(trashDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list the trash")
+	}
+	trashed := make([]*trashedAccount, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		parts := strings.SplitN(entry.Name(), "-", 2)
+		deletedAt, err := strconv.ParseInt(parts[0], 10, 64)
+		if len(parts) != 2 || err != nil {
+			log.WithField("entry", entry.Name()).Warn("Skipping unknown entry of the trash")
+			continue
+		}
+		account := &trashedAccount{
+			entryDir:  filepath.Join(trashDir, entry.Name()),
+			name:      parts[1],
+			deletedAt: time.Unix(0, deletedAt),
+		}
+		matches, err := filepath.Glob(filepath.Join(account.entryDir, account.name, w.keystoreFileGlob()))
+		if err != nil || len(matches) == 0 {
+			return nil, fmt.Errorf("no keystore found for deleted account %s", account.name)
+		}
+		encoded, err := ioutil.ReadFile(matches[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read keystore of deleted account %s", account.name)
+		}
+		account.pubKey, err = keystorePubKey(encoded)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read public key of deleted account %s", account.name)
+		}
+		trashed = append(trashed, account)
+	}
+	sort.Slice(trashed, func(i, j int) bool {
+		return trashed[i].deletedAt.Before(trashed[j].deletedAt)
+	})
+	return trashed, nil
+}
+
+// Moves the directory and password file of an account from the trash back into the wallet.
+func (w *Wallet) restoreTrashedAccount(account *trashedAccount) error {
+	if err := w.checkWritable(); err != nil {
+		return err
+	}
+	if err := w.checkOnDisk("restoring deleted accounts"); err != nil {
+		return err
+	}
+	accountPath := filepath.Join(w.AccountsDir(), account.name)
+	if _, err := os.Stat(accountPath); err == nil {
+		return fmt.Errorf("account %s is already in the wallet", account.name)
+	}
+	passwordFileName := account.name + direct.PasswordFileSuffix
+	passwordPath := filepath.Join(w.passwordsDir, passwordFileName)
+	trashedPasswordPath := filepath.Join(account.entryDir, passwordFileName)
+	hasPassword := fileExists(trashedPasswordPath)
+	if hasPassword && fileExists(passwordPath) {
+		return fmt.Errorf("a password file of account %s is already in the wallet", account.name)
+	}
+	if err := os.Rename(filepath.Join(account.entryDir, account.name), accountPath); err != nil {
+		return errors.Wrapf(err, "could not move account %s out of the trash", account.name)
+	}
+	if hasPassword {
+		if err := os.MkdirAll(w.passwordsDir, DirectoryPermissions); err != nil {
+			return errors.Wrap(err, "could not create passwords directory")
+		}
+		if err := os.Rename(trashedPasswordPath, passwordPath); err != nil {
+			return errors.Wrapf(err, "could not move password of account %s out of the trash", account.name)
+		}
+	}
+	if err := os.RemoveAll(account.entryDir); err != nil {
+		return errors.Wrapf(err, "could not remove account %s from the trash", account.name)
+	}
+	return w.updateManifest(context.Background(), nil, nil, []string{account.name})
+}
+
+// Erases the accounts of the trash deleted longer than the retention period ago, returning how
+// many were erased.
+func (w *Wallet) purgeTrash(retention time.Duration) (int, error) {
+	if err := w.checkWritable(); err != nil {
+		return 0, err
+	}
+	if err := w.checkOnDisk("erasing deleted accounts"); err != nil {
+		return 0, err
+	}
+	trashed, err := w.trashedAccounts()
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for _, account := range trashed {
+		if roughtime.Since(account.deletedAt) < retention {
+			continue
+		}
+		if err := os.RemoveAll(account.entryDir); err != nil {
+			return purged, errors.Wrapf(err, "could not erase deleted account %s from the trash", account.name)
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func printTrash(trashed []*trashedAccount, retention time.Duration) error {
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "NAME\tPUBLIC KEY\tDELETED AT\tKEPT UNTIL")
+	for _, account := range trashed {
+		fmt.Fprintf(
+			table, "%s\t%#x\t%s\t%s\n",
+			account.name,
+			bytesutil.Trunc(account.pubKey[:]),
+			account.deletedAt.UTC().Format(time.RFC3339),
+			account.deletedAt.Add(retention).UTC().Format(time.RFC3339),
+		)
+	}
+	if err := table.Flush(); err != nil {
+		return errors.Wrap(err, "could not print the trash")
+	}
+	fmt.Printf("Restore accounts from the trash with --%s\n", flags.AccountsFlag.Name)
+	return nil
+}
@@ -0,0 +1,71 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestRestoreAccounts(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFilePath,
+		keymanagerKind:     v2keymanager.Direct,
+	}
+	wallet, err := NewWallet(setupWalletCtx(t, cfg), v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	names := make([]string, 2)
+	pubKeys := make([][48]byte, len(names))
+	for i := range names {
+		names[i], err = keymanager.CreateAccount(ctx, password)
+		require.NoError(t, err)
+		pubKeys[i], err = keymanager.PublicKeyForAccount(names[i])
+		require.NoError(t, err)
+	}
+	cfg.deletePublicKeys = []string{fmt.Sprintf("%#x", pubKeys[0]), fmt.Sprintf("%#x", pubKeys[1])}
+	require.NoError(t, DeleteAccount(setupWalletCtx(t, cfg)))
+	accounts, err := wallet.accountPubKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(accounts))
+
+	// Without --accounts, the trash is only listed.
+	require.NoError(t, RestoreAccounts(setupWalletCtx(t, cfg)))
+	cfg.accountsToExport = names[1]
+	require.NoError(t, RestoreAccounts(setupWalletCtx(t, cfg)))
+	accounts, err = wallet.accountPubKeys(ctx)
+	require.NoError(t, err)
+	assert.DeepEqual(t, map[string][48]byte{names[1]: pubKeys[1]}, accounts)
+	assert.Equal(t, true, fileExists(filepath.Join(passwordsDir, names[1]+direct.PasswordFileSuffix)))
+	trashed, err := wallet.trashedAccounts()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(trashed))
+	assert.Equal(t, names[0], trashed[0].name)
+	assert.Equal(t, pubKeys[0], trashed[0].pubKey)
+
+	// Accounts deleted longer ago than the retention period are erased.
+	purged, err := wallet.purgeTrash(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 0, purged)
+	purged, err = wallet.purgeTrash(0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	trashed, err = wallet.trashedAccounts()
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(trashed))
+}
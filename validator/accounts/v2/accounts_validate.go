@@ -0,0 +1,357 @@
+package v2
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/urfave/cli/v2"
+	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+)
+
+const (
+	// EIP-2335 keystores are at version 4.
+	eip2335KeystoreVersion = 4
+	// Size in bytes of the AES-128-CTR initialization vector.
+	eip2335IVSize = 16
+	// The salt should be at least as long as the derived key.
+	eip2335MinSaltSize = 32
+	// Both the checksum and the encrypted BLS secret key are 32 bytes long.
+	eip2335ChecksumSize  = 32
+	eip2335CipherMsgSize = 32
+)
+
+// keystoreReport holds the outcome of validating a single keystore.
+type keystoreReport struct {
+	Path      string   `json:"path"`
+	PublicKey string   `json:"pubkey,omitempty"`
+	Valid     bool     `json:"valid"`
+	Decrypted bool     `json:"decrypted"`
+	Errors    []string `json:"errors,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"`
+}
+
+// keystoreValidationSummary is printed as JSON by accounts-v2 validate.
+type keystoreValidationSummary struct {
+	Total     int               `json:"total"`
+	Valid     int               `json:"valid"`
+	Invalid   int               `json:"invalid"`
+	Keystores []*keystoreReport `json:"keystores"`
+}
+
+func (r *keystoreReport) errorf(format string, args ...interface{}) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
+
+func (r *keystoreReport) warnf(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// ValidateKeystores checks every keystore of --keys-dir, or of the accounts of a non-HD wallet
+// if no keys directory is given, for EIP-2335 conformance and prints a JSON summary to stdout.
+// Keystores are decrypted to verify their checksum and public key whenever a password is
+// available, either from --account-password-file or from the wallet's passwords directory.
+func ValidateKeystores(cliCtx *cli.Context) error {
+	var reports []*keystoreReport
+	var err error
+	if keysDir := cliCtx.String(flags.KeysDirFlag.Name); keysDir != "" {
+		reports, err = validateKeysDir(cliCtx, keysDir)
+	} else {
+		reports, err = validateWalletKeystores(cliCtx)
+	}
+	if err != nil {
+		return err
+	}
+	summary := &keystoreValidationSummary{
+		Total:     len(reports),
+		Keystores: reports,
+	}
+	for _, report := range reports {
+		if report.Valid {
+			summary.Valid++
+		} else {
+			summary.Invalid++
+		}
+	}
+	encoded, err := json.MarshalIndent(summary, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "could not marshal validation summary")
+	}
+	fmt.Println(string(encoded))
+	if summary.Invalid > 0 {
+		return fmt.Errorf("%d of %d keystores are invalid", summary.Invalid, summary.Total)
+	}
+	return nil
+}
+
+func validateKeysDir(cliCtx *cli.Context, keysDir string) ([]*keystoreReport, error) {
+	keysDir, err := expandPath(keysDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse keys directory")
+	}
+	var password *string
+	if passwordFile := cliCtx.String(flags.AccountPasswordFileFlag.Name); passwordFile != "" {
+		data, err := ioutil.ReadFile(passwordFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read account password file")
+		}
+		pw := string(data)
+		password = &pw
+	}
+	isDir, err := hasDir(keysDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not determine if path is a directory")
+	}
+	paths := []string{keysDir}
+	if isDir {
+		files, err := ioutil.ReadDir(keysDir)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read dir")
+		}
+		paths = make([]string, 0, len(files))
+		for _, file := range files {
+			if file.IsDir() || !strings.HasPrefix(file.Name(), "keystore") {
+				continue
+			}
+			paths = append(paths, filepath.Join(keysDir, file.Name()))
+		}
+	}
+	reports := make([]*keystoreReport, len(paths))
+	for i, path := range paths {
+		encoded, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read keystore %s", path)
+		}
+		reports[i] = validateKeystore(path, encoded, password)
+	}
+	return reports, nil
+}
+
+func validateWalletKeystores(cliCtx *cli.Context) ([]*keystoreReport, error) {
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open wallet")
+	}
+	if wallet.KeymanagerKind() != v2keymanager.Direct {
+		return nil, errors.New("only the keystores of non-HD wallets can be validated")
+	}
+	accountNames, err := wallet.ListDirs()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list accounts")
+	}
+	reports := make([]*keystoreReport, len(accountNames))
+	for i, accountName := range accountNames {
+		keystoreFileName, err := wallet.FileNameAtPath(ctx, accountName, wallet.keystoreFileGlob())
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not find keystore of account %s", accountName)
+		}
+		encoded, err := wallet.ReadFileAtPath(ctx, accountName, wallet.keystoreFileGlob())
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read keystore of account %s", accountName)
+		}
+		var password *string
+		if pw, err := wallet.ReadPasswordFromDisk(ctx, accountName+direct.PasswordFileSuffix); err == nil {
+			password = &pw
+		}
+		reports[i] = validateKeystore(filepath.Join(wallet.AccountsDir(), accountName, keystoreFileName), encoded, password)
+	}
+	return reports, nil
+}
+
+// Checks a keystore for EIP-2335 conformance. If the password is not nil the keystore is also
+// decrypted to verify its checksum and that it holds the secret key of its public key.
+func validateKeystore(path string, encoded []byte, password *string) *keystoreReport {
+	report := &keystoreReport{Path: path}
+	keystore := &v2keymanager.Keystore{}
+	if err := json.Unmarshal(encoded, keystore); err != nil {
+		report.errorf("not a keystore json file: %v", err)
+		return report
+	}
+	report.PublicKey = keystore.Pubkey
+	if keystore.Version != eip2335KeystoreVersion {
+		report.errorf("version is %d, expected %d", keystore.Version, eip2335KeystoreVersion)
+	}
+	if _, err := uuid.Parse(keystore.ID); err != nil {
+		report.errorf("uuid %q is not a valid UUID", keystore.ID)
+	}
+	if strings.HasPrefix(keystore.Pubkey, "0x") {
+		report.warnf("pubkey should not have a 0x prefix")
+	}
+	pubKeyBytes, err := hex.DecodeString(strings.TrimPrefix(keystore.Pubkey, "0x"))
+	if err != nil || len(pubKeyBytes) != 48 {
+		report.errorf("pubkey is not a hex encoded 48 byte public key")
+		pubKeyBytes = nil
+	} else if _, err := bls.PublicKeyFromBytes(pubKeyBytes); err != nil {
+		report.errorf("pubkey is not a valid BLS public key: %v", err)
+	}
+	validateKDFModule(report, keystore.Crypto)
+	validateChecksumModule(report, keystore.Crypto)
+	validateCipherModule(report, keystore.Crypto)
+
+	switch {
+	case len(report.Errors) > 0:
+	case password == nil:
+		report.warnf("no password available, checksum and public key were not verified")
+	default:
+		validateDecryption(report, keystore, *password, pubKeyBytes)
+	}
+	report.Valid = len(report.Errors) == 0
+	return report
+}
+
+func validateKDFModule(report *keystoreReport, crypto map[string]interface{}) {
+	function, params, ok := cryptoModule(report, crypto, "kdf")
+	if !ok {
+		return
+	}
+	if dklen, ok := intParam(params, "dklen"); !ok || dklen != 32 {
+		report.errorf("kdf dklen must be 32")
+	}
+	salt, ok := hexParam(params, "salt")
+	switch {
+	case !ok || len(salt) == 0:
+		report.errorf("kdf salt must be a non-empty hex string")
+	case len(salt) < eip2335MinSaltSize:
+		report.warnf("kdf salt is %d bytes long, at least %d are recommended", len(salt), eip2335MinSaltSize)
+	}
+	cfg := &direct.KDFConfig{Function: function}
+	switch function {
+	case direct.ScryptKDF:
+		var okN, okR, okP bool
+		cfg.ScryptN, okN = intParam(params, "n")
+		cfg.ScryptR, okR = intParam(params, "r")
+		cfg.ScryptP, okP = intParam(params, "p")
+		if !okN || !okR || !okP {
+			report.errorf("kdf scrypt params must include n, r and p")
+			return
+		}
+	case direct.PBKDF2KDF:
+		var okC bool
+		cfg.PBKDF2Iterations, okC = intParam(params, "c")
+		if !okC {
+			report.errorf("kdf pbkdf2 params must include c")
+			return
+		}
+		if prf, _ := params["prf"].(string); prf != "hmac-sha256" {
+			report.errorf("kdf pbkdf2 prf is %q, expected hmac-sha256", prf)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		report.errorf("kdf: %v", err)
+	}
+}
+
+func validateChecksumModule(report *keystoreReport, crypto map[string]interface{}) {
+	function, _, ok := cryptoModule(report, crypto, "checksum")
+	if !ok {
+		return
+	}
+	if function != "sha256" {
+		report.errorf("checksum function is %q, expected sha256", function)
+	}
+	if message, ok := hexMessage(crypto, "checksum"); !ok || len(message) != eip2335ChecksumSize {
+		report.errorf("checksum message must be a hex encoded %d byte hash", eip2335ChecksumSize)
+	}
+}
+
+func validateCipherModule(report *keystoreReport, crypto map[string]interface{}) {
+	function, params, ok := cryptoModule(report, crypto, "cipher")
+	if !ok {
+		return
+	}
+	if function != "aes-128-ctr" {
+		report.errorf("cipher function is %q, expected aes-128-ctr", function)
+	}
+	if iv, ok := hexParam(params, "iv"); !ok || len(iv) != eip2335IVSize {
+		report.errorf("cipher iv must be a hex encoded %d byte value", eip2335IVSize)
+	}
+	if message, ok := hexMessage(crypto, "cipher"); !ok || len(message) != eip2335CipherMsgSize {
+		report.errorf("cipher message must be a hex encoded %d byte secret key", eip2335CipherMsgSize)
+	}
+}
+
+func validateDecryption(report *keystoreReport, keystore *v2keymanager.Keystore, password string, pubKey []byte) {
+	decryptor := keystorev4.New()
+	rawSigningKey, err := decryptor.Decrypt(keystore.Crypto, password)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid checksum") {
+			report.errorf("checksum verification failed, the password is wrong or the keystore is corrupted")
+		} else {
+			report.errorf("could not decrypt keystore: %v", err)
+		}
+		return
+	}
+	report.Decrypted = true
+	secretKey, err := bls.SecretKeyFromBytes(rawSigningKey)
+	v2keymanager.ZeroSecret(rawSigningKey)
+	if err != nil {
+		report.errorf("decrypted secret key is not a valid BLS secret key")
+		return
+	}
+	if !bytes.Equal(secretKey.PublicKey().Marshal(), pubKey) {
+		report.errorf("pubkey does not match the decrypted secret key")
+	}
+}
+
+// Returns the function and params of a module of the crypto section of a keystore, reporting
+// an error if the module is malformed.
+func cryptoModule(report *keystoreReport, crypto map[string]interface{}, name string) (string, map[string]interface{}, bool) {
+	module, ok := crypto[name].(map[string]interface{})
+	if !ok {
+		report.errorf("crypto is missing its %s module", name)
+		return "", nil, false
+	}
+	function, ok := module["function"].(string)
+	if !ok {
+		report.errorf("%s module is missing its function", name)
+		return "", nil, false
+	}
+	params, ok := module["params"].(map[string]interface{})
+	if !ok {
+		report.errorf("%s module is missing its params", name)
+		return "", nil, false
+	}
+	if _, ok := module["message"].(string); !ok {
+		report.errorf("%s module is missing its message", name)
+		return "", nil, false
+	}
+	return function, params, true
+}
+
+func hexMessage(crypto map[string]interface{}, name string) ([]byte, bool) {
+	module, _ := crypto[name].(map[string]interface{})
+	return hexParam(module, "message")
+}
+
+func hexParam(params map[string]interface{}, key string) ([]byte, bool) {
+	s, ok := params[key].(string)
+	if !ok {
+		return nil, false
+	}
+	decoded, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, false
+	}
+	return decoded, true
+}
+
+// JSON numbers are decoded as float64, which are only accepted if they hold an integer.
+func intParam(params map[string]interface{}, key string) (int, bool) {
+	f, ok := params[key].(float64)
+	if !ok || f != float64(int(f)) {
+		return 0, false
+	}
+	return int(f), true
+}
@@ -0,0 +1,138 @@
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+)
+
+func encodedTestKeystore(t *testing.T, mutate func(keystore *v2keymanager.Keystore)) []byte {
+	keystore, err := v2keymanager.NewKeystore(bls.RandKey(), "" /* path */, password)
+	require.NoError(t, err)
+	if mutate != nil {
+		mutate(keystore)
+	}
+	encoded, err := json.Marshal(keystore)
+	require.NoError(t, err)
+	return encoded
+}
+
+func reportHasError(report *keystoreReport, substr string) bool {
+	for _, msg := range report.Errors {
+		if strings.Contains(msg, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestValidateKeystore(t *testing.T) {
+	pw := password
+	wrongPassword := "not-the-password"
+	tests := []struct {
+		name      string
+		mutate    func(keystore *v2keymanager.Keystore)
+		password  *string
+		wantError string
+	}{
+		{
+			name:     "valid",
+			password: &pw,
+		},
+		{
+			name: "valid without password",
+		},
+		{
+			name:      "wrong password",
+			password:  &wrongPassword,
+			wantError: "checksum verification failed",
+		},
+		{
+			name: "wrong version",
+			mutate: func(keystore *v2keymanager.Keystore) {
+				keystore.Version = 3
+			},
+			wantError: "version is 3",
+		},
+		{
+			name: "invalid uuid",
+			mutate: func(keystore *v2keymanager.Keystore) {
+				keystore.ID = "not-a-uuid"
+			},
+			wantError: "is not a valid UUID",
+		},
+		{
+			name: "mismatched pubkey",
+			mutate: func(keystore *v2keymanager.Keystore) {
+				keystore.Pubkey = fmt.Sprintf("%x", bls.RandKey().PublicKey().Marshal())
+			},
+			password:  &pw,
+			wantError: "pubkey does not match the decrypted secret key",
+		},
+		{
+			name: "weak kdf",
+			mutate: func(keystore *v2keymanager.Keystore) {
+				kdf := keystore.Crypto["kdf"].(map[string]interface{})
+				kdf["params"].(map[string]interface{})["n"] = float64(1024)
+			},
+			wantError: "scrypt N must be a power of 2",
+		},
+		{
+			name: "unsupported cipher",
+			mutate: func(keystore *v2keymanager.Keystore) {
+				keystore.Crypto["cipher"].(map[string]interface{})["function"] = "aes-256-gcm"
+			},
+			wantError: "cipher function is \"aes-256-gcm\"",
+		},
+		{
+			name: "missing checksum",
+			mutate: func(keystore *v2keymanager.Keystore) {
+				delete(keystore.Crypto, "checksum")
+			},
+			wantError: "crypto is missing its checksum module",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := validateKeystore("keystore.json", encodedTestKeystore(t, tt.mutate), tt.password)
+			if tt.wantError == "" {
+				assert.Equal(t, true, report.Valid, "Unexpected errors %v", report.Errors)
+				assert.Equal(t, tt.password != nil, report.Decrypted)
+				return
+			}
+			assert.Equal(t, false, report.Valid)
+			assert.Equal(t, true, reportHasError(report, tt.wantError), "Missing error %q in %v", tt.wantError, report.Errors)
+		})
+	}
+}
+
+func TestValidateKeystores_KeysDir(t *testing.T) {
+	_, _, passwordFilePath := setupWalletAndPasswordsDir(t)
+	keysDir := filepath.Join(testutil.TempDir(), t.Name())
+	require.NoError(t, os.MkdirAll(keysDir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(keysDir), "Failed to remove directory")
+	})
+	require.NoError(t, ioutil.WriteFile(filepath.Join(keysDir, "keystore-0.json"), encodedTestKeystore(t, nil), os.ModePerm))
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		keysDir:             keysDir,
+		accountPasswordFile: passwordFilePath,
+	})
+	require.NoError(t, ValidateKeystores(cliCtx))
+
+	corrupted := encodedTestKeystore(t, func(keystore *v2keymanager.Keystore) {
+		keystore.Version = 1
+	})
+	require.NoError(t, ioutil.WriteFile(filepath.Join(keysDir, "keystore-1.json"), corrupted, os.ModePerm))
+	assert.ErrorContains(t, "1 of 2 keystores are invalid", ValidateKeystores(cliCtx))
+}
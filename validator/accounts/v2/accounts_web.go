@@ -0,0 +1,238 @@
+package v2
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// webAccountsFileName is the file accounts are exchanged with the web UI wallet in.
+	webAccountsFileName = "web-wallet-accounts.json"
+	// webAccountsFileVersion is the version of the exchange format written by this client.
+	webAccountsFileVersion = 1
+	// webPasswordDerivationFunction is the only password derivation of version 1 of the format.
+	webPasswordDerivationFunction = "hkdf-sha256"
+)
+
+// webAccountsFile is the format accounts are exchanged in with the browser based wallet flow
+// of the Prysm web UI, which protects all of its accounts with the password of its wallet
+// alone. Version 1 of the format is a JSON object with the fields:
+//
+//	version: 1.
+//	password_derivation.function: "hkdf-sha256".
+//	password_derivation.salt: 32 random bytes, hex encoded, generated for every file.
+//	accounts[].name: the name of the account, which its keystore password is bound to.
+//	accounts[].keystore: the EIP-2335 keystore of the account.
+//	accounts[].created_at: the creation time of the account as a unix timestamp, if known.
+//
+// The keystore of every account is encrypted with its own password, derived from the web wallet
+// password as the first 32 bytes read from HKDF-SHA256 with the web wallet password as secret,
+// the salt as salt and "prysm-account-password:" followed by the account name as info, hex
+// encoded. This is the derivation of the accounts of a non-HD wallet with a master password, so
+// only the web wallet password is ever entered: accounts are re-encrypted on export with
+// passwords derived from the export password, and on import the derived passwords unlock them
+// and are derived again for the master password of the wallet if it has one.
+type webAccountsFile struct {
+	Version            int                    `json:"version"`
+	PasswordDerivation *webPasswordDerivation `json:"password_derivation"`
+	Accounts           []*webAccount          `json:"accounts"`
+}
+
+// webPasswordDerivation describes how the keystore passwords of a web UI accounts file are
+// derived from the web wallet password.
+type webPasswordDerivation struct {
+	Function string `json:"function"`
+	Salt     string `json:"salt"`
+}
+
+// webAccount is an account of a web UI wallet. The creation time is a unix timestamp, and is
+// omitted if it is not known.
+type webAccount struct {
+	Name      string                 `json:"name"`
+	Keystore  *v2keymanager.Keystore `json:"keystore"`
+	CreatedAt int64                  `json:"created_at,omitempty"`
+}
+
+// Returns the password the keystore of an account of the file is encrypted with.
+func (f *webAccountsFile) accountPassword(webPassword string, accountName string) (string, error) {
+	salt, err := hex.DecodeString(f.PasswordDerivation.Salt)
+	if err != nil {
+		return "", errors.Wrap(err, "could not decode password derivation salt")
+	}
+	return deriveAccountPassword(webPassword, salt, accountName)
+}
+
+// Exports the selected accounts into a web UI accounts file in the export directory, each
+// encrypted with its password derived from the export password.
+func (w *Wallet) exportWebAccounts(
+	ctx context.Context,
+	exporter keystoreExporter,
+	exportDir string,
+	accountNames []string,
+	pubKeys [][48]byte,
+	exportPassword string,
+) error {
+	salt := make([]byte, masterPasswordSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return errors.Wrap(err, "could not generate password derivation salt")
+	}
+	accountsFile := &webAccountsFile{
+		Version: webAccountsFileVersion,
+		PasswordDerivation: &webPasswordDerivation{
+			Function: webPasswordDerivationFunction,
+			Salt:     hex.EncodeToString(salt),
+		},
+		Accounts: make([]*webAccount, len(accountNames)),
+	}
+	for i, name := range accountNames {
+		password, err := accountsFile.accountPassword(exportPassword, name)
+		if err != nil {
+			return err
+		}
+		keystores, err := exporter.ExportKeystores(ctx, [][48]byte{pubKeys[i]}, password)
+		if err != nil {
+			return errors.Wrapf(err, "could not export keystore of account %s", name)
+		}
+		accountsFile.Accounts[i] = &webAccount{Name: name, Keystore: keystores[0]}
+		if w.KeymanagerKind() == v2keymanager.Direct {
+			keystoreFileName, err := w.FileNameAtPath(ctx, name, w.keystoreFileGlob())
+			if err != nil {
+				return errors.Wrapf(err, "could not get keystore file name for account: %s", name)
+			}
+			createdAt, err := w.keystoreFileTimestamp(keystoreFileName)
+			if err != nil {
+				return errors.Wrap(err, "could not get timestamp from keystore file name")
+			}
+			accountsFile.Accounts[i].CreatedAt = createdAt.Unix()
+		}
+	}
+	if err := writeWebAccountsFile(exportDir, accountsFile); err != nil {
+		return err
+	}
+	log.WithField("path", filepath.Join(exportDir, webAccountsFileName)).Infof(
+		"Exported %d accounts for the web UI wallet, import them with the export password as the web wallet password",
+		len(accountNames),
+	)
+	return nil
+}
+
+// Writes a web UI accounts file into the export directory.
+func writeWebAccountsFile(exportDir string, accountsFile *webAccountsFile) error {
+	if err := os.MkdirAll(exportDir, params.BeaconIoConfig().ReadWriteExecutePermissions); err != nil {
+		return errors.Wrap(err, "could not create export directory")
+	}
+	filePath := filepath.Join(exportDir, webAccountsFileName)
+	if fileExists(filePath) {
+		return fmt.Errorf("web wallet accounts file already exists at path: %s", filePath)
+	}
+	encoded, err := json.MarshalIndent(accountsFile, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "could not marshal web wallet accounts")
+	}
+	if err := writeFileAtomic(filePath, encoded, params.BeaconIoConfig().ReadWritePermissions); err != nil {
+		return errors.Wrapf(err, "could not write %s", filePath)
+	}
+	return nil
+}
+
+// Reads a web UI accounts file, rejecting versions of the format newer than this client knows.
+func readWebAccountsFile(filePath string) (*webAccountsFile, error) {
+	encoded, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read web wallet accounts file")
+	}
+	accountsFile := &webAccountsFile{}
+	if err := json.Unmarshal(encoded, accountsFile); err != nil {
+		return nil, errors.Wrap(err, "could not decode web wallet accounts file")
+	}
+	if accountsFile.Version < 1 || accountsFile.Version > webAccountsFileVersion {
+		return nil, fmt.Errorf(
+			"web wallet accounts file has version %d, only versions up to %d are supported",
+			accountsFile.Version,
+			webAccountsFileVersion,
+		)
+	}
+	derivation := accountsFile.PasswordDerivation
+	if derivation == nil || derivation.Function != webPasswordDerivationFunction {
+		return nil, fmt.Errorf("web wallet accounts file must derive its passwords with %s", webPasswordDerivationFunction)
+	}
+	for i, account := range accountsFile.Accounts {
+		if account == nil || account.Keystore == nil || account.Name == "" {
+			return nil, fmt.Errorf("account %d of web wallet accounts file has no name or keystore", i)
+		}
+	}
+	return accountsFile, nil
+}
+
+// Imports the accounts of a web UI accounts file given with --keys-dir, unlocking them with the
+// passwords derived from the password of the web UI wallet. Every imported account keeps its
+// derived password as its account password, which is derived again from the master password of
+// the wallet if it has one, and its creation time from the web UI.
+func importWebAccounts(ctx context.Context, cliCtx *cli.Context, wallet *Wallet, filter *pubKeyFilter) error {
+	filePath, err := inputDirectory(cliCtx, webAccountsFilePromptText, flags.KeysDirFlag)
+	if err != nil {
+		return errors.Wrap(err, "could not parse web wallet accounts file path")
+	}
+	if isDir, err := hasDir(filePath); err != nil {
+		return errors.Wrap(err, "could not determine if path is a directory")
+	} else if isDir {
+		filePath = filepath.Join(filePath, webAccountsFileName)
+	}
+	accountsFile, err := readWebAccountsFile(filePath)
+	if err != nil {
+		return err
+	}
+	if len(accountsFile.Accounts) == 0 {
+		return errors.New("web wallet accounts file holds no accounts")
+	}
+	webPassword, err := inputPassword(cliCtx, flags.AccountPasswordFileFlag, webWalletPasswordPromptText, noConfirmPass)
+	if err != nil {
+		return errors.Wrap(err, "could not input web wallet password")
+	}
+	if err := wallet.SaveWallet(); err != nil {
+		return errors.Wrap(err, "could not save wallet")
+	}
+	existing, err := walletKeysFromCli(ctx, cliCtx, wallet)
+	if err != nil {
+		return err
+	}
+	defer existing.reportSkipped()
+	pubKeysImported := make([][]byte, 0, len(accountsFile.Accounts))
+	for _, account := range accountsFile.Accounts {
+		password, err := accountsFile.accountPassword(webPassword, account.Name)
+		if err != nil {
+			return err
+		}
+		keystoreBytes, err := json.Marshal(account.Keystore)
+		if err != nil {
+			return errors.Wrap(err, "could not encode keystore")
+		}
+		createdAt := roughtime.Now()
+		if account.CreatedAt > 0 {
+			createdAt = time.Unix(account.CreatedAt, 0)
+		}
+		pubKey, err := wallet.importKeystoreBytesWithPassword(ctx, keystoreBytes, password, createdAt, existing, filter)
+		if err != nil {
+			return errors.Wrapf(err, "could not import account %s of web wallet", account.Name)
+		}
+		if pubKey != nil {
+			pubKeysImported = append(pubKeysImported, pubKey)
+		}
+	}
+	printKeystoresImported(pubKeysImported)
+	return nil
+}
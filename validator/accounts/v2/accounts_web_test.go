@@ -0,0 +1,194 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/petnames"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/derived"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+)
+
+func TestWebAccounts_RoundTrip(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	exportDir := filepath.Join(testutil.TempDir(), exportDirName, t.Name())
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(exportDir), "Failed to remove directory")
+	})
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		walletPasswordFile:  passwordFile,
+		accountPasswordFile: passwordFile,
+		keymanagerKind:      v2keymanager.Derived,
+		numAccounts:         3,
+	})
+	_, err := CreateWallet(cliCtx)
+	require.NoError(t, err)
+	require.NoError(t, CreateAccount(cliCtx))
+
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	keymanager, err := wallet.InitializeKeymanager(ctx, true)
+	require.NoError(t, err)
+	km, ok := keymanager.(*derived.Keymanager)
+	require.Equal(t, true, ok)
+	names, err := km.ValidatingAccountNames(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(names))
+
+	require.NoError(t, ExportAccount(setupWalletCtx(t, &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFile,
+		exportPasswordFile: passwordFile,
+		exportDir:          exportDir,
+		exportFormat:       webExportFormat,
+		accountsToExport:   "all",
+		keymanagerKind:     v2keymanager.Derived,
+	})))
+	accountsFile, err := readWebAccountsFile(filepath.Join(exportDir, webAccountsFileName))
+	require.NoError(t, err)
+	assert.Equal(t, webAccountsFileVersion, accountsFile.Version)
+	assert.Equal(t, webPasswordDerivationFunction, accountsFile.PasswordDerivation.Function)
+	require.Equal(t, 3, len(accountsFile.Accounts))
+	// Every keystore is encrypted with its password derived from the export password.
+	derivedPasswords := make(map[string]string, len(accountsFile.Accounts))
+	for _, account := range accountsFile.Accounts {
+		derived, err := accountsFile.accountPassword(password, account.Name)
+		require.NoError(t, err)
+		_, err = keystorev4.New().Decrypt(account.Keystore.Crypto, derived)
+		require.NoError(t, err)
+		_, err = keystorev4.New().Decrypt(account.Keystore.Crypto, password)
+		assert.ErrorContains(t, "invalid checksum", err)
+		pubKey := pubKeyOfWebAccount(t, account)
+		accountName := petnames.DeterministicName(pubKey[:], "-")
+		derivedPasswords[accountName] = derived
+	}
+
+	// The accounts of the web wallet are imported into a new non-HD wallet with the web wallet
+	// password alone, each keeping its derived password as its account password.
+	directWalletDir, directPasswordsDir, _ := setupWalletAndPasswordsDir(t)
+	importCfg := &testWalletConfig{
+		walletDir:           directWalletDir,
+		passwordsDir:        directPasswordsDir,
+		keysDir:             exportDir,
+		importFormat:        webImportFormat,
+		keymanagerKind:      v2keymanager.Direct,
+		walletPasswordFile:  passwordFile,
+		accountPasswordFile: passwordFile,
+	}
+	require.NoError(t, ImportAccount(setupWalletCtx(t, importCfg)))
+	directWallet, err := OpenWallet(setupWalletCtx(t, importCfg))
+	require.NoError(t, err)
+	imported, err := directWallet.accountPubKeys(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(imported))
+	for _, name := range names {
+		pubKey, err := km.PublicKeyForAccount(name)
+		require.NoError(t, err)
+		accountName := petnames.DeterministicName(pubKey[:], "-")
+		_, ok := imported[accountName]
+		assert.Equal(t, true, ok, "Expected account %s to be imported", accountName)
+		stored, err := ioutil.ReadFile(filepath.Join(directPasswordsDir, accountName+direct.PasswordFileSuffix))
+		require.NoError(t, err)
+		assert.Equal(t, derivedPasswords[accountName], string(stored))
+		require.NoError(t, directWallet.checkPasswordForAccount(accountName, string(stored)))
+	}
+}
+
+func TestWebAccounts_ImportWithMasterPassword(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	exportDir := filepath.Join(testutil.TempDir(), exportDirName, t.Name())
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(exportDir), "Failed to remove directory")
+	})
+	cfg := &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		walletPasswordFile:  passwordFile,
+		accountPasswordFile: passwordFile,
+		exportPasswordFile:  passwordFile,
+		exportDir:           exportDir,
+		exportFormat:        webExportFormat,
+		accountsToExport:    "all",
+		keymanagerKind:      v2keymanager.Direct,
+	}
+	_, err := CreateWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	require.NoError(t, CreateAccount(setupWalletCtx(t, cfg)))
+	require.NoError(t, ExportAccount(setupWalletCtx(t, cfg)))
+
+	// The derived passwords of the web wallet are derived again for the master password of the
+	// wallet importing its accounts, so no password file is written.
+	directWalletDir, directPasswordsDir, _ := setupWalletAndPasswordsDir(t)
+	importCfg := &testWalletConfig{
+		walletDir:           directWalletDir,
+		passwordsDir:        directPasswordsDir,
+		keymanagerKind:      v2keymanager.Direct,
+		walletPasswordFile:  passwordFile,
+		accountPasswordFile: passwordFile,
+	}
+	_, err = CreateWallet(setupWalletCtx(t, importCfg))
+	require.NoError(t, err)
+	require.NoError(t, UseMasterPassword(setupWalletCtx(t, importCfg)))
+	importCfg.keysDir = exportDir
+	importCfg.importFormat = webImportFormat
+	require.NoError(t, ImportAccount(setupWalletCtx(t, importCfg)))
+
+	wallet, err := OpenWallet(setupWalletCtx(t, importCfg))
+	require.NoError(t, err)
+	accountNames, err := wallet.ListDirs()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(accountNames))
+	derived, ok, err := wallet.DeriveAccountPassword(accountNames[0])
+	require.NoError(t, err)
+	require.Equal(t, true, ok)
+	require.NoError(t, wallet.checkPasswordForAccount(accountNames[0], derived))
+	passwordFiles, err := filepath.Glob(filepath.Join(directPasswordsDir, "*"+direct.PasswordFileSuffix))
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(passwordFiles))
+}
+
+// Reads the public key of the keystore of an account of a web UI accounts file.
+func pubKeyOfWebAccount(t *testing.T, account *webAccount) [48]byte {
+	encoded, err := json.Marshal(account.Keystore)
+	require.NoError(t, err)
+	pubKey, err := keystorePubKey(encoded)
+	require.NoError(t, err)
+	return pubKey
+}
+
+func TestReadWebAccountsFile_UnknownVersion(t *testing.T) {
+	dir := filepath.Join(testutil.TempDir(), t.Name())
+	require.NoError(t, os.MkdirAll(dir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dir), "Failed to remove directory")
+	})
+	filePath := filepath.Join(dir, webAccountsFileName)
+	require.NoError(t, ioutil.WriteFile(filePath, []byte(`{"version":2,"password_derivation":{"function":"hkdf-sha256"},"accounts":[]}`), os.ModePerm))
+	_, err := readWebAccountsFile(filePath)
+	assert.ErrorContains(t, "only versions up to 1 are supported", err)
+}
+
+func TestReadWebAccountsFile_UnknownPasswordDerivation(t *testing.T) {
+	dir := filepath.Join(testutil.TempDir(), t.Name())
+	require.NoError(t, os.MkdirAll(dir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dir), "Failed to remove directory")
+	})
+	filePath := filepath.Join(dir, webAccountsFileName)
+	require.NoError(t, ioutil.WriteFile(filePath, []byte(`{"version":1,"accounts":[]}`), os.ModePerm))
+	_, err := readWebAccountsFile(filePath)
+	assert.ErrorContains(t, "must derive its passwords with hkdf-sha256", err)
+}
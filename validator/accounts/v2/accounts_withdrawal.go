@@ -0,0 +1,622 @@
+package v2
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/depositutil"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/derived"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// withdrawalCredentialsFileName is the file in the accounts directory of a wallet recording
+	// the withdrawal credentials of its accounts.
+	withdrawalCredentialsFileName = "withdrawal-credentials.json"
+	// withdrawalKeystoreFileNameFormat matches the keystore file names of the eth2.0-deposit-cli,
+	// by EIP-2334 withdrawal key path.
+	withdrawalKeystoreFileNameFormat = "withdrawal-keystore-m_12381_3600_%d_0.json"
+	// randomWithdrawalKeystoreFileNameFormat is the file name of the keystore of a random
+	// withdrawal key, by the fingerprint of the key.
+	randomWithdrawalKeystoreFileNameFormat = "withdrawal-keystore-%s.json"
+	// withdrawalCredentialsBatchSize bounds the public keys queried from the beacon node at once,
+	// so every query fits a single page of results.
+	withdrawalCredentialsBatchSize = 100
+	// verifyBeaconNodeTimeout bounds connecting to the beacon node and querying its validators.
+	verifyBeaconNodeTimeout = 2 * time.Minute
+)
+
+// Sources of the withdrawal key of an account.
+const (
+	// A random withdrawal key displayed once when the account was created.
+	withdrawalSourceRandom = "random"
+	// A random withdrawal key stored as a keystore in the account.
+	withdrawalSourceKeystore = "keystore"
+	// A random withdrawal key written as a keystore outside of the wallet, of which only the
+	// fingerprint was displayed.
+	withdrawalSourceKeystoreFile = "keystore-file"
+	// A withdrawal key derived from a separate withdrawal mnemonic.
+	withdrawalSourceMnemonic = "mnemonic"
+	// Withdrawal credentials supplied with --withdrawal-credentials, of a key held elsewhere.
+	withdrawalSourceExternal = "external"
+)
+
+// withdrawalKeystoreWarning is logged for accounts whose withdrawal key is written to a keystore
+// outside of the wallet.
+const withdrawalKeystoreWarning = "Anyone with both the withdrawal keystore and the withdrawal password can withdraw " +
+	"the stake of the validator, and losing either loses it: back them up and move the keystore off this machine"
+
+// Results of comparing the withdrawal credentials of an account with the ones on chain.
+const (
+	withdrawalCredentialsMatch    = "MATCH"
+	withdrawalCredentialsMismatch = "MISMATCH"
+	withdrawalCredentialsNotFound = "NOT_ON_CHAIN"
+	withdrawalCredentialsUnknown  = "UNKNOWN"
+)
+
+// withdrawalCredentialRecords are the withdrawal credentials of the accounts of a wallet, by
+// 0x-prefixed validating public key, along with the next account number of the withdrawal
+// mnemonic new accounts derive their withdrawal key from.
+type withdrawalCredentialRecords struct {
+	NextMnemonicAccount uint64                                 `json:"next_mnemonic_account"`
+	Accounts            map[string]*withdrawalCredentialRecord `json:"accounts"`
+}
+
+// withdrawalCredentialRecord describes the withdrawal credentials an account was deposited with.
+type withdrawalCredentialRecord struct {
+	WithdrawalCredentials string  `json:"withdrawal_credentials"`
+	WithdrawalPublicKey   string  `json:"withdrawal_public_key,omitempty"`
+	Source                string  `json:"source"`
+	MnemonicAccount       *uint64 `json:"mnemonic_account,omitempty"`
+}
+
+// withdrawalCredentialCheck is the result of comparing the withdrawal credentials of an account
+// with the ones of its validator on chain.
+type withdrawalCredentialCheck struct {
+	name     string
+	pubKey   [48]byte
+	expected []byte
+	onChain  []byte
+	result   string
+}
+
+// Creates a new account in a non-HD wallet, withdrawing to a key derived from the
+// --withdrawal-mnemonic-file or to the --withdrawal-credentials if given, and records its
+// withdrawal credentials.
+func createDirectAccount(
+	ctx context.Context,
+	cliCtx *cli.Context,
+	wallet *Wallet,
+	km *direct.Keymanager,
+	password string,
+) (string, error) {
+	records, err := wallet.readWithdrawalCredentials()
+	if err != nil {
+		return "", err
+	}
+	record := &withdrawalCredentialRecord{Source: withdrawalSourceRandom}
+	if cliCtx.Bool(flags.StoreWithdrawalKeyFlag.Name) {
+		record.Source = withdrawalSourceKeystore
+	}
+	var accountName string
+	if cliCtx.IsSet(flags.WithdrawalMnemonicFileFlag.Name) {
+		mnemonic, err := inputWithdrawalMnemonic(cliCtx)
+		if err != nil {
+			return "", err
+		}
+		accountNumber := records.NextMnemonicAccount
+		withdrawalKey, err := derived.WithdrawalKeyFromMnemonic(mnemonic, accountNumber)
+		if err != nil {
+			return "", errors.Wrap(err, "could not derive withdrawal key")
+		}
+		accountName, err = km.CreateAccountWithWithdrawalKey(ctx, password, withdrawalKey)
+		if err != nil {
+			return "", err
+		}
+		record.Source = withdrawalSourceMnemonic
+		record.MnemonicAccount = &accountNumber
+		record.WithdrawalPublicKey = fmt.Sprintf("%#x", withdrawalKey.PublicKey().Marshal())
+		records.NextMnemonicAccount++
+		log.WithField(
+			"path", fmt.Sprintf(derived.WithdrawalKeyDerivationPathTemplate, accountNumber),
+		).Info("Account withdraws to a key of the withdrawal mnemonic")
+	} else if cliCtx.IsSet(flags.WithdrawalCredentialsFlag.Name) {
+		withdrawalCredentials, withdrawalPubKey, err := inputWithdrawalCredentials(cliCtx)
+		if err != nil {
+			return "", err
+		}
+		accountName, err = km.CreateAccountWithWithdrawalCredentials(ctx, password, withdrawalCredentials)
+		if err != nil {
+			return "", err
+		}
+		record.Source = withdrawalSourceExternal
+		if withdrawalPubKey != nil {
+			record.WithdrawalPublicKey = fmt.Sprintf("%#x", withdrawalPubKey.Marshal())
+		}
+		log.WithField(
+			"withdrawalCredentials", fmt.Sprintf("%#x", withdrawalCredentials),
+		).Info("Account withdraws to the given withdrawal credentials")
+	} else if cliCtx.Bool(flags.StoreWithdrawalKeyFlag.Name) || cliCtx.Bool(flags.PrintWithdrawalKeyFlag.Name) {
+		accountName, err = km.CreateAccount(ctx, password)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		keystores, err := inputWithdrawalKeystoreWriter(cliCtx, wallet, password)
+		if err != nil {
+			return "", err
+		}
+		// The withdrawal keystore is written before the account, so a withdrawal key is never
+		// lost with the deposit data of an account withdrawing to it.
+		withdrawalKey := bls.RandKey()
+		filePath, err := keystores.write(withdrawalKey)
+		if err != nil {
+			return "", err
+		}
+		accountName, err = km.CreateAccountWithWithdrawalKey(ctx, password, withdrawalKey)
+		if err != nil {
+			return "", err
+		}
+		record.Source = withdrawalSourceKeystoreFile
+		record.WithdrawalPublicKey = fmt.Sprintf("%#x", withdrawalKey.PublicKey().Marshal())
+		log.WithFields(logrus.Fields{
+			"fingerprint": withdrawalKeyFingerprint(withdrawalKey),
+			"path":        filePath,
+		}).Info("Wrote the withdrawal key of the account to a keystore encrypted with the withdrawal password")
+		log.Warn(withdrawalKeystoreWarning)
+	}
+	if err := wallet.recordAccountCreation(ctx, accountName, roughtime.Now()); err != nil {
+		return "", err
+	}
+	depositData, err := directAccountDepositData(ctx, wallet, accountName)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not read deposit data of account %s", accountName)
+	}
+	if depositData == nil {
+		return "", fmt.Errorf("no deposit data written for account %s", accountName)
+	}
+	pubKey, err := km.PublicKeyForAccount(accountName)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not get public key for account %s", accountName)
+	}
+	record.WithdrawalCredentials = "0x" + depositData.WithdrawalCredentials
+	records.Accounts[fmt.Sprintf("%#x", pubKey)] = record
+	if err := wallet.writeWithdrawalCredentials(ctx, records); err != nil {
+		return "", err
+	}
+	return accountName, nil
+}
+
+// withdrawalKeystoreWriter writes the random withdrawal keys of new accounts as EIP-2335 keystores
+// encrypted with a withdrawal password to a directory outside of the wallet, so they are never
+// displayed. Keystores written to the accounts directory of the wallet are kept in its storage
+// instead, as artifacts encrypted with the artifact key of the wallet if it has one.
+type withdrawalKeystoreWriter struct {
+	wallet   *Wallet
+	dir      string
+	password string
+}
+
+// Inputs the withdrawal password and the --withdrawal-keystores-dir random withdrawal keys are
+// written to. The withdrawal password must differ from the account password stored next to the
+// wallet.
+func inputWithdrawalKeystoreWriter(
+	cliCtx *cli.Context,
+	wallet *Wallet,
+	accountPassword string,
+) (*withdrawalKeystoreWriter, error) {
+	password, err := inputPassword(cliCtx, flags.WithdrawalPasswordFileFlag, withdrawalPasswordPromptText, confirmPass)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not input withdrawal password")
+	}
+	if password == accountPassword {
+		return nil, errors.New("the withdrawal password must differ from the account password")
+	}
+	dir, err := inputDirectory(cliCtx, withdrawalDirPromptText, flags.WithdrawalKeystoresDirFlag)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse output directory")
+	}
+	if err := os.MkdirAll(dir, params.BeaconIoConfig().ReadWriteExecutePermissions); err != nil {
+		return nil, errors.Wrap(err, "could not create output directory")
+	}
+	return &withdrawalKeystoreWriter{wallet: wallet, dir: dir, password: password}, nil
+}
+
+// Writes a withdrawal key as a keystore named after its fingerprint, returning its path. An
+// existing keystore is never overwritten, and the keystore is never left partially written, so a
+// crash cannot lose the only copy of a withdrawal key.
+func (w *withdrawalKeystoreWriter) write(withdrawalKey bls.SecretKey) (string, error) {
+	keystore, err := v2keymanager.NewKeystore(withdrawalKey, "" /* path */, w.password)
+	if err != nil {
+		return "", errors.Wrap(err, "could not encrypt withdrawal key")
+	}
+	encoded, err := json.MarshalIndent(keystore, "", "\t")
+	if err != nil {
+		return "", errors.Wrap(err, "could not marshal withdrawal keystore")
+	}
+	fileName := fmt.Sprintf(randomWithdrawalKeystoreFileNameFormat, withdrawalKeyFingerprint(withdrawalKey))
+	filePath := filepath.Join(w.dir, fileName)
+	if w.dir == w.wallet.AccountsDir() {
+		ctx := context.Background()
+		if _, err := w.wallet.files().readFile(ctx, fileName); err == nil {
+			return "", fmt.Errorf("withdrawal keystore %s already exists", filePath)
+		}
+		if err := w.wallet.WriteFileAtPath(ctx, "" /* accounts dir */, fileName, encoded); err != nil {
+			return "", err
+		}
+		return filePath, nil
+	}
+	if fileExists(filePath) {
+		return "", fmt.Errorf("withdrawal keystore %s already exists", filePath)
+	}
+	if err := writeFileAtomic(filePath, encoded, params.BeaconIoConfig().ReadWritePermissions); err != nil {
+		return "", errors.Wrapf(err, "could not write %s", filePath)
+	}
+	return filePath, nil
+}
+
+// Returns the fingerprint a random withdrawal key is displayed as, the first 8 bytes of the hash
+// of its withdrawal credentials, so it can be matched with the deposit data of its account.
+func withdrawalKeyFingerprint(withdrawalKey bls.SecretKey) string {
+	return fmt.Sprintf("%x", depositutil.WithdrawalCredentialsHash(withdrawalKey)[1:9])
+}
+
+// WriteWithdrawalKeystores writes the withdrawal keys of the accounts of a non-HD wallet which
+// were derived from the --withdrawal-mnemonic-file as EIP-2335 keystores, encrypted with a
+// withdrawal password.
+func WriteWithdrawalKeystores(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	records, err := wallet.readWithdrawalCredentials()
+	if err != nil {
+		return err
+	}
+	accounts, err := wallet.accountPubKeys(ctx)
+	if err != nil {
+		return err
+	}
+	names := make(map[string]string, len(accounts))
+	for name, pubKey := range accounts {
+		names[fmt.Sprintf("%#x", pubKey)] = name
+	}
+	fromMnemonic := make([]string, 0, len(records.Accounts))
+	for pubKey, record := range records.Accounts {
+		if record.Source == withdrawalSourceMnemonic && record.MnemonicAccount != nil {
+			fromMnemonic = append(fromMnemonic, pubKey)
+		}
+	}
+	if len(fromMnemonic) == 0 {
+		return errors.New("no accounts of the wallet withdraw to a key of a withdrawal mnemonic")
+	}
+	sort.Slice(fromMnemonic, func(i, j int) bool {
+		return *records.Accounts[fromMnemonic[i]].MnemonicAccount < *records.Accounts[fromMnemonic[j]].MnemonicAccount
+	})
+	if !cliCtx.IsSet(flags.WithdrawalMnemonicFileFlag.Name) {
+		return fmt.Errorf("the withdrawal mnemonic must be given with --%s", flags.WithdrawalMnemonicFileFlag.Name)
+	}
+	mnemonic, err := inputWithdrawalMnemonic(cliCtx)
+	if err != nil {
+		return err
+	}
+	password, err := inputPassword(cliCtx, flags.WithdrawalPasswordFileFlag, withdrawalPasswordPromptText, confirmPass)
+	if err != nil {
+		return errors.Wrap(err, "could not input withdrawal password")
+	}
+	outputDir, err := inputDirectory(cliCtx, withdrawalDirPromptText, flags.WithdrawalKeystoresDirFlag)
+	if err != nil {
+		return errors.Wrap(err, "could not parse output directory")
+	}
+	if err := os.MkdirAll(outputDir, params.BeaconIoConfig().ReadWriteExecutePermissions); err != nil {
+		return errors.Wrap(err, "could not create output directory")
+	}
+	for _, pubKey := range fromMnemonic {
+		record := records.Accounts[pubKey]
+		accountNumber := *record.MnemonicAccount
+		withdrawalKey, err := derived.WithdrawalKeyFromMnemonic(mnemonic, accountNumber)
+		if err != nil {
+			return errors.Wrap(err, "could not derive withdrawal key")
+		}
+		// A different mnemonic derives different keys, which must not be mistaken for the ones
+		// the accounts withdraw to.
+		if fmt.Sprintf("%#x", withdrawalKey.PublicKey().Marshal()) != record.WithdrawalPublicKey {
+			return fmt.Errorf("withdrawal mnemonic does not derive the withdrawal key of validating public key %s", pubKey)
+		}
+		withdrawalKeyPath := fmt.Sprintf(derived.WithdrawalKeyDerivationPathTemplate, accountNumber)
+		keystore, err := v2keymanager.NewKeystore(withdrawalKey, withdrawalKeyPath, password)
+		if err != nil {
+			return errors.Wrapf(err, "could not encrypt withdrawal key %s", withdrawalKeyPath)
+		}
+		encoded, err := json.MarshalIndent(keystore, "", "\t")
+		if err != nil {
+			return errors.Wrap(err, "could not marshal withdrawal keystore")
+		}
+		filePath := filepath.Join(outputDir, fmt.Sprintf(withdrawalKeystoreFileNameFormat, accountNumber))
+		if fileExists(filePath) {
+			return fmt.Errorf("withdrawal keystore %s already exists", filePath)
+		}
+		if err := writeFileAtomic(filePath, encoded, params.BeaconIoConfig().ReadWritePermissions); err != nil {
+			return errors.Wrapf(err, "could not write %s", filePath)
+		}
+		name, ok := names[pubKey]
+		if !ok {
+			name = "deleted account"
+		}
+		fmt.Printf("Wrote withdrawal keystore of %s to %s\n", au.BrightGreen(name).Bold(), filePath)
+	}
+	return nil
+}
+
+// VerifyWithdrawalCredentials checks the withdrawal credentials of the validators of the accounts
+// of a wallet on the beacon node at --beacon-rpc-provider match the ones the wallet expects,
+// recorded when the accounts were created or read from their deposit data.
+func VerifyWithdrawalCredentials(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	keymanager, err := wallet.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+	if err != nil {
+		return errors.Wrap(err, "could not initialize keymanager")
+	}
+	checks, err := expectedWithdrawalCredentials(ctx, wallet, keymanager)
+	if err != nil {
+		return err
+	}
+	if len(checks) == 0 {
+		return errors.New("wallet has no accounts to verify")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, verifyBeaconNodeTimeout)
+	defer cancel()
+	conn, err := dialBeaconNode(ctx, cliCtx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.WithError(err).Error("Could not close connection to beacon node")
+		}
+	}()
+	if err := checkWithdrawalCredentials(ctx, ethpb.NewBeaconChainClient(conn), checks); err != nil {
+		return err
+	}
+	mismatches := 0
+	for _, check := range checks {
+		result := au.BrightGreen(check.result)
+		switch check.result {
+		case withdrawalCredentialsMismatch:
+			mismatches++
+			result = au.BrightRed(check.result)
+		case withdrawalCredentialsNotFound, withdrawalCredentialsUnknown:
+			result = au.BrightYellow(check.result)
+		}
+		fmt.Printf("%s %s %s\n", result.Bold(), au.BrightGreen(check.name), au.BrightMagenta(fmt.Sprintf("%#x", check.pubKey)))
+		if check.result == withdrawalCredentialsMismatch {
+			fmt.Printf("  expected %#x, on chain %#x\n", check.expected, check.onChain)
+		}
+	}
+	if mismatches > 0 {
+		return fmt.Errorf("withdrawal credentials of %d validators do not match the wallet", mismatches)
+	}
+	return nil
+}
+
+// Gathers the withdrawal credentials the wallet expects for each of its accounts, from the
+// recorded credentials or else from the deposit data of the accounts.
+func expectedWithdrawalCredentials(
+	ctx context.Context,
+	wallet *Wallet,
+	keymanager v2keymanager.IKeymanager,
+) ([]*withdrawalCredentialCheck, error) {
+	inventory, err := inventoryAccounts(ctx, wallet, keymanager)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not build account inventory")
+	}
+	records, err := wallet.readWithdrawalCredentials()
+	if err != nil {
+		return nil, err
+	}
+	accountNames := make([]string, len(inventory.Accounts))
+	for i, account := range inventory.Accounts {
+		accountNames[i] = account.Name
+	}
+	checks := make([]*withdrawalCredentialCheck, len(inventory.Accounts))
+	for i, account := range inventory.Accounts {
+		pubKey, err := parsePubKey(account.PublicKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid public key of account %s", account.Name)
+		}
+		check := &withdrawalCredentialCheck{name: account.Name, pubKey: pubKey}
+		checks[i] = check
+		var credentials string
+		if record, ok := records.Accounts[account.PublicKey]; ok {
+			credentials = strings.TrimPrefix(record.WithdrawalCredentials, "0x")
+		} else {
+			switch km := keymanager.(type) {
+			case *direct.Keymanager:
+				entry, err := directAccountDepositData(ctx, wallet, account.Name)
+				if err != nil {
+					return nil, errors.Wrapf(err, "could not read deposit data of account %s", account.Name)
+				}
+				if entry != nil {
+					credentials = entry.WithdrawalCredentials
+				}
+			case *derived.Keymanager:
+				entry, err := derivedAccountDepositData(km, accountNames, account.Name)
+				if err != nil {
+					return nil, errors.Wrapf(err, "could not derive deposit data of account %s", account.Name)
+				}
+				credentials = entry.WithdrawalCredentials
+			}
+		}
+		if credentials == "" {
+			continue
+		}
+		check.expected, err = hex.DecodeString(credentials)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid withdrawal credentials of account %s", account.Name)
+		}
+	}
+	return checks, nil
+}
+
+// Compares the expected withdrawal credentials of the accounts with the ones of their validators
+// on the beacon node.
+func checkWithdrawalCredentials(
+	ctx context.Context,
+	beaconClient ethpb.BeaconChainClient,
+	checks []*withdrawalCredentialCheck,
+) error {
+	byPubKey := make(map[[48]byte]*withdrawalCredentialCheck, len(checks))
+	for _, check := range checks {
+		byPubKey[check.pubKey] = check
+		check.result = withdrawalCredentialsNotFound
+	}
+	for start := 0; start < len(checks); start += withdrawalCredentialsBatchSize {
+		end := start + withdrawalCredentialsBatchSize
+		if end > len(checks) {
+			end = len(checks)
+		}
+		pubKeys := make([][]byte, 0, end-start)
+		for _, check := range checks[start:end] {
+			pubKey := check.pubKey
+			pubKeys = append(pubKeys, pubKey[:])
+		}
+		resp, err := beaconClient.ListValidators(ctx, &ethpb.ListValidatorsRequest{
+			PublicKeys: pubKeys,
+			PageSize:   int32(len(pubKeys)),
+		})
+		if err != nil {
+			return errors.Wrap(err, "could not list validators")
+		}
+		for _, container := range resp.ValidatorList {
+			if container.Validator == nil || len(container.Validator.PublicKey) != 48 {
+				continue
+			}
+			var pubKey [48]byte
+			copy(pubKey[:], container.Validator.PublicKey)
+			check, ok := byPubKey[pubKey]
+			if !ok {
+				continue
+			}
+			check.onChain = container.Validator.WithdrawalCredentials
+			switch {
+			case check.expected == nil:
+				check.result = withdrawalCredentialsUnknown
+			case bytes.Equal(check.expected, check.onChain):
+				check.result = withdrawalCredentialsMatch
+			default:
+				check.result = withdrawalCredentialsMismatch
+			}
+		}
+	}
+	return nil
+}
+
+// Reads the withdrawal credentials recorded for the accounts of the wallet.
+func (w *Wallet) readWithdrawalCredentials() (*withdrawalCredentialRecords, error) {
+	records := &withdrawalCredentialRecords{Accounts: make(map[string]*withdrawalCredentialRecord)}
+	encoded, err := w.files().readFile(context.Background(), withdrawalCredentialsFileName)
+	if os.IsNotExist(err) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read withdrawal credentials")
+	}
+	if err := json.Unmarshal(encoded, records); err != nil {
+		return nil, errors.Wrap(err, "could not decode withdrawal credentials")
+	}
+	if records.Accounts == nil {
+		records.Accounts = make(map[string]*withdrawalCredentialRecord)
+	}
+	return records, nil
+}
+
+func (w *Wallet) writeWithdrawalCredentials(ctx context.Context, records *withdrawalCredentialRecords) error {
+	encoded, err := json.MarshalIndent(records, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "could not marshal withdrawal credentials")
+	}
+	if err := w.WriteFileAtPath(ctx, "" /* accounts dir */, withdrawalCredentialsFileName, encoded); err != nil {
+		return errors.Wrap(err, "could not write withdrawal credentials")
+	}
+	return nil
+}
+
+// Parses the --withdrawal-credentials, given either as BLS withdrawal credentials or as the BLS
+// withdrawal public key they are formed from, which is returned as well if given.
+func inputWithdrawalCredentials(cliCtx *cli.Context) ([]byte, bls.PublicKey, error) {
+	encoded := strings.TrimSpace(cliCtx.String(flags.WithdrawalCredentialsFlag.Name))
+	if !strings.HasPrefix(encoded, "0x") {
+		return nil, nil, errors.New("withdrawal credentials must be 0x-prefixed")
+	}
+	raw, err := hex.DecodeString(encoded[2:])
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not decode withdrawal credentials")
+	}
+	switch len(raw) {
+	case 32:
+		prefix := params.BeaconConfig().BLSWithdrawalPrefixByte
+		if raw[0] != prefix {
+			return nil, nil, fmt.Errorf(
+				"withdrawal credentials of prefix %#x are not supported, only BLS withdrawal credentials of prefix %#x",
+				raw[:1],
+				[]byte{prefix},
+			)
+		}
+		return raw, nil, nil
+	case 48:
+		withdrawalPubKey, err := bls.PublicKeyFromBytes(raw)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "invalid withdrawal public key")
+		}
+		return depositutil.WithdrawalCredentialsFromPublicKey(withdrawalPubKey), withdrawalPubKey, nil
+	default:
+		return nil, nil, fmt.Errorf(
+			"expected 32 bytes of withdrawal credentials or a 48 bytes withdrawal public key, got %d bytes",
+			len(raw),
+		)
+	}
+}
+
+func inputWithdrawalMnemonic(cliCtx *cli.Context) (string, error) {
+	mnemonicFilePath, err := expandPath(cliCtx.String(flags.WithdrawalMnemonicFileFlag.Name))
+	if err != nil {
+		return "", errors.Wrap(err, "could not determine absolute path of withdrawal mnemonic file")
+	}
+	data, err := ioutil.ReadFile(mnemonicFilePath)
+	if err != nil {
+		return "", errors.Wrap(err, "could not read withdrawal mnemonic file")
+	}
+	mnemonic := strings.TrimSpace(string(data))
+	if err := validateMnemonic(mnemonic); err != nil {
+		return "", errors.Wrap(err, "withdrawal mnemonic did not pass validation")
+	}
+	return mnemonic, nil
+}
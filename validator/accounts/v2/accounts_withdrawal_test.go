@@ -0,0 +1,236 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/depositutil"
+	"github.com/prysmaticlabs/prysm/shared/mock"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/derived"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+)
+
+func TestWithdrawalCredentials_FromMnemonic(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	withdrawalPassword := "withdrawalPassw0rd$2020"
+	filesDir := filepath.Join(filepath.Dir(walletDir), "withdrawal")
+	require.NoError(t, os.MkdirAll(filesDir, os.ModePerm))
+	mnemonicFilePath := filepath.Join(filesDir, mnemonicFileName)
+	require.NoError(t, ioutil.WriteFile(mnemonicFilePath, []byte(mnemonic+"\n"), os.ModePerm))
+	withdrawalPasswordFilePath := filepath.Join(filesDir, passwordFileName)
+	require.NoError(t, ioutil.WriteFile(withdrawalPasswordFilePath, []byte(withdrawalPassword), os.ModePerm))
+	cfg := &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFilePath,
+		keymanagerKind:     v2keymanager.Direct,
+		withdrawalMnemonic: mnemonicFilePath,
+		withdrawalPassword: withdrawalPasswordFilePath,
+		withdrawalDir:      filepath.Join(filesDir, "keystores"),
+	}
+	cliCtx := setupWalletCtx(t, cfg)
+	wallet, err := NewWallet(cliCtx, v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	pubKeys := make([][48]byte, 2)
+	for i := range pubKeys {
+		name, err := createDirectAccount(ctx, cliCtx, wallet, keymanager, password)
+		require.NoError(t, err)
+		pubKeys[i], err = keymanager.PublicKeyForAccount(name)
+		require.NoError(t, err)
+	}
+
+	// Accounts withdraw to consecutive keys of the withdrawal mnemonic.
+	records, err := wallet.readWithdrawalCredentials()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), records.NextMnemonicAccount)
+	for i, pubKey := range pubKeys {
+		withdrawalKey, err := derived.WithdrawalKeyFromMnemonic(mnemonic, uint64(i))
+		require.NoError(t, err)
+		record, ok := records.Accounts[fmt.Sprintf("%#x", pubKey)]
+		require.Equal(t, true, ok, "Expected withdrawal credentials recorded for account %d", i)
+		assert.Equal(t, withdrawalSourceMnemonic, record.Source)
+		assert.Equal(t, uint64(i), *record.MnemonicAccount)
+		assert.Equal(t, fmt.Sprintf("%#x", depositutil.WithdrawalCredentialsHash(withdrawalKey)), record.WithdrawalCredentials)
+	}
+
+	require.NoError(t, WriteWithdrawalKeystores(cliCtx))
+	for i := range pubKeys {
+		encoded, err := ioutil.ReadFile(filepath.Join(cfg.withdrawalDir, fmt.Sprintf(withdrawalKeystoreFileNameFormat, i)))
+		require.NoError(t, err)
+		keystore := &v2keymanager.Keystore{}
+		require.NoError(t, json.Unmarshal(encoded, keystore))
+		rawWithdrawalKey, err := keystorev4.New().Decrypt(keystore.Crypto, withdrawalPassword)
+		require.NoError(t, err, "Could not decrypt withdrawal keystore")
+		withdrawalKey, err := derived.WithdrawalKeyFromMnemonic(mnemonic, uint64(i))
+		require.NoError(t, err)
+		assert.DeepEqual(t, withdrawalKey.Marshal(), rawWithdrawalKey)
+	}
+
+	// Existing withdrawal keystores are not overwritten.
+	assert.ErrorContains(t, "already exists", WriteWithdrawalKeystores(cliCtx))
+
+	// Keystores are not written for the keys of another mnemonic.
+	otherMnemonic := strings.Repeat("abandon ", 23) + "art"
+	require.NoError(t, ioutil.WriteFile(mnemonicFilePath, []byte(otherMnemonic), os.ModePerm))
+	assert.ErrorContains(t, "does not derive the withdrawal key", WriteWithdrawalKeystores(cliCtx))
+}
+
+func TestWithdrawalCredentials_External(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	withdrawalPubKey := bls.RandKey().PublicKey()
+	withdrawalCredentials := depositutil.WithdrawalCredentialsFromPublicKey(withdrawalPubKey)
+	cfg := &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFilePath,
+		keymanagerKind:     v2keymanager.Direct,
+		withdrawalCreds:    fmt.Sprintf("%#x", withdrawalCredentials),
+	}
+	cliCtx := setupWalletCtx(t, cfg)
+	wallet, err := NewWallet(cliCtx, v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	name, err := createDirectAccount(ctx, cliCtx, wallet, keymanager, password)
+	require.NoError(t, err)
+	pubKey, err := keymanager.PublicKeyForAccount(name)
+	require.NoError(t, err)
+
+	// The withdrawal public key is recorded when given instead of the withdrawal credentials.
+	cfg.withdrawalCreds = fmt.Sprintf("%#x", withdrawalPubKey.Marshal())
+	name, err = createDirectAccount(ctx, setupWalletCtx(t, cfg), wallet, keymanager, password)
+	require.NoError(t, err)
+	otherPubKey, err := keymanager.PublicKeyForAccount(name)
+	require.NoError(t, err)
+
+	records, err := wallet.readWithdrawalCredentials()
+	require.NoError(t, err)
+	for _, key := range [][48]byte{pubKey, otherPubKey} {
+		record, ok := records.Accounts[fmt.Sprintf("%#x", key)]
+		require.Equal(t, true, ok, "Expected withdrawal credentials recorded for %#x", key)
+		assert.Equal(t, withdrawalSourceExternal, record.Source)
+		assert.Equal(t, fmt.Sprintf("%#x", withdrawalCredentials), record.WithdrawalCredentials)
+	}
+	assert.Equal(t, "", records.Accounts[fmt.Sprintf("%#x", pubKey)].WithdrawalPublicKey)
+	assert.Equal(t, fmt.Sprintf("%#x", withdrawalPubKey.Marshal()), records.Accounts[fmt.Sprintf("%#x", otherPubKey)].WithdrawalPublicKey)
+
+	for _, invalid := range []string{
+		fmt.Sprintf("%x", withdrawalCredentials),
+		"0xabcd",
+		fmt.Sprintf("%#x", append([]byte{0x05}, withdrawalCredentials[1:]...)),
+	} {
+		cfg.withdrawalCreds = invalid
+		_, err = createDirectAccount(ctx, setupWalletCtx(t, cfg), wallet, keymanager, password)
+		assert.NotNil(t, err, "Expected %s to be rejected", invalid)
+	}
+}
+
+func TestWithdrawalCredentials_Keystore(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	withdrawalPassword := "withdrawalPassw0rd$2020"
+	filesDir := filepath.Join(filepath.Dir(walletDir), "withdrawal")
+	require.NoError(t, os.MkdirAll(filesDir, os.ModePerm))
+	withdrawalPasswordFilePath := filepath.Join(filesDir, passwordFileName)
+	require.NoError(t, ioutil.WriteFile(withdrawalPasswordFilePath, []byte(withdrawalPassword), os.ModePerm))
+	cfg := &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFilePath,
+		keymanagerKind:     v2keymanager.Direct,
+		withdrawalPassword: withdrawalPasswordFilePath,
+		withdrawalDir:      filepath.Join(filesDir, "keystores"),
+	}
+	cliCtx := setupWalletCtx(t, cfg)
+	wallet, err := NewWallet(cliCtx, v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	name, err := createDirectAccount(ctx, cliCtx, wallet, keymanager, password)
+	require.NoError(t, err)
+	pubKey, err := keymanager.PublicKeyForAccount(name)
+	require.NoError(t, err)
+
+	// The random withdrawal key is only written to a keystore named after its fingerprint.
+	records, err := wallet.readWithdrawalCredentials()
+	require.NoError(t, err)
+	record, ok := records.Accounts[fmt.Sprintf("%#x", pubKey)]
+	require.Equal(t, true, ok, "Expected withdrawal credentials recorded for %#x", pubKey)
+	assert.Equal(t, withdrawalSourceKeystoreFile, record.Source)
+	fingerprint := strings.TrimPrefix(record.WithdrawalCredentials, "0x00")[:16]
+	encoded, err := ioutil.ReadFile(filepath.Join(cfg.withdrawalDir, fmt.Sprintf(randomWithdrawalKeystoreFileNameFormat, fingerprint)))
+	require.NoError(t, err)
+	keystore := &v2keymanager.Keystore{}
+	require.NoError(t, json.Unmarshal(encoded, keystore))
+	secretKeyBytes, err := keystorev4.New().Decrypt(keystore.Crypto, withdrawalPassword)
+	require.NoError(t, err)
+	withdrawalKey, err := bls.SecretKeyFromBytes(secretKeyBytes)
+	require.NoError(t, err)
+	assert.Equal(t, fingerprint, withdrawalKeyFingerprint(withdrawalKey))
+	assert.Equal(t, fmt.Sprintf("%#x", withdrawalKey.PublicKey().Marshal()), record.WithdrawalPublicKey)
+
+	// The withdrawal password must differ from the account password.
+	require.NoError(t, ioutil.WriteFile(withdrawalPasswordFilePath, []byte(password), os.ModePerm))
+	_, err = createDirectAccount(ctx, setupWalletCtx(t, cfg), wallet, keymanager, password)
+	assert.ErrorContains(t, "must differ from the account password", err)
+}
+
+func TestCheckWithdrawalCredentials(t *testing.T) {
+	checks := make([]*withdrawalCredentialCheck, 4)
+	for i := range checks {
+		checks[i] = &withdrawalCredentialCheck{name: fmt.Sprintf("account-%d", i)}
+		checks[i].pubKey[0] = byte(i + 1)
+		if i < 3 {
+			checks[i].expected = []byte{0, byte(i + 1)}
+		}
+	}
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	beaconClient := mock.NewMockBeaconChainClient(ctrl)
+	beaconClient.EXPECT().ListValidators(
+		gomock.Any(),
+		gomock.Any(),
+	).Return(&ethpb.Validators{
+		ValidatorList: []*ethpb.Validators_ValidatorContainer{
+			{Index: 1, Validator: &ethpb.Validator{PublicKey: checks[0].pubKey[:], WithdrawalCredentials: []byte{0, 1}}},
+			{Index: 2, Validator: &ethpb.Validator{PublicKey: checks[1].pubKey[:], WithdrawalCredentials: []byte{0, 9}}},
+			{Index: 4, Validator: &ethpb.Validator{PublicKey: checks[3].pubKey[:], WithdrawalCredentials: []byte{0, 4}}},
+		},
+	}, nil /*err*/)
+
+	require.NoError(t, checkWithdrawalCredentials(context.Background(), beaconClient, checks))
+	assert.Equal(t, withdrawalCredentialsMatch, checks[0].result)
+	assert.Equal(t, withdrawalCredentialsMismatch, checks[1].result)
+	assert.DeepEqual(t, []byte{0, 9}, checks[1].onChain)
+	assert.Equal(t, withdrawalCredentialsNotFound, checks[2].result)
+	assert.Equal(t, withdrawalCredentialsUnknown, checks[3].result)
+}
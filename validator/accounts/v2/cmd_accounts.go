@@ -1,6 +1,7 @@
 package v2
 
 import (
+	"github.com/prysmaticlabs/prysm/shared/cmd"
 	"github.com/prysmaticlabs/prysm/shared/featureconfig"
 	"github.com/prysmaticlabs/prysm/validator/flags"
 	"github.com/urfave/cli/v2"
@@ -17,12 +18,40 @@ var AccountCommands = &cli.Command{
 			Name: "create",
 			Description: `creates a new validator account for eth2. If no wallet exists at the given wallet path, creates a new wallet for a user based on
 specified input, capable of creating a direct, derived, or remote wallet.
-this command outputs a deposit data string which is required to become a validator in eth2.`,
+this command outputs a deposit data string which is required to become a validator in eth2.
+the random withdrawal key of a new non-HD account is written to --withdrawal-keystores-dir as a keystore encrypted with a
+separate withdrawal password, and only its fingerprint is displayed. with --print-withdrawal-key, it is displayed once instead.
+with --store-withdrawal-key, the withdrawal key of a new non-HD account is stored in the account as a keystore encrypted
+with a separate withdrawal password instead.
+with --withdrawal-mnemonic-file, the withdrawal key of a new non-HD account is derived from a separate withdrawal mnemonic.
+with --withdrawal-credentials, the deposit data of new non-HD accounts is bound to externally supplied withdrawal
+credentials, or to the ones of a BLS withdrawal public key, such as a custodian's cold key, and no withdrawal key is generated.
+the withdrawal credentials of new non-HD accounts are recorded in the wallet.
+with --count, several accounts are created at once, in parallel for non-HD wallets. a table of the new accounts is printed
+instead of the output of every account, and their deposit data is written to a single deposit_data-<timestamp>.json in
+--deposit-data-output-dir.
+with --indices, the accounts of specific derivation indices such as 100-149 are created in an HD wallet, so ranges of
+accounts of a single mnemonic can be spread across machines.
+with --dry-run, the keys and deposit data of the new accounts are generated and the files creating them would write are
+printed, but nothing is written to disk and no wallet is created`,
 			Flags: []cli.Flag{
 				flags.WalletDirFlag,
 				flags.WalletPasswordFileFlag,
 				flags.AccountPasswordFileFlag,
 				flags.NumAccountsFlag,
+				flags.CreateCountFlag,
+				flags.AccountIndicesFlag,
+				flags.DepositDataOutputDirFlag,
+				flags.DepositDataFormatFlag,
+				flags.StoreWithdrawalKeyFlag,
+				flags.PrintWithdrawalKeyFlag,
+				flags.WithdrawalPasswordFileFlag,
+				flags.WithdrawalKeystoresDirFlag,
+				flags.PasswordStdinFlag,
+				flags.AllowWeakPasswordFlag,
+				flags.WithdrawalMnemonicFileFlag,
+				flags.WithdrawalCredentialsFlag,
+				flags.DryRunFlag,
 				featureconfig.AltonaTestnet,
 				featureconfig.OnyxTestnet,
 			},
@@ -34,12 +63,30 @@ this command outputs a deposit data string which is required to become a validat
 			},
 		},
 		{
-			Name:        "list",
-			Description: "Lists all validator accounts in a user's wallet directory",
+			Name: "list",
+			Description: `lists all validator accounts in a user's wallet directory.
+with --output=json or --output=yaml, the public key, name and creation time of every account and the keymanager kind
+of the wallet are written to stdout as a machine readable inventory.
+with --with-labels, only the accounts having all of the given labels are listed.
+with --sort and --page-size, large wallets are listed --page-size accounts at a time in the given order, and --count
+only displays the number of accounts. the keystores of non-HD wallets are not decrypted to list their accounts.
+with --sort=created, accounts of non-HD wallets are listed in the order of the creation times recorded in their metadata,
+which copying the wallet does not change.
+with --show-last-signed, the last slot every account proposed a block for and the last epoch it attested to are read
+from the slashing protection history of the validator database in --datadir, so accounts which went quiet stand out`,
 			Flags: []cli.Flag{
 				flags.WalletDirFlag,
 				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
 				flags.ShowDepositDataFlag,
+				flags.ListOutputFlag,
+				flags.WithLabelsFlag,
+				flags.ListSortFlag,
+				flags.ListPageFlag,
+				flags.ListPageSizeFlag,
+				flags.ListCountFlag,
+				flags.ShowLastSignedFlag,
+				cmd.DataDirFlag,
 				featureconfig.AltonaTestnet,
 				featureconfig.OnyxTestnet,
 			},
@@ -51,12 +98,421 @@ this command outputs a deposit data string which is required to become a validat
 			},
 		},
 		{
-			Name:        "export",
-			Description: `exports the account of a given directory into a zip of the provided output path. This zip can be used to later import the account to another directory`,
+			Name: "report",
+			Description: `reports when every account of a wallet was created, whether its deposit is visible on chain and where its files
+are on disk. deposit statuses are queried from the beacon node at --beacon-rpc-provider.
+with --output=json or --output=yaml, the report is written to stdout in a machine readable format`,
 			Flags: []cli.Flag{
 				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.BeaconRPCProviderFlag,
+				flags.CertFlag,
+				flags.GrpcHeadersFlag,
+				flags.GrpcRetriesFlag,
+				flags.GrpcRetryDelayFlag,
+				cmd.GrpcMaxCallRecvMsgSizeFlag,
+				flags.ListOutputFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := ReportAccounts(cliCtx); err != nil {
+					log.Fatalf("Could not report accounts: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "status",
+			Description: `queries the beacon node at --beacon-rpc-provider for the validator of every account of a wallet and prints
+its index, status, balance, activation and exit epochs and whether it was slashed. with --output=json or --output=yaml,
+the statuses are written to stdout in a machine readable format`,
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.BeaconRPCProviderFlag,
+				flags.CertFlag,
+				flags.GrpcHeadersFlag,
+				flags.GrpcRetriesFlag,
+				flags.GrpcRetryDelayFlag,
+				cmd.GrpcMaxCallRecvMsgSizeFlag,
+				flags.ListOutputFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := StatusAccounts(cliCtx); err != nil {
+					log.Fatalf("Could not query account statuses: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "deposits",
+			Description: `tracks the deposits of the accounts of a non-HD wallet: the DepositEvent logs of the --deposit-contract are
+read from the eth1 node at --http-web3provider and matched against the deposit data root stored for every account,
+marking accounts as deposit pending or included. with --beacon-rpc-provider, accounts with an included deposit whose
+validator is active are marked as active`,
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.HTTPWeb3ProviderFlag,
+				flags.DepositContractFlag,
+				flags.DepositFromBlockFlag,
+				flags.BeaconRPCProviderFlag,
+				flags.CertFlag,
+				flags.GrpcHeadersFlag,
+				flags.GrpcRetriesFlag,
+				flags.GrpcRetryDelayFlag,
+				cmd.GrpcMaxCallRecvMsgSizeFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := TrackDeposits(cliCtx); err != nil {
+					log.Fatalf("Could not track deposits: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "exit",
+			Description: `submits a voluntary exit for the validators of the selected --accounts of a wallet to the beacon node at
+--beacon-rpc-provider. exits are signed by the keymanager of the wallet, recorded in the wallet and cannot be reversed.
+exiting accounts of a wallet with a TOTP secret requires a --totp-code of its authenticator app`,
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AccountsFlag,
+				flags.BeaconRPCProviderFlag,
+				flags.CertFlag,
+				flags.GrpcHeadersFlag,
+				flags.GrpcRetriesFlag,
+				flags.GrpcRetryDelayFlag,
+				cmd.GrpcMaxCallRecvMsgSizeFlag,
+				flags.SkipExitConfirmFlag,
+				flags.TOTPCodeFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := ExitAccounts(cliCtx); err != nil {
+					log.Fatalf("Could not exit accounts: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "withdrawal-keystores",
+			Description: `writes the withdrawal keys of the accounts of a non-HD wallet derived from the --withdrawal-mnemonic-file to
+--withdrawal-keystores-dir as EIP-2335 keystores encrypted with a withdrawal password`,
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.WithdrawalMnemonicFileFlag,
+				flags.WithdrawalPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AllowWeakPasswordFlag,
+				flags.WithdrawalKeystoresDirFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := WriteWithdrawalKeystores(cliCtx); err != nil {
+					log.Fatalf("Could not write withdrawal keystores: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "verify-withdrawal-credentials",
+			Description: `checks the withdrawal credentials of the validators of the accounts of a wallet on the beacon node at
+--beacon-rpc-provider match the ones the wallet expects, recorded when the accounts were created or read from their deposit data`,
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.BeaconRPCProviderFlag,
+				flags.CertFlag,
+				flags.GrpcHeadersFlag,
+				flags.GrpcRetriesFlag,
+				flags.GrpcRetryDelayFlag,
+				cmd.GrpcMaxCallRecvMsgSizeFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := VerifyWithdrawalCredentials(cliCtx); err != nil {
+					log.Fatalf("Could not verify withdrawal credentials: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "archive",
+			Description: `moves the selected --accounts of a non-HD wallet, or with --exited every account which exit was submitted with
+accounts-v2 exit, to the cold archive of the wallet. archived accounts are not loaded by the validator and their keystores
+are never decrypted, until they are restored with accounts-v2 unarchive`,
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AccountsFlag,
+				flags.ArchiveExitedFlag,
+				flags.ApprovalTokensFlag,
+				flags.TOTPCodeFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := ArchiveAccounts(cliCtx); err != nil {
+					log.Fatalf("Could not archive accounts: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name:        "unarchive",
+			Description: `moves the selected --accounts of the cold archive of a non-HD wallet back into the wallet`,
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AccountsFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := UnarchiveAccounts(cliCtx); err != nil {
+					log.Fatalf("Could not restore archived accounts: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "delete",
+			Description: `deletes the accounts of the --delete-public-keys from a non-HD wallet, along with their password files.
+the accounts of the public keys listed one per line in the --pubkeys-file and the accounts having all of the --with-labels
+are deleted as well.
+the deletion of every account must be confirmed by typing its full public key. deleted accounts are moved to the trash of the
+wallet and can be restored with accounts-v2 restore, until they are erased once the --trash-retention expires.
+the slashing protection history of the deleted accounts is read from the validator database in --datadir and kept in the wallet,
+and also exported to --slashing-protection-file if given. it is merged back into the validator database when accounts are
+imported into the wallet again, so the keys of deleted accounts never start from a clean history.
+deleting accounts of a wallet with an approval policy requires the --approval-tokens of its operators for their public keys,
+and of a wallet with a TOTP secret a --totp-code of its authenticator app`,
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordsDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.DeletePublicKeysFlag,
+				flags.PubKeysFileFlag,
+				flags.WithLabelsFlag,
+				flags.SkipDeleteConfirmFlag,
+				flags.ApprovalTokensFlag,
+				flags.TOTPCodeFlag,
+				flags.TrashRetentionFlag,
+				cmd.DataDirFlag,
+				flags.GenesisValidatorsRootFlag,
+				flags.SlashingProtectionFileFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := DeleteAccount(cliCtx); err != nil {
+					log.Fatalf("Could not delete accounts: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "restore",
+			Description: `restores the selected --accounts deleted from a non-HD wallet from its trash, along with their password
+files. without --accounts, the accounts in the trash are listed with the time they are kept until`,
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordsDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AccountsFlag,
+				flags.TrashRetentionFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := RestoreAccounts(cliCtx); err != nil {
+					log.Fatalf("Could not restore accounts: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "rename",
+			ArgsUsage: "<old> <new>",
+			Description: `renames an account of a non-HD wallet. the account directory and its password file are renamed,
+its keystore and deposit data are left untouched`,
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordsDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := RenameAccount(cliCtx); err != nil {
+					log.Fatalf("Could not rename account: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "change-password",
+			Description: `re-encrypts the keystores of the selected --accounts of a non-HD wallet with a new password and updates
+their password files. each keystore must decrypt with the current password of its account, the keys of the accounts
+are left untouched`,
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordsDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.AccountsFlag,
+				flags.AccountPasswordFileFlag,
+				flags.NewAccountPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AllowWeakPasswordFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := ChangeAccountPassword(cliCtx); err != nil {
+					log.Fatalf("Could not change account password: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "label",
+			Description: `attaches the --add-labels to and removes the --remove-labels from the --accounts of a wallet.
+labels are key=value pairs stored in the wallet, such as customer=acme or batch=2020-09. the list, export and delete
+commands select the accounts having all of the --with-labels`,
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AccountsFlag,
+				flags.AddLabelsFlag,
+				flags.RemoveLabelsFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := LabelAccounts(cliCtx); err != nil {
+					log.Fatalf("Could not label accounts: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "note",
+			Description: `sets the free-form --notes of the selected --accounts of a non-HD wallet, replacing their previous notes.
+notes are stored next to the keystore of each account and shown by accounts-v2 list. empty notes remove them`,
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AccountsFlag,
+				flags.AccountNotesFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := NoteAccounts(cliCtx); err != nil {
+					log.Fatalf("Could not set account notes: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "copy-metadata",
+			Description: `copies the names, labels, notes and creation times of the accounts of the non-HD wallet at
+--source-wallet-dir to the accounts of the same public keys in the wallet at --wallet-dir, such as after re-importing
+keys on a new machine. keys are never copied, and accounts missing from either wallet are skipped`,
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordsDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.SourceWalletDirFlag,
+				flags.SourceWalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := CopyAccountMetadata(cliCtx); err != nil {
+					log.Fatalf("Could not copy account metadata: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "deactivate",
+			Description: `schedules the selected --accounts of a wallet to stop being served by the validator client after the
+--deactivate-after-epoch or the --deactivate-after date, whichever comes first, such as keys being handed off to another
+operator or exited at a planned time. deactivated keys are no longer validated with and signing with them fails.
+--cancel-deactivation removes the scheduled deactivation of the accounts. with --pubkeys-file, the accounts of the public
+keys listed one per line in the file are selected instead of the --accounts`,
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AccountsFlag,
+				flags.PubKeysFileFlag,
+				flags.DeactivateAfterEpochFlag,
+				flags.DeactivateAfterFlag,
+				flags.CancelDeactivationFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := DeactivateAccounts(cliCtx); err != nil {
+					log.Fatalf("Could not schedule account deactivation: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "export",
+			Description: `exports the selected accounts of a wallet, by account name or public key, as standalone EIP-2335 keystore
+files encrypted with a new export password. These keystores can be imported by other eth2 clients or with the import command.
+with --export-format=web3signer, a key configuration is written for every keystore to provision a Web3Signer remote signer.
+with --export-format=web, the keystores are written to a single web UI wallet accounts file, each encrypted with a password
+derived from the export password, which the Prysm web UI wallet imports with the export password as its wallet password.
+with --slashing-protection-file, the slashing protection history of the exported accounts in --datadir is written as an EIP-3076 interchange file.
+with --pubkeys-file, the accounts of the public keys listed one per line in the file are exported.
+with --with-labels, only the accounts having all of the given labels are exported.
+exporting accounts of a wallet with an approval policy requires the --approval-tokens of its operators for their public keys,
+and of a wallet with a TOTP secret a --totp-code of its authenticator app`,
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
 				flags.BackupDirFlag,
 				flags.AccountsFlag,
+				flags.PubKeysFileFlag,
+				flags.WithLabelsFlag,
+				flags.ExportPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AllowWeakPasswordFlag,
+				flags.ExportFormatFlag,
+				flags.SlashingProtectionFileFlag,
+				flags.GenesisValidatorsRootFlag,
+				flags.ApprovalTokensFlag,
+				flags.TOTPCodeFlag,
+				cmd.DataDirFlag,
 				featureconfig.AltonaTestnet,
 				featureconfig.OnyxTestnet,
 			},
@@ -68,13 +524,104 @@ this command outputs a deposit data string which is required to become a validat
 			},
 		},
 		{
-			Name:        "import",
-			Description: `imports the accounts from a given zip file to the provided wallet path. This zip can be created using the export command`,
+			Name: "sign-message",
+			Description: `signs the --message with the validating key of the selected account, to prove control of its public key
+to staking pools or exchanges. the message is signed in a domain of its own, so the signature can never be used as a
+consensus message, and the public key, message, signing root and signature are printed as json`,
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordsDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AccountsFlag,
+				flags.MessageFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := SignMessage(cliCtx); err != nil {
+					log.Fatalf("Could not sign message: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "deposit-data",
+			Description: `writes the deposit data of the selected accounts of a wallet, by account name or public key, into a single
+deposit_data-<timestamp>.json file in the format accepted by the eth2 launchpad`,
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AccountsFlag,
+				flags.DepositDataOutputDirFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := ExportDepositData(cliCtx); err != nil {
+					log.Fatalf("Could not export deposit data: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "import",
+			Description: `imports validator keystores into a non-HD wallet. By default, --keys-dir is a directory of EIP-2335 keystores,
+a single keystore file, or a .zip or .tar.gz archive of keystores such as one written by accounts-v2 export.
+keystores are unlocked with --account-password-file or the password prompted for, or with --keystore-passwords-file,
+which gives the password of each keystore of a --keys-dir directory by its public key.
+other clients' layouts are imported with --format:
+  teku: keystores are read from each --teku-keys with a password file per keystore from the --teku-passwords
+    given in the same position, as used by Teku.
+  nimbus: --keys-dir is a Nimbus data directory whose validators and secrets directories are imported.
+  lighthouse: --keys-dir is a Lighthouse validators directory whose voting keystores are unlocked with their
+    passwords from the secrets directory next to it, or from --lighthouse-secrets-dir.
+  ethdo: the --ethdo-accounts of the ethdo wallets stored in --keys-dir, or the default ethdo location, are imported.
+  web: --keys-dir is a Prysm web UI wallet accounts file, whose keystore passwords are derived from the web wallet password
+    given with --account-password-file or prompted for, and derived again for the wallet if it has a master password.
+Ethereum v3 keystores wrapping BLS secret keys, which must give their BLS public key, are converted to EIP-2335 keystores
+when their password is known from --account-password-file, or from the password files of the teku and nimbus formats.
+with --url and --sha256, a keystore archive or keystore file is downloaded over https or from an s3:// or gs:// bucket
+and imported once its digest is verified. objects encrypted server-side with a customer supplied key are read with
+--sse-key-file, bundles encrypted client-side are decrypted with --decryption-key-file.
+with --private-key-file, raw hex encoded BLS secret keys are encrypted into the wallet after an explicit confirmation,
+which --skip-private-key-import-confirm skips.
+with --slashing-protection-file, the EIP-3076 slashing protection history in the file is merged into --datadir before
+any keystore is imported, once checked against --genesis-validators-root if given. the slashing protection history the
+wallet kept of its deleted accounts is merged into --datadir as well.
+with --include-pubkeys or --exclude-pubkeys, only the keystores and slashing protection history of the selected validating
+public keys are imported, an exclusion taking precedence over an inclusion.
+keys the wallet already holds are skipped and listed once the import is done, unless --reimport is given to overwrite their accounts.
+imports of a keystore directory with --account-password-file or --keystore-passwords-file are checkpointed, running an
+interrupted import again resumes it.
+with --password-stdin, the passwords asked for are read from standard input instead of prompted for.`,
 			Flags: []cli.Flag{
 				flags.WalletDirFlag,
 				flags.WalletPasswordsDirFlag,
 				flags.KeysDirFlag,
+				flags.ImportFormatFlag,
+				flags.KeystoresURLFlag,
+				flags.KeystoresSHA256Flag,
+				flags.KeystoresSSEKeyFileFlag,
+				flags.KeystoresDecryptionKeyFileFlag,
+				flags.LighthouseSecretsDirFlag,
+				flags.TekuKeysFlag,
+				flags.TekuPasswordsFlag,
+				flags.EthdoAccountsFlag,
+				flags.PrivateKeyFileFlag,
+				flags.SkipPrivateKeyImportConfirmFlag,
+				flags.IncludePubKeysFlag,
+				flags.ExcludePubKeysFlag,
+				flags.ReimportFlag,
 				flags.WalletPasswordFileFlag,
+				flags.AccountPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AllowWeakPasswordFlag,
+				flags.KeystorePasswordsFileFlag,
+				flags.SlashingProtectionFileFlag,
+				flags.GenesisValidatorsRootFlag,
+				cmd.DataDirFlag,
 				featureconfig.AltonaTestnet,
 				featureconfig.OnyxTestnet,
 			},
@@ -85,5 +632,72 @@ this command outputs a deposit data string which is required to become a validat
 				return nil
 			},
 		},
+		{
+			Name: "validate",
+			Description: `checks every keystore in --keys-dir, or in a non-HD wallet if no keys directory is given, for EIP-2335
+conformance: version and uuid fields, public key encoding and KDF, checksum and cipher parameters. When a password is
+available, from --account-password-file or the wallet passwords directory, each keystore is decrypted to verify its
+checksum and that it holds the secret key of its public key. A JSON summary is printed to stdout`,
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordsDirFlag,
+				flags.KeysDirFlag,
+				flags.AccountPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := ValidateKeystores(cliCtx); err != nil {
+					log.Fatalf("Keystore validation failed: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "sync-remote",
+			Description: `queries the remote signer of a remote wallet for the keys it holds, or the Web3Signer at --web3signer-url,
+and registers them as the accounts of the wallet. Keys the signer no longer holds are removed from the accounts, so running
+the command again keeps the account list of the wallet in sync with the signer`,
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.Web3SignerURLFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := SyncRemoteAccounts(cliCtx); err != nil {
+					log.Fatalf("Could not sync accounts with remote signer: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "migrate-from-v1",
+			Description: `migrates the validating keys of a v1 keymanager, selected with --keymanager and --keymanageropts, into
+EIP-2335 keystores in a non-HD wallet. The v1 keys are left untouched and every migrated account is verified against
+its v1 key. Slashing protection history in --datadir is keyed by public key and keeps applying to the migrated accounts`,
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordsDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.AccountPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AllowWeakPasswordFlag,
+				flags.KeyManager,
+				flags.KeyManagerOpts,
+				cmd.DataDirFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := MigrateFromV1(cliCtx); err != nil {
+					log.Fatalf("Could not migrate v1 accounts: %v", err)
+				}
+				return nil
+			},
+		},
 	},
 }
@@ -1,6 +1,7 @@
 package v2
 
 import (
+	"github.com/prysmaticlabs/prysm/shared/cmd"
 	"github.com/prysmaticlabs/prysm/shared/featureconfig"
 	"github.com/prysmaticlabs/prysm/validator/flags"
 	"github.com/urfave/cli/v2"
@@ -25,6 +26,12 @@ var WalletCommands = &cli.Command{
 				flags.RemoteSignerKeyPathFlag,
 				flags.RemoteSignerCACertPathFlag,
 				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AllowWeakPasswordFlag,
+				flags.DepositDataFormatFlag,
+				flags.KeystoreFileNameFormatFlag,
+				flags.EncryptKeymanagerConfigFlag,
+				flags.WalletStorageURLFlag,
 				featureconfig.AltonaTestnet,
 				featureconfig.OnyxTestnet,
 			},
@@ -45,6 +52,8 @@ var WalletCommands = &cli.Command{
 				flags.RemoteSignerKeyPathFlag,
 				flags.RemoteSignerCACertPathFlag,
 				flags.WalletPasswordsDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
 				featureconfig.AltonaTestnet,
 				featureconfig.OnyxTestnet,
 			},
@@ -63,7 +72,11 @@ var WalletCommands = &cli.Command{
 				flags.WalletPasswordsDirFlag,
 				flags.MnemonicFileFlag,
 				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AllowWeakPasswordFlag,
 				flags.NumAccountsFlag,
+				flags.AccountIndicesFlag,
+				flags.DepositDataOutputDirFlag,
 				featureconfig.AltonaTestnet,
 				featureconfig.OnyxTestnet,
 			},
@@ -74,5 +87,492 @@ var WalletCommands = &cli.Command{
 				return nil
 			},
 		},
+		{
+			Name: "convert",
+			Usage: "rebuilds a non-HD wallet as an HD wallet derived from a mnemonic, once every account of the wallet " +
+				"is verified to hold the validating key of a derivation index of the mnemonic",
+			Description: `the first derivation indices of the mnemonic, up to 100 past the number of accounts, are searched for the
+validating key of every account of the non-HD wallet, and the conversion is refused if any account is not derived from
+the mnemonic. The HD wallet holds every account up to the highest index found, and its keys are checked against the
+accounts of the non-HD wallet before the conversion completes. The keystores and account passwords of the non-HD wallet
+are retired into a <wallet-dir>-retired-direct-<timestamp> directory next to the wallet directory`,
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordsDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AllowWeakPasswordFlag,
+				flags.MnemonicFileFlag,
+				flags.SkipConvertConfirmFlag,
+				flags.ApprovalTokensFlag,
+				flags.TOTPCodeFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := ConvertWallet(cliCtx); err != nil {
+					log.Fatalf("Could not convert wallet: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "change-password",
+			Usage: "re-encrypts the seed of an HD wallet, an encrypted keymanager config and the encrypted account passwords of " +
+				"a non-HD wallet with a new wallet password. " +
+				"every file is re-encrypted before any is replaced, and replaced files are restored if another one fails",
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.NewWalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AllowWeakPasswordFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := ChangeWalletPassword(cliCtx); err != nil {
+					log.Fatalf("Could not change wallet password: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "verify",
+			Usage: "checks the integrity of a non-HD wallet by decrypting the keystore of every account with its stored " +
+				"password, confirming the decrypted key matches the public key of the keystore, and looking for password " +
+				"files belonging to no account. prints a pass or fail result for every account",
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.WalletPasswordsDirFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := VerifyWallet(cliCtx); err != nil {
+					log.Fatalf("Could not verify wallet: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "backup",
+			Usage: "writes the wallet, the account passwords of a non-HD wallet and the validator database with its " +
+				"slashing protection history into a single file encrypted with a backup password. Stop the validator first",
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordsDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.BackupDirFlag,
+				flags.BackupPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AllowWeakPasswordFlag,
+				flags.ApprovalTokensFlag,
+				flags.TOTPCodeFlag,
+				cmd.DataDirFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := BackupWallet(cliCtx); err != nil {
+					log.Fatalf("Could not back up wallet: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "sync",
+			Usage: "replicates the files of the wallet to a secondary location given by --sync-target, a directory or " +
+				"a storage url, copying the files changed since the last sync. refuses to sync a target which diverged " +
+				"from the wallet, such as one holding a different keystore for the same public key. account passwords " +
+				"are not replicated",
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.SyncTargetFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := SyncWallet(cliCtx); err != nil {
+					log.Fatalf("Could not sync wallet: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "compact",
+			Usage: "merges the keystores of the accounts of a non-HD wallet into a single accounts keystore encrypted " +
+				"with the wallet password, decrypted once at startup rather than once per account, or writes them back " +
+				"with --expand. account passwords stay in the passwords directory of the wallet. the scrypt cost of the " +
+				"accounts keystore is set with --accounts-keystore-scrypt-n, -r and -p, and kept in the keymanager config",
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AllowWeakPasswordFlag,
+				flags.ExpandWalletFlag,
+				flags.AccountsKeystoreScryptNFlag,
+				flags.AccountsKeystoreScryptRFlag,
+				flags.AccountsKeystoreScryptPFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := CompactWallet(cliCtx); err != nil {
+					log.Fatalf("Could not compact wallet: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "gc",
+			Usage: "reports the orphaned files of a non-HD wallet: password files of no account, empty account " +
+				"directories, directories without a keystore and temporary files of interrupted writes. removes them " +
+				"with --remove-orphans, but for directories holding files",
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.RemoveOrphansFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := CollectWalletGarbage(cliCtx); err != nil {
+					log.Fatalf("Could not collect wallet garbage: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "snapshot",
+			Usage: "captures a copy of the wallet, its account passwords and the validator database under " +
+				"--snapshot-label, to roll the wallet back to after a botched import or migration. the validator must be stopped",
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.SnapshotsDirFlag,
+				flags.SnapshotLabelFlag,
+				cmd.DataDirFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := SnapshotWallet(cliCtx); err != nil {
+					log.Fatalf("Could not take wallet snapshot: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "list-snapshots",
+			Usage: "lists the wallet snapshots taken with wallet-v2 snapshot",
+			Flags: []cli.Flag{
+				flags.SnapshotsDirFlag,
+				cmd.DataDirFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := ListWalletSnapshots(cliCtx); err != nil {
+					log.Fatalf("Could not list wallet snapshots: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "restore-snapshot",
+			Usage: "rolls the wallet and its account passwords back to the snapshot of --snapshot-label, taking a " +
+				"snapshot of the wallet first so the restore can be undone. slashing protection history is never rolled " +
+				"back, the history of the snapshot is merged into the validator database",
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.SnapshotsDirFlag,
+				flags.SnapshotLabelFlag,
+				flags.ApprovalTokensFlag,
+				flags.TOTPCodeFlag,
+				cmd.DataDirFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := RestoreWalletSnapshot(cliCtx); err != nil {
+					log.Fatalf("Could not restore wallet snapshot: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "encrypt-passwords",
+			Usage: "moves the plaintext account password files of a non-HD wallet into a password store encrypted with " +
+				"the wallet password, entered once to open the wallet. passwords of accounts created afterwards are " +
+				"written to the password store too",
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AllowWeakPasswordFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := EncryptAccountPasswords(cliCtx); err != nil {
+					log.Fatalf("Could not encrypt account passwords: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "encrypt-artifacts",
+			Usage: "encrypts the deposit data, withdrawal keystores, account metadata and withdrawal credentials of a " +
+				"non-HD wallet with a key encrypted with the wallet password. artifacts of accounts created afterwards " +
+				"are encrypted too",
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AllowWeakPasswordFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := EncryptArtifacts(cliCtx); err != nil {
+					log.Fatalf("Could not encrypt artifacts: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "use-master-password",
+			Usage: "re-encrypts the keystores of the accounts of a non-HD wallet with passwords derived from the wallet " +
+				"password, its master password, and removes their password files. changing the wallet password with " +
+				"wallet-v2 change-password rotates the passwords of every account",
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AllowWeakPasswordFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := UseMasterPassword(cliCtx); err != nil {
+					log.Fatalf("Could not use master password: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "generate-operator-key",
+			Usage: "writes a new key for an operator of approval policies to --operator-key-file, and prints its public " +
+				"key to configure approval policies with",
+			Flags: []cli.Flag{
+				flags.OperatorKeyFileFlag,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := GenerateOperatorKey(cliCtx); err != nil {
+					log.Fatalf("Could not generate operator key: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "set-approval-policy",
+			Usage: "makes deleting and exporting accounts of the wallet require approval tokens of --approvals-required of " +
+				"the --approval-operators. replacing an approval policy requires --approval-tokens of its operators",
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.ApprovalOperatorsFlag,
+				flags.ApprovalsRequiredFlag,
+				flags.ApprovalTokensFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := SetApprovalPolicy(cliCtx); err != nil {
+					log.Fatalf("Could not set approval policy: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "approve",
+			Usage: "signs an approval token for the --approval-action with the key of an operator, for the accounts of " +
+				"--approval-public-keys or --pubkeys-file, the snapshot of --snapshot-label, or the approval policy of " +
+				"--approval-operators. backups and conversions are approved for the whole wallet. a wallet accepts every " +
+				"approval token once only",
+			Flags: []cli.Flag{
+				flags.OperatorKeyFileFlag,
+				flags.ApprovalActionFlag,
+				flags.ApprovalPublicKeysFlag,
+				flags.PubKeysFileFlag,
+				flags.ApprovalOperatorsFlag,
+				flags.ApprovalsRequiredFlag,
+				flags.SnapshotLabelFlag,
+				flags.ApprovalValidityFlag,
+				flags.ApprovalTokenFileFlag,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := ApproveOperation(cliCtx); err != nil {
+					log.Fatalf("Could not approve operation: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "enroll-security-key",
+			Usage: "wraps the wallet password with a FIDO2 security key, so opening the wallet requires touching it on top " +
+				"of entering the wallet password. the wallet is re-encrypted with a random password only the security key " +
+				"and the wallet password unwrap. requires the fido2-cred, fido2-assert and fido2-token tools of libfido2",
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.SecurityKeyDeviceFlag,
+				flags.PasswordStdinFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := EnrollSecurityKey(cliCtx); err != nil {
+					log.Fatalf("Could not enroll security key: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "remove-security-key",
+			Usage: "re-encrypts a wallet whose password is wrapped by a security key with a new wallet password, and " +
+				"removes its security key so it is opened with the wallet password alone",
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.NewWalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AllowWeakPasswordFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := RemoveSecurityKey(cliCtx); err != nil {
+					log.Fatalf("Could not remove security key: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "enroll-totp",
+			Usage: "generates a TOTP secret for an authenticator app, whose codes are then required to export, delete, " +
+				"exit and archive the accounts of a wallet, back it up, convert it or restore a snapshot of it. the wallet " +
+				"must have a keymanager config encrypted with the wallet password, which records the enrollment",
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.TOTPCodeFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := EnrollTOTP(cliCtx); err != nil {
+					log.Fatalf("Could not enroll TOTP secret: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "remove-totp",
+			Usage: "removes the TOTP secret of a wallet, given a code of its authenticator app",
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.TOTPCodeFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := RemoveTOTP(cliCtx); err != nil {
+					log.Fatalf("Could not remove TOTP secret: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "restore",
+			Usage: "restores a wallet, its account passwords and the validator database from a backup written by " +
+				"wallet-v2 backup, or merges the accounts of a non-HD backup into an existing non-HD wallet with --merge",
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordsDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.BackupFileFlag,
+				flags.BackupPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.MergeRestoreFlag,
+				flags.ForceRestoreFlag,
+				cmd.DataDirFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := RestoreWallet(cliCtx); err != nil {
+					log.Fatalf("Could not restore wallet: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "migrate",
+			Usage: "creates a new direct wallet holding the validating keys of a v1 keymanager, such as interop, keystore, " +
+				"or unencrypted, selected with --keymanager and --keymanageropts, and verifies both hold the same public keys",
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordsDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.AccountPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				flags.AllowWeakPasswordFlag,
+				flags.KeymanagerKindFlag,
+				flags.KeyManager,
+				flags.KeyManagerOpts,
+				cmd.DataDirFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := MigrateWallet(cliCtx); err != nil {
+					log.Fatalf("Could not migrate v1 keymanager: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "consolidate-metadata",
+			Usage: "migrates the accounts of a non-HD wallet to versioned metadata documents, moving their creation " +
+				"time from created_at.txt and copying their deposit data into the account-metadata.json next to their " +
+				"keystore. account passwords stay in the passwords directory of the wallet",
+			Flags: []cli.Flag{
+				flags.WalletDirFlag,
+				flags.WalletPasswordFileFlag,
+				flags.PasswordStdinFlag,
+				featureconfig.AltonaTestnet,
+				featureconfig.OnyxTestnet,
+			},
+			Action: func(cliCtx *cli.Context) error {
+				if err := ConsolidateAccountMetadata(cliCtx); err != nil {
+					log.Fatalf("Could not consolidate account metadata: %v", err)
+				}
+				return nil
+			},
+		},
 	},
 }
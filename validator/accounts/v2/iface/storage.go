@@ -0,0 +1,36 @@
+package iface
+
+import (
+	"context"
+)
+
+// Storage keeps the files of a wallet, such as the keymanager config and the keystores of its
+// accounts, by slash separated paths relative to the accounts directory of the wallet. Storage
+// backends of other packages implement it, and are registered for the scheme of their storage
+// urls with RegisterWalletStorage of the accounts package.
+type Storage interface {
+	// ReadFile returns the contents of a file, or an error matching os.ErrNotExist if there is none.
+	ReadFile(ctx context.Context, name string) ([]byte, error)
+	// WriteFile creates or replaces a file, creating its directory if needed.
+	WriteFile(ctx context.Context, name string, data []byte) error
+	// Glob returns the names of the files of a directory matching a pattern in the syntax of
+	// path.Match, in lexical order. The root of the storage is the empty directory.
+	Glob(ctx context.Context, dir string, pattern string) ([]string, error)
+	// ListDirs returns the names of the top-level directories.
+	ListDirs(ctx context.Context) ([]string, error)
+}
+
+// RemovableStorage is storage able to remove files. Accounts are only deleted from registered
+// storage implementing it.
+type RemovableStorage interface {
+	Storage
+	// RemoveFile removes a file, doing nothing if there is none.
+	RemoveFile(ctx context.Context, name string) error
+}
+
+// TransactionalStorage is storage able to write several files at once, such that either all of
+// them are written or none of them are. The files of new accounts are written to it at once.
+type TransactionalStorage interface {
+	Storage
+	WriteFiles(ctx context.Context, files map[string][]byte) error
+}
@@ -12,12 +12,37 @@ type Wallet interface {
 	// Methods to retrieve wallet and accounts metadata.
 	AccountsDir() string
 	ListDirs() ([]string, error)
+	// Password of the wallet, which encrypts the accounts keystore of a compacted non-HD wallet.
+	Password() string
 	// Read methods for important wallet and accounts-related files.
 	ReadEncryptedSeedFromDisk(ctx context.Context) (io.ReadCloser, error)
 	ReadFileAtPath(ctx context.Context, filePath string, fileName string) ([]byte, error)
+	// FileNameAtPath returns the name of the first file of a path matching a glob pattern.
+	FileNameAtPath(ctx context.Context, filePath string, fileName string) (string, error)
 	ReadPasswordFromDisk(ctx context.Context, passwordFileName string) (string, error)
 	// Write methods to persist important wallet and accounts-related files to disk.
 	WriteFileAtPath(ctx context.Context, pathName string, fileName string, data []byte) error
+	// WriteFilesAtPath writes several files of a path by file name, all at once if the storage of
+	// the wallet supports transactions.
+	WriteFilesAtPath(ctx context.Context, pathName string, files map[string][]byte) error
 	WritePasswordToDisk(ctx context.Context, passwordFileName string, password string) error
 	WriteEncryptedSeedToDisk(ctx context.Context, encoded []byte) error
+	// Delete methods to remove accounts-related files from disk.
+	DeleteAccountFiles(ctx context.Context, accountName string, passwordFileName string) error
+}
+
+// AccountPasswordDeriver is implemented by wallets deriving the passwords of their accounts from a
+// master password, which keymanagers encrypt the keystores of new accounts with in place of the
+// passwords they are given.
+type AccountPasswordDeriver interface {
+	// DeriveAccountPassword returns the password of an account, and false if the wallet has no
+	// master password.
+	DeriveAccountPassword(accountName string) (string, bool, error)
+}
+
+// LockableWallet is implemented by wallets able to drop their wallet password from memory while
+// their keymanager is locked, which verify the password they are given again to unlock it.
+type LockableWallet interface {
+	LockPassword()
+	UnlockPassword(ctx context.Context, walletPassword string) error
 }
@@ -1,7 +1,9 @@
 package v2
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/user"
@@ -20,14 +22,29 @@ import (
 
 const (
 	importKeysDirPromptText      = "Enter the directory or filepath where your keystores to import are located"
+	lighthouseDirPromptText      = "Enter the Lighthouse validators directory to import from"
+	nimbusDirPromptText          = "Enter the Nimbus data directory to import from"
+	ethdoPassphrasePromptText    = "Passphrase of the ethdo accounts"
+	webAccountsFilePromptText    = "Enter the web wallet accounts file to import"
+	webWalletPasswordPromptText  = "Password of the web wallet"
 	exportDirPromptText          = "Enter a file location to write the exported account(s) to"
+	depositDataDirPromptText     = "Enter a directory to write the deposit data of the selected account(s) to"
+	withdrawalDirPromptText      = "Enter a directory to write the withdrawal keystores to"
+	backupDirPromptText          = "Enter a directory to write the wallet backup to"
 	walletDirPromptText          = "Enter a wallet directory"
 	passwordsDirPromptText       = "Directory where passwords will be stored"
 	newWalletPasswordPromptText  = "New wallet password"
 	confirmPasswordPromptText    = "Confirm password"
 	walletPasswordPromptText     = "Wallet password"
 	newAccountPasswordPromptText = "New account password"
+	withdrawalPasswordPromptText = "New password for the withdrawal keystore, different from the account password"
+	exportPasswordPromptText     = "New password for the exported keystores"
+	newBackupPasswordPromptText  = "New password for the wallet backup"
+	backupPasswordPromptText     = "Password of the wallet backup"
 	passwordForAccountPromptText = "Enter password for account with public key %#x"
+	// passwordEnvVarPrefix is the prefix of the environment variables passwords are read from
+	// when their password file flag is not set.
+	passwordEnvVarPrefix = "PRYSM_"
 )
 
 type passwordConfirm int
@@ -41,10 +58,14 @@ const (
 
 var au = aurora.NewAurora(true)
 
+// stdinPasswords reads the passwords of --password-stdin, one line per password in the order a
+// command asks for them. It is replaced in tests.
+var stdinPasswords = bufio.NewReader(os.Stdin)
+
 func inputDirectory(cliCtx *cli.Context, promptText string, flag *cli.StringFlag) (string, error) {
 	directory := cliCtx.String(flag.Name)
 	if cliCtx.IsSet(flag.Name) {
-		return expandPath(directory)
+		return canonicalPath(directory)
 	}
 	// Append and log the appropriate directory name depending on the flag used.
 	if flag.Name == flags.WalletDirFlag.Name {
@@ -54,7 +75,7 @@ func inputDirectory(cliCtx *cli.Context, promptText string, flag *cli.StringFlag
 		}
 		if ok {
 			log.Infof("%s %s", au.BrightMagenta("(wallet path)"), directory)
-			return directory, nil
+			return canonicalPath(directory)
 		}
 	} else if flag.Name == flags.WalletPasswordsDirFlag.Name {
 		ok, err := hasDir(directory)
@@ -63,7 +84,7 @@ func inputDirectory(cliCtx *cli.Context, promptText string, flag *cli.StringFlag
 		}
 		if ok {
 			log.Infof("%s %s", au.BrightMagenta("(account passwords path)"), directory)
-			return directory, nil
+			return canonicalPath(directory)
 		}
 	}
 
@@ -71,45 +92,42 @@ func inputDirectory(cliCtx *cli.Context, promptText string, flag *cli.StringFlag
 	if err != nil {
 		return "", err
 	}
-	if inputtedDir == directory {
-		return directory, nil
-	}
-	return expandPath(inputtedDir)
+	return canonicalPath(inputtedDir)
 }
 
+// Inputs a password of a password file flag. New passwords, which are confirmed when prompted
+// for, must pass the password strength checks unless --allow-weak-password is set, while existing
+// passwords only need to be non-empty, as they are checked by decrypting with them.
 func inputPassword(
 	cliCtx *cli.Context,
 	passwordFileFlag *cli.StringFlag,
 	promptText string,
 	confirmPassword passwordConfirm,
 ) (string, error) {
-	if cliCtx.IsSet(passwordFileFlag.Name) {
-		passwordFilePathInput := cliCtx.String(passwordFileFlag.Name)
-		passwordFilePath, err := expandPath(passwordFilePathInput)
-		if err != nil {
-			return "", errors.Wrap(err, "could not determine absolute path of password file")
-		}
-		data, err := ioutil.ReadFile(passwordFilePath)
-		if err != nil {
-			return "", errors.Wrap(err, "could not read password file")
-		}
-		enteredPassword := strings.TrimRight(string(data), "\r\n")
-		if err := promptutil.ValidatePasswordInput(enteredPassword); err != nil {
+	validatePassword := promptutil.NotEmpty
+	if confirmPassword == confirmPass {
+		validatePassword = newPasswordValidator(cliCtx)
+	}
+	password, ok, err := nonInteractivePassword(cliCtx, passwordFileFlag)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		if err := validatePassword(password); err != nil {
 			return "", errors.Wrap(err, "password did not pass validation")
 		}
-		return enteredPassword, nil
+		return password, nil
 	}
 	var hasValidPassword bool
 	var walletPassword string
-	var err error
 	for !hasValidPassword {
-		walletPassword, err = promptutil.PasswordPrompt(promptText, promptutil.ValidatePasswordInput)
+		walletPassword, err = promptutil.PasswordPrompt(promptText, validatePassword)
 		if err != nil {
 			return "", fmt.Errorf("could not read account password: %v", err)
 		}
 
 		if confirmPassword == confirmPass {
-			passwordConfirmation, err := promptutil.PasswordPrompt(confirmPasswordPromptText, promptutil.ValidatePasswordInput)
+			passwordConfirmation, err := promptutil.PasswordPrompt(confirmPasswordPromptText, promptutil.NotEmpty)
 			if err != nil {
 				return "", fmt.Errorf("could not read password confirmation: %v", err)
 			}
@@ -125,20 +143,78 @@ func inputPassword(
 	return walletPassword, nil
 }
 
-func inputWeakPassword(cliCtx *cli.Context, passwordFileFlag *cli.StringFlag, promptText string) (string, error) {
+// Returns the validation of new passwords: the length, character and zxcvbn strength checks of
+// strong passwords, or only a warning for passwords failing them if --allow-weak-password is set.
+func newPasswordValidator(cliCtx *cli.Context) func(string) error {
+	if !cliCtx.Bool(flags.AllowWeakPasswordFlag.Name) {
+		return promptutil.ValidatePasswordInput
+	}
+	return func(input string) error {
+		if err := promptutil.NotEmpty(input); err != nil {
+			return err
+		}
+		if err := promptutil.ValidatePasswordInput(input); err != nil {
+			log.WithError(err).Warnf("Using a weak password as --%s is set", flags.AllowWeakPasswordFlag.Name)
+		}
+		return nil
+	}
+}
+
+// Reads the password of a password file flag without prompting for it, from the file of the flag,
+// else from the environment variable of the flag, else from the next line of standard input if
+// --password-stdin is set. Returns false if none of them is given, so the password is prompted for.
+func nonInteractivePassword(cliCtx *cli.Context, passwordFileFlag *cli.StringFlag) (string, bool, error) {
 	if cliCtx.IsSet(passwordFileFlag.Name) {
-		passwordFilePathInput := cliCtx.String(passwordFileFlag.Name)
-		passwordFilePath, err := expandPath(passwordFilePathInput)
+		password, err := readPasswordFile(cliCtx.String(passwordFileFlag.Name))
 		if err != nil {
-			return "", errors.Wrap(err, "could not determine absolute path of password file")
+			return "", false, err
 		}
-		data, err := ioutil.ReadFile(passwordFilePath)
-		if err != nil {
-			return "", errors.Wrap(err, "could not read password file")
+		return password, true, nil
+	}
+	if password, ok := os.LookupEnv(passwordEnvVar(passwordFileFlag)); ok {
+		return strings.TrimRight(password, "\r\n"), true, nil
+	}
+	if cliCtx.Bool(flags.PasswordStdinFlag.Name) {
+		line, err := stdinPasswords.ReadString('\n')
+		if err == io.EOF && line == "" {
+			return "", false, fmt.Errorf("no password left on standard input for --%s", passwordFileFlag.Name)
+		}
+		if err != nil && err != io.EOF {
+			return "", false, errors.Wrap(err, "could not read password from standard input")
 		}
-		return strings.TrimRight(string(data), "\r\n"), nil
+		return strings.TrimRight(line, "\r\n"), true, nil
 	}
+	return "", false, nil
+}
+
+// Returns the environment variable holding the password of a password file flag, the name of the
+// flag without its -file suffix, such as PRYSM_WALLET_PASSWORD for --wallet-password-file.
+func passwordEnvVar(passwordFileFlag *cli.StringFlag) string {
+	name := strings.TrimSuffix(passwordFileFlag.Name, "-file")
+	return passwordEnvVarPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
 
+// Reads a password from a plain-text file, ignoring trailing newlines.
+func readPasswordFile(passwordFilePathInput string) (string, error) {
+	passwordFilePath, err := expandPath(passwordFilePathInput)
+	if err != nil {
+		return "", errors.Wrap(err, "could not determine absolute path of password file")
+	}
+	data, err := ioutil.ReadFile(passwordFilePath)
+	if err != nil {
+		return "", errors.Wrap(err, "could not read password file")
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func inputWeakPassword(cliCtx *cli.Context, passwordFileFlag *cli.StringFlag, promptText string) (string, error) {
+	password, ok, err := nonInteractivePassword(cliCtx, passwordFileFlag)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return password, nil
+	}
 	walletPassword, err := promptutil.PasswordPrompt(promptText, promptutil.NotEmpty)
 	if err != nil {
 		return "", fmt.Errorf("could not read account password: %v", err)
@@ -146,6 +222,21 @@ func inputWeakPassword(cliCtx *cli.Context, passwordFileFlag *cli.StringFlag, pr
 	return walletPassword, nil
 }
 
+// Inputs the password of a wallet opened outside of a wallet command, such as one of the wallets
+// loaded by a validator client, from passwordFile if given, else from the wallet password
+// environment variable, else prompting for it.
+func inputWalletPasswordOf(passwordFile string, target string) (string, error) {
+	if passwordFile != "" {
+		return readPasswordFile(passwordFile)
+	}
+	if password, ok := os.LookupEnv(passwordEnvVar(flags.WalletPasswordFileFlag)); ok {
+		return strings.TrimRight(password, "\r\n"), nil
+	}
+	return promptutil.PasswordPrompt(
+		fmt.Sprintf("%s for %s", walletPasswordPromptText, target), promptutil.NotEmpty,
+	)
+}
+
 func inputRemoteKeymanagerConfig(cliCtx *cli.Context) (*remote.Config, error) {
 	addr := cliCtx.String(flags.GrpcRemoteAddressFlag.Name)
 	crt := cliCtx.String(flags.RemoteSignerCertPathFlag.Name)
@@ -241,6 +332,37 @@ func expandPath(p string) (string, error) {
 	return filepath.Abs(path.Clean(os.ExpandEnv(p)))
 }
 
+// Returns the canonical form of the path of a wallet or passwords directory: the absolute path,
+// with ~ and environment variables expanded, through no symbolic links, so a directory given as
+// a relative path, through a symbolic link or as its target is always the same directory. Missing
+// directories are resolved from their closest existing parent.
+func canonicalPath(p string) (string, error) {
+	expanded, err := expandPath(p)
+	if err != nil {
+		return "", err
+	}
+	missing := make([]string, 0)
+	existing := expanded
+	for {
+		resolved, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			for i := len(missing) - 1; i >= 0; i-- {
+				resolved = filepath.Join(resolved, missing[i])
+			}
+			return resolved, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return expanded, nil
+		}
+		missing = append(missing, filepath.Base(existing))
+		existing = parent
+	}
+}
+
 func homeDir() string {
 	if home := os.Getenv("HOME"); home != "" {
 		return home
@@ -0,0 +1,118 @@
+package v2
+
+import (
+	"bufio"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/urfave/cli/v2"
+)
+
+func TestCanonicalPath(t *testing.T) {
+	tmpDir, err := filepath.EvalSymlinks(testutil.TempDir())
+	require.NoError(t, err)
+	dir := filepath.Join(tmpDir, t.Name())
+	t.Cleanup(func() {
+		assert.NoError(t, os.RemoveAll(dir))
+	})
+	walletDir := filepath.Join(dir, "wallet")
+	require.NoError(t, os.MkdirAll(walletDir, DirectoryPermissions))
+	link := filepath.Join(dir, "link")
+	require.NoError(t, os.Symlink(walletDir, link))
+
+	resolved, err := canonicalPath(link)
+	require.NoError(t, err)
+	assert.Equal(t, walletDir, resolved)
+	resolved, err = canonicalPath(filepath.Join(link, "missing", "direct"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(walletDir, "missing", "direct"), resolved)
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() {
+		require.NoError(t, os.Chdir(wd))
+	})
+	resolved, err = canonicalPath("link/../link/")
+	require.NoError(t, err)
+	assert.Equal(t, walletDir, resolved)
+
+	home := os.Getenv("HOME")
+	require.NoError(t, os.Setenv("HOME", link))
+	t.Cleanup(func() {
+		require.NoError(t, os.Setenv("HOME", home))
+	})
+	resolved, err = canonicalPath("~/accounts")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(walletDir, "accounts"), resolved)
+}
+
+func TestInputPassword_NonInteractive(t *testing.T) {
+	assert.Equal(t, "PRYSM_WALLET_PASSWORD", passwordEnvVar(flags.WalletPasswordFileFlag))
+	assert.Equal(t, "PRYSM_NEW_WALLET_PASSWORD", passwordEnvVar(flags.NewWalletPasswordFileFlag))
+	passwordFile := filepath.Join(testutil.TempDir(), t.Name()+".txt")
+	require.NoError(t, ioutil.WriteFile(passwordFile, []byte(password+"\n"), os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.Remove(passwordFile))
+	})
+	setupCtx := func(passwordFile string, passwordStdin bool) *cli.Context {
+		set := flag.NewFlagSet("test", 0)
+		set.String(flags.WalletPasswordFileFlag.Name, "", "")
+		set.String(flags.AccountPasswordFileFlag.Name, "", "")
+		set.Bool(flags.PasswordStdinFlag.Name, passwordStdin, "")
+		set.Bool(flags.AllowWeakPasswordFlag.Name, false, "")
+		if passwordFile != "" {
+			require.NoError(t, set.Set(flags.WalletPasswordFileFlag.Name, passwordFile))
+		}
+		return cli.NewContext(&cli.App{}, set, nil)
+	}
+
+	// The environment variable is read if the password file flag is not set.
+	require.NoError(t, os.Setenv("PRYSM_WALLET_PASSWORD", "Env1r0nmentPassw0rd!"))
+	t.Cleanup(func() {
+		require.NoError(t, os.Unsetenv("PRYSM_WALLET_PASSWORD"))
+	})
+	walletPassword, err := inputPassword(setupCtx("", false), flags.WalletPasswordFileFlag, walletPasswordPromptText, confirmPass)
+	require.NoError(t, err)
+	assert.Equal(t, "Env1r0nmentPassw0rd!", walletPassword)
+	walletPassword, err = inputPassword(setupCtx(passwordFile, false), flags.WalletPasswordFileFlag, walletPasswordPromptText, confirmPass)
+	require.NoError(t, err)
+	assert.Equal(t, password, walletPassword)
+	require.NoError(t, os.Setenv("PRYSM_WALLET_PASSWORD", "weak"))
+	_, err = inputPassword(setupCtx("", false), flags.WalletPasswordFileFlag, walletPasswordPromptText, confirmPass)
+	assert.ErrorContains(t, "password did not pass validation", err)
+
+	// Weak passwords are accepted as existing passwords, and as new passwords with --allow-weak-password.
+	walletPassword, err = inputPassword(setupCtx("", false), flags.WalletPasswordFileFlag, walletPasswordPromptText, noConfirmPass)
+	require.NoError(t, err)
+	assert.Equal(t, "weak", walletPassword)
+	cliCtx := setupCtx("", false)
+	require.NoError(t, cliCtx.Set(flags.AllowWeakPasswordFlag.Name, "true"))
+	walletPassword, err = inputPassword(cliCtx, flags.WalletPasswordFileFlag, walletPasswordPromptText, confirmPass)
+	require.NoError(t, err)
+	assert.Equal(t, "weak", walletPassword)
+
+	// Passwords of --password-stdin are read one line at a time, in the order they are asked for.
+	defaultStdin := stdinPasswords
+	stdinPasswords = bufio.NewReader(strings.NewReader("first password\r\nsecond password"))
+	t.Cleanup(func() {
+		stdinPasswords = defaultStdin
+	})
+	cliCtx = setupCtx("", true)
+	accountPassword, err := inputWeakPassword(cliCtx, flags.AccountPasswordFileFlag, newAccountPasswordPromptText)
+	require.NoError(t, err)
+	assert.Equal(t, "first password", accountPassword)
+	accountPassword, err = inputWeakPassword(cliCtx, flags.AccountPasswordFileFlag, newAccountPasswordPromptText)
+	require.NoError(t, err)
+	assert.Equal(t, "second password", accountPassword)
+	_, err = inputWeakPassword(cliCtx, flags.AccountPasswordFileFlag, newAccountPasswordPromptText)
+	assert.ErrorContains(t, "no password left on standard input", err)
+}
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"io"
 	"io/ioutil"
+	"path"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -18,6 +20,8 @@ type Wallet struct {
 	EncryptedSeedFile []byte
 	AccountPasswords  map[string]string
 	UnlockAccounts    bool
+	WalletPassword    string
+	lockedPassword    string
 	lock              sync.RWMutex
 }
 
@@ -42,6 +46,26 @@ func (m *Wallet) ListDirs() ([]string, error) {
 	return m.Directories, nil
 }
 
+// Password --
+func (m *Wallet) Password() string {
+	return m.WalletPassword
+}
+
+// LockPassword --
+func (m *Wallet) LockPassword() {
+	m.lockedPassword = m.WalletPassword
+	m.WalletPassword = ""
+}
+
+// UnlockPassword --
+func (m *Wallet) UnlockPassword(ctx context.Context, walletPassword string) error {
+	if walletPassword != m.lockedPassword {
+		return errors.New("could not decrypt with the wallet password")
+	}
+	m.WalletPassword = walletPassword
+	return nil
+}
+
 // WritePasswordToDisk --
 func (m *Wallet) WritePasswordToDisk(ctx context.Context, passwordFileName string, password string) error {
 	m.lock.Lock()
@@ -73,18 +97,50 @@ func (m *Wallet) WriteFileAtPath(ctx context.Context, pathName string, fileName
 	return nil
 }
 
+// WriteFilesAtPath --
+func (m *Wallet) WriteFilesAtPath(ctx context.Context, pathName string, files map[string][]byte) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.Files[pathName] == nil {
+		m.Files[pathName] = make(map[string][]byte)
+	}
+	for fileName, data := range files {
+		m.Files[pathName][fileName] = data
+	}
+	return nil
+}
+
 // ReadFileAtPath --
 func (m *Wallet) ReadFileAtPath(ctx context.Context, pathName string, fileName string) ([]byte, error) {
 	m.lock.RLock()
 	defer m.lock.RUnlock()
 	for f, v := range m.Files[pathName] {
-		if strings.Contains(fileName, f) {
+		if ok, err := path.Match(fileName, f); err == nil && ok || strings.Contains(fileName, f) {
 			return v, nil
 		}
 	}
 	return nil, errors.New("file not found")
 }
 
+// FileNameAtPath --
+func (m *Wallet) FileNameAtPath(ctx context.Context, pathName string, fileName string) (string, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	matches := make([]string, 0)
+	for f := range m.Files[pathName] {
+		if ok, err := path.Match(fileName, f); err != nil {
+			return "", err
+		} else if ok {
+			matches = append(matches, f)
+		}
+	}
+	if len(matches) == 0 {
+		return "", errors.New("file not found")
+	}
+	sort.Strings(matches)
+	return matches[0], nil
+}
+
 // ReadEncryptedSeedFromDisk --
 func (m *Wallet) ReadEncryptedSeedFromDisk(ctx context.Context) (io.ReadCloser, error) {
 	m.lock.Lock()
@@ -99,3 +155,21 @@ func (m *Wallet) WriteEncryptedSeedToDisk(ctx context.Context, encoded []byte) e
 	m.EncryptedSeedFile = encoded
 	return nil
 }
+
+// DeleteAccountFiles --
+func (m *Wallet) DeleteAccountFiles(ctx context.Context, accountName string, passwordFileName string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.Files[accountName]; !ok {
+		return errors.New("account not found")
+	}
+	delete(m.Files, accountName)
+	delete(m.AccountPasswords, passwordFileName)
+	for i, dir := range m.Directories {
+		if dir == accountName {
+			m.Directories = append(m.Directories[:i], m.Directories[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
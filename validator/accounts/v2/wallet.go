@@ -1,13 +1,17 @@
 package v2
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -17,6 +21,7 @@ import (
 	"github.com/logrusorgru/aurora"
 	"github.com/pkg/errors"
 	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
 	"github.com/prysmaticlabs/prysm/validator/flags"
 	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
 	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/derived"
@@ -31,8 +36,27 @@ import (
 const (
 	// KeymanagerConfigFileName for the keymanager used by the wallet: direct, derived, or remote.
 	KeymanagerConfigFileName = "keymanageropts.json"
-	// DirectoryPermissions for directories created under the wallet path.
-	DirectoryPermissions = os.ModePerm
+	// DirectoryPermissions for directories created under the wallet path, accessible by their
+	// owner only.
+	DirectoryPermissions = 0700
+	// FilePermissions for files written under the wallet path and account passwords, readable and
+	// writable by their owner only.
+	FilePermissions = 0600
+	// archiveDirName is the directory in the accounts directory of a non-HD wallet holding its
+	// archived accounts, which are not listed as accounts of the wallet and never decrypted.
+	archiveDirName = "archive"
+	// tombstoneDirName is the directory in the accounts directory of a non-HD wallet keeping the
+	// slashing protection history of its deleted accounts.
+	tombstoneDirName = "tombstones"
+	// trashDirName is the directory in the accounts directory of a non-HD wallet its deleted
+	// accounts are moved to, until they are restored or their retention period expires.
+	trashDirName = ".trash"
+	// trashEntryNameFormat names the directory in the trash holding the files of a deleted
+	// account, by unix deletion time in nanoseconds and account name.
+	trashEntryNameFormat = "%d-%s"
+	// tmpFileSuffix ends the names of the temporary files wallet files are written to before
+	// they are renamed over the files they replace.
+	tmpFileSuffix = ".tmp"
 )
 
 var (
@@ -44,6 +68,8 @@ var (
 	ErrWalletExists = errors.New("you already have a wallet at the specified path. You can " +
 		"edit your wallet configuration by running ./prysm.sh validator wallet-v2 edit-config",
 	)
+	// ErrWalletReadOnly is returned by every operation writing to a wallet opened read-only.
+	ErrWalletReadOnly        = errors.New("wallet is read-only, it was opened with --wallet-read-only")
 	keymanagerKindSelections = map[v2keymanager.Kind]string{
 		v2keymanager.Derived: "HD Wallet (Recommended)",
 		v2keymanager.Direct:  "Non-HD Wallet (Most Basic)",
@@ -61,6 +87,42 @@ type Wallet struct {
 	passwordsDir   string
 	keymanagerKind v2keymanager.Kind
 	walletPassword string
+	// encryptedConfig is true if the keymanager config file is
+	// encrypted on disk using the wallet password.
+	encryptedConfig bool
+	// storageURL selects the object storage the files of the wallet are
+	// kept in, they are kept in the accounts path if it is empty.
+	storageURL string
+	storage    walletStorage
+	// passwords are the account passwords of a wallet kept in memory or
+	// of its password store, by password file name, nil for wallets with
+	// password files in their passwords directory.
+	passwords map[string]string
+	// passwordStore is true if the account passwords of a non-HD wallet
+	// are kept in its password store, encrypted with the wallet password.
+	passwordStore bool
+	// masterPasswordSalt is the salt the passwords of the accounts of a
+	// non-HD wallet are derived with from the wallet password, nil for
+	// wallets without a master password.
+	masterPasswordSalt []byte
+	// artifactKey is the key the deposit data and withdrawal artifacts of
+	// a non-HD wallet are encrypted with, nil if they are in plaintext.
+	artifactKey []byte
+	// kubeSecrets are the Kubernetes Secrets a wallet kept in memory
+	// is loaded from, if any.
+	kubeSecrets *kubernetesSecrets
+	// retryPolicy is how storage operations failing with transient errors
+	// are retried, the default policy if nil.
+	retryPolicy *storageRetryPolicy
+	// readOnly is true if every write to the wallet fails, such as
+	// for a standby validator client.
+	readOnly bool
+	// manifestKey signs the manifest of the files of the wallet, which
+	// is only kept if it is set.
+	manifestKey []byte
+	// keystoreNameFormat is the file name format of the keystores of
+	// a non-HD wallet from its keymanager config, the default if empty.
+	keystoreNameFormat string
 }
 
 func init() {
@@ -96,7 +158,17 @@ func NewWallet(
 		keymanagerKind: keymanagerKind,
 		walletDir:      walletDir,
 	}
-	if keymanagerKind == v2keymanager.Derived {
+	if storageURL := cliCtx.String(flags.WalletStorageURLFlag.Name); storageURL != "" {
+		storage, err := openWalletStorage(accountsPath, storageURL)
+		if err != nil {
+			return nil, err
+		}
+		w.storageURL = storageURL
+		w.storage = storage
+	}
+	// Encrypted wallets need a wallet password to encrypt their container.
+	encrypted, encryptedWallet := w.storage.(*encryptedStorage)
+	if keymanagerKind == v2keymanager.Derived || encryptedWallet {
 		walletPassword, err := inputPassword(
 			cliCtx,
 			flags.WalletPasswordFileFlag,
@@ -108,6 +180,28 @@ func NewWallet(
 		}
 		w.walletPassword = walletPassword
 	}
+	if cliCtx.Bool(flags.EncryptKeymanagerConfigFlag.Name) {
+		// Derived wallets already have a wallet password, other wallets
+		// only need one to protect their keymanager config file.
+		if w.walletPassword == "" {
+			walletPassword, err := inputPassword(
+				cliCtx,
+				flags.WalletPasswordFileFlag,
+				newWalletPasswordPromptText,
+				confirmPass,
+			)
+			if err != nil {
+				return nil, errors.Wrap(err, "could not get password")
+			}
+			w.walletPassword = walletPassword
+		}
+		w.encryptedConfig = true
+	}
+	if encryptedWallet {
+		if err := encrypted.unlock(w.walletPassword); err != nil {
+			return nil, err
+		}
+	}
 	if keymanagerKind == v2keymanager.Direct {
 		passwordsDir, err := inputDirectory(cliCtx, passwordsDirPromptText, flags.WalletPasswordsDirFlag)
 		if err != nil {
@@ -115,6 +209,11 @@ func NewWallet(
 		}
 		w.passwordsDir = passwordsDir
 	}
+	if cliCtx.IsSet(flags.WalletManifestKeyFileFlag.Name) {
+		if err := w.SetManifestKeyFile(cliCtx.String(flags.WalletManifestKeyFileFlag.Name)); err != nil {
+			return nil, err
+		}
+	}
 	return w, nil
 }
 
@@ -127,6 +226,67 @@ func OpenWallet(cliCtx *cli.Context) (*Wallet, error) {
 	if err != nil {
 		return nil, err
 	}
+	w, err := openWallet(walletDir, cliCtx.Bool(flags.WalletReadOnlyFlag.Name), func() (string, error) {
+		return inputPassword(cliCtx, flags.WalletPasswordFileFlag, walletPasswordPromptText, noConfirmPass)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cliCtx.IsSet(flags.WalletManifestKeyFileFlag.Name) {
+		if err := w.SetManifestKeyFile(cliCtx.String(flags.WalletManifestKeyFileFlag.Name)); err != nil {
+			return nil, err
+		}
+	}
+	if w.keymanagerKind == v2keymanager.Direct {
+		// If the user provided a flag and for the password directory, and that value does not match
+		// the wallet's configuration then log a warning to the user.
+		// See https://github.com/prysmaticlabs/prysm/issues/6794.
+		if cliCtx.IsSet(flags.WalletPasswordsDirFlag.Name) {
+			passwordsDir, err := canonicalPath(cliCtx.String(flags.WalletPasswordsDirFlag.Name))
+			if err != nil {
+				return nil, errors.Wrap(err, "could not parse passwords directory")
+			}
+			if passwordsDir != w.passwordsDir {
+				log.Warnf("The provided value for --%s does not match the wallet configuration. "+
+					"Please edit your wallet password directory using wallet-v2 edit-config.",
+					flags.WalletPasswordsDirFlag.Name,
+				)
+				w.passwordsDir = passwordsDir // Override config value.
+			}
+		}
+		au := aurora.NewAurora(true)
+		log.Infof("%s %s", au.BrightMagenta("(account passwords path)"), w.passwordsDir)
+	}
+	log.Info("Successfully opened wallet")
+	return w, nil
+}
+
+// OpenWalletAtPath opens the wallet of a given directory, such as one of several wallets
+// loaded by a validator client. If the wallet requires a password, it is read from
+// passwordFile, or prompted for if no password file is given. Every write to a wallet opened
+// read-only fails with ErrWalletReadOnly.
+func OpenWalletAtPath(walletDir string, passwordFile string, readOnly bool) (*Wallet, error) {
+	walletDir, err := canonicalPath(walletDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse wallet directory")
+	}
+	w, err := openWallet(walletDir, readOnly, func() (string, error) {
+		return inputWalletPasswordOf(passwordFile, walletDir)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if w.keymanagerKind == v2keymanager.Direct {
+		au := aurora.NewAurora(true)
+		log.Infof("%s %s", au.BrightMagenta("(account passwords path)"), w.passwordsDir)
+	}
+	log.Info("Successfully opened wallet")
+	return w, nil
+}
+
+// Opens the wallet of a directory, inputting the wallet password only if the wallet
+// requires one.
+func openWallet(walletDir string, readOnly bool, inputWalletPassword func() (string, error)) (*Wallet, error) {
 	ok, err := hasDir(walletDir)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not parse wallet directory")
@@ -151,52 +311,142 @@ func OpenWallet(cliCtx *cli.Context) (*Wallet, error) {
 		walletDir:      walletDir,
 		accountsPath:   walletPath,
 		keymanagerKind: keymanagerKind,
+		readOnly:       readOnly,
 	}
 	log.Infof("%s %s", au.BrightMagenta("(wallet directory)"), w.walletDir)
-	if keymanagerKind == v2keymanager.Derived {
-		walletPassword, err := inputPassword(
-			cliCtx,
-			flags.WalletPasswordFileFlag,
-			walletPasswordPromptText,
-			noConfirmPass,
-		)
+	storageCfg, storage, err := readWalletStorageConfig(walletPath)
+	if err != nil {
+		return nil, err
+	}
+	if storageCfg != nil {
+		w.storageURL = storageCfg.URL
+		w.storage = storage
+		log.Infof("%s %s", au.BrightMagenta("(wallet storage)"), w.storageURL)
+	}
+	if w.hasSecurityKey() {
+		inputWalletPassword = unwrapWalletPassword(walletPath, inputWalletPassword)
+	}
+	if err := w.readConfig(inputWalletPassword); err != nil {
+		return nil, err
+	}
+	if err := w.migrateWalletLayout(context.Background()); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Reads the configuration of the wallet from its storage, inputting the wallet password only if
+// the storage, the keymanager config or the accounts of the wallet are encrypted with it.
+func (w *Wallet) readConfig(inputWalletPassword func() (string, error)) error {
+	// Encrypted wallets are decrypted before anything else is read from them.
+	if encrypted, ok := w.storage.(*encryptedStorage); ok {
+		walletPassword, err := inputWalletPassword()
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if err := encrypted.unlock(walletPassword); err != nil {
+			return err
 		}
 		w.walletPassword = walletPassword
 	}
-	if keymanagerKind == v2keymanager.Direct {
+	if w.keymanagerKind == v2keymanager.Derived && w.walletPassword == "" {
+		walletPassword, err := inputWalletPassword()
+		if err != nil {
+			return err
+		}
+		w.walletPassword = walletPassword
+	}
+	encryptedConfig, err := w.hasEncryptedKeymanagerConfig()
+	if err != nil {
+		return errors.Wrap(err, "could not read keymanager config")
+	}
+	if encryptedConfig && w.walletPassword == "" {
+		walletPassword, err := inputWalletPassword()
+		if err != nil {
+			return err
+		}
+		w.walletPassword = walletPassword
+	}
+	w.encryptedConfig = encryptedConfig
+	if w.keymanagerKind == v2keymanager.Direct {
 		keymanagerCfg, err := w.ReadKeymanagerConfigFromDisk(context.Background())
 		if err != nil {
-			return nil, err
+			return err
 		}
-		directCfg, err := direct.UnmarshalConfigFile(keymanagerCfg)
+		directCfg, err := direct.UnmarshalConfigFile(keymanagerCfg, w.walletPassword)
 		if err != nil {
-			return nil, err
+			return err
 		}
+		w.keystoreNameFormat = directCfg.KeystoreFileNameFormat
 		w.passwordsDir = directCfg.AccountPasswordsDirectory
-		// If the user provided a flag and for the password directory, and that value does not match
-		// the wallet's configuration then log a warning to the user.
-		// See https://github.com/prysmaticlabs/prysm/issues/6794.
-		if cliCtx.IsSet(flags.WalletPasswordsDirFlag.Name) && cliCtx.String(flags.WalletPasswordsDirFlag.Name) != w.passwordsDir {
-			log.Warnf("The provided value for --%s does not match the wallet configuration. "+
-				"Please edit your wallet password directory using wallet-v2 edit-config.",
-				flags.WalletPasswordsDirFlag.Name,
-			)
-			w.passwordsDir = cliCtx.String(flags.WalletPasswordsDirFlag.Name) // Override config value.
+		if w.passwordsDir != "" {
+			// Configs written before directories were canonicalized may hold relative paths or
+			// paths through symbolic links.
+			w.passwordsDir, err = canonicalPath(w.passwordsDir)
+			if err != nil {
+				return errors.Wrap(err, "could not parse passwords directory of keymanager config")
+			}
+		}
+		if fileExists(w.artifactKeyPath()) && w.walletPassword == "" {
+			walletPassword, err := inputWalletPassword()
+			if err != nil {
+				return err
+			}
+			w.walletPassword = walletPassword
+		}
+		if err := w.readArtifactKey(); err != nil {
+			return err
+		}
+		if err := w.readMasterPassword(inputWalletPassword); err != nil {
+			return err
+		}
+		if w.passwords == nil && w.passwordsDir != "" && fileExists(w.passwordStorePath()) {
+			if w.walletPassword == "" {
+				walletPassword, err := inputWalletPassword()
+				if err != nil {
+					return err
+				}
+				w.walletPassword = walletPassword
+			}
+			w.passwords, err = w.readPasswordStore()
+			if err != nil {
+				return err
+			}
+			w.passwordStore = true
+		}
+		// The accounts of compacted wallets are decrypted with the wallet password.
+		_, err = w.files().readFile(context.Background(), direct.AccountsKeystoreFileName)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "could not read accounts keystore")
+		}
+		if err == nil && w.walletPassword == "" {
+			walletPassword, err := inputWalletPassword()
+			if err != nil {
+				return err
+			}
+			w.walletPassword = walletPassword
 		}
-		au := aurora.NewAurora(true)
-		log.Infof("%s %s", au.BrightMagenta("(account passwords path)"), w.passwordsDir)
 	}
-	log.Info("Successfully opened wallet")
-	return w, nil
+	return nil
 }
 
 // SaveWallet persists the wallet's directories to disk.
 func (w *Wallet) SaveWallet() error {
+	if err := w.checkWritable(); err != nil {
+		return err
+	}
 	if err := os.MkdirAll(w.accountsPath, DirectoryPermissions); err != nil {
 		return errors.Wrap(err, "could not create wallet directory")
 	}
+	if w.storageURL != "" {
+		if err := writeWalletStorageConfig(w.accountsPath, &walletStorageConfig{URL: w.storageURL}); err != nil {
+			return err
+		}
+	}
+	// Wallets are saved when they are created, or after they were opened and migrated.
+	if err := w.writeWalletLayoutVersion(context.Background(), currentWalletLayoutVersion()); err != nil {
+		return err
+	}
 	if w.keymanagerKind == v2keymanager.Direct {
 		if err := os.MkdirAll(w.passwordsDir, DirectoryPermissions); err != nil {
 			return errors.Wrap(err, "could not create passwords directory")
@@ -215,6 +465,136 @@ func (w *Wallet) AccountsDir() string {
 	return w.accountsPath
 }
 
+// PasswordsDir for the account passwords of a non-HD wallet.
+func (w *Wallet) PasswordsDir() string {
+	return w.passwordsDir
+}
+
+// Password of the wallet, empty for non-HD wallets without an encrypted keymanager config, storage
+// or accounts keystore.
+func (w *Wallet) Password() string {
+	return w.walletPassword
+}
+
+// LockPassword drops the wallet password from memory while the keymanager of the wallet is locked,
+// until UnlockPassword is called with it again. Go strings cannot be overwritten, so the password
+// is released rather than wiped.
+func (w *Wallet) LockPassword() {
+	w.walletPassword = ""
+}
+
+// UnlockPassword verifies a wallet password by decrypting a file of the wallet encrypted with it,
+// and keeps it in memory again once it does.
+func (w *Wallet) UnlockPassword(ctx context.Context, walletPassword string) error {
+	if err := w.verifyPassword(ctx, walletPassword); err != nil {
+		return err
+	}
+	w.walletPassword = walletPassword
+	return nil
+}
+
+// Verifies a wallet password by decrypting the first file of the wallet encrypted with it, the
+// container of an encrypted wallet, the seed of an HD wallet, an encrypted keymanager config, the
+// accounts keystore, the password store, the artifact key or the master password file.
+func (w *Wallet) verifyPassword(ctx context.Context, walletPassword string) error {
+	if encrypted, ok := w.storage.(*encryptedStorage); ok {
+		_, err := encrypted.load(walletPassword)
+		return err
+	}
+	storage := w.files()
+	if w.keymanagerKind == v2keymanager.Derived {
+		enc, err := storage.readFile(ctx, derived.EncryptedSeedFileName)
+		if err != nil {
+			return errors.Wrap(err, "could not read seed configuration")
+		}
+		seedCfg := &derived.SeedConfig{}
+		if err := json.Unmarshal(enc, seedCfg); err != nil {
+			return errors.Wrap(err, "could not unmarshal seed configuration")
+		}
+		return decryptWithPassword(seedCfg.Crypto, walletPassword)
+	}
+	if w.encryptedConfig {
+		enc, err := storage.readFile(ctx, KeymanagerConfigFileName)
+		if err != nil {
+			return errors.Wrap(err, "could not read keymanager config")
+		}
+		if _, err := v2keymanager.DecryptConfig(enc, walletPassword); err != nil {
+			return errors.Wrap(err, "could not decrypt keymanager config with the wallet password")
+		}
+		return nil
+	}
+	enc, err := storage.readFile(ctx, direct.AccountsKeystoreFileName)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "could not read accounts keystore")
+	}
+	if err == nil {
+		accountsKeystore := &direct.AccountsKeystore{}
+		if err := json.Unmarshal(enc, accountsKeystore); err != nil {
+			return errors.Wrap(err, "could not decode accounts keystore")
+		}
+		return decryptWithPassword(accountsKeystore.Crypto, walletPassword)
+	}
+	if w.passwordStore {
+		enc, err := ioutil.ReadFile(w.passwordStorePath())
+		if err != nil {
+			return errors.Wrapf(err, "could not read %s", w.passwordStorePath())
+		}
+		_, err = decryptPasswordStore(enc, walletPassword)
+		return err
+	}
+	if w.artifactKey != nil {
+		enc, err := ioutil.ReadFile(w.artifactKeyPath())
+		if err != nil {
+			return errors.Wrapf(err, "could not read %s", w.artifactKeyPath())
+		}
+		key, err := decryptArtifactKeyFile(enc, walletPassword)
+		if err != nil {
+			return err
+		}
+		v2keymanager.ZeroSecret(key)
+		return nil
+	}
+	if w.masterPasswordSalt != nil {
+		enc, err := storage.readFile(ctx, masterPasswordFileName)
+		if err != nil {
+			return errors.Wrapf(err, "could not read %s", masterPasswordFileName)
+		}
+		salt, err := decryptMasterPasswordFile(enc, walletPassword)
+		if err != nil {
+			return err
+		}
+		v2keymanager.ZeroSecret(salt)
+		return nil
+	}
+	return errNoWalletPassword
+}
+
+// Decrypts the crypto fields of a keystore with the wallet password, wiping the decrypted secret.
+func decryptWithPassword(cryptoFields map[string]interface{}, walletPassword string) error {
+	secret, err := keystorev4.New().Decrypt(cryptoFields, walletPassword)
+	if err != nil {
+		return errors.Wrap(err, "could not decrypt with the wallet password")
+	}
+	v2keymanager.ZeroSecret(secret)
+	return nil
+}
+
+// Returns the storage the files of the wallet are kept in.
+func (w *Wallet) files() walletStorage {
+	storage := w.storage
+	if storage == nil {
+		storage = &diskStorage{root: w.accountsPath}
+	}
+	storage = withStorageRetries(storage, w.storageRetries())
+	if w.readOnly {
+		storage = withReadOnly(storage)
+	}
+	if w.artifactKey != nil {
+		storage = withArtifactEncryption(storage, w.artifactKey)
+	}
+	return storage
+}
+
 // InitializeKeymanager reads a keymanager config from disk at the wallet path,
 // unmarshals it based on the wallet's keymanager kind, and returns its value.
 func (w *Wallet) InitializeKeymanager(
@@ -228,7 +608,7 @@ func (w *Wallet) InitializeKeymanager(
 	var keymanager v2keymanager.IKeymanager
 	switch w.KeymanagerKind() {
 	case v2keymanager.Direct:
-		cfg, err := direct.UnmarshalConfigFile(configFile)
+		cfg, err := direct.UnmarshalConfigFile(configFile, w.walletPassword)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not unmarshal keymanager config file")
 		}
@@ -237,7 +617,7 @@ func (w *Wallet) InitializeKeymanager(
 			return nil, errors.Wrap(err, "could not initialize direct keymanager")
 		}
 	case v2keymanager.Derived:
-		cfg, err := derived.UnmarshalConfigFile(configFile)
+		cfg, err := derived.UnmarshalConfigFile(configFile, w.walletPassword)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not unmarshal keymanager config file")
 		}
@@ -246,14 +626,20 @@ func (w *Wallet) InitializeKeymanager(
 			return nil, errors.Wrap(err, "could not initialize derived keymanager")
 		}
 	case v2keymanager.Remote:
-		cfg, err := remote.UnmarshalConfigFile(configFile)
+		cfg, err := remote.UnmarshalConfigFile(configFile, w.walletPassword)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not unmarshal keymanager config file")
 		}
-		keymanager, err = remote.NewKeymanager(ctx, 100000000, cfg)
+		km, err := remote.NewKeymanager(ctx, 100000000, cfg)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not initialize remote keymanager")
 		}
+		accounts, err := w.remoteAccountsByPubKey()
+		if err != nil {
+			return nil, err
+		}
+		km.SetAccounts(accounts)
+		keymanager = km
 	default:
 		return nil, fmt.Errorf("keymanager kind not supported: %s", w.keymanagerKind)
 	}
@@ -262,45 +648,49 @@ func (w *Wallet) InitializeKeymanager(
 
 // ListDirs in wallet accounts path.
 func (w *Wallet) ListDirs() ([]string, error) {
-	accountsDir, err := os.Open(w.AccountsDir())
+	ctx := context.Background()
+	list, err := w.files().listDirs(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		if err := accountsDir.Close(); err != nil {
-			log.WithField(
-				"directory", w.AccountsDir(),
-			).Errorf("Could not close accounts directory: %v", err)
+	var compacted map[string]bool
+	if w.keymanagerKind == v2keymanager.Direct {
+		compacted, err = w.compactedAccountNames(ctx)
+		if err != nil {
+			return nil, err
 		}
-	}()
-
-	list, err := accountsDir.Readdirnames(0) // 0 to read all files and folders.
-	if err != nil {
-		return nil, errors.Wrapf(err, "could not read files in directory: %s", w.AccountsDir())
 	}
-	dirNames := make([]string, 0)
+	dirNames := make([]string, 0, len(list))
 	for _, item := range list {
-		ok, err := hasDir(filepath.Join(w.AccountsDir(), item))
-		if err != nil {
-			return nil, errors.Wrapf(err, "could not parse directory: %v", err)
+		if item == archiveDirName || item == tombstoneDirName || strings.HasPrefix(item, ".") {
+			continue
 		}
-		if ok {
-			dirNames = append(dirNames, item)
+		// Directories of non-HD wallets without a keystore, such as those left behind by an
+		// interrupted account creation, are not accounts. They are reported by wallet-v2 gc.
+		if w.keymanagerKind == v2keymanager.Direct && !compacted[item] {
+			keystores, err := w.files().glob(ctx, item, w.keystoreFileGlob())
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not read directory %s", item)
+			}
+			if len(keystores) == 0 {
+				log.WithField("directory", item).Debug("Skipping directory of the wallet without a keystore")
+				continue
+			}
 		}
+		dirNames = append(dirNames, item)
 	}
 	return dirNames, nil
 }
 
 // WriteFileAtPath within the wallet directory given the desired path, filename, and raw data.
 func (w *Wallet) WriteFileAtPath(ctx context.Context, filePath string, fileName string, data []byte) error {
-	accountPath := filepath.Join(w.accountsPath, filePath)
-	if err := os.MkdirAll(accountPath, os.ModePerm); err != nil {
-		return errors.Wrapf(err, "could not create path: %s", accountPath)
-	}
-	fullPath := filepath.Join(accountPath, fileName)
-	if err := ioutil.WriteFile(fullPath, data, os.ModePerm); err != nil {
+	fullPath := path.Join(filepath.ToSlash(filePath), fileName)
+	if err := w.files().writeFile(ctx, fullPath, data); err != nil {
 		return errors.Wrapf(err, "could not write %s", filePath)
 	}
+	if err := w.recordMutation(ctx, journalWrites(ctx, map[string][]byte{fullPath: data})); err != nil {
+		return err
+	}
 	log.WithFields(logrus.Fields{
 		"path":     fullPath,
 		"fileName": fileName,
@@ -308,21 +698,49 @@ func (w *Wallet) WriteFileAtPath(ctx context.Context, filePath string, fileName
 	return nil
 }
 
+// WriteFilesAtPath within the wallet directory given the desired path and the raw data of the files
+// by filename. Wallets stored in a database write all of the files in a single transaction, or none
+// of them.
+func (w *Wallet) WriteFilesAtPath(ctx context.Context, filePath string, files map[string][]byte) error {
+	fullPaths := make(map[string][]byte, len(files))
+	for fileName, data := range files {
+		fullPaths[path.Join(filepath.ToSlash(filePath), fileName)] = data
+	}
+	if storage, ok := w.files().(transactionalStorage); ok {
+		if err := storage.writeFiles(ctx, fullPaths); err != nil {
+			return errors.Wrapf(err, "could not write %s", filePath)
+		}
+	} else {
+		// Write in a fixed order, so a failed write leaves the same files behind every time.
+		fileNames := make([]string, 0, len(files))
+		for fileName := range files {
+			fileNames = append(fileNames, fileName)
+		}
+		sort.Strings(fileNames)
+		for _, fileName := range fileNames {
+			fullPath := path.Join(filepath.ToSlash(filePath), fileName)
+			if err := w.files().writeFile(ctx, fullPath, files[fileName]); err != nil {
+				return errors.Wrapf(err, "could not write %s", filePath)
+			}
+		}
+	}
+	if err := w.recordMutation(ctx, journalWrites(ctx, fullPaths)); err != nil {
+		return err
+	}
+	log.WithFields(logrus.Fields{
+		"path":  filePath,
+		"files": len(files),
+	}).Debug("Wrote new files at path")
+	return nil
+}
+
 // ReadFileAtPath within the wallet directory given the desired path and filename.
 func (w *Wallet) ReadFileAtPath(ctx context.Context, filePath string, fileName string) ([]byte, error) {
-	accountPath := filepath.Join(w.accountsPath, filePath)
-	if err := os.MkdirAll(accountPath, os.ModePerm); err != nil {
-		return nil, errors.Wrapf(err, "could not create path: %s", accountPath)
-	}
-	fullPath := filepath.Join(accountPath, fileName)
-	matches, err := filepath.Glob(fullPath)
+	fullFileName, err := w.FileNameAtPath(ctx, filePath, fileName)
 	if err != nil {
-		return []byte{}, errors.Wrap(err, "could not find file")
-	}
-	if len(matches) == 0 {
-		return []byte{}, fmt.Errorf("no files found %s", fullPath)
+		return []byte{}, err
 	}
-	rawData, err := ioutil.ReadFile(matches[0])
+	rawData, err := w.files().readFile(ctx, path.Join(filepath.ToSlash(filePath), fullFileName))
 	if err != nil {
 		return nil, errors.Wrapf(err, "could not read %s", filePath)
 	}
@@ -332,20 +750,14 @@ func (w *Wallet) ReadFileAtPath(ctx context.Context, filePath string, fileName s
 // FileNameAtPath return the full file name for the requested file. It allows for finding the file
 // with a regex pattern.
 func (w *Wallet) FileNameAtPath(ctx context.Context, filePath string, fileName string) (string, error) {
-	accountPath := filepath.Join(w.accountsPath, filePath)
-	if err := os.MkdirAll(accountPath, os.ModePerm); err != nil {
-		return "", errors.Wrapf(err, "could not create path: %s", accountPath)
-	}
-	fullPath := filepath.Join(accountPath, fileName)
-	matches, err := filepath.Glob(fullPath)
+	matches, err := w.files().glob(ctx, filepath.ToSlash(filePath), fileName)
 	if err != nil {
 		return "", errors.Wrap(err, "could not find file")
 	}
 	if len(matches) == 0 {
-		return "", fmt.Errorf("no files found %s", fullPath)
+		return "", fmt.Errorf("no files found %s", filepath.Join(w.accountsPath, filePath, fileName))
 	}
-	fullFileName := filepath.Base(matches[0])
-	return fullFileName, nil
+	return matches[0], nil
 }
 
 // AccountTimestamp retrieves the timestamp from a given keystore file name.
@@ -364,54 +776,133 @@ func AccountTimestamp(fileName string) (time.Time, error) {
 	return unixTimestamp, nil
 }
 
+// Returns the file name format of the keystores of the accounts of a non-HD wallet.
+func (w *Wallet) keystoreFileNameFormat() string {
+	if w.keystoreNameFormat == "" {
+		return direct.KeystoreFileNameFormat
+	}
+	return w.keystoreNameFormat
+}
+
+// Returns the glob pattern matching the keystores of the accounts of a non-HD wallet.
+func (w *Wallet) keystoreFileGlob() string {
+	return direct.KeystoreFileGlob(w.keystoreNameFormat)
+}
+
+// Returns the creation time of an account from the name of its keystore file.
+func (w *Wallet) keystoreFileTimestamp(fileName string) (time.Time, error) {
+	if w.keystoreNameFormat == "" {
+		return AccountTimestamp(fileName)
+	}
+	i := strings.Index(w.keystoreNameFormat, "%d")
+	prefix, suffix := w.keystoreNameFormat[:i], w.keystoreNameFormat[i+len("%d"):]
+	if len(fileName) < len(prefix)+len(suffix) || !strings.HasPrefix(fileName, prefix) || !strings.HasSuffix(fileName, suffix) {
+		return time.Unix(0, 0), fmt.Errorf("file name %s is not of keystore file name format %s", fileName, w.keystoreNameFormat)
+	}
+	unixTimestamp, err := strconv.ParseInt(fileName[len(prefix):len(fileName)-len(suffix)], 10, 64)
+	if err != nil {
+		return time.Unix(0, 0), errors.Wrapf(err, "could not parse account created at timestamp: %s", fileName)
+	}
+	return time.Unix(unixTimestamp, 0), nil
+}
+
 // ReadKeymanagerConfigFromDisk opens a keymanager config file
 // for reading if it exists at the wallet path.
 func (w *Wallet) ReadKeymanagerConfigFromDisk(ctx context.Context) (io.ReadCloser, error) {
-	configFilePath := filepath.Join(w.accountsPath, KeymanagerConfigFileName)
-	if !fileExists(configFilePath) {
+	enc, err := w.files().readFile(ctx, KeymanagerConfigFileName)
+	if errors.Is(err, os.ErrNotExist) {
 		return nil, fmt.Errorf("no keymanager config file found at path: %s", w.accountsPath)
 	}
-	return os.Open(configFilePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read %s", KeymanagerConfigFileName)
+	}
+	return ioutil.NopCloser(bytes.NewReader(enc)), nil
 }
 
 // WriteKeymanagerConfigToDisk takes an encoded keymanager config file
-// and writes it to the wallet path.
+// and writes it to the wallet path, encrypting it with the wallet password
+// if the wallet uses an encrypted keymanager config.
 func (w *Wallet) WriteKeymanagerConfigToDisk(ctx context.Context, encoded []byte) error {
-	configFilePath := filepath.Join(w.accountsPath, KeymanagerConfigFileName)
+	if w.encryptedConfig {
+		var err error
+		encoded, err = v2keymanager.EncryptConfig(encoded, w.walletPassword)
+		if err != nil {
+			return errors.Wrap(err, "could not encrypt keymanager config")
+		}
+	}
 	// Write the config file to disk.
-	if err := ioutil.WriteFile(configFilePath, encoded, os.ModePerm); err != nil {
-		return errors.Wrapf(err, "could not write %s", configFilePath)
+	if err := w.files().writeFile(ctx, KeymanagerConfigFileName, encoded); err != nil {
+		return errors.Wrapf(err, "could not write %s", KeymanagerConfigFileName)
 	}
-	log.WithField("configFilePath", configFilePath).Debug("Wrote keymanager config file to disk")
+	if err := w.recordMutation(ctx, journalWrites(ctx, map[string][]byte{KeymanagerConfigFileName: encoded})); err != nil {
+		return err
+	}
+	log.WithField("configFilePath", filepath.Join(w.accountsPath, KeymanagerConfigFileName)).Debug(
+		"Wrote keymanager config file to disk",
+	)
 	return nil
 }
 
+// Checks whether the keymanager config file at the wallet path was
+// encrypted with the wallet password.
+func (w *Wallet) hasEncryptedKeymanagerConfig() (bool, error) {
+	enc, err := w.files().readFile(context.Background(), KeymanagerConfigFileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return v2keymanager.IsEncryptedConfig(enc), nil
+}
+
 // ReadEncryptedSeedFromDisk reads the encrypted wallet seed configuration from
 // within the wallet path.
 func (w *Wallet) ReadEncryptedSeedFromDisk(ctx context.Context) (io.ReadCloser, error) {
-	configFilePath := filepath.Join(w.accountsPath, derived.EncryptedSeedFileName)
-	if !fileExists(configFilePath) {
+	enc, err := w.files().readFile(ctx, derived.EncryptedSeedFileName)
+	if errors.Is(err, os.ErrNotExist) {
 		return nil, fmt.Errorf("no encrypted seed file found at path: %s", w.accountsPath)
 	}
-	return os.Open(configFilePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read %s", derived.EncryptedSeedFileName)
+	}
+	return ioutil.NopCloser(bytes.NewReader(enc)), nil
 }
 
 // WriteEncryptedSeedToDisk writes the encrypted wallet seed configuration
 // within the wallet path.
 func (w *Wallet) WriteEncryptedSeedToDisk(ctx context.Context, encoded []byte) error {
-	seedFilePath := filepath.Join(w.accountsPath, derived.EncryptedSeedFileName)
-	// Write the config file to disk.
-	if err := ioutil.WriteFile(seedFilePath, encoded, os.ModePerm); err != nil {
-		return errors.Wrapf(err, "could not write %s", seedFilePath)
+	if err := w.files().writeFile(ctx, derived.EncryptedSeedFileName, encoded); err != nil {
+		return errors.Wrapf(err, "could not write %s", derived.EncryptedSeedFileName)
 	}
-	log.WithField("seedFilePath", seedFilePath).Debug("Wrote wallet encrypted seed file to disk")
+	if err := w.recordMutation(ctx, journalWrites(ctx, map[string][]byte{derived.EncryptedSeedFileName: encoded})); err != nil {
+		return err
+	}
+	log.WithField("seedFilePath", filepath.Join(w.accountsPath, derived.EncryptedSeedFileName)).Debug(
+		"Wrote wallet encrypted seed file to disk",
+	)
 	return nil
 }
 
 // ReadPasswordFromDisk --
 func (w *Wallet) ReadPasswordFromDisk(ctx context.Context, passwordFileName string) (string, error) {
+	if derived, ok, err := w.DeriveAccountPassword(strings.TrimSuffix(passwordFileName, direct.PasswordFileSuffix)); err != nil || ok {
+		return derived, err
+	}
+	if w.passwords != nil {
+		password, ok := w.passwords[passwordFileName]
+		if !ok {
+			return "", fmt.Errorf("no password %s in wallet", passwordFileName)
+		}
+		return password, nil
+	}
 	fullPath := filepath.Join(w.passwordsDir, passwordFileName)
-	rawData, err := ioutil.ReadFile(fullPath)
+	var rawData []byte
+	err := w.storageRetries().do(ctx, func() error {
+		var err error
+		rawData, err = ioutil.ReadFile(fullPath)
+		return err
+	})
 	if err != nil {
 		return "", errors.Wrapf(err, "could not read %s", fullPath)
 	}
@@ -422,15 +913,11 @@ func (w *Wallet) ReadPasswordFromDisk(ctx context.Context, passwordFileName stri
 // either from a file or from stdin. Then, it saves the password to the wallet.
 func (w *Wallet) enterPasswordForAccount(cliCtx *cli.Context, accountName string, pubKey []byte) error {
 	au := aurora.NewAurora(true)
-	var password string
-	var err error
-	if cliCtx.IsSet(flags.AccountPasswordFileFlag.Name) {
-		passwordFilePath := cliCtx.String(flags.AccountPasswordFileFlag.Name)
-		data, err := ioutil.ReadFile(passwordFilePath)
-		if err != nil {
-			return err
-		}
-		password = string(data)
+	password, ok, err := nonInteractivePassword(cliCtx, flags.AccountPasswordFileFlag)
+	if err != nil {
+		return err
+	}
+	if ok {
 		err = w.checkPasswordForAccount(accountName, password)
 		if err != nil && strings.Contains(err.Error(), "invalid checksum") {
 			return fmt.Errorf("invalid password entered for account with public key %#x", pubKey)
@@ -579,7 +1066,7 @@ func (w *Wallet) askUntilPasswordConfirms(cliCtx *cli.Context, accountName strin
 }
 
 func (w *Wallet) checkPasswordForAccount(accountName string, password string) error {
-	encoded, err := w.ReadFileAtPath(context.Background(), accountName, direct.KeystoreFileName)
+	encoded, err := w.ReadFileAtPath(context.Background(), accountName, w.keystoreFileGlob())
 	if err != nil {
 		return errors.Wrap(err, "could not read keystore file")
 	}
@@ -588,19 +1075,101 @@ func (w *Wallet) checkPasswordForAccount(accountName string, password string) er
 		return errors.Wrap(err, "could not decode json")
 	}
 	decryptor := keystorev4.New()
-	_, err = decryptor.Decrypt(keystoreJSON.Crypto, password)
+	rawSigningKey, err := decryptor.Decrypt(keystoreJSON.Crypto, password)
 	if err != nil {
 		return errors.Wrap(err, "could not decrypt keystore")
 	}
+	v2keymanager.ZeroSecret(rawSigningKey)
 	return nil
 }
 
 // WritePasswordToDisk --
 func (w *Wallet) WritePasswordToDisk(ctx context.Context, passwordFileName string, password string) error {
+	if err := w.checkWritable(); err != nil {
+		return err
+	}
+	// Wallets with a master password have no password files, the keystores of their accounts are
+	// encrypted with passwords derived from it.
+	accountName := strings.TrimSuffix(passwordFileName, direct.PasswordFileSuffix)
+	if derived, ok, err := w.DeriveAccountPassword(accountName); err != nil || ok {
+		if err != nil {
+			return err
+		}
+		if password != derived {
+			return fmt.Errorf("the password of account %s is derived from the wallet master password and cannot be set", accountName)
+		}
+		return nil
+	}
+	if w.passwords != nil {
+		w.passwords[passwordFileName] = password
+		if w.passwordStore {
+			if err := w.writePasswordStore(w.passwords, w.walletPassword); err != nil {
+				return err
+			}
+		}
+		return w.recordMutation(ctx, &walletJournalEntry{Action: journalAction(ctx), Passwords: []string{passwordFileName}})
+	}
 	passwordPath := filepath.Join(w.passwordsDir, passwordFileName)
-	if err := ioutil.WriteFile(passwordPath, []byte(password), os.ModePerm); err != nil {
+	if err := writeFileAtomic(passwordPath, []byte(password), FilePermissions); err != nil {
 		return errors.Wrapf(err, "could not write %s", passwordPath)
 	}
+	return w.recordMutation(ctx, &walletJournalEntry{Action: journalAction(ctx), Passwords: []string{passwordPath}})
+}
+
+// DeleteAccountFiles moves the directory of an account and its password file from the wallet
+// into its trash, where they are kept until restored with accounts-v2 restore or erased once their
+// retention period expires. Both are moved together, so an account is never left with only some
+// of its files. Passwords kept in the password store of the wallet stay in it.
+func (w *Wallet) DeleteAccountFiles(ctx context.Context, accountName string, passwordFileName string) error {
+	if err := w.checkWritable(); err != nil {
+		return err
+	}
+	if err := w.checkOnDisk("deleting accounts"); err != nil {
+		return err
+	}
+	accountPath := filepath.Join(w.accountsPath, accountName)
+	ok, err := hasDir(accountPath)
+	if err != nil {
+		return errors.Wrapf(err, "could not read account directory %s", accountPath)
+	}
+	if !ok {
+		return fmt.Errorf("account %s not found in wallet", accountName)
+	}
+	entryName := fmt.Sprintf(trashEntryNameFormat, roughtime.Now().UnixNano(), accountName)
+	entryDir := filepath.Join(w.accountsPath, trashDirName, entryName)
+	if err := os.MkdirAll(entryDir, DirectoryPermissions); err != nil {
+		return errors.Wrapf(err, "could not create directory %s", entryDir)
+	}
+	trashedAccountPath := filepath.Join(entryDir, accountName)
+	if err := os.Rename(accountPath, trashedAccountPath); err != nil {
+		if err := os.RemoveAll(entryDir); err != nil {
+			log.WithError(err).Errorf("Could not remove directory %s", entryDir)
+		}
+		return errors.Wrapf(err, "could not move account %s to the trash", accountName)
+	}
+	passwordPath := filepath.Join(w.passwordsDir, passwordFileName)
+	if fileExists(passwordPath) {
+		if err := os.Rename(passwordPath, filepath.Join(entryDir, passwordFileName)); err != nil {
+			if err := os.Rename(trashedAccountPath, accountPath); err != nil {
+				log.WithError(err).Errorf("Could not restore account %s, its files are in %s", accountName, entryDir)
+			} else if err := os.RemoveAll(entryDir); err != nil {
+				log.WithError(err).Errorf("Could not remove directory %s", entryDir)
+			}
+			return errors.Wrapf(err, "could not move password of account %s to the trash", accountName)
+		}
+	}
+	return w.recordMutation(ctx, &walletJournalEntry{
+		Action:  journalActionAccountDeleted,
+		Deleted: []string{accountName, passwordPath},
+	})
+}
+
+// Returns an error for an operation moving the directories of accounts or files kept outside of
+// the storage of the wallet, such as its trash and archive, which only wallets kept on disk have.
+func (w *Wallet) checkOnDisk(operation string) error {
+	if w.storage != nil {
+		return fmt.Errorf("%s is not supported for wallets stored in %s", operation, w.storageURL)
+	}
 	return nil
 }
 
@@ -698,3 +1267,69 @@ func isEmptyWallet(name string) (bool, error) {
 
 	return true, err
 }
+
+// Writes a file such that it is never observed partially written, not even after a crash. The
+// data is written and synced to a hidden temporary file next to the file, which is then renamed
+// over it, and the rename is synced by syncing the directory.
+func writeFileAtomic(filePath string, data []byte, perm os.FileMode) error {
+	tmpPath := filepath.Join(
+		filepath.Dir(filePath),
+		fmt.Sprintf(".%s.%d.%d%s", filepath.Base(filePath), os.Getpid(), time.Now().UnixNano(), tmpFileSuffix),
+	)
+	return writeFileVia(tmpPath, filePath, data, perm)
+}
+
+// Writes a file atomically through the given temporary file, which is replaced if it exists.
+func writeFileVia(tmpPath string, filePath string, data []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	removeTmp := func() {
+		if err := os.Remove(tmpPath); err != nil && !os.IsNotExist(err) {
+			log.WithError(err).Errorf("Could not remove %s", tmpPath)
+		}
+	}
+	if _, err := f.Write(data); err != nil {
+		if closeErr := f.Close(); closeErr != nil {
+			log.WithError(closeErr).Errorf("Could not close %s", tmpPath)
+		}
+		removeTmp()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		if closeErr := f.Close(); closeErr != nil {
+			log.WithError(closeErr).Errorf("Could not close %s", tmpPath)
+		}
+		removeTmp()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		removeTmp()
+		return err
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		removeTmp()
+		return err
+	}
+	return syncDir(filepath.Dir(filePath))
+}
+
+// Syncs a directory, making the files created in or renamed into it durable. Directories cannot
+// be synced on windows, so they are left to the file system there.
+func syncDir(dirPath string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	dir, err := os.Open(dirPath)
+	if err != nil {
+		return err
+	}
+	if err := dir.Sync(); err != nil {
+		if closeErr := dir.Close(); closeErr != nil {
+			log.WithError(closeErr).Errorf("Could not close directory %s", dirPath)
+		}
+		return errors.Wrapf(err, "could not sync directory %s", dirPath)
+	}
+	return dir.Close()
+}
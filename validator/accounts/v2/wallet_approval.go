@@ -0,0 +1,535 @@
+package v2
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// approvalPolicyFileName is the file in the accounts path of a wallet holding the operators
+	// approving its destructive operations, which are only carried out with approval tokens signed
+	// by enough of them.
+	approvalPolicyFileName = "approval-policy.json"
+	// usedApprovalTokensFileName is the file in the accounts path of a wallet holding the ids of the
+	// approval tokens it accepted which have not expired yet, so no token is accepted twice.
+	usedApprovalTokensFileName = "approval-tokens-used.json"
+	approvalVersion            = 1
+	approvalTokenIDSize        = 16
+	// Approval tokens sign a message starting with this domain, so the keys of operators cannot be
+	// made to sign anything else.
+	approvalDomain = "prysm-wallet-approval:v1"
+	// Destructive operations requiring approval.
+	approvalActionDeleteAccounts  = "delete-accounts"
+	approvalActionExportAccounts  = "export-accounts"
+	approvalActionArchiveAccounts = "archive-accounts"
+	approvalActionBackupWallet    = "backup-wallet"
+	approvalActionConvertWallet   = "convert-wallet"
+	approvalActionRestoreSnapshot = "restore-snapshot"
+	approvalActionSetPolicy       = "set-approval-policy"
+	// minApprovalOperators is the least number of operators of an approval policy, and of approval
+	// tokens it requires.
+	minApprovalOperators = 2
+)
+
+var approvalActions = []string{
+	approvalActionDeleteAccounts,
+	approvalActionExportAccounts,
+	approvalActionArchiveAccounts,
+	approvalActionBackupWallet,
+	approvalActionConvertWallet,
+	approvalActionRestoreSnapshot,
+	approvalActionSetPolicy,
+}
+
+// approvalOperator is an operator of an approval policy, identified by the ed25519 public key
+// approval tokens are signed with.
+type approvalOperator struct {
+	Name      string `json:"name"`
+	PublicKey string `json:"public_key"`
+}
+
+// approvalPolicy is the content of the approval policy file of a wallet.
+type approvalPolicy struct {
+	Version   int                 `json:"version"`
+	Required  int                 `json:"required"`
+	Operators []*approvalOperator `json:"operators"`
+}
+
+// approvalToken is the approval of an operator for an action on subjects, such as the deletion of
+// the accounts of validating public keys, until it expires. Its random id is signed with it, and
+// a wallet accepts a token of an id once only.
+type approvalToken struct {
+	Version   int      `json:"version"`
+	ID        string   `json:"id"`
+	Action    string   `json:"action"`
+	Subjects  []string `json:"subjects"`
+	Expires   int64    `json:"expires"`
+	Operator  string   `json:"operator"`
+	Signature string   `json:"signature"`
+}
+
+// GenerateOperatorKey writes a new ed25519 operator key to --operator-key-file, for an operator
+// of an approval policy to sign approval tokens with, and prints its public key to configure the
+// approval policy of wallets with.
+func GenerateOperatorKey(cliCtx *cli.Context) error {
+	keyFile := cliCtx.String(flags.OperatorKeyFileFlag.Name)
+	if keyFile == "" {
+		return fmt.Errorf("--%s is required", flags.OperatorKeyFileFlag.Name)
+	}
+	keyFile, err := expandPath(keyFile)
+	if err != nil {
+		return errors.Wrap(err, "could not expand operator key file path")
+	}
+	if fileExists(keyFile) {
+		return fmt.Errorf("operator key file %s already exists", keyFile)
+	}
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return errors.Wrap(err, "could not generate operator key")
+	}
+	if err := writeFileAtomic(keyFile, []byte(hex.EncodeToString(privateKey.Seed())), FilePermissions); err != nil {
+		return errors.Wrapf(err, "could not write %s", keyFile)
+	}
+	fmt.Printf("Wrote operator key to %s, its public key is %s\n", keyFile, au.BrightGreen(fmt.Sprintf("%#x", []byte(publicKey))))
+	return nil
+}
+
+// SetApprovalPolicy makes every operation exporting or removing the keys of the wallet, deleting,
+// exporting and archiving its accounts, backing it up, converting it and restoring a snapshot of
+// it, require approval tokens of --approvals-required of the --approval-operators, given as
+// name=0x<public key> of the operator keys written by wallet-v2 generate-operator-key. Replacing
+// the approval policy of a wallet requires approval tokens of its current operators for the new
+// policy. The approval policy is a file of the wallet, so it only guards wallets operators cannot
+// write to directly, such as those served by the validator client of a custodian.
+func SetApprovalPolicy(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	if err := wallet.checkWritable(); err != nil {
+		return err
+	}
+	policy, err := approvalPolicyFromCli(cliCtx)
+	if err != nil {
+		return err
+	}
+	if err := wallet.checkApproval(cliCtx, approvalActionSetPolicy, policy.subjects()); err != nil {
+		return err
+	}
+	encoded, err := json.MarshalIndent(policy, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "could not encode approval policy")
+	}
+	if err := wallet.WriteFileAtPath(ctx, "" /* accounts dir */, approvalPolicyFileName, encoded); err != nil {
+		return errors.Wrap(err, "could not write approval policy")
+	}
+	fmt.Printf(
+		"Exporting and removing the keys of the wallet now requires approval tokens of %s of %s operators\n",
+		au.BrightGreen(policy.Required),
+		au.BrightGreen(len(policy.Operators)),
+	)
+	return nil
+}
+
+// ApproveOperation signs an approval token for the --approval-action with the key of an operator
+// in --operator-key-file, valid for --approval-validity, and writes it to --approval-token-file.
+// Deletions, exports and archivals are approved for the accounts of the --approval-public-keys or
+// of those listed in the --pubkeys-file, snapshot restores for the --snapshot-label, and approval
+// policies for the --approval-operators and --approvals-required of the new policy. Backups and
+// conversions are approved for the whole wallet.
+func ApproveOperation(cliCtx *cli.Context) error {
+	action := cliCtx.String(flags.ApprovalActionFlag.Name)
+	var subjects []string
+	switch action {
+	case approvalActionDeleteAccounts, approvalActionExportAccounts, approvalActionArchiveAccounts:
+		pubKeys, err := parsePubKeyList(cliCtx.StringSlice(flags.ApprovalPublicKeysFlag.Name))
+		if err != nil {
+			return err
+		}
+		if cliCtx.IsSet(flags.PubKeysFileFlag.Name) {
+			listed, err := readPubKeysFile(cliCtx)
+			if err != nil {
+				return err
+			}
+			for _, pubKey := range listed {
+				pubKeys[pubKey] = true
+			}
+		}
+		if len(pubKeys) == 0 {
+			return fmt.Errorf(
+				"the accounts to approve %s of must be given with --%s or --%s",
+				action,
+				flags.ApprovalPublicKeysFlag.Name,
+				flags.PubKeysFileFlag.Name,
+			)
+		}
+		for pubKey := range pubKeys {
+			subjects = append(subjects, fmt.Sprintf("%#x", pubKey))
+		}
+	case approvalActionRestoreSnapshot:
+		label := cliCtx.String(flags.SnapshotLabelFlag.Name)
+		if label == "" {
+			return fmt.Errorf("the snapshot to approve restoring must be given with --%s", flags.SnapshotLabelFlag.Name)
+		}
+		subjects = []string{snapshotSubject(label)}
+	case approvalActionBackupWallet, approvalActionConvertWallet:
+	case approvalActionSetPolicy:
+		policy, err := approvalPolicyFromCli(cliCtx)
+		if err != nil {
+			return err
+		}
+		subjects = policy.subjects()
+	default:
+		return fmt.Errorf("unknown approval action %q, expected one of %s", action, strings.Join(approvalActions, ", "))
+	}
+	privateKey, err := readOperatorKey(cliCtx.String(flags.OperatorKeyFileFlag.Name))
+	if err != nil {
+		return err
+	}
+	tokenFile := cliCtx.String(flags.ApprovalTokenFileFlag.Name)
+	if tokenFile == "" {
+		return fmt.Errorf("--%s is required", flags.ApprovalTokenFileFlag.Name)
+	}
+	expires := roughtime.Now().Add(cliCtx.Duration(flags.ApprovalValidityFlag.Name))
+	token, err := signApprovalToken(privateKey, action, subjects, expires)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.MarshalIndent(token, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "could not encode approval token")
+	}
+	if err := writeFileAtomic(tokenFile, encoded, FilePermissions); err != nil {
+		return errors.Wrapf(err, "could not write %s", tokenFile)
+	}
+	fmt.Printf(
+		"Approved %s of %d subjects until %s in %s\n",
+		au.BrightGreen(action),
+		len(subjects),
+		expires.Format(time.RFC3339),
+		tokenFile,
+	)
+	return nil
+}
+
+// Checks the --approval-tokens approve an action on subjects, with tokens of as many operators of
+// the approval policy of the wallet as it requires, and records their ids so they cannot be used
+// again. Wallets without an approval policy require no approval.
+func (w *Wallet) checkApproval(cliCtx *cli.Context, action string, subjects []string) error {
+	policy, err := w.readApprovalPolicy()
+	if err != nil || policy == nil {
+		return err
+	}
+	used, err := w.readUsedApprovalTokens()
+	if err != nil {
+		return err
+	}
+	operators := make(map[string]string, len(policy.Operators))
+	for _, operator := range policy.Operators {
+		operators[strings.ToLower(operator.PublicKey)] = operator.Name
+	}
+	approvedBy := make(map[string]bool)
+	accepted := make(map[string]int64)
+	now := roughtime.Now()
+	for _, tokenFile := range cliCtx.StringSlice(flags.ApprovalTokensFlag.Name) {
+		token, err := readApprovalToken(tokenFile)
+		if err != nil {
+			return err
+		}
+		name, ok := operators[strings.ToLower(token.Operator)]
+		if !ok {
+			log.WithField("token", tokenFile).Warn("Approval token is not signed by an operator of the approval policy")
+			continue
+		}
+		if err := token.approves(action, subjects, now); err != nil {
+			log.WithError(err).WithField("token", tokenFile).Warn("Approval token does not approve the operation")
+			continue
+		}
+		if _, ok := used[token.ID]; ok {
+			log.WithField("token", tokenFile).Warn("Approval token was already used")
+			continue
+		}
+		approvedBy[name] = true
+		accepted[token.ID] = token.Expires
+	}
+	if len(approvedBy) < policy.Required {
+		return fmt.Errorf(
+			"%s requires approval tokens of %d operators of the approval policy of the wallet, given with --%s, "+
+				"got valid approval tokens of %d",
+			action,
+			policy.Required,
+			flags.ApprovalTokensFlag.Name,
+			len(approvedBy),
+		)
+	}
+	if w.readOnly {
+		log.Warn("Wallet is read-only, the approval tokens are not recorded as used")
+		return nil
+	}
+	// Ids of expired tokens are dropped, as expired tokens are refused anyway.
+	for id, expires := range used {
+		if now.Unix() >= expires {
+			delete(used, id)
+		}
+	}
+	for id, expires := range accepted {
+		used[id] = expires
+	}
+	encoded, err := json.MarshalIndent(used, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "could not encode used approval tokens")
+	}
+	ctx := withJournalAction(context.Background(), journalActionApprovalTokensUsed)
+	if err := w.files().writeFile(ctx, usedApprovalTokensFileName, encoded); err != nil {
+		return errors.Wrap(err, "could not record approval tokens as used")
+	}
+	return w.recordMutation(ctx, journalWrites(ctx, map[string][]byte{usedApprovalTokensFileName: encoded}))
+}
+
+// Reads the ids of the approval tokens the wallet accepted, with their expiry.
+func (w *Wallet) readUsedApprovalTokens() (map[string]int64, error) {
+	used := make(map[string]int64)
+	encoded, err := w.files().readFile(context.Background(), usedApprovalTokensFileName)
+	if os.IsNotExist(err) {
+		return used, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read used approval tokens")
+	}
+	if err := json.Unmarshal(encoded, &used); err != nil {
+		return nil, errors.Wrap(err, "could not decode used approval tokens")
+	}
+	return used, nil
+}
+
+// Reads the approval policy of the wallet, which is nil if it has none.
+func (w *Wallet) readApprovalPolicy() (*approvalPolicy, error) {
+	encoded, err := w.files().readFile(context.Background(), approvalPolicyFileName)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read approval policy")
+	}
+	policy := &approvalPolicy{}
+	if err := json.Unmarshal(encoded, policy); err != nil {
+		return nil, errors.Wrap(err, "could not decode approval policy")
+	}
+	if policy.Version != approvalVersion {
+		return nil, fmt.Errorf("unsupported approval policy version %d", policy.Version)
+	}
+	if err := policy.validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid approval policy")
+	}
+	return policy, nil
+}
+
+// Returns the approval policy of the --approval-operators and --approvals-required.
+func approvalPolicyFromCli(cliCtx *cli.Context) (*approvalPolicy, error) {
+	policy := &approvalPolicy{
+		Version:  approvalVersion,
+		Required: cliCtx.Int(flags.ApprovalsRequiredFlag.Name),
+	}
+	for _, entry := range cliCtx.StringSlice(flags.ApprovalOperatorsFlag.Name) {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("operator %q is not given as name=0x<public key>", entry)
+		}
+		policy.Operators = append(policy.Operators, &approvalOperator{
+			Name:      strings.TrimSpace(parts[0]),
+			PublicKey: strings.ToLower(strings.TrimSpace(parts[1])),
+		})
+	}
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (p *approvalPolicy) validate() error {
+	if len(p.Operators) < minApprovalOperators {
+		return fmt.Errorf("an approval policy needs at least %d operators, got %d", minApprovalOperators, len(p.Operators))
+	}
+	if p.Required < minApprovalOperators || p.Required > len(p.Operators) {
+		return fmt.Errorf(
+			"an approval policy requires between %d and its %d operators to approve, not %d",
+			minApprovalOperators,
+			len(p.Operators),
+			p.Required,
+		)
+	}
+	names := make(map[string]bool, len(p.Operators))
+	publicKeys := make(map[string]bool, len(p.Operators))
+	for _, operator := range p.Operators {
+		if operator.Name == "" {
+			return errors.New("operators of an approval policy must be named")
+		}
+		if _, err := parseOperatorPublicKey(operator.PublicKey); err != nil {
+			return errors.Wrapf(err, "invalid public key of operator %s", operator.Name)
+		}
+		if names[operator.Name] || publicKeys[strings.ToLower(operator.PublicKey)] {
+			return fmt.Errorf("operator %s is in the approval policy more than once", operator.Name)
+		}
+		names[operator.Name] = true
+		publicKeys[strings.ToLower(operator.PublicKey)] = true
+	}
+	return nil
+}
+
+// Returns what approval tokens for the approval policy approve, its operators and the number of
+// them it requires.
+func (p *approvalPolicy) subjects() []string {
+	subjects := make([]string, 0, len(p.Operators)+1)
+	subjects = append(subjects, "required="+strconv.Itoa(p.Required))
+	for _, operator := range p.Operators {
+		subjects = append(subjects, operator.Name+"="+strings.ToLower(operator.PublicKey))
+	}
+	return subjects
+}
+
+// Checks a token approves an action on every subject at a time. Approval policies are only
+// approved by tokens for exactly the same policy.
+func (t *approvalToken) approves(action string, subjects []string, now time.Time) error {
+	if t.Version != approvalVersion {
+		return fmt.Errorf("unsupported approval token version %d", t.Version)
+	}
+	if t.ID == "" {
+		return errors.New("approval token has no id, sign a new one with wallet-v2 approve")
+	}
+	publicKey, err := parseOperatorPublicKey(t.Operator)
+	if err != nil {
+		return err
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(t.Signature, "0x"))
+	if err != nil {
+		return errors.Wrap(err, "could not decode approval token signature")
+	}
+	if !ed25519.Verify(publicKey, approvalMessage(t.ID, t.Action, t.Subjects, t.Expires), signature) {
+		return errors.New("approval token signature is invalid")
+	}
+	if t.Action != action {
+		return fmt.Errorf("approval token approves %s, not %s", t.Action, action)
+	}
+	if now.Unix() >= t.Expires {
+		return fmt.Errorf("approval token expired at %s", time.Unix(t.Expires, 0).UTC().Format(time.RFC3339))
+	}
+	approved := make(map[string]bool, len(t.Subjects))
+	for _, subject := range t.Subjects {
+		approved[strings.ToLower(subject)] = true
+	}
+	for _, subject := range subjects {
+		if !approved[strings.ToLower(subject)] {
+			return fmt.Errorf("approval token does not approve %s", subject)
+		}
+	}
+	if action == approvalActionSetPolicy && len(approved) != len(subjects) {
+		return errors.New("approval token approves another approval policy")
+	}
+	return nil
+}
+
+// Returns the subjects of approval tokens for the deletion, export or archival of the accounts of
+// public keys.
+func pubKeySubjects(pubKeys [][48]byte) []string {
+	subjects := make([]string, len(pubKeys))
+	for i, pubKey := range pubKeys {
+		subjects[i] = fmt.Sprintf("%#x", pubKey)
+	}
+	return subjects
+}
+
+// Returns the subject of approval tokens for restoring the wallet snapshot of a label.
+func snapshotSubject(label string) string {
+	return "snapshot=" + label
+}
+
+func signApprovalToken(
+	privateKey ed25519.PrivateKey,
+	action string,
+	subjects []string,
+	expires time.Time,
+) (*approvalToken, error) {
+	id := make([]byte, approvalTokenIDSize)
+	if _, err := rand.Read(id); err != nil {
+		return nil, errors.Wrap(err, "could not generate approval token id")
+	}
+	sorted := make([]string, len(subjects))
+	for i, subject := range subjects {
+		sorted[i] = strings.ToLower(subject)
+	}
+	sort.Strings(sorted)
+	token := &approvalToken{
+		Version:  approvalVersion,
+		ID:       hex.EncodeToString(id),
+		Action:   action,
+		Subjects: sorted,
+		Expires:  expires.Unix(),
+		Operator: fmt.Sprintf("%#x", []byte(privateKey.Public().(ed25519.PublicKey))),
+	}
+	signature := ed25519.Sign(privateKey, approvalMessage(token.ID, action, sorted, token.Expires))
+	token.Signature = fmt.Sprintf("%#x", signature)
+	return token, nil
+}
+
+// Returns the message an approval token signs, its id, action, expiry and subjects in the order of
+// the token, one per line.
+func approvalMessage(id string, action string, subjects []string, expires int64) []byte {
+	lines := append([]string{approvalDomain, id, action, strconv.FormatInt(expires, 10)}, subjects...)
+	return []byte(strings.Join(lines, "\n"))
+}
+
+func readApprovalToken(tokenFile string) (*approvalToken, error) {
+	encoded, err := ioutil.ReadFile(tokenFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read approval token %s", tokenFile)
+	}
+	token := &approvalToken{}
+	if err := json.Unmarshal(encoded, token); err != nil {
+		return nil, errors.Wrapf(err, "could not decode approval token %s", tokenFile)
+	}
+	return token, nil
+}
+
+func readOperatorKey(keyFile string) (ed25519.PrivateKey, error) {
+	if keyFile == "" {
+		return nil, fmt.Errorf("--%s is required", flags.OperatorKeyFileFlag.Name)
+	}
+	encoded, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read operator key %s", keyFile)
+	}
+	seed, err := hex.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("operator key %s is not a hex-encoded ed25519 seed", keyFile)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+func parseOperatorPublicKey(s string) (ed25519.PublicKey, error) {
+	publicKey, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not decode operator public key %s", s)
+	}
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("operator public key %s is %d bytes long, expected %d", s, len(publicKey), ed25519.PublicKeySize)
+	}
+	return publicKey, nil
+}
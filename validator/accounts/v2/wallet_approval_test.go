@@ -0,0 +1,102 @@
+package v2
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+)
+
+func TestApprovalPolicy_DeleteAccounts(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		walletPasswordFile:  passwordFile,
+		accountPasswordFile: passwordFile,
+		keymanagerKind:      v2keymanager.Direct,
+	}
+	_, err := CreateWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	require.NoError(t, CreateAccount(setupWalletCtx(t, cfg)))
+	require.NoError(t, CreateAccount(setupWalletCtx(t, cfg)))
+	pubKeys := walletPubKeys(t, cfg)
+	require.Equal(t, 2, len(pubKeys))
+
+	operatorKeys := make(map[string]ed25519.PrivateKey)
+	for _, name := range []string{"alice", "bob", "carol"} {
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		operatorKeys[name] = privateKey
+		cfg.approvalOperators = append(cfg.approvalOperators, fmt.Sprintf("%s=%#x", name, []byte(publicKey)))
+	}
+	tokensDir := filepath.Dir(passwordFile)
+	writeToken := func(name string, operator string, action string, subjects []string, expires time.Time) string {
+		token, err := signApprovalToken(operatorKeys[operator], action, subjects, expires)
+		require.NoError(t, err)
+		encoded, err := json.Marshal(token)
+		require.NoError(t, err)
+		tokenFile := filepath.Join(tokensDir, name+".json")
+		require.NoError(t, ioutil.WriteFile(tokenFile, encoded, FilePermissions))
+		return tokenFile
+	}
+	valid := roughtime.Now().Add(time.Hour)
+
+	// A policy of a single operator would not be dual control.
+	policyCfg := *cfg
+	policyCfg.approvalOperators = cfg.approvalOperators[:1]
+	assert.ErrorContains(t, "at least 2 operators", SetApprovalPolicy(setupWalletCtx(t, &policyCfg)))
+	require.NoError(t, SetApprovalPolicy(setupWalletCtx(t, cfg)))
+
+	cfg.deletePublicKeys = []string{fmt.Sprintf("%#x", pubKeys[0])}
+	deleteSubjects := pubKeySubjects(pubKeys[:1])
+	assert.ErrorContains(t, "got valid approval tokens of 0", DeleteAccount(setupWalletCtx(t, cfg)))
+	alice := writeToken("alice", "alice", approvalActionDeleteAccounts, deleteSubjects, valid)
+	cfg.approvalTokens = []string{alice, alice}
+	assert.ErrorContains(t, "got valid approval tokens of 1", DeleteAccount(setupWalletCtx(t, cfg)))
+	cfg.approvalTokens = []string{
+		alice,
+		writeToken("bob-export", "bob", approvalActionExportAccounts, deleteSubjects, valid),
+		writeToken("bob-other", "bob", approvalActionDeleteAccounts, pubKeySubjects(pubKeys[1:]), valid),
+		writeToken("bob-expired", "bob", approvalActionDeleteAccounts, deleteSubjects, roughtime.Now().Add(-time.Minute)),
+	}
+	assert.ErrorContains(t, "got valid approval tokens of 1", DeleteAccount(setupWalletCtx(t, cfg)))
+
+	// Tokens of two operators approve the deletion, and may approve more accounts than are deleted.
+	cfg.approvalTokens = []string{
+		alice,
+		writeToken("bob", "bob", approvalActionDeleteAccounts, pubKeySubjects(pubKeys), valid),
+	}
+	require.NoError(t, DeleteAccount(setupWalletCtx(t, cfg)))
+	assert.DeepEqual(t, pubKeys[1:], walletPubKeys(t, cfg))
+
+	// Tokens are accepted once only, even for accounts they approve which were not deleted yet.
+	cfg.deletePublicKeys = []string{fmt.Sprintf("%#x", pubKeys[1])}
+	cfg.approvalTokens = []string{
+		cfg.approvalTokens[1],
+		writeToken("alice-again", "alice", approvalActionDeleteAccounts, pubKeySubjects(pubKeys[1:]), valid),
+	}
+	assert.ErrorContains(t, "got valid approval tokens of 1", DeleteAccount(setupWalletCtx(t, cfg)))
+	assert.DeepEqual(t, pubKeys[1:], walletPubKeys(t, cfg))
+
+	// Replacing the policy requires approval of its operators for the new policy.
+	cfg.approvalTokens = nil
+	cfg.approvalOperators = cfg.approvalOperators[1:]
+	assert.ErrorContains(t, "set-approval-policy requires approval tokens", SetApprovalPolicy(setupWalletCtx(t, cfg)))
+	policy, err := approvalPolicyFromCli(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	cfg.approvalTokens = []string{
+		writeToken("alice-policy", "alice", approvalActionSetPolicy, policy.subjects(), valid),
+		writeToken("carol-policy", "carol", approvalActionSetPolicy, policy.subjects(), valid),
+	}
+	require.NoError(t, SetApprovalPolicy(setupWalletCtx(t, cfg)))
+}
@@ -0,0 +1,407 @@
+package v2
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/urfave/cli/v2"
+	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+)
+
+const (
+	// artifactKeyFileName is the file in the accounts directory of a non-HD wallet holding the key
+	// its deposit data and withdrawal artifacts are encrypted with, itself encrypted with the wallet
+	// password. Artifacts are stored in plaintext in wallets without one.
+	artifactKeyFileName = "artifact-key.json"
+	artifactKeyVersion  = 1
+	artifactKeySize     = 32
+	// encryptedArtifactVersion is the version of the envelope of an encrypted artifact, which
+	// authenticates the path of the artifact in the wallet.
+	encryptedArtifactVersion = 2
+	// legacyEncryptedArtifactVersion is the version of the envelope of artifacts encrypted before
+	// their path was authenticated, which only authenticates their file name. They are read as
+	// before, and encrypted again with their path by wallet-v2 encrypt-artifacts.
+	legacyEncryptedArtifactVersion = 1
+	// withdrawalKeystoreFilePattern matches the withdrawal keystores written to the accounts
+	// directory of a wallet, by withdrawal key path or by fingerprint of a random withdrawal key.
+	withdrawalKeystoreFilePattern = "withdrawal-keystore-*.json"
+)
+
+// artifactFileNames are the files of a non-HD wallet revealing the withdrawal credentials of its
+// validators, or linking them to their depositor, by file name in the wallet. Withdrawal keystores
+// matching withdrawalKeystoreFilePattern are artifacts too.
+var artifactFileNames = map[string]bool{
+	direct.DepositDataFileName:        true,
+	direct.DepositDataJSONFileName:    true,
+	direct.WithdrawalKeystoreFileName: true,
+	accountMetadataFileName:           true,
+	withdrawalCredentialsFileName:     true,
+}
+
+// artifactKeyFile is the content of the artifact key file of a wallet, the key encrypted with
+// keystorev4 like EIP-2335 keystores. Artifacts are encrypted with the key rather than the wallet
+// password itself, so reading one does not run the KDF of the wallet password every time, and
+// changing the wallet password only re-encrypts the key.
+type artifactKeyFile struct {
+	Version int                    `json:"version"`
+	Crypto  map[string]interface{} `json:"crypto"`
+}
+
+// encryptedArtifact is the content of an artifact file encrypted with the artifact key with
+// AES-256-GCM, authenticating its slash separated path relative to the accounts directory, so
+// artifacts cannot be swapped for one another, even between accounts.
+type encryptedArtifact struct {
+	Version    int    `json:"encrypted_artifact"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// EncryptArtifacts encrypts the deposit data, withdrawal keystores, account metadata and
+// withdrawal credentials of a non-HD wallet with a key encrypted with the wallet password, like
+// its keystores, since they reveal the withdrawal credentials of its validators and link them to
+// their depositor. Artifacts of accounts created or imported after are encrypted too. Artifacts
+// left in plaintext by an interrupted run are encrypted by running it again. Wallets without a
+// wallet password are given one.
+func EncryptArtifacts(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	wallet, err := openDirectWallet(cliCtx)
+	if err != nil {
+		return err
+	}
+	if err := wallet.checkWritable(); err != nil {
+		return err
+	}
+	if wallet.walletPassword == "" {
+		wallet.walletPassword, err = inputPassword(cliCtx, flags.WalletPasswordFileFlag, newWalletPasswordPromptText, confirmPass)
+		if err != nil {
+			return errors.Wrap(err, "could not get password")
+		}
+	}
+	lock, err := wallet.Lock()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := lock.Unlock(); err != nil {
+			log.WithError(err).Error("Could not unlock wallet")
+		}
+	}()
+	encrypted, err := wallet.encryptArtifacts(withJournalAction(ctx, journalActionArtifactsEncrypted))
+	if err != nil {
+		return err
+	}
+	fmt.Printf(
+		"Encrypted %s deposit data and withdrawal files with the key in %s\n",
+		au.BrightGreen(encrypted),
+		au.BrightGreen(wallet.artifactKeyPath()),
+	)
+	return nil
+}
+
+// Generates the artifact key of the wallet if it has none, and rewrites every artifact of the
+// accounts directory still in plaintext, or encrypted before its path was authenticated, encrypted
+// with it, returning how many were encrypted. Archived and trashed accounts are left as they are.
+func (w *Wallet) encryptArtifacts(ctx context.Context) (int, error) {
+	if w.artifactKey == nil {
+		key := make([]byte, artifactKeySize)
+		if _, err := rand.Read(key); err != nil {
+			return 0, errors.Wrap(err, "could not generate artifact key")
+		}
+		encoded, err := encryptArtifactKeyFile(key, w.walletPassword)
+		if err != nil {
+			return 0, err
+		}
+		if err := writeFileAtomic(w.artifactKeyPath(), encoded, FilePermissions); err != nil {
+			return 0, errors.Wrapf(err, "could not write %s", w.artifactKeyPath())
+		}
+		w.artifactKey = key
+	}
+	accountNames, err := w.files().listDirs(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not list accounts")
+	}
+	encrypted := 0
+	for _, dir := range append([]string{"" /* accounts dir */}, accountNames...) {
+		withdrawalKeystores, err := w.storageWithoutArtifactEncryption().glob(ctx, dir, withdrawalKeystoreFilePattern)
+		if err != nil {
+			return encrypted, errors.Wrap(err, "could not list withdrawal keystores")
+		}
+		fileNames := withdrawalKeystores
+		for fileName := range artifactFileNames {
+			fileNames = append(fileNames, fileName)
+		}
+		files := make(map[string][]byte)
+		for _, fileName := range fileNames {
+			fullPath := path.Join(dir, fileName)
+			raw, err := w.storageWithoutArtifactEncryption().readFile(ctx, fullPath)
+			if err != nil || encryptedArtifactVersionOf(raw) == encryptedArtifactVersion {
+				continue
+			}
+			// Artifacts encrypted before their path was authenticated are decrypted to be
+			// encrypted again.
+			decrypted, err := w.files().readFile(ctx, fullPath)
+			if err != nil {
+				return encrypted, err
+			}
+			files[fileName] = decrypted
+		}
+		if len(files) == 0 {
+			continue
+		}
+		if err := w.WriteFilesAtPath(ctx, dir, files); err != nil {
+			return encrypted, err
+		}
+		encrypted += len(files)
+	}
+	return encrypted, nil
+}
+
+// Returns the path of the artifact key file of the wallet.
+func (w *Wallet) artifactKeyPath() string {
+	return filepath.Join(w.accountsPath, artifactKeyFileName)
+}
+
+// Decrypts the artifact key of the wallet with the wallet password if it has one, after which
+// its artifacts are encrypted and decrypted as they are written and read.
+func (w *Wallet) readArtifactKey() error {
+	if !fileExists(w.artifactKeyPath()) {
+		return nil
+	}
+	encoded, err := ioutil.ReadFile(w.artifactKeyPath())
+	if err != nil {
+		return errors.Wrap(err, "could not read artifact key")
+	}
+	w.artifactKey, err = decryptArtifactKeyFile(encoded, w.walletPassword)
+	return err
+}
+
+// Re-encrypts the artifact key of the wallet with a new wallet password, in memory.
+func (w *Wallet) reencryptArtifactKeyFile(newPassword string) (*reencryptedFile, error) {
+	enc, err := ioutil.ReadFile(w.artifactKeyPath())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read artifact key")
+	}
+	key, err := decryptArtifactKeyFile(enc, w.walletPassword)
+	if err != nil {
+		return nil, err
+	}
+	defer v2keymanager.ZeroSecret(key)
+	encoded, err := encryptArtifactKeyFile(key, newPassword)
+	if err != nil {
+		return nil, err
+	}
+	return &reencryptedFile{path: w.artifactKeyPath(), previous: enc, data: encoded}, nil
+}
+
+// Returns the storage of the wallet reading the raw contents of its artifacts, to tell which are
+// still in plaintext.
+func (w *Wallet) storageWithoutArtifactEncryption() walletStorage {
+	switch storage := w.files().(type) {
+	case *artifactStorage:
+		return storage.storage
+	case *artifactTransactionalStorage:
+		return storage.storage
+	default:
+		return storage
+	}
+}
+
+func encryptArtifactKeyFile(key []byte, walletPassword string) ([]byte, error) {
+	cryptoFields, err := keystorev4.New().Encrypt(key, walletPassword)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encrypt artifact key")
+	}
+	encoded, err := json.MarshalIndent(&artifactKeyFile{
+		Version: artifactKeyVersion,
+		Crypto:  cryptoFields,
+	}, "", "\t")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encode artifact key file")
+	}
+	return encoded, nil
+}
+
+func decryptArtifactKeyFile(encoded []byte, walletPassword string) ([]byte, error) {
+	file := &artifactKeyFile{}
+	if err := json.Unmarshal(encoded, file); err != nil {
+		return nil, errors.Wrap(err, "could not decode artifact key file")
+	}
+	if file.Version != artifactKeyVersion {
+		return nil, fmt.Errorf("unsupported artifact key file version %d", file.Version)
+	}
+	key, err := keystorev4.New().Decrypt(file.Crypto, walletPassword)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decrypt artifact key, wrong wallet password")
+	}
+	return key, nil
+}
+
+// artifactStorage encrypts the artifacts written to the storage of a wallet with its artifact
+// key, and decrypts those read from it. Artifacts written before the wallet had an artifact key
+// are read as they are.
+type artifactStorage struct {
+	storage walletStorage
+	key     []byte
+}
+
+type artifactTransactionalStorage struct {
+	*artifactStorage
+}
+
+// Wraps storage to encrypt and decrypt artifacts with a key, keeping it transactional if it is.
+func withArtifactEncryption(storage walletStorage, key []byte) walletStorage {
+	artifacts := &artifactStorage{storage: storage, key: key}
+	if _, ok := storage.(transactionalStorage); ok {
+		return &artifactTransactionalStorage{artifacts}
+	}
+	return artifacts
+}
+
+func (s *artifactStorage) readFile(ctx context.Context, name string) ([]byte, error) {
+	data, err := s.storage.readFile(ctx, name)
+	if err != nil || !isArtifactFile(name) || !isEncryptedArtifact(data) {
+		return data, err
+	}
+	decrypted, err := decryptArtifact(s.key, name, data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not decrypt %s", name)
+	}
+	return decrypted, nil
+}
+
+func (s *artifactStorage) writeFile(ctx context.Context, name string, data []byte) error {
+	encrypted, err := s.encrypt(name, data)
+	if err != nil {
+		return err
+	}
+	return s.storage.writeFile(ctx, name, encrypted)
+}
+
+func (s *artifactStorage) removeFile(ctx context.Context, name string) error {
+	return s.storage.removeFile(ctx, name)
+}
+
+func (s *artifactStorage) glob(ctx context.Context, dir string, pattern string) ([]string, error) {
+	return s.storage.glob(ctx, dir, pattern)
+}
+
+func (s *artifactStorage) listDirs(ctx context.Context) ([]string, error) {
+	return s.storage.listDirs(ctx)
+}
+
+func (s *artifactTransactionalStorage) writeFiles(ctx context.Context, files map[string][]byte) error {
+	encryptedFiles := make(map[string][]byte, len(files))
+	for name, data := range files {
+		encrypted, err := s.encrypt(name, data)
+		if err != nil {
+			return err
+		}
+		encryptedFiles[name] = encrypted
+	}
+	return s.storage.(transactionalStorage).writeFiles(ctx, encryptedFiles)
+}
+
+func (s *artifactStorage) encrypt(name string, data []byte) ([]byte, error) {
+	if !isArtifactFile(name) {
+		return data, nil
+	}
+	encrypted, err := encryptArtifact(s.key, name, data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not encrypt %s", name)
+	}
+	return encrypted, nil
+}
+
+// Returns whether a file of the wallet, by its slash separated path relative to the accounts
+// directory, is an artifact.
+func isArtifactFile(name string) bool {
+	fileName := path.Base(name)
+	if artifactFileNames[fileName] {
+		return true
+	}
+	ok, err := path.Match(withdrawalKeystoreFilePattern, fileName)
+	return err == nil && ok
+}
+
+// Returns whether the contents of an artifact file are encrypted. Deposit data is either SSZ or
+// JSON without an encrypted_artifact field, as are the other artifacts.
+func isEncryptedArtifact(data []byte) bool {
+	return encryptedArtifactVersionOf(data) != 0
+}
+
+// Returns the version of the envelope of an encrypted artifact, 0 for artifacts in plaintext.
+func encryptedArtifactVersionOf(data []byte) int {
+	artifact := &encryptedArtifact{}
+	if err := json.Unmarshal(data, artifact); err != nil {
+		return 0
+	}
+	return artifact.Version
+}
+
+// Encrypts an artifact, authenticating its slash separated path relative to the accounts directory.
+func encryptArtifact(key []byte, name string, data []byte) ([]byte, error) {
+	aead, err := artifactAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "could not generate nonce")
+	}
+	return json.MarshalIndent(&encryptedArtifact{
+		Version:    encryptedArtifactVersion,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, data, []byte(name)),
+	}, "", "\t")
+}
+
+// Decrypts an artifact encrypted at a slash separated path relative to the accounts directory.
+func decryptArtifact(key []byte, name string, data []byte) ([]byte, error) {
+	artifact := &encryptedArtifact{}
+	if err := json.Unmarshal(data, artifact); err != nil {
+		return nil, errors.Wrap(err, "could not decode encrypted artifact")
+	}
+	var additionalData []byte
+	switch artifact.Version {
+	case encryptedArtifactVersion:
+		additionalData = []byte(name)
+	case legacyEncryptedArtifactVersion:
+		additionalData = []byte(path.Base(name))
+	default:
+		return nil, fmt.Errorf("unsupported encrypted artifact version %d", artifact.Version)
+	}
+	if key == nil {
+		return nil, errors.New("artifact is encrypted but the wallet has no artifact key")
+	}
+	aead, err := artifactAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(artifact.Nonce) != aead.NonceSize() {
+		return nil, errors.New("encrypted artifact has an invalid nonce")
+	}
+	decrypted, err := aead.Open(nil, artifact.Nonce, artifact.Ciphertext, additionalData)
+	if err != nil {
+		return nil, errors.Wrap(err, "artifact was not encrypted with the artifact key of the wallet")
+	}
+	return decrypted, nil
+}
+
+func artifactAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create artifact cipher")
+	}
+	return cipher.NewGCM(block)
+}
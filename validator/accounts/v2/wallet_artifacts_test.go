@@ -0,0 +1,111 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestEncryptArtifacts(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		walletPasswordFile:  passwordFile,
+		accountPasswordFile: passwordFile,
+		keymanagerKind:      v2keymanager.Direct,
+	}
+	_, err := CreateWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	require.NoError(t, CreateAccount(setupWalletCtx(t, cfg)))
+	ctx := context.Background()
+	wallet, err := OpenWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	accountNames, err := wallet.files().listDirs(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(accountNames))
+	depositDataPath := filepath.Join(walletDir, v2keymanager.Direct.String(), accountNames[0], direct.DepositDataFileName)
+	want, err := ioutil.ReadFile(depositDataPath)
+	require.NoError(t, err)
+
+	require.NoError(t, EncryptArtifacts(setupWalletCtx(t, cfg)))
+	onDisk, err := ioutil.ReadFile(depositDataPath)
+	require.NoError(t, err)
+	assert.Equal(t, true, isEncryptedArtifact(onDisk))
+	wallet, err = OpenWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	depositData, err := wallet.ReadFileAtPath(ctx, accountNames[0], direct.DepositDataFileName)
+	require.NoError(t, err)
+	assert.DeepEqual(t, want, depositData)
+
+	// Artifacts of accounts created afterwards are encrypted as they are written.
+	require.NoError(t, CreateAccount(setupWalletCtx(t, cfg)))
+	accountNames, err = wallet.files().listDirs(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(accountNames))
+	for _, accountName := range accountNames {
+		onDisk, err := ioutil.ReadFile(filepath.Join(walletDir, v2keymanager.Direct.String(), accountName, direct.DepositDataFileName))
+		require.NoError(t, err)
+		assert.Equal(t, true, isEncryptedArtifact(onDisk))
+	}
+
+	// An artifact is bound to its path, so it cannot be swapped for another file or for the same
+	// file of another account.
+	_, err = decryptArtifact(wallet.artifactKey, path.Join(accountNames[1], direct.DepositDataJSONFileName), onDisk)
+	assert.ErrorContains(t, "not encrypted with the artifact key", err)
+	_, err = decryptArtifact(wallet.artifactKey, path.Join(accountNames[0], direct.DepositDataFileName), onDisk)
+	assert.ErrorContains(t, "not encrypted with the artifact key", err)
+	assert.Equal(t, true, isArtifactFile("withdrawal-keystore-0123456789abcdef.json"))
+
+	// Artifacts encrypted before their path was authenticated are still read, and encrypted again
+	// with their path.
+	want, err = wallet.ReadFileAtPath(ctx, accountNames[1], direct.DepositDataFileName)
+	require.NoError(t, err)
+	aead, err := artifactAEAD(wallet.artifactKey)
+	require.NoError(t, err)
+	nonce := make([]byte, aead.NonceSize())
+	legacy, err := json.Marshal(&encryptedArtifact{
+		Version:    legacyEncryptedArtifactVersion,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, want, []byte(direct.DepositDataFileName)),
+	})
+	require.NoError(t, err)
+	legacyPath := filepath.Join(walletDir, v2keymanager.Direct.String(), accountNames[1], direct.DepositDataFileName)
+	require.NoError(t, ioutil.WriteFile(legacyPath, legacy, os.ModePerm))
+	depositData, err = wallet.ReadFileAtPath(ctx, accountNames[1], direct.DepositDataFileName)
+	require.NoError(t, err)
+	assert.DeepEqual(t, want, depositData)
+	require.NoError(t, EncryptArtifacts(setupWalletCtx(t, cfg)))
+	onDisk, err = ioutil.ReadFile(legacyPath)
+	require.NoError(t, err)
+	assert.Equal(t, encryptedArtifactVersion, encryptedArtifactVersionOf(onDisk))
+
+	// Renaming an account encrypts its artifacts again for its new path.
+	require.NoError(t, wallet.renameAccount(accountNames[1], "renamed-account"))
+	depositData, err = wallet.ReadFileAtPath(ctx, "renamed-account", direct.DepositDataFileName)
+	require.NoError(t, err)
+	assert.DeepEqual(t, want, depositData)
+	accountNames[1] = "renamed-account"
+
+	// Changing the wallet password only re-encrypts the artifact key.
+	newPasswordFile := filepath.Join(filepath.Dir(passwordFile), "new-wallet-password.txt")
+	require.NoError(t, ioutil.WriteFile(newPasswordFile, []byte("Sh1nyN3wWall3t!"), os.ModePerm))
+	cfg.newWalletPassword = newPasswordFile
+	require.NoError(t, ChangeWalletPassword(setupWalletCtx(t, cfg)))
+	cfg.walletPasswordFile = newPasswordFile
+	wallet, err = OpenWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	depositData, err = wallet.ReadFileAtPath(ctx, accountNames[0], direct.DepositDataFileName)
+	require.NoError(t, err)
+	assert.Equal(t, false, isEncryptedArtifact(depositData))
+	assert.Equal(t, 2, len(walletPubKeys(t, cfg)))
+}
@@ -0,0 +1,210 @@
+package v2
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
+	"github.com/prysmaticlabs/prysm/validator/db/kv"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/urfave/cli/v2"
+	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+)
+
+const (
+	// walletBackupFileNameFormat is the file name of a wallet backup created at a unix timestamp.
+	walletBackupFileNameFormat = "wallet-backup-%d.json"
+	walletBackupVersion        = 1
+	// Entries of the encrypted backup archive.
+	walletBackupManifestName    = "manifest.json"
+	walletBackupWalletDir       = "wallet"
+	walletBackupPasswordsDir    = "passwords"
+	walletBackupValidatorDBName = "validator.db"
+)
+
+// walletBackup is the file written by wallet-v2 backup. The backup archive is encrypted with
+// keystorev4, the same crypto as EIP-2335 keystores, so nothing about the wallet is readable
+// without the backup password.
+type walletBackup struct {
+	Version   int                    `json:"version"`
+	UUID      string                 `json:"uuid"`
+	CreatedAt int64                  `json:"created_at"`
+	Crypto    map[string]interface{} `json:"crypto"`
+}
+
+// walletBackupManifest describes the contents of a backup archive.
+type walletBackupManifest struct {
+	KeymanagerKind     string `json:"keymanager_kind"`
+	HasPasswords       bool   `json:"has_passwords"`
+	HasValidatorDB     bool   `json:"has_validator_db"`
+	PasswordsDirectory string `json:"passwords_directory,omitempty"`
+}
+
+// BackupWallet writes the whole wallet directory, the account passwords of a non-HD wallet and
+// the validator database with its slashing protection history into a single file, encrypted
+// with a backup password. The validator must be stopped so the database can be read.
+func BackupWallet(cliCtx *cli.Context) error {
+	wallet, err := OpenWallet(cliCtx)
+	if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	if err := wallet.checkApproval(cliCtx, approvalActionBackupWallet, nil /* the whole wallet */); err != nil {
+		return err
+	}
+	if err := wallet.checkTOTP(cliCtx, "backing up the wallet"); err != nil {
+		return err
+	}
+	backupDir, err := inputDirectory(cliCtx, backupDirPromptText, flags.BackupDirFlag)
+	if err != nil {
+		return errors.Wrap(err, "could not parse backup directory")
+	}
+	backupPassword, err := inputPassword(cliCtx, flags.BackupPasswordFileFlag, newBackupPasswordPromptText, confirmPass)
+	if err != nil {
+		return errors.Wrap(err, "could not input backup password")
+	}
+	archive, err := wallet.backupArchive(validatorDataDir(cliCtx))
+	if err != nil {
+		return err
+	}
+	cryptoFields, err := keystorev4.New().Encrypt(archive, backupPassword)
+	if err != nil {
+		return errors.Wrap(err, "could not encrypt backup")
+	}
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return errors.Wrap(err, "could not generate backup uuid")
+	}
+	createdAt := roughtime.Now().Unix()
+	encoded, err := json.MarshalIndent(&walletBackup{
+		Version:   walletBackupVersion,
+		UUID:      id.String(),
+		CreatedAt: createdAt,
+		Crypto:    cryptoFields,
+	}, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "could not marshal backup")
+	}
+	if err := os.MkdirAll(backupDir, params.BeaconIoConfig().ReadWriteExecutePermissions); err != nil {
+		return errors.Wrap(err, "could not create backup directory")
+	}
+	backupPath := filepath.Join(backupDir, fmt.Sprintf(walletBackupFileNameFormat, createdAt))
+	if fileExists(backupPath) {
+		return fmt.Errorf("backup file already exists at path: %s", backupPath)
+	}
+	if err := writeFileAtomic(backupPath, encoded, params.BeaconIoConfig().ReadWritePermissions); err != nil {
+		return errors.Wrapf(err, "could not write %s", backupPath)
+	}
+	log.WithField("backupPath", backupPath).Info("Successfully backed up wallet")
+	return nil
+}
+
+// Creates the zip archive holding the wallet directory, the account passwords directory of
+// non-HD wallets, the validator database if there is one in dataDir, and a manifest.
+func (w *Wallet) backupArchive(dataDir string) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	archive := zip.NewWriter(buf)
+	manifest := &walletBackupManifest{
+		KeymanagerKind: w.keymanagerKind.String(),
+	}
+	if err := addDirToZip(archive, w.walletDir, walletBackupWalletDir); err != nil {
+		return nil, errors.Wrap(err, "could not archive wallet directory")
+	}
+	if w.passwordsDir != "" {
+		ok, err := hasDir(w.passwordsDir)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read passwords directory")
+		}
+		if ok {
+			if err := addDirToZip(archive, w.passwordsDir, walletBackupPasswordsDir); err != nil {
+				return nil, errors.Wrap(err, "could not archive passwords directory")
+			}
+			manifest.HasPasswords = true
+			manifest.PasswordsDirectory = w.passwordsDir
+		}
+	}
+
+	store, err := kv.GetKVStore(dataDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open validator database, make sure the validator is stopped")
+	}
+	if store == nil {
+		log.WithField("datadir", dataDir).Warn("No validator database found, the backup holds no slashing protection history")
+	} else {
+		writer, err := archive.Create(walletBackupValidatorDBName)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create validator database entry")
+		}
+		_, err = store.Backup(writer)
+		if closeErr := store.Close(); closeErr != nil {
+			log.WithError(closeErr).Error("Could not close validator database")
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "could not archive validator database")
+		}
+		manifest.HasValidatorDB = true
+	}
+
+	encodedManifest, err := json.MarshalIndent(manifest, "", "\t")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal backup manifest")
+	}
+	writer, err := archive.Create(walletBackupManifestName)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create backup manifest entry")
+	}
+	if _, err := writer.Write(encodedManifest); err != nil {
+		return nil, errors.Wrap(err, "could not write backup manifest")
+	}
+	if err := archive.Close(); err != nil {
+		return nil, errors.Wrap(err, "could not close backup archive")
+	}
+	return buf.Bytes(), nil
+}
+
+// Adds every regular file under dir to the archive, named by its slash separated path relative
+// to dir under the given prefix.
+func addDirToZip(archive *zip.Writer, dir string, prefix string) error {
+	return filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return errors.Wrapf(err, "could not walk %s", filePath)
+		}
+		// The lock of a wallet belongs to the validator client using it, not to the wallet.
+		if !info.Mode().IsRegular() || info.Name() == walletLockFileName {
+			return nil
+		}
+		relPath, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return errors.Wrap(err, "could not get zip file info header")
+		}
+		header.Name = path.Join(prefix, filepath.ToSlash(relPath))
+		header.Method = zip.Deflate
+		writer, err := archive.CreateHeader(header)
+		if err != nil {
+			return errors.Wrap(err, "could not create header")
+		}
+		f, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := f.Close(); err != nil {
+				log.WithError(err).Error("Could not close file")
+			}
+		}()
+		_, err = io.Copy(writer, f)
+		return err
+	})
+}
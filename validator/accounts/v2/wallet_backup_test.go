@@ -0,0 +1,97 @@
+package v2
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	"github.com/prysmaticlabs/prysm/validator/db/kv"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+)
+
+func TestBackupWallet_Direct(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	backupDir := filepath.Join(testutil.TempDir(), t.Name(), "backup")
+	dataDir := filepath.Join(testutil.TempDir(), t.Name(), "datadir")
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(filepath.Dir(backupDir)), "Failed to remove directory")
+	})
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		walletPasswordFile:  passwordFile,
+		accountPasswordFile: passwordFile,
+		backupPasswordFile:  passwordFile,
+		exportDir:           backupDir,
+		dataDir:             dataDir,
+		keymanagerKind:      v2keymanager.Direct,
+	})
+	_, err := CreateWallet(cliCtx)
+	require.NoError(t, err)
+	require.NoError(t, CreateAccount(cliCtx))
+
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	keymanager, err := wallet.InitializeKeymanager(ctx, true)
+	require.NoError(t, err)
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(pubKeys))
+	store, err := kv.NewKVStore(dataDir, pubKeys)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+	accountNames, err := keymanager.(*direct.Keymanager).ValidatingAccountNames()
+	require.NoError(t, err)
+
+	require.NoError(t, BackupWallet(cliCtx))
+
+	files, err := ioutil.ReadDir(backupDir)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(files))
+	assert.Equal(t, true, strings.HasPrefix(files[0].Name(), "wallet-backup-"))
+	encoded, err := ioutil.ReadFile(filepath.Join(backupDir, files[0].Name()))
+	require.NoError(t, err)
+	backup := &walletBackup{}
+	require.NoError(t, json.Unmarshal(encoded, backup))
+	assert.Equal(t, walletBackupVersion, backup.Version)
+	_, err = keystorev4.New().Decrypt(backup.Crypto, "wrong-password")
+	assert.ErrorContains(t, "invalid checksum", err)
+	decrypted, err := keystorev4.New().Decrypt(backup.Crypto, password)
+	require.NoError(t, err)
+
+	archive, err := zip.NewReader(bytes.NewReader(decrypted), int64(len(decrypted)))
+	require.NoError(t, err)
+	entries := make(map[string]*zip.File)
+	for _, f := range archive.File {
+		entries[f.Name] = f
+	}
+	for _, name := range []string{
+		"wallet/direct/" + KeymanagerConfigFileName,
+		"passwords/" + accountNames[0] + direct.PasswordFileSuffix,
+		walletBackupValidatorDBName,
+		walletBackupManifestName,
+	} {
+		_, ok := entries[name]
+		assert.Equal(t, true, ok, "Missing backup entry %s", name)
+	}
+	rc, err := entries[walletBackupManifestName].Open()
+	require.NoError(t, err)
+	manifest := &walletBackupManifest{}
+	require.NoError(t, json.NewDecoder(rc).Decode(manifest))
+	require.NoError(t, rc.Close())
+	assert.Equal(t, v2keymanager.Direct.String(), manifest.KeymanagerKind)
+	assert.Equal(t, true, manifest.HasPasswords)
+	assert.Equal(t, true, manifest.HasValidatorDB)
+}
@@ -0,0 +1,198 @@
+package v2
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+)
+
+// BundleWalletConfig selects the wallet bundle a wallet is opened from. A wallet bundle is a single
+// zip file holding a wallet directory under wallet/ and, for non-HD wallets, optionally the
+// account passwords under passwords/, the layout of the archive of a wallet backup, so it can be
+// written with any zip tool from a provisioned wallet.
+type BundleWalletConfig struct {
+	// Path of the bundle.
+	Path string
+	// ExtractDir is a new or empty directory the bundle is extracted into and opened from, so
+	// the wallet can be written to. If not set, the wallet is kept in memory and is read-only.
+	ExtractDir string
+	// PasswordFile holds the wallet password, which is prompted for if needed and not given.
+	PasswordFile string
+}
+
+// OpenBundleWallet opens the wallet of a single-file bundle, to distribute pre-provisioned wallets
+// to ephemeral validator instances. The wallet is kept in memory read-only, nothing of it being
+// written to disk, unless the bundle is extracted into cfg.ExtractDir first and opened from there.
+// Account passwords of the bundle are used in place of the passwords directory of the wallet.
+func OpenBundleWallet(ctx context.Context, cfg *BundleWalletConfig) (*Wallet, error) {
+	bundlePath, err := expandPath(cfg.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse wallet bundle path")
+	}
+	archive, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open wallet bundle")
+	}
+	defer func() {
+		if err := archive.Close(); err != nil {
+			log.WithError(err).Error("Could not close wallet bundle")
+		}
+	}()
+	bundle, err := readWalletBundle(&archive.Reader)
+	if err != nil {
+		return nil, err
+	}
+	inputWalletPassword := func() (string, error) {
+		return inputWalletPasswordOf(cfg.PasswordFile, bundlePath)
+	}
+	var w *Wallet
+	if cfg.ExtractDir != "" {
+		w, err = bundle.extractWallet(cfg.ExtractDir, inputWalletPassword)
+	} else {
+		w, err = bundle.memoryWallet(ctx, bundlePath, inputWalletPassword)
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.WithField("bundle", bundlePath).Info("Successfully opened wallet bundle")
+	return w, nil
+}
+
+// Reads the entries of a wallet bundle, checking it holds a single wallet of the layout of a
+// wallet backup archive.
+func readWalletBundle(archive *zip.Reader) (*walletRestore, error) {
+	bundle := &walletRestore{entries: make(map[string]*zip.File, len(archive.File))}
+	kinds := make(map[string]bool)
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+		if !isSafeArchivePath(entry.Name) {
+			return nil, fmt.Errorf("wallet bundle entry %q is not a safe relative path", entry.Name)
+		}
+		dir := strings.SplitN(entry.Name, "/", 3)
+		switch {
+		case len(dir) == 3 && dir[0] == walletBackupWalletDir:
+			kinds[dir[1]] = true
+		case len(dir) == 2 && dir[0] == walletBackupPasswordsDir:
+		default:
+			return nil, fmt.Errorf(
+				"wallet bundle entry %q is neither in %s/<keymanager kind>/ nor in %s/",
+				entry.Name,
+				walletBackupWalletDir,
+				walletBackupPasswordsDir,
+			)
+		}
+		bundle.entries[entry.Name] = entry
+	}
+	if len(kinds) != 1 {
+		return nil, fmt.Errorf("wanted 1 wallet in wallet bundle, found %d", len(kinds))
+	}
+	for kind := range kinds {
+		var err error
+		bundle.kind, err = v2keymanager.ParseKind(kind)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not parse keymanager kind of wallet bundle")
+		}
+	}
+	if bundle.kind != v2keymanager.Direct && len(bundle.entriesUnder(walletBackupPasswordsDir)) > 0 {
+		return nil, fmt.Errorf("wallet bundle holds account passwords of a %s wallet", bundle.kind)
+	}
+	return bundle, nil
+}
+
+// Extracts the bundle into a new or empty directory, and opens the wallet from there.
+func (r *walletRestore) extractWallet(extractDir string, inputWalletPassword func() (string, error)) (*Wallet, error) {
+	extractDir, err := canonicalPath(extractDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse wallet bundle extract directory")
+	}
+	if isDir(extractDir) {
+		entries, err := ioutil.ReadDir(extractDir)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not list wallet bundle extract directory")
+		}
+		if len(entries) > 0 {
+			return nil, fmt.Errorf("wallet bundle extract directory %s is not empty", extractDir)
+		}
+	}
+	walletDir := filepath.Join(extractDir, walletBackupWalletDir)
+	if err := r.extract(walletBackupWalletDir, walletDir); err != nil {
+		return nil, errors.Wrap(err, "could not extract wallet of wallet bundle")
+	}
+	passwordsDir := filepath.Join(extractDir, walletBackupPasswordsDir)
+	hasPasswords := len(r.entriesUnder(walletBackupPasswordsDir)) > 0
+	if hasPasswords {
+		if err := r.extract(walletBackupPasswordsDir, passwordsDir); err != nil {
+			return nil, errors.Wrap(err, "could not extract account passwords of wallet bundle")
+		}
+	}
+	w, err := openWallet(walletDir, false /* read only */, inputWalletPassword)
+	if err != nil {
+		return nil, err
+	}
+	if hasPasswords {
+		w.passwordsDir = passwordsDir
+	}
+	log.WithField("path", extractDir).Info("Extracted wallet bundle")
+	return w, nil
+}
+
+// Opens the wallet of the bundle kept in memory, read-only.
+func (r *walletRestore) memoryWallet(
+	ctx context.Context, bundlePath string, inputWalletPassword func() (string, error),
+) (*Wallet, error) {
+	accountsDir := path.Join(walletBackupWalletDir, r.kind.String())
+	files := make(map[string][]byte)
+	var passwords map[string]string
+	names := make([]string, 0, len(r.entries))
+	for name := range r.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		data, err := readZipEntry(r.entries[name])
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read %s of wallet bundle", name)
+		}
+		if strings.HasPrefix(name, walletBackupPasswordsDir+"/") {
+			if passwords == nil {
+				passwords = make(map[string]string)
+			}
+			passwords[strings.TrimPrefix(name, walletBackupPasswordsDir+"/")] = string(data)
+			continue
+		}
+		files[strings.TrimPrefix(name, accountsDir+"/")] = data
+	}
+	if _, ok := files[walletStorageConfigFileName]; ok {
+		return nil, errors.New("wallet bundle holds a wallet kept in a wallet storage, only the files of a wallet can be bundled")
+	}
+	storage := newMemoryStorage()
+	if err := storage.writeFiles(ctx, files); err != nil {
+		return nil, err
+	}
+	w := &Wallet{
+		walletDir:      bundlePath,
+		accountsPath:   filepath.Join(bundlePath, r.kind.String()),
+		keymanagerKind: r.kind,
+		storageURL:     "memory://",
+		storage:        storage,
+		passwords:      passwords,
+		readOnly:       true,
+	}
+	if err := w.readConfig(inputWalletPassword); err != nil {
+		return nil, err
+	}
+	if err := w.migrateWalletLayout(ctx); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
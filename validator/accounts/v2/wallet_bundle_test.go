@@ -0,0 +1,86 @@
+package v2
+
+import (
+	"archive/zip"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+)
+
+func TestOpenBundleWallet(t *testing.T) {
+	ctx := context.Background()
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		walletPasswordFile:  passwordFile,
+		accountPasswordFile: passwordFile,
+		keymanagerKind:      v2keymanager.Direct,
+	}
+	_, err := CreateWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	require.NoError(t, CreateAccount(setupWalletCtx(t, cfg)))
+	want := walletPubKeys(t, &testWalletConfig{walletDir: walletDir, passwordsDir: passwordsDir})
+
+	bundleDir := filepath.Join(testutil.TempDir(), t.Name())
+	require.NoError(t, os.MkdirAll(bundleDir, os.ModePerm))
+	t.Cleanup(func() {
+		assert.NoError(t, os.RemoveAll(bundleDir))
+	})
+	bundlePath := filepath.Join(bundleDir, "wallet.zip")
+	writeBundle := func(dirs map[string]string) {
+		f, err := os.Create(bundlePath)
+		require.NoError(t, err)
+		archive := zip.NewWriter(f)
+		for prefix, dir := range dirs {
+			require.NoError(t, addDirToZip(archive, dir, prefix))
+		}
+		require.NoError(t, archive.Close())
+		require.NoError(t, f.Close())
+	}
+	pubKeysOf := func(wallet *Wallet) [][48]byte {
+		keymanager, err := wallet.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+		require.NoError(t, err)
+		pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+		require.NoError(t, err)
+		return pubKeys
+	}
+	writeBundle(map[string]string{
+		walletBackupWalletDir:    walletDir,
+		walletBackupPasswordsDir: passwordsDir,
+	})
+
+	// Wallets kept in memory are read-only and read the account passwords of the bundle.
+	require.NoError(t, os.RemoveAll(passwordsDir))
+	wallet, err := OpenBundleWallet(ctx, &BundleWalletConfig{Path: bundlePath})
+	require.NoError(t, err)
+	assert.Equal(t, true, wallet.ReadOnly())
+	assert.DeepEqual(t, want, pubKeysOf(wallet))
+	assert.ErrorContains(t, ErrWalletReadOnly.Error(), wallet.WriteFileAtPath(ctx, "", "file", []byte("data")))
+
+	// Extracted wallets can be written to, and use the account passwords extracted.
+	extractDir := filepath.Join(bundleDir, "extracted")
+	wallet, err = OpenBundleWallet(ctx, &BundleWalletConfig{Path: bundlePath, ExtractDir: extractDir})
+	require.NoError(t, err)
+	assert.Equal(t, false, wallet.ReadOnly())
+	assert.Equal(t, filepath.Join(extractDir, walletBackupPasswordsDir), wallet.PasswordsDir())
+	assert.DeepEqual(t, want, pubKeysOf(wallet))
+	require.NoError(t, wallet.WriteFileAtPath(ctx, "", "file", []byte("data")))
+	_, err = OpenBundleWallet(ctx, &BundleWalletConfig{Path: bundlePath, ExtractDir: extractDir})
+	assert.ErrorContains(t, "is not empty", err)
+
+	// Bundles only hold a wallet and its account passwords.
+	writeBundle(map[string]string{"other": wallet.PasswordsDir(), walletBackupWalletDir: walletDir})
+	_, err = OpenBundleWallet(ctx, &BundleWalletConfig{Path: bundlePath})
+	assert.ErrorContains(t, "is neither in", err)
+	require.NoError(t, ioutil.WriteFile(bundlePath, []byte("not a zip file"), os.ModePerm))
+	_, err = OpenBundleWallet(ctx, &BundleWalletConfig{Path: bundlePath})
+	assert.ErrorContains(t, "could not open wallet bundle", err)
+}
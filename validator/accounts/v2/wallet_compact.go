@@ -0,0 +1,211 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/urfave/cli/v2"
+)
+
+// CompactWallet merges the keystores of the accounts of a non-HD wallet into a single accounts
+// keystore encrypted with the wallet password, so the validator client decrypts one keystore at
+// startup rather than one per account, or with --expand writes the keystores of the accounts
+// back. Account passwords stay in the passwords directory of the wallet, as the keystores written
+// back are encrypted with them.
+func CompactWallet(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	wallet, err := openDirectWallet(cliCtx)
+	if err != nil {
+		return err
+	}
+	expand := cliCtx.Bool(flags.ExpandWalletFlag.Name)
+	if wallet.walletPassword == "" {
+		if expand {
+			return errors.New("wallet has no accounts keystore to expand")
+		}
+		wallet.walletPassword, err = inputPassword(cliCtx, flags.WalletPasswordFileFlag, newWalletPasswordPromptText, confirmPass)
+		if err != nil {
+			return errors.Wrap(err, "could not get password")
+		}
+	}
+	if !expand {
+		if err := wallet.setAccountsKeystoreKDF(ctx, cliCtx); err != nil {
+			return err
+		}
+	}
+	keymanager, err := wallet.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+	if err != nil {
+		return errors.Wrap(err, "could not initialize keymanager")
+	}
+	km, ok := keymanager.(*direct.Keymanager)
+	if !ok {
+		return errors.New("could not assert keymanager interface to concrete type")
+	}
+	if expand {
+		expanded, err := wallet.expandAccounts(ctx, km)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Wrote the keystores of %s accounts back from the accounts keystore\n", au.BrightGreen(len(expanded)))
+		return nil
+	}
+	compacted, err := wallet.compactAccounts(ctx, km)
+	if err != nil {
+		return err
+	}
+	fmt.Printf(
+		"Compacted the keystores of %s accounts into %s\n",
+		au.BrightGreen(len(compacted)),
+		au.BrightGreen(filepath.Join(wallet.accountsPath, direct.AccountsKeystoreFileName)),
+	)
+	return nil
+}
+
+// Writes the scrypt cost of the accounts keystore given by --accounts-keystore-scrypt-n, -r and -p
+// to the keymanager config of the wallet, on top of the cost it already has, if any is given.
+func (w *Wallet) setAccountsKeystoreKDF(ctx context.Context, cliCtx *cli.Context) error {
+	if !cliCtx.IsSet(flags.AccountsKeystoreScryptNFlag.Name) &&
+		!cliCtx.IsSet(flags.AccountsKeystoreScryptRFlag.Name) &&
+		!cliCtx.IsSet(flags.AccountsKeystoreScryptPFlag.Name) {
+		return nil
+	}
+	if err := w.checkWritable(); err != nil {
+		return err
+	}
+	enc, err := w.ReadKeymanagerConfigFromDisk(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not read keymanager config")
+	}
+	cfg, err := direct.UnmarshalConfigFile(enc, w.walletPassword)
+	if err != nil {
+		return errors.Wrap(err, "could not unmarshal keymanager config")
+	}
+	kdf := &direct.KDFConfig{Function: direct.ScryptKDF}
+	if cfg.AccountsKeystoreKDF != nil {
+		kdf = cfg.AccountsKeystoreKDF
+	}
+	if cliCtx.IsSet(flags.AccountsKeystoreScryptNFlag.Name) {
+		kdf.ScryptN = cliCtx.Int(flags.AccountsKeystoreScryptNFlag.Name)
+	}
+	if cliCtx.IsSet(flags.AccountsKeystoreScryptRFlag.Name) {
+		kdf.ScryptR = cliCtx.Int(flags.AccountsKeystoreScryptRFlag.Name)
+	}
+	if cliCtx.IsSet(flags.AccountsKeystoreScryptPFlag.Name) {
+		kdf.ScryptP = cliCtx.Int(flags.AccountsKeystoreScryptPFlag.Name)
+	}
+	if err := kdf.ValidateAccountsKeystore(); err != nil {
+		return err
+	}
+	cfg.AccountsKeystoreKDF = kdf
+	encodedCfg, err := direct.MarshalConfigFile(ctx, cfg)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal keymanager config")
+	}
+	if err := w.WriteKeymanagerConfigToDisk(ctx, encodedCfg); err != nil {
+		return errors.Wrap(err, "could not write keymanager config")
+	}
+	log.Infof("Encrypting the accounts keystore with %s", kdf)
+	return nil
+}
+
+// Moves the validating keys of the accounts of the wallet into its accounts keystore, and removes
+// their keystores once it holds them, returning the names of the accounts compacted.
+func (w *Wallet) compactAccounts(ctx context.Context, km *direct.Keymanager) ([]string, error) {
+	if err := w.checkWritable(); err != nil {
+		return nil, err
+	}
+	ctx = withJournalAction(ctx, journalActionWalletCompacted)
+	compacted, err := km.CompactAccounts(ctx, w.walletPassword)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not compact accounts")
+	}
+	storage := w.files()
+	removed := make([]string, 0, len(compacted))
+	for _, name := range compacted {
+		matches, err := storage.glob(ctx, name, w.keystoreFileGlob())
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not find keystore of account %s", name)
+		}
+		for _, match := range matches {
+			if err := storage.removeFile(ctx, path.Join(name, match)); err != nil {
+				return nil, errors.Wrapf(err, "could not remove keystore of account %s", name)
+			}
+			removed = append(removed, path.Join(name, match))
+		}
+	}
+	if err := w.recordMutation(ctx, &walletJournalEntry{
+		Action:  journalActionWalletCompacted,
+		Deleted: removed,
+	}); err != nil {
+		return nil, err
+	}
+	return compacted, nil
+}
+
+// Writes the keystores of the accounts of the accounts keystore of the wallet back, and removes
+// the accounts keystore once they are written, returning the names of the accounts expanded.
+func (w *Wallet) expandAccounts(ctx context.Context, km *direct.Keymanager) ([]string, error) {
+	if err := w.checkWritable(); err != nil {
+		return nil, err
+	}
+	ctx = withJournalAction(ctx, journalActionWalletExpanded)
+	expanded, err := km.ExpandAccounts(ctx, w.walletPassword)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not expand accounts")
+	}
+	if err := w.files().removeFile(ctx, direct.AccountsKeystoreFileName); err != nil {
+		return nil, errors.Wrap(err, "could not remove accounts keystore")
+	}
+	if err := w.recordMutation(ctx, &walletJournalEntry{
+		Action:  journalActionWalletExpanded,
+		Deleted: []string{direct.AccountsKeystoreFileName},
+	}); err != nil {
+		return nil, err
+	}
+	return expanded, nil
+}
+
+// Returns the names of the accounts of the wallet compacted into its accounts keystore, read from
+// its public keys without decrypting it.
+func (w *Wallet) compactedAccountNames(ctx context.Context) (map[string]bool, error) {
+	encoded, err := w.files().readFile(ctx, direct.AccountsKeystoreFileName)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read accounts keystore")
+	}
+	accountsKeystore := &direct.AccountsKeystore{}
+	if err := json.Unmarshal(encoded, accountsKeystore); err != nil {
+		return nil, errors.Wrap(err, "could not decode accounts keystore")
+	}
+	names := make(map[string]bool, len(accountsKeystore.PublicKeys))
+	for name := range accountsKeystore.PublicKeys {
+		names[name] = true
+	}
+	return names, nil
+}
+
+// Returns an error if accounts of the wallet are compacted into its accounts keystore, as the
+// commands managing accounts one at a time work on their keystores.
+func (w *Wallet) checkNotCompacted() error {
+	_, err := w.files().readFile(context.Background(), direct.AccountsKeystoreFileName)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "could not read accounts keystore")
+	}
+	if err == nil {
+		return fmt.Errorf(
+			"accounts of the wallet are compacted into %s, expand the wallet with wallet-v2 compact --%s first",
+			direct.AccountsKeystoreFileName,
+			flags.ExpandWalletFlag.Name,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestCompactWallet(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFilePath,
+		keymanagerKind:     v2keymanager.Direct,
+	}
+	wallet, err := NewWallet(setupWalletCtx(t, cfg), v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	names := make([]string, 3)
+	keystores := make(map[string][]byte, len(names))
+	keystoreFileNames := make(map[string]string, len(names))
+	for i := range names {
+		names[i], err = keymanager.CreateAccount(ctx, password)
+		require.NoError(t, err)
+		keystoreFileNames[names[i]], err = wallet.FileNameAtPath(ctx, names[i], wallet.keystoreFileGlob())
+		require.NoError(t, err)
+		keystores[names[i]], err = wallet.ReadFileAtPath(ctx, names[i], keystoreFileNames[names[i]])
+		require.NoError(t, err)
+	}
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	// Public keys of the wallet opened again, which the keymanager returns in no particular order.
+	openKeymanager := func() map[[48]byte]bool {
+		wallet, err := OpenWallet(setupWalletCtx(t, cfg))
+		require.NoError(t, err)
+		keymanager, err := wallet.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+		require.NoError(t, err)
+		validatingKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+		require.NoError(t, err)
+		loaded := make(map[[48]byte]bool, len(validatingKeys))
+		for _, pubKey := range validatingKeys {
+			loaded[pubKey] = true
+		}
+		return loaded
+	}
+	want := make(map[[48]byte]bool, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		want[pubKey] = true
+	}
+
+	cfg.expandWallet = true
+	assert.ErrorContains(t, "no accounts keystore to expand", CompactWallet(setupWalletCtx(t, cfg)))
+	cfg.expandWallet = false
+	require.NoError(t, CompactWallet(setupWalletCtx(t, cfg)))
+	accountsKeystorePath := filepath.Join(wallet.AccountsDir(), direct.AccountsKeystoreFileName)
+	assert.Equal(t, true, fileExists(accountsKeystorePath))
+	for _, name := range names {
+		_, err := wallet.ReadFileAtPath(ctx, name, wallet.keystoreFileGlob())
+		assert.ErrorContains(t, "no files found", err)
+	}
+	// Compacted wallets are opened with the wallet password and load every account at once.
+	assert.DeepEqual(t, want, openKeymanager())
+	compacted, err := OpenWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	_, err = compacted.accountPubKeys(ctx)
+	assert.ErrorContains(t, "wallet-v2 compact --expand", err)
+
+	// Accounts created after compacting are compacted along with those already compacted.
+	keymanager, err = direct.NewKeymanager(ctx, compacted, direct.DefaultConfig())
+	require.NoError(t, err)
+	newName, err := keymanager.CreateAccount(ctx, password)
+	require.NoError(t, err)
+	newKeystore, err := compacted.ReadFileAtPath(ctx, newName, compacted.keystoreFileGlob())
+	require.NoError(t, err)
+	newPubKey, err := keystorePubKey(newKeystore)
+	require.NoError(t, err)
+	want[newPubKey] = true
+	assert.DeepEqual(t, want, openKeymanager())
+	require.NoError(t, CompactWallet(setupWalletCtx(t, cfg)))
+	assert.DeepEqual(t, want, openKeymanager())
+
+	// The accounts keystore is encrypted again with a scrypt cost set by flags, which is kept in the
+	// keymanager config and may not be lower than the minimum of accounts keystores.
+	cfg.accountsKeystoreN = 1 << 14
+	assert.ErrorContains(t, "scrypt N of the accounts keystore must be at least", CompactWallet(setupWalletCtx(t, cfg)))
+	cfg.accountsKeystoreN = 0
+	cfg.accountsKeystoreP = 2
+	require.NoError(t, CompactWallet(setupWalletCtx(t, cfg)))
+	cfg.accountsKeystoreP = 0
+	encoded, err := ioutil.ReadFile(accountsKeystorePath)
+	require.NoError(t, err)
+	accountsKeystore := &direct.AccountsKeystore{}
+	require.NoError(t, json.Unmarshal(encoded, accountsKeystore))
+	kdf, ok := accountsKeystore.Crypto["kdf"].(map[string]interface{})
+	require.Equal(t, true, ok)
+	assert.Equal(t, float64(2), kdf["params"].(map[string]interface{})["p"])
+	configFile, err := compacted.ReadKeymanagerConfigFromDisk(ctx)
+	require.NoError(t, err)
+	keymanagerCfg, err := direct.UnmarshalConfigFile(configFile, "" /* password */)
+	require.NoError(t, err)
+	require.NotNil(t, keymanagerCfg.AccountsKeystoreKDF)
+	assert.Equal(t, 2, keymanagerCfg.AccountsKeystoreKDF.ScryptP)
+	assert.DeepEqual(t, want, openKeymanager())
+
+	// Expanding writes back the keystores, decrypting with the passwords of their accounts and
+	// keeping their file names and uuids.
+	cfg.expandWallet = true
+	require.NoError(t, CompactWallet(setupWalletCtx(t, cfg)))
+	assert.Equal(t, false, fileExists(accountsKeystorePath))
+	for _, name := range names {
+		encoded, err := wallet.ReadFileAtPath(ctx, name, wallet.keystoreFileGlob())
+		require.NoError(t, err)
+		keystore := &v2keymanager.Keystore{}
+		require.NoError(t, json.Unmarshal(encoded, keystore))
+		previous := &v2keymanager.Keystore{}
+		require.NoError(t, json.Unmarshal(keystores[name], previous))
+		assert.Equal(t, previous.ID, keystore.ID)
+		assert.Equal(t, previous.Pubkey, keystore.Pubkey)
+		fileName, err := wallet.FileNameAtPath(ctx, name, wallet.keystoreFileGlob())
+		require.NoError(t, err)
+		assert.Equal(t, keystoreFileNames[name], fileName)
+	}
+	assert.DeepEqual(t, want, openKeymanager())
+}
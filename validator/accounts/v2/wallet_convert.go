@@ -0,0 +1,305 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/promptutil"
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/derived"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// convertDerivationLookahead is how many derivation indices past the number of accounts of a
+	// wallet are searched for its keys, as accounts may have been deleted from the wallet.
+	convertDerivationLookahead = 100
+	// retiredDirectWalletDirFormat names the directory next to a converted wallet directory the
+	// keystores and account passwords of the non-HD wallet are moved to.
+	retiredDirectWalletDirFormat = "%s-retired-direct-%d"
+	retiredPasswordsDirName      = "passwords"
+)
+
+const convertWalletConfirmText = "Rebuild the wallet as an HD wallet and retire its keystores (y/n)"
+
+// ConvertWallet rebuilds a non-HD wallet as an HD wallet derived from a mnemonic. Every account
+// of the wallet must hold the validating key of a derivation index of the mnemonic. Once the HD
+// wallet holds all of their keys, the keystores and account passwords of the non-HD wallet are
+// retired into a directory next to the wallet directory, rather than deleted.
+func ConvertWallet(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	if wallet.KeymanagerKind() != v2keymanager.Direct {
+		return errors.New("only non-HD wallets can be converted into an HD wallet")
+	}
+	// Retiring the keystores moves the accounts directory, which only wallets on disk have.
+	if err := wallet.checkOnDisk("converting the wallet"); err != nil {
+		return err
+	}
+	if err := wallet.checkApproval(cliCtx, approvalActionConvertWallet, nil /* the whole wallet */); err != nil {
+		return err
+	}
+	if err := wallet.checkTOTP(cliCtx, "converting the wallet"); err != nil {
+		return err
+	}
+	keymanager, err := wallet.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+	if err != nil {
+		return errors.Wrap(err, "could not initialize keymanager")
+	}
+	km, ok := keymanager.(*direct.Keymanager)
+	if !ok {
+		return errors.New("could not assert keymanager interface to concrete type")
+	}
+	accountNames, err := km.ValidatingAccountNames()
+	if err != nil {
+		return errors.Wrap(err, "could not fetch account names")
+	}
+	if len(accountNames) == 0 {
+		return errors.New("wallet has no accounts to convert")
+	}
+	accounts := make(map[[48]byte]string, len(accountNames))
+	for _, name := range accountNames {
+		pubKey, err := km.PublicKeyForAccount(name)
+		if err != nil {
+			return errors.Wrapf(err, "could not get public key for account %s", name)
+		}
+		accounts[pubKey] = name
+	}
+
+	mnemonic, err := inputMnemonic(cliCtx)
+	if err != nil {
+		return errors.Wrap(err, "could not get mnemonic phrase")
+	}
+	indices, err := derivationIndices(mnemonic, accounts)
+	if err != nil {
+		return err
+	}
+	nextAccount := printDerivationIndices(accounts, indices)
+	if !cliCtx.Bool(flags.SkipConvertConfirmFlag.Name) {
+		if _, err := promptutil.ValidatePrompt(convertWalletConfirmText, promptutil.ValidateConfirmation); err != nil {
+			return errors.Wrap(err, "wallet conversion not confirmed")
+		}
+	}
+	walletPassword := wallet.walletPassword
+	if walletPassword == "" {
+		walletPassword, err = inputPassword(cliCtx, flags.WalletPasswordFileFlag, newWalletPasswordPromptText, confirmPass)
+		if err != nil {
+			return errors.Wrap(err, "could not get password")
+		}
+	}
+
+	retiredDir, err := wallet.retireDirectAccounts(accountNames)
+	if err != nil {
+		return err
+	}
+	derivedWallet := &Wallet{
+		walletDir:       wallet.walletDir,
+		accountsPath:    filepath.Join(wallet.walletDir, v2keymanager.Derived.String()),
+		keymanagerKind:  v2keymanager.Derived,
+		walletPassword:  walletPassword,
+		encryptedConfig: wallet.encryptedConfig,
+	}
+	if err := derivedWallet.writeDerivedWallet(ctx, mnemonic, nextAccount, accounts); err != nil {
+		if rollbackErr := wallet.restoreRetiredAccounts(derivedWallet, retiredDir, accountNames); rollbackErr != nil {
+			log.WithError(rollbackErr).Errorf(
+				"Could not restore the non-HD wallet, its keystores and account passwords are in %s", retiredDir,
+			)
+		}
+		return errors.Wrap(err, "could not rebuild wallet as an HD wallet")
+	}
+	log.WithField("path", retiredDir).Warn(
+		"The keystores of the non-HD wallet were retired, they hold the same keys as the HD wallet " +
+			"and must never be used by another validator client. Securely erase them once you no longer need them",
+	)
+	fmt.Printf(
+		"Successfully converted the wallet at %s into an HD wallet with %s accounts\n",
+		au.BrightGreen(wallet.walletDir),
+		au.BrightMagenta(nextAccount),
+	)
+	return nil
+}
+
+// Finds the derivation index of the mnemonic each public key is the validating key of, failing
+// if any of them is not derived from the mnemonic.
+func derivationIndices(mnemonic string, accounts map[[48]byte]string) (map[[48]byte]uint64, error) {
+	searched := uint64(len(accounts) + convertDerivationLookahead)
+	derivedKeys, err := derived.ValidatingPublicKeysFromMnemonic(mnemonic, searched)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not derive validating keys from mnemonic")
+	}
+	indices := make(map[[48]byte]uint64, len(accounts))
+	for i, pubKey := range derivedKeys {
+		if _, ok := accounts[pubKey]; ok {
+			indices[pubKey] = uint64(i)
+		}
+	}
+	unmatched := make([]string, 0)
+	for pubKey, name := range accounts {
+		if _, ok := indices[pubKey]; !ok {
+			unmatched = append(unmatched, fmt.Sprintf("%s (%#x)", name, bytesutil.Trunc(pubKey[:])))
+		}
+	}
+	if len(unmatched) > 0 {
+		sort.Strings(unmatched)
+		return nil, fmt.Errorf(
+			"accounts %v are not derived from the mnemonic within its first %d derivation indices, "+
+				"an HD wallet cannot hold their keys",
+			unmatched,
+			searched,
+		)
+	}
+	return indices, nil
+}
+
+// Prints the derivation index of every account and returns the number of accounts of the HD
+// wallet, which also holds the keys of the indices below the highest one the wallet did not hold.
+func printDerivationIndices(accounts map[[48]byte]string, indices map[[48]byte]uint64) uint64 {
+	byIndex := make(map[uint64][48]byte, len(indices))
+	nextAccount := uint64(0)
+	for pubKey, index := range indices {
+		byIndex[index] = pubKey
+		if index+1 > nextAccount {
+			nextAccount = index + 1
+		}
+	}
+	fmt.Println("")
+	for i := uint64(0); i < nextAccount; i++ {
+		pubKey, ok := byIndex[i]
+		if !ok {
+			log.WithField("accountNumber", i).Warn(
+				"Derivation index is not held by the wallet, the HD wallet will hold its key as a new account",
+			)
+			continue
+		}
+		fmt.Printf(
+			"%s | %s %#x\n",
+			au.BrightBlue(fmt.Sprintf("Account %d", i)).Bold(),
+			au.BrightGreen(accounts[pubKey]).Bold(),
+			bytesutil.Trunc(pubKey[:]),
+		)
+	}
+	fmt.Println("")
+	return nextAccount
+}
+
+// Moves the keystores and the account passwords of a non-HD wallet kept on disk into a directory
+// next to the wallet directory, returning its path.
+func (w *Wallet) retireDirectAccounts(accountNames []string) (string, error) {
+	if err := w.checkWritable(); err != nil {
+		return "", err
+	}
+	if err := w.checkOnDisk("retiring the keystores of the wallet"); err != nil {
+		return "", err
+	}
+	retiredDir := fmt.Sprintf(retiredDirectWalletDirFormat, filepath.Clean(w.walletDir), roughtime.Now().Unix())
+	if err := os.MkdirAll(filepath.Join(retiredDir, retiredPasswordsDirName), DirectoryPermissions); err != nil {
+		return "", errors.Wrap(err, "could not create directory for retired keystores")
+	}
+	if err := os.Rename(w.accountsPath, filepath.Join(retiredDir, w.keymanagerKind.String())); err != nil {
+		return "", errors.Wrap(err, "could not retire keystores")
+	}
+	for _, name := range accountNames {
+		passwordFileName := name + direct.PasswordFileSuffix
+		err := os.Rename(
+			filepath.Join(w.passwordsDir, passwordFileName),
+			filepath.Join(retiredDir, retiredPasswordsDirName, passwordFileName),
+		)
+		if err != nil && !os.IsNotExist(err) {
+			return "", errors.Wrapf(err, "could not retire password of account %s into %s", name, retiredDir)
+		}
+	}
+	return retiredDir, nil
+}
+
+// Undoes retireDirectAccounts after the HD wallet could not be written, removing what was
+// written of it.
+func (w *Wallet) restoreRetiredAccounts(derivedWallet *Wallet, retiredDir string, accountNames []string) error {
+	if err := w.checkOnDisk("restoring the retired keystores of the wallet"); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(derivedWallet.accountsPath); err != nil {
+		return errors.Wrap(err, "could not remove HD wallet")
+	}
+	if err := os.Rename(filepath.Join(retiredDir, w.keymanagerKind.String()), w.accountsPath); err != nil {
+		return errors.Wrap(err, "could not restore keystores")
+	}
+	for _, name := range accountNames {
+		passwordFileName := name + direct.PasswordFileSuffix
+		err := os.Rename(
+			filepath.Join(retiredDir, retiredPasswordsDirName, passwordFileName),
+			filepath.Join(w.passwordsDir, passwordFileName),
+		)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "could not restore password of account %s", name)
+		}
+	}
+	return os.RemoveAll(retiredDir)
+}
+
+// Writes an HD wallet derived from the mnemonic holding nextAccount accounts, and checks it holds
+// the validating keys of every account of the non-HD wallet.
+func (w *Wallet) writeDerivedWallet(
+	ctx context.Context,
+	mnemonic string,
+	nextAccount uint64,
+	accounts map[[48]byte]string,
+) error {
+	seedConfig, err := derived.SeedFileFromMnemonic(ctx, mnemonic, w.walletPassword)
+	if err != nil {
+		return errors.Wrap(err, "could not initialize new wallet seed file")
+	}
+	seedConfig.NextAccount = nextAccount
+	seedConfigFile, err := derived.MarshalEncryptedSeedFile(ctx, seedConfig)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal encrypted wallet seed file")
+	}
+	keymanagerConfig, err := derived.MarshalConfigFile(ctx, derived.DefaultConfig())
+	if err != nil {
+		return errors.Wrap(err, "could not marshal keymanager config file")
+	}
+	if err := w.SaveWallet(); err != nil {
+		return errors.Wrap(err, "could not save wallet to disk")
+	}
+	if err := w.WriteKeymanagerConfigToDisk(ctx, keymanagerConfig); err != nil {
+		return errors.Wrap(err, "could not write keymanager config to disk")
+	}
+	if err := w.WriteEncryptedSeedToDisk(ctx, seedConfigFile); err != nil {
+		return errors.Wrap(err, "could not write encrypted wallet seed config to disk")
+	}
+	keymanager, err := w.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+	if err != nil {
+		return errors.Wrap(err, "could not initialize HD wallet keymanager")
+	}
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not fetch validating public keys")
+	}
+	held := make(map[[48]byte]bool, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		held[pubKey] = true
+	}
+	for pubKey, name := range accounts {
+		if !held[pubKey] {
+			return fmt.Errorf("HD wallet does not hold the validating key of account %s", name)
+		}
+		log.WithFields(logrus.Fields{
+			"name":      name,
+			"publicKey": fmt.Sprintf("%#x", bytesutil.Trunc(pubKey[:])),
+		}).Debug("Verified HD wallet holds validating key")
+	}
+	return nil
+}
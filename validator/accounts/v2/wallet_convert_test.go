@@ -0,0 +1,133 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/rand"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/derived"
+	"github.com/tyler-smith/go-bip39"
+	util "github.com/wealdtech/go-eth2-util"
+)
+
+// Sets up a non-HD wallet holding the given secret keys, and a file holding a new mnemonic,
+// returning the wallet config and the mnemonic.
+func setupConvertibleWallet(t *testing.T, extraKeys ...bls.SecretKey) (*testWalletConfig, string) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	keysDir := filepath.Join(testutil.TempDir(), t.Name())
+	require.NoError(t, os.MkdirAll(keysDir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(keysDir), "Failed to remove directory")
+		retired, err := filepath.Glob(filepath.Clean(walletDir) + "-retired-direct-*")
+		require.NoError(t, err)
+		for _, dir := range retired {
+			require.NoError(t, os.RemoveAll(dir), "Failed to remove directory")
+		}
+	})
+	entropy := make([]byte, 32)
+	_, err := rand.NewGenerator().Read(entropy)
+	require.NoError(t, err)
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	require.NoError(t, err)
+	mnemonicFile := filepath.Join(keysDir, "mnemonic.txt")
+	require.NoError(t, ioutil.WriteFile(mnemonicFile, []byte(mnemonic), 0600))
+
+	// The wallet holds the keys of derivation indices 0, 1 and 3 of the mnemonic.
+	seed := bip39.NewSeed(mnemonic, "")
+	lines := make([]string, 0)
+	for _, index := range []int{0, 1, 3} {
+		validatingKey, err := util.PrivateKeyFromSeedAndPath(seed, fmt.Sprintf(derived.ValidatingKeyDerivationPathTemplate, index))
+		require.NoError(t, err)
+		lines = append(lines, fmt.Sprintf("%#x", validatingKey.Marshal()))
+	}
+	for _, secretKey := range extraKeys {
+		lines = append(lines, fmt.Sprintf("%#x", secretKey.Marshal()))
+	}
+	privateKeyFile := filepath.Join(keysDir, "keys.txt")
+	require.NoError(t, ioutil.WriteFile(privateKeyFile, []byte(strings.Join(lines, "\n")), 0600))
+	cfg := &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		walletPasswordFile:  passwordFile,
+		accountPasswordFile: passwordFile,
+		privateKeyFile:      privateKeyFile,
+		keymanagerKind:      v2keymanager.Direct,
+	}
+	require.NoError(t, ImportAccount(setupWalletCtx(t, cfg)))
+	cfg.privateKeyFile = ""
+	cfg.mnemonicFile = mnemonicFile
+	return cfg, mnemonic
+}
+
+func TestConvertWallet(t *testing.T) {
+	cfg, mnemonic := setupConvertibleWallet(t)
+	cliCtx := setupWalletCtx(t, cfg)
+	require.NoError(t, ConvertWallet(cliCtx))
+
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	assert.Equal(t, v2keymanager.Derived, wallet.KeymanagerKind())
+	keymanager, err := wallet.InitializeKeymanager(ctx, true)
+	require.NoError(t, err)
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	// Index 2 was not in the wallet, but the HD wallet holds every index up to the highest one.
+	wantKeys, err := derived.ValidatingPublicKeysFromMnemonic(mnemonic, 4)
+	require.NoError(t, err)
+	require.Equal(t, len(wantKeys), len(pubKeys))
+	held := make(map[[48]byte]bool)
+	for _, pubKey := range pubKeys {
+		held[pubKey] = true
+	}
+	for _, pubKey := range wantKeys {
+		assert.Equal(t, true, held[pubKey], "Expected HD wallet to hold %#x", bytesutil.Trunc(pubKey[:]))
+	}
+
+	retired, err := filepath.Glob(filepath.Clean(cfg.walletDir) + "-retired-direct-*")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(retired))
+	retiredPasswords, err := ioutil.ReadDir(filepath.Join(retired[0], retiredPasswordsDirName))
+	require.NoError(t, err)
+	assert.Equal(t, 3, len(retiredPasswords))
+}
+
+func TestConvertWallet_KeyNotDerivedFromMnemonic(t *testing.T) {
+	cfg, _ := setupConvertibleWallet(t, bls.RandKey())
+	cliCtx := setupWalletCtx(t, cfg)
+	assert.ErrorContains(t, "not derived from the mnemonic", ConvertWallet(cliCtx))
+
+	// The wallet is left untouched.
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	assert.Equal(t, v2keymanager.Direct, wallet.KeymanagerKind())
+	accounts, err := wallet.accountPubKeys(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 4, len(accounts))
+}
+
+func TestWallet_RetireDirectAccounts_NotOnDisk(t *testing.T) {
+	wallet := &Wallet{
+		walletDir:      testutil.TempDir(),
+		accountsPath:   filepath.Join(testutil.TempDir(), v2keymanager.Direct.String()),
+		keymanagerKind: v2keymanager.Direct,
+		storage:        newMemoryStorage(),
+		storageURL:     "memory://",
+	}
+	_, err := wallet.retireDirectAccounts([]string{"account"})
+	assert.ErrorContains(t, "not supported for wallets stored in memory://", err)
+	assert.ErrorContains(t, "not supported for wallets stored in memory://", wallet.restoreRetiredAccounts(
+		&Wallet{}, filepath.Join(wallet.walletDir, "retired"), []string{"account"},
+	))
+}
@@ -2,6 +2,8 @@ package v2
 
 import (
 	"context"
+	"fmt"
+	"path"
 
 	"github.com/pkg/errors"
 	"github.com/prysmaticlabs/prysm/validator/flags"
@@ -63,6 +65,24 @@ func createDirectKeymanagerWallet(cliCtx *cli.Context, wallet *Wallet) error {
 	}
 	defaultConfig := direct.DefaultConfig()
 	defaultConfig.AccountPasswordsDirectory = wallet.passwordsDir
+	if cliCtx.IsSet(flags.DepositDataFormatFlag.Name) {
+		format := cliCtx.String(flags.DepositDataFormatFlag.Name)
+		if err := direct.ValidateDepositDataFormat(format); err != nil {
+			return err
+		}
+		defaultConfig.DepositDataFormat = format
+	}
+	if cliCtx.IsSet(flags.KeystoreFileNameFormatFlag.Name) {
+		format := cliCtx.String(flags.KeystoreFileNameFormatFlag.Name)
+		if err := direct.ValidateKeystoreFileNameFormat(format); err != nil {
+			return err
+		}
+		if ok, err := path.Match(direct.KeystoreFileGlob(format), accountMetadataFileName); err != nil || ok {
+			return fmt.Errorf("keystore file name format %s also names the %s file of accounts", format, accountMetadataFileName)
+		}
+		defaultConfig.KeystoreFileNameFormat = format
+		wallet.keystoreNameFormat = format
+	}
 	keymanagerConfig, err := direct.MarshalConfigFile(context.Background(), defaultConfig)
 	if err != nil {
 		return errors.Wrap(err, "could not marshal keymanager config file")
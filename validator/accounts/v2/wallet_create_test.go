@@ -72,7 +72,7 @@ func TestCreateWallet_Direct(t *testing.T) {
 	// We read the keymanager config for the newly created wallet.
 	encoded, err := wallet.ReadKeymanagerConfigFromDisk(ctx)
 	assert.NoError(t, err)
-	cfg, err := direct.UnmarshalConfigFile(encoded)
+	cfg, err := direct.UnmarshalConfigFile(encoded, "" /* password */)
 	assert.NoError(t, err)
 
 	// We assert the created configuration was as desired.
@@ -102,7 +102,7 @@ func TestCreateWallet_Derived(t *testing.T) {
 	// We read the keymanager config for the newly created wallet.
 	encoded, err := wallet.ReadKeymanagerConfigFromDisk(ctx)
 	assert.NoError(t, err)
-	cfg, err := derived.UnmarshalConfigFile(encoded)
+	cfg, err := derived.UnmarshalConfigFile(encoded, "" /* password */)
 	assert.NoError(t, err)
 
 	// We assert the created configuration was as desired.
@@ -151,9 +151,60 @@ func TestCreateWallet_Remote(t *testing.T) {
 	// We read the keymanager config for the newly created wallet.
 	encoded, err := wallet.ReadKeymanagerConfigFromDisk(ctx)
 	assert.NoError(t, err)
-	cfg, err := remote.UnmarshalConfigFile(encoded)
+	cfg, err := remote.UnmarshalConfigFile(encoded, "" /* password */)
 	assert.NoError(t, err)
 
 	// We assert the created configuration was as desired.
 	assert.DeepEqual(t, wantCfg, cfg)
 }
+
+func TestCreateWallet_RemoteEncryptedConfig(t *testing.T) {
+	walletDir, _, passwordFile := setupWalletAndPasswordsDir(t)
+	wantCfg := &remote.Config{
+		RemoteCertificate: &remote.CertificateConfig{
+			ClientCertPath: "/tmp/client.crt",
+			ClientKeyPath:  "/tmp/client.key",
+			CACertPath:     "/tmp/ca.crt",
+		},
+		RemoteAddr: "host.example.com:4000",
+	}
+	app := cli.App{}
+	set := flag.NewFlagSet("test", 0)
+	keymanagerKind := "remote"
+	set.String(flags.WalletDirFlag.Name, walletDir, "")
+	set.String(flags.KeymanagerKindFlag.Name, keymanagerKind, "")
+	set.String(flags.GrpcRemoteAddressFlag.Name, wantCfg.RemoteAddr, "")
+	set.String(flags.RemoteSignerCertPathFlag.Name, wantCfg.RemoteCertificate.ClientCertPath, "")
+	set.String(flags.RemoteSignerKeyPathFlag.Name, wantCfg.RemoteCertificate.ClientKeyPath, "")
+	set.String(flags.RemoteSignerCACertPathFlag.Name, wantCfg.RemoteCertificate.CACertPath, "")
+	set.String(flags.WalletPasswordFileFlag.Name, passwordFile, "")
+	set.Bool(flags.EncryptKeymanagerConfigFlag.Name, true, "")
+	assert.NoError(t, set.Set(flags.WalletDirFlag.Name, walletDir))
+	assert.NoError(t, set.Set(flags.KeymanagerKindFlag.Name, keymanagerKind))
+	assert.NoError(t, set.Set(flags.GrpcRemoteAddressFlag.Name, wantCfg.RemoteAddr))
+	assert.NoError(t, set.Set(flags.RemoteSignerCertPathFlag.Name, wantCfg.RemoteCertificate.ClientCertPath))
+	assert.NoError(t, set.Set(flags.RemoteSignerKeyPathFlag.Name, wantCfg.RemoteCertificate.ClientKeyPath))
+	assert.NoError(t, set.Set(flags.RemoteSignerCACertPathFlag.Name, wantCfg.RemoteCertificate.CACertPath))
+	assert.NoError(t, set.Set(flags.WalletPasswordFileFlag.Name, passwordFile))
+	assert.NoError(t, set.Set(flags.EncryptKeymanagerConfigFlag.Name, "true"))
+	cliCtx := cli.NewContext(&app, set, nil)
+
+	_, err := CreateWallet(cliCtx)
+	require.NoError(t, err)
+
+	// The config file on disk must not leak the remote signer details.
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	assert.Equal(t, true, wallet.encryptedConfig)
+	encoded, err := wallet.ReadKeymanagerConfigFromDisk(ctx)
+	require.NoError(t, err)
+	_, err = remote.UnmarshalConfigFile(encoded, "" /* password */)
+	assert.ErrorContains(t, v2keymanager.ErrConfigPasswordRequired.Error(), err)
+
+	encoded, err = wallet.ReadKeymanagerConfigFromDisk(ctx)
+	require.NoError(t, err)
+	cfg, err := remote.UnmarshalConfigFile(encoded, wallet.walletPassword)
+	require.NoError(t, err)
+	assert.DeepEqual(t, wantCfg, cfg)
+}
@@ -27,7 +27,7 @@ func EditWalletConfiguration(cliCtx *cli.Context) error {
 		if err != nil {
 			return errors.Wrap(err, "could not read config")
 		}
-		cfg, err := direct.UnmarshalConfigFile(enc)
+		cfg, err := direct.UnmarshalConfigFile(enc, wallet.walletPassword)
 		if err != nil {
 			return errors.Wrap(err, "could not unmarshal config")
 		}
@@ -40,6 +40,12 @@ func EditWalletConfiguration(cliCtx *cli.Context) error {
 		}
 		defaultCfg := direct.DefaultConfig()
 		defaultCfg.AccountPasswordsDirectory = passwordsDir
+		// Keystores are only ever named after the format of the wallet they were created in.
+		defaultCfg.KeystoreFileNameFormat = cfg.KeystoreFileNameFormat
+		// The cost of the accounts keystore is never lowered by editing the config.
+		defaultCfg.AccountsKeystoreKDF = cfg.AccountsKeystoreKDF
+		// Nor is a TOTP enrollment lifted.
+		defaultCfg.TOTPEnrolled = cfg.TOTPEnrolled
 		encodedCfg, err := direct.MarshalConfigFile(ctx, defaultCfg)
 		if err != nil {
 			return errors.Wrap(err, "could not marshal config file")
@@ -54,7 +60,7 @@ func EditWalletConfiguration(cliCtx *cli.Context) error {
 		if err != nil {
 			return errors.Wrap(err, "could not read config")
 		}
-		cfg, err := remote.UnmarshalConfigFile(enc)
+		cfg, err := remote.UnmarshalConfigFile(enc, wallet.walletPassword)
 		if err != nil {
 			return errors.Wrap(err, "could not unmarshal config")
 		}
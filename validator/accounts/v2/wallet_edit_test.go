@@ -63,7 +63,7 @@ func TestEditWalletConfiguration(t *testing.T) {
 	encoded, err := wallet.ReadKeymanagerConfigFromDisk(ctx)
 	require.NoError(t, err)
 
-	cfg, err := remote.UnmarshalConfigFile(encoded)
+	cfg, err := remote.UnmarshalConfigFile(encoded, "" /* password */)
 	assert.NoError(t, err)
 	assert.DeepEqual(t, wantCfg, cfg)
 }
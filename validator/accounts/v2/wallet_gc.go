@@ -0,0 +1,202 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/urfave/cli/v2"
+)
+
+// walletOrphan is a file or directory of a non-HD wallet belonging to no account, such as the
+// password file of an account whose directory was removed by hand.
+type walletOrphan struct {
+	path   string
+	reason string
+	// removable orphans are removed by wallet-v2 gc --remove-orphans. Directories holding files
+	// but no keystore are only reported, as their files may still be needed.
+	removable bool
+}
+
+// CollectWalletGarbage reports the orphaned files and directories of a non-HD wallet: password
+// files of no account, empty account directories, directories without a keystore and temporary
+// files left behind by interrupted writes. With --remove-orphans, all of them but directories
+// holding files are removed, while holding the lock of the wallet.
+func CollectWalletGarbage(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	wallet, err := openDirectWallet(cliCtx)
+	if err != nil {
+		return err
+	}
+	orphans, err := wallet.findOrphans(ctx)
+	if err != nil {
+		return err
+	}
+	if len(orphans) == 0 {
+		fmt.Println("Wallet has no orphaned files")
+		return nil
+	}
+	for _, orphan := range orphans {
+		fmt.Printf("%s: %s\n", au.BrightRed(orphan.path), orphan.reason)
+	}
+	if !cliCtx.Bool(flags.RemoveOrphansFlag.Name) {
+		fmt.Printf("Found %s orphaned files, remove them with --%s\n", au.BrightRed(len(orphans)), flags.RemoveOrphansFlag.Name)
+		return nil
+	}
+	// A running validator client may be writing the temporary files.
+	lock, err := wallet.Lock()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := lock.Unlock(); err != nil {
+			log.WithError(err).Error("Could not unlock wallet")
+		}
+	}()
+	removed, err := wallet.removeOrphans(ctx, orphans)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Removed %s orphaned files\n", au.BrightGreen(removed))
+	return nil
+}
+
+// Finds the orphaned files and directories of the wallet, in the order of their paths.
+func (w *Wallet) findOrphans(ctx context.Context) ([]*walletOrphan, error) {
+	compacted, err := w.compactedAccountNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ioutil.ReadDir(w.accountsPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list accounts")
+	}
+	orphans := make([]*walletOrphan, 0)
+	// Password files of archived accounts and of directories without a keystore are kept.
+	names := make(map[string]bool, len(entries))
+	tmpDirs := []string{w.accountsPath}
+	for _, entry := range entries {
+		entryPath := filepath.Join(w.accountsPath, entry.Name())
+		if !entry.IsDir() {
+			continue
+		}
+		if entry.Name() == archiveDirName {
+			archived, err := ioutil.ReadDir(entryPath)
+			if err != nil {
+				return nil, errors.Wrap(err, "could not list archived accounts")
+			}
+			for _, account := range archived {
+				names[account.Name()] = true
+			}
+			continue
+		}
+		if entry.Name() == tombstoneDirName || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		names[entry.Name()] = true
+		tmpDirs = append(tmpDirs, entryPath)
+		if compacted[entry.Name()] {
+			continue
+		}
+		files, err := ioutil.ReadDir(entryPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not list directory %s", entryPath)
+		}
+		keystores, err := filepath.Glob(filepath.Join(entryPath, w.keystoreFileGlob()))
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case len(files) == 0:
+			orphans = append(orphans, &walletOrphan{path: entryPath, reason: "empty account directory", removable: true})
+		case len(keystores) == 0:
+			orphans = append(orphans, &walletOrphan{path: entryPath, reason: "account directory without a keystore"})
+		}
+	}
+	for name := range compacted {
+		names[name] = true
+	}
+	if w.passwordsDir != "" && isDir(w.passwordsDir) {
+		passwordFiles, err := filepath.Glob(filepath.Join(w.passwordsDir, "*"+direct.PasswordFileSuffix))
+		if err != nil {
+			return nil, err
+		}
+		for _, passwordFile := range passwordFiles {
+			if !names[strings.TrimSuffix(filepath.Base(passwordFile), direct.PasswordFileSuffix)] {
+				orphans = append(orphans, &walletOrphan{
+					path:      passwordFile,
+					reason:    "password file without an account",
+					removable: true,
+				})
+			}
+		}
+		tmpDirs = append(tmpDirs, w.passwordsDir)
+	}
+	for _, dir := range tmpDirs {
+		for _, suffix := range []string{tmpFileSuffix, reencryptedFileSuffix} {
+			tmpFiles, err := filepath.Glob(filepath.Join(dir, "*"+suffix))
+			if err != nil {
+				return nil, err
+			}
+			for _, tmpFile := range tmpFiles {
+				orphans = append(orphans, &walletOrphan{
+					path:      tmpFile,
+					reason:    "temporary file of an interrupted write",
+					removable: true,
+				})
+			}
+		}
+	}
+	sort.Slice(orphans, func(i, j int) bool {
+		return orphans[i].path < orphans[j].path
+	})
+	return orphans, nil
+}
+
+// Removes the removable orphans of the wallet, files before directories so only directories
+// which are still empty are removed, returning how many were removed.
+func (w *Wallet) removeOrphans(ctx context.Context, orphans []*walletOrphan) (int, error) {
+	if err := w.checkWritable(); err != nil {
+		return 0, err
+	}
+	if err := w.checkOnDisk("removing orphaned files"); err != nil {
+		return 0, err
+	}
+	removed := make([]string, 0, len(orphans))
+	for _, dirs := range []bool{false, true} {
+		for _, orphan := range orphans {
+			if !orphan.removable || isDir(orphan.path) != dirs {
+				continue
+			}
+			if err := os.Remove(orphan.path); err != nil {
+				return len(removed), errors.Wrapf(err, "could not remove %s", orphan.path)
+			}
+			// Paths of the accounts directory are recorded as paths of the wallet.
+			rel, err := filepath.Rel(w.accountsPath, orphan.path)
+			if err != nil || !isWithinDir(orphan.path, w.accountsPath) {
+				removed = append(removed, orphan.path)
+				continue
+			}
+			removed = append(removed, filepath.ToSlash(rel))
+		}
+	}
+	if err := w.recordMutation(ctx, &walletJournalEntry{
+		Action:  journalActionOrphansRemoved,
+		Deleted: removed,
+	}); err != nil {
+		return len(removed), err
+	}
+	return len(removed), nil
+}
+
+func isDir(dirPath string) bool {
+	info, err := os.Stat(dirPath)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,84 @@
+package v2
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestCollectWalletGarbage(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFilePath,
+		keymanagerKind:     v2keymanager.Direct,
+	}
+	wallet, err := NewWallet(setupWalletCtx(t, cfg), v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	names := make([]string, 2)
+	for i := range names {
+		names[i], err = keymanager.CreateAccount(ctx, password)
+		require.NoError(t, err)
+	}
+	require.NoError(t, wallet.archiveAccount(names[1]))
+
+	// The password of the archived account is kept, the others are orphans.
+	emptyDir := filepath.Join(wallet.AccountsDir(), "empty-account")
+	require.NoError(t, os.MkdirAll(emptyDir, DirectoryPermissions))
+	junkDir := filepath.Join(wallet.AccountsDir(), "junk")
+	require.NoError(t, os.MkdirAll(junkDir, DirectoryPermissions))
+	junkFile := filepath.Join(junkDir, "notes.txt")
+	require.NoError(t, ioutil.WriteFile(junkFile, []byte("notes"), FilePermissions))
+	stalePassword := filepath.Join(wallet.PasswordsDir(), "removed-account"+direct.PasswordFileSuffix)
+	require.NoError(t, ioutil.WriteFile(stalePassword, []byte(password), FilePermissions))
+	tmpFile := filepath.Join(wallet.AccountsDir(), names[0], ".keystore.json.1.2"+tmpFileSuffix)
+	require.NoError(t, ioutil.WriteFile(tmpFile, []byte("{}"), FilePermissions))
+
+	// Directories without a keystore are not accounts.
+	accountNames, err := wallet.ListDirs()
+	require.NoError(t, err)
+	assert.DeepEqual(t, []string{names[0]}, accountNames)
+
+	orphans, err := wallet.findOrphans(ctx)
+	require.NoError(t, err)
+	paths := make([]string, len(orphans))
+	for i, orphan := range orphans {
+		paths[i] = orphan.path
+	}
+	want := []string{tmpFile, emptyDir, junkDir, stalePassword}
+	sort.Strings(want)
+	assert.DeepEqual(t, want, paths)
+
+	// Orphans are only reported unless removal is asked for.
+	require.NoError(t, CollectWalletGarbage(setupWalletCtx(t, cfg)))
+	assert.Equal(t, true, fileExists(stalePassword))
+	cfg.removeOrphans = true
+	require.NoError(t, CollectWalletGarbage(setupWalletCtx(t, cfg)))
+	for _, removed := range []string{tmpFile, emptyDir, stalePassword} {
+		_, err := os.Stat(removed)
+		assert.Equal(t, true, os.IsNotExist(err), "%s was not removed", removed)
+	}
+	assert.Equal(t, true, fileExists(junkFile))
+	assert.Equal(t, true, fileExists(filepath.Join(wallet.PasswordsDir(), names[0]+direct.PasswordFileSuffix)))
+	assert.Equal(t, true, fileExists(filepath.Join(wallet.PasswordsDir(), names[1]+direct.PasswordFileSuffix)))
+	orphans, err = wallet.findOrphans(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(orphans))
+	assert.Equal(t, junkDir, orphans[0].path)
+}
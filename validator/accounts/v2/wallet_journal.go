@@ -0,0 +1,159 @@
+package v2
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
+)
+
+// walletJournalFileName is the file in the accounts directory of a wallet every mutation of the
+// wallet is appended to, one JSON entry per line, so what changed in a wallet and when can be
+// reconstructed after an incident. Wallets kept in other storage keep it in their storage.
+const walletJournalFileName = "wallet-journal.jsonl"
+
+const (
+	journalActionWrite                 = "write"
+	journalActionAccountCreated        = "account-created"
+	journalActionAccountImported       = "account-imported"
+	journalActionAccountDeleted        = "account-deleted"
+	journalActionPasswordChanged       = "password-changed"
+	journalActionWalletCompacted       = "wallet-compacted"
+	journalActionWalletExpanded        = "wallet-expanded"
+	journalActionOrphansRemoved        = "orphans-removed"
+	journalActionSnapshotRestored      = "snapshot-restored"
+	journalActionPasswordsEncrypted    = "passwords-encrypted"
+	journalActionMasterPasswordEnabled = "master-password-enabled"
+	journalActionSecurityKeyEnrolled   = "security-key-enrolled"
+	journalActionSecurityKeyRemoved    = "security-key-removed"
+	journalActionTOTPEnrolled          = "totp-enrolled"
+	journalActionTOTPCodeUsed          = "totp-code-used"
+	journalActionTOTPRemoved           = "totp-removed"
+	journalActionArtifactsEncrypted    = "artifacts-encrypted"
+	journalActionApprovalTokensUsed    = "approval-tokens-used"
+)
+
+// walletJournalEntry is a mutation of a wallet, recorded once it succeeded.
+type walletJournalEntry struct {
+	Time   string `json:"time"`
+	Action string `json:"action"`
+	// Written are the hex encoded sha256 hashes of the files written, by their path in the wallet.
+	Written map[string]string `json:"written,omitempty"`
+	// Passwords are the password files written. Their contents are not hashed, as the hash of a
+	// password is enough to check guesses of it.
+	Passwords []string `json:"passwords,omitempty"`
+	Deleted   []string `json:"deleted,omitempty"`
+}
+
+type journalActionKey struct{}
+
+// Returns a context recording the mutations of wallets made with it as the given action, such as
+// the files written when creating an account as account-created, rather than as plain writes.
+func withJournalAction(ctx context.Context, action string) context.Context {
+	return context.WithValue(ctx, journalActionKey{}, action)
+}
+
+func journalAction(ctx context.Context) string {
+	if action, ok := ctx.Value(journalActionKey{}).(string); ok {
+		return action
+	}
+	return journalActionWrite
+}
+
+// Returns the entry recording files written to the wallet.
+func journalWrites(ctx context.Context, files map[string][]byte) *walletJournalEntry {
+	written := make(map[string]string, len(files))
+	for filePath, data := range files {
+		hash := sha256.Sum256(data)
+		written[filePath] = hex.EncodeToString(hash[:])
+	}
+	return &walletJournalEntry{Action: journalAction(ctx), Written: written}
+}
+
+// Appends an entry to the journal of the wallet and updates the manifest of the wallet with it.
+// The journal of a wallet kept in its accounts directory is only ever appended to, the journal of
+// a wallet kept in other storage is rewritten with the entry appended.
+func (w *Wallet) recordMutation(ctx context.Context, entry *walletJournalEntry) error {
+	if err := w.checkWritable(); err != nil {
+		return err
+	}
+	entry.Time = roughtime.Now().UTC().Format(time.RFC3339Nano)
+	sort.Strings(entry.Passwords)
+	sort.Strings(entry.Deleted)
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "could not encode wallet journal entry")
+	}
+	encoded = append(encoded, '\n')
+	if w.storage != nil {
+		journal, err := w.files().readFile(ctx, walletJournalFileName)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "could not read wallet journal")
+		}
+		if err := w.files().writeFile(ctx, walletJournalFileName, append(journal, encoded...)); err != nil {
+			return errors.Wrap(err, "could not write wallet journal")
+		}
+		return w.updateManifest(ctx, entry.Written, entry.Deleted, nil)
+	}
+	if err := os.MkdirAll(w.accountsPath, DirectoryPermissions); err != nil {
+		return errors.Wrapf(err, "could not create path: %s", w.accountsPath)
+	}
+	journalPath := filepath.Join(w.accountsPath, walletJournalFileName)
+	f, err := os.OpenFile(journalPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, params.BeaconIoConfig().ReadWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "could not open wallet journal %s", journalPath)
+	}
+	if _, err := f.Write(encoded); err != nil {
+		if err := f.Close(); err != nil {
+			log.WithError(err).Errorf("Could not close wallet journal %s", journalPath)
+		}
+		return errors.Wrapf(err, "could not append to wallet journal %s", journalPath)
+	}
+	if err := f.Sync(); err != nil {
+		if err := f.Close(); err != nil {
+			log.WithError(err).Errorf("Could not close wallet journal %s", journalPath)
+		}
+		return errors.Wrapf(err, "could not sync wallet journal %s", journalPath)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return w.updateManifest(ctx, entry.Written, entry.Deleted, nil)
+}
+
+// Reads the entries of the journal of the wallet, oldest first.
+func (w *Wallet) readWalletJournal(ctx context.Context) ([]*walletJournalEntry, error) {
+	var journal []byte
+	var err error
+	if w.storage != nil {
+		journal, err = w.files().readFile(ctx, walletJournalFileName)
+	} else {
+		journal, err = ioutil.ReadFile(filepath.Join(w.accountsPath, walletJournalFileName))
+	}
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read wallet journal")
+	}
+	entries := make([]*walletJournalEntry, 0)
+	decoder := json.NewDecoder(bytes.NewReader(journal))
+	for decoder.More() {
+		entry := &walletJournalEntry{}
+		if err := decoder.Decode(entry); err != nil {
+			return nil, errors.Wrap(err, "could not decode wallet journal")
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
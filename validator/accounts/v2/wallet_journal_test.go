@@ -0,0 +1,59 @@
+package v2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestWallet_Journal(t *testing.T) {
+	walletDir, passwordsDir, _ := setupWalletAndPasswordsDir(t)
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:      walletDir,
+		passwordsDir:   passwordsDir,
+		keymanagerKind: v2keymanager.Direct,
+	})
+	wallet, err := NewWallet(cliCtx, v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	entries, err := wallet.readWalletJournal(ctx)
+	require.NoError(t, err)
+	start := len(entries)
+
+	name, err := keymanager.CreateAccount(withJournalAction(ctx, journalActionAccountCreated), "hello world")
+	require.NoError(t, err)
+	keystoreFileName, err := wallet.FileNameAtPath(ctx, name, direct.KeystoreFileName)
+	require.NoError(t, err)
+	keystore, err := wallet.ReadFileAtPath(ctx, name, direct.KeystoreFileName)
+	require.NoError(t, err)
+	keystorePath := name + "/" + keystoreFileName
+	require.NoError(t, wallet.DeleteAccountFiles(ctx, name, name+direct.PasswordFileSuffix))
+
+	entries, err = wallet.readWalletJournal(ctx)
+	require.NoError(t, err)
+	entries = entries[start:]
+	var created *walletJournalEntry
+	for _, entry := range entries {
+		if _, ok := entry.Written[keystorePath]; ok {
+			created = entry
+		}
+	}
+	require.NotNil(t, created, "no journal entry for the keystore of the new account")
+	assert.Equal(t, journalActionAccountCreated, created.Action)
+	hash := sha256.Sum256(keystore)
+	assert.Equal(t, hex.EncodeToString(hash[:]), created.Written[keystorePath])
+
+	deleted := entries[len(entries)-1]
+	assert.Equal(t, journalActionAccountDeleted, deleted.Action)
+	assert.DeepEqual(t, []string{name, filepath.Join(passwordsDir, name+direct.PasswordFileSuffix)}, deleted.Deleted)
+}
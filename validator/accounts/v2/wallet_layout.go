@@ -0,0 +1,120 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// walletLayoutFileName is the file in the accounts directory of a wallet recording the version of
+// the layout of its files. Wallets without one were created before layouts were versioned, and are
+// of layout version 0.
+const walletLayoutFileName = "wallet-layout.json"
+
+// walletLayout is the content of the layout file of a wallet.
+type walletLayout struct {
+	Version int `json:"version"`
+}
+
+// walletMigration changes the files of a wallet from the layout version before to its version.
+// Migrations must be safe to run again, as a migration interrupted before the layout version is
+// recorded is run again the next time the wallet is opened.
+type walletMigration struct {
+	version     int
+	description string
+	migrate     func(ctx context.Context, w *Wallet) error
+}
+
+// walletMigrations are the migrations between every two consecutive layout versions, in order. The
+// version of the last one is the layout version of new wallets.
+var walletMigrations = []*walletMigration{
+	{
+		version:     1,
+		description: "moved the creation time and deposit data of non-HD accounts into their metadata",
+		migrate: func(ctx context.Context, w *Wallet) error {
+			if w.keymanagerKind != v2keymanager.Direct {
+				return nil
+			}
+			_, _, err := w.consolidateAllAccountMetadata(ctx)
+			return err
+		},
+	},
+}
+
+// Returns the layout version of new wallets.
+func currentWalletLayoutVersion() int {
+	return walletMigrations[len(walletMigrations)-1].version
+}
+
+// Reads the layout version of the wallet, 0 if it has no layout file.
+func (w *Wallet) readWalletLayoutVersion(ctx context.Context) (int, error) {
+	encoded, err := w.files().readFile(ctx, walletLayoutFileName)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "could not read wallet layout")
+	}
+	layout := &walletLayout{}
+	if err := json.Unmarshal(encoded, layout); err != nil {
+		return 0, errors.Wrap(err, "could not decode wallet layout")
+	}
+	return layout.Version, nil
+}
+
+func (w *Wallet) writeWalletLayoutVersion(ctx context.Context, version int) error {
+	encoded, err := json.MarshalIndent(&walletLayout{Version: version}, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "could not marshal wallet layout")
+	}
+	if err := w.WriteFileAtPath(ctx, "" /* accounts dir */, walletLayoutFileName, encoded); err != nil {
+		return errors.Wrap(err, "could not write wallet layout")
+	}
+	return nil
+}
+
+// Migrates the files of the wallet to the current layout version, one version at a time,
+// recording every version reached. Wallets of a newer layout version than this version of Prysm
+// supports are refused rather than risking to misread them.
+func (w *Wallet) migrateWalletLayout(ctx context.Context) error {
+	version, err := w.readWalletLayoutVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if version > currentWalletLayoutVersion() {
+		return fmt.Errorf(
+			"wallet layout is of version %d, this version of Prysm only supports up to %d, please upgrade Prysm",
+			version,
+			currentWalletLayoutVersion(),
+		)
+	}
+	if w.readOnly && version < currentWalletLayoutVersion() {
+		log.WithFields(logrus.Fields{
+			"version": version,
+			"current": currentWalletLayoutVersion(),
+		}).Warn("Wallet is read-only, not migrating it to the current wallet layout")
+		return nil
+	}
+	for _, migration := range walletMigrations {
+		if migration.version <= version {
+			continue
+		}
+		if err := migration.migrate(ctx, w); err != nil {
+			return errors.Wrapf(err, "could not migrate wallet layout to version %d", migration.version)
+		}
+		if err := w.writeWalletLayoutVersion(ctx, migration.version); err != nil {
+			return err
+		}
+		log.WithFields(logrus.Fields{
+			"from": version,
+			"to":   migration.version,
+		}).Infof("Migrated wallet layout: %s", migration.description)
+		version = migration.version
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestWallet_MigrateLayout(t *testing.T) {
+	walletDir, passwordsDir, _ := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:      walletDir,
+		passwordsDir:   passwordsDir,
+		keymanagerKind: v2keymanager.Direct,
+	}
+	cliCtx := setupWalletCtx(t, cfg)
+	wallet, err := NewWallet(cliCtx, v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+
+	// New wallets are of the current layout.
+	version, err := wallet.readWalletLayoutVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, currentWalletLayoutVersion(), version)
+
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	name, err := keymanager.CreateAccount(ctx, "hello world")
+	require.NoError(t, err)
+
+	// Wallets created before layouts were versioned are migrated when opened.
+	require.NoError(t, os.Remove(filepath.Join(wallet.AccountsDir(), walletLayoutFileName)))
+	require.NoError(t, wallet.WriteFileAtPath(ctx, name, direct.TimestampFileName, []byte("1600000000\n")))
+	require.NoError(t, wallet.writeAccountMetadata(ctx, name, &accountMetadata{}))
+	wallet, err = OpenWallet(cliCtx)
+	require.NoError(t, err)
+	version, err = wallet.readWalletLayoutVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, currentWalletLayoutVersion(), version)
+	metadata, err := wallet.readAccountMetadata(name)
+	require.NoError(t, err)
+	assert.Equal(t, accountMetadataVersion, metadata.Version)
+	assert.Equal(t, time.Unix(1600000000, 0).UTC().Format(time.RFC3339Nano), metadata.CreatedAt)
+	assert.Equal(t, false, fileExists(filepath.Join(wallet.AccountsDir(), name, direct.TimestampFileName)))
+
+	// Wallets of a newer layout are refused rather than misread.
+	require.NoError(t, wallet.writeWalletLayoutVersion(ctx, currentWalletLayoutVersion()+1))
+	_, err = OpenWallet(cliCtx)
+	assert.ErrorContains(t, fmt.Sprintf("only supports up to %d", currentWalletLayoutVersion()), err)
+}
@@ -0,0 +1,107 @@
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
+)
+
+// walletLockFileName is the file in the accounts directory of a wallet a validator client holds an
+// exclusive lock on while it validates with the wallet. It also holds the lease of the client.
+const walletLockFileName = "wallet.lock"
+
+// errWalletLockHeld is returned by lockFile if another process holds the lock of the file.
+var errWalletLockHeld = errors.New("wallet lock is held by another process")
+
+// WalletLock is an exclusive advisory lock on a wallet, preventing two validator clients on the
+// same host from validating with the same keys, which would get them slashed. It does not stop
+// validator clients on other hosts from using a wallet kept in object storage.
+type WalletLock struct {
+	file *os.File
+}
+
+// walletLease identifies the process holding the lock of a wallet.
+type walletLease struct {
+	Host   string    `json:"host"`
+	PID    int       `json:"pid"`
+	Locked time.Time `json:"locked"`
+}
+
+// Lock acquires the exclusive lock of the wallet, failing with the host and pid of the process
+// holding it if it is already locked. The lock is released by Unlock, or when the process exits.
+func (w *Wallet) Lock() (*WalletLock, error) {
+	if err := os.MkdirAll(w.accountsPath, DirectoryPermissions); err != nil {
+		return nil, errors.Wrap(err, "could not create wallet directory")
+	}
+	lockPath := filepath.Join(w.accountsPath, walletLockFileName)
+	f, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, params.BeaconIoConfig().ReadWritePermissions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open wallet lock file %s", lockPath)
+	}
+	if err := lockFile(f); err != nil {
+		if closeErr := f.Close(); closeErr != nil {
+			log.WithError(closeErr).Errorf("Could not close wallet lock file %s", lockPath)
+		}
+		if err == errWalletLockHeld {
+			return nil, fmt.Errorf("wallet %s is in use by %s", w.walletDir, readWalletLease(lockPath))
+		}
+		return nil, errors.Wrapf(err, "could not lock wallet lock file %s", lockPath)
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown host"
+	}
+	encoded, err := json.MarshalIndent(&walletLease{
+		Host:   host,
+		PID:    os.Getpid(),
+		Locked: roughtime.Now(),
+	}, "", "\t")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal wallet lease")
+	}
+	// The lease of the previous holder is replaced, whether it exited or crashed.
+	if err := f.Truncate(0); err != nil {
+		return nil, errors.Wrap(err, "could not clear wallet lease")
+	}
+	if _, err := f.WriteAt(encoded, 0); err != nil {
+		return nil, errors.Wrap(err, "could not write wallet lease")
+	}
+	if err := f.Sync(); err != nil {
+		return nil, errors.Wrap(err, "could not sync wallet lease")
+	}
+	return &WalletLock{file: f}, nil
+}
+
+// Unlock releases the lock of the wallet. Its lease is left behind, for the next holder to
+// replace.
+func (l *WalletLock) Unlock() error {
+	if err := unlockFile(l.file); err != nil {
+		return errors.Wrap(err, "could not unlock wallet")
+	}
+	return l.file.Close()
+}
+
+// Describes the process holding the lock of a wallet by its lease, if it can be read.
+func readWalletLease(lockPath string) string {
+	encoded, err := ioutil.ReadFile(lockPath)
+	if err != nil {
+		return "another process"
+	}
+	lease := &walletLease{}
+	if err := json.Unmarshal(encoded, lease); err != nil || lease.PID == 0 {
+		return "another process"
+	}
+	return fmt.Sprintf(
+		"process %d on %s since %s",
+		lease.PID,
+		lease.Host,
+		lease.Locked.Format(time.RFC3339),
+	)
+}
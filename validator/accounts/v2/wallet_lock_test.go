@@ -0,0 +1,35 @@
+package v2
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+)
+
+func TestWallet_Lock(t *testing.T) {
+	walletDir, passwordsDir, _ := setupWalletAndPasswordsDir(t)
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:      walletDir,
+		passwordsDir:   passwordsDir,
+		keymanagerKind: v2keymanager.Direct,
+	})
+	wallet, err := CreateWallet(cliCtx)
+	require.NoError(t, err)
+
+	walletLock, err := wallet.Lock()
+	require.NoError(t, err)
+	_, err = wallet.Lock()
+	assert.ErrorContains(t, fmt.Sprintf("is in use by process %d", os.Getpid()), err)
+	// The lock file in the accounts directory does not get in the way of opening the wallet.
+	_, err = OpenWallet(cliCtx)
+	require.NoError(t, err)
+
+	require.NoError(t, walletLock.Unlock())
+	walletLock, err = wallet.Lock()
+	require.NoError(t, err)
+	require.NoError(t, walletLock.Unlock())
+}
@@ -0,0 +1,32 @@
+// +build windows
+
+package v2
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// Locks a byte far past the lease rather than the file, so the lease stays readable by the
+// processes the lock keeps out.
+const lockOffset = 0x7fffffff
+
+func lockFile(f *os.File) error {
+	err := windows.LockFileEx(
+		windows.Handle(f.Fd()),
+		windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY,
+		0, /* reserved */
+		1, /* bytes low */
+		0, /* bytes high */
+		&windows.Overlapped{Offset: lockOffset},
+	)
+	if err == windows.ERROR_LOCK_VIOLATION {
+		return errWalletLockHeld
+	}
+	return err
+}
+
+func unlockFile(f *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0 /* reserved */, 1, 0, &windows.Overlapped{Offset: lockOffset})
+}
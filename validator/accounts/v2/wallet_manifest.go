@@ -0,0 +1,257 @@
+package v2
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// walletManifestFileName is the file in the accounts directory of a wallet listing the sha256
+	// hashes of the files of the wallet, signed with a key kept outside of the wallet, so files
+	// modified other than by Prysm are detected before any key of the wallet is loaded.
+	walletManifestFileName = "wallet-manifest.json"
+	// manifestKeySize is the size in bytes of the keys wallet manifests are signed with.
+	manifestKeySize = 32
+)
+
+// walletManifest is the content of the manifest file of a wallet.
+type walletManifest struct {
+	// Files are the hex encoded sha256 hashes of the files of the wallet, by path.
+	Files map[string]string `json:"files"`
+	// Signature is the hex encoded HMAC-SHA256 of the files with the manifest key.
+	Signature string `json:"signature"`
+}
+
+// SetManifestKeyFile makes the wallet keep a manifest of its files signed with the key of a file,
+// which is created with a new random key if it does not exist. The key file must be kept outside
+// of the wallet, where it cannot be modified along with the wallet, and be given to every command
+// changing the wallet, or the manifest no longer matches the files of the wallet.
+func (w *Wallet) SetManifestKeyFile(keyFile string) error {
+	keyFile, err := expandPath(keyFile)
+	if err != nil {
+		return errors.Wrap(err, "could not parse manifest key file")
+	}
+	if isWithinDir(keyFile, w.walletDir) {
+		return fmt.Errorf("manifest key file %s must be kept outside of the wallet directory %s", keyFile, w.walletDir)
+	}
+	encoded, err := ioutil.ReadFile(keyFile)
+	if os.IsNotExist(err) {
+		key := make([]byte, manifestKeySize)
+		if _, err := rand.Read(key); err != nil {
+			return errors.Wrap(err, "could not generate manifest key")
+		}
+		if err := os.MkdirAll(filepath.Dir(keyFile), DirectoryPermissions); err != nil {
+			return errors.Wrapf(err, "could not create path: %s", filepath.Dir(keyFile))
+		}
+		if err := writeFileAtomic(keyFile, []byte(hex.EncodeToString(key)), FilePermissions); err != nil {
+			return errors.Wrapf(err, "could not write manifest key file %s", keyFile)
+		}
+		log.WithField("keyFile", keyFile).Info("Generated new wallet manifest key")
+		w.manifestKey = key
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "could not read manifest key file %s", keyFile)
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil || len(key) != manifestKeySize {
+		return fmt.Errorf("manifest key file %s does not hold a hex encoded key of %d bytes", keyFile, manifestKeySize)
+	}
+	w.manifestKey = key
+	return nil
+}
+
+// VerifyManifest checks the files of the wallet against its signed manifest, failing if the
+// manifest was not signed with the manifest key of the wallet, or if any file of the wallet was
+// modified, removed or added since the manifest was last updated. A wallet without a manifest is
+// given one of its current files. Wallets without a manifest key are not checked.
+func (w *Wallet) VerifyManifest(ctx context.Context) error {
+	if w.manifestKey == nil {
+		return nil
+	}
+	manifest, err := w.readManifest(ctx)
+	if err != nil {
+		return err
+	}
+	files, err := manifestFiles(ctx, w.files())
+	if err != nil {
+		return errors.Wrap(err, "could not read wallet files")
+	}
+	if manifest == nil {
+		if err := w.checkWritable(); err != nil {
+			return errors.Wrap(err, "wallet has no manifest")
+		}
+		log.Warn("Wallet has no manifest, creating one of its current files")
+		return w.writeManifest(ctx, files)
+	}
+	problems := make([]string, 0)
+	for filePath, hash := range files {
+		expected, ok := manifest.Files[filePath]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s is not in the manifest", filePath))
+		} else if expected != hash {
+			problems = append(problems, fmt.Sprintf("%s was modified", filePath))
+		}
+	}
+	for filePath := range manifest.Files {
+		if _, ok := files[filePath]; !ok {
+			problems = append(problems, fmt.Sprintf("%s is missing", filePath))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("wallet files do not match the wallet manifest: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Updates the manifest of the wallet with the hashes of files written to the wallet, dropping the
+// files and directories removed from it and adding the files of directories moved into it, such as
+// restored from its archive. The manifest is only updated if its signature is valid, so a modified
+// manifest is never signed.
+func (w *Wallet) updateManifest(ctx context.Context, written map[string]string, removed []string, addedDirs []string) error {
+	if w.manifestKey == nil {
+		return nil
+	}
+	manifest, err := w.readManifest(ctx)
+	if err != nil {
+		return err
+	}
+	if manifest == nil {
+		// Wallets are given a manifest of all of their files when first verified.
+		return nil
+	}
+	files := manifest.Files
+	for _, removedPath := range removed {
+		for filePath := range files {
+			if filePath == removedPath || strings.HasPrefix(filePath, removedPath+"/") {
+				delete(files, filePath)
+			}
+		}
+	}
+	if len(addedDirs) > 0 {
+		current, err := manifestFiles(ctx, w.files())
+		if err != nil {
+			return errors.Wrap(err, "could not read wallet files")
+		}
+		for _, dir := range addedDirs {
+			for filePath, hash := range current {
+				if strings.HasPrefix(filePath, dir+"/") {
+					files[filePath] = hash
+				}
+			}
+		}
+	}
+	for filePath, hash := range written {
+		if isManifestFile(filePath) {
+			files[filePath] = hash
+		}
+	}
+	return w.writeManifest(ctx, files)
+}
+
+// Reads the manifest of the wallet, nil if it has none, failing if its signature is invalid.
+func (w *Wallet) readManifest(ctx context.Context) (*walletManifest, error) {
+	encoded, err := w.files().readFile(ctx, walletManifestFileName)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read wallet manifest")
+	}
+	manifest := &walletManifest{}
+	if err := json.Unmarshal(encoded, manifest); err != nil {
+		return nil, errors.Wrap(err, "could not decode wallet manifest")
+	}
+	if manifest.Files == nil {
+		manifest.Files = make(map[string]string)
+	}
+	signature, err := hex.DecodeString(manifest.Signature)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode wallet manifest signature")
+	}
+	expected, err := signManifest(w.manifestKey, manifest.Files)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(signature, expected) {
+		return nil, errors.New("wallet manifest signature is invalid, the manifest was modified or signed with another key")
+	}
+	return manifest, nil
+}
+
+func (w *Wallet) writeManifest(ctx context.Context, files map[string]string) error {
+	signature, err := signManifest(w.manifestKey, files)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.MarshalIndent(&walletManifest{
+		Files:     files,
+		Signature: hex.EncodeToString(signature),
+	}, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "could not encode wallet manifest")
+	}
+	if err := w.files().writeFile(ctx, walletManifestFileName, encoded); err != nil {
+		return errors.Wrap(err, "could not write wallet manifest")
+	}
+	return nil
+}
+
+// Returns the HMAC-SHA256 of the files of a manifest, encoded with their paths in lexical order.
+func signManifest(key []byte, files map[string]string) ([]byte, error) {
+	encoded, err := json.Marshal(files)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encode wallet manifest files")
+	}
+	mac := hmac.New(sha256.New, key)
+	if _, err := mac.Write(encoded); err != nil {
+		return nil, err
+	}
+	return mac.Sum(nil), nil
+}
+
+// Returns the hashes of the files of the storage of a wallet listed in its manifest: the files
+// synced to other copies of the wallet, other than its journal.
+func manifestFiles(ctx context.Context, storage walletStorage) (map[string]string, error) {
+	files, err := syncedFiles(ctx, storage)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make(map[string]string, len(files))
+	for filePath, data := range files {
+		if isManifestFile(filePath) {
+			hashes[filePath] = hashOfData(data)
+		}
+	}
+	return hashes, nil
+}
+
+func isManifestFile(filePath string) bool {
+	if filePath == walletManifestFileName || filePath == walletJournalFileName {
+		return false
+	}
+	parts := strings.Split(filePath, "/")
+	if len(parts) > 2 {
+		return false
+	}
+	if len(parts) == 2 {
+		dirName := parts[0]
+		if dirName == archiveDirName || dirName == tombstoneDirName || strings.HasPrefix(dirName, ".") {
+			return false
+		}
+	}
+	return isSyncedFile(parts[len(parts)-1])
+}
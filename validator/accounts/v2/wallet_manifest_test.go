@@ -0,0 +1,78 @@
+package v2
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestWallet_VerifyManifest(t *testing.T) {
+	walletDir, passwordsDir, _ := setupWalletAndPasswordsDir(t)
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:      walletDir,
+		passwordsDir:   passwordsDir,
+		keymanagerKind: v2keymanager.Direct,
+	})
+	wallet, err := NewWallet(cliCtx, v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	name, err := keymanager.CreateAccount(ctx, password)
+	require.NoError(t, err)
+
+	keyDir := filepath.Join(testutil.TempDir(), t.Name())
+	t.Cleanup(func() {
+		assert.NoError(t, os.RemoveAll(keyDir))
+	})
+	keyFile := filepath.Join(keyDir, "manifest.key")
+	assert.ErrorContains(t, "must be kept outside of the wallet", wallet.SetManifestKeyFile(filepath.Join(walletDir, "manifest.key")))
+	require.NoError(t, wallet.SetManifestKeyFile(keyFile))
+	assert.Equal(t, true, fileExists(keyFile))
+
+	// Wallets without a manifest are given one of their current files.
+	require.NoError(t, wallet.VerifyManifest(ctx))
+	require.NoError(t, wallet.VerifyManifest(ctx))
+
+	// Files written and accounts renamed by the wallet are kept in the manifest.
+	newName, err := keymanager.CreateAccount(ctx, password)
+	require.NoError(t, err)
+	require.NoError(t, wallet.renameAccount(newName, "renamed"))
+	require.NoError(t, wallet.VerifyManifest(ctx))
+
+	// Wallets opened again with the same key file verify the manifest.
+	wallet, err = OpenWalletAtPath(walletDir, "", false /* readOnly */)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SetManifestKeyFile(keyFile))
+	require.NoError(t, wallet.VerifyManifest(ctx))
+
+	keystoreFileName, err := wallet.FileNameAtPath(ctx, name, direct.KeystoreFileName)
+	require.NoError(t, err)
+	keystorePath := filepath.Join(wallet.AccountsDir(), name, keystoreFileName)
+	keystore, err := ioutil.ReadFile(keystorePath)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(keystorePath, []byte("{}"), FilePermissions))
+	assert.ErrorContains(t, name+"/"+keystoreFileName+" was modified", wallet.VerifyManifest(ctx))
+	require.NoError(t, ioutil.WriteFile(keystorePath, keystore, FilePermissions))
+	require.NoError(t, os.MkdirAll(filepath.Join(wallet.AccountsDir(), "planted"), DirectoryPermissions))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(wallet.AccountsDir(), "planted", keystoreFileName), keystore, FilePermissions))
+	assert.ErrorContains(t, "planted/"+keystoreFileName+" is not in the manifest", wallet.VerifyManifest(ctx))
+	require.NoError(t, os.RemoveAll(filepath.Join(wallet.AccountsDir(), "planted")))
+	require.NoError(t, wallet.VerifyManifest(ctx))
+
+	// Manifests signed with another key are refused.
+	require.NoError(t, wallet.SetManifestKeyFile(filepath.Join(keyDir, "other.key")))
+	assert.ErrorContains(t, "wallet manifest signature is invalid", wallet.VerifyManifest(ctx))
+}
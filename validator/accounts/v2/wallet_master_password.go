@@ -0,0 +1,352 @@
+package v2
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/urfave/cli/v2"
+	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// masterPasswordFileName is the file in the accounts path of a non-HD wallet holding the salt
+	// the passwords of its accounts are derived with from the wallet password, its master password,
+	// in place of a password file per account.
+	masterPasswordFileName = "master-password.json"
+	masterPasswordVersion  = 1
+	masterPasswordSaltSize = 32
+	// Account passwords are derived with the name of their account appended to this info.
+	accountPasswordInfo   = "prysm-account-password:"
+	accountPasswordLength = 32
+)
+
+// masterPasswordFile is the content of the master password file of a wallet. The salt is also
+// encrypted with the wallet password with keystorev4, so a wrong wallet password is detected
+// before the passwords of the accounts are derived with it.
+type masterPasswordFile struct {
+	Version int                    `json:"version"`
+	Salt    string                 `json:"salt"`
+	Crypto  map[string]interface{} `json:"crypto"`
+}
+
+// UseMasterPassword re-encrypts the keystores of the accounts of a non-HD wallet with passwords
+// derived from the wallet password with HKDF, and removes their password files, so the wallet
+// password is the only secret to manage: it is entered once to open the wallet, and changing it
+// with wallet-v2 change-password rotates the passwords of every account. Accounts created or
+// imported afterwards are encrypted with their derived password too. Wallets without a wallet
+// password are given one.
+func UseMasterPassword(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	wallet, err := openDirectWallet(cliCtx)
+	if err != nil {
+		return err
+	}
+	if wallet.masterPasswordSalt != nil {
+		return errors.New("wallet already uses a master password")
+	}
+	if fileExists(filepath.Join(wallet.accountsPath, direct.AccountsKeystoreFileName)) {
+		return errors.New("compacted wallets cannot use a master password, expand the wallet with wallet-v2 compact --expand first")
+	}
+	if wallet.walletPassword == "" {
+		wallet.walletPassword, err = inputPassword(cliCtx, flags.WalletPasswordFileFlag, newWalletPasswordPromptText, confirmPass)
+		if err != nil {
+			return errors.Wrap(err, "could not get password")
+		}
+	}
+	lock, err := wallet.Lock()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := lock.Unlock(); err != nil {
+			log.WithError(err).Error("Could not unlock wallet")
+		}
+	}()
+	converted, err := wallet.useMasterPassword(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf(
+		"Encrypted %s accounts with passwords derived from the wallet password, kept in %s\n",
+		au.BrightGreen(converted),
+		au.BrightGreen(filepath.Join(wallet.accountsPath, masterPasswordFileName)),
+	)
+	return nil
+}
+
+// Writes the master password file and re-encrypts the keystores of the accounts of the wallet with
+// their derived passwords, then removes the password files and the password store, returning how
+// many accounts were re-encrypted. The master password file is removed again if re-encrypting the
+// keystores fails.
+func (w *Wallet) useMasterPassword(ctx context.Context) (int, error) {
+	if err := w.checkWritable(); err != nil {
+		return 0, err
+	}
+	salt := make([]byte, masterPasswordSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return 0, errors.Wrap(err, "could not generate master password salt")
+	}
+	accountNames, err := w.ListDirs()
+	if err != nil {
+		return 0, errors.Wrap(err, "could not list accounts")
+	}
+	files := make([]*reencryptedFile, 0, len(accountNames))
+	for _, accountName := range accountNames {
+		password, err := w.ReadPasswordFromDisk(ctx, accountName+direct.PasswordFileSuffix)
+		if err != nil {
+			return 0, err
+		}
+		derived, err := deriveAccountPassword(w.walletPassword, salt, accountName)
+		if err != nil {
+			return 0, err
+		}
+		file, err := w.reencryptAccountKeystore(ctx, accountName, password, derived)
+		if err != nil {
+			return 0, err
+		}
+		files = append(files, file)
+	}
+	encoded, err := encryptMasterPasswordFile(salt, w.walletPassword)
+	if err != nil {
+		return 0, err
+	}
+	// The salt is written before any keystore is re-encrypted with a password derived from it, so
+	// a crash in between never leaves keystores only the lost salt could decrypt.
+	masterPasswordPath := filepath.Join(w.accountsPath, masterPasswordFileName)
+	if err := writeFileAtomic(masterPasswordPath, encoded, FilePermissions); err != nil {
+		return 0, errors.Wrapf(err, "could not write %s", masterPasswordPath)
+	}
+	if err := replaceWalletFiles(files); err != nil {
+		if err := os.Remove(masterPasswordPath); err != nil {
+			log.WithError(err).Errorf("Could not remove %s", masterPasswordPath)
+		}
+		return 0, err
+	}
+	w.masterPasswordSalt = salt
+	deleted := make([]string, 0, len(accountNames)+1)
+	if w.passwordsDir != "" {
+		passwordFiles, err := filepath.Glob(filepath.Join(w.passwordsDir, "*"+direct.PasswordFileSuffix))
+		if err != nil {
+			return 0, err
+		}
+		if w.passwordStore {
+			passwordFiles = append(passwordFiles, w.passwordStorePath())
+		}
+		for _, passwordFile := range passwordFiles {
+			if err := os.Remove(passwordFile); err != nil {
+				return 0, errors.Wrapf(err, "could not remove %s", passwordFile)
+			}
+			deleted = append(deleted, passwordFile)
+		}
+	}
+	w.passwords = nil
+	w.passwordStore = false
+	written := map[string][]byte{masterPasswordFileName: encoded}
+	for _, file := range files {
+		written[w.journalPath(file.path)] = file.data
+	}
+	entry := journalWrites(withJournalAction(ctx, journalActionMasterPasswordEnabled), written)
+	entry.Deleted = deleted
+	if err := w.recordMutation(ctx, entry); err != nil {
+		return 0, err
+	}
+	return len(files), nil
+}
+
+// DeriveAccountPassword returns the password of an account derived from the master password of
+// the wallet, and false if the passwords of its accounts are not derived from one.
+func (w *Wallet) DeriveAccountPassword(accountName string) (string, bool, error) {
+	if w.masterPasswordSalt == nil {
+		return "", false, nil
+	}
+	password, err := deriveAccountPassword(w.walletPassword, w.masterPasswordSalt, accountName)
+	if err != nil {
+		return "", false, err
+	}
+	return password, true, nil
+}
+
+// Reads the salt of the master password file of the wallet, if it has one, inputting the wallet
+// password it was written with.
+func (w *Wallet) readMasterPassword(inputWalletPassword func() (string, error)) error {
+	encoded, err := w.files().readFile(context.Background(), masterPasswordFileName)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not read master password file")
+	}
+	if w.walletPassword == "" {
+		w.walletPassword, err = inputWalletPassword()
+		if err != nil {
+			return err
+		}
+	}
+	w.masterPasswordSalt, err = decryptMasterPasswordFile(encoded, w.walletPassword)
+	return err
+}
+
+// Re-encrypts the master password file and the keystores of the accounts of the wallet with a new
+// wallet password, in memory.
+func (w *Wallet) reencryptMasterPasswordFiles(newPassword string) ([]*reencryptedFile, error) {
+	accountNames, err := w.ListDirs()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list accounts")
+	}
+	files := make([]*reencryptedFile, 0, len(accountNames)+1)
+	for _, accountName := range accountNames {
+		oldPassword, err := deriveAccountPassword(w.walletPassword, w.masterPasswordSalt, accountName)
+		if err != nil {
+			return nil, err
+		}
+		newDerived, err := deriveAccountPassword(newPassword, w.masterPasswordSalt, accountName)
+		if err != nil {
+			return nil, err
+		}
+		file, err := w.reencryptAccountKeystore(context.Background(), accountName, oldPassword, newDerived)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	masterPasswordPath := filepath.Join(w.accountsPath, masterPasswordFileName)
+	enc, err := w.files().readFile(context.Background(), masterPasswordFileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read %s", masterPasswordPath)
+	}
+	encoded, err := encryptMasterPasswordFile(w.masterPasswordSalt, newPassword)
+	if err != nil {
+		return nil, err
+	}
+	return append(files, &reencryptedFile{
+		path: masterPasswordPath, name: masterPasswordFileName, previous: enc, data: encoded,
+	}), nil
+}
+
+// Re-encrypts the keystore of an account from its current password to a new one, in memory.
+func (w *Wallet) reencryptAccountKeystore(
+	ctx context.Context, accountName string, oldPassword string, newPassword string,
+) (*reencryptedFile, error) {
+	storage := w.files()
+	matches, err := storage.glob(ctx, accountName, w.keystoreFileGlob())
+	if err != nil || len(matches) == 0 {
+		return nil, fmt.Errorf("no keystore found for account %s", accountName)
+	}
+	keystoreName := path.Join(accountName, matches[0])
+	enc, err := storage.readFile(ctx, keystoreName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read keystore of account %s", accountName)
+	}
+	keystorePath := filepath.Join(w.accountsPath, filepath.FromSlash(keystoreName))
+	encoded, err := reencryptKeystoreFile(enc, oldPassword, newPassword)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not re-encrypt keystore of account %s", accountName)
+	}
+	return &reencryptedFile{path: keystorePath, name: keystoreName, previous: enc, data: encoded}, nil
+}
+
+// Returns a keystore encoded as JSON which is encrypted with the derived password of an account if
+// the wallet has a master password, re-encrypting it from the password it is encrypted with.
+func (w *Wallet) keystoreForAccount(accountName string, keystoreBytes []byte, password string) ([]byte, string, error) {
+	derived, ok, err := w.DeriveAccountPassword(accountName)
+	if err != nil || !ok {
+		return keystoreBytes, password, err
+	}
+	encoded, err := reencryptKeystoreFile(keystoreBytes, password, derived)
+	if err != nil {
+		return nil, "", err
+	}
+	return encoded, derived, nil
+}
+
+// Returns the name a wallet file is recorded by in the journal, its path relative to the accounts
+// path of the wallet, or its base name for files kept elsewhere such as the password store.
+func (w *Wallet) journalPath(filePath string) string {
+	relPath, err := filepath.Rel(w.accountsPath, filePath)
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return filepath.Base(filePath)
+	}
+	return filepath.ToSlash(relPath)
+}
+
+func reencryptKeystoreFile(enc []byte, oldPassword string, newPassword string) ([]byte, error) {
+	keystore := &v2keymanager.Keystore{}
+	if err := json.Unmarshal(enc, keystore); err != nil {
+		return nil, errors.Wrap(err, "could not decode keystore")
+	}
+	reencrypted, err := direct.ReencryptKeystore(keystore, oldPassword, newPassword)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.MarshalIndent(reencrypted, "", "\t")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encode keystore")
+	}
+	return encoded, nil
+}
+
+// Derives the password of an account from the wallet password with HKDF-SHA256, salted with the
+// salt of the master password file and bound to the name of the account.
+func deriveAccountPassword(walletPassword string, salt []byte, accountName string) (string, error) {
+	if walletPassword == "" {
+		return "", errors.New("wallet has no master password")
+	}
+	secret := make([]byte, accountPasswordLength)
+	reader := hkdf.New(sha256.New, []byte(walletPassword), salt, []byte(accountPasswordInfo+accountName))
+	if _, err := io.ReadFull(reader, secret); err != nil {
+		return "", errors.Wrap(err, "could not derive account password")
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+func encryptMasterPasswordFile(salt []byte, walletPassword string) ([]byte, error) {
+	cryptoFields, err := keystorev4.New().Encrypt(salt, walletPassword)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encrypt master password salt")
+	}
+	encoded, err := json.MarshalIndent(&masterPasswordFile{
+		Version: masterPasswordVersion,
+		Salt:    hex.EncodeToString(salt),
+		Crypto:  cryptoFields,
+	}, "", "\t")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encode master password file")
+	}
+	return encoded, nil
+}
+
+func decryptMasterPasswordFile(encoded []byte, walletPassword string) ([]byte, error) {
+	file := &masterPasswordFile{}
+	if err := json.Unmarshal(encoded, file); err != nil {
+		return nil, errors.Wrap(err, "could not decode master password file")
+	}
+	if file.Version != masterPasswordVersion {
+		return nil, fmt.Errorf("unsupported master password file version %d", file.Version)
+	}
+	salt, err := hex.DecodeString(file.Salt)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode master password salt")
+	}
+	decrypted, err := keystorev4.New().Decrypt(file.Crypto, walletPassword)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decrypt master password file, wrong wallet password")
+	}
+	if hex.EncodeToString(decrypted) != file.Salt {
+		return nil, errors.New("master password file does not match its salt")
+	}
+	return salt, nil
+}
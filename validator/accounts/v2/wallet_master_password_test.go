@@ -0,0 +1,85 @@
+package v2
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestUseMasterPassword(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		walletPasswordFile:  passwordFile,
+		accountPasswordFile: passwordFile,
+		keymanagerKind:      v2keymanager.Direct,
+	}
+	_, err := CreateWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	require.NoError(t, CreateAccount(setupWalletCtx(t, cfg)))
+	require.NoError(t, CreateAccount(setupWalletCtx(t, cfg)))
+	want := walletPubKeys(t, cfg)
+	require.Equal(t, 2, len(want))
+	passwordFiles := func() []string {
+		files, err := filepath.Glob(filepath.Join(passwordsDir, "*"+direct.PasswordFileSuffix))
+		require.NoError(t, err)
+		return files
+	}
+	require.Equal(t, 2, len(passwordFiles()))
+
+	require.NoError(t, UseMasterPassword(setupWalletCtx(t, cfg)))
+	assert.Equal(t, 0, len(passwordFiles()))
+	accountsPath := filepath.Join(walletDir, v2keymanager.Direct.String())
+	assert.Equal(t, true, fileExists(filepath.Join(accountsPath, masterPasswordFileName)))
+	assert.ErrorContains(t, "already uses a master password", UseMasterPassword(setupWalletCtx(t, cfg)))
+	assert.DeepEqual(t, want, walletPubKeys(t, cfg))
+
+	// Keystores of accounts are encrypted with their derived password, never the account password.
+	wallet, err := OpenWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	accountNames, err := wallet.ListDirs()
+	require.NoError(t, err)
+	derived, ok, err := wallet.DeriveAccountPassword(accountNames[0])
+	require.NoError(t, err)
+	require.Equal(t, true, ok)
+	require.NoError(t, wallet.checkPasswordForAccount(accountNames[0], derived))
+	assert.ErrorContains(t, "invalid checksum", wallet.checkPasswordForAccount(accountNames[0], password))
+	assert.ErrorContains(t, "derived from the wallet master password", wallet.WritePasswordToDisk(
+		context.Background(), accountNames[0]+direct.PasswordFileSuffix, password,
+	))
+
+	// Renamed accounts are re-encrypted with the password derived with their new name.
+	require.NoError(t, wallet.renameAccount(accountNames[0], "renamed"))
+	renamed, _, err := wallet.DeriveAccountPassword("renamed")
+	require.NoError(t, err)
+	require.NoError(t, wallet.checkPasswordForAccount("renamed", renamed))
+
+	// Accounts created afterwards are encrypted with their derived password too.
+	require.NoError(t, CreateAccount(setupWalletCtx(t, cfg)))
+	assert.Equal(t, 0, len(passwordFiles()))
+	assert.Equal(t, 3, len(walletPubKeys(t, cfg)))
+
+	// Changing the wallet password rotates the passwords of every account.
+	newPasswordFile := filepath.Join(filepath.Dir(passwordFile), "new-wallet-password.txt")
+	require.NoError(t, ioutil.WriteFile(newPasswordFile, []byte("Sh1nyN3wWall3t!"), os.ModePerm))
+	cfg.newWalletPassword = newPasswordFile
+	require.NoError(t, ChangeWalletPassword(setupWalletCtx(t, cfg)))
+	_, err = OpenWallet(setupWalletCtx(t, cfg))
+	assert.ErrorContains(t, "wrong wallet password", err)
+	cfg.walletPasswordFile = newPasswordFile
+	assert.Equal(t, 3, len(walletPubKeys(t, cfg)))
+	wallet, err = OpenWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	rotated, _, err := wallet.DeriveAccountPassword("renamed")
+	require.NoError(t, err)
+	assert.NotEqual(t, renamed, rotated)
+	require.NoError(t, wallet.checkPasswordForAccount("renamed", rotated))
+}
@@ -0,0 +1,151 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/urfave/cli/v2"
+)
+
+// MigrateWallet creates a new v2 wallet holding the same validating keys as the v1 keymanager
+// selected by the --keymanager and --keymanageropts flags, such as interop, keystore, or unencrypted.
+// The passphrase of a v1 keystore keymanager is kept as account password unless another one is
+// given, and the public keys of the new wallet must match the v1 ones exactly.
+func MigrateWallet(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	kind := v2keymanager.Direct
+	if cliCtx.IsSet(flags.KeymanagerKindFlag.Name) {
+		var err error
+		kind, err = v2keymanager.ParseKind(cliCtx.String(flags.KeymanagerKindFlag.Name))
+		if err != nil {
+			return err
+		}
+	}
+	switch kind {
+	case v2keymanager.Direct:
+	case v2keymanager.Derived:
+		return errors.New(
+			"v1 keys are not derived from a seed phrase and cannot be held by a derived wallet, " +
+				"migrate them into a direct wallet instead",
+		)
+	default:
+		return fmt.Errorf("cannot migrate v1 keys into a %s wallet", kind)
+	}
+	walletDir := cliCtx.String(flags.WalletDirFlag.Name)
+	ok, err := hasDir(walletDir)
+	if err != nil {
+		return errors.Wrapf(err, "could not check if wallet dir %s exists", walletDir)
+	}
+	if ok {
+		isEmpty, err := isEmptyWallet(walletDir)
+		if err != nil {
+			return errors.Wrap(err, "could not check if wallet has files")
+		}
+		if !isEmpty {
+			return fmt.Errorf(
+				"a wallet already exists at %s, use accounts-v2 migrate-from-v1 to add v1 keys to it", walletDir,
+			)
+		}
+	}
+
+	manager, opts, err := v1KeymanagerOpts(cliCtx)
+	if err != nil {
+		return err
+	}
+	legacyKeys, err := readV1SecretKeys(cliCtx)
+	if err != nil {
+		return err
+	}
+	if len(legacyKeys) == 0 {
+		return errors.New("no validating keys found in the v1 keymanager")
+	}
+	password, err := migrationAccountPassword(cliCtx, manager, opts)
+	if err != nil {
+		return err
+	}
+	wallet, err := createDirectWallet(cliCtx)
+	if err != nil {
+		return errors.Wrap(err, "could not create wallet")
+	}
+	existing, err := walletKeysFromCli(ctx, cliCtx, wallet)
+	if err != nil {
+		return err
+	}
+	if _, err := wallet.importSecretKeys(ctx, legacyKeys, password, existing, nil /* filter */); err != nil {
+		return err
+	}
+	if err := verifyPublicKeyParity(ctx, wallet, legacyKeys); err != nil {
+		return errors.Wrap(err, "could not verify migrated wallet")
+	}
+	if err := verifyMigratedAccounts(ctx, wallet, legacyKeys); err != nil {
+		return errors.Wrap(err, "could not verify migrated accounts")
+	}
+	if err := verifySlashingProtectionHistory(ctx, cliCtx, publicKeysOf(legacyKeys)); err != nil {
+		return errors.Wrap(err, "could not verify slashing protection history")
+	}
+	fmt.Printf(
+		"Successfully migrated the %s keymanager with %s accounts to a %s wallet at %s\n",
+		manager,
+		au.BrightMagenta(len(legacyKeys)),
+		kind,
+		au.BrightGreen(wallet.walletDir),
+	)
+	return nil
+}
+
+// The account password is read from --account-password-file if it is set. Otherwise the
+// passphrase of a v1 keystore keymanager is kept, and any other keymanager prompts for one.
+func migrationAccountPassword(cliCtx *cli.Context, manager string, opts string) (string, error) {
+	if cliCtx.String(flags.AccountPasswordFileFlag.Name) == "" && manager == "keystore" {
+		keystoreOpts := struct {
+			Passphrase string `json:"passphrase"`
+		}{}
+		if err := json.Unmarshal([]byte(opts), &keystoreOpts); err != nil {
+			return "", errors.Wrap(err, "could not decode keystore keymanager options")
+		}
+		if keystoreOpts.Passphrase != "" {
+			log.Info("Keeping the v1 keystore passphrase as the password of the migrated accounts")
+			return keystoreOpts.Passphrase, nil
+		}
+	}
+	password, err := inputPassword(cliCtx, flags.AccountPasswordFileFlag, newAccountPasswordPromptText, confirmPass)
+	if err != nil {
+		return "", errors.Wrap(err, "could not input new account password")
+	}
+	return password, nil
+}
+
+// Checks the wallet holds exactly the public keys of the v1 keymanager.
+func verifyPublicKeyParity(ctx context.Context, wallet *Wallet, legacyKeys map[[48]byte]bls.SecretKey) error {
+	keymanager, err := wallet.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+	if err != nil {
+		return errors.Wrap(err, "could not reload keymanager")
+	}
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not fetch validating public keys")
+	}
+	if len(pubKeys) != len(legacyKeys) {
+		return fmt.Errorf("wallet holds %d public keys, expected %d", len(pubKeys), len(legacyKeys))
+	}
+	for _, pubKey := range pubKeys {
+		if _, ok := legacyKeys[pubKey]; !ok {
+			return fmt.Errorf("wallet holds public key %#x which is not in the v1 keymanager", bytesutil.Trunc(pubKey[:]))
+		}
+	}
+	return nil
+}
+
+func publicKeysOf(secretKeys map[[48]byte]bls.SecretKey) [][48]byte {
+	pubKeys := make([][48]byte, 0, len(secretKeys))
+	for pubKey := range secretKeys {
+		pubKeys = append(pubKeys, pubKey)
+	}
+	return pubKeys
+}
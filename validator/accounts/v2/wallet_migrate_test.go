@@ -0,0 +1,75 @@
+package v2
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/interop"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+)
+
+func TestMigrateWallet_Interop(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	dataDir := filepath.Join(testutil.TempDir(), t.Name())
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dataDir), "Failed to remove directory")
+	})
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		accountPasswordFile: passwordFile,
+		dataDir:             dataDir,
+		keymanagerKind:      v2keymanager.Direct,
+		v1Keymanager:        "interop",
+		v1KeymanagerOpts:    `{"keys":4,"offset":2}`,
+	})
+	require.NoError(t, MigrateWallet(cliCtx))
+
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	assert.Equal(t, v2keymanager.Direct, wallet.KeymanagerKind())
+	keymanager, err := wallet.InitializeKeymanager(ctx, true)
+	require.NoError(t, err)
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 4, len(pubKeys))
+	_, wantedPubKeys, err := interop.DeterministicallyGenerateKeys(2, 4)
+	require.NoError(t, err)
+	migrated := make(map[[48]byte]bool, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		migrated[pubKey] = true
+	}
+	for _, wanted := range wantedPubKeys {
+		assert.Equal(t, true, migrated[bytesutil.ToBytes48(wanted.Marshal())], "Missing migrated key %#x", wanted.Marshal())
+	}
+
+	// Migrating again must not touch the wallet created by the first migration.
+	assert.ErrorContains(t, "a wallet already exists", MigrateWallet(cliCtx))
+}
+
+func TestMigrateWallet_DerivedUnsupported(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		accountPasswordFile: passwordFile,
+		keymanagerKind:      v2keymanager.Derived,
+		v1Keymanager:        "interop",
+		v1KeymanagerOpts:    `{"keys":1,"offset":0}`,
+	})
+	assert.ErrorContains(t, "cannot be held by a derived wallet", MigrateWallet(cliCtx))
+}
+
+func TestMigrationAccountPassword_KeepsKeystorePassphrase(t *testing.T) {
+	cliCtx := setupWalletCtx(t, &testWalletConfig{})
+	password, err := migrationAccountPassword(cliCtx, "keystore", `{"path":"/tmp/keys","passphrase":"v1-passphrase"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "v1-passphrase", password)
+}
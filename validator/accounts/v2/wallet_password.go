@@ -0,0 +1,292 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/derived"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/urfave/cli/v2"
+)
+
+// Suffix of the files a re-encrypted wallet file is written to before replacing it.
+const reencryptedFileSuffix = ".reencrypted"
+
+var errNoWalletPassword = errors.New(
+	"wallet has no wallet password, only HD wallets and wallets with an encrypted keymanager config, " +
+		"compacted accounts, encrypted account passwords or a master password do",
+)
+
+// reencryptedFile is a wallet file encrypted with the wallet password, along with its contents
+// encrypted with a new wallet password. The name of a file kept in the storage of the wallet is
+// its path relative to the accounts directory, and empty for files kept on disk outside of it.
+type reencryptedFile struct {
+	path     string
+	name     string
+	previous []byte
+	data     []byte
+}
+
+// ChangeWalletPassword re-encrypts every file of a wallet encrypted with its wallet password, the
+// seed of an HD wallet, an encrypted keymanager config, the accounts keystore of a compacted
+// non-HD wallet and the password store of a non-HD wallet, with a new wallet password given by
+// --new-wallet-password-file or entered at the prompt. The keystores of the accounts of a wallet
+// with a master password are re-encrypted with passwords derived from the new one, and the
+// container of an encrypted wallet is re-encrypted along with the files in it. All files are
+// re-encrypted before any is replaced, and the replaced files are restored if replacing another
+// one fails.
+func ChangeWalletPassword(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	if err := wallet.checkWritable(); err != nil {
+		return err
+	}
+	if wallet.walletPassword == "" {
+		return errNoWalletPassword
+	}
+	if wallet.hasSecurityKey() {
+		return errors.New(
+			"the wallet password is wrapped by a security key, remove it with wallet-v2 remove-security-key first",
+		)
+	}
+	newPassword, err := inputPassword(cliCtx, flags.NewWalletPasswordFileFlag, newWalletPasswordPromptText, confirmPass)
+	if err != nil {
+		return errors.Wrap(err, "could not input new wallet password")
+	}
+	if newPassword == wallet.walletPassword {
+		return errors.New("new wallet password is the same as the current one")
+	}
+	if err := wallet.changePassword(withJournalAction(ctx, journalActionPasswordChanged), newPassword); err != nil {
+		return err
+	}
+	log.Info("Successfully changed wallet password")
+	return nil
+}
+
+// Re-encrypts the files of the wallet with a new wallet password and replaces them, after writing
+// the given files recovering the new password, such as the security key file wrapping it, so the
+// files are never encrypted with a password nothing on disk recovers. The given files are restored,
+// or removed if they did not exist, when replacing the files of the wallet fails.
+func (w *Wallet) changePassword(ctx context.Context, newPassword string, staged ...*reencryptedFile) error {
+	encrypted, isEncrypted := w.storage.(*encryptedStorage)
+	if w.storage != nil && !isEncrypted {
+		return fmt.Errorf("changing the wallet password is not supported for wallets stored in %s", w.storageURL)
+	}
+	files, err := w.reencryptFiles(ctx, newPassword)
+	if err != nil {
+		return err
+	}
+	if err := replaceWalletFiles(staged); err != nil {
+		return err
+	}
+	if isEncrypted {
+		err = replaceContainerFiles(encrypted, files, newPassword)
+	} else {
+		err = replaceWalletFiles(files)
+	}
+	if err != nil {
+		for _, file := range staged {
+			if err := restoreFile(file); err != nil {
+				log.WithError(err).Errorf("Could not restore %s", file.path)
+			}
+		}
+		return err
+	}
+	files = append(staged, files...)
+	replaced := make(map[string][]byte, len(files))
+	for _, file := range files {
+		replaced[w.journalPath(file.path)] = file.data
+	}
+	if err := w.recordMutation(ctx, journalWrites(ctx, replaced)); err != nil {
+		return err
+	}
+	w.walletPassword = newPassword
+	return nil
+}
+
+// Re-encrypts the files of the wallet encrypted with its wallet password with a new password, in
+// memory.
+func (w *Wallet) reencryptFiles(ctx context.Context, newPassword string) ([]*reencryptedFile, error) {
+	files := make([]*reencryptedFile, 0, 2)
+	storage := w.files()
+	if w.keymanagerKind == v2keymanager.Derived {
+		seedPath := filepath.Join(w.accountsPath, derived.EncryptedSeedFileName)
+		enc, err := storage.readFile(ctx, derived.EncryptedSeedFileName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read %s", seedPath)
+		}
+		seedCfg := &derived.SeedConfig{}
+		if err := json.Unmarshal(enc, seedCfg); err != nil {
+			return nil, errors.Wrap(err, "could not unmarshal seed configuration")
+		}
+		reencrypted, err := derived.ReencryptSeedConfig(seedCfg, w.walletPassword, newPassword)
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := derived.MarshalEncryptedSeedFile(ctx, reencrypted)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not marshal seed configuration")
+		}
+		files = append(files, &reencryptedFile{
+			path: seedPath, name: derived.EncryptedSeedFileName, previous: enc, data: encoded,
+		})
+	}
+	if w.encryptedConfig {
+		configPath := filepath.Join(w.accountsPath, KeymanagerConfigFileName)
+		enc, err := storage.readFile(ctx, KeymanagerConfigFileName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read %s", configPath)
+		}
+		decrypted, err := v2keymanager.DecryptConfig(enc, w.walletPassword)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not decrypt keymanager config with the wallet password")
+		}
+		encoded, err := v2keymanager.EncryptConfig(decrypted, newPassword)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &reencryptedFile{
+			path: configPath, name: KeymanagerConfigFileName, previous: enc, data: encoded,
+		})
+	}
+	if w.keymanagerKind == v2keymanager.Direct {
+		accountsKeystorePath := filepath.Join(w.accountsPath, direct.AccountsKeystoreFileName)
+		enc, err := storage.readFile(ctx, direct.AccountsKeystoreFileName)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, errors.Wrapf(err, "could not read %s", accountsKeystorePath)
+		}
+		if err == nil {
+			reencrypted, err := reencryptAccountsKeystoreFile(enc, w.walletPassword, newPassword)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, &reencryptedFile{
+				path: accountsKeystorePath, name: direct.AccountsKeystoreFileName, previous: enc, data: reencrypted,
+			})
+		}
+	}
+	if w.passwordStore {
+		enc, err := ioutil.ReadFile(w.passwordStorePath())
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read %s", w.passwordStorePath())
+		}
+		passwords, err := decryptPasswordStore(enc, w.walletPassword)
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := encryptPasswordStore(passwords, newPassword)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &reencryptedFile{path: w.passwordStorePath(), previous: enc, data: encoded})
+	}
+	if w.masterPasswordSalt != nil {
+		masterPasswordFiles, err := w.reencryptMasterPasswordFiles(newPassword)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, masterPasswordFiles...)
+	}
+	if w.artifactKey != nil {
+		artifactKeyFile, err := w.reencryptArtifactKeyFile(newPassword)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, artifactKeyFile)
+	}
+	if fileExists(w.totpPath()) {
+		totpFile, err := w.reencryptTOTPFile(newPassword)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, totpFile)
+	}
+	return files, nil
+}
+
+func reencryptAccountsKeystoreFile(enc []byte, oldPassword string, newPassword string) ([]byte, error) {
+	accountsKeystore := &direct.AccountsKeystore{}
+	if err := json.Unmarshal(enc, accountsKeystore); err != nil {
+		return nil, errors.Wrap(err, "could not decode accounts keystore")
+	}
+	reencrypted, err := direct.ReencryptAccountsKeystore(accountsKeystore, oldPassword, newPassword)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.MarshalIndent(reencrypted, "", "\t")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encode accounts keystore")
+	}
+	return encoded, nil
+}
+
+// Replaces the re-encrypted files of an encrypted wallet: the files kept on disk are replaced first,
+// then the container is re-encrypted with the new wallet password along with the files kept in it,
+// in a single write. The files replaced on disk are restored if writing the container fails.
+func replaceContainerFiles(encrypted *encryptedStorage, files []*reencryptedFile, newPassword string) error {
+	onDisk := make([]*reencryptedFile, 0, len(files))
+	inContainer := make(map[string][]byte, len(files))
+	for _, file := range files {
+		if file.name == "" {
+			onDisk = append(onDisk, file)
+		} else {
+			inContainer[file.name] = file.data
+		}
+	}
+	if err := replaceWalletFiles(onDisk); err != nil {
+		return err
+	}
+	if err := encrypted.rekey(inContainer, newPassword); err != nil {
+		for _, file := range onDisk {
+			if err := restoreFile(file); err != nil {
+				log.WithError(err).Errorf("Could not restore %s", file.path)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// Replaces wallet files with their re-encrypted contents, restoring the files already replaced if
+// replacing one fails. Each file is written next to the file it replaces and then renamed over it,
+// so a file is never left partially written.
+func replaceWalletFiles(files []*reencryptedFile) error {
+	for i, file := range files {
+		if err := replaceFile(file.path, file.data); err != nil {
+			for _, replaced := range files[:i] {
+				if err := restoreFile(replaced); err != nil {
+					log.WithError(err).Errorf("Could not restore %s", replaced.path)
+				}
+			}
+			return errors.Wrapf(err, "could not replace %s", file.path)
+		}
+	}
+	return nil
+}
+
+func replaceFile(path string, data []byte) error {
+	return writeFileVia(path+reencryptedFileSuffix, path, data, FilePermissions)
+}
+
+// Restores the previous contents of a replaced file, removing it if it did not exist before.
+func restoreFile(file *reencryptedFile) error {
+	if file.previous == nil {
+		if err := os.Remove(file.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return replaceFile(file.path, file.previous)
+}
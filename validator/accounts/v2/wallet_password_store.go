@@ -0,0 +1,191 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/urfave/cli/v2"
+	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+)
+
+const (
+	// passwordStoreFileName is the file in the passwords directory of a non-HD wallet holding the
+	// passwords of its accounts encrypted with the wallet password, in place of a plaintext password
+	// file per account.
+	passwordStoreFileName = "account-passwords.json"
+	passwordStoreVersion  = 1
+)
+
+// passwordStore is the content of the password store file of a wallet. The passwords are encrypted
+// with keystorev4, the same crypto as EIP-2335 keystores, as a JSON object of the passwords by the
+// name of the password file they replace.
+type passwordStore struct {
+	Version int                    `json:"version"`
+	Crypto  map[string]interface{} `json:"crypto"`
+}
+
+// EncryptAccountPasswords moves the plaintext password files of the accounts of a non-HD wallet
+// into its password store, encrypted with the wallet password, which is entered once to open the
+// wallet rather than being readable by anyone with access to the passwords directory. Passwords of
+// accounts created or imported after are written to the password store too. Wallets without a
+// wallet password are given one.
+func EncryptAccountPasswords(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	wallet, err := openDirectWallet(cliCtx)
+	if err != nil {
+		return err
+	}
+	if wallet.passwordStore {
+		return errors.New("account passwords of the wallet are already encrypted")
+	}
+	if wallet.walletPassword == "" {
+		wallet.walletPassword, err = inputPassword(cliCtx, flags.WalletPasswordFileFlag, newWalletPasswordPromptText, confirmPass)
+		if err != nil {
+			return errors.Wrap(err, "could not get password")
+		}
+	}
+	lock, err := wallet.Lock()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := lock.Unlock(); err != nil {
+			log.WithError(err).Error("Could not unlock wallet")
+		}
+	}()
+	encrypted, err := wallet.encryptAccountPasswords(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf(
+		"Encrypted the passwords of %s accounts into %s\n",
+		au.BrightGreen(encrypted),
+		au.BrightGreen(wallet.passwordStorePath()),
+	)
+	return nil
+}
+
+// Moves the password files of the passwords directory of the wallet into its password store, and
+// removes them once the password store holds them, returning how many were moved.
+func (w *Wallet) encryptAccountPasswords(ctx context.Context) (int, error) {
+	if err := w.checkWritable(); err != nil {
+		return 0, err
+	}
+	if w.passwordsDir == "" {
+		return 0, errors.New("wallet has no passwords directory")
+	}
+	passwordFiles, err := filepath.Glob(filepath.Join(w.passwordsDir, "*"+direct.PasswordFileSuffix))
+	if err != nil {
+		return 0, err
+	}
+	passwords := make(map[string]string, len(passwordFiles))
+	for _, passwordFile := range passwordFiles {
+		password, err := ioutil.ReadFile(passwordFile)
+		if err != nil {
+			return 0, errors.Wrapf(err, "could not read %s", passwordFile)
+		}
+		passwords[filepath.Base(passwordFile)] = string(password)
+	}
+	if err := os.MkdirAll(w.passwordsDir, DirectoryPermissions); err != nil {
+		return 0, errors.Wrap(err, "could not create passwords directory")
+	}
+	if err := w.writePasswordStore(passwords, w.walletPassword); err != nil {
+		return 0, err
+	}
+	// Password files are only removed once the password store is known to decrypt to them.
+	stored, err := w.readPasswordStore()
+	if err != nil {
+		return 0, err
+	}
+	for name, password := range passwords {
+		if stored[name] != password {
+			return 0, fmt.Errorf("password store does not hold the password of %s", name)
+		}
+	}
+	for _, passwordFile := range passwordFiles {
+		if err := os.Remove(passwordFile); err != nil {
+			return 0, errors.Wrapf(err, "could not remove %s", passwordFile)
+		}
+	}
+	w.passwords = stored
+	w.passwordStore = true
+	if err := w.recordMutation(ctx, &walletJournalEntry{
+		Action:  journalActionPasswordsEncrypted,
+		Deleted: passwordFiles,
+	}); err != nil {
+		return 0, err
+	}
+	return len(passwords), nil
+}
+
+// Returns the path of the password store of the wallet.
+func (w *Wallet) passwordStorePath() string {
+	return filepath.Join(w.passwordsDir, passwordStoreFileName)
+}
+
+// Reads and decrypts the password store of the wallet with the wallet password.
+func (w *Wallet) readPasswordStore() (map[string]string, error) {
+	encoded, err := ioutil.ReadFile(w.passwordStorePath())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read password store")
+	}
+	return decryptPasswordStore(encoded, w.walletPassword)
+}
+
+// Encrypts the passwords with a wallet password and writes them to the password store of the
+// wallet, replacing it atomically.
+func (w *Wallet) writePasswordStore(passwords map[string]string, walletPassword string) error {
+	encoded, err := encryptPasswordStore(passwords, walletPassword)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(w.passwordStorePath(), encoded, FilePermissions); err != nil {
+		return errors.Wrapf(err, "could not write %s", w.passwordStorePath())
+	}
+	return nil
+}
+
+func encryptPasswordStore(passwords map[string]string, walletPassword string) ([]byte, error) {
+	decrypted, err := json.Marshal(passwords)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encode account passwords")
+	}
+	cryptoFields, err := keystorev4.New().Encrypt(decrypted, walletPassword)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encrypt account passwords")
+	}
+	encoded, err := json.MarshalIndent(&passwordStore{
+		Version: passwordStoreVersion,
+		Crypto:  cryptoFields,
+	}, "", "\t")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encode password store")
+	}
+	return encoded, nil
+}
+
+func decryptPasswordStore(encoded []byte, walletPassword string) (map[string]string, error) {
+	store := &passwordStore{}
+	if err := json.Unmarshal(encoded, store); err != nil {
+		return nil, errors.Wrap(err, "could not decode password store")
+	}
+	if store.Version != passwordStoreVersion {
+		return nil, fmt.Errorf("unsupported password store version %d", store.Version)
+	}
+	decrypted, err := keystorev4.New().Decrypt(store.Crypto, walletPassword)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decrypt password store, wrong wallet password")
+	}
+	passwords := make(map[string]string)
+	if err := json.Unmarshal(decrypted, &passwords); err != nil {
+		return nil, errors.Wrap(err, "could not decode account passwords")
+	}
+	return passwords, nil
+}
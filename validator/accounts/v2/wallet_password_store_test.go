@@ -0,0 +1,64 @@
+package v2
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestEncryptAccountPasswords(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		walletPasswordFile:  passwordFile,
+		accountPasswordFile: passwordFile,
+		keymanagerKind:      v2keymanager.Direct,
+	}
+	_, err := CreateWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	require.NoError(t, CreateAccount(setupWalletCtx(t, cfg)))
+	require.NoError(t, CreateAccount(setupWalletCtx(t, cfg)))
+	want := walletPubKeys(t, cfg)
+	require.Equal(t, 2, len(want))
+	passwordFiles := func() []string {
+		files, err := filepath.Glob(filepath.Join(passwordsDir, "*"+direct.PasswordFileSuffix))
+		require.NoError(t, err)
+		return files
+	}
+	require.Equal(t, 2, len(passwordFiles()))
+
+	require.NoError(t, EncryptAccountPasswords(setupWalletCtx(t, cfg)))
+	assert.Equal(t, 0, len(passwordFiles()))
+	assert.Equal(t, true, fileExists(filepath.Join(passwordsDir, passwordStoreFileName)))
+	assert.ErrorContains(t, "already encrypted", EncryptAccountPasswords(setupWalletCtx(t, cfg)))
+	assert.DeepEqual(t, want, walletPubKeys(t, cfg))
+
+	// Accounts created afterwards have their passwords written to the password store.
+	require.NoError(t, CreateAccount(setupWalletCtx(t, cfg)))
+	assert.Equal(t, 0, len(passwordFiles()))
+	assert.Equal(t, 3, len(walletPubKeys(t, cfg)))
+
+	// The password store is re-encrypted with a new wallet password.
+	newPasswordFile := filepath.Join(filepath.Dir(passwordFile), "new-wallet-password.txt")
+	require.NoError(t, ioutil.WriteFile(newPasswordFile, []byte("Sh1nyN3wWall3t!"), os.ModePerm))
+	cfg.newWalletPassword = newPasswordFile
+	require.NoError(t, ChangeWalletPassword(setupWalletCtx(t, cfg)))
+	_, err = OpenWallet(setupWalletCtx(t, cfg))
+	assert.ErrorContains(t, "wrong wallet password", err)
+	cfg.walletPasswordFile = newPasswordFile
+	wallet, err := OpenWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	keymanager, err := direct.NewKeymanager(context.Background(), wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, len(pubKeys))
+}
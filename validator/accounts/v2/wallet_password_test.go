@@ -0,0 +1,114 @@
+package v2
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/derived"
+)
+
+func TestChangeWalletPassword(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	newPasswordFilePath := filepath.Join(filepath.Dir(passwordFilePath), "new-wallet-password.txt")
+	require.NoError(t, ioutil.WriteFile(newPasswordFilePath, []byte("Sh1nyN3wWall3t!"), os.ModePerm))
+	mnemonicFilePath := filepath.Join(filepath.Dir(passwordFilePath), mnemonicFileName)
+	require.NoError(t, ioutil.WriteFile(mnemonicFilePath, []byte(mnemonic), os.ModePerm))
+	cfg := &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFilePath,
+		mnemonicFile:       mnemonicFilePath,
+		numAccounts:        2,
+		keymanagerKind:     v2keymanager.Derived,
+	}
+	require.NoError(t, RecoverWallet(setupWalletCtx(t, cfg)))
+	ctx := context.Background()
+
+	// Encrypt the keymanager config too, so both the seed and the config are re-encrypted.
+	wallet, err := OpenWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	configFile, err := wallet.ReadKeymanagerConfigFromDisk(ctx)
+	require.NoError(t, err)
+	keymanagerCfg, err := derived.UnmarshalConfigFile(configFile, "" /* password */)
+	require.NoError(t, err)
+	encodedCfg, err := derived.MarshalConfigFile(ctx, keymanagerCfg)
+	require.NoError(t, err)
+	wallet.encryptedConfig = true
+	require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+	// Public keys of the wallet opened with the password of a file, which the keymanager returns
+	// in no particular order.
+	openKeymanager := func(passwordFile string) (map[[48]byte]bool, error) {
+		walletCfg := *cfg
+		walletCfg.walletPasswordFile = passwordFile
+		wallet, err := OpenWallet(setupWalletCtx(t, &walletCfg))
+		if err != nil {
+			return nil, err
+		}
+		keymanager, err := wallet.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+		if err != nil {
+			return nil, err
+		}
+		validatingKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+		if err != nil {
+			return nil, err
+		}
+		pubKeys := make(map[[48]byte]bool, len(validatingKeys))
+		for _, pubKey := range validatingKeys {
+			pubKeys[pubKey] = true
+		}
+		return pubKeys, nil
+	}
+	pubKeys, err := openKeymanager(passwordFilePath)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(pubKeys))
+
+	// Failing to replace the keymanager config restores the already replaced seed.
+	cfg.newWalletPassword = newPasswordFilePath
+	blocker := filepath.Join(wallet.AccountsDir(), KeymanagerConfigFileName+reencryptedFileSuffix)
+	require.NoError(t, os.MkdirAll(blocker, os.ModePerm))
+	assert.ErrorContains(t, "could not replace", ChangeWalletPassword(setupWalletCtx(t, cfg)))
+	require.NoError(t, os.RemoveAll(blocker))
+	restored, err := openKeymanager(passwordFilePath)
+	require.NoError(t, err)
+	assert.DeepEqual(t, pubKeys, restored)
+
+	require.NoError(t, ChangeWalletPassword(setupWalletCtx(t, cfg)))
+	reencrypted, err := openKeymanager(newPasswordFilePath)
+	require.NoError(t, err)
+	assert.DeepEqual(t, pubKeys, reencrypted)
+	_, err = openKeymanager(passwordFilePath)
+	assert.ErrorContains(t, "could not decrypt", err)
+}
+
+func TestWallet_LockAndUnlockPassword(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	mnemonicFilePath := filepath.Join(filepath.Dir(passwordFilePath), mnemonicFileName)
+	require.NoError(t, ioutil.WriteFile(mnemonicFilePath, []byte(mnemonic), os.ModePerm))
+	cfg := &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFilePath,
+		mnemonicFile:       mnemonicFilePath,
+		numAccounts:        1,
+		keymanagerKind:     v2keymanager.Derived,
+	}
+	require.NoError(t, RecoverWallet(setupWalletCtx(t, cfg)))
+	wallet, err := OpenWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	wallet.LockPassword()
+	assert.Equal(t, "", wallet.Password())
+	// The password is verified by decrypting the seed of the wallet, not by comparing it with the
+	// password dropped when locking.
+	assert.ErrorContains(t, "could not decrypt", wallet.UnlockPassword(ctx, "Wr0ngPassw0rd!"))
+	assert.Equal(t, "", wallet.Password())
+	require.NoError(t, wallet.UnlockPassword(ctx, password))
+	assert.Equal(t, password, wallet.Password())
+}
@@ -0,0 +1,102 @@
+package v2
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// walletPermissionsMask are the permission bits no file or directory of a wallet may have, those
+// granting any access to users other than the owner.
+const walletPermissionsMask = 0077
+
+// permissionsToFix is a file or directory of a wallet accessible by other users.
+type permissionsToFix struct {
+	path  string
+	mode  os.FileMode
+	isDir bool
+}
+
+// CheckPermissions verifies that the directories of the wallet, its keystores and its account
+// passwords are accessible by their owner only, as with permissions 0700 and 0600, failing
+// otherwise. If fix is true, their permissions are set to 0700 and 0600 instead. Permissions are
+// not checked on windows, which has no such permissions.
+func (w *Wallet) CheckPermissions(fix bool) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	dirs := []string{w.walletDir}
+	if w.passwordsDir != "" && !isWithinDir(w.passwordsDir, w.walletDir) {
+		dirs = append(dirs, w.passwordsDir)
+	}
+	toFix := make([]*permissionsToFix, 0)
+	for _, dir := range dirs {
+		found, err := walletPermissionsToFix(dir)
+		if err != nil {
+			return err
+		}
+		toFix = append(toFix, found...)
+	}
+	if len(toFix) == 0 {
+		return nil
+	}
+	if !fix {
+		paths := make([]string, 0, len(toFix))
+		for _, f := range toFix {
+			paths = append(paths, fmt.Sprintf("%s (%#o)", f.path, f.mode.Perm()))
+		}
+		return fmt.Errorf(
+			"%d files and directories of the wallet are accessible by other users, restrict them to their "+
+				"owner with permissions 0600 and 0700, or restart with --wallet-fix-permissions: %s",
+			len(toFix),
+			strings.Join(paths, ", "),
+		)
+	}
+	if err := w.checkWritable(); err != nil {
+		return err
+	}
+	for _, f := range toFix {
+		perm := os.FileMode(FilePermissions)
+		if f.isDir {
+			perm = DirectoryPermissions
+		}
+		if err := os.Chmod(f.path, perm); err != nil {
+			return errors.Wrapf(err, "could not set permissions of %s", f.path)
+		}
+		log.WithField("path", f.path).Warnf("Restricted permissions from %#o to %#o", f.mode.Perm(), perm)
+	}
+	return nil
+}
+
+// Returns the files and directories of a directory, including itself, accessible by other users.
+// Symbolic links are not followed, their own permissions are never used.
+func walletPermissionsToFix(dir string) ([]*permissionsToFix, error) {
+	toFix := make([]*permissionsToFix, 0)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 || info.Mode().Perm()&walletPermissionsMask == 0 {
+			return nil
+		}
+		toFix = append(toFix, &permissionsToFix{path: path, mode: info.Mode(), isDir: info.IsDir()})
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not check permissions of %s", dir)
+	}
+	return toFix, nil
+}
+
+// Returns true if a path is the given directory or within it.
+func isWithinDir(path string, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
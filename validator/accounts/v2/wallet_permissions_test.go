@@ -0,0 +1,71 @@
+package v2
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+)
+
+func TestWallet_CheckPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Permissions are not checked on windows")
+	}
+	walletDir, passwordsDir, _ := setupWalletAndPasswordsDir(t)
+	accountsPath := filepath.Join(walletDir, v2keymanager.Direct.String())
+	w := &Wallet{
+		walletDir:      walletDir,
+		accountsPath:   accountsPath,
+		passwordsDir:   passwordsDir,
+		keymanagerKind: v2keymanager.Direct,
+	}
+	keystorePath := filepath.Join(accountsPath, "account", "keystore-0.json")
+	passwordPath := filepath.Join(passwordsDir, "account.pass")
+	require.NoError(t, os.MkdirAll(filepath.Dir(keystorePath), DirectoryPermissions))
+	require.NoError(t, os.MkdirAll(passwordsDir, DirectoryPermissions))
+	require.NoError(t, os.Chmod(walletDir, DirectoryPermissions))
+	require.NoError(t, ioutil.WriteFile(keystorePath, []byte("{}"), FilePermissions))
+	require.NoError(t, ioutil.WriteFile(passwordPath, []byte(password), FilePermissions))
+	require.NoError(t, w.CheckPermissions(false /* fix */))
+
+	require.NoError(t, os.Chmod(keystorePath, 0644))
+	require.NoError(t, os.Chmod(passwordPath, 0640))
+	require.NoError(t, os.Chmod(filepath.Dir(keystorePath), 0755))
+	err := w.CheckPermissions(false /* fix */)
+	assert.ErrorContains(t, "3 files and directories of the wallet are accessible by other users", err)
+	assert.ErrorContains(t, keystorePath, err)
+	assert.ErrorContains(t, passwordPath, err)
+
+	w.readOnly = true
+	assert.ErrorContains(t, ErrWalletReadOnly.Error(), w.CheckPermissions(true /* fix */))
+	w.readOnly = false
+	require.NoError(t, w.CheckPermissions(true /* fix */))
+	require.NoError(t, w.CheckPermissions(false /* fix */))
+	info, err := os.Stat(keystorePath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(FilePermissions), info.Mode().Perm())
+	info, err = os.Stat(filepath.Dir(keystorePath))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(DirectoryPermissions), info.Mode().Perm())
+}
+
+func TestDiskStorage_WritesWithOwnerOnlyPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Permissions are not checked on windows")
+	}
+	walletDir, _, _ := setupWalletAndPasswordsDir(t)
+	storage := &diskStorage{root: walletDir}
+	require.NoError(t, storage.writeFile(context.Background(), "account/keystore-0.json", []byte("{}")))
+	info, err := os.Stat(filepath.Join(walletDir, "account", "keystore-0.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0), info.Mode().Perm()&walletPermissionsMask)
+	info, err = os.Stat(filepath.Join(walletDir, "account"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0), info.Mode().Perm()&walletPermissionsMask)
+}
@@ -0,0 +1,69 @@
+package v2
+
+import (
+	"context"
+)
+
+// readOnlyStorage fails every write to the storage of a read-only wallet, before anything is
+// written, while reading from it as usual.
+type readOnlyStorage struct {
+	storage walletStorage
+}
+
+// Wraps storage to fail its writes, keeping it transactional if it is.
+func withReadOnly(storage walletStorage) walletStorage {
+	readOnly := &readOnlyStorage{storage: storage}
+	if _, ok := storage.(transactionalStorage); ok {
+		return &readOnlyTransactionalStorage{readOnly}
+	}
+	return readOnly
+}
+
+type readOnlyTransactionalStorage struct {
+	*readOnlyStorage
+}
+
+func (s *readOnlyStorage) readFile(ctx context.Context, name string) ([]byte, error) {
+	return s.storage.readFile(ctx, name)
+}
+
+func (s *readOnlyStorage) writeFile(ctx context.Context, name string, data []byte) error {
+	return ErrWalletReadOnly
+}
+
+func (s *readOnlyStorage) removeFile(ctx context.Context, name string) error {
+	return ErrWalletReadOnly
+}
+
+func (s *readOnlyStorage) glob(ctx context.Context, dir string, pattern string) ([]string, error) {
+	return s.storage.glob(ctx, dir, pattern)
+}
+
+func (s *readOnlyStorage) listDirs(ctx context.Context) ([]string, error) {
+	return s.storage.listDirs(ctx)
+}
+
+func (s *readOnlyTransactionalStorage) writeFiles(ctx context.Context, files map[string][]byte) error {
+	return ErrWalletReadOnly
+}
+
+// SetReadOnly makes every operation writing to the wallet, its account passwords or its journal
+// fail with ErrWalletReadOnly before anything is written, such as for a monitoring or standby
+// validator client which must never change the keys of the wallet. Wallets opened read-only are
+// not migrated to the current wallet layout either.
+func (w *Wallet) SetReadOnly() {
+	w.readOnly = true
+}
+
+// ReadOnly returns true if the wallet was opened or set read-only.
+func (w *Wallet) ReadOnly() bool {
+	return w.readOnly
+}
+
+// Returns ErrWalletReadOnly if the wallet is read-only, to check before writing to it.
+func (w *Wallet) checkWritable() error {
+	if w.readOnly {
+		return ErrWalletReadOnly
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package v2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestWallet_ReadOnly(t *testing.T) {
+	walletDir, passwordsDir, _ := setupWalletAndPasswordsDir(t)
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:      walletDir,
+		passwordsDir:   passwordsDir,
+		keymanagerKind: v2keymanager.Direct,
+	})
+	wallet, err := NewWallet(cliCtx, v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	name, err := keymanager.CreateAccount(ctx, password)
+	require.NoError(t, err)
+	journal, err := wallet.readWalletJournal(ctx)
+	require.NoError(t, err)
+
+	wallet, err = OpenWalletAtPath(walletDir, "", true /* readOnly */)
+	require.NoError(t, err)
+	assert.Equal(t, true, wallet.ReadOnly())
+	keystore, err := wallet.ReadFileAtPath(ctx, name, direct.KeystoreFileName)
+	require.NoError(t, err)
+	keystoreFileName, err := wallet.FileNameAtPath(ctx, name, direct.KeystoreFileName)
+	require.NoError(t, err)
+
+	err = wallet.WriteFileAtPath(ctx, name, keystoreFileName, []byte("{}"))
+	assert.ErrorContains(t, ErrWalletReadOnly.Error(), err)
+	err = wallet.WriteFilesAtPath(ctx, name, map[string][]byte{keystoreFileName: []byte("{}")})
+	assert.ErrorContains(t, ErrWalletReadOnly.Error(), err)
+	err = wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg)
+	assert.ErrorContains(t, ErrWalletReadOnly.Error(), err)
+	err = wallet.WritePasswordToDisk(ctx, name+direct.PasswordFileSuffix, "new-password")
+	assert.ErrorContains(t, ErrWalletReadOnly.Error(), err)
+	err = wallet.DeleteAccountFiles(ctx, name, name+direct.PasswordFileSuffix)
+	assert.ErrorContains(t, ErrWalletReadOnly.Error(), err)
+	err = wallet.renameAccount(name, "renamed")
+	assert.ErrorContains(t, ErrWalletReadOnly.Error(), err)
+	assert.ErrorContains(t, ErrWalletReadOnly.Error(), wallet.SaveWallet())
+
+	// Nothing of the wallet was changed.
+	unchanged, err := wallet.ReadFileAtPath(ctx, name, direct.KeystoreFileName)
+	require.NoError(t, err)
+	assert.DeepEqual(t, keystore, unchanged)
+	accountPassword, err := wallet.ReadPasswordFromDisk(ctx, name+direct.PasswordFileSuffix)
+	require.NoError(t, err)
+	assert.NotEqual(t, "new-password", accountPassword)
+	unchangedJournal, err := wallet.readWalletJournal(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, len(journal), len(unchangedJournal))
+}
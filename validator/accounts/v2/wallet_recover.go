@@ -58,6 +58,19 @@ func RecoverWallet(cliCtx *cli.Context) error {
 		return errors.New("not a derived keymanager")
 	}
 
+	if cliCtx.IsSet(flags.AccountIndicesFlag.Name) {
+		indices, err := parseAccountIndices(cliCtx.String(flags.AccountIndicesFlag.Name))
+		if err != nil {
+			return err
+		}
+		if err := createDerivedAccountsAtIndices(cliCtx, km, indices); err != nil {
+			return err
+		}
+		log.WithField("wallet-path", wallet.AccountsDir()).Infof(
+			"Successfully recovered HD wallet with the accounts of %d indices", len(indices),
+		)
+		return nil
+	}
 	numAccounts, err := inputNumAccounts(cliCtx)
 	if err != nil {
 		return errors.Wrap(err, "could not get number of accounts to recover")
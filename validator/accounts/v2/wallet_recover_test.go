@@ -59,7 +59,7 @@ func TestRecoverDerivedWallet(t *testing.T) {
 
 	encoded, err := wallet.ReadKeymanagerConfigFromDisk(ctx)
 	assert.NoError(t, err)
-	cfg, err := derived.UnmarshalConfigFile(encoded)
+	cfg, err := derived.UnmarshalConfigFile(encoded, "" /* password */)
 	assert.NoError(t, err)
 
 	// We assert the created configuration was as desired.
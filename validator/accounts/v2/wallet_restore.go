@@ -0,0 +1,473 @@
+package v2
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/validator/db/kv"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/prysmaticlabs/prysm/validator/slashing-protection/interchange"
+	"github.com/urfave/cli/v2"
+	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+)
+
+// walletRestore holds the decrypted archive of a wallet backup, indexed by entry name.
+type walletRestore struct {
+	manifest *walletBackupManifest
+	kind     v2keymanager.Kind
+	entries  map[string]*zip.File
+}
+
+// RestoreWallet decrypts a backup written by wallet-v2 backup and restores its wallet, account
+// passwords and validator database. With --merge, the accounts of a non-HD backup are added to
+// the existing non-HD wallet instead, which fails if the wallet already holds any of their
+// public keys unless --force is given to replace those accounts, moving them to the trash.
+func RestoreWallet(cliCtx *cli.Context) error {
+	if cliCtx.String(flags.BackupFileFlag.Name) == "" {
+		return fmt.Errorf("--%s is required to restore a wallet", flags.BackupFileFlag.Name)
+	}
+	backupPath, err := expandPath(cliCtx.String(flags.BackupFileFlag.Name))
+	if err != nil {
+		return errors.Wrap(err, "could not parse backup file path")
+	}
+	backupPassword, err := inputPassword(cliCtx, flags.BackupPasswordFileFlag, backupPasswordPromptText, noConfirmPass)
+	if err != nil {
+		return errors.Wrap(err, "could not input backup password")
+	}
+	restore, err := readWalletBackup(backupPath, backupPassword)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	switch {
+	case errors.Is(err, ErrNoWalletFound):
+		return restore.restoreWallet(ctx, cliCtx)
+	case err != nil:
+		return errors.Wrap(err, "could not open wallet")
+	case !cliCtx.Bool(flags.MergeRestoreFlag.Name):
+		return fmt.Errorf(
+			"a wallet already exists at %s, pass --%s to merge the backup into it",
+			wallet.walletDir,
+			flags.MergeRestoreFlag.Name,
+		)
+	}
+	return restore.mergeIntoWallet(ctx, cliCtx, wallet)
+}
+
+// Decrypts the backup file and checks its archive holds everything its manifest describes.
+func readWalletBackup(backupPath string, backupPassword string) (*walletRestore, error) {
+	encoded, err := ioutil.ReadFile(backupPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read backup file")
+	}
+	backup := &walletBackup{}
+	if err := json.Unmarshal(encoded, backup); err != nil {
+		return nil, errors.Wrap(err, "could not decode backup file")
+	}
+	if backup.Version != walletBackupVersion {
+		return nil, fmt.Errorf("unsupported wallet backup version %d", backup.Version)
+	}
+	if backup.Crypto == nil {
+		return nil, errors.New("backup file has no crypto section")
+	}
+	decrypted, err := keystorev4.New().Decrypt(backup.Crypto, backupPassword)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decrypt backup, wrong backup password")
+	}
+	return readBackupArchive(decrypted)
+}
+
+// Reads the archive of a wallet backup or snapshot and checks it holds everything its manifest
+// describes.
+func readBackupArchive(encoded []byte) (*walletRestore, error) {
+	archive, err := zip.NewReader(bytes.NewReader(encoded), int64(len(encoded)))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open backup archive")
+	}
+	restore := &walletRestore{
+		entries: make(map[string]*zip.File, len(archive.File)),
+	}
+	for _, f := range archive.File {
+		if !isSafeArchivePath(f.Name) {
+			return nil, fmt.Errorf("backup archive entry %q is outside of the archive", f.Name)
+		}
+		restore.entries[f.Name] = f
+	}
+	manifestEntry, ok := restore.entries[walletBackupManifestName]
+	if !ok {
+		return nil, errors.New("backup archive has no manifest")
+	}
+	encodedManifest, err := readZipEntry(manifestEntry)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read backup manifest")
+	}
+	restore.manifest = &walletBackupManifest{}
+	if err := json.Unmarshal(encodedManifest, restore.manifest); err != nil {
+		return nil, errors.Wrap(err, "could not decode backup manifest")
+	}
+	if err := restore.validateManifest(); err != nil {
+		return nil, errors.Wrap(err, "invalid backup manifest")
+	}
+	return restore, nil
+}
+
+func (r *walletRestore) validateManifest() error {
+	kind, err := v2keymanager.ParseKind(r.manifest.KeymanagerKind)
+	if err != nil {
+		return err
+	}
+	r.kind = kind
+	configEntry := path.Join(walletBackupWalletDir, kind.String(), KeymanagerConfigFileName)
+	if _, ok := r.entries[configEntry]; !ok {
+		return fmt.Errorf("backup of a %s wallet has no %s", kind, configEntry)
+	}
+	if _, ok := r.entries[walletBackupValidatorDBName]; ok != r.manifest.HasValidatorDB {
+		return errors.New("manifest does not match whether the backup holds a validator database")
+	}
+	hasPasswords := len(r.entriesUnder(walletBackupPasswordsDir)) > 0
+	if r.manifest.HasPasswords && !hasPasswords {
+		return errors.New("manifest lists account passwords the backup does not hold")
+	}
+	if kind == v2keymanager.Direct && !r.manifest.HasPasswords {
+		return errors.New("backup of a non-HD wallet holds no account passwords")
+	}
+	return nil
+}
+
+// Restores the backup into a wallet directory which has no wallet yet.
+func (r *walletRestore) restoreWallet(ctx context.Context, cliCtx *cli.Context) error {
+	walletDir, err := inputDirectory(cliCtx, walletDirPromptText, flags.WalletDirFlag)
+	if err != nil {
+		return errors.Wrap(err, "could not parse wallet directory")
+	}
+	if err := r.extract(walletBackupWalletDir, walletDir); err != nil {
+		return errors.Wrap(err, "could not restore wallet directory")
+	}
+	if r.manifest.HasPasswords {
+		passwordsDir := r.manifest.PasswordsDirectory
+		if dir := cliCtx.String(flags.WalletPasswordsDirFlag.Name); dir != "" {
+			passwordsDir, err = canonicalPath(dir)
+			if err != nil {
+				return errors.Wrap(err, "could not parse passwords directory")
+			}
+		}
+		if passwordsDir == "" {
+			return fmt.Errorf("--%s is required to restore the account passwords", flags.WalletPasswordsDirFlag.Name)
+		}
+		if passwordsDir != r.manifest.PasswordsDirectory {
+			log.Warnf("The wallet configuration still points to the backed up passwords directory %s, "+
+				"use wallet-v2 edit-config to point it to %s",
+				r.manifest.PasswordsDirectory,
+				passwordsDir,
+			)
+		}
+		if err := r.extract(walletBackupPasswordsDir, passwordsDir); err != nil {
+			return errors.Wrap(err, "could not restore account passwords")
+		}
+	}
+	if err := r.restoreValidatorDB(ctx, validatorDataDir(cliCtx), nil /* all public keys */); err != nil {
+		return err
+	}
+	log.WithField("walletDir", walletDir).Info("Successfully restored wallet from backup")
+	return nil
+}
+
+// Adds the accounts of a non-HD backup to an existing non-HD wallet, along with their account
+// passwords and slashing protection history.
+func (r *walletRestore) mergeIntoWallet(ctx context.Context, cliCtx *cli.Context, wallet *Wallet) error {
+	if r.kind != v2keymanager.Direct || wallet.KeymanagerKind() != v2keymanager.Direct {
+		return fmt.Errorf(
+			"only a non-HD backup can be merged into a non-HD wallet, cannot merge a %s backup into a %s wallet",
+			r.kind,
+			wallet.KeymanagerKind(),
+		)
+	}
+	backupAccounts, err := r.accountPubKeys(wallet.keystoreFileGlob())
+	if err != nil {
+		return err
+	}
+	walletAccounts, err := wallet.accountPubKeys(ctx)
+	if err != nil {
+		return err
+	}
+	accountByPubKey := make(map[[48]byte]string, len(walletAccounts))
+	for name, pubKey := range walletAccounts {
+		accountByPubKey[pubKey] = name
+	}
+	force := cliCtx.Bool(flags.ForceRestoreFlag.Name)
+	pubKeys := make([][48]byte, 0, len(backupAccounts))
+	replaced := make([]string, 0)
+	for name, pubKey := range backupAccounts {
+		pubKeys = append(pubKeys, pubKey)
+		if existing, ok := accountByPubKey[pubKey]; ok {
+			if !force {
+				return fmt.Errorf(
+					"wallet account %s already holds public key %#x of the backup, pass --%s to replace it",
+					existing,
+					bytesutil.Trunc(pubKey[:]),
+					flags.ForceRestoreFlag.Name,
+				)
+			}
+			replaced = append(replaced, existing)
+			continue
+		}
+		if _, ok := walletAccounts[name]; ok {
+			return fmt.Errorf("wallet account %s holds a different public key than the backup account of that name", name)
+		}
+	}
+
+	for name := range backupAccounts {
+		if _, ok := r.entries[path.Join(walletBackupPasswordsDir, name+direct.PasswordFileSuffix)]; !ok {
+			return fmt.Errorf("backup holds no password for account %s", name)
+		}
+	}
+
+	// The backup accounts are extracted into a hidden directory of the wallet first, so the
+	// accounts they replace are only removed once the backup accounts are fully written.
+	stagingDir, err := ioutil.TempDir(wallet.AccountsDir(), ".restore-")
+	if err != nil {
+		return errors.Wrap(err, "could not create staging directory")
+	}
+	defer func() {
+		if err := os.RemoveAll(stagingDir); err != nil {
+			log.WithError(err).Error("Could not remove staging directory")
+		}
+	}()
+	for name := range backupAccounts {
+		accountDir := path.Join(walletBackupWalletDir, v2keymanager.Direct.String(), name)
+		if err := r.extract(accountDir, filepath.Join(stagingDir, name)); err != nil {
+			return errors.Wrapf(err, "could not restore account %s", name)
+		}
+	}
+	for _, name := range replaced {
+		if err := wallet.DeleteAccountFiles(ctx, name, name+direct.PasswordFileSuffix); err != nil {
+			return errors.Wrapf(err, "could not replace account %s", name)
+		}
+		log.WithField("account", name).Warn(
+			"Replaced wallet account with the backup account holding the same public key, the previous one is kept in the trash",
+		)
+	}
+	for name := range backupAccounts {
+		if err := os.Rename(filepath.Join(stagingDir, name), filepath.Join(wallet.AccountsDir(), name)); err != nil {
+			return errors.Wrapf(err, "could not restore account %s", name)
+		}
+		passwordEntry := path.Join(walletBackupPasswordsDir, name+direct.PasswordFileSuffix)
+		if err := r.extractFile(passwordEntry, filepath.Join(wallet.passwordsDir, name+direct.PasswordFileSuffix)); err != nil {
+			return errors.Wrapf(err, "could not restore password of account %s", name)
+		}
+	}
+	if err := r.restoreValidatorDB(ctx, validatorDataDir(cliCtx), pubKeys); err != nil {
+		return err
+	}
+	log.WithField("walletDir", wallet.walletDir).Infof("Successfully merged %d accounts from backup", len(backupAccounts))
+	return nil
+}
+
+// Restores the validator database of the backup into dataDir. If dataDir already holds a
+// database, the slashing protection history of the given public keys, or of every key if none
+// are given, is merged into it so none of the history already there is lost.
+func (r *walletRestore) restoreValidatorDB(ctx context.Context, dataDir string, pubKeys [][48]byte) error {
+	entry, ok := r.entries[walletBackupValidatorDBName]
+	if !ok {
+		return nil
+	}
+	store, err := kv.GetKVStore(dataDir)
+	if err != nil {
+		return errors.Wrap(err, "could not open validator database, make sure the validator is stopped")
+	}
+	if store == nil {
+		// The backup holds the database file as is, named as the validator expects it.
+		if err := r.extractFile(entry.Name, filepath.Join(dataDir, walletBackupValidatorDBName)); err != nil {
+			return errors.Wrap(err, "could not restore validator database")
+		}
+		log.WithField("datadir", dataDir).Info("Restored validator database")
+		return nil
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			log.WithError(err).Error("Could not close validator database")
+		}
+	}()
+
+	tmpDir, err := ioutil.TempDir("", "prysm-restore")
+	if err != nil {
+		return errors.Wrap(err, "could not create temporary directory")
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			log.WithError(err).Error("Could not remove temporary directory")
+		}
+	}()
+	if err := r.extractFile(entry.Name, filepath.Join(tmpDir, walletBackupValidatorDBName)); err != nil {
+		return errors.Wrap(err, "could not extract validator database")
+	}
+	backupStore, err := kv.GetKVStore(tmpDir)
+	if err != nil {
+		return errors.Wrap(err, "could not open backed up validator database")
+	}
+	defer func() {
+		if err := backupStore.Close(); err != nil {
+			log.WithError(err).Error("Could not close backed up validator database")
+		}
+	}()
+	if pubKeys == nil {
+		pubKeys, err = backupStore.PubKeys()
+		if err != nil {
+			return errors.Wrap(err, "could not read public keys of backed up validator database")
+		}
+	}
+	// The interchange document never leaves this process, so it needs no genesis validators root.
+	doc, err := interchange.ExportHistory(ctx, backupStore, [32]byte{}, pubKeys)
+	if err != nil {
+		return errors.Wrap(err, "could not read backed up slashing protection history")
+	}
+	if _, err := interchange.ImportHistory(ctx, store, doc, pubKeys); err != nil {
+		return errors.Wrap(err, "could not merge slashing protection history")
+	}
+	log.WithField("datadir", dataDir).Infof("Merged slashing protection history of %d public keys", len(pubKeys))
+	return nil
+}
+
+// Reads the public key of every account of a non-HD backup from its keystores matching a glob
+// pattern, by account name.
+func (r *walletRestore) accountPubKeys(keystoreFileGlob string) (map[string][48]byte, error) {
+	accountsDir := path.Join(walletBackupWalletDir, v2keymanager.Direct.String())
+	pubKeys := make(map[string][48]byte)
+	for name, entry := range r.entries {
+		if path.Dir(path.Dir(name)) != accountsDir {
+			continue
+		}
+		if ok, err := path.Match(keystoreFileGlob, path.Base(name)); err != nil || !ok {
+			continue
+		}
+		encoded, err := readZipEntry(entry)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read %s", name)
+		}
+		pubKey, err := keystorePubKey(encoded)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read public key of %s", name)
+		}
+		pubKeys[path.Base(path.Dir(name))] = pubKey
+	}
+	return pubKeys, nil
+}
+
+// Reads the public key of every account of a non-HD wallet, by account name, without
+// decrypting their keystores.
+func (w *Wallet) accountPubKeys(ctx context.Context) (map[string][48]byte, error) {
+	if err := w.checkNotCompacted(); err != nil {
+		return nil, err
+	}
+	names, err := w.ListDirs()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list accounts")
+	}
+	pubKeys := make(map[string][48]byte, len(names))
+	for _, name := range names {
+		encoded, err := w.ReadFileAtPath(ctx, name, w.keystoreFileGlob())
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read keystore of account %s", name)
+		}
+		pubKey, err := keystorePubKey(encoded)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read public key of account %s", name)
+		}
+		pubKeys[name] = pubKey
+	}
+	return pubKeys, nil
+}
+
+func keystorePubKey(encoded []byte) ([48]byte, error) {
+	keystore := &v2keymanager.Keystore{}
+	if err := json.Unmarshal(encoded, keystore); err != nil {
+		return [48]byte{}, errors.Wrap(err, "could not decode keystore json")
+	}
+	pubKey, err := hex.DecodeString(strings.TrimPrefix(keystore.Pubkey, "0x"))
+	if err != nil || len(pubKey) != 48 {
+		return [48]byte{}, fmt.Errorf("keystore public key %q is not a 48 byte hex value", keystore.Pubkey)
+	}
+	return bytesutil.ToBytes48(pubKey), nil
+}
+
+// Lists the entries under the given archive directory.
+func (r *walletRestore) entriesUnder(dir string) []*zip.File {
+	entries := make([]*zip.File, 0)
+	for name, entry := range r.entries {
+		if strings.HasPrefix(name, dir+"/") {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Writes every entry under the given archive directory to targetDir, keeping its relative path.
+func (r *walletRestore) extract(dir string, targetDir string) error {
+	for _, entry := range r.entriesUnder(dir) {
+		relPath := strings.TrimPrefix(entry.Name, dir+"/")
+		if err := r.extractFile(entry.Name, filepath.Join(targetDir, filepath.FromSlash(relPath))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Writes a single archive entry to filePath, refusing to overwrite an existing file.
+func (r *walletRestore) extractFile(name string, filePath string) error {
+	data, err := readZipEntry(r.entries[name])
+	if err != nil {
+		return errors.Wrapf(err, "could not read %s", name)
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), params.BeaconIoConfig().ReadWriteExecutePermissions); err != nil {
+		return errors.Wrapf(err, "could not create directory for %s", filePath)
+	}
+	f, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, params.BeaconIoConfig().ReadWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "could not create %s", filePath)
+	}
+	if _, err := f.Write(data); err != nil {
+		if closeErr := f.Close(); closeErr != nil {
+			log.WithError(closeErr).Error("Could not close file")
+		}
+		return errors.Wrapf(err, "could not write %s", filePath)
+	}
+	return f.Close()
+}
+
+func readZipEntry(entry *zip.File) ([]byte, error) {
+	rc, err := entry.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rc.Close(); err != nil {
+			log.WithError(err).Error("Could not close archive entry")
+		}
+	}()
+	return ioutil.ReadAll(rc)
+}
+
+// Reports whether an archive entry name stays within the directory it is extracted to.
+func isSafeArchivePath(name string) bool {
+	if name == "" || path.IsAbs(name) || strings.Contains(name, `\`) {
+		return false
+	}
+	cleaned := path.Clean(name)
+	return cleaned == name && cleaned != ".." && !strings.HasPrefix(cleaned, "../")
+}
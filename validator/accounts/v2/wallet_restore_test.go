@@ -0,0 +1,179 @@
+package v2
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/go-bitfield"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	"github.com/prysmaticlabs/prysm/validator/db/kv"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+)
+
+// Creates a non-HD wallet with one account and proposal history for it, backs it up, and
+// returns the path of the backup along with the public key of the account.
+func backupTestWallet(t *testing.T, slotBits bitfield.Bitlist) (string, [48]byte) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	backupDir := filepath.Join(testutil.TempDir(), t.Name(), "backup")
+	dataDir := filepath.Join(testutil.TempDir(), t.Name(), "datadir")
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(filepath.Join(testutil.TempDir(), t.Name())), "Failed to remove directory")
+	})
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		walletPasswordFile:  passwordFile,
+		accountPasswordFile: passwordFile,
+		backupPasswordFile:  passwordFile,
+		exportDir:           backupDir,
+		dataDir:             dataDir,
+		keymanagerKind:      v2keymanager.Direct,
+	})
+	_, err := CreateWallet(cliCtx)
+	require.NoError(t, err)
+	require.NoError(t, CreateAccount(cliCtx))
+	pubKeys := walletPubKeys(t, &testWalletConfig{walletDir: walletDir, passwordsDir: passwordsDir})
+	require.Equal(t, 1, len(pubKeys))
+
+	store, err := kv.NewKVStore(dataDir, pubKeys)
+	require.NoError(t, err)
+	require.NoError(t, store.SaveProposalHistoryForEpoch(context.Background(), pubKeys[0][:], 2, slotBits))
+	require.NoError(t, store.Close())
+	require.NoError(t, BackupWallet(cliCtx))
+
+	files, err := ioutil.ReadDir(backupDir)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(files))
+	return filepath.Join(backupDir, files[0].Name()), pubKeys[0]
+}
+
+func walletPubKeys(t *testing.T, cfg *testWalletConfig) [][48]byte {
+	ctx := context.Background()
+	cfg.keymanagerKind = v2keymanager.Direct
+	wallet, err := OpenWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	keymanager, err := wallet.InitializeKeymanager(ctx, true)
+	require.NoError(t, err)
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	return pubKeys
+}
+
+func TestRestoreWallet_Direct(t *testing.T) {
+	slotBits := bitfield.Bitlist{0x04, 0x00, 0x00, 0x00, 0x04}
+	backupFile, pubKey := backupTestWallet(t, slotBits)
+
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	dataDir := filepath.Join(testutil.TempDir(), t.Name(), "restored-datadir")
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFile,
+		backupPasswordFile: passwordFile,
+		backupFile:         backupFile,
+		dataDir:            dataDir,
+	})
+	require.NoError(t, RestoreWallet(cliCtx))
+
+	pubKeys := walletPubKeys(t, &testWalletConfig{walletDir: walletDir, passwordsDir: passwordsDir})
+	require.Equal(t, 1, len(pubKeys))
+	assert.Equal(t, pubKey, pubKeys[0])
+	store, err := kv.GetKVStore(dataDir)
+	require.NoError(t, err)
+	require.NotNil(t, store)
+	defer func() {
+		require.NoError(t, store.Close())
+	}()
+	savedBits, err := store.ProposalHistoryForEpoch(context.Background(), pubKey[:], 2)
+	require.NoError(t, err)
+	require.DeepEqual(t, slotBits, savedBits)
+}
+
+func TestRestoreWallet_WrongPassword(t *testing.T) {
+	backupFile, _ := backupTestWallet(t, bitfield.NewBitlist(64))
+	walletDir, passwordsDir, _ := setupWalletAndPasswordsDir(t)
+	wrongPasswordFile := filepath.Join(testutil.TempDir(), t.Name(), "wrong-password.txt")
+	require.NoError(t, ioutil.WriteFile(wrongPasswordFile, []byte("SomeOtherPassword42!$"), os.ModePerm))
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		backupPasswordFile: wrongPasswordFile,
+		backupFile:         backupFile,
+	})
+	assert.ErrorContains(t, "wrong backup password", RestoreWallet(cliCtx))
+}
+
+func TestRestoreWallet_Merge(t *testing.T) {
+	slotBits := bitfield.Bitlist{0x04, 0x00, 0x00, 0x00, 0x04}
+	backupFile, pubKey := backupTestWallet(t, slotBits)
+
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	dataDir := filepath.Join(testutil.TempDir(), t.Name(), "existing-datadir")
+	cfg := &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		walletPasswordFile:  passwordFile,
+		accountPasswordFile: passwordFile,
+		backupPasswordFile:  passwordFile,
+		backupFile:          backupFile,
+		dataDir:             dataDir,
+		keymanagerKind:      v2keymanager.Direct,
+	}
+	_, err := CreateWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	require.NoError(t, CreateAccount(setupWalletCtx(t, cfg)))
+	existing := walletPubKeys(t, &testWalletConfig{walletDir: walletDir, passwordsDir: passwordsDir})
+	store, err := kv.NewKVStore(dataDir, existing)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	err = RestoreWallet(setupWalletCtx(t, cfg))
+	assert.ErrorContains(t, "a wallet already exists", err)
+
+	cfg.mergeRestore = true
+	require.NoError(t, RestoreWallet(setupWalletCtx(t, cfg)))
+	pubKeys := walletPubKeys(t, &testWalletConfig{walletDir: walletDir, passwordsDir: passwordsDir})
+	require.Equal(t, 2, len(pubKeys))
+	store, err = kv.GetKVStore(dataDir)
+	require.NoError(t, err)
+	require.NotNil(t, store)
+	savedBits, err := store.ProposalHistoryForEpoch(context.Background(), pubKey[:], 2)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+	assert.Equal(t, true, savedBits.BitAt(2), "Expected merged proposal history")
+
+	// Restoring the same backup again would duplicate its public key.
+	err = RestoreWallet(setupWalletCtx(t, cfg))
+	assert.ErrorContains(t, "already holds public key", err)
+
+	cfg.forceRestore = true
+	require.NoError(t, RestoreWallet(setupWalletCtx(t, cfg)))
+	pubKeys = walletPubKeys(t, &testWalletConfig{walletDir: walletDir, passwordsDir: passwordsDir})
+	require.Equal(t, 2, len(pubKeys))
+	// The replaced account is kept in the trash rather than removed.
+	wallet, err := OpenWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	trashed, err := wallet.trashedAccounts()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(trashed))
+	assert.Equal(t, pubKey, trashed[0].pubKey)
+}
+
+func TestIsSafeArchivePath(t *testing.T) {
+	for name, want := range map[string]bool{
+		"wallet/direct/keymanageropts.json": true,
+		"validator.db":                      true,
+		"":                                  false,
+		"/etc/passwd":                       false,
+		"../wallet":                         false,
+		"wallet/../../passwords":            false,
+		`wallet\..\passwords`:               false,
+	} {
+		assert.Equal(t, want, isSafeArchivePath(name), "Unexpected result for %q", name)
+	}
+}
@@ -0,0 +1,247 @@
+package v2
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/petnames"
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+const (
+	// secretsPasswordFileName is the password file in a secrets directory giving the password of
+	// the keystores there without a password file of their own.
+	secretsPasswordFileName = "password"
+	// SecretsKeystoreEnvPrefix starts the names of the environment variables holding keystores
+	// to validate with, such as PRYSM_KEYSTORE_0. The password of a keystore is held by the
+	// variable of the same name ending with _PASSWORD, such as PRYSM_KEYSTORE_0_PASSWORD, or
+	// else by PRYSM_KEYSTORE_PASSWORD.
+	SecretsKeystoreEnvPrefix = "PRYSM_KEYSTORE_"
+	secretsPasswordEnvSuffix = "_PASSWORD"
+)
+
+// walletSecret is a keystore loaded from secrets, along with its password.
+type walletSecret struct {
+	source   string
+	keystore []byte
+	password string
+}
+
+// SecretsWalletConfig selects the secrets a wallet kept in memory is loaded from.
+type SecretsWalletConfig struct {
+	// Dir is a directory secrets are mounted into, if any.
+	Dir string
+	// Environ are environment variables in the format of os.Environ, read only if not nil.
+	Environ []string
+	// KubernetesSelector selects the Kubernetes Secrets to load by label, if any, in
+	// KubernetesNamespace or else in the namespace of the pod.
+	KubernetesSelector  string
+	KubernetesNamespace string
+}
+
+// OpenSecretsWallet creates a non-HD wallet kept in memory only from the keystores of secrets, for
+// deployments with immutable infrastructure. Secrets are read from a directory they are mounted
+// into, such as Docker or Kubernetes secrets on a tmpfs, from environment variables, and from
+// Kubernetes Secrets read with the API of the cluster the validator client runs in. Each keystore
+// <name>.json of a directory or Kubernetes Secret has its password in <name>.pass, or else in the
+// file or key named password. Nothing of the wallet is ever written to disk.
+func OpenSecretsWallet(ctx context.Context, cfg *SecretsWalletConfig) (*Wallet, error) {
+	var kubeSecrets *kubernetesSecrets
+	if cfg.KubernetesSelector != "" {
+		var err error
+		kubeSecrets, err = newKubernetesSecrets(cfg.KubernetesNamespace, cfg.KubernetesSelector)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return openSecretsWallet(ctx, cfg, kubeSecrets)
+}
+
+func openSecretsWallet(ctx context.Context, cfg *SecretsWalletConfig, kubeSecrets *kubernetesSecrets) (*Wallet, error) {
+	secrets := make([]*walletSecret, 0)
+	if cfg.Dir != "" {
+		dirSecrets, err := readSecretsDir(cfg.Dir)
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, dirSecrets...)
+	}
+	if cfg.Environ != nil {
+		envSecrets, err := readSecretsEnv(cfg.Environ)
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, envSecrets...)
+	}
+	if kubeSecrets != nil {
+		kubernetesSecrets, err := kubeSecrets.list(ctx)
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, kubernetesSecrets...)
+	}
+	if len(secrets) == 0 {
+		return nil, errors.New("no keystores found in secrets")
+	}
+	w := &Wallet{
+		walletDir:      cfg.Dir,
+		keymanagerKind: v2keymanager.Direct,
+		storageURL:     "memory://",
+		storage:        newMemoryStorage(),
+		passwords:      make(map[string]string),
+		kubeSecrets:    kubeSecrets,
+	}
+	encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal keymanager config")
+	}
+	if err := w.storage.writeFile(ctx, KeymanagerConfigFileName, encodedCfg); err != nil {
+		return nil, err
+	}
+	added, err := w.addSecrets(ctx, secrets)
+	if err != nil {
+		return nil, err
+	}
+	log.WithField("keystores", added).Info("Loaded wallet from secrets")
+	return w, nil
+}
+
+// Adds the keystores of secrets to a wallet kept in memory as accounts, named after their public
+// keys, returning how many were added. Keystores of accounts the wallet already has are skipped.
+func (w *Wallet) addSecrets(ctx context.Context, secrets []*walletSecret) (int, error) {
+	files := make(map[string][]byte)
+	passwords := make(map[string]string)
+	keystoreFileName := fmt.Sprintf(direct.KeystoreFileNameFormat, roughtime.Now().Unix())
+	for _, secret := range secrets {
+		keystore := &v2keymanager.Keystore{}
+		if err := json.Unmarshal(secret.keystore, keystore); err != nil {
+			return 0, errors.Wrapf(err, "could not decode keystore of %s", secret.source)
+		}
+		pubKey, err := hex.DecodeString(strings.TrimPrefix(keystore.Pubkey, "0x"))
+		if err != nil || len(pubKey) != 48 {
+			return 0, fmt.Errorf("keystore of %s has no valid public key", secret.source)
+		}
+		passwordFileName := petnames.DeterministicName(pubKey, "-") + direct.PasswordFileSuffix
+		if _, ok := w.passwords[passwordFileName]; ok {
+			continue
+		}
+		if _, ok := passwords[passwordFileName]; ok {
+			log.WithField("source", secret.source).Warn("Skipping keystore already loaded from another secret")
+			continue
+		}
+		files[strings.TrimSuffix(passwordFileName, direct.PasswordFileSuffix)+"/"+keystoreFileName] = secret.keystore
+		passwords[passwordFileName] = secret.password
+	}
+	// Keystores are only added along with their passwords, so every account found can be loaded.
+	for passwordFileName, password := range passwords {
+		w.passwords[passwordFileName] = password
+	}
+	if err := w.storage.(transactionalStorage).writeFiles(ctx, files); err != nil {
+		return 0, err
+	}
+	return len(files), nil
+}
+
+// Reads the keystores of a secrets directory along with their passwords.
+func readSecretsDir(secretsDir string) ([]*walletSecret, error) {
+	secretsDir, err := expandPath(secretsDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse secrets directory")
+	}
+	entries, err := ioutil.ReadDir(secretsDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list secrets directory")
+	}
+	files := make(map[string][]byte)
+	for _, entry := range entries {
+		// Secrets mounted by Kubernetes are symbolic links into a hidden directory.
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		filePath := filepath.Join(secretsDir, entry.Name())
+		data, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read secret %s", filePath)
+		}
+		files[entry.Name()] = data
+	}
+	return secretsOfFiles(secretsDir, files)
+}
+
+// Returns the keystores of the files of a secrets directory or Kubernetes Secret by name, along
+// with their passwords, in the order of their names.
+func secretsOfFiles(source string, files map[string][]byte) ([]*walletSecret, error) {
+	keystoreNames := make([]string, 0)
+	for name := range files {
+		if strings.HasSuffix(name, ".json") {
+			keystoreNames = append(keystoreNames, name)
+		}
+	}
+	sort.Strings(keystoreNames)
+	secrets := make([]*walletSecret, 0, len(keystoreNames))
+	for _, name := range keystoreNames {
+		password, ok := files[strings.TrimSuffix(name, ".json")+direct.PasswordFileSuffix]
+		if !ok {
+			password, ok = files[secretsPasswordFileName]
+		}
+		if !ok {
+			return nil, fmt.Errorf(
+				"no password for keystore %s of %s in %s or %s",
+				name,
+				source,
+				strings.TrimSuffix(name, ".json")+direct.PasswordFileSuffix,
+				secretsPasswordFileName,
+			)
+		}
+		secrets = append(secrets, &walletSecret{
+			source:   source + "/" + name,
+			keystore: files[name],
+			password: strings.TrimRight(string(password), "\r\n"),
+		})
+	}
+	return secrets, nil
+}
+
+// Reads the keystores of environment variables along with their passwords, in the order of the
+// names of the variables.
+func readSecretsEnv(environ []string) ([]*walletSecret, error) {
+	vars := make(map[string]string, len(environ))
+	names := make([]string, 0)
+	for _, entry := range environ {
+		i := strings.Index(entry, "=")
+		if i < 0 {
+			continue
+		}
+		name, value := entry[:i], entry[i+1:]
+		vars[name] = value
+		if strings.HasPrefix(name, SecretsKeystoreEnvPrefix) && !strings.HasSuffix(name, secretsPasswordEnvSuffix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	secrets := make([]*walletSecret, 0, len(names))
+	for _, name := range names {
+		password, ok := vars[name+secretsPasswordEnvSuffix]
+		if !ok {
+			password, ok = vars[strings.TrimSuffix(SecretsKeystoreEnvPrefix, "_")+secretsPasswordEnvSuffix]
+		}
+		if !ok {
+			return nil, fmt.Errorf("no password for the keystore of %s in %s%s", name, name, secretsPasswordEnvSuffix)
+		}
+		secrets = append(secrets, &walletSecret{
+			source:   name,
+			keystore: []byte(vars[name]),
+			password: strings.TrimRight(password, "\r\n"),
+		})
+	}
+	return secrets, nil
+}
@@ -0,0 +1,154 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// kubernetesNamespaceFile holds the namespace of the pod, in pods with a service account.
+	kubernetesNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+	// kubernetesWatchRetryDelay is how long to wait before watching Kubernetes Secrets again after
+	// the watch failed.
+	kubernetesWatchRetryDelay = 5 * time.Second
+)
+
+// kubernetesSecrets reads the keystores of the Kubernetes Secrets selected by a label selector in
+// a namespace, with the API of the cluster the validator client runs in.
+type kubernetesSecrets struct {
+	client    kubernetes.Interface
+	namespace string
+	selector  string
+	// resourceVersion is the version of the Secrets last read, which watches start from.
+	resourceVersion string
+}
+
+// Creates a client of the Kubernetes API with the service account of the pod, reading the Secrets
+// of the namespace of the pod unless another namespace is given.
+func newKubernetesSecrets(namespace string, selector string) (*kubernetesSecrets, error) {
+	if namespace == "" {
+		encoded, err := ioutil.ReadFile(kubernetesNamespaceFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read namespace of pod, the namespace of the secrets must be given")
+		}
+		namespace = strings.TrimSpace(string(encoded))
+	}
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not configure Kubernetes client")
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create Kubernetes client")
+	}
+	return &kubernetesSecrets{client: client, namespace: namespace, selector: selector}, nil
+}
+
+// Lists the keystores of the selected Secrets.
+func (k *kubernetesSecrets) list(ctx context.Context) ([]*walletSecret, error) {
+	list, err := k.client.CoreV1().Secrets(k.namespace).List(ctx, metav1.ListOptions{LabelSelector: k.selector})
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not list Kubernetes secrets %s in namespace %s", k.selector, k.namespace)
+	}
+	k.resourceVersion = list.ResourceVersion
+	secrets := make([]*walletSecret, 0)
+	for i := range list.Items {
+		secretKeystores, err := k.keystores(&list.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, secretKeystores...)
+	}
+	return secrets, nil
+}
+
+func (k *kubernetesSecrets) keystores(secret *v1.Secret) ([]*walletSecret, error) {
+	return secretsOfFiles(fmt.Sprintf("kubernetes secret %s/%s", secret.Namespace, secret.Name), secret.Data)
+}
+
+// WatchKubernetesSecrets adds the keystores of the Kubernetes Secrets created or updated while the
+// validator client runs to a wallet loaded from Kubernetes Secrets, calling onNewAccounts once
+// they are, until ctx is done. Accounts of deleted Secrets are still validated with until the
+// validator client restarts.
+func (w *Wallet) WatchKubernetesSecrets(ctx context.Context, onNewAccounts func(ctx context.Context)) error {
+	if w.kubeSecrets == nil {
+		return errors.New("wallet is not loaded from Kubernetes secrets")
+	}
+	k := w.kubeSecrets
+	log.WithFields(logrus.Fields{
+		"namespace": k.namespace,
+		"selector":  k.selector,
+	}).Info("Watching Kubernetes secrets for new accounts")
+	for {
+		watcher, err := k.client.CoreV1().Secrets(k.namespace).Watch(ctx, metav1.ListOptions{
+			LabelSelector:   k.selector,
+			ResourceVersion: k.resourceVersion,
+		})
+		if err != nil {
+			log.WithError(err).Error("Could not watch Kubernetes secrets")
+		} else {
+			for event := range watcher.ResultChan() {
+				added, err := w.handleKubernetesSecretEvent(ctx, event)
+				if err != nil {
+					log.WithError(err).Error("Could not load Kubernetes secret")
+					continue
+				}
+				if added > 0 {
+					onNewAccounts(ctx)
+				}
+			}
+			watcher.Stop()
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(kubernetesWatchRetryDelay):
+		}
+	}
+}
+
+// Adds the keystores of a Secret created or updated to the wallet, returning how many were added.
+// Secrets are listed again if the watch fell too far behind.
+func (w *Wallet) handleKubernetesSecretEvent(ctx context.Context, event watch.Event) (int, error) {
+	k := w.kubeSecrets
+	switch event.Type {
+	case watch.Added, watch.Modified:
+		secret, ok := event.Object.(*v1.Secret)
+		if !ok {
+			return 0, fmt.Errorf("unexpected object %T in watch of Kubernetes secrets", event.Object)
+		}
+		k.resourceVersion = secret.ResourceVersion
+		secrets, err := k.keystores(secret)
+		if err != nil {
+			return 0, err
+		}
+		return w.addSecrets(ctx, secrets)
+	case watch.Deleted:
+		if secret, ok := event.Object.(*v1.Secret); ok {
+			k.resourceVersion = secret.ResourceVersion
+			log.WithField("secret", secret.Namespace+"/"+secret.Name).Warn(
+				"Kubernetes secret deleted, its accounts are validated with until the validator client restarts",
+			)
+		}
+		return 0, nil
+	case watch.Error:
+		log.WithField("status", event.Object).Debug("Watch of Kubernetes secrets failed, listing them again")
+		secrets, err := k.list(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return w.addSecrets(ctx, secrets)
+	}
+	return 0, nil
+}
@@ -0,0 +1,115 @@
+package v2
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestOpenSecretsWallet(t *testing.T) {
+	ctx := context.Background()
+	secretsDir := filepath.Join(testutil.TempDir(), t.Name(), "secrets")
+	envDir := filepath.Join(testutil.TempDir(), t.Name(), "env")
+	require.NoError(t, os.MkdirAll(secretsDir, os.ModePerm))
+	require.NoError(t, os.MkdirAll(envDir, os.ModePerm))
+	t.Cleanup(func() {
+		assert.NoError(t, os.RemoveAll(filepath.Join(testutil.TempDir(), t.Name())))
+	})
+	createKeystore(t, secretsDir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(secretsDir, secretsPasswordFileName), []byte(password+"\n"), os.ModePerm))
+	envKeystore, err := ioutil.ReadFile(createKeystore(t, envDir))
+	require.NoError(t, err)
+
+	_, err = OpenSecretsWallet(ctx, &SecretsWalletConfig{Environ: []string{"HOME=/root"}})
+	assert.ErrorContains(t, "no keystores found", err)
+	_, err = OpenSecretsWallet(ctx, &SecretsWalletConfig{
+		Environ: []string{SecretsKeystoreEnvPrefix + "0=" + string(envKeystore)},
+	})
+	assert.ErrorContains(t, "no password for the keystore", err)
+
+	wallet, err := OpenSecretsWallet(ctx, &SecretsWalletConfig{
+		Dir: secretsDir,
+		Environ: []string{
+			"HOME=/root",
+			SecretsKeystoreEnvPrefix + "0=" + string(envKeystore),
+			SecretsKeystoreEnvPrefix + "0" + secretsPasswordEnvSuffix + "=" + password,
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, v2keymanager.Direct, wallet.KeymanagerKind())
+	keymanager, err := wallet.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+	require.NoError(t, err)
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(pubKeys))
+
+	// Nothing is written to disk.
+	entries, err := ioutil.ReadDir(secretsDir)
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+}
+
+func TestOpenSecretsWallet_Kubernetes(t *testing.T) {
+	ctx := context.Background()
+	keystoresDir := filepath.Join(testutil.TempDir(), t.Name())
+	require.NoError(t, os.MkdirAll(keystoresDir, os.ModePerm))
+	t.Cleanup(func() {
+		assert.NoError(t, os.RemoveAll(keystoresDir))
+	})
+	newSecret := func(name string, resourceVersion string) *v1.Secret {
+		keystorePath := createKeystore(t, keystoresDir)
+		keystore, err := ioutil.ReadFile(keystorePath)
+		require.NoError(t, err)
+		require.NoError(t, os.Remove(keystorePath))
+		return &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       "validators",
+				Labels:          map[string]string{"app": "validator"},
+				ResourceVersion: resourceVersion,
+			},
+			Data: map[string][]byte{
+				"keystore.json":         keystore,
+				secretsPasswordFileName: []byte(password),
+			},
+		}
+	}
+	client := fake.NewSimpleClientset(newSecret("keys-0", "1"))
+	kubeSecrets := &kubernetesSecrets{client: client, namespace: "validators", selector: "app=validator"}
+	wallet, err := openSecretsWallet(ctx, &SecretsWalletConfig{KubernetesSelector: "app=validator"}, kubeSecrets)
+	require.NoError(t, err)
+	accountNames, err := wallet.ListDirs()
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(accountNames))
+
+	// Keystores of new secrets are added, those of updated secrets only once.
+	secret := newSecret("keys-1", "2")
+	added, err := wallet.handleKubernetesSecretEvent(ctx, watch.Event{Type: watch.Added, Object: secret})
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+	assert.Equal(t, "2", kubeSecrets.resourceVersion)
+	added, err = wallet.handleKubernetesSecretEvent(ctx, watch.Event{Type: watch.Modified, Object: secret})
+	require.NoError(t, err)
+	assert.Equal(t, 0, added)
+	keymanager, err := wallet.InitializeKeymanager(ctx, true /* skip mnemonic confirm */)
+	require.NoError(t, err)
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(pubKeys))
+
+	// Secrets without passwords are refused.
+	delete(secret.Data, secretsPasswordFileName)
+	_, err = wallet.handleKubernetesSecretEvent(ctx, watch.Event{Type: watch.Modified, Object: secret})
+	assert.ErrorContains(t, "no password for keystore keystore.json of kubernetes secret validators/keys-1", err)
+}
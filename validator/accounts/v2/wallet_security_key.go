@@ -0,0 +1,289 @@
+package v2
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/urfave/cli/v2"
+	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// securityKeyFileName is the file in the accounts path of a wallet holding its wallet password
+	// wrapped by a secret of a FIDO2 security key. It is read from disk before the storage of the
+	// wallet, which may be encrypted with the wallet password.
+	securityKeyFileName     = "security-key.json"
+	securityKeyVersion      = 1
+	securityKeyRelyingParty = "prysm-wallet"
+	securityKeySaltSize     = 32
+	// The wallet password of a wallet with a security key is random, so only the security key and
+	// the passphrase it is wrapped with unlock it.
+	securityKeyPasswordSize = 32
+	securityKeyWrapInfo     = "prysm-wallet-security-key"
+)
+
+// securityKeyFile is the content of the security key file of a wallet. The wallet password is
+// encrypted with keystorev4 with a key derived from both the hmac-secret the security key computes
+// from the salt with its credential and the passphrase of the wallet.
+type securityKeyFile struct {
+	Version      int                    `json:"version"`
+	RelyingParty string                 `json:"relying_party"`
+	CredentialID string                 `json:"credential_id"`
+	Salt         string                 `json:"salt"`
+	Crypto       map[string]interface{} `json:"crypto"`
+}
+
+// securityKey computes secrets with a credential of a FIDO2 security key and its hmac-secret
+// extension, each requiring the security key to be touched.
+type securityKey interface {
+	makeCredential(relyingParty string) ([]byte, error)
+	hmacSecret(relyingParty string, credentialID []byte, salt []byte) ([]byte, error)
+}
+
+// Opens the FIDO2 security key at a device path, or lists the paths of those plugged in. Tests
+// replace them with software security keys.
+var (
+	openSecurityKey  = func(device string) securityKey { return &fido2Tool{device: device} }
+	listSecurityKeys = listFido2Devices
+)
+
+// EnrollSecurityKey wraps the wallet password of a wallet with a FIDO2 security key, so opening
+// the wallet, such as to start the validator client, requires touching the security key on top of
+// entering the wallet password. The files of the wallet are re-encrypted with a new random wallet
+// password, wrapped with a key derived from both the hmac-secret of a new credential of the
+// security key and the current wallet password, which keeps being entered as before.
+func EnrollSecurityKey(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	if err := wallet.checkWritable(); err != nil {
+		return err
+	}
+	if wallet.hasSecurityKey() {
+		return errors.New("wallet password is already wrapped by a security key")
+	}
+	if wallet.walletPassword == "" {
+		return errNoWalletPassword
+	}
+	device := cliCtx.String(flags.SecurityKeyDeviceFlag.Name)
+	if device == "" {
+		devices, err := listSecurityKeys()
+		if err != nil {
+			return err
+		}
+		if len(devices) == 0 {
+			return errors.New("no security key found, plug one in or pass its path with --" + flags.SecurityKeyDeviceFlag.Name)
+		}
+		device = devices[0]
+	}
+	lock, err := wallet.Lock()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := lock.Unlock(); err != nil {
+			log.WithError(err).Error("Could not unlock wallet")
+		}
+	}()
+	key := openSecurityKey(device)
+	log.WithField("device", device).Info("Touch the security key to register it with the wallet")
+	credentialID, err := key.makeCredential(securityKeyRelyingParty)
+	if err != nil {
+		return errors.Wrap(err, "could not register security key")
+	}
+	salt := make([]byte, securityKeySaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return errors.Wrap(err, "could not generate security key salt")
+	}
+	log.Info("Touch the security key again to wrap the wallet password")
+	secret, err := key.hmacSecret(securityKeyRelyingParty, credentialID, salt)
+	if err != nil {
+		return errors.Wrap(err, "could not compute secret of security key")
+	}
+	rawPassword := make([]byte, securityKeyPasswordSize)
+	if _, err := rand.Read(rawPassword); err != nil {
+		return errors.Wrap(err, "could not generate wallet password")
+	}
+	newPassword := hex.EncodeToString(rawPassword)
+	wrapKey, err := securityKeyWrapKey(secret, wallet.walletPassword)
+	if err != nil {
+		return err
+	}
+	encoded, err := encryptSecurityKeyFile(&securityKeyFile{
+		Version:      securityKeyVersion,
+		RelyingParty: securityKeyRelyingParty,
+		CredentialID: hex.EncodeToString(credentialID),
+		Salt:         hex.EncodeToString(salt),
+	}, newPassword, wrapKey)
+	if err != nil {
+		return err
+	}
+	// The security key file is written first, so the files are never encrypted with the new random
+	// password unless the security key can unwrap it.
+	err = wallet.changePassword(withJournalAction(ctx, journalActionSecurityKeyEnrolled), newPassword, &reencryptedFile{
+		path: wallet.securityKeyPath(),
+		data: encoded,
+	})
+	if err != nil {
+		return err
+	}
+	log.Info("Successfully wrapped the wallet password with the security key")
+	log.Warn("The wallet can no longer be opened without this security key, back up the mnemonic or the " +
+		"keystores of its accounts before relying on it")
+	return nil
+}
+
+// RemoveSecurityKey re-encrypts the files of a wallet whose password is wrapped by a security key
+// with a new wallet password given by --new-wallet-password-file or entered at the prompt, and
+// removes its security key file, so it is opened with the wallet password alone again.
+func RemoveSecurityKey(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	if err := wallet.checkWritable(); err != nil {
+		return err
+	}
+	if !wallet.hasSecurityKey() {
+		return errors.New("wallet password is not wrapped by a security key")
+	}
+	newPassword, err := inputPassword(cliCtx, flags.NewWalletPasswordFileFlag, newWalletPasswordPromptText, confirmPass)
+	if err != nil {
+		return errors.Wrap(err, "could not input new wallet password")
+	}
+	lock, err := wallet.Lock()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := lock.Unlock(); err != nil {
+			log.WithError(err).Error("Could not unlock wallet")
+		}
+	}()
+	if err := wallet.changePassword(withJournalAction(ctx, journalActionPasswordChanged), newPassword); err != nil {
+		return err
+	}
+	if err := os.Remove(wallet.securityKeyPath()); err != nil {
+		return errors.Wrapf(
+			err, "wallet password was changed but could not remove %s, remove it to open the wallet", wallet.securityKeyPath(),
+		)
+	}
+	entry := &walletJournalEntry{
+		Action:  journalActionSecurityKeyRemoved,
+		Deleted: []string{securityKeyFileName},
+	}
+	if err := wallet.recordMutation(ctx, entry); err != nil {
+		return err
+	}
+	log.Info("Successfully removed the security key of the wallet")
+	return nil
+}
+
+// Returns whether the wallet password of the wallet is wrapped by a security key.
+func (w *Wallet) hasSecurityKey() bool {
+	return fileExists(w.securityKeyPath())
+}
+
+func (w *Wallet) securityKeyPath() string {
+	return filepath.Join(w.accountsPath, securityKeyFileName)
+}
+
+// Returns a function inputting the wallet password of a wallet whose password is wrapped by a
+// security key: the passphrase is input as the wallet password would be, and unwraps the wallet
+// password once the security key is touched.
+func unwrapWalletPassword(accountsPath string, inputPassphrase func() (string, error)) func() (string, error) {
+	return func() (string, error) {
+		passphrase, err := inputPassphrase()
+		if err != nil {
+			return "", err
+		}
+		encoded, err := ioutil.ReadFile(filepath.Join(accountsPath, securityKeyFileName))
+		if err != nil {
+			return "", errors.Wrap(err, "could not read security key file")
+		}
+		file := &securityKeyFile{}
+		if err := json.Unmarshal(encoded, file); err != nil {
+			return "", errors.Wrap(err, "could not decode security key file")
+		}
+		if file.Version != securityKeyVersion {
+			return "", fmt.Errorf("unsupported security key file version %d", file.Version)
+		}
+		credentialID, err := hex.DecodeString(file.CredentialID)
+		if err != nil {
+			return "", errors.Wrap(err, "could not decode credential id of security key")
+		}
+		salt, err := hex.DecodeString(file.Salt)
+		if err != nil {
+			return "", errors.Wrap(err, "could not decode security key salt")
+		}
+		devices, err := listSecurityKeys()
+		if err != nil {
+			return "", err
+		}
+		if len(devices) == 0 {
+			return "", errors.New("no security key found, plug in the security key of the wallet")
+		}
+		log.Info("Touch the security key of the wallet to unlock it")
+		// The credential of the wallet is only known to the security key it was made with.
+		for _, device := range devices {
+			secret, secretErr := openSecurityKey(device).hmacSecret(file.RelyingParty, credentialID, salt)
+			if secretErr != nil {
+				err = secretErr
+				log.WithError(err).WithField("device", device).Debug("Could not compute secret of security key")
+				continue
+			}
+			wrapKey, wrapErr := securityKeyWrapKey(secret, passphrase)
+			if wrapErr != nil {
+				return "", wrapErr
+			}
+			decrypted, decryptErr := keystorev4.New().Decrypt(file.Crypto, wrapKey)
+			if decryptErr != nil {
+				return "", errors.Wrap(decryptErr, "could not unwrap wallet password, wrong wallet password")
+			}
+			return string(decrypted), nil
+		}
+		return "", errors.Wrap(err, "none of the security keys plugged in unlocks the wallet")
+	}
+}
+
+// Derives the key a wallet password is wrapped with from both the hmac-secret of the security key
+// and the passphrase of the wallet, so neither unwraps it alone.
+func securityKeyWrapKey(secret []byte, passphrase string) (string, error) {
+	wrapKey := make([]byte, 32)
+	reader := hkdf.New(sha256.New, append(append([]byte{}, secret...), passphrase...), nil, []byte(securityKeyWrapInfo))
+	if _, err := io.ReadFull(reader, wrapKey); err != nil {
+		return "", errors.Wrap(err, "could not derive wrapping key")
+	}
+	return hex.EncodeToString(wrapKey), nil
+}
+
+func encryptSecurityKeyFile(file *securityKeyFile, walletPassword string, wrapKey string) ([]byte, error) {
+	cryptoFields, err := keystorev4.New().Encrypt([]byte(walletPassword), wrapKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not wrap wallet password")
+	}
+	file.Crypto = cryptoFields
+	encoded, err := json.MarshalIndent(file, "", "\t")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encode security key file")
+	}
+	return encoded, nil
+}
@@ -0,0 +1,120 @@
+package v2
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// securityKeyUserName is the user name of the credentials of wallets on security keys.
+const securityKeyUserName = "prysm-wallet"
+
+// fido2Tool is a FIDO2 security key used through the fido2-cred and fido2-assert tools of
+// libfido2, which prompt for the PIN of the security key themselves if it has one.
+type fido2Tool struct {
+	device string
+}
+
+// Makes a credential with the hmac-secret extension on the security key, returning its id.
+func (k *fido2Tool) makeCredential(relyingParty string) ([]byte, error) {
+	clientDataHash, err := randomBase64(32)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := randomBase64(32)
+	if err != nil {
+		return nil, err
+	}
+	// fido2-cred -M outputs the client data hash, relying party, format, authenticator data and
+	// credential id, followed by the attestation.
+	output, err := runFido2Tool("fido2-cred", []string{clientDataHash, relyingParty, securityKeyUserName, userID}, "-M", "-h", k.device)
+	if err != nil {
+		return nil, err
+	}
+	if len(output) < 5 {
+		return nil, errors.New("unexpected output of fido2-cred")
+	}
+	credentialID, err := base64.StdEncoding.DecodeString(output[4])
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode credential id")
+	}
+	return credentialID, nil
+}
+
+// Computes the hmac-secret of a salt with a credential of the security key.
+func (k *fido2Tool) hmacSecret(relyingParty string, credentialID []byte, salt []byte) ([]byte, error) {
+	clientDataHash, err := randomBase64(32)
+	if err != nil {
+		return nil, err
+	}
+	input := []string{
+		clientDataHash,
+		relyingParty,
+		base64.StdEncoding.EncodeToString(credentialID),
+		base64.StdEncoding.EncodeToString(salt),
+	}
+	// fido2-assert -G outputs the client data hash, relying party, authenticator data and
+	// signature, followed by the hmac-secret.
+	output, err := runFido2Tool("fido2-assert", input, "-G", "-h", k.device)
+	if err != nil {
+		return nil, err
+	}
+	if len(output) < 5 {
+		return nil, errors.New("unexpected output of fido2-assert")
+	}
+	secret, err := base64.StdEncoding.DecodeString(output[4])
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode hmac-secret")
+	}
+	if len(secret) != len(salt) {
+		return nil, fmt.Errorf("hmac-secret is %d bytes, expected %d", len(secret), len(salt))
+	}
+	return secret, nil
+}
+
+// Lists the paths of the FIDO2 security keys plugged in, with fido2-token -L.
+func listFido2Devices() ([]string, error) {
+	output, err := runFido2Tool("fido2-token", nil, "-L")
+	if err != nil {
+		return nil, err
+	}
+	devices := make([]string, 0, len(output))
+	for _, line := range output {
+		// Each line is the path of a security key, followed by its vendor and product.
+		if i := strings.Index(line, ": "); i > 0 {
+			devices = append(devices, line[:i])
+		}
+	}
+	return devices, nil
+}
+
+// Runs a libfido2 tool with the given lines as input, returning the lines of its output.
+func runFido2Tool(name string, input []string, args ...string) ([]string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(strings.Join(input, "\n") + "\n")
+	cmd.Stderr = os.Stderr
+	output, err := cmd.Output()
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, errors.Wrapf(err, "could not find %s, install the tools of libfido2", name)
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "could not run %s", name)
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return nil, nil
+	}
+	return lines, nil
+}
+
+func randomBase64(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "could not generate random bytes")
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,123 @@
+package v2
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+)
+
+// softSecurityKey is a security key computing hmac-secrets with a key in memory.
+type softSecurityKey struct {
+	key         []byte
+	credentials map[string]bool
+}
+
+func newSoftSecurityKey(t *testing.T) *softSecurityKey {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return &softSecurityKey{key: key, credentials: make(map[string]bool)}
+}
+
+func (k *softSecurityKey) makeCredential(relyingParty string) ([]byte, error) {
+	credentialID := make([]byte, 32)
+	if _, err := rand.Read(credentialID); err != nil {
+		return nil, err
+	}
+	k.credentials[relyingParty+string(credentialID)] = true
+	return credentialID, nil
+}
+
+func (k *softSecurityKey) hmacSecret(relyingParty string, credentialID []byte, salt []byte) ([]byte, error) {
+	if !k.credentials[relyingParty+string(credentialID)] {
+		return nil, errors.New("no credentials")
+	}
+	mac := hmac.New(sha256.New, k.key)
+	mac.Write(credentialID)
+	mac.Write(salt)
+	return mac.Sum(nil), nil
+}
+
+// Plugs in the given security keys, by device path, for the rest of the test.
+func plugSecurityKeys(t *testing.T, keys map[string]*softSecurityKey) {
+	open, list := openSecurityKey, listSecurityKeys
+	openSecurityKey = func(device string) securityKey {
+		return keys[device]
+	}
+	listSecurityKeys = func() ([]string, error) {
+		devices := make([]string, 0, len(keys))
+		for device := range keys {
+			devices = append(devices, device)
+		}
+		return devices, nil
+	}
+	t.Cleanup(func() {
+		openSecurityKey, listSecurityKeys = open, list
+	})
+}
+
+func TestEnrollSecurityKey(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		walletPasswordFile:  passwordFile,
+		accountPasswordFile: passwordFile,
+		keymanagerKind:      v2keymanager.Direct,
+	}
+	_, err := CreateWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	require.NoError(t, CreateAccount(setupWalletCtx(t, cfg)))
+	require.NoError(t, UseMasterPassword(setupWalletCtx(t, cfg)))
+	want := walletPubKeys(t, cfg)
+
+	securityKey := newSoftSecurityKey(t)
+	plugSecurityKeys(t, map[string]*softSecurityKey{"/dev/hidraw0": securityKey})
+	require.NoError(t, EnrollSecurityKey(setupWalletCtx(t, cfg)))
+	assert.ErrorContains(t, "already wrapped by a security key", EnrollSecurityKey(setupWalletCtx(t, cfg)))
+	assert.DeepEqual(t, want, walletPubKeys(t, cfg))
+
+	// The wallet password no longer decrypts the wallet without the security key.
+	masterPasswordFile, err := ioutil.ReadFile(filepath.Join(walletDir, v2keymanager.Direct.String(), masterPasswordFileName))
+	require.NoError(t, err)
+	_, err = decryptMasterPasswordFile(masterPasswordFile, password)
+	assert.ErrorContains(t, "wrong wallet password", err)
+	plugSecurityKeys(t, map[string]*softSecurityKey{})
+	_, err = OpenWallet(setupWalletCtx(t, cfg))
+	assert.ErrorContains(t, "no security key found", err)
+	plugSecurityKeys(t, map[string]*softSecurityKey{"/dev/hidraw1": newSoftSecurityKey(t)})
+	_, err = OpenWallet(setupWalletCtx(t, cfg))
+	assert.ErrorContains(t, "none of the security keys plugged in unlocks the wallet", err)
+
+	// Nor does the security key without the wallet password.
+	plugSecurityKeys(t, map[string]*softSecurityKey{
+		"/dev/hidraw1": newSoftSecurityKey(t),
+		"/dev/hidraw2": securityKey,
+	})
+	wrongPasswordFile := filepath.Join(filepath.Dir(passwordFile), "wrong-wallet-password.txt")
+	require.NoError(t, ioutil.WriteFile(wrongPasswordFile, []byte("Wr0ngWall3tPassw0rd!"), os.ModePerm))
+	wrongCfg := *cfg
+	wrongCfg.walletPasswordFile = wrongPasswordFile
+	_, err = OpenWallet(setupWalletCtx(t, &wrongCfg))
+	assert.ErrorContains(t, "could not unwrap wallet password", err)
+	assert.ErrorContains(t, "wrapped by a security key", ChangeWalletPassword(setupWalletCtx(t, cfg)))
+
+	// Once the security key is removed, the wallet opens with its new wallet password alone.
+	newPasswordFile := filepath.Join(filepath.Dir(passwordFile), "new-wallet-password.txt")
+	require.NoError(t, ioutil.WriteFile(newPasswordFile, []byte("Sh1nyN3wWall3t!"), os.ModePerm))
+	cfg.newWalletPassword = newPasswordFile
+	require.NoError(t, RemoveSecurityKey(setupWalletCtx(t, cfg)))
+	plugSecurityKeys(t, map[string]*softSecurityKey{})
+	cfg.walletPasswordFile = newPasswordFile
+	assert.DeepEqual(t, want, walletPubKeys(t, cfg))
+	assert.ErrorContains(t, "not wrapped by a security key", RemoveSecurityKey(setupWalletCtx(t, cfg)))
+}
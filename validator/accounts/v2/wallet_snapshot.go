@@ -0,0 +1,298 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// walletSnapshotsDirName is the directory of the datadir wallet snapshots are kept in, unless
+	// another directory is given.
+	walletSnapshotsDirName = "wallet-snapshots"
+	walletSnapshotSuffix   = ".zip"
+)
+
+var snapshotLabelRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// walletSnapshot is a snapshot of a wallet in the snapshots directory.
+type walletSnapshot struct {
+	label   string
+	path    string
+	created time.Time
+	size    int64
+}
+
+// SnapshotWallet captures a point-in-time copy of the wallet, the account passwords of a non-HD
+// wallet and the validator database with its slashing protection history, under a label, so the
+// wallet can be rolled back to it with wallet-v2 restore-snapshot after a botched import or
+// migration. The snapshot is taken while holding the lock of the wallet, and the validator must be
+// stopped so the database can be read. Snapshots hold the account passwords as they are in the
+// passwords directory, and are only readable by the user.
+func SnapshotWallet(cliCtx *cli.Context) error {
+	wallet, err := OpenWallet(cliCtx)
+	if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	label := cliCtx.String(flags.SnapshotLabelFlag.Name)
+	if label == "" {
+		label = fmt.Sprintf("snapshot-%d", roughtime.Now().Unix())
+	}
+	lock, err := wallet.Lock()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := lock.Unlock(); err != nil {
+			log.WithError(err).Error("Could not unlock wallet")
+		}
+	}()
+	snapshotPath, err := wallet.snapshot(walletSnapshotsDir(cliCtx), label, validatorDataDir(cliCtx))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Took snapshot %s of the wallet at %s\n", au.BrightGreen(label), snapshotPath)
+	return nil
+}
+
+// ListWalletSnapshots lists the snapshots of the snapshots directory, oldest first.
+func ListWalletSnapshots(cliCtx *cli.Context) error {
+	snapshotsDir := walletSnapshotsDir(cliCtx)
+	snapshots, err := listWalletSnapshots(snapshotsDir)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		fmt.Printf("No wallet snapshots in %s\n", snapshotsDir)
+		return nil
+	}
+	for _, snapshot := range snapshots {
+		fmt.Printf(
+			"%s\t%s\t%d bytes\n",
+			au.BrightGreen(snapshot.label),
+			snapshot.created.Format(time.RFC3339),
+			snapshot.size,
+		)
+	}
+	return nil
+}
+
+// RestoreWalletSnapshot rolls the wallet and the account passwords of a non-HD wallet back to a
+// snapshot of --snapshot-label, first taking a snapshot of the wallet as it is, so the restore can
+// be undone. The journal of the wallet is kept, recording the restore. Slashing protection history
+// is never rolled back, as signing again what was signed since the snapshot gets a validator
+// slashed: the history of the snapshot is merged into the validator database instead.
+func RestoreWalletSnapshot(cliCtx *cli.Context) error {
+	label := cliCtx.String(flags.SnapshotLabelFlag.Name)
+	if label == "" {
+		return fmt.Errorf("--%s is required to restore a snapshot", flags.SnapshotLabelFlag.Name)
+	}
+	snapshotsDir := walletSnapshotsDir(cliCtx)
+	snapshotPath, err := walletSnapshotPath(snapshotsDir, label)
+	if err != nil {
+		return err
+	}
+	encoded, err := ioutil.ReadFile(snapshotPath)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no wallet snapshot %s in %s", label, snapshotsDir)
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not read wallet snapshot")
+	}
+	snapshot, err := readBackupArchive(encoded)
+	if err != nil {
+		return errors.Wrapf(err, "could not read wallet snapshot %s", label)
+	}
+	wallet, err := OpenWallet(cliCtx)
+	if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	if err := wallet.checkWritable(); err != nil {
+		return err
+	}
+	if wallet.storage != nil {
+		return errors.New("only wallets kept in the wallet directory can be restored from snapshots")
+	}
+	if err := wallet.checkApproval(cliCtx, approvalActionRestoreSnapshot, []string{snapshotSubject(label)}); err != nil {
+		return err
+	}
+	if err := wallet.checkTOTP(cliCtx, "restoring a wallet snapshot"); err != nil {
+		return err
+	}
+	if snapshot.kind != wallet.keymanagerKind {
+		return fmt.Errorf("snapshot %s is of a %s wallet, cannot restore it into a %s wallet", label, snapshot.kind, wallet.keymanagerKind)
+	}
+	lock, err := wallet.Lock()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := lock.Unlock(); err != nil {
+			log.WithError(err).Error("Could not unlock wallet")
+		}
+	}()
+	dataDir := validatorDataDir(cliCtx)
+	undoLabel := fmt.Sprintf("before-restore-of-%s-%d", label, roughtime.Now().Unix())
+	if _, err := wallet.snapshot(snapshotsDir, undoLabel, dataDir); err != nil {
+		return errors.Wrap(err, "could not take snapshot of the wallet before restoring")
+	}
+	if err := wallet.restoreSnapshot(context.Background(), snapshot, dataDir); err != nil {
+		return errors.Wrapf(err, "could not restore snapshot, the wallet before restoring is in snapshot %s", undoLabel)
+	}
+	fmt.Printf(
+		"Restored snapshot %s of the wallet, undo with --%s=%s\n",
+		au.BrightGreen(label),
+		flags.SnapshotLabelFlag.Name,
+		undoLabel,
+	)
+	return nil
+}
+
+// Writes a snapshot of the wallet to the snapshots directory under a label, returning its path.
+func (w *Wallet) snapshot(snapshotsDir string, label string, dataDir string) (string, error) {
+	if w.storage != nil {
+		return "", errors.New("only wallets kept in the wallet directory can be snapshotted")
+	}
+	snapshotPath, err := walletSnapshotPath(snapshotsDir, label)
+	if err != nil {
+		return "", err
+	}
+	if fileExists(snapshotPath) {
+		return "", fmt.Errorf("wallet snapshot %s already exists", label)
+	}
+	archive, err := w.backupArchive(dataDir)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(snapshotsDir, params.BeaconIoConfig().ReadWriteExecutePermissions); err != nil {
+		return "", errors.Wrap(err, "could not create snapshots directory")
+	}
+	if err := writeFileAtomic(snapshotPath, archive, params.BeaconIoConfig().ReadWritePermissions); err != nil {
+		return "", errors.Wrapf(err, "could not write %s", snapshotPath)
+	}
+	return snapshotPath, nil
+}
+
+// Replaces the files of the wallet and its account passwords with those of a snapshot, keeping the
+// lock and the journal of the wallet, and merges the slashing protection history of the snapshot
+// into the validator database of dataDir.
+func (w *Wallet) restoreSnapshot(ctx context.Context, snapshot *walletRestore, dataDir string) error {
+	entries, err := ioutil.ReadDir(w.accountsPath)
+	if err != nil {
+		return errors.Wrap(err, "could not list wallet files")
+	}
+	accountsDir := path.Join(walletBackupWalletDir, w.keymanagerKind.String())
+	restored := make(map[string]bool)
+	for _, entry := range snapshot.entriesUnder(accountsDir) {
+		restored[strings.SplitN(strings.TrimPrefix(entry.Name, accountsDir+"/"), "/", 2)[0]] = true
+	}
+	removed := make([]string, 0)
+	for _, entry := range entries {
+		if entry.Name() == walletLockFileName || entry.Name() == walletJournalFileName {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(w.accountsPath, entry.Name())); err != nil {
+			return errors.Wrapf(err, "could not remove %s", entry.Name())
+		}
+		if !restored[entry.Name()] {
+			removed = append(removed, entry.Name())
+		}
+	}
+	written := make(map[string]string)
+	for _, entry := range snapshot.entriesUnder(accountsDir) {
+		relPath := strings.TrimPrefix(entry.Name, accountsDir+"/")
+		if relPath == walletJournalFileName {
+			continue
+		}
+		data, err := readZipEntry(entry)
+		if err != nil {
+			return errors.Wrapf(err, "could not read %s", entry.Name)
+		}
+		if err := snapshot.extractFile(entry.Name, filepath.Join(w.accountsPath, filepath.FromSlash(relPath))); err != nil {
+			return err
+		}
+		written[relPath] = hashOfData(data)
+	}
+	passwords := make([]string, 0)
+	if snapshot.manifest.HasPasswords && w.passwordsDir != "" {
+		passwordFiles, err := filepath.Glob(filepath.Join(w.passwordsDir, "*"+direct.PasswordFileSuffix))
+		if err != nil {
+			return err
+		}
+		for _, passwordFile := range passwordFiles {
+			if err := os.Remove(passwordFile); err != nil {
+				return errors.Wrapf(err, "could not remove %s", passwordFile)
+			}
+		}
+		if err := snapshot.extract(walletBackupPasswordsDir, w.passwordsDir); err != nil {
+			return errors.Wrap(err, "could not restore account passwords")
+		}
+		for _, entry := range snapshot.entriesUnder(walletBackupPasswordsDir) {
+			passwords = append(passwords, strings.TrimPrefix(entry.Name, walletBackupPasswordsDir+"/"))
+		}
+	}
+	if err := snapshot.restoreValidatorDB(ctx, dataDir, nil /* all public keys */); err != nil {
+		return err
+	}
+	return w.recordMutation(ctx, &walletJournalEntry{
+		Action:    journalActionSnapshotRestored,
+		Written:   written,
+		Passwords: passwords,
+		Deleted:   removed,
+	})
+}
+
+// Returns the snapshots of a snapshots directory, oldest first.
+func listWalletSnapshots(snapshotsDir string) ([]*walletSnapshot, error) {
+	entries, err := ioutil.ReadDir(snapshotsDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list snapshots directory")
+	}
+	snapshots := make([]*walletSnapshot, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), walletSnapshotSuffix) {
+			continue
+		}
+		snapshots = append(snapshots, &walletSnapshot{
+			label:   strings.TrimSuffix(entry.Name(), walletSnapshotSuffix),
+			path:    filepath.Join(snapshotsDir, entry.Name()),
+			created: entry.ModTime(),
+			size:    entry.Size(),
+		})
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].created.Before(snapshots[j].created)
+	})
+	return snapshots, nil
+}
+
+func walletSnapshotsDir(cliCtx *cli.Context) string {
+	if dir := cliCtx.String(flags.SnapshotsDirFlag.Name); dir != "" {
+		return dir
+	}
+	return filepath.Join(validatorDataDir(cliCtx), walletSnapshotsDirName)
+}
+
+func walletSnapshotPath(snapshotsDir string, label string) (string, error) {
+	if !snapshotLabelRegex.MatchString(label) {
+		return "", fmt.Errorf("invalid snapshot label %q, labels hold letters, digits, dots, dashes and underscores", label)
+	}
+	return filepath.Join(snapshotsDir, label+walletSnapshotSuffix), nil
+}
@@ -0,0 +1,86 @@
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	"github.com/prysmaticlabs/prysm/validator/db/kv"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestWalletSnapshots(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	dataDir := filepath.Join(testutil.TempDir(), t.Name(), "datadir")
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(filepath.Dir(dataDir)), "Failed to remove directory")
+	})
+	cfg := &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		walletPasswordFile:  passwordFile,
+		accountPasswordFile: passwordFile,
+		dataDir:             dataDir,
+		keymanagerKind:      v2keymanager.Direct,
+	}
+	_, err := CreateWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	require.NoError(t, CreateAccount(setupWalletCtx(t, cfg)))
+	want := walletPubKeys(t, &testWalletConfig{walletDir: walletDir, passwordsDir: passwordsDir})
+	require.Equal(t, 1, len(want))
+	store, err := kv.NewKVStore(dataDir, want)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	cfg.snapshotLabel = "../escape"
+	assert.ErrorContains(t, "invalid snapshot label", SnapshotWallet(setupWalletCtx(t, cfg)))
+	cfg.snapshotLabel = "before-import"
+	require.NoError(t, SnapshotWallet(setupWalletCtx(t, cfg)))
+	assert.ErrorContains(t, "already exists", SnapshotWallet(setupWalletCtx(t, cfg)))
+	snapshots, err := listWalletSnapshots(filepath.Join(dataDir, walletSnapshotsDirName))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(snapshots))
+	assert.Equal(t, "before-import", snapshots[0].label)
+	info, err := os.Stat(snapshots[0].path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// A botched import adds an account, which restoring the snapshot removes along with its password.
+	require.NoError(t, CreateAccount(setupWalletCtx(t, cfg)))
+	require.Equal(t, 2, len(walletPubKeys(t, &testWalletConfig{walletDir: walletDir, passwordsDir: passwordsDir})))
+	cfg.snapshotLabel = "missing"
+	assert.ErrorContains(t, "no wallet snapshot missing", RestoreWalletSnapshot(setupWalletCtx(t, cfg)))
+	cfg.snapshotLabel = "before-import"
+	require.NoError(t, RestoreWalletSnapshot(setupWalletCtx(t, cfg)))
+	assert.DeepEqual(t, want, walletPubKeys(t, &testWalletConfig{walletDir: walletDir, passwordsDir: passwordsDir}))
+	passwordFiles, err := filepath.Glob(filepath.Join(passwordsDir, "*"+direct.PasswordFileSuffix))
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(passwordFiles))
+
+	// The journal is kept and records the restore.
+	journal, err := ioutil.ReadFile(filepath.Join(walletDir, v2keymanager.Direct.String(), walletJournalFileName))
+	require.NoError(t, err)
+	assert.Equal(t, true, strings.Contains(string(journal), journalActionAccountCreated))
+	assert.Equal(t, true, strings.Contains(string(journal), journalActionSnapshotRestored))
+
+	// The wallet before the restore is kept in a snapshot of its own, which undoes the restore.
+	snapshots, err = listWalletSnapshots(filepath.Join(dataDir, walletSnapshotsDirName))
+	require.NoError(t, err)
+	require.Equal(t, 2, len(snapshots))
+	undoLabel := ""
+	for _, snapshot := range snapshots {
+		if strings.HasPrefix(snapshot.label, "before-restore-of-before-import-") {
+			undoLabel = snapshot.label
+		}
+	}
+	require.NotEqual(t, "", undoLabel)
+	cfg.snapshotLabel = undoLabel
+	require.NoError(t, RestoreWalletSnapshot(setupWalletCtx(t, cfg)))
+	assert.Equal(t, 2, len(walletPubKeys(t, &testWalletConfig{walletDir: walletDir, passwordsDir: passwordsDir})))
+}
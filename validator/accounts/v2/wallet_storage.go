@@ -0,0 +1,295 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/params"
+)
+
+// walletStorageConfigFileName is the file in the accounts directory of a wallet selecting the
+// storage its files are kept in. Wallets without one keep their files in the accounts directory.
+const walletStorageConfigFileName = "wallet-storage.json"
+
+// walletStorage keeps the files of a wallet, such as the keymanager config and the keystores of
+// its accounts, by slash separated paths relative to the accounts directory of the wallet.
+type walletStorage interface {
+	// readFile returns the contents of a file, or an error matching os.ErrNotExist if there is none.
+	readFile(ctx context.Context, name string) ([]byte, error)
+	writeFile(ctx context.Context, name string, data []byte) error
+	// removeFile removes a file, doing nothing if there is none.
+	removeFile(ctx context.Context, name string) error
+	// glob returns the names of the files of a directory matching a pattern in the syntax of
+	// path.Match, in lexical order.
+	glob(ctx context.Context, dir string, pattern string) ([]string, error)
+	// listDirs returns the names of the top-level directories.
+	listDirs(ctx context.Context) ([]string, error)
+}
+
+// transactionalStorage is storage able to write several files at once, such that either all of
+// them are written or none of them are.
+type transactionalStorage interface {
+	writeFiles(ctx context.Context, files map[string][]byte) error
+}
+
+// walletStorageConfig is the content of the storage config file of a wallet.
+type walletStorageConfig struct {
+	URL string `json:"url"`
+}
+
+// Opens the storage a storage url selects for a wallet, such as s3://bucket/prefix, bolt:// or
+// sqlite:// for a database in the accounts directory of the wallet, encrypted:// for a container
+// there encrypted with the wallet password, webdavs://host/path for a WebDAV server, or storage of
+// a scheme registered by another package.
+func openWalletStorage(accountsPath string, storageURL string) (walletStorage, error) {
+	u, err := url.Parse(storageURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse wallet storage url")
+	}
+	if opener, ok := registeredWalletStorageOpener(u.Scheme); ok {
+		return openRegisteredStorage(opener, accountsPath, u)
+	}
+	switch u.Scheme {
+	case boltScheme:
+		return newBoltStorage(accountsPath, u)
+	case sqliteScheme:
+		return newSQLiteStorage(accountsPath, u)
+	case encryptedScheme:
+		return newEncryptedStorage(accountsPath, u)
+	case webdavScheme, webdavsScheme:
+		store, err := newWebDAVStore(u)
+		if err != nil {
+			return nil, err
+		}
+		return &objectStorage{store: store, prefix: strings.Trim(u.Path, "/")}, nil
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("no bucket in wallet storage url %s", storageURL)
+	}
+	var store objectStore
+	switch u.Scheme {
+	case s3Scheme:
+		store, err = newS3Store(u.Host, u.Query())
+	case gcsScheme:
+		store, err = newGCSStore(context.Background(), u.Host, u.Query())
+	default:
+		schemes := append(
+			[]string{s3Scheme, gcsScheme, boltScheme, sqliteScheme, encryptedScheme, webdavScheme, webdavsScheme},
+			RegisteredWalletStorageSchemes()...,
+		)
+		return nil, fmt.Errorf(
+			"unsupported wallet storage url scheme %q, expected one of %s://",
+			u.Scheme,
+			strings.Join(schemes, "://, "),
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &objectStorage{store: store, prefix: strings.Trim(u.Path, "/")}, nil
+}
+
+// Opens the storage selected by the storage config file in the accounts directory of a wallet,
+// returning nil if the wallet has none.
+func readWalletStorageConfig(accountsPath string) (*walletStorageConfig, walletStorage, error) {
+	encoded, err := ioutil.ReadFile(filepath.Join(accountsPath, walletStorageConfigFileName))
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not read wallet storage config")
+	}
+	cfg := &walletStorageConfig{}
+	if err := json.Unmarshal(encoded, cfg); err != nil {
+		return nil, nil, errors.Wrap(err, "could not decode wallet storage config")
+	}
+	storage, err := openWalletStorage(accountsPath, cfg.URL)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, storage, nil
+}
+
+func writeWalletStorageConfig(accountsPath string, cfg *walletStorageConfig) error {
+	encoded, err := json.MarshalIndent(cfg, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "could not marshal wallet storage config")
+	}
+	configPath := filepath.Join(accountsPath, walletStorageConfigFileName)
+	if err := writeFileAtomic(configPath, encoded, params.BeaconIoConfig().ReadWritePermissions); err != nil {
+		return errors.Wrapf(err, "could not write %s", configPath)
+	}
+	return nil
+}
+
+// Returns the path of the database of a bolt://, sqlite:// or encrypted:// url, or the database
+// with the default file name in the accounts directory of the wallet if the url has no path.
+func walletDatabasePath(accountsPath string, u *url.URL, defaultFileName string) (string, error) {
+	if u.Host != "" {
+		return "", fmt.Errorf(
+			"wallet storage url %s has a host, %s:// urls only take the path of a database, such as %s:///path/to/%s",
+			u.String(),
+			u.Scheme,
+			u.Scheme,
+			defaultFileName,
+		)
+	}
+	if u.Path == "" {
+		return filepath.Join(accountsPath, defaultFileName), nil
+	}
+	return u.Path, nil
+}
+
+// diskStorage keeps the files of a wallet in its accounts directory. Files are replaced atomically,
+// so a crash never leaves a keystore or config partially written.
+type diskStorage struct {
+	root string
+}
+
+func (s *diskStorage) readFile(ctx context.Context, name string) ([]byte, error) {
+	return ioutil.ReadFile(filepath.Join(s.root, filepath.FromSlash(name)))
+}
+
+func (s *diskStorage) writeFile(ctx context.Context, name string, data []byte) error {
+	fullPath := filepath.Join(s.root, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(fullPath), DirectoryPermissions); err != nil {
+		return errors.Wrapf(err, "could not create path: %s", filepath.Dir(fullPath))
+	}
+	return writeFileAtomic(fullPath, data, FilePermissions)
+}
+
+func (s *diskStorage) removeFile(ctx context.Context, name string) error {
+	if err := os.Remove(filepath.Join(s.root, filepath.FromSlash(name))); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *diskStorage) glob(ctx context.Context, dir string, pattern string) ([]string, error) {
+	dirPath := filepath.Join(s.root, filepath.FromSlash(dir))
+	if err := os.MkdirAll(dirPath, DirectoryPermissions); err != nil {
+		return nil, errors.Wrapf(err, "could not create path: %s", dirPath)
+	}
+	matches, err := filepath.Glob(filepath.Join(dirPath, pattern))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(matches))
+	for i, match := range matches {
+		names[i] = filepath.Base(match)
+	}
+	return names, nil
+}
+
+func (s *diskStorage) listDirs(ctx context.Context) ([]string, error) {
+	rootDir, err := os.Open(s.root)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rootDir.Close(); err != nil {
+			log.WithField("directory", s.root).Errorf("Could not close accounts directory: %v", err)
+		}
+	}()
+	list, err := rootDir.Readdirnames(0) // 0 to read all files and folders.
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read files in directory: %s", s.root)
+	}
+	dirNames := make([]string, 0)
+	for _, item := range list {
+		ok, err := hasDir(filepath.Join(s.root, item))
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse directory: %v", err)
+		}
+		if ok {
+			dirNames = append(dirNames, item)
+		}
+	}
+	return dirNames, nil
+}
+
+// objectStore is a bucket of object storage, such as s3 or gcs.
+type objectStore interface {
+	// getObject returns the contents of an object, or os.ErrNotExist if there is none.
+	getObject(ctx context.Context, key string) ([]byte, error)
+	putObject(ctx context.Context, key string, data []byte) error
+	// deleteObject removes an object, doing nothing if there is none.
+	deleteObject(ctx context.Context, key string) error
+	// listObjects returns the keys of the objects directly under a key prefix, and the prefixes
+	// up to the next slash of the keys of the objects further under it.
+	listObjects(ctx context.Context, prefix string) (keys []string, dirPrefixes []string, err error)
+}
+
+// objectStorage keeps the files of a wallet as objects of a bucket, keyed by their path under a
+// key prefix.
+type objectStorage struct {
+	store  objectStore
+	prefix string
+}
+
+func (s *objectStorage) key(name string) string {
+	return path.Join(s.prefix, name)
+}
+
+// Returns the key prefix of the objects in a directory.
+func (s *objectStorage) dirPrefix(dir string) string {
+	if key := s.key(dir); key != "" {
+		return key + "/"
+	}
+	return ""
+}
+
+func (s *objectStorage) readFile(ctx context.Context, name string) ([]byte, error) {
+	return s.store.getObject(ctx, s.key(name))
+}
+
+func (s *objectStorage) writeFile(ctx context.Context, name string, data []byte) error {
+	return s.store.putObject(ctx, s.key(name), data)
+}
+
+func (s *objectStorage) removeFile(ctx context.Context, name string) error {
+	return s.store.deleteObject(ctx, s.key(name))
+}
+
+func (s *objectStorage) glob(ctx context.Context, dir string, pattern string) ([]string, error) {
+	prefix := s.dirPrefix(dir)
+	keys, _, err := s.store.listObjects(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0)
+	for _, key := range keys {
+		name := strings.TrimPrefix(key, prefix)
+		ok, err := path.Match(pattern, name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *objectStorage) listDirs(ctx context.Context) ([]string, error) {
+	prefix := s.dirPrefix("")
+	_, dirPrefixes, err := s.store.listObjects(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	dirNames := make([]string, len(dirPrefixes))
+	for i, dirPrefix := range dirPrefixes {
+		dirNames[i] = strings.TrimSuffix(strings.TrimPrefix(dirPrefix, prefix), "/")
+	}
+	return dirNames, nil
+}
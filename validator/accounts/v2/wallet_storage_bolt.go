@@ -0,0 +1,193 @@
+package v2
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	boltScheme = "bolt"
+	// walletDatabaseFileName is the default database of a wallet stored in bolt, in the accounts
+	// directory of the wallet.
+	walletDatabaseFileName = "wallet.db"
+)
+
+// walletFilesBucket holds the files of a wallet stored in bolt, keyed by their path.
+var walletFilesBucket = []byte("files")
+
+// boltStorage keeps the files of a wallet as records of a single bolt database, rather than as a
+// directory and a few small files for every account. The database is opened for every operation,
+// as the validator client and accounts-v2 commands may use the same wallet at once: readers share
+// it, and only writers hold it exclusively, while writing.
+type boltStorage struct {
+	dbPath string
+}
+
+// Creates the storage of a bolt:// url, of the database at the path of the url or of the default
+// database in the accounts directory of the wallet.
+func newBoltStorage(accountsPath string, u *url.URL) (*boltStorage, error) {
+	dbPath, err := walletDatabasePath(accountsPath, u, walletDatabaseFileName)
+	if err != nil {
+		return nil, err
+	}
+	return &boltStorage{dbPath: dbPath}, nil
+}
+
+func (s *boltStorage) open(readOnly bool) (*bolt.DB, error) {
+	db, err := bolt.Open(s.dbPath, params.BeaconIoConfig().ReadWritePermissions, &bolt.Options{
+		Timeout:  params.BeaconIoConfig().BoltTimeout,
+		ReadOnly: readOnly,
+	})
+	if err == bolt.ErrTimeout {
+		return nil, errors.New("cannot obtain wallet database lock, wallet may be in use by another process")
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open wallet database %s", s.dbPath)
+	}
+	return db, nil
+}
+
+func (s *boltStorage) close(db *bolt.DB) {
+	if err := db.Close(); err != nil {
+		log.WithError(err).Errorf("Could not close wallet database %s", s.dbPath)
+	}
+}
+
+// Calls fn with the files bucket of the database, which is nil until a file is written.
+func (s *boltStorage) view(fn func(bucket *bolt.Bucket) error) error {
+	if !fileExists(s.dbPath) {
+		return fn(nil)
+	}
+	db, err := s.open(true /* read only */)
+	if err != nil {
+		return err
+	}
+	defer s.close(db)
+	return db.View(func(tx *bolt.Tx) error {
+		return fn(tx.Bucket(walletFilesBucket))
+	})
+}
+
+func (s *boltStorage) readFile(ctx context.Context, name string) ([]byte, error) {
+	var data []byte
+	err := s.view(func(bucket *bolt.Bucket) error {
+		if bucket == nil {
+			return nil
+		}
+		if v := bucket.Get([]byte(name)); v != nil {
+			// Values are only valid within their transaction.
+			data = make([]byte, len(v))
+			copy(data, v)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, os.ErrNotExist
+	}
+	return data, nil
+}
+
+func (s *boltStorage) writeFile(ctx context.Context, name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(s.dbPath), DirectoryPermissions); err != nil {
+		return errors.Wrapf(err, "could not create path: %s", filepath.Dir(s.dbPath))
+	}
+	db, err := s.open(false /* read only */)
+	if err != nil {
+		return err
+	}
+	defer s.close(db)
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(walletFilesBucket)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(name), data)
+	})
+}
+
+func (s *boltStorage) removeFile(ctx context.Context, name string) error {
+	if !fileExists(s.dbPath) {
+		return nil
+	}
+	db, err := s.open(false /* read only */)
+	if err != nil {
+		return err
+	}
+	defer s.close(db)
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(walletFilesBucket)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.Delete([]byte(name))
+	})
+}
+
+func (s *boltStorage) glob(ctx context.Context, dir string, pattern string) ([]string, error) {
+	prefix := ""
+	if dir != "" {
+		prefix = dir + "/"
+	}
+	names := make([]string, 0)
+	err := s.view(func(bucket *bolt.Bucket) error {
+		if bucket == nil {
+			return nil
+		}
+		c := bucket.Cursor()
+		for k, _ := c.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, _ = c.Next() {
+			name := string(k[len(prefix):])
+			if strings.Contains(name, "/") {
+				continue
+			}
+			ok, err := path.Match(pattern, name)
+			if err != nil {
+				return err
+			}
+			if ok {
+				names = append(names, name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+func (s *boltStorage) listDirs(ctx context.Context) ([]string, error) {
+	dirNames := make([]string, 0)
+	err := s.view(func(bucket *bolt.Bucket) error {
+		if bucket == nil {
+			return nil
+		}
+		// Keys are sorted, so the files of a directory are listed one after the other.
+		return bucket.ForEach(func(k, _ []byte) error {
+			i := bytes.IndexByte(k, '/')
+			if i < 0 {
+				return nil
+			}
+			dirName := string(k[:i])
+			if len(dirNames) == 0 || dirNames[len(dirNames)-1] != dirName {
+				dirNames = append(dirNames, dirName)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dirNames, nil
+}
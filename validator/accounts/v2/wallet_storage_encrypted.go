@@ -0,0 +1,220 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+)
+
+const (
+	encryptedScheme = "encrypted"
+	// walletContainerFileName is the default container of a wallet stored encrypted, in the
+	// accounts directory of the wallet.
+	walletContainerFileName = "wallet.encrypted"
+)
+
+// encryptedWalletContainer is the content of the container file of an encrypted wallet, its files
+// encrypted as a whole with the wallet password in the same way as EIP-2335 keystores.
+type encryptedWalletContainer struct {
+	Crypto  map[string]interface{} `json:"crypto"`
+	Version uint                   `json:"version"`
+}
+
+// encryptedStorage keeps the files of a wallet in a single container file encrypted with the
+// wallet password, so not even the names of its accounts are readable on disk. The container is
+// decrypted into memory when the wallet is opened, and files are only read from memory after.
+// Writes decrypt the container again before writing it back with their files, so they keep the
+// files written by other processes since.
+type encryptedStorage struct {
+	containerPath string
+	lock          sync.RWMutex
+	password      string
+	// files are the decrypted files of the container, nil until the storage is unlocked.
+	files map[string][]byte
+}
+
+// Creates the storage of an encrypted:// url, of the container at the path of the url or of the
+// default container in the accounts directory of the wallet. It has to be unlocked with the
+// wallet password before use.
+func newEncryptedStorage(accountsPath string, u *url.URL) (*encryptedStorage, error) {
+	containerPath, err := walletDatabasePath(accountsPath, u, walletContainerFileName)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedStorage{containerPath: containerPath}, nil
+}
+
+// Decrypts the container with the wallet password, or starts an empty one if there is none yet.
+func (s *encryptedStorage) unlock(password string) error {
+	if password == "" {
+		return errors.New("encrypted wallets require a wallet password")
+	}
+	files, err := s.load(password)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.password = password
+	s.files = files
+	return nil
+}
+
+func (s *encryptedStorage) load(password string) (map[string][]byte, error) {
+	encoded, err := ioutil.ReadFile(s.containerPath)
+	if os.IsNotExist(err) {
+		return make(map[string][]byte), nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read wallet container %s", s.containerPath)
+	}
+	container := &encryptedWalletContainer{}
+	if err := json.Unmarshal(encoded, container); err != nil {
+		return nil, errors.Wrap(err, "could not decode wallet container")
+	}
+	decrypted, err := keystorev4.New().Decrypt(container.Crypto, password)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decrypt wallet container, wrong wallet password")
+	}
+	files := make(map[string][]byte)
+	if err := json.Unmarshal(decrypted, &files); err != nil {
+		return nil, errors.Wrap(err, "could not decode files of wallet container")
+	}
+	return files, nil
+}
+
+// Encrypts the files and replaces the container with them atomically.
+func (s *encryptedStorage) save(files map[string][]byte) error {
+	encoded, err := json.Marshal(files)
+	if err != nil {
+		return errors.Wrap(err, "could not encode files of wallet container")
+	}
+	encryptor := keystorev4.New()
+	cryptoFields, err := encryptor.Encrypt(encoded, s.password)
+	if err != nil {
+		return errors.Wrap(err, "could not encrypt wallet container")
+	}
+	encoded, err = json.MarshalIndent(&encryptedWalletContainer{
+		Crypto:  cryptoFields,
+		Version: encryptor.Version(),
+	}, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "could not encode wallet container")
+	}
+	if err := os.MkdirAll(filepath.Dir(s.containerPath), DirectoryPermissions); err != nil {
+		return errors.Wrapf(err, "could not create path: %s", filepath.Dir(s.containerPath))
+	}
+	if err := writeFileAtomic(s.containerPath, encoded, params.BeaconIoConfig().ReadWritePermissions); err != nil {
+		return errors.Wrapf(err, "could not write wallet container %s", s.containerPath)
+	}
+	return nil
+}
+
+func (s *encryptedStorage) readFile(ctx context.Context, name string) ([]byte, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if s.files == nil {
+		return nil, errors.New("encrypted wallet is locked")
+	}
+	data, ok := s.files[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return append([]byte{}, data...), nil
+}
+
+func (s *encryptedStorage) writeFile(ctx context.Context, name string, data []byte) error {
+	return s.writeFiles(ctx, map[string][]byte{name: data})
+}
+
+func (s *encryptedStorage) writeFiles(ctx context.Context, files map[string][]byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.files == nil {
+		return errors.New("encrypted wallet is locked")
+	}
+	current, err := s.load(s.password)
+	if err != nil {
+		return err
+	}
+	for name, data := range files {
+		current[name] = append([]byte{}, data...)
+	}
+	if err := s.save(current); err != nil {
+		return err
+	}
+	s.files = current
+	return nil
+}
+
+func (s *encryptedStorage) removeFile(ctx context.Context, name string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.files == nil {
+		return errors.New("encrypted wallet is locked")
+	}
+	current, err := s.load(s.password)
+	if err != nil {
+		return err
+	}
+	if _, ok := current[name]; !ok {
+		s.files = current
+		return nil
+	}
+	delete(current, name)
+	if err := s.save(current); err != nil {
+		return err
+	}
+	s.files = current
+	return nil
+}
+
+// Writes files to the container and re-encrypts it with a new wallet password, in a single write.
+func (s *encryptedStorage) rekey(files map[string][]byte, password string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.files == nil {
+		return errors.New("encrypted wallet is locked")
+	}
+	current, err := s.load(s.password)
+	if err != nil {
+		return err
+	}
+	for name, data := range files {
+		current[name] = append([]byte{}, data...)
+	}
+	previous := s.password
+	s.password = password
+	if err := s.save(current); err != nil {
+		s.password = previous
+		return err
+	}
+	s.files = current
+	return nil
+}
+
+func (s *encryptedStorage) glob(ctx context.Context, dir string, pattern string) ([]string, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if s.files == nil {
+		return nil, errors.New("encrypted wallet is locked")
+	}
+	return globFiles(s.files, dir, pattern)
+}
+
+func (s *encryptedStorage) listDirs(ctx context.Context) ([]string, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if s.files == nil {
+		return nil, errors.New("encrypted wallet is locked")
+	}
+	return dirsOfFiles(s.files), nil
+}
@@ -0,0 +1,96 @@
+package v2
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"os"
+
+	"cloud.google.com/go/storage"
+	"github.com/pkg/errors"
+	"google.golang.org/api/iterator"
+)
+
+// newGCSClient creates a gcs client. Credentials are resolved as application default credentials,
+// so validators running in GKE authenticate with the service account their workload identity is
+// bound to, without any key file. It is replaced in tests to avoid reaching object storage.
+var newGCSClient = func(ctx context.Context) (*storage.Client, error) {
+	return storage.NewClient(ctx)
+}
+
+// gcsStore is a gcs bucket. Objects are encrypted with keys managed by google, or with the cloud
+// kms key of the bucket or of the wallet storage url.
+type gcsStore struct {
+	bucket     *storage.BucketHandle
+	kmsKeyName string
+}
+
+// Creates the store of a gcs bucket, configured by the kms_key_name query parameter of its wallet
+// storage url, the resource name of the customer managed key its objects are encrypted with.
+func newGCSStore(ctx context.Context, bucket string, query url.Values) (*gcsStore, error) {
+	client, err := newGCSClient(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create gcs client")
+	}
+	return &gcsStore{
+		bucket:     client.Bucket(bucket),
+		kmsKeyName: query.Get("kms_key_name"),
+	}, nil
+}
+
+func (s *gcsStore) getObject(ctx context.Context, key string) ([]byte, error) {
+	r, err := s.bucket.Object(key).NewReader(ctx)
+	if err == storage.ErrObjectNotExist {
+		return nil, os.ErrNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := r.Close(); err != nil {
+			log.WithError(err).Errorf("Could not close gcs object %s", key)
+		}
+	}()
+	return ioutil.ReadAll(r)
+}
+
+func (s *gcsStore) putObject(ctx context.Context, key string, data []byte) error {
+	// Cancelling the context of the writer aborts the upload, closing the writer would commit it.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w := s.bucket.Object(key).NewWriter(ctx)
+	w.KMSKeyName = s.kmsKeyName
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+func (s *gcsStore) deleteObject(ctx context.Context, key string) error {
+	if err := s.bucket.Object(key).Delete(ctx); err != nil && err != storage.ErrObjectNotExist {
+		return err
+	}
+	return nil
+}
+
+func (s *gcsStore) listObjects(ctx context.Context, prefix string) ([]string, []string, error) {
+	keys := make([]string, 0)
+	dirPrefixes := make([]string, 0)
+	it := s.bucket.Objects(ctx, &storage.Query{Prefix: prefix, Delimiter: "/"})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "could not list objects of bucket")
+		}
+		// Objects under the delimiter are only listed by their prefix.
+		if attrs.Prefix != "" {
+			dirPrefixes = append(dirPrefixes, attrs.Prefix)
+		} else {
+			keys = append(keys, attrs.Name)
+		}
+	}
+	return keys, dirPrefixes, nil
+}
@@ -0,0 +1,109 @@
+package v2
+
+import (
+	"context"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// memoryStorage keeps the files of a wallet in memory only, such as those of a wallet loaded from
+// mounted secrets. Files written to it, such as new metadata of its accounts, are lost when the
+// process exits.
+type memoryStorage struct {
+	lock  sync.RWMutex
+	files map[string][]byte
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{files: make(map[string][]byte)}
+}
+
+func (s *memoryStorage) readFile(ctx context.Context, name string) ([]byte, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	data, ok := s.files[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return append([]byte{}, data...), nil
+}
+
+func (s *memoryStorage) writeFile(ctx context.Context, name string, data []byte) error {
+	return s.writeFiles(ctx, map[string][]byte{name: data})
+}
+
+func (s *memoryStorage) writeFiles(ctx context.Context, files map[string][]byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for name, data := range files {
+		s.files[name] = append([]byte{}, data...)
+	}
+	return nil
+}
+
+func (s *memoryStorage) removeFile(ctx context.Context, name string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.files, name)
+	return nil
+}
+
+func (s *memoryStorage) glob(ctx context.Context, dir string, pattern string) ([]string, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return globFiles(s.files, dir, pattern)
+}
+
+func (s *memoryStorage) listDirs(ctx context.Context) ([]string, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return dirsOfFiles(s.files), nil
+}
+
+// Returns the names of the files of a directory matching a pattern, of files kept by their slash
+// separated paths, in lexical order.
+func globFiles(files map[string][]byte, dir string, pattern string) ([]string, error) {
+	prefix := ""
+	if dir != "" {
+		prefix = dir + "/"
+	}
+	names := make([]string, 0)
+	for filePath := range files {
+		if !strings.HasPrefix(filePath, prefix) {
+			continue
+		}
+		name := filePath[len(prefix):]
+		if strings.Contains(name, "/") {
+			continue
+		}
+		ok, err := path.Match(pattern, name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Returns the names of the top-level directories of files kept by their slash separated paths,
+// in lexical order.
+func dirsOfFiles(files map[string][]byte) []string {
+	seen := make(map[string]bool)
+	dirNames := make([]string, 0)
+	for filePath := range files {
+		i := strings.Index(filePath, "/")
+		if i < 0 || seen[filePath[:i]] {
+			continue
+		}
+		seen[filePath[:i]] = true
+		dirNames = append(dirNames, filePath[:i])
+	}
+	sort.Strings(dirNames)
+	return dirNames
+}
@@ -0,0 +1,137 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/validator/accounts/v2/iface"
+)
+
+// WalletStorageOpener opens the storage a storage url selects for a wallet, for the scheme it is
+// registered for. The accounts directory of the wallet holds the storage config of the wallet,
+// and the database of backends keeping one next to it.
+type WalletStorageOpener func(accountsPath string, storageURL *url.URL) (iface.Storage, error)
+
+var (
+	walletStorageOpenersLock sync.RWMutex
+	walletStorageOpeners     = make(map[string]WalletStorageOpener)
+	// builtinStorageSchemes are the schemes of the storage backends of this package.
+	builtinStorageSchemes = map[string]bool{
+		s3Scheme:        true,
+		gcsScheme:       true,
+		boltScheme:      true,
+		sqliteScheme:    true,
+		encryptedScheme: true,
+		webdavScheme:    true,
+		webdavsScheme:   true,
+		"memory":        true,
+	}
+	storageSchemeRegex = regexp.MustCompile(`^[a-z][a-z0-9+.-]*$`)
+)
+
+// RegisterWalletStorage registers a storage backend of another package for the storage urls of a
+// scheme, such as vault for vault://host/path, so wallets can be created in it with
+// --wallet-storage-url and opened from it without changes to the keymanagers, which only ever see
+// the files of a wallet. Backends are registered from the init function of their package, which
+// must be imported by the binary. The schemes of the built-in backends and schemes already
+// registered cannot be registered again.
+func RegisterWalletStorage(scheme string, opener WalletStorageOpener) error {
+	if !storageSchemeRegex.MatchString(scheme) {
+		return fmt.Errorf("%q is not a valid url scheme", scheme)
+	}
+	if opener == nil {
+		return fmt.Errorf("no opener for wallet storage scheme %s", scheme)
+	}
+	if builtinStorageSchemes[scheme] {
+		return fmt.Errorf("wallet storage scheme %s is built in", scheme)
+	}
+	walletStorageOpenersLock.Lock()
+	defer walletStorageOpenersLock.Unlock()
+	if _, ok := walletStorageOpeners[scheme]; ok {
+		return fmt.Errorf("wallet storage scheme %s is already registered", scheme)
+	}
+	walletStorageOpeners[scheme] = opener
+	return nil
+}
+
+// RegisteredWalletStorageSchemes returns the schemes of the storage backends registered by other
+// packages, in lexical order.
+func RegisteredWalletStorageSchemes() []string {
+	walletStorageOpenersLock.RLock()
+	defer walletStorageOpenersLock.RUnlock()
+	schemes := make([]string, 0, len(walletStorageOpeners))
+	for scheme := range walletStorageOpeners {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+func registeredWalletStorageOpener(scheme string) (WalletStorageOpener, bool) {
+	walletStorageOpenersLock.RLock()
+	defer walletStorageOpenersLock.RUnlock()
+	opener, ok := walletStorageOpeners[scheme]
+	return opener, ok
+}
+
+// Opens registered storage, adapting it to the storage of the wallet and keeping it transactional
+// if it is.
+func openRegisteredStorage(opener WalletStorageOpener, accountsPath string, u *url.URL) (walletStorage, error) {
+	storage, err := opener(accountsPath, u)
+	if err != nil {
+		return nil, err
+	}
+	if storage == nil {
+		return nil, fmt.Errorf("wallet storage of scheme %s opened no storage", u.Scheme)
+	}
+	registered := &registeredStorage{storage: storage}
+	if _, ok := storage.(iface.TransactionalStorage); ok {
+		return &registeredTransactionalStorage{registered}, nil
+	}
+	return registered, nil
+}
+
+// registeredStorage is storage of a backend registered by another package.
+type registeredStorage struct {
+	storage iface.Storage
+}
+
+// registeredTransactionalStorage is transactional storage of a backend registered by another
+// package.
+type registeredTransactionalStorage struct {
+	*registeredStorage
+}
+
+func (s *registeredStorage) readFile(ctx context.Context, name string) ([]byte, error) {
+	return s.storage.ReadFile(ctx, name)
+}
+
+func (s *registeredStorage) writeFile(ctx context.Context, name string, data []byte) error {
+	return s.storage.WriteFile(ctx, name, data)
+}
+
+// Files are only removed from registered storage able to remove them.
+func (s *registeredStorage) removeFile(ctx context.Context, name string) error {
+	removable, ok := s.storage.(iface.RemovableStorage)
+	if !ok {
+		return errors.New("registered wallet storage cannot remove files")
+	}
+	return removable.RemoveFile(ctx, name)
+}
+
+func (s *registeredStorage) glob(ctx context.Context, dir string, pattern string) ([]string, error) {
+	return s.storage.Glob(ctx, dir, pattern)
+}
+
+func (s *registeredStorage) listDirs(ctx context.Context) ([]string, error) {
+	return s.storage.ListDirs(ctx)
+}
+
+func (s *registeredTransactionalStorage) writeFiles(ctx context.Context, files map[string][]byte) error {
+	return s.storage.(iface.TransactionalStorage).WriteFiles(ctx, files)
+}
@@ -0,0 +1,86 @@
+package v2
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	"github.com/prysmaticlabs/prysm/validator/accounts/v2/iface"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+// testStorage is storage of another package, keeping files in memory.
+type testStorage struct {
+	files *memoryStorage
+}
+
+func (s *testStorage) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	return s.files.readFile(ctx, name)
+}
+
+func (s *testStorage) WriteFile(ctx context.Context, name string, data []byte) error {
+	return s.files.writeFile(ctx, name, data)
+}
+
+func (s *testStorage) Glob(ctx context.Context, dir string, pattern string) ([]string, error) {
+	return s.files.glob(ctx, dir, pattern)
+}
+
+func (s *testStorage) ListDirs(ctx context.Context) ([]string, error) {
+	return s.files.listDirs(ctx)
+}
+
+func TestRegisterWalletStorage(t *testing.T) {
+	storage := &testStorage{files: newMemoryStorage()}
+	var opened *url.URL
+	opener := func(accountsPath string, storageURL *url.URL) (iface.Storage, error) {
+		opened = storageURL
+		return storage, nil
+	}
+	require.NoError(t, RegisterWalletStorage("test-storage", opener))
+	defer func() {
+		walletStorageOpenersLock.Lock()
+		delete(walletStorageOpeners, "test-storage")
+		walletStorageOpenersLock.Unlock()
+	}()
+	assert.ErrorContains(t, "already registered", RegisterWalletStorage("test-storage", opener))
+	assert.ErrorContains(t, "built in", RegisterWalletStorage(s3Scheme, opener))
+	assert.ErrorContains(t, "not a valid url scheme", RegisterWalletStorage("Test Storage", opener))
+	assert.ErrorContains(t, "no opener", RegisterWalletStorage("other-storage", nil))
+	assert.DeepEqual(t, []string{"test-storage"}, RegisteredWalletStorageSchemes())
+
+	walletDir, passwordsDir, _ := setupWalletAndPasswordsDir(t)
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:      walletDir,
+		passwordsDir:   passwordsDir,
+		keymanagerKind: v2keymanager.Direct,
+		storageURL:     "test-storage://host/wallet",
+	})
+	_, err := CreateWallet(cliCtx)
+	require.NoError(t, err)
+	assert.Equal(t, "/wallet", opened.Path)
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	_, ok := wallet.storage.(*registeredStorage)
+	assert.Equal(t, true, ok, "registered storage is not transactional")
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	name, err := keymanager.CreateAccount(ctx, password)
+	require.NoError(t, err)
+
+	// The files of the wallet are kept in the registered storage.
+	keystores, err := storage.Glob(ctx, name, direct.KeystoreFileName)
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(keystores))
+	wallet, err = OpenWallet(cliCtx)
+	require.NoError(t, err)
+	keymanager, err = direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(pubKeys))
+}
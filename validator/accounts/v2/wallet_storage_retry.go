@@ -0,0 +1,181 @@
+package v2
+
+import (
+	"context"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultStorageRetries is how many times a wallet retries a storage operation failing with a
+	// transient error, such as an I/O error of a wallet on a network volume, before failing.
+	defaultStorageRetries = 3
+	// defaultStorageRetryDelay is how long a wallet waits before retrying a storage operation the
+	// first time, doubling for every retry after.
+	defaultStorageRetryDelay = 100 * time.Millisecond
+)
+
+// transientErrnos are the errors of file system calls which may succeed if retried, such as
+// those of a network volume which is briefly unavailable.
+var transientErrnos = map[syscall.Errno]bool{
+	syscall.EAGAIN:       true,
+	syscall.EBUSY:        true,
+	syscall.ECONNRESET:   true,
+	syscall.EHOSTUNREACH: true,
+	syscall.EINTR:        true,
+	syscall.EIO:          true,
+	syscall.ENETDOWN:     true,
+	syscall.ENETUNREACH:  true,
+	syscall.ESTALE:       true,
+	syscall.ETIMEDOUT:    true,
+}
+
+// IsTransientStorageError returns true if an error of the storage of a wallet is transient, so
+// retrying the operation which failed may succeed. Other errors, such as a missing file or a lack
+// of permissions, are permanent and fail the same way every time.
+func IsTransientStorageError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		return transientErrnos[errno]
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return netErr.Timeout() || netErr.Temporary()
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrIoErr
+	}
+	return false
+}
+
+// storageRetryPolicy is how a wallet retries storage operations failing with transient errors,
+// backing off exponentially from its delay.
+type storageRetryPolicy struct {
+	retries uint
+	delay   time.Duration
+}
+
+var defaultStorageRetryPolicy = &storageRetryPolicy{
+	retries: defaultStorageRetries,
+	delay:   defaultStorageRetryDelay,
+}
+
+// Calls fn until it succeeds, fails with a permanent error, or has failed with transient errors
+// once more than there are retries. Permanent errors are returned as they are, so callers can
+// still check them with os.IsNotExist and such.
+func (p *storageRetryPolicy) do(ctx context.Context, fn func() error) error {
+	delay := p.delay
+	for attempt := uint(0); ; attempt++ {
+		err := fn()
+		if err == nil || !IsTransientStorageError(err) {
+			return err
+		}
+		if attempt == p.retries {
+			return errors.Wrapf(err, "wallet storage failed %d times", attempt+1)
+		}
+		log.WithError(err).WithFields(logrus.Fields{
+			"attempt": attempt + 1,
+			"delay":   delay,
+		}).Warn("Wallet storage failed with a transient error, retrying")
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// retryingStorage retries the operations of the storage of a wallet which fail with transient
+// errors according to a retry policy.
+type retryingStorage struct {
+	storage walletStorage
+	policy  *storageRetryPolicy
+}
+
+// retryingTransactionalStorage retries the operations of transactional storage, writing all of
+// the files again when a transaction fails with a transient error.
+type retryingTransactionalStorage struct {
+	*retryingStorage
+}
+
+// Wraps storage to retry its operations according to a retry policy, keeping it transactional if
+// it is.
+func withStorageRetries(storage walletStorage, policy *storageRetryPolicy) walletStorage {
+	retrying := &retryingStorage{storage: storage, policy: policy}
+	if _, ok := storage.(transactionalStorage); ok {
+		return &retryingTransactionalStorage{retrying}
+	}
+	return retrying
+}
+
+func (s *retryingStorage) readFile(ctx context.Context, name string) ([]byte, error) {
+	var data []byte
+	err := s.policy.do(ctx, func() error {
+		var err error
+		data, err = s.storage.readFile(ctx, name)
+		return err
+	})
+	return data, err
+}
+
+func (s *retryingStorage) writeFile(ctx context.Context, name string, data []byte) error {
+	return s.policy.do(ctx, func() error {
+		return s.storage.writeFile(ctx, name, data)
+	})
+}
+
+func (s *retryingStorage) removeFile(ctx context.Context, name string) error {
+	return s.policy.do(ctx, func() error {
+		return s.storage.removeFile(ctx, name)
+	})
+}
+
+func (s *retryingStorage) glob(ctx context.Context, dir string, pattern string) ([]string, error) {
+	var names []string
+	err := s.policy.do(ctx, func() error {
+		var err error
+		names, err = s.storage.glob(ctx, dir, pattern)
+		return err
+	})
+	return names, err
+}
+
+func (s *retryingStorage) listDirs(ctx context.Context) ([]string, error) {
+	var dirNames []string
+	err := s.policy.do(ctx, func() error {
+		var err error
+		dirNames, err = s.storage.listDirs(ctx)
+		return err
+	})
+	return dirNames, err
+}
+
+func (s *retryingTransactionalStorage) writeFiles(ctx context.Context, files map[string][]byte) error {
+	return s.policy.do(ctx, func() error {
+		return s.storage.(transactionalStorage).writeFiles(ctx, files)
+	})
+}
+
+// SetStorageRetries sets how many times the wallet retries a storage operation failing with a
+// transient error, waiting delay before the first retry and twice as long before every next one.
+// Wallets retry 3 times from 100ms unless set otherwise.
+func (w *Wallet) SetStorageRetries(retries uint, delay time.Duration) {
+	w.retryPolicy = &storageRetryPolicy{retries: retries, delay: delay}
+}
+
+func (w *Wallet) storageRetries() *storageRetryPolicy {
+	if w.retryPolicy == nil {
+		return defaultStorageRetryPolicy
+	}
+	return w.retryPolicy
+}
@@ -0,0 +1,76 @@
+package v2
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+)
+
+// flakyStorage fails its reads with an error a number of times before reading from its files.
+type flakyStorage struct {
+	diskStorage
+	failures int
+	err      error
+	reads    int
+}
+
+func (s *flakyStorage) readFile(ctx context.Context, name string) ([]byte, error) {
+	s.reads++
+	if s.reads <= s.failures {
+		return nil, s.err
+	}
+	return s.diskStorage.readFile(ctx, name)
+}
+
+func TestIsTransientStorageError(t *testing.T) {
+	assert.Equal(t, false, IsTransientStorageError(nil))
+	assert.Equal(t, true, IsTransientStorageError(&os.PathError{Op: "open", Path: "keystore.json", Err: syscall.EIO}))
+	assert.Equal(t, true, IsTransientStorageError(errors.Wrap(&os.PathError{Err: syscall.ESTALE}, "could not read")))
+	assert.Equal(t, false, IsTransientStorageError(&os.PathError{Op: "open", Path: "keystore.json", Err: syscall.ENOENT}))
+	assert.Equal(t, false, IsTransientStorageError(&os.PathError{Op: "open", Path: "keystore.json", Err: syscall.EACCES}))
+	assert.Equal(t, false, IsTransientStorageError(errors.New("could not decode keystore")))
+}
+
+func TestWalletStorage_Retries(t *testing.T) {
+	ctx := context.Background()
+	root := filepath.Join(testutil.TempDir(), t.Name())
+	t.Cleanup(func() {
+		assert.NoError(t, os.RemoveAll(root))
+	})
+	policy := &storageRetryPolicy{retries: 3, delay: time.Millisecond}
+	require.NoError(t, withStorageRetries(&diskStorage{root: root}, policy).writeFile(ctx, "keystore.json", []byte("hello")))
+
+	// Transient errors are retried.
+	storage := &flakyStorage{diskStorage: diskStorage{root: root}, failures: 2, err: &os.PathError{Err: syscall.EIO}}
+	data, err := withStorageRetries(storage, policy).readFile(ctx, "keystore.json")
+	require.NoError(t, err)
+	assert.DeepEqual(t, []byte("hello"), data)
+	assert.Equal(t, 3, storage.reads)
+
+	// Until there are no retries left.
+	storage = &flakyStorage{diskStorage: diskStorage{root: root}, failures: 10, err: &os.PathError{Err: syscall.EIO}}
+	_, err = withStorageRetries(storage, policy).readFile(ctx, "keystore.json")
+	assert.ErrorContains(t, "wallet storage failed 4 times", err)
+	assert.Equal(t, true, IsTransientStorageError(err))
+	assert.Equal(t, 4, storage.reads)
+
+	// Permanent errors are not retried, and returned as they are.
+	storage = &flakyStorage{diskStorage: diskStorage{root: root}, failures: 10, err: &os.PathError{Err: syscall.ENOENT}}
+	_, err = withStorageRetries(storage, policy).readFile(ctx, "keystore.json")
+	assert.Equal(t, true, os.IsNotExist(err))
+	assert.Equal(t, 1, storage.reads)
+
+	// Transactional storage stays transactional.
+	_, ok := withStorageRetries(&sqliteStorage{}, policy).(transactionalStorage)
+	assert.Equal(t, true, ok)
+	_, ok = withStorageRetries(&diskStorage{root: root}, policy).(transactionalStorage)
+	assert.Equal(t, false, ok)
+}
@@ -0,0 +1,144 @@
+package v2
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/pkg/errors"
+)
+
+// newS3Client creates the client of the s3 compatible object storage at endpoint, or of aws if
+// endpoint is empty. Credentials are resolved by the default credential chain of the aws sdk. It is
+// replaced in tests to avoid reaching object storage.
+var newS3Client = func(region string, endpoint string) (s3iface.S3API, error) {
+	cfg := aws.NewConfig()
+	if region != "" {
+		cfg = cfg.WithRegion(region)
+	}
+	if endpoint != "" {
+		// S3 compatible stores such as minio mostly serve buckets by path rather than subdomain.
+		cfg = cfg.WithEndpoint(endpoint).WithS3ForcePathStyle(true)
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *cfg,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create aws session")
+	}
+	return s3.New(sess), nil
+}
+
+// s3Store is an s3 bucket. Every object is written with server-side encryption, with keys managed
+// by s3 or by aws kms.
+type s3Store struct {
+	client               s3iface.S3API
+	bucket               string
+	serverSideEncryption string
+	kmsKeyID             string
+}
+
+// Creates the store of an s3 bucket, configured by the region, endpoint, sse and kms_key_id query
+// parameters of its wallet storage url. Objects are encrypted with keys managed by s3 unless sse
+// is aws:kms.
+func newS3Store(bucket string, query url.Values) (*s3Store, error) {
+	store := &s3Store{
+		bucket:               bucket,
+		serverSideEncryption: s3.ServerSideEncryptionAes256,
+		kmsKeyID:             query.Get("kms_key_id"),
+	}
+	switch sse := query.Get("sse"); sse {
+	case "", s3.ServerSideEncryptionAes256:
+	case s3.ServerSideEncryptionAwsKms:
+		store.serverSideEncryption = sse
+	default:
+		return nil, fmt.Errorf(
+			"unsupported server-side encryption %q, expected %s or %s",
+			sse,
+			s3.ServerSideEncryptionAes256,
+			s3.ServerSideEncryptionAwsKms,
+		)
+	}
+	if store.kmsKeyID != "" && store.serverSideEncryption != s3.ServerSideEncryptionAwsKms {
+		return nil, fmt.Errorf("kms_key_id requires sse=%s", s3.ServerSideEncryptionAwsKms)
+	}
+	client, err := newS3Client(query.Get("region"), query.Get("endpoint"))
+	if err != nil {
+		return nil, err
+	}
+	store.client = client
+	return store, nil
+}
+
+func (s *s3Store) getObject(ctx context.Context, key string) ([]byte, error) {
+	out, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+		return nil, os.ErrNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := out.Body.Close(); err != nil {
+			log.WithError(err).Errorf("Could not close s3 object %s", key)
+		}
+	}()
+	return ioutil.ReadAll(out.Body)
+}
+
+func (s *s3Store) putObject(ctx context.Context, key string, data []byte) error {
+	input := &s3.PutObjectInput{
+		Bucket:               aws.String(s.bucket),
+		Key:                  aws.String(key),
+		Body:                 bytes.NewReader(data),
+		ServerSideEncryption: aws.String(s.serverSideEncryption),
+	}
+	if s.kmsKeyID != "" {
+		input.SSEKMSKeyId = aws.String(s.kmsKeyID)
+	}
+	_, err := s.client.PutObjectWithContext(ctx, input)
+	return err
+}
+
+// Deleting an object which does not exist succeeds in s3.
+func (s *s3Store) deleteObject(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+func (s *s3Store) listObjects(ctx context.Context, prefix string) ([]string, []string, error) {
+	keys := make([]string, 0)
+	dirPrefixes := make([]string, 0)
+	err := s.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(s.bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			keys = append(keys, aws.StringValue(object.Key))
+		}
+		for _, commonPrefix := range page.CommonPrefixes {
+			dirPrefixes = append(dirPrefixes, aws.StringValue(commonPrefix.Prefix))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "could not list objects of bucket %s", s.bucket)
+	}
+	return keys, dirPrefixes, nil
+}
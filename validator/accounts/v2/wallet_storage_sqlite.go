@@ -0,0 +1,240 @@
+package v2
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/params"
+)
+
+const (
+	sqliteScheme = "sqlite"
+	// walletSQLiteFileName is the default database of a wallet stored in sqlite, in the accounts
+	// directory of the wallet.
+	walletSQLiteFileName = "wallet.sqlite"
+)
+
+// The files of a wallet stored in sqlite are the rows of a single table, so the wallet can be
+// inspected with the sqlite3 shell, such as with: SELECT path FROM files ORDER BY path;
+const createWalletFilesTable = `CREATE TABLE IF NOT EXISTS files (
+	path TEXT PRIMARY KEY NOT NULL,
+	data BLOB NOT NULL
+)`
+
+// sqliteStorage keeps the files of a wallet as the rows of a sqlite database. Every write is a
+// transaction, and the files written by a single account operation, such as the keystore and
+// deposit data of a new account, are written in the same transaction, so the wallet never holds
+// half of an account. Like bolt storage, the database is opened for every operation.
+type sqliteStorage struct {
+	dbPath string
+}
+
+// Creates the storage of a sqlite:// url, of the database at the path of the url or of the default
+// database in the accounts directory of the wallet.
+func newSQLiteStorage(accountsPath string, u *url.URL) (*sqliteStorage, error) {
+	dbPath, err := walletDatabasePath(accountsPath, u, walletSQLiteFileName)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteStorage{dbPath: dbPath}, nil
+}
+
+func (s *sqliteStorage) open(ctx context.Context) (*sql.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(s.dbPath), DirectoryPermissions); err != nil {
+		return nil, errors.Wrapf(err, "could not create path: %s", filepath.Dir(s.dbPath))
+	}
+	// Writers take the lock of the database when they begin their transaction rather than on
+	// their first write, and wait as long as for a bolt database for other writers to finish.
+	dsn := fmt.Sprintf(
+		"file:%s?_busy_timeout=%d&_txlock=immediate",
+		s.dbPath,
+		params.BeaconIoConfig().BoltTimeout.Milliseconds(),
+	)
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open wallet database %s", s.dbPath)
+	}
+	if _, err := db.ExecContext(ctx, createWalletFilesTable); err != nil {
+		s.close(db)
+		return nil, s.wrapError(err)
+	}
+	if err := os.Chmod(s.dbPath, params.BeaconIoConfig().ReadWritePermissions); err != nil {
+		s.close(db)
+		return nil, errors.Wrapf(err, "could not set permissions of wallet database %s", s.dbPath)
+	}
+	return db, nil
+}
+
+func (s *sqliteStorage) close(db *sql.DB) {
+	if err := db.Close(); err != nil {
+		log.WithError(err).Errorf("Could not close wallet database %s", s.dbPath)
+	}
+}
+
+func (s *sqliteStorage) wrapError(err error) error {
+	if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.Code == sqlite3.ErrBusy {
+		return errors.New("cannot obtain wallet database lock, wallet may be in use by another process")
+	}
+	return errors.Wrapf(err, "could not access wallet database %s", s.dbPath)
+}
+
+// Calls fn with the database, unless there is none yet and so no files.
+func (s *sqliteStorage) view(ctx context.Context, fn func(db *sql.DB) error) error {
+	if !fileExists(s.dbPath) {
+		return nil
+	}
+	db, err := s.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.close(db)
+	if err := fn(db); err != nil {
+		return s.wrapError(err)
+	}
+	return nil
+}
+
+func (s *sqliteStorage) readFile(ctx context.Context, name string) ([]byte, error) {
+	var data []byte
+	err := s.view(ctx, func(db *sql.DB) error {
+		err := db.QueryRowContext(ctx, "SELECT data FROM files WHERE path = ?", name).Scan(&data)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, os.ErrNotExist
+	}
+	return data, nil
+}
+
+func (s *sqliteStorage) writeFile(ctx context.Context, name string, data []byte) error {
+	return s.writeFiles(ctx, map[string][]byte{name: data})
+}
+
+func (s *sqliteStorage) writeFiles(ctx context.Context, files map[string][]byte) error {
+	db, err := s.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.close(db)
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return s.wrapError(err)
+	}
+	for name, data := range files {
+		if _, err := tx.ExecContext(ctx, "INSERT OR REPLACE INTO files (path, data) VALUES (?, ?)", name, data); err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.WithError(rollbackErr).Errorf("Could not roll back write to wallet database %s", s.dbPath)
+			}
+			return s.wrapError(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return s.wrapError(err)
+	}
+	return nil
+}
+
+func (s *sqliteStorage) removeFile(ctx context.Context, name string) error {
+	if !fileExists(s.dbPath) {
+		return nil
+	}
+	db, err := s.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.close(db)
+	if _, err := db.ExecContext(ctx, "DELETE FROM files WHERE path = ?", name); err != nil {
+		return s.wrapError(err)
+	}
+	return nil
+}
+
+func (s *sqliteStorage) glob(ctx context.Context, dir string, pattern string) ([]string, error) {
+	prefix := ""
+	if dir != "" {
+		prefix = dir + "/"
+	}
+	names := make([]string, 0)
+	err := s.view(ctx, func(db *sql.DB) error {
+		rows, err := db.QueryContext(
+			ctx,
+			"SELECT path FROM files WHERE substr(path, 1, ?) = ? ORDER BY path",
+			len(prefix),
+			prefix,
+		)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := rows.Close(); err != nil {
+				log.WithError(err).Errorf("Could not close rows of wallet database %s", s.dbPath)
+			}
+		}()
+		for rows.Next() {
+			var filePath string
+			if err := rows.Scan(&filePath); err != nil {
+				return err
+			}
+			name := filePath[len(prefix):]
+			if strings.Contains(name, "/") {
+				continue
+			}
+			ok, err := path.Match(pattern, name)
+			if err != nil {
+				return err
+			}
+			if ok {
+				names = append(names, name)
+			}
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+func (s *sqliteStorage) listDirs(ctx context.Context) ([]string, error) {
+	dirNames := make([]string, 0)
+	err := s.view(ctx, func(db *sql.DB) error {
+		rows, err := db.QueryContext(
+			ctx,
+			"SELECT DISTINCT substr(path, 1, instr(path, '/') - 1) AS dir FROM files WHERE instr(path, '/') > 0 ORDER BY dir",
+		)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := rows.Close(); err != nil {
+				log.WithError(err).Errorf("Could not close rows of wallet database %s", s.dbPath)
+			}
+		}()
+		for rows.Next() {
+			var dirName string
+			if err := rows.Scan(&dirName); err != nil {
+				return err
+			}
+			dirNames = append(dirNames, dirName)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dirNames, nil
+}
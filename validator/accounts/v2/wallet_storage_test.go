@@ -0,0 +1,484 @@
+package v2
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	gcs "cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"google.golang.org/api/option"
+)
+
+// fakeS3 is an in-memory s3 bucket, recording the server-side encryption of every object.
+type fakeS3 struct {
+	s3iface.S3API
+	lock       sync.Mutex
+	objects    map[string][]byte
+	encryption map[string]string
+}
+
+func (f *fakeS3) GetObjectWithContext(_ aws.Context, input *s3.GetObjectInput, _ ...request.Option) (*s3.GetObjectOutput, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	data, ok := f.objects[aws.StringValue(input.Key)]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchKey, "no such key", nil)
+	}
+	return &s3.GetObjectOutput{Body: ioutil.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func (f *fakeS3) PutObjectWithContext(_ aws.Context, input *s3.PutObjectInput, _ ...request.Option) (*s3.PutObjectOutput, error) {
+	data, err := ioutil.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.objects[aws.StringValue(input.Key)] = data
+	f.encryption[aws.StringValue(input.Key)] = aws.StringValue(input.ServerSideEncryption)
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeS3) DeleteObjectWithContext(_ aws.Context, input *s3.DeleteObjectInput, _ ...request.Option) (*s3.DeleteObjectOutput, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	delete(f.objects, aws.StringValue(input.Key))
+	delete(f.encryption, aws.StringValue(input.Key))
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func (f *fakeS3) ListObjectsV2PagesWithContext(
+	_ aws.Context,
+	input *s3.ListObjectsV2Input,
+	fn func(*s3.ListObjectsV2Output, bool) bool,
+	_ ...request.Option,
+) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	prefix := aws.StringValue(input.Prefix)
+	page := &s3.ListObjectsV2Output{}
+	commonPrefixes := make(map[string]bool)
+	keys := make([]string, 0, len(f.objects))
+	for key := range f.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if i := strings.Index(key[len(prefix):], "/"); i >= 0 {
+			commonPrefix := key[:len(prefix)+i+1]
+			if !commonPrefixes[commonPrefix] {
+				commonPrefixes[commonPrefix] = true
+				page.CommonPrefixes = append(page.CommonPrefixes, &s3.CommonPrefix{Prefix: aws.String(commonPrefix)})
+			}
+			continue
+		}
+		page.Contents = append(page.Contents, &s3.Object{Key: aws.String(key)})
+	}
+	fn(page, true)
+	return nil
+}
+
+func TestWalletStorage_S3(t *testing.T) {
+	bucket := &fakeS3{objects: make(map[string][]byte), encryption: make(map[string]string)}
+	var region, endpoint string
+	defaultClient := newS3Client
+	newS3Client = func(r string, e string) (s3iface.S3API, error) {
+		region, endpoint = r, e
+		return bucket, nil
+	}
+	defer func() {
+		newS3Client = defaultClient
+	}()
+	walletDir, passwordsDir, _ := setupWalletAndPasswordsDir(t)
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:      walletDir,
+		passwordsDir:   passwordsDir,
+		keymanagerKind: v2keymanager.Direct,
+		storageURL:     "s3://validators/wallets/mainnet?region=eu-west-1&endpoint=https://minio:9000",
+	})
+	_, err := CreateWallet(cliCtx)
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-1", region)
+	assert.Equal(t, "https://minio:9000", endpoint)
+	// Only the storage config is kept in the wallet directory.
+	_, err = ioutil.ReadFile(filepath.Join(walletDir, v2keymanager.Direct.String(), KeymanagerConfigFileName))
+	assert.ErrorContains(t, "no such file", err)
+	_, ok := bucket.objects["wallets/mainnet/"+KeymanagerConfigFileName]
+	assert.Equal(t, true, ok)
+
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	name, err := keymanager.CreateAccount(ctx, password)
+	require.NoError(t, err)
+	accountNames, err := wallet.ListDirs()
+	require.NoError(t, err)
+	assert.DeepEqual(t, []string{name}, accountNames)
+	keystoreFileName, err := wallet.FileNameAtPath(ctx, name, direct.KeystoreFileName)
+	require.NoError(t, err)
+	keystoreKey := "wallets/mainnet/" + name + "/" + keystoreFileName
+	encoded, err := wallet.ReadFileAtPath(ctx, name, direct.KeystoreFileName)
+	require.NoError(t, err)
+	assert.DeepEqual(t, bucket.objects[keystoreKey], encoded)
+	for key, sse := range bucket.encryption {
+		assert.Equal(t, s3.ServerSideEncryptionAes256, sse, "Object %s is not encrypted", key)
+	}
+	assert.ErrorContains(t, "not supported for wallets stored in", wallet.DeleteAccountFiles(ctx, name, name+direct.PasswordFileSuffix))
+	assert.ErrorContains(t, "not supported for wallets stored in", wallet.renameAccount(name, "renamed"))
+
+	// Accounts of the wallet are read back from the bucket.
+	keymanager, err = direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(pubKeys))
+}
+
+func TestWalletStorage_Bolt(t *testing.T) {
+	walletDir, passwordsDir, _ := setupWalletAndPasswordsDir(t)
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:      walletDir,
+		passwordsDir:   passwordsDir,
+		keymanagerKind: v2keymanager.Direct,
+		storageURL:     "bolt://",
+	})
+	_, err := CreateWallet(cliCtx)
+	require.NoError(t, err)
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	names := make([]string, 3)
+	for i := range names {
+		names[i], err = keymanager.CreateAccount(ctx, password)
+		require.NoError(t, err)
+	}
+	labels, err := wallet.readAccountLabels()
+	require.NoError(t, err)
+	labels.Accounts["0x01"] = map[string]string{"region": "eu"}
+	require.NoError(t, wallet.writeAccountLabels(ctx, labels))
+
+	// The accounts directory only holds the storage config and the database.
+	entries, err := ioutil.ReadDir(wallet.AccountsDir())
+	require.NoError(t, err)
+	fileNames := make([]string, len(entries))
+	for i, entry := range entries {
+		fileNames[i] = entry.Name()
+	}
+	assert.DeepEqual(t, []string{walletDatabaseFileName, walletStorageConfigFileName}, fileNames)
+
+	accountNames, err := wallet.ListDirs()
+	require.NoError(t, err)
+	sort.Strings(names)
+	assert.DeepEqual(t, names, accountNames)
+	keystores, err := wallet.files().glob(ctx, names[0], direct.KeystoreFileName)
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(keystores))
+	labels, err = wallet.readAccountLabels()
+	require.NoError(t, err)
+	assert.DeepEqual(t, map[string]string{"region": "eu"}, labels.Accounts["0x01"])
+	keymanager, err = direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, len(names), len(pubKeys))
+
+	_, err = openWalletStorage("", "bolt://host/wallet.db")
+	assert.ErrorContains(t, "only take the path of a database", err)
+}
+
+func TestWalletStorage_SQLite(t *testing.T) {
+	walletDir, passwordsDir, _ := setupWalletAndPasswordsDir(t)
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:      walletDir,
+		passwordsDir:   passwordsDir,
+		keymanagerKind: v2keymanager.Direct,
+		storageURL:     "sqlite://",
+	})
+	_, err := CreateWallet(cliCtx)
+	require.NoError(t, err)
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	cfg := direct.DefaultConfig()
+	cfg.DepositDataFormat = direct.AllDepositDataFormats
+	keymanager, err := direct.NewKeymanager(ctx, wallet, cfg)
+	require.NoError(t, err)
+	names := make([]string, 2)
+	for i := range names {
+		names[i], err = keymanager.CreateAccount(ctx, password)
+		require.NoError(t, err)
+	}
+
+	entries, err := ioutil.ReadDir(wallet.AccountsDir())
+	require.NoError(t, err)
+	fileNames := make([]string, len(entries))
+	for i, entry := range entries {
+		fileNames[i] = entry.Name()
+	}
+	assert.DeepEqual(t, []string{walletStorageConfigFileName, walletSQLiteFileName}, fileNames)
+
+	accountNames, err := wallet.ListDirs()
+	require.NoError(t, err)
+	sort.Strings(names)
+	assert.DeepEqual(t, names, accountNames)
+	// The keystore and deposit data of an account are written together.
+	for _, name := range names {
+		accountFiles, err := wallet.files().glob(ctx, name, "*")
+		require.NoError(t, err)
+		assert.Equal(t, 3, len(accountFiles))
+	}
+	keymanager, err = direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, len(names), len(pubKeys))
+
+	_, err = openWalletStorage("", "sqlite://host/wallet.sqlite")
+	assert.ErrorContains(t, "only take the path of a database", err)
+}
+
+func TestWalletStorage_SQLiteTransaction(t *testing.T) {
+	ctx := context.Background()
+	storage := &sqliteStorage{dbPath: filepath.Join(testutil.TempDir(), t.Name(), walletSQLiteFileName)}
+	defer func() {
+		assert.NoError(t, os.RemoveAll(filepath.Dir(storage.dbPath)))
+	}()
+	_, err := storage.readFile(ctx, "account/keystore.json")
+	assert.Equal(t, true, os.IsNotExist(err))
+	require.NoError(t, storage.writeFiles(ctx, map[string][]byte{
+		"account/keystore.json":    []byte("keystore"),
+		"account/deposit_data.ssz": []byte("deposit"),
+		"labels.json":              []byte("{}"),
+	}))
+	names, err := storage.glob(ctx, "account", "*")
+	require.NoError(t, err)
+	assert.DeepEqual(t, []string{"deposit_data.ssz", "keystore.json"}, names)
+	names, err = storage.glob(ctx, "", "*.json")
+	require.NoError(t, err)
+	assert.DeepEqual(t, []string{"labels.json"}, names)
+	dirNames, err := storage.listDirs(ctx)
+	require.NoError(t, err)
+	assert.DeepEqual(t, []string{"account"}, dirNames)
+
+	// A failed transaction writes none of its files, here as a file without data is not a valid row.
+	err = storage.writeFiles(ctx, map[string][]byte{
+		"account/keystore.json": []byte("replaced"),
+		"other/keystore.json":   nil,
+	})
+	assert.ErrorContains(t, "could not access wallet database", err)
+	data, err := storage.readFile(ctx, "account/keystore.json")
+	require.NoError(t, err)
+	assert.DeepEqual(t, []byte("keystore"), data)
+	dirNames, err = storage.listDirs(ctx)
+	require.NoError(t, err)
+	assert.DeepEqual(t, []string{"account"}, dirNames)
+}
+
+func TestWalletStorage_RemoveFile(t *testing.T) {
+	ctx := context.Background()
+	root := filepath.Join(testutil.TempDir(), t.Name())
+	defer func() {
+		assert.NoError(t, os.RemoveAll(root))
+	}()
+	encrypted := &encryptedStorage{containerPath: filepath.Join(root, "encrypted", walletContainerFileName)}
+	require.NoError(t, encrypted.unlock(password))
+	storages := map[string]walletStorage{
+		"disk":      &diskStorage{root: filepath.Join(root, "disk")},
+		"memory":    newMemoryStorage(),
+		"bolt":      &boltStorage{dbPath: filepath.Join(root, "bolt", walletDatabaseFileName)},
+		"sqlite":    &sqliteStorage{dbPath: filepath.Join(root, "sqlite", walletSQLiteFileName)},
+		"encrypted": encrypted,
+		"s3": &objectStorage{
+			store: &s3Store{
+				client: &fakeS3{objects: make(map[string][]byte), encryption: make(map[string]string)},
+				bucket: "validators",
+			},
+		},
+	}
+	for name, storage := range storages {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, storage.removeFile(ctx, "account/keystore.json"))
+			require.NoError(t, storage.writeFile(ctx, "account/keystore.json", []byte("keystore")))
+			require.NoError(t, storage.writeFile(ctx, "account/deposit_data.ssz", []byte("deposit")))
+			require.NoError(t, storage.removeFile(ctx, "account/keystore.json"))
+			_, err := storage.readFile(ctx, "account/keystore.json")
+			assert.Equal(t, true, os.IsNotExist(err))
+			names, err := storage.glob(ctx, "account", "*")
+			require.NoError(t, err)
+			assert.DeepEqual(t, []string{"deposit_data.ssz"}, names)
+			require.NoError(t, storage.removeFile(ctx, "account/deposit_data.ssz"))
+			names, err = storage.glob(ctx, "account", "*")
+			require.NoError(t, err)
+			assert.Equal(t, 0, len(names))
+		})
+	}
+	assert.Equal(t, ErrWalletReadOnly, withReadOnly(newMemoryStorage()).removeFile(ctx, "account/keystore.json"))
+}
+
+func TestWalletStorage_Encrypted(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFile,
+		keymanagerKind:     v2keymanager.Direct,
+		storageURL:         "encrypted://",
+	})
+	_, err := CreateWallet(cliCtx)
+	require.NoError(t, err)
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	name, err := keymanager.CreateAccount(ctx, password)
+	require.NoError(t, err)
+
+	// The accounts directory only holds the storage config and the container, which does not
+	// even give away the names of the accounts.
+	entries, err := ioutil.ReadDir(wallet.AccountsDir())
+	require.NoError(t, err)
+	fileNames := make([]string, len(entries))
+	for i, entry := range entries {
+		fileNames[i] = entry.Name()
+	}
+	assert.DeepEqual(t, []string{walletStorageConfigFileName, walletContainerFileName}, fileNames)
+	container, err := ioutil.ReadFile(filepath.Join(wallet.AccountsDir(), walletContainerFileName))
+	require.NoError(t, err)
+	assert.Equal(t, false, bytes.Contains(container, []byte(name)))
+
+	wallet, err = OpenWallet(cliCtx)
+	require.NoError(t, err)
+	accountNames, err := wallet.ListDirs()
+	require.NoError(t, err)
+	assert.DeepEqual(t, []string{name}, accountNames)
+	keymanager, err = direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(pubKeys))
+
+	wrongPasswordFile := filepath.Join(filepath.Dir(passwordFile), "wrong-password")
+	require.NoError(t, ioutil.WriteFile(wrongPasswordFile, []byte("Passw0rdz4938%%"), os.ModePerm))
+	cliCtx = setupWalletCtx(t, &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: wrongPasswordFile,
+	})
+	_, err = OpenWallet(cliCtx)
+	assert.ErrorContains(t, "wrong wallet password", err)
+
+	storage := &encryptedStorage{containerPath: filepath.Join(wallet.AccountsDir(), walletContainerFileName)}
+	_, err = storage.readFile(ctx, KeymanagerConfigFileName)
+	assert.ErrorContains(t, "encrypted wallet is locked", err)
+	assert.ErrorContains(t, "require a wallet password", storage.unlock(""))
+}
+
+func TestWalletStorage_EncryptedChangePassword(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFile,
+		keymanagerKind:     v2keymanager.Direct,
+		storageURL:         "encrypted://",
+	}
+	_, err := CreateWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	ctx := context.Background()
+	wallet, err := OpenWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	_, err = keymanager.CreateAccount(ctx, password)
+	require.NoError(t, err)
+
+	// The container is re-encrypted with the new wallet password along with the files in it.
+	newPasswordFile := filepath.Join(filepath.Dir(passwordFile), "new-wallet-password.txt")
+	require.NoError(t, ioutil.WriteFile(newPasswordFile, []byte("Sh1nyN3wWall3t!"), os.ModePerm))
+	cfg.newWalletPassword = newPasswordFile
+	require.NoError(t, ChangeWalletPassword(setupWalletCtx(t, cfg)))
+	_, err = OpenWallet(setupWalletCtx(t, cfg))
+	assert.ErrorContains(t, "wrong wallet password", err)
+	cfg.walletPasswordFile = newPasswordFile
+	wallet, err = OpenWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	keymanager, err = direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(pubKeys))
+}
+
+func TestWalletStorage_S3URL(t *testing.T) {
+	defaultClient := newS3Client
+	newS3Client = func(string, string) (s3iface.S3API, error) {
+		return &fakeS3{}, nil
+	}
+	defer func() {
+		newS3Client = defaultClient
+	}()
+	storage, err := openWalletStorage("", "s3://validators?sse=aws:kms&kms_key_id=alias/validators")
+	require.NoError(t, err)
+	objects, ok := storage.(*objectStorage)
+	require.Equal(t, true, ok)
+	assert.Equal(t, "", objects.dirPrefix(""))
+	assert.Equal(t, "account/keystore.json", objects.key("account/keystore.json"))
+	store, ok := objects.store.(*s3Store)
+	require.Equal(t, true, ok)
+	assert.Equal(t, "validators", store.bucket)
+	assert.Equal(t, s3.ServerSideEncryptionAwsKms, store.serverSideEncryption)
+	assert.Equal(t, "alias/validators", store.kmsKeyID)
+
+	_, err = openWalletStorage("", "s3://validators?kms_key_id=alias/validators")
+	assert.ErrorContains(t, "kms_key_id requires sse=aws:kms", err)
+	_, err = openWalletStorage("", "s3://validators?sse=none")
+	assert.ErrorContains(t, "unsupported server-side encryption", err)
+	_, err = openWalletStorage("", "s3:///wallet")
+	assert.ErrorContains(t, "no bucket", err)
+	_, err = openWalletStorage("", "ftp://validators/wallet")
+	assert.ErrorContains(t, "unsupported wallet storage url scheme", err)
+}
+
+func TestWalletStorage_GCSURL(t *testing.T) {
+	defaultClient := newGCSClient
+	newGCSClient = func(ctx context.Context) (*gcs.Client, error) {
+		return gcs.NewClient(ctx, option.WithoutAuthentication())
+	}
+	defer func() {
+		newGCSClient = defaultClient
+	}()
+	kmsKeyName := "projects/validators/locations/europe-west1/keyRings/wallets/cryptoKeys/mainnet"
+	storage, err := openWalletStorage("", "gs://validators/wallets/mainnet/?kms_key_name="+kmsKeyName)
+	require.NoError(t, err)
+	objects, ok := storage.(*objectStorage)
+	require.Equal(t, true, ok)
+	assert.Equal(t, "wallets/mainnet/", objects.dirPrefix(""))
+	assert.Equal(t, "wallets/mainnet/account", objects.key("account"))
+	store, ok := objects.store.(*gcsStore)
+	require.Equal(t, true, ok)
+	assert.Equal(t, kmsKeyName, store.kmsKeyName)
+}
@@ -0,0 +1,276 @@
+package v2
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// webdavsScheme selects a WebDAV server reached over https, such as the files of a Nextcloud user
+	// at webdavs://cloud.example.com/remote.php/dav/files/validator/wallet.
+	webdavsScheme = "webdavs"
+	// webdavScheme selects a WebDAV server reached over plain http, such as one on localhost.
+	webdavScheme = "webdav"
+	// WebDAVPasswordEnvVar holds the password the user of a WebDAV wallet storage url authenticates
+	// with. Credentials are never kept in the url, as it is written to the wallet storage config.
+	WebDAVPasswordEnvVar = "PRYSM_WEBDAV_PASSWORD"
+	// WebDAVTokenEnvVar holds the bearer token to authenticate to a WebDAV server with, used if the
+	// wallet storage url has no user.
+	WebDAVTokenEnvVar = "PRYSM_WEBDAV_TOKEN"
+	webdavTimeout     = 30 * time.Second
+)
+
+// newWebDAVClient creates the http client of WebDAV servers. It is replaced in tests to reach a
+// server of the test.
+var newWebDAVClient = func() *http.Client {
+	return &http.Client{Timeout: webdavTimeout}
+}
+
+// webdavStore is a WebDAV server, such as Nextcloud, keeping objects as files of their keys under
+// its root. Missing collections are created when objects are put into them.
+type webdavStore struct {
+	client   *http.Client
+	baseURL  *url.URL
+	username string
+	password string
+	token    string
+}
+
+// Creates the store of the WebDAV server of a webdav:// or webdavs:// wallet storage url,
+// authenticating as the user of the url with the password of WebDAVPasswordEnvVar, or else with
+// the bearer token of WebDAVTokenEnvVar if there is one.
+func newWebDAVStore(u *url.URL) (*webdavStore, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("no server in wallet storage url %s://%s", u.Scheme, u.Path)
+	}
+	if _, ok := u.User.Password(); ok {
+		return nil, fmt.Errorf(
+			"wallet storage url %s://%s%s holds a password, which would be written to the wallet storage config, set %s instead",
+			u.Scheme,
+			u.Host,
+			u.Path,
+			WebDAVPasswordEnvVar,
+		)
+	}
+	baseURL := &url.URL{Scheme: "https", Host: u.Host, Path: "/"}
+	if u.Scheme == webdavScheme {
+		baseURL.Scheme = "http"
+	}
+	store := &webdavStore{
+		client:  newWebDAVClient(),
+		baseURL: baseURL,
+		token:   os.Getenv(WebDAVTokenEnvVar),
+	}
+	if u.User != nil {
+		store.username = u.User.Username()
+		store.password = os.Getenv(WebDAVPasswordEnvVar)
+		if store.password == "" {
+			return nil, fmt.Errorf("no password of WebDAV user %s in %s", store.username, WebDAVPasswordEnvVar)
+		}
+	}
+	return store, nil
+}
+
+func (s *webdavStore) getObject(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer s.closeBody(resp)
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, os.ErrNotExist
+	case resp.StatusCode != http.StatusOK:
+		return nil, webdavError(resp.StatusCode, http.MethodGet, key)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (s *webdavStore) putObject(ctx context.Context, key string, data []byte) error {
+	status, err := s.put(ctx, key, data)
+	if err != nil {
+		return err
+	}
+	// Servers answer 409 Conflict to requests within a collection which does not exist.
+	if status == http.StatusConflict {
+		if err := s.makeCollection(ctx, path.Dir(key)); err != nil {
+			return err
+		}
+		status, err = s.put(ctx, key, data)
+		if err != nil {
+			return err
+		}
+	}
+	switch status {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	}
+	return webdavError(status, http.MethodPut, key)
+}
+
+func (s *webdavStore) deleteObject(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	s.closeBody(resp)
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	}
+	return webdavError(resp.StatusCode, http.MethodDelete, key)
+}
+
+func (s *webdavStore) put(ctx context.Context, key string, data []byte) (int, error) {
+	resp, err := s.do(ctx, http.MethodPut, key, nil, data)
+	if err != nil {
+		return 0, err
+	}
+	s.closeBody(resp)
+	return resp.StatusCode, nil
+}
+
+// Creates the collection of a key, and the collections above it which do not exist yet.
+func (s *webdavStore) makeCollection(ctx context.Context, key string) error {
+	status, err := s.mkcol(ctx, key)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusConflict && path.Dir(key) != key && path.Dir(key) != "." {
+		if err := s.makeCollection(ctx, path.Dir(key)); err != nil {
+			return err
+		}
+		status, err = s.mkcol(ctx, key)
+		if err != nil {
+			return err
+		}
+	}
+	switch status {
+	// Servers answer MKCOL of a collection created meanwhile with 405 Method Not Allowed.
+	case http.StatusCreated, http.StatusMethodNotAllowed:
+		return nil
+	}
+	return webdavError(status, "MKCOL", key)
+}
+
+func (s *webdavStore) mkcol(ctx context.Context, key string) (int, error) {
+	resp, err := s.do(ctx, "MKCOL", key+"/", nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	s.closeBody(resp)
+	return resp.StatusCode, nil
+}
+
+// webdavMultistatus is the response of a PROPFIND request.
+type webdavMultistatus struct {
+	Responses []struct {
+		Href string `xml:"href"`
+		// Collection is set for collections only.
+		Collection *struct{} `xml:"propstat>prop>resourcetype>collection"`
+	} `xml:"response"`
+}
+
+const webdavPropfindBody = `<?xml version="1.0" encoding="utf-8"?><propfind xmlns="DAV:"><prop><resourcetype/></prop></propfind>`
+
+func (s *webdavStore) listObjects(ctx context.Context, prefix string) ([]string, []string, error) {
+	resp, err := s.do(ctx, "PROPFIND", prefix, map[string]string{
+		"Depth":        "1",
+		"Content-Type": "application/xml; charset=utf-8",
+	}, []byte(webdavPropfindBody))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer s.closeBody(resp)
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return []string{}, []string{}, nil
+	case resp.StatusCode != http.StatusMultiStatus:
+		return nil, nil, webdavError(resp.StatusCode, "PROPFIND", prefix)
+	}
+	multistatus := &webdavMultistatus{}
+	if err := xml.NewDecoder(resp.Body).Decode(multistatus); err != nil {
+		return nil, nil, errors.Wrapf(err, "could not decode WebDAV listing of %s", prefix)
+	}
+	keys := make([]string, 0)
+	dirPrefixes := make([]string, 0)
+	for _, response := range multistatus.Responses {
+		href, err := url.Parse(response.Href)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "could not parse WebDAV href %s", response.Href)
+		}
+		key := strings.TrimPrefix(strings.TrimPrefix(href.Path, s.baseURL.Path), "/")
+		// The collection listed is part of its own listing.
+		if strings.TrimSuffix(key, "/") == strings.TrimSuffix(prefix, "/") || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if response.Collection != nil {
+			dirPrefixes = append(dirPrefixes, strings.TrimSuffix(key, "/")+"/")
+		} else {
+			keys = append(keys, key)
+		}
+	}
+	return keys, dirPrefixes, nil
+}
+
+func (s *webdavStore) do(ctx context.Context, method string, key string, header map[string]string, body []byte) (*http.Response, error) {
+	target := *s.baseURL
+	target.Path = path.Join(s.baseURL.Path, key)
+	if strings.HasSuffix(key, "/") || key == "" {
+		target.Path = strings.TrimSuffix(target.Path, "/") + "/"
+	}
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not create WebDAV %s request", method)
+	}
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+	switch {
+	case s.username != "":
+		req.SetBasicAuth(s.username, s.password)
+	case s.token != "":
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not %s %s", method, target.String())
+	}
+	return resp, nil
+}
+
+func (s *webdavStore) closeBody(resp *http.Response) {
+	if err := resp.Body.Close(); err != nil {
+		log.WithError(err).Error("Could not close WebDAV response body")
+	}
+}
+
+func webdavError(status int, method string, key string) error {
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		return fmt.Errorf(
+			"WebDAV server denied %s %s with %s, check %s or %s",
+			method,
+			key,
+			http.StatusText(status),
+			WebDAVPasswordEnvVar,
+			WebDAVTokenEnvVar,
+		)
+	}
+	return fmt.Errorf("WebDAV server failed %s %s with %d %s", method, key, status, http.StatusText(status))
+}
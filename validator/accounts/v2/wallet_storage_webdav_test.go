@@ -0,0 +1,91 @@
+package v2
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"golang.org/x/net/webdav"
+)
+
+func TestWalletStorage_WebDAV(t *testing.T) {
+	dav := &webdav.Handler{
+		Prefix:     "/remote.php/dav/files/validator",
+		FileSystem: webdav.NewMemFS(),
+		LockSystem: webdav.NewMemLS(),
+	}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, pass, ok := r.BasicAuth(); !ok || username != "validator" || pass != "dav-password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		dav.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	defaultClient := newWebDAVClient
+	newWebDAVClient = server.Client
+	defer func() {
+		newWebDAVClient = defaultClient
+	}()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	storageURL := "webdavs://validator@" + serverURL.Host + "/remote.php/dav/files/validator/wallets/mainnet"
+
+	_, err = openWalletStorage("", storageURL)
+	assert.ErrorContains(t, "no password of WebDAV user validator", err)
+	_, err = openWalletStorage("", "webdavs://validator:dav-password@"+serverURL.Host+"/wallet")
+	assert.ErrorContains(t, "holds a password", err)
+	require.NoError(t, os.Setenv(WebDAVPasswordEnvVar, "wrong-password"))
+	defer func() {
+		require.NoError(t, os.Unsetenv(WebDAVPasswordEnvVar))
+	}()
+	storage, err := openWalletStorage("", storageURL)
+	require.NoError(t, err)
+	assert.ErrorContains(t, "denied", storage.writeFile(context.Background(), "file", []byte("data")))
+	require.NoError(t, os.Setenv(WebDAVPasswordEnvVar, "dav-password"))
+
+	walletDir, passwordsDir, _ := setupWalletAndPasswordsDir(t)
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:      walletDir,
+		passwordsDir:   passwordsDir,
+		keymanagerKind: v2keymanager.Direct,
+		storageURL:     storageURL,
+	})
+	_, err = CreateWallet(cliCtx)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	require.NoError(t, err)
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	name, err := keymanager.CreateAccount(ctx, password)
+	require.NoError(t, err)
+	accountNames, err := wallet.ListDirs()
+	require.NoError(t, err)
+	assert.DeepEqual(t, []string{name}, accountNames)
+	keystoreFileName, err := wallet.FileNameAtPath(ctx, name, direct.KeystoreFileName)
+	require.NoError(t, err)
+	encoded, err := wallet.ReadFileAtPath(ctx, name, direct.KeystoreFileName)
+	require.NoError(t, err)
+	f, err := dav.FileSystem.OpenFile(ctx, "/wallets/mainnet/"+name+"/"+keystoreFileName, os.O_RDONLY, 0)
+	require.NoError(t, err)
+	stat, err := f.Stat()
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	assert.Equal(t, int64(len(encoded)), stat.Size())
+
+	// Accounts of the wallet are read back from the server.
+	keymanager, err = direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	pubKeys, err := keymanager.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(pubKeys))
+}
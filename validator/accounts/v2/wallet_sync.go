@@ -0,0 +1,330 @@
+package v2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/urfave/cli/v2"
+)
+
+// walletSyncStateFileName is the file in the accounts directory of a wallet recording the hashes
+// of the files last synced to each sync target, so files changed in a target since are told apart
+// from files changed in the wallet.
+const walletSyncStateFileName = "wallet-sync.json"
+
+// walletSyncState is the content of the sync state file of a wallet.
+type walletSyncState struct {
+	// Targets are the hex encoded sha256 hashes of the files last synced to each target, by path.
+	Targets map[string]map[string]string `json:"targets"`
+}
+
+// SyncWallet replicates the files of a wallet to the secondary location of --sync-target, a
+// directory or a storage url such as s3://bucket/prefix. Files changed in the wallet since the
+// last sync are copied over, but a target which changed on its own since, such as one holding a
+// different keystore for the same public key, has diverged and is refused. Account passwords are
+// kept outside the wallet and are not replicated.
+func SyncWallet(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	targetURL := cliCtx.String(flags.SyncTargetFlag.Name)
+	if targetURL == "" {
+		return fmt.Errorf("no sync target given with --%s", flags.SyncTargetFlag.Name)
+	}
+	target, err := openSyncTarget(targetURL, wallet.walletPassword)
+	if err != nil {
+		return err
+	}
+	copied, err := wallet.syncTo(ctx, targetURL, target)
+	if err != nil {
+		return err
+	}
+	au := aurora.NewAurora(true)
+	fmt.Printf("Synced %s files of the wallet to %s\n", au.BrightGreen(copied), targetURL)
+	return nil
+}
+
+// Opens the storage of a sync target, a directory or a storage url.
+func openSyncTarget(targetURL string, walletPassword string) (walletStorage, error) {
+	if !strings.Contains(targetURL, "://") {
+		dir, err := expandPath(targetURL)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not parse sync target")
+		}
+		return &diskStorage{root: dir}, nil
+	}
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse sync target")
+	}
+	switch u.Scheme {
+	case boltScheme, sqliteScheme, encryptedScheme:
+		if u.Path == "" {
+			return nil, fmt.Errorf("sync target %s has no path, such as %s:///path/to/wallet", targetURL, u.Scheme)
+		}
+	}
+	storage, err := openWalletStorage("", targetURL)
+	if err != nil {
+		return nil, err
+	}
+	if encrypted, ok := storage.(*encryptedStorage); ok {
+		if err := encrypted.unlock(walletPassword); err != nil {
+			return nil, errors.Wrap(err, "could not unlock sync target with the wallet password")
+		}
+	}
+	return storage, nil
+}
+
+// Copies the files of the wallet changed since the last sync to a target, returning how many were
+// copied, unless the target diverged from the wallet.
+func (w *Wallet) syncTo(ctx context.Context, targetURL string, target walletStorage) (int, error) {
+	if err := w.checkWritable(); err != nil {
+		return 0, err
+	}
+	files, err := syncedFiles(ctx, w.files())
+	if err != nil {
+		return 0, errors.Wrap(err, "could not read wallet")
+	}
+	targetFiles, err := syncedFiles(ctx, target)
+	if err != nil {
+		return 0, errors.Wrapf(err, "could not read sync target %s", targetURL)
+	}
+	state, err := w.readSyncState()
+	if err != nil {
+		return 0, err
+	}
+	base := state.Targets[targetURL]
+
+	conflicts := divergedAccounts(files, targetFiles, w.keystoreFileGlob())
+	changed := make(map[string][]byte)
+	synced := make(map[string]string, len(files))
+	paths := make([]string, 0, len(files)+len(targetFiles))
+	for filePath := range files {
+		paths = append(paths, filePath)
+	}
+	for filePath := range targetFiles {
+		if _, ok := files[filePath]; !ok {
+			paths = append(paths, filePath)
+		}
+	}
+	sort.Strings(paths)
+	for _, filePath := range paths {
+		data, inWallet := files[filePath]
+		targetHash, inTarget := hashOf(targetFiles, filePath)
+		baseHash, inBase := base[filePath]
+		switch {
+		case !inWallet && inBase && targetHash == baseHash:
+			// Files removed from the wallet are kept in the target, which only ever gains files.
+			log.WithField("path", filePath).Warn("File was removed from the wallet, keeping it in the sync target")
+		case !inWallet:
+			conflicts = append(conflicts, fmt.Sprintf("%s is only in the sync target", filePath))
+		case inTarget && targetHash == hashOfData(data):
+			synced[filePath] = targetHash
+		case !inTarget && !inBase, inTarget && inBase && targetHash == baseHash:
+			changed[filePath] = data
+			synced[filePath] = hashOfData(data)
+		default:
+			conflicts = append(conflicts, fmt.Sprintf("%s changed in the sync target since the last sync", filePath))
+		}
+	}
+	if len(conflicts) > 0 {
+		return 0, fmt.Errorf(
+			"sync target %s has diverged from the wallet, refusing to sync: %s",
+			targetURL,
+			strings.Join(conflicts, "; "),
+		)
+	}
+	if err := writeSyncedFiles(ctx, target, changed); err != nil {
+		return 0, errors.Wrapf(err, "could not write to sync target %s", targetURL)
+	}
+	if state.Targets == nil {
+		state.Targets = make(map[string]map[string]string)
+	}
+	state.Targets[targetURL] = synced
+	if err := w.writeSyncState(state); err != nil {
+		return 0, err
+	}
+	return len(changed), nil
+}
+
+// Returns the files of the storage of a wallet which are synced by path: the files of its root and
+// of its account directories. Files only ever kept on disk, such as the lock of the wallet and the
+// directories of archived, deleted and tombstoned accounts, are not synced.
+func syncedFiles(ctx context.Context, storage walletStorage) (map[string][]byte, error) {
+	dirNames, err := storage.listDirs(ctx)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	dirs := make(map[string]bool, len(dirNames))
+	for _, dirName := range dirNames {
+		dirs[dirName] = true
+	}
+	files := make(map[string][]byte)
+	rootNames, err := storage.glob(ctx, "", "*")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range rootNames {
+		if dirs[name] || !isSyncedFile(name) {
+			continue
+		}
+		data, err := storage.readFile(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = data
+	}
+	for _, dirName := range dirNames {
+		if dirName == archiveDirName || dirName == tombstoneDirName || strings.HasPrefix(dirName, ".") {
+			continue
+		}
+		names, err := storage.glob(ctx, dirName, "*")
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if !isSyncedFile(name) {
+				continue
+			}
+			filePath := path.Join(dirName, name)
+			data, err := storage.readFile(ctx, filePath)
+			if err != nil {
+				return nil, err
+			}
+			files[filePath] = data
+		}
+	}
+	return files, nil
+}
+
+func isSyncedFile(name string) bool {
+	switch name {
+	case walletLockFileName, walletSyncStateFileName, walletStorageConfigFileName:
+		return false
+	}
+	return !strings.HasPrefix(name, ".") && !strings.HasSuffix(name, tmpFileSuffix)
+}
+
+// Returns the public keys held by accounts of different names in the wallet and in the target.
+// Different keystores of accounts of the same name are conflicts of their files.
+func divergedAccounts(files map[string][]byte, targetFiles map[string][]byte, keystoreFileGlob string) []string {
+	accounts := keystoreAccounts(files, keystoreFileGlob)
+	targetAccounts := keystoreAccounts(targetFiles, keystoreFileGlob)
+	conflicts := make([]string, 0)
+	pubKeys := make([]string, 0, len(accounts))
+	for pubKey := range accounts {
+		pubKeys = append(pubKeys, pubKey)
+	}
+	sort.Strings(pubKeys)
+	for _, pubKey := range pubKeys {
+		targetAccount, ok := targetAccounts[pubKey]
+		if ok && targetAccount != accounts[pubKey] {
+			conflicts = append(conflicts, fmt.Sprintf(
+				"public key %s is held by account %s in the wallet but by account %s in the sync target",
+				pubKey,
+				accounts[pubKey],
+				targetAccount,
+			))
+		}
+	}
+	return conflicts
+}
+
+// Returns the account of each public key of the keystores of non-HD wallet files.
+func keystoreAccounts(files map[string][]byte, keystoreFileGlob string) map[string]string {
+	accounts := make(map[string]string)
+	for filePath, data := range files {
+		dir, name := path.Split(filePath)
+		if dir == "" {
+			continue
+		}
+		if ok, err := path.Match(keystoreFileGlob, name); err != nil || !ok {
+			continue
+		}
+		keystore := &v2keymanager.Keystore{}
+		if err := json.Unmarshal(data, keystore); err != nil || keystore.Pubkey == "" {
+			continue
+		}
+		accounts[strings.TrimPrefix(keystore.Pubkey, "0x")] = strings.TrimSuffix(dir, "/")
+	}
+	return accounts
+}
+
+func writeSyncedFiles(ctx context.Context, target walletStorage, files map[string][]byte) error {
+	if len(files) == 0 {
+		return nil
+	}
+	if storage, ok := target.(transactionalStorage); ok {
+		return storage.writeFiles(ctx, files)
+	}
+	paths := make([]string, 0, len(files))
+	for filePath := range files {
+		paths = append(paths, filePath)
+	}
+	sort.Strings(paths)
+	for _, filePath := range paths {
+		if err := target.writeFile(ctx, filePath, files[filePath]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hashOf(files map[string][]byte, filePath string) (string, bool) {
+	data, ok := files[filePath]
+	if !ok {
+		return "", false
+	}
+	return hashOfData(data), true
+}
+
+func hashOfData(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+func (w *Wallet) readSyncState() (*walletSyncState, error) {
+	encoded, err := ioutil.ReadFile(filepath.Join(w.accountsPath, walletSyncStateFileName))
+	if os.IsNotExist(err) {
+		return &walletSyncState{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read wallet sync state")
+	}
+	state := &walletSyncState{}
+	if err := json.Unmarshal(encoded, state); err != nil {
+		return nil, errors.Wrap(err, "could not decode wallet sync state")
+	}
+	return state, nil
+}
+
+func (w *Wallet) writeSyncState(state *walletSyncState) error {
+	encoded, err := json.MarshalIndent(state, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "could not encode wallet sync state")
+	}
+	statePath := filepath.Join(w.accountsPath, walletSyncStateFileName)
+	if err := writeFileAtomic(statePath, encoded, params.BeaconIoConfig().ReadWritePermissions); err != nil {
+		return errors.Wrapf(err, "could not write %s", statePath)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package v2
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestWallet_SyncTo(t *testing.T) {
+	walletDir, passwordsDir, _ := setupWalletAndPasswordsDir(t)
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:      walletDir,
+		passwordsDir:   passwordsDir,
+		keymanagerKind: v2keymanager.Direct,
+	})
+	wallet, err := NewWallet(cliCtx, v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	name, err := keymanager.CreateAccount(ctx, password)
+	require.NoError(t, err)
+
+	targetDir := filepath.Join(testutil.TempDir(), t.Name())
+	t.Cleanup(func() {
+		assert.NoError(t, os.RemoveAll(targetDir))
+	})
+	target, err := openSyncTarget(targetDir, "")
+	require.NoError(t, err)
+	copied, err := wallet.syncTo(ctx, targetDir, target)
+	require.NoError(t, err)
+	assert.Equal(t, true, copied > 0)
+	keystoreFileName, err := wallet.FileNameAtPath(ctx, name, direct.KeystoreFileName)
+	require.NoError(t, err)
+	assert.Equal(t, true, fileExists(filepath.Join(targetDir, name, keystoreFileName)))
+	assert.Equal(t, false, fileExists(filepath.Join(targetDir, walletLockFileName)))
+
+	// Only files changed since are copied.
+	newName, err := keymanager.CreateAccount(ctx, password)
+	require.NoError(t, err)
+	copied, err = wallet.syncTo(ctx, targetDir, target)
+	require.NoError(t, err)
+	assert.Equal(t, true, copied > 0)
+	newKeystoreFileName, err := wallet.FileNameAtPath(ctx, newName, direct.KeystoreFileName)
+	require.NoError(t, err)
+	assert.Equal(t, true, fileExists(filepath.Join(targetDir, newName, newKeystoreFileName)))
+	copied, err = wallet.syncTo(ctx, targetDir, target)
+	require.NoError(t, err)
+	assert.Equal(t, 0, copied)
+
+	// A target holding a keystore for a public key under another name has diverged.
+	keystore, err := wallet.ReadFileAtPath(ctx, name, direct.KeystoreFileName)
+	require.NoError(t, err)
+	require.NoError(t, target.writeFile(ctx, "other-account/"+keystoreFileName, keystore))
+	_, err = wallet.syncTo(ctx, targetDir, target)
+	assert.ErrorContains(t, "is held by account "+name+" in the wallet but by account other-account", err)
+	require.NoError(t, os.RemoveAll(filepath.Join(targetDir, "other-account")))
+
+	// As has a target whose files changed since the last sync.
+	require.NoError(t, target.writeFile(ctx, name+"/"+keystoreFileName, []byte("{}")))
+	_, err = wallet.syncTo(ctx, targetDir, target)
+	assert.ErrorContains(t, "changed in the sync target since the last sync", err)
+}
@@ -1,6 +1,7 @@
 package v2
 
 import (
+	"context"
 	"crypto/rand"
 	"flag"
 	"fmt"
@@ -13,6 +14,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prysmaticlabs/prysm/shared/cmd"
 	"github.com/prysmaticlabs/prysm/shared/params"
 	"github.com/prysmaticlabs/prysm/shared/testutil"
 	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
@@ -36,7 +38,67 @@ type testWalletConfig struct {
 	accountsToExport    string
 	walletPasswordFile  string
 	accountPasswordFile string
+	exportPasswordFile  string
+	dataDir             string
+	v1Keymanager        string
+	v1KeymanagerOpts    string
+	importFormat        string
+	ethdoAccounts       string
+	slashingProtection  string
+	genesisRoot         string
+	depositDataDir      string
+	privateKeyFile      string
+	exportFormat        string
+	keystoresURL        string
+	backupPasswordFile  string
+	backupFile          string
+	mergeRestore        bool
+	forceRestore        bool
+	reimport            bool
+	keystorePasswords   string
+	keystoresSHA256     string
+	sseKeyFile          string
+	decryptionKeyFile   string
+	mnemonicFile        string
+	withdrawalMnemonic  string
+	withdrawalCreds     string
+	withdrawalPassword  string
+	withdrawalDir       string
+	newAccountPassword  string
+	newWalletPassword   string
+	accountNotes        string
+	tekuKeys            []string
+	tekuPasswords       []string
+	includePubKeys      []string
+	excludePubKeys      []string
+	deletePublicKeys    []string
+	withLabels          []string
+	addLabels           []string
+	removeLabels        []string
+	listSort            string
+	listPage            int
+	listPageSize        int
+	listCount           bool
 	numAccounts         int64
+	createCount         int
+	accountIndices      string
+	archiveExited       bool
+	dryRun              bool
+	deactivateAfter     string
+	deactivateEpoch     string
+	cancelDeactivation  bool
+	trashRetention      time.Duration
+	pubKeysFile         string
+	storageURL          string
+	expandWallet        bool
+	removeOrphans       bool
+	snapshotsDir        string
+	snapshotLabel       string
+	accountsKeystoreN   int
+	accountsKeystoreP   int
+	approvalOperators   []string
+	approvalTokens      []string
+	totpCode            string
 	keymanagerKind      v2keymanager.Kind
 }
 
@@ -51,21 +113,194 @@ func setupWalletCtx(
 	set.String(flags.KeysDirFlag.Name, cfg.keysDir, "")
 	set.String(flags.KeymanagerKindFlag.Name, cfg.keymanagerKind.String(), "")
 	set.String(flags.BackupDirFlag.Name, cfg.exportDir, "")
-	set.String(flags.AccountsFlag.Name, cfg.accountsToExport, "")
+	set.Var(cli.NewStringSlice(), flags.AccountsFlag.Name, "")
 	set.String(flags.WalletPasswordFileFlag.Name, cfg.walletPasswordFile, "")
 	set.String(flags.AccountPasswordFileFlag.Name, cfg.accountPasswordFile, "")
+	set.String(flags.ExportPasswordFileFlag.Name, cfg.exportPasswordFile, "")
+	set.String(cmd.DataDirFlag.Name, cfg.dataDir, "")
+	set.String(flags.KeyManager.Name, cfg.v1Keymanager, "")
+	set.String(flags.KeyManagerOpts.Name, cfg.v1KeymanagerOpts, "")
+	set.String(flags.ImportFormatFlag.Name, cfg.importFormat, "")
+	set.Var(cli.NewStringSlice(), flags.EthdoAccountsFlag.Name, "")
+	set.Var(cli.NewStringSlice(), flags.TekuKeysFlag.Name, "")
+	set.Var(cli.NewStringSlice(), flags.TekuPasswordsFlag.Name, "")
+	set.Var(cli.NewStringSlice(), flags.IncludePubKeysFlag.Name, "")
+	set.Var(cli.NewStringSlice(), flags.ExcludePubKeysFlag.Name, "")
+	set.Var(cli.NewStringSlice(), flags.DeletePublicKeysFlag.Name, "")
+	set.Var(cli.NewStringSlice(), flags.WithLabelsFlag.Name, "")
+	set.Var(cli.NewStringSlice(), flags.AddLabelsFlag.Name, "")
+	set.Var(cli.NewStringSlice(), flags.RemoveLabelsFlag.Name, "")
+	set.String(flags.ListSortFlag.Name, cfg.listSort, "")
+	set.Int(flags.ListPageFlag.Name, cfg.listPage, "")
+	set.Int(flags.ListPageSizeFlag.Name, cfg.listPageSize, "")
+	set.Bool(flags.ListCountFlag.Name, cfg.listCount, "")
+	set.String(flags.SlashingProtectionFileFlag.Name, cfg.slashingProtection, "")
+	set.String(flags.GenesisValidatorsRootFlag.Name, cfg.genesisRoot, "")
+	set.String(flags.DepositDataOutputDirFlag.Name, cfg.depositDataDir, "")
+	set.String(flags.PrivateKeyFileFlag.Name, cfg.privateKeyFile, "")
+	set.String(flags.ExportFormatFlag.Name, cfg.exportFormat, "")
+	set.String(flags.KeystoresURLFlag.Name, cfg.keystoresURL, "")
+	set.String(flags.BackupPasswordFileFlag.Name, cfg.backupPasswordFile, "")
+	set.String(flags.KeystoresSHA256Flag.Name, cfg.keystoresSHA256, "")
+	set.String(flags.KeystoresSSEKeyFileFlag.Name, cfg.sseKeyFile, "")
+	set.String(flags.KeystoresDecryptionKeyFileFlag.Name, cfg.decryptionKeyFile, "")
+	set.String(flags.BackupFileFlag.Name, cfg.backupFile, "")
+	set.Bool(flags.MergeRestoreFlag.Name, cfg.mergeRestore, "")
+	set.Bool(flags.ForceRestoreFlag.Name, cfg.forceRestore, "")
+	set.Bool(flags.ReimportFlag.Name, cfg.reimport, "")
+	set.String(flags.KeystorePasswordsFileFlag.Name, cfg.keystorePasswords, "")
+	set.Bool(flags.SkipPrivateKeyImportConfirmFlag.Name, true, "")
+	set.Bool(flags.SkipConvertConfirmFlag.Name, true, "")
+	set.Bool(flags.SkipDeleteConfirmFlag.Name, true, "")
+	set.Bool(flags.SkipExitConfirmFlag.Name, true, "")
+	set.String(flags.MnemonicFileFlag.Name, cfg.mnemonicFile, "")
+	set.Bool(flags.StoreWithdrawalKeyFlag.Name, false, "")
+	// Withdrawal keys are only written to keystores by tests giving a directory for them.
+	set.Bool(flags.PrintWithdrawalKeyFlag.Name, cfg.withdrawalDir == "", "")
+	set.String(flags.WithdrawalMnemonicFileFlag.Name, cfg.withdrawalMnemonic, "")
+	set.String(flags.WithdrawalCredentialsFlag.Name, cfg.withdrawalCreds, "")
+	set.String(flags.WithdrawalPasswordFileFlag.Name, cfg.withdrawalPassword, "")
+	set.String(flags.WithdrawalKeystoresDirFlag.Name, cfg.withdrawalDir, "")
 	set.Bool(flags.SkipMnemonicConfirmFlag.Name, true, "")
 	set.Int64(flags.NumAccountsFlag.Name, cfg.numAccounts, "")
+	set.Int(flags.CreateCountFlag.Name, 1, "")
+	set.String(flags.AccountIndicesFlag.Name, cfg.accountIndices, "")
+	set.Bool(flags.ArchiveExitedFlag.Name, cfg.archiveExited, "")
+	set.String(flags.NewAccountPasswordFileFlag.Name, cfg.newAccountPassword, "")
+	set.String(flags.NewWalletPasswordFileFlag.Name, cfg.newWalletPassword, "")
+	set.String(flags.AccountNotesFlag.Name, cfg.accountNotes, "")
+	set.Bool(flags.DryRunFlag.Name, cfg.dryRun, "")
+	set.String(flags.DeactivateAfterFlag.Name, "", "")
+	set.Uint64(flags.DeactivateAfterEpochFlag.Name, 0, "")
+	set.Bool(flags.CancelDeactivationFlag.Name, cfg.cancelDeactivation, "")
+	set.Duration(flags.TrashRetentionFlag.Name, flags.TrashRetentionFlag.Value, "")
+	set.String(flags.PubKeysFileFlag.Name, "", "")
+	set.String(flags.WalletStorageURLFlag.Name, cfg.storageURL, "")
+	set.Bool(flags.ExpandWalletFlag.Name, cfg.expandWallet, "")
+	set.Bool(flags.RemoveOrphansFlag.Name, cfg.removeOrphans, "")
+	set.String(flags.SnapshotsDirFlag.Name, cfg.snapshotsDir, "")
+	set.String(flags.SnapshotLabelFlag.Name, cfg.snapshotLabel, "")
+	set.Int(flags.AccountsKeystoreScryptNFlag.Name, 0, "")
+	set.Int(flags.AccountsKeystoreScryptRFlag.Name, 0, "")
+	set.Int(flags.AccountsKeystoreScryptPFlag.Name, 0, "")
+	set.Var(cli.NewStringSlice(), flags.ApprovalOperatorsFlag.Name, "")
+	set.Int(flags.ApprovalsRequiredFlag.Name, flags.ApprovalsRequiredFlag.Value, "")
+	set.Var(cli.NewStringSlice(), flags.ApprovalTokensFlag.Name, "")
+	set.String(flags.TOTPCodeFlag.Name, "", "")
 	assert.NoError(tb, set.Set(flags.WalletDirFlag.Name, cfg.walletDir))
 	assert.NoError(tb, set.Set(flags.WalletPasswordsDirFlag.Name, cfg.passwordsDir))
 	assert.NoError(tb, set.Set(flags.KeysDirFlag.Name, cfg.keysDir))
 	assert.NoError(tb, set.Set(flags.KeymanagerKindFlag.Name, cfg.keymanagerKind.String()))
 	assert.NoError(tb, set.Set(flags.BackupDirFlag.Name, cfg.exportDir))
-	assert.NoError(tb, set.Set(flags.AccountsFlag.Name, cfg.accountsToExport))
+	if cfg.accountsToExport != "" {
+		assert.NoError(tb, set.Set(flags.AccountsFlag.Name, cfg.accountsToExport))
+	}
 	assert.NoError(tb, set.Set(flags.WalletPasswordFileFlag.Name, cfg.walletPasswordFile))
 	assert.NoError(tb, set.Set(flags.AccountPasswordFileFlag.Name, cfg.accountPasswordFile))
+	assert.NoError(tb, set.Set(flags.ExportPasswordFileFlag.Name, cfg.exportPasswordFile))
+	assert.NoError(tb, set.Set(cmd.DataDirFlag.Name, cfg.dataDir))
+	assert.NoError(tb, set.Set(flags.KeyManager.Name, cfg.v1Keymanager))
+	assert.NoError(tb, set.Set(flags.KeyManagerOpts.Name, cfg.v1KeymanagerOpts))
+	assert.NoError(tb, set.Set(flags.ImportFormatFlag.Name, cfg.importFormat))
+	if cfg.ethdoAccounts != "" {
+		assert.NoError(tb, set.Set(flags.EthdoAccountsFlag.Name, cfg.ethdoAccounts))
+	}
+	for _, keys := range cfg.tekuKeys {
+		assert.NoError(tb, set.Set(flags.TekuKeysFlag.Name, keys))
+	}
+	for _, passwords := range cfg.tekuPasswords {
+		assert.NoError(tb, set.Set(flags.TekuPasswordsFlag.Name, passwords))
+	}
+	for _, pubKey := range cfg.includePubKeys {
+		assert.NoError(tb, set.Set(flags.IncludePubKeysFlag.Name, pubKey))
+	}
+	for _, pubKey := range cfg.excludePubKeys {
+		assert.NoError(tb, set.Set(flags.ExcludePubKeysFlag.Name, pubKey))
+	}
+	for _, pubKey := range cfg.deletePublicKeys {
+		assert.NoError(tb, set.Set(flags.DeletePublicKeysFlag.Name, pubKey))
+	}
+	for _, label := range cfg.withLabels {
+		assert.NoError(tb, set.Set(flags.WithLabelsFlag.Name, label))
+	}
+	for _, label := range cfg.addLabels {
+		assert.NoError(tb, set.Set(flags.AddLabelsFlag.Name, label))
+	}
+	for _, name := range cfg.removeLabels {
+		assert.NoError(tb, set.Set(flags.RemoveLabelsFlag.Name, name))
+	}
+	assert.NoError(tb, set.Set(flags.SlashingProtectionFileFlag.Name, cfg.slashingProtection))
+	assert.NoError(tb, set.Set(flags.GenesisValidatorsRootFlag.Name, cfg.genesisRoot))
+	assert.NoError(tb, set.Set(flags.DepositDataOutputDirFlag.Name, cfg.depositDataDir))
+	if cfg.privateKeyFile != "" {
+		assert.NoError(tb, set.Set(flags.PrivateKeyFileFlag.Name, cfg.privateKeyFile))
+	}
+	assert.NoError(tb, set.Set(flags.SkipPrivateKeyImportConfirmFlag.Name, "true"))
+	assert.NoError(tb, set.Set(flags.ExportFormatFlag.Name, cfg.exportFormat))
+	assert.NoError(tb, set.Set(flags.KeystoresURLFlag.Name, cfg.keystoresURL))
+	if cfg.backupPasswordFile != "" {
+		assert.NoError(tb, set.Set(flags.BackupPasswordFileFlag.Name, cfg.backupPasswordFile))
+	}
+	assert.NoError(tb, set.Set(flags.KeystoresSHA256Flag.Name, cfg.keystoresSHA256))
+	if cfg.mnemonicFile != "" {
+		assert.NoError(tb, set.Set(flags.MnemonicFileFlag.Name, cfg.mnemonicFile))
+	}
+	if cfg.withdrawalMnemonic != "" {
+		assert.NoError(tb, set.Set(flags.WithdrawalMnemonicFileFlag.Name, cfg.withdrawalMnemonic))
+	}
+	if cfg.withdrawalCreds != "" {
+		assert.NoError(tb, set.Set(flags.WithdrawalCredentialsFlag.Name, cfg.withdrawalCreds))
+	}
+	if cfg.withdrawalPassword != "" {
+		assert.NoError(tb, set.Set(flags.WithdrawalPasswordFileFlag.Name, cfg.withdrawalPassword))
+	}
+	if cfg.newAccountPassword != "" {
+		assert.NoError(tb, set.Set(flags.NewAccountPasswordFileFlag.Name, cfg.newAccountPassword))
+	}
+	if cfg.newWalletPassword != "" {
+		assert.NoError(tb, set.Set(flags.NewWalletPasswordFileFlag.Name, cfg.newWalletPassword))
+	}
+	if cfg.accountNotes != "" {
+		assert.NoError(tb, set.Set(flags.AccountNotesFlag.Name, cfg.accountNotes))
+	}
+	if cfg.withdrawalDir != "" {
+		assert.NoError(tb, set.Set(flags.WithdrawalKeystoresDirFlag.Name, cfg.withdrawalDir))
+	}
+	if cfg.pubKeysFile != "" {
+		assert.NoError(tb, set.Set(flags.PubKeysFileFlag.Name, cfg.pubKeysFile))
+	}
+	if cfg.trashRetention != 0 {
+		assert.NoError(tb, set.Set(flags.TrashRetentionFlag.Name, cfg.trashRetention.String()))
+	}
+	if cfg.deactivateAfter != "" {
+		assert.NoError(tb, set.Set(flags.DeactivateAfterFlag.Name, cfg.deactivateAfter))
+	}
+	if cfg.deactivateEpoch != "" {
+		assert.NoError(tb, set.Set(flags.DeactivateAfterEpochFlag.Name, cfg.deactivateEpoch))
+	}
 	assert.NoError(tb, set.Set(flags.SkipMnemonicConfirmFlag.Name, "true"))
 	assert.NoError(tb, set.Set(flags.NumAccountsFlag.Name, strconv.Itoa(int(cfg.numAccounts))))
+	if cfg.createCount != 0 {
+		assert.NoError(tb, set.Set(flags.CreateCountFlag.Name, strconv.Itoa(cfg.createCount)))
+	}
+	if cfg.accountIndices != "" {
+		assert.NoError(tb, set.Set(flags.AccountIndicesFlag.Name, cfg.accountIndices))
+	}
+	if cfg.accountsKeystoreN != 0 {
+		assert.NoError(tb, set.Set(flags.AccountsKeystoreScryptNFlag.Name, strconv.Itoa(cfg.accountsKeystoreN)))
+	}
+	if cfg.accountsKeystoreP != 0 {
+		assert.NoError(tb, set.Set(flags.AccountsKeystoreScryptPFlag.Name, strconv.Itoa(cfg.accountsKeystoreP)))
+	}
+	for _, operator := range cfg.approvalOperators {
+		assert.NoError(tb, set.Set(flags.ApprovalOperatorsFlag.Name, operator))
+	}
+	for _, tokenFile := range cfg.approvalTokens {
+		assert.NoError(tb, set.Set(flags.ApprovalTokensFlag.Name, tokenFile))
+	}
+	if cfg.totpCode != "" {
+		assert.NoError(tb, set.Set(flags.TOTPCodeFlag.Name, cfg.totpCode))
+	}
 	return cli.NewContext(&app, set, nil)
 }
 
@@ -103,6 +338,28 @@ func TestCreateAndReadWallet(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestOpenWalletAtPath(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	cliCtx := setupWalletCtx(t, &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFile,
+		keymanagerKind:     v2keymanager.Derived,
+	})
+	_, err := CreateWallet(cliCtx)
+	require.NoError(t, err)
+
+	wallet, err := OpenWalletAtPath(walletDir, passwordFile, false /* readOnly */)
+	require.NoError(t, err)
+	assert.Equal(t, v2keymanager.Derived, wallet.KeymanagerKind())
+	assert.Equal(t, password, wallet.walletPassword)
+	_, err = wallet.InitializeKeymanager(context.Background(), true /* skipMnemonicConfirm */)
+	require.NoError(t, err)
+
+	_, err = OpenWalletAtPath(filepath.Join(walletDir, "missing"), passwordFile, false /* readOnly */)
+	assert.ErrorContains(t, ErrNoWalletFound.Error(), err)
+}
+
 func TestAccountTimestamp(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -141,6 +398,19 @@ func TestAccountTimestamp(t *testing.T) {
 	}
 }
 
+func TestWallet_KeystoreFileTimestamp(t *testing.T) {
+	w := &Wallet{keystoreNameFormat: "validator_%d_keystore.json"}
+	assert.Equal(t, "validator_*_keystore.json", w.keystoreFileGlob())
+	assert.Equal(t, "validator_1234567_keystore.json", fmt.Sprintf(w.keystoreFileNameFormat(), 1234567))
+	got, err := w.keystoreFileTimestamp("validator_1234567_keystore.json")
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(1234567, 0), got)
+	_, err = w.keystoreFileTimestamp("keystore-1234567.json")
+	assert.ErrorContains(t, "is not of keystore file name format", err)
+	_, err = w.keystoreFileTimestamp("validator_now_keystore.json")
+	assert.ErrorContains(t, "could not parse account created at timestamp", err)
+}
+
 func Test_IsEmptyWallet_RandomFiles(t *testing.T) {
 	path := testutil.TempDir()
 	walletDir := filepath.Join(path, "test")
@@ -156,3 +426,30 @@ func Test_IsEmptyWallet_RandomFiles(t *testing.T) {
 	assert.Equal(t, false, got)
 	require.NoError(t, os.RemoveAll(walletDir), "Failed to remove directory")
 }
+
+func Test_WriteFileAtomic(t *testing.T) {
+	dir := filepath.Join(testutil.TempDir(), t.Name())
+	require.NoError(t, os.MkdirAll(dir, params.BeaconIoConfig().ReadWriteExecutePermissions))
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	filePath := filepath.Join(dir, "keystore.json")
+	require.NoError(t, writeFileAtomic(filePath, []byte("first"), params.BeaconIoConfig().ReadWritePermissions))
+	require.NoError(t, writeFileAtomic(filePath, []byte("second"), params.BeaconIoConfig().ReadWritePermissions))
+	data, err := ioutil.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.DeepEqual(t, []byte("second"), data)
+
+	// A failed write leaves neither the file nor its temporary file behind.
+	assert.NotNil(t, writeFileAtomic(filepath.Join(dir, "missing", "keystore.json"), []byte("data"), os.ModePerm))
+	blocked := filepath.Join(dir, "blocked")
+	require.NoError(t, os.MkdirAll(blocked, params.BeaconIoConfig().ReadWriteExecutePermissions))
+	assert.NotNil(t, writeFileAtomic(blocked, []byte("data"), os.ModePerm))
+	entries, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+	assert.DeepEqual(t, []string{"blocked", "keystore.json"}, names)
+}
@@ -0,0 +1,388 @@
+package v2
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" // #nosec G505 -- RFC 6238 codes of authenticator apps are HMAC-SHA1.
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/promptutil"
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
+	"github.com/prysmaticlabs/prysm/validator/flags"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/derived"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/urfave/cli/v2"
+	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+)
+
+const (
+	// totpFileName is the file in the accounts path of a wallet holding the secret of the RFC 6238
+	// codes its sensitive account commands require, encrypted with the wallet password.
+	totpFileName   = "totp.json"
+	totpVersion    = 1
+	totpSecretSize = 20
+	totpDigits     = 6
+	totpPeriod     = 30 * time.Second
+	// Codes of the time steps right before and after the current one are accepted, for clock skew.
+	totpSkew       = 1
+	totpIssuer     = "Prysm"
+	totpPromptText = "Enter the code of the authenticator app of the wallet"
+)
+
+// totpFile is the content of the TOTP file of a wallet. The last step is the time step of the last
+// code accepted, so a code is never accepted twice.
+type totpFile struct {
+	Version  int                    `json:"version"`
+	LastStep uint64                 `json:"last_step"`
+	Crypto   map[string]interface{} `json:"crypto"`
+}
+
+// EnrollTOTP generates a TOTP secret for a wallet, to add to an authenticator app, after which
+// every command exporting or removing the keys of the wallet requires a code of the app given with
+// --totp-code or entered at the prompt, so a leaked shell session is not enough to carry them out.
+// The secret is encrypted with the wallet password and only written once a code of the app is
+// entered. The enrollment is recorded in the keymanager config, which must be encrypted with the
+// wallet password, so removing the TOTP file does not lift the requirement.
+func EnrollTOTP(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	if err := wallet.checkWritable(); err != nil {
+		return err
+	}
+	enrolled, err := wallet.hasTOTP(ctx)
+	if err != nil {
+		return err
+	}
+	if enrolled {
+		return errors.New("wallet already has a TOTP secret, remove it with wallet-v2 remove-totp first")
+	}
+	if wallet.walletPassword == "" {
+		return errNoWalletPassword
+	}
+	if !wallet.encryptedConfig {
+		return fmt.Errorf(
+			"TOTP requires a keymanager config encrypted with the wallet password, create the wallet with --%s",
+			flags.EncryptKeymanagerConfigFlag.Name,
+		)
+	}
+	secret := make([]byte, totpSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return errors.Wrap(err, "could not generate TOTP secret")
+	}
+	encodedSecret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret)
+	fmt.Printf(
+		"Add this secret to an authenticator app, or the URI as a QR code:\n\n%s\n\n%s\n\n",
+		au.BrightGreen(encodedSecret),
+		au.BrightGreen(totpURI(encodedSecret, filepath.Base(wallet.walletDir))),
+	)
+	code, err := inputTOTPCode(cliCtx)
+	if err != nil {
+		return err
+	}
+	step, err := verifyTOTP(secret, code, roughtime.Now(), 0)
+	if err != nil {
+		return errors.Wrap(err, "authenticator app was not set up with the secret")
+	}
+	encoded, err := encryptTOTPFile(secret, step, wallet.walletPassword)
+	if err != nil {
+		return err
+	}
+	ctx = withJournalAction(ctx, journalActionTOTPEnrolled)
+	if err := writeFileAtomic(wallet.totpPath(), encoded, FilePermissions); err != nil {
+		return errors.Wrap(err, "could not write TOTP file")
+	}
+	if err := wallet.recordMutation(ctx, journalWrites(ctx, map[string][]byte{totpFileName: encoded})); err != nil {
+		return err
+	}
+	// The TOTP file is written first, so the wallet is never enrolled without a secret.
+	if err := wallet.setTOTPEnrolled(ctx, true); err != nil {
+		if rmErr := os.Remove(wallet.totpPath()); rmErr != nil {
+			log.WithError(rmErr).Error("Could not remove TOTP file")
+		}
+		return errors.Wrap(err, "could not record TOTP enrollment")
+	}
+	log.Info("Successfully enrolled TOTP secret, exporting and removing the keys of the wallet now require a code")
+	return nil
+}
+
+// RemoveTOTP removes the TOTP secret of a wallet, once a code of its authenticator app is given.
+func RemoveTOTP(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	if err := wallet.checkWritable(); err != nil {
+		return err
+	}
+	enrolled, err := wallet.hasTOTP(ctx)
+	if err != nil {
+		return err
+	}
+	if !enrolled {
+		return errors.New("wallet has no TOTP secret")
+	}
+	if err := wallet.checkTOTP(cliCtx, "removing the TOTP secret"); err != nil {
+		return err
+	}
+	// The enrollment is cleared first, so the wallet is never enrolled without a secret.
+	if err := wallet.setTOTPEnrolled(withJournalAction(ctx, journalActionTOTPRemoved), false); err != nil {
+		return errors.Wrap(err, "could not clear TOTP enrollment")
+	}
+	if err := os.Remove(wallet.totpPath()); err != nil {
+		return errors.Wrap(err, "could not remove TOTP file")
+	}
+	entry := &walletJournalEntry{
+		Action:  journalActionTOTPRemoved,
+		Deleted: []string{totpFileName},
+	}
+	if err := wallet.recordMutation(ctx, entry); err != nil {
+		return err
+	}
+	log.Info("Successfully removed TOTP secret")
+	return nil
+}
+
+// Checks a code of the authenticator app of the wallet is given for an operation, if the wallet
+// has a TOTP secret, and records its time step so it cannot be used again. A wallet which
+// enrollment is recorded in its keymanager config fails the check if its TOTP file is missing.
+func (w *Wallet) checkTOTP(cliCtx *cli.Context, operation string) error {
+	enrolled, err := w.hasTOTP(context.Background())
+	if err != nil {
+		return err
+	}
+	if !enrolled {
+		return nil
+	}
+	encoded, err := w.files().readFile(context.Background(), totpFileName)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s requires a code of the authenticator app of the wallet, but its TOTP file is missing", operation)
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not read TOTP file")
+	}
+	file, secret, err := decryptTOTPFile(encoded, w.walletPassword)
+	if err != nil {
+		return err
+	}
+	code, err := inputTOTPCode(cliCtx)
+	if err != nil {
+		return err
+	}
+	step, err := verifyTOTP(secret, code, roughtime.Now(), file.LastStep)
+	if err != nil {
+		return errors.Wrapf(err, "%s requires a code of the authenticator app of the wallet", operation)
+	}
+	if w.readOnly {
+		log.Warn("Wallet is read-only, the TOTP code is not recorded as used")
+		return nil
+	}
+	encoded, err = json.MarshalIndent(&totpFile{
+		Version:  totpVersion,
+		LastStep: step,
+		Crypto:   file.Crypto,
+	}, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "could not encode TOTP file")
+	}
+	ctx := withJournalAction(context.Background(), journalActionTOTPCodeUsed)
+	if err := w.files().writeFile(ctx, totpFileName, encoded); err != nil {
+		return errors.Wrap(err, "could not record TOTP code as used")
+	}
+	return w.recordMutation(ctx, journalWrites(ctx, map[string][]byte{totpFileName: encoded}))
+}
+
+// Returns whether the wallet has a TOTP secret, if either its enrollment is recorded in the
+// keymanager config or the wallet has a TOTP file.
+func (w *Wallet) hasTOTP(ctx context.Context) (bool, error) {
+	if fileExists(w.totpPath()) {
+		return true, nil
+	}
+	configFile, err := w.ReadKeymanagerConfigFromDisk(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "could not read keymanager config")
+	}
+	switch w.KeymanagerKind() {
+	case v2keymanager.Direct:
+		cfg, err := direct.UnmarshalConfigFile(configFile, w.walletPassword)
+		if err != nil {
+			return false, errors.Wrap(err, "could not unmarshal keymanager config file")
+		}
+		return cfg.TOTPEnrolled, nil
+	case v2keymanager.Derived:
+		cfg, err := derived.UnmarshalConfigFile(configFile, w.walletPassword)
+		if err != nil {
+			return false, errors.Wrap(err, "could not unmarshal keymanager config file")
+		}
+		return cfg.TOTPEnrolled, nil
+	default:
+		return false, nil
+	}
+}
+
+// Records in the keymanager config of the wallet whether it has a TOTP secret.
+func (w *Wallet) setTOTPEnrolled(ctx context.Context, enrolled bool) error {
+	configFile, err := w.ReadKeymanagerConfigFromDisk(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not read keymanager config")
+	}
+	var encodedCfg []byte
+	switch w.KeymanagerKind() {
+	case v2keymanager.Direct:
+		cfg, err := direct.UnmarshalConfigFile(configFile, w.walletPassword)
+		if err != nil {
+			return errors.Wrap(err, "could not unmarshal keymanager config file")
+		}
+		cfg.TOTPEnrolled = enrolled
+		encodedCfg, err = direct.MarshalConfigFile(ctx, cfg)
+		if err != nil {
+			return errors.Wrap(err, "could not marshal keymanager config")
+		}
+	case v2keymanager.Derived:
+		cfg, err := derived.UnmarshalConfigFile(configFile, w.walletPassword)
+		if err != nil {
+			return errors.Wrap(err, "could not unmarshal keymanager config file")
+		}
+		cfg.TOTPEnrolled = enrolled
+		encodedCfg, err = derived.MarshalConfigFile(ctx, cfg)
+		if err != nil {
+			return errors.Wrap(err, "could not marshal keymanager config")
+		}
+	default:
+		return fmt.Errorf("TOTP is not supported for %s wallets", w.KeymanagerKind())
+	}
+	return w.WriteKeymanagerConfigToDisk(ctx, encodedCfg)
+}
+
+func (w *Wallet) totpPath() string {
+	return filepath.Join(w.accountsPath, totpFileName)
+}
+
+// Re-encrypts the TOTP file of the wallet with a new wallet password, in memory.
+func (w *Wallet) reencryptTOTPFile(newPassword string) (*reencryptedFile, error) {
+	enc, err := ioutil.ReadFile(w.totpPath())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read TOTP file")
+	}
+	file, secret, err := decryptTOTPFile(enc, w.walletPassword)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := encryptTOTPFile(secret, file.LastStep, newPassword)
+	if err != nil {
+		return nil, err
+	}
+	return &reencryptedFile{path: w.totpPath(), previous: enc, data: encoded}, nil
+}
+
+func inputTOTPCode(cliCtx *cli.Context) (string, error) {
+	if cliCtx.IsSet(flags.TOTPCodeFlag.Name) {
+		code := strings.TrimSpace(cliCtx.String(flags.TOTPCodeFlag.Name))
+		return code, validateTOTPCode(code)
+	}
+	code, err := promptutil.ValidatePrompt(totpPromptText, validateTOTPCode)
+	if err != nil {
+		return "", errors.Wrap(err, "could not input TOTP code")
+	}
+	return code, nil
+}
+
+func validateTOTPCode(code string) error {
+	if len(code) != totpDigits || strings.Trim(code, "0123456789") != "" {
+		return fmt.Errorf("TOTP code must be %d digits", totpDigits)
+	}
+	return nil
+}
+
+// Returns the time step a code is valid for, the current one or one right before or after it,
+// which must be after the last step a code was accepted for.
+func verifyTOTP(secret []byte, code string, now time.Time, lastStep uint64) (uint64, error) {
+	current := uint64(now.Unix()) / uint64(totpPeriod.Seconds())
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= lastStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(totpCode(secret, step)), []byte(code)) == 1 {
+			return step, nil
+		}
+	}
+	return 0, errors.New("wrong or already used TOTP code")
+}
+
+// Computes the RFC 6238 code of a time step with HMAC-SHA1, as authenticator apps do by default.
+func totpCode(secret []byte, step uint64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], step)
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// Returns the otpauth URI authenticator apps read the secret from as a QR code.
+func totpURI(encodedSecret string, account string) string {
+	query := url.Values{}
+	query.Set("secret", encodedSecret)
+	query.Set("issuer", totpIssuer)
+	query.Set("digits", fmt.Sprintf("%d", totpDigits))
+	query.Set("period", fmt.Sprintf("%d", int(totpPeriod.Seconds())))
+	return (&url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + totpIssuer + ":" + account,
+		RawQuery: query.Encode(),
+	}).String()
+}
+
+func encryptTOTPFile(secret []byte, lastStep uint64, walletPassword string) ([]byte, error) {
+	cryptoFields, err := keystorev4.New().Encrypt(secret, walletPassword)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encrypt TOTP secret")
+	}
+	encoded, err := json.MarshalIndent(&totpFile{
+		Version:  totpVersion,
+		LastStep: lastStep,
+		Crypto:   cryptoFields,
+	}, "", "\t")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encode TOTP file")
+	}
+	return encoded, nil
+}
+
+func decryptTOTPFile(encoded []byte, walletPassword string) (*totpFile, []byte, error) {
+	file := &totpFile{}
+	if err := json.Unmarshal(encoded, file); err != nil {
+		return nil, nil, errors.Wrap(err, "could not decode TOTP file")
+	}
+	if file.Version != totpVersion {
+		return nil, nil, fmt.Errorf("unsupported TOTP file version %d", file.Version)
+	}
+	secret, err := keystorev4.New().Decrypt(file.Crypto, walletPassword)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not decrypt TOTP file, wrong wallet password")
+	}
+	return file, secret, nil
+}
@@ -0,0 +1,102 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// Test vectors of RFC 6238 for HMAC-SHA1, truncated to 6 digits.
+	secret := []byte("12345678901234567890")
+	tests := []struct {
+		time int64
+		code string
+	}{
+		{time: 59, code: "287082"},
+		{time: 1111111109, code: "081804"},
+		{time: 1234567890, code: "005924"},
+		{time: 2000000000, code: "279037"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.code, totpCode(secret, uint64(tt.time)/30))
+		step, err := verifyTOTP(secret, tt.code, time.Unix(tt.time, 0), 0)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(tt.time)/30, step)
+		_, err = verifyTOTP(secret, tt.code, time.Unix(tt.time, 0), step)
+		assert.ErrorContains(t, "wrong or already used TOTP code", err)
+		_, err = verifyTOTP(secret, tt.code, time.Unix(tt.time+120, 0), 0)
+		assert.ErrorContains(t, "wrong or already used TOTP code", err)
+	}
+}
+
+func TestCheckTOTP(t *testing.T) {
+	walletDir, passwordsDir, passwordFile := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:           walletDir,
+		passwordsDir:        passwordsDir,
+		walletPasswordFile:  passwordFile,
+		accountPasswordFile: passwordFile,
+		keymanagerKind:      v2keymanager.Direct,
+	}
+	_, err := CreateWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	require.NoError(t, CreateAccount(setupWalletCtx(t, cfg)))
+	require.NoError(t, CreateAccount(setupWalletCtx(t, cfg)))
+	wallet, err := OpenWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	accounts, err := wallet.accountPubKeys(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, len(accounts))
+
+	// Enroll a secret known to the test, as an authenticator app would hold it, recording the
+	// enrollment in an encrypted keymanager config as enroll-totp does.
+	secret := []byte("12345678901234567890")
+	encoded, err := encryptTOTPFile(secret, 0, password)
+	require.NoError(t, err)
+	totpPath := filepath.Join(walletDir, v2keymanager.Direct.String(), totpFileName)
+	require.NoError(t, ioutil.WriteFile(totpPath, encoded, os.ModePerm))
+	wallet.encryptedConfig = true
+	require.NoError(t, wallet.setTOTPEnrolled(context.Background(), true))
+	step := uint64(roughtime.Now().Unix()) / 30
+	for _, pubKey := range accounts {
+		cfg.deletePublicKeys = []string{fmt.Sprintf("%#x", pubKey)}
+	}
+
+	cfg.totpCode = totpCode(secret, step-5)
+	assert.ErrorContains(t, "deleting accounts requires a code", DeleteAccount(setupWalletCtx(t, cfg)))
+	// Removing the TOTP file does not lift the requirement.
+	require.NoError(t, os.Remove(totpPath))
+	cfg.totpCode = totpCode(secret, step)
+	assert.ErrorContains(t, "TOTP file is missing", DeleteAccount(setupWalletCtx(t, cfg)))
+	require.NoError(t, ioutil.WriteFile(totpPath, encoded, os.ModePerm))
+	require.NoError(t, DeleteAccount(setupWalletCtx(t, cfg)))
+	accounts, err = wallet.accountPubKeys(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(accounts))
+
+	// A code is never accepted twice, and the secret follows a new wallet password.
+	assert.ErrorContains(t, "wrong or already used TOTP code", RemoveTOTP(setupWalletCtx(t, cfg)))
+	newPasswordFile := filepath.Join(filepath.Dir(passwordFile), "new-wallet-password.txt")
+	require.NoError(t, ioutil.WriteFile(newPasswordFile, []byte("Sh1nyN3wWall3t!"), os.ModePerm))
+	cfg.newWalletPassword = newPasswordFile
+	require.NoError(t, ChangeWalletPassword(setupWalletCtx(t, cfg)))
+	cfg.walletPasswordFile = newPasswordFile
+	cfg.totpCode = totpCode(secret, step+1)
+	require.NoError(t, RemoveTOTP(setupWalletCtx(t, cfg)))
+	assert.ErrorContains(t, "wallet has no TOTP secret", RemoveTOTP(setupWalletCtx(t, cfg)))
+	wallet, err = OpenWallet(setupWalletCtx(t, cfg))
+	require.NoError(t, err)
+	enrolled, err := wallet.hasTOTP(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, false, enrolled)
+}
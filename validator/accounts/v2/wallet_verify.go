@@ -0,0 +1,143 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+	"github.com/urfave/cli/v2"
+)
+
+// accountVerification is the outcome of verifying an account of a non-HD wallet.
+type accountVerification struct {
+	name     string
+	pubKey   string
+	problems []string
+}
+
+// VerifyWallet checks the integrity of a non-HD wallet: the keystore of every account is
+// decrypted with its stored password and the decrypted key must match the public key of the
+// keystore, and every password file must belong to an account of the wallet or of its archive.
+// A pass or fail result is printed for every account, and an error is returned if any check
+// failed.
+func VerifyWallet(cliCtx *cli.Context) error {
+	ctx := context.Background()
+	wallet, err := OpenWallet(cliCtx)
+	if errors.Is(err, ErrNoWalletFound) {
+		return errors.Wrap(err, "no wallet found at path, create a new wallet with wallet-v2 create")
+	} else if err != nil {
+		return errors.Wrap(err, "could not open wallet")
+	}
+	if wallet.KeymanagerKind() != v2keymanager.Direct {
+		return fmt.Errorf("only non-HD wallets store keystores and passwords to verify, not %s wallets", wallet.KeymanagerKind())
+	}
+	accountNames, err := wallet.ListDirs()
+	if err != nil {
+		return errors.Wrap(err, "could not list accounts")
+	}
+	sort.Strings(accountNames)
+	verifications := make([]*accountVerification, len(accountNames))
+	for i, name := range accountNames {
+		verifications[i] = wallet.verifyAccount(ctx, name)
+	}
+	orphans, err := wallet.orphanedPasswordFiles(ctx, accountNames)
+	if err != nil {
+		return err
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ACCOUNT\tPUBLIC KEY\tRESULT\tPROBLEMS")
+	failed := 0
+	for _, verification := range verifications {
+		result := "PASS"
+		if len(verification.problems) > 0 {
+			result = "FAIL"
+			failed++
+		}
+		fmt.Fprintf(
+			table, "%s\t%s\t%s\t%s\n",
+			verification.name, verification.pubKey, result, strings.Join(verification.problems, "; "),
+		)
+	}
+	if err := table.Flush(); err != nil {
+		return errors.Wrap(err, "could not print verification results")
+	}
+	for _, orphan := range orphans {
+		fmt.Printf("%s password file %s belongs to no account of the wallet\n", au.BrightRed("[orphaned]").Bold(), orphan)
+	}
+	fmt.Printf(
+		"%d of %d accounts passed verification, %d orphaned password files\n",
+		len(verifications)-failed, len(verifications), len(orphans),
+	)
+	if failed > 0 || len(orphans) > 0 {
+		return fmt.Errorf("wallet verification failed: %d accounts failed, %d orphaned password files", failed, len(orphans))
+	}
+	return nil
+}
+
+// Decrypts the keystore of an account with its stored password, reporting every problem found.
+func (w *Wallet) verifyAccount(ctx context.Context, accountName string) *accountVerification {
+	verification := &accountVerification{name: accountName}
+	keystoreFileName, err := w.FileNameAtPath(ctx, accountName, w.keystoreFileGlob())
+	if err != nil {
+		verification.problems = append(verification.problems, "no keystore found")
+		return verification
+	}
+	keystorePath := filepath.Join(w.AccountsDir(), accountName, keystoreFileName)
+	encoded, err := ioutil.ReadFile(keystorePath)
+	if err != nil {
+		verification.problems = append(verification.problems, fmt.Sprintf("could not read keystore: %v", err))
+		return verification
+	}
+	var password *string
+	if pw, err := w.ReadPasswordFromDisk(ctx, accountName+direct.PasswordFileSuffix); err == nil {
+		password = &pw
+	} else {
+		verification.problems = append(verification.problems, "no stored password")
+	}
+	report := validateKeystore(keystorePath, encoded, password)
+	verification.pubKey = report.PublicKey
+	verification.problems = append(verification.problems, report.Errors...)
+	return verification
+}
+
+// Lists the password files of the passwords directory of the wallet which belong neither to
+// one of the given accounts nor to an archived account.
+func (w *Wallet) orphanedPasswordFiles(ctx context.Context, accountNames []string) ([]string, error) {
+	entries, err := ioutil.ReadDir(w.passwordsDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list password files")
+	}
+	archived, err := w.archivedAccountPubKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	owners := make(map[string]bool, len(accountNames)+len(archived))
+	for _, name := range accountNames {
+		owners[name] = true
+	}
+	for name := range archived {
+		owners[name] = true
+	}
+	var orphans []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), direct.PasswordFileSuffix) {
+			continue
+		}
+		if !owners[strings.TrimSuffix(entry.Name(), direct.PasswordFileSuffix)] {
+			orphans = append(orphans, filepath.Join(w.passwordsDir, entry.Name()))
+		}
+	}
+	return orphans, nil
+}
@@ -0,0 +1,56 @@
+package v2
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
+)
+
+func TestVerifyWallet(t *testing.T) {
+	walletDir, passwordsDir, passwordFilePath := setupWalletAndPasswordsDir(t)
+	cfg := &testWalletConfig{
+		walletDir:          walletDir,
+		passwordsDir:       passwordsDir,
+		walletPasswordFile: passwordFilePath,
+		keymanagerKind:     v2keymanager.Direct,
+	}
+	wallet, err := NewWallet(setupWalletCtx(t, cfg), v2keymanager.Direct)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SaveWallet())
+	ctx := context.Background()
+	encodedCfg, err := direct.MarshalConfigFile(ctx, direct.DefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, wallet.WriteKeymanagerConfigToDisk(ctx, encodedCfg))
+	keymanager, err := direct.NewKeymanager(ctx, wallet, direct.DefaultConfig())
+	require.NoError(t, err)
+	names := make([]string, 3)
+	for i := range names {
+		names[i], err = keymanager.CreateAccount(ctx, password)
+		require.NoError(t, err)
+	}
+	require.NoError(t, VerifyWallet(setupWalletCtx(t, cfg)))
+
+	// The password files of archived accounts are not orphaned.
+	require.NoError(t, wallet.archiveAccount(names[2]))
+	require.NoError(t, VerifyWallet(setupWalletCtx(t, cfg)))
+
+	require.NoError(t, wallet.WritePasswordToDisk(ctx, "deleted-account"+direct.PasswordFileSuffix, password))
+	assert.ErrorContains(t, "0 accounts failed, 1 orphaned password files", VerifyWallet(setupWalletCtx(t, cfg)))
+
+	require.NoError(t, wallet.WritePasswordToDisk(ctx, names[0]+direct.PasswordFileSuffix, "wrongpassword"))
+	assert.ErrorContains(t, "1 accounts failed, 1 orphaned password files", VerifyWallet(setupWalletCtx(t, cfg)))
+	verification := wallet.verifyAccount(ctx, names[0])
+	require.Equal(t, 1, len(verification.problems))
+	assert.Equal(t, true, strings.Contains(verification.problems[0], "checksum verification failed"))
+
+	require.NoError(t, os.Remove(filepath.Join(passwordsDir, names[1]+direct.PasswordFileSuffix)))
+	verification = wallet.verifyAccount(ctx, names[1])
+	assert.DeepEqual(t, []string{"no stored password"}, verification.problems)
+}
@@ -0,0 +1,228 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	ptypes "github.com/gogo/protobuf/types"
+	"github.com/pkg/errors"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+	"github.com/prysmaticlabs/prysm/beacon-chain/core/helpers"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/sirupsen/logrus"
+)
+
+// Backoff bounds used when re-establishing the chain head stream.
+var (
+	chainHeadStreamInitialBackoff = time.Second
+	chainHeadStreamMaxBackoff     = time.Minute
+)
+
+// SubscribeToChainHead keeps a chain head stream open with the beacon node until
+// the context is canceled. If the stream drops, it is re-established with an
+// exponential backoff and the head updates missed while disconnected are replayed,
+// so reorgs which happened during the outage are not silently skipped.
+func (v *validator) SubscribeToChainHead(ctx context.Context) {
+	backoff := chainHeadStreamInitialBackoff
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		received, err := v.streamChainHead(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		// A stream which delivered heads was healthy, so its failure is not part of an outage.
+		if received {
+			backoff = chainHeadStreamInitialBackoff
+		}
+		log.WithError(err).WithField("retryIn", backoff).Warn("Chain head stream to beacon node interrupted")
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff *= 2
+		if backoff > chainHeadStreamMaxBackoff {
+			backoff = chainHeadStreamMaxBackoff
+		}
+	}
+}
+
+// Opens a chain head stream, replays any head updates missed since the last one
+// received, and then processes head updates until the stream fails. It reports
+// whether the stream received at least one head before failing.
+func (v *validator) streamChainHead(ctx context.Context) (bool, error) {
+	stream, err := v.beaconClient.StreamChainHead(ctx, &ptypes.Empty{})
+	if err != nil {
+		return false, errors.Wrap(err, "could not setup chain head streaming client")
+	}
+	if v.lastChainHead() != nil {
+		if err := v.replayMissedChainHeads(ctx); err != nil {
+			return false, errors.Wrap(err, "could not replay missed chain heads")
+		}
+	}
+	received := false
+	for {
+		head, err := stream.Recv()
+		if ctx.Err() != nil {
+			return received, ctx.Err()
+		}
+		if err != nil {
+			return received, errors.Wrap(err, "could not receive chain head from stream")
+		}
+		v.processChainHead(ctx, head)
+		received = true
+	}
+}
+
+// Replays the head updates between the last received head and the beacon node's
+// current head by walking the blocks of the missed slots. A block which does not
+// descend from the last known head signals a reorg during the outage.
+func (v *validator) replayMissedChainHeads(ctx context.Context) error {
+	head, err := v.beaconClient.GetChainHead(ctx, &ptypes.Empty{})
+	if err != nil {
+		return err
+	}
+	last := v.lastChainHead()
+	if head.HeadSlot <= last.HeadSlot {
+		v.processChainHead(ctx, head)
+		return nil
+	}
+	if head.HeadSlot-last.HeadSlot > maxChainHeadReplaySlots() {
+		log.WithFields(logrus.Fields{
+			"lastSlot": last.HeadSlot,
+			"headSlot": head.HeadSlot,
+		}).Warn("Missed too many chain head updates to replay, refreshing duties")
+		v.markDutiesStale()
+		v.setLastChainHead(head)
+		return nil
+	}
+	log.WithFields(logrus.Fields{
+		"fromSlot": last.HeadSlot + 1,
+		"toSlot":   head.HeadSlot,
+	}).Info("Replaying chain head updates missed while disconnected")
+	parentRoot := last.HeadBlockRoot
+	for slot := last.HeadSlot + 1; slot <= head.HeadSlot; slot++ {
+		resp, err := v.beaconClient.ListBlocks(ctx, &ethpb.ListBlocksRequest{
+			QueryFilter: &ethpb.ListBlocksRequest_Slot{Slot: slot},
+		})
+		if err != nil {
+			return errors.Wrapf(err, "could not list blocks at slot %d", slot)
+		}
+		for _, container := range resp.BlockContainers {
+			if container.Block == nil || container.Block.Block == nil {
+				continue
+			}
+			// The block descends from the last replayed head, so it is recorded as is.
+			if bytes.Equal(container.Block.Block.ParentRoot, parentRoot) {
+				v.setLastChainHead(&ethpb.ChainHead{
+					HeadSlot:      slot,
+					HeadEpoch:     helpers.SlotToEpoch(slot),
+					HeadBlockRoot: container.BlockRoot,
+				})
+				parentRoot = container.BlockRoot
+				break
+			}
+		}
+	}
+	if !bytes.Equal(parentRoot, head.HeadBlockRoot) {
+		log.WithFields(logrus.Fields{
+			"lastSlot": last.HeadSlot,
+			"lastRoot": fmt.Sprintf("%#x", last.HeadBlockRoot),
+			"headSlot": head.HeadSlot,
+			"headRoot": fmt.Sprintf("%#x", head.HeadBlockRoot),
+		}).Warn("Chain reorganized while disconnected from beacon node, refreshing duties")
+		v.markDutiesStale()
+	}
+	v.setLastChainHead(head)
+	return nil
+}
+
+// Records a new chain head, refreshing duties unless its block descends from the
+// block of the previously received head. A head at a later slot may still be on
+// another fork, so its ancestry is checked rather than its slot alone.
+func (v *validator) processChainHead(ctx context.Context, head *ethpb.ChainHead) {
+	prev := v.lastChainHead()
+	v.setLastChainHead(head)
+	if prev == nil || bytes.Equal(prev.HeadBlockRoot, head.HeadBlockRoot) {
+		return
+	}
+	descends, err := v.descendsFrom(ctx, head, prev)
+	if err != nil {
+		log.WithError(err).Warn("Could not check ancestry of chain head, refreshing duties")
+		v.markDutiesStale()
+		return
+	}
+	if !descends {
+		log.WithFields(logrus.Fields{
+			"oldSlot": prev.HeadSlot,
+			"oldRoot": fmt.Sprintf("%#x", prev.HeadBlockRoot),
+			"newSlot": head.HeadSlot,
+			"newRoot": fmt.Sprintf("%#x", head.HeadBlockRoot),
+		}).Warn("Chain reorg detected, refreshing duties")
+		v.markDutiesStale()
+	}
+}
+
+// Walks the parent roots of the block of a head back to the slot of an earlier
+// head, reporting whether it reaches the block of the earlier head. Heads too far
+// apart to walk are reported as not descending from it.
+func (v *validator) descendsFrom(ctx context.Context, head *ethpb.ChainHead, ancestor *ethpb.ChainHead) (bool, error) {
+	if head.HeadSlot <= ancestor.HeadSlot || head.HeadSlot-ancestor.HeadSlot > maxChainHeadReplaySlots() {
+		return false, nil
+	}
+	root := head.HeadBlockRoot
+	for !bytes.Equal(root, ancestor.HeadBlockRoot) {
+		resp, err := v.beaconClient.ListBlocks(ctx, &ethpb.ListBlocksRequest{
+			QueryFilter: &ethpb.ListBlocksRequest_Root{Root: root},
+		})
+		if err != nil {
+			return false, errors.Wrapf(err, "could not get block %#x", root)
+		}
+		if len(resp.BlockContainers) == 0 || resp.BlockContainers[0].Block == nil || resp.BlockContainers[0].Block.Block == nil {
+			return false, fmt.Errorf("beacon node has no block %#x", root)
+		}
+		block := resp.BlockContainers[0].Block.Block
+		if block.Slot <= ancestor.HeadSlot {
+			return false, nil
+		}
+		root = block.ParentRoot
+	}
+	return true, nil
+}
+
+// Returns the most slots of head updates replayed or walked back through, past
+// which duties are refreshed instead.
+func maxChainHeadReplaySlots() uint64 {
+	return 2 * params.BeaconConfig().SlotsPerEpoch
+}
+
+func (v *validator) lastChainHead() *ethpb.ChainHead {
+	v.chainHeadLock.Lock()
+	defer v.chainHeadLock.Unlock()
+	return v.chainHead
+}
+
+func (v *validator) setLastChainHead(head *ethpb.ChainHead) {
+	v.chainHeadLock.Lock()
+	defer v.chainHeadLock.Unlock()
+	v.chainHead = head
+}
+
+func (v *validator) markDutiesStale() {
+	v.chainHeadLock.Lock()
+	defer v.chainHeadLock.Unlock()
+	v.dutiesStale = true
+}
+
+// Reports whether duties were invalidated by a reorg, clearing the flag.
+func (v *validator) consumeStaleDuties() bool {
+	v.chainHeadLock.Lock()
+	defer v.chainHeadLock.Unlock()
+	stale := v.dutiesStale
+	v.dutiesStale = false
+	return stale
+}
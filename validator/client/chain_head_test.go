@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	ptypes "github.com/gogo/protobuf/types"
+	"github.com/golang/mock/gomock"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+	"github.com/prysmaticlabs/prysm/shared/mock"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+)
+
+func blocksAtSlot(slot uint64, parentRoot []byte, root []byte) *ethpb.ListBlocksResponse {
+	return &ethpb.ListBlocksResponse{
+		BlockContainers: []*ethpb.BeaconBlockContainer{{
+			Block:     &ethpb.SignedBeaconBlock{Block: &ethpb.BeaconBlock{Slot: slot, ParentRoot: parentRoot}},
+			BlockRoot: root,
+		}},
+	}
+}
+
+func TestProcessChainHead_ReorgMarksDutiesStale(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock.NewMockBeaconChainClient(ctrl)
+	v := validator{beaconClient: client}
+	ctx := context.Background()
+	v.processChainHead(ctx, &ethpb.ChainHead{HeadSlot: 10, HeadBlockRoot: []byte{'a'}})
+	client.EXPECT().ListBlocks(gomock.Any(), &ethpb.ListBlocksRequest{
+		QueryFilter: &ethpb.ListBlocksRequest_Root{Root: []byte{'b'}},
+	}).Return(blocksAtSlot(11, []byte{'a'}, []byte{'b'}), nil)
+	v.processChainHead(ctx, &ethpb.ChainHead{HeadSlot: 11, HeadBlockRoot: []byte{'b'}})
+	assert.Equal(t, false, v.consumeStaleDuties())
+
+	// A head at the same slot with a different root is a reorg.
+	v.processChainHead(ctx, &ethpb.ChainHead{HeadSlot: 11, HeadBlockRoot: []byte{'c'}})
+	assert.Equal(t, true, v.consumeStaleDuties())
+	assert.Equal(t, false, v.consumeStaleDuties(), "Expected stale flag to be cleared once consumed")
+
+	// So is a head at a later slot on another fork.
+	client.EXPECT().ListBlocks(gomock.Any(), &ethpb.ListBlocksRequest{
+		QueryFilter: &ethpb.ListBlocksRequest_Root{Root: []byte{'e'}},
+	}).Return(blocksAtSlot(13, []byte{'d'}, []byte{'e'}), nil)
+	client.EXPECT().ListBlocks(gomock.Any(), &ethpb.ListBlocksRequest{
+		QueryFilter: &ethpb.ListBlocksRequest_Root{Root: []byte{'d'}},
+	}).Return(blocksAtSlot(11, []byte{'a'}, []byte{'d'}), nil)
+	v.processChainHead(ctx, &ethpb.ChainHead{HeadSlot: 13, HeadBlockRoot: []byte{'e'}})
+	assert.Equal(t, true, v.consumeStaleDuties())
+}
+
+func TestReplayMissedChainHeads_NoReorg(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock.NewMockBeaconChainClient(ctrl)
+	v := validator{beaconClient: client}
+	v.setLastChainHead(&ethpb.ChainHead{HeadSlot: 10, HeadBlockRoot: []byte{'a'}})
+
+	client.EXPECT().GetChainHead(gomock.Any(), &ptypes.Empty{}).Return(
+		&ethpb.ChainHead{HeadSlot: 12, HeadBlockRoot: []byte{'c'}}, nil,
+	)
+	client.EXPECT().ListBlocks(gomock.Any(), &ethpb.ListBlocksRequest{
+		QueryFilter: &ethpb.ListBlocksRequest_Slot{Slot: 11},
+	}).Return(blocksAtSlot(11, []byte{'a'}, []byte{'b'}), nil)
+	client.EXPECT().ListBlocks(gomock.Any(), &ethpb.ListBlocksRequest{
+		QueryFilter: &ethpb.ListBlocksRequest_Slot{Slot: 12},
+	}).Return(blocksAtSlot(12, []byte{'b'}, []byte{'c'}), nil)
+
+	require.NoError(t, v.replayMissedChainHeads(context.Background()))
+	assert.Equal(t, false, v.consumeStaleDuties())
+	assert.Equal(t, uint64(12), v.lastChainHead().HeadSlot)
+}
+
+func TestReplayMissedChainHeads_ReorgWhileDisconnected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock.NewMockBeaconChainClient(ctrl)
+	v := validator{beaconClient: client}
+	v.setLastChainHead(&ethpb.ChainHead{HeadSlot: 10, HeadBlockRoot: []byte{'a'}})
+
+	// The new head at slot 11 does not descend from the last received head.
+	client.EXPECT().GetChainHead(gomock.Any(), &ptypes.Empty{}).Return(
+		&ethpb.ChainHead{HeadSlot: 11, HeadBlockRoot: []byte{'x'}}, nil,
+	)
+	client.EXPECT().ListBlocks(gomock.Any(), &ethpb.ListBlocksRequest{
+		QueryFilter: &ethpb.ListBlocksRequest_Slot{Slot: 11},
+	}).Return(blocksAtSlot(11, []byte{'z'}, []byte{'x'}), nil)
+
+	require.NoError(t, v.replayMissedChainHeads(context.Background()))
+	assert.Equal(t, true, v.consumeStaleDuties())
+	assert.DeepEqual(t, []byte{'x'}, v.lastChainHead().HeadBlockRoot)
+}
+
+func TestSubscribeToChainHead_ResetsBackoffAfterReceivingHeads(t *testing.T) {
+	initialBackoff, maxBackoff := chainHeadStreamInitialBackoff, chainHeadStreamMaxBackoff
+	chainHeadStreamInitialBackoff, chainHeadStreamMaxBackoff = 50*time.Millisecond, time.Second
+	defer func() {
+		chainHeadStreamInitialBackoff, chainHeadStreamMaxBackoff = initialBackoff, maxBackoff
+	}()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock.NewMockBeaconChainClient(ctrl)
+	stream := mock.NewMockBeaconChain_StreamChainHeadClient(ctrl)
+	v := validator{beaconClient: client}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var attempts []time.Time
+	attempt := func(result ethpb.BeaconChain_StreamChainHeadClient, err error) func(context.Context, *ptypes.Empty) (ethpb.BeaconChain_StreamChainHeadClient, error) {
+		return func(context.Context, *ptypes.Empty) (ethpb.BeaconChain_StreamChainHeadClient, error) {
+			attempts = append(attempts, time.Now())
+			return result, err
+		}
+	}
+	unavailable := errors.New("beacon node unavailable")
+	// Two failed attempts double the backoff to 4 times its initial value, then a stream
+	// delivers a head before failing.
+	gomock.InOrder(
+		client.EXPECT().StreamChainHead(gomock.Any(), &ptypes.Empty{}).DoAndReturn(attempt(nil, unavailable)),
+		client.EXPECT().StreamChainHead(gomock.Any(), &ptypes.Empty{}).DoAndReturn(attempt(nil, unavailable)),
+		client.EXPECT().StreamChainHead(gomock.Any(), &ptypes.Empty{}).DoAndReturn(attempt(stream, nil)),
+		client.EXPECT().StreamChainHead(gomock.Any(), &ptypes.Empty{}).DoAndReturn(
+			func(context.Context, *ptypes.Empty) (ethpb.BeaconChain_StreamChainHeadClient, error) {
+				attempts = append(attempts, time.Now())
+				cancel()
+				return nil, unavailable
+			},
+		),
+	)
+	gomock.InOrder(
+		stream.EXPECT().Recv().Return(&ethpb.ChainHead{HeadSlot: 1, HeadBlockRoot: []byte{'a'}}, nil),
+		stream.EXPECT().Recv().Return(nil, unavailable),
+	)
+
+	v.SubscribeToChainHead(ctx)
+	require.Equal(t, 4, len(attempts))
+	assert.Equal(t, true, attempts[2].Sub(attempts[1]) >= 2*chainHeadStreamInitialBackoff, "Expected backoff to double after a failed attempt")
+	assert.Equal(t, true, attempts[3].Sub(attempts[2]) < 3*chainHeadStreamInitialBackoff, "Expected backoff to be reset after receiving a head")
+}
@@ -24,6 +24,7 @@ type fakeValidator struct {
 	LogValidatorGainsAndLossesCalled bool
 	SaveProtectionsCalled            bool
 	SlotDeadlineCalled               bool
+	SubscribeToChainHeadCalled       bool
 	ProposeBlockArg1                 uint64
 	AttestToBlockHeadArg1            uint64
 	RoleAtArg1                       uint64
@@ -122,3 +123,7 @@ func (fv *fakeValidator) SubmitAggregateAndProof(_ context.Context, slot uint64,
 func (fv *fakeValidator) LogAttestationsSubmitted() {}
 
 func (fv *fakeValidator) UpdateDomainDataCaches(context.Context, uint64) {}
+
+func (fv *fakeValidator) SubscribeToChainHead(_ context.Context) {
+	fv.SubscribeToChainHeadCalled = true
+}
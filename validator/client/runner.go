@@ -36,6 +36,7 @@ type Validator interface {
 	LogAttestationsSubmitted()
 	SaveProtections(ctx context.Context) error
 	UpdateDomainDataCaches(ctx context.Context, slot uint64)
+	SubscribeToChainHead(ctx context.Context)
 }
 
 // Run the main validator routine. This routine exits if the context is
@@ -77,6 +78,9 @@ func run(ctx context.Context, v Validator) {
 	if err := v.UpdateDuties(ctx, headSlot); err != nil {
 		handleAssignmentError(err, headSlot)
 	}
+	// Track chain head updates so duties are refreshed after reorgs,
+	// including those which happen while disconnected from the beacon node.
+	go v.SubscribeToChainHead(ctx)
 	for {
 		ctx, span := trace.StartSpan(ctx, "validator.processSlot")
 
@@ -68,6 +68,9 @@ type validator struct {
 	attesterHistoryByPubKey            map[[48]byte]*slashpb.AttestationHistory
 	attesterHistoryByPubKeyLock        sync.RWMutex
 	protector                          slashingprotection.Protector
+	chainHead                          *ethpb.ChainHead
+	dutiesStale                        bool
+	chainHeadLock                      sync.Mutex
 }
 
 // Done cleans up the validator.
@@ -106,6 +109,7 @@ func (v *validator) WaitForChainStart(ctx context.Context) error {
 	}
 	// Once the ChainStart log is received, we update the genesis time of the validator client
 	// and begin a slot ticker used to track the current slot the beacon node is in.
+	v.setKeymanagerGenesisTime()
 	v.ticker = slotutil.GetSlotTicker(time.Unix(int64(v.genesisTime), 0), params.BeaconConfig().SecondsPerSlot)
 	log.WithField("genesisTime", time.Unix(int64(v.genesisTime), 0)).Info("Beacon chain started")
 	return nil
@@ -171,11 +175,20 @@ func (v *validator) WaitForSynced(ctx context.Context) error {
 	}
 	// Once the Synced log is received, we update the genesis time of the validator client
 	// and begin a slot ticker used to track the current slot the beacon node is in.
+	v.setKeymanagerGenesisTime()
 	v.ticker = slotutil.GetSlotTicker(time.Unix(int64(v.genesisTime), 0), params.BeaconConfig().SecondsPerSlot)
 	log.WithField("genesisTime", time.Unix(int64(v.genesisTime), 0)).Info("Chain has started and the beacon node is synced")
 	return nil
 }
 
+// Keys scheduled for deactivation after an epoch are served once the keymanager knows the
+// genesis time the current epoch is computed from.
+func (v *validator) setKeymanagerGenesisTime() {
+	if km, ok := v.keyManagerV2.(*v2keymanager.DeactivatingKeymanager); ok {
+		km.SetGenesisTime(v.genesisTime)
+	}
+}
+
 // SlasherReady checks if slasher that was configured as external protection
 // is reachable.
 func (v *validator) SlasherReady(ctx context.Context) error {
@@ -329,8 +342,9 @@ func (v *validator) SlotDeadline(slot uint64) time.Time {
 // list of upcoming assignments needs to be updated. For example, at the
 // beginning of a new epoch.
 func (v *validator) UpdateDuties(ctx context.Context, slot uint64) error {
-	if slot%params.BeaconConfig().SlotsPerEpoch != 0 && v.duties != nil {
-		// Do nothing if not epoch start AND assignments already exist.
+	if slot%params.BeaconConfig().SlotsPerEpoch != 0 && v.duties != nil && !v.consumeStaleDuties() {
+		// Do nothing if not epoch start AND assignments already exist
+		// AND they were not invalidated by a chain reorg.
 		return nil
 	}
 	// Set deadline to end of epoch.
@@ -2,6 +2,7 @@
 package kv
 
 import (
+	"io"
 	"os"
 	"path/filepath"
 
@@ -109,6 +110,48 @@ func GetKVStore(directory string) (*Store, error) {
 	return &Store{db: boltDb, databasePath: directory}, nil
 }
 
+// Backup writes a consistent copy of the database file to w, returning the number of bytes written.
+func (store *Store) Backup(w io.Writer) (int64, error) {
+	var n int64
+	err := store.view(func(tx *bolt.Tx) error {
+		var err error
+		n, err = tx.WriteTo(w)
+		return err
+	})
+	return n, err
+}
+
+// PubKeys returns the validator public keys the database holds a proposal or attestation
+// history for.
+func (store *Store) PubKeys() ([][48]byte, error) {
+	pubKeys := make([][48]byte, 0)
+	seen := make(map[[48]byte]bool)
+	err := store.view(func(tx *bolt.Tx) error {
+		for _, bucketName := range [][]byte{historicProposalsBucket, historicAttestationsBucket} {
+			bucket := tx.Bucket(bucketName)
+			if bucket == nil {
+				continue
+			}
+			if err := bucket.ForEach(func(k, _ []byte) error {
+				if len(k) != 48 {
+					return nil
+				}
+				var pubKey [48]byte
+				copy(pubKey[:], k)
+				if !seen[pubKey] {
+					seen[pubKey] = true
+					pubKeys = append(pubKeys, pubKey)
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return pubKeys, err
+}
+
 // Size returns the db size in bytes.
 func (store *Store) Size() (int64, error) {
 	var size int64
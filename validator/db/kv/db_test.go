@@ -1,11 +1,14 @@
 package kv
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/prysmaticlabs/go-bitfield"
+	slashpb "github.com/prysmaticlabs/prysm/proto/slashing"
 	"github.com/prysmaticlabs/prysm/shared/rand"
 	"github.com/prysmaticlabs/prysm/shared/testutil/require"
 )
@@ -35,3 +38,59 @@ func tempdir() string {
 	}
 	return d
 }
+
+func TestStore_Backup(t *testing.T) {
+	ctx := context.Background()
+	pubKey := [48]byte{1}
+	db := setupDB(t, [][48]byte{pubKey})
+	slotBits := bitfield.Bitlist{0x04, 0x00, 0x00, 0x00, 0x04}
+	require.NoError(t, db.SaveProposalHistoryForEpoch(ctx, pubKey[:], 2, slotBits))
+
+	backupDir := filepath.Join(tempdir(), t.Name())
+	require.NoError(t, os.MkdirAll(backupDir, os.ModePerm))
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(backupDir), "Failed to remove directory")
+	})
+	f, err := os.Create(filepath.Join(backupDir, databaseFileName))
+	require.NoError(t, err)
+	n, err := db.Backup(f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	size, err := db.Size()
+	require.NoError(t, err)
+	require.Equal(t, size, n)
+
+	backup, err := GetKVStore(backupDir)
+	require.NoError(t, err)
+	require.NotNil(t, backup)
+	defer func() {
+		require.NoError(t, backup.Close())
+	}()
+	savedBits, err := backup.ProposalHistoryForEpoch(ctx, pubKey[:], 2)
+	require.NoError(t, err)
+	require.DeepEqual(t, slotBits, savedBits)
+}
+
+func TestStore_PubKeys(t *testing.T) {
+	ctx := context.Background()
+	pubKeys := [][48]byte{{1}, {2}}
+	db := setupDB(t, pubKeys)
+	attestingKey := [48]byte{3}
+	history := map[[48]byte]*slashpb.AttestationHistory{
+		attestingKey: {TargetToSource: map[uint64]uint64{1: 0}, LatestEpochWritten: 1},
+	}
+	require.NoError(t, db.SaveAttestationHistoryForPubKeys(ctx, history))
+
+	stored, err := db.PubKeys()
+	require.NoError(t, err)
+	require.Equal(t, 3, len(stored))
+	for _, pubKey := range append(pubKeys, attestingKey) {
+		found := false
+		for _, storedKey := range stored {
+			if storedKey == pubKey {
+				found = true
+			}
+		}
+		require.Equal(t, true, found, "Missing public key %#x", pubKey)
+	}
+}
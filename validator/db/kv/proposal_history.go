@@ -39,6 +39,29 @@ func (store *Store) ProposalHistoryForEpoch(ctx context.Context, publicKey []byt
 	return slotBitlist, err
 }
 
+// ProposalHistoryForPubKey returns the proposal history of every epoch still stored for the
+// validator public key, keyed by epoch. Returns an empty map if the validator never proposed.
+func (store *Store) ProposalHistoryForPubKey(ctx context.Context, publicKey []byte) (map[uint64]bitfield.Bitlist, error) {
+	ctx, span := trace.StartSpan(ctx, "Validator.ProposalHistoryForPubKey")
+	defer span.End()
+
+	history := make(map[uint64]bitfield.Bitlist)
+	err := store.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(historicProposalsBucket)
+		valBucket := bucket.Bucket(publicKey)
+		if valBucket == nil {
+			return nil
+		}
+		return valBucket.ForEach(func(k, v []byte) error {
+			slotBits := make(bitfield.Bitlist, len(v))
+			copy(slotBits, v)
+			history[binary.LittleEndian.Uint64(k)] = slotBits
+			return nil
+		})
+	})
+	return history, err
+}
+
 // SaveProposalHistoryForEpoch saves the proposal history for the requested validator public key.
 func (store *Store) SaveProposalHistoryForEpoch(ctx context.Context, pubKey []byte, epoch uint64, slotBits bitfield.Bitlist) error {
 	ctx, span := trace.StartSpan(ctx, "Validator.SaveProposalHistoryForEpoch")
@@ -138,6 +138,34 @@ func TestProposalHistoryForEpoch_MultipleEpochs(t *testing.T) {
 	}
 }
 
+func TestProposalHistoryForPubKey_OK(t *testing.T) {
+	pubKey := [48]byte{0}
+	unknownPubKey := [48]byte{1}
+	db := setupDB(t, [][48]byte{pubKey})
+	slotsPerEpoch := params.BeaconConfig().SlotsPerEpoch
+
+	history, err := db.ProposalHistoryForPubKey(context.Background(), unknownPubKey[:])
+	require.NoError(t, err)
+	require.Equal(t, 0, len(history), "Expected no history for a validator that never proposed")
+
+	slots := []uint64{3, slotsPerEpoch*2 + 5}
+	for _, slot := range slots {
+		slotBits, err := db.ProposalHistoryForEpoch(context.Background(), pubKey[:], helpers.SlotToEpoch(slot))
+		require.NoError(t, err)
+		slotBits.SetBitAt(slot%slotsPerEpoch, true)
+		require.NoError(t, db.SaveProposalHistoryForEpoch(context.Background(), pubKey[:], helpers.SlotToEpoch(slot), slotBits))
+	}
+
+	history, err = db.ProposalHistoryForPubKey(context.Background(), pubKey[:])
+	require.NoError(t, err)
+	require.Equal(t, len(slots), len(history))
+	for _, slot := range slots {
+		slotBits, ok := history[helpers.SlotToEpoch(slot)]
+		require.Equal(t, true, ok, "Missing history for epoch %d", helpers.SlotToEpoch(slot))
+		require.Equal(t, true, slotBits.BitAt(slot%slotsPerEpoch), "Expected slot %d to be marked as proposed", slot)
+	}
+}
+
 func TestPruneProposalHistory_OK(t *testing.T) {
 	slotsPerEpoch := params.BeaconConfig().SlotsPerEpoch
 	wsPeriod := params.BeaconConfig().WeakSubjectivityPeriod
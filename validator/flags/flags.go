@@ -143,12 +143,126 @@ var (
 	// AccountPasswordFileFlag is path to a file containing a password for a new validator account.
 	AccountPasswordFileFlag = &cli.StringFlag{
 		Name:  "account-password-file",
-		Usage: "Path to a plain-text, .txt file containing a password for a new validator account",
+		Usage: "Path to a plain-text, .txt file containing a password for a new validator account, or set PRYSM_ACCOUNT_PASSWORD to the password",
 	}
 	// WalletPasswordFileFlag is the path to a file containing your wallet password.
 	WalletPasswordFileFlag = &cli.StringFlag{
 		Name:  "wallet-password-file",
-		Usage: "Path to a plain-text, .txt file containing your wallet password",
+		Usage: "Path to a plain-text, .txt file containing your wallet password, or set PRYSM_WALLET_PASSWORD to the password",
+	}
+	// AdditionalWalletDirsFlag defines wallets the validator client loads alongside --wallet-dir.
+	AdditionalWalletDirsFlag = &cli.StringSliceFlag{
+		Name: "additional-wallet-dirs",
+		Usage: "Paths to further wallet directories of any kind to validate with alongside --wallet-dir, " +
+			"merging their keys. The password file of a wallet which requires a password is given as <wallet-dir>=<password-file>",
+	}
+	// WatchAccountsFlag makes the validator client load the accounts added to its non-HD wallet
+	// while it runs.
+	WatchAccountsFlag = &cli.BoolFlag{
+		Name: "watch-accounts",
+		Usage: "Watch the non-HD wallet of --wallet-dir for new accounts, loading the keystore of an account dropped into " +
+			"the wallet once its password file is in the passwords directory, without restarting the validator client",
+	}
+	// KeymanagerIdleTimeoutFlag makes the validator client lock its non-HD keymanager once it has
+	// not signed anything for a while.
+	KeymanagerIdleTimeoutFlag = &cli.DurationFlag{
+		Name: "keymanager-idle-timeout",
+		Usage: "Lock the non-HD keymanager of --wallet-dir once it has not signed anything for this long, such as 30m, " +
+			"clearing the validating keys from memory until the wallet password is entered at the prompt again. " +
+			"Only for validator clients operated manually, as duties are missed while locked",
+	}
+	// AllowUnlockedKeyMemoryFlag lets the validator client run when the secret keys of its non-HD
+	// keymanager cannot be locked into memory.
+	AllowUnlockedKeyMemoryFlag = &cli.BoolFlag{
+		Name: "allow-unlocked-key-memory",
+		Usage: "Keep the validating keys of a non-HD keymanager in ordinary memory, which may be swapped to disk, " +
+			"when they cannot be locked into memory, such as on Windows or beyond the memlock limit (ulimit -l) " +
+			"of the validator client, instead of refusing to start",
+	}
+	// SyncTargetFlag defines the secondary location to replicate a wallet to.
+	SyncTargetFlag = &cli.StringFlag{
+		Name: "sync-target",
+		Usage: "Directory or storage url, such as s3://bucket/prefix, gs://bucket/prefix or bolt:///path/to/wallet.db, " +
+			"to replicate the wallet to",
+	}
+	// WalletSecretsDirFlag makes the validator client validate with the keystores mounted into a
+	// directory as secrets, rather than with the wallet of --wallet-dir.
+	WalletSecretsDirFlag = &cli.StringFlag{
+		Name: "wallet-secrets-dir",
+		Usage: "Validate with a non-HD wallet kept in memory only, loaded from the keystores <name>.json mounted into this " +
+			"directory, such as Docker or Kubernetes secrets on a tmpfs. The password of a keystore is read from " +
+			"<name>.pass, or else from the file named password. Nothing is written to disk, and --wallet-dir is not used",
+	}
+	// WalletSecretsFromEnvFlag makes the validator client validate with the keystores of environment
+	// variables, rather than with the wallet of --wallet-dir.
+	WalletSecretsFromEnvFlag = &cli.BoolFlag{
+		Name: "wallet-secrets-from-env",
+		Usage: "Validate with a non-HD wallet kept in memory only, loaded from the keystores of the environment variables " +
+			"PRYSM_KEYSTORE_<id>, with their passwords in PRYSM_KEYSTORE_<id>_PASSWORD or else PRYSM_KEYSTORE_PASSWORD. " +
+			"Can be used along with --wallet-secrets-dir. Nothing is written to disk, and --wallet-dir is not used",
+	}
+	// WalletSecretsKubernetesSelectorFlag makes the validator client validate with the keystores of
+	// Kubernetes Secrets, rather than with the wallet of --wallet-dir.
+	WalletSecretsKubernetesSelectorFlag = &cli.StringFlag{
+		Name: "wallet-k8s-secrets-selector",
+		Usage: "Validate with a non-HD wallet kept in memory only, loaded from the keystores of the Kubernetes Secrets " +
+			"matching this label selector, such as app=validator, read with the service account of the pod. A keystore " +
+			"<name>.json of a Secret has its password in <name>.pass, or else in password. Secrets created or updated " +
+			"while the validator client runs are loaded too. Can be used along with the other wallet secrets flags",
+	}
+	// WalletSecretsKubernetesNamespaceFlag defines the namespace of the Kubernetes Secrets to validate with.
+	WalletSecretsKubernetesNamespaceFlag = &cli.StringFlag{
+		Name:  "wallet-k8s-secrets-namespace",
+		Usage: "Namespace of the Kubernetes Secrets of --wallet-k8s-secrets-selector, the namespace of the pod if not set",
+	}
+	// WalletBundleFlag makes the validator client validate with the wallet of a single-file bundle,
+	// rather than with the wallet of --wallet-dir.
+	WalletBundleFlag = &cli.StringFlag{
+		Name: "wallet-bundle",
+		Usage: "Validate with the wallet of this zip file, holding the wallet directory under wallet/ and optionally " +
+			"the account passwords of a non-HD wallet under passwords/. The wallet is kept in memory read-only unless " +
+			"--wallet-bundle-extract-dir is given, and --wallet-dir is not used",
+	}
+	// WalletBundleExtractDirFlag defines the directory a wallet bundle is extracted into.
+	WalletBundleExtractDirFlag = &cli.StringFlag{
+		Name: "wallet-bundle-extract-dir",
+		Usage: "New or empty directory to extract the wallet of --wallet-bundle into and open it from, so the wallet " +
+			"can be written to, rather than keeping it in memory read-only",
+	}
+	// WalletStorageRetriesFlag defines the number of times to retry a wallet storage operation failing
+	// with a transient error.
+	WalletStorageRetriesFlag = &cli.UintFlag{
+		Name: "wallet-storage-retries",
+		Usage: "Number of attempts to retry reading or writing wallet files failing with a transient error, such as " +
+			"an I/O error of a wallet on a network volume. Other errors, such as missing files, are not retried",
+		Value: 3,
+	}
+	// WalletStorageRetryDelayFlag defines the interval before the first retry of a failed wallet storage
+	// operation, doubling for every retry after.
+	WalletStorageRetryDelayFlag = &cli.DurationFlag{
+		Name:  "wallet-storage-retry-delay",
+		Usage: "The amount of time before the first retry of a failed wallet storage operation, doubling for every retry after.",
+		Value: 100 * time.Millisecond,
+	}
+	// WalletReadOnlyFlag defines whether every operation writing to the wallet fails.
+	WalletReadOnlyFlag = &cli.BoolFlag{
+		Name: "wallet-read-only",
+		Usage: "Open the wallets read-only, failing every operation which would write to them, such as for a " +
+			"monitoring or standby validator client which must never change the keys of a production wallet",
+	}
+	// WalletFixPermissionsFlag defines whether the permissions of wallet files accessible by other users
+	// are fixed on startup rather than refusing to start.
+	WalletFixPermissionsFlag = &cli.BoolFlag{
+		Name: "wallet-fix-permissions",
+		Usage: "Restrict the permissions of the wallet directories, keystores and account passwords accessible by " +
+			"other users to 0700 and 0600 on startup, rather than refusing to start",
+	}
+	// WalletManifestKeyFileFlag defines the file of the key signing the manifest of the wallet files.
+	WalletManifestKeyFileFlag = &cli.StringFlag{
+		Name: "wallet-manifest-key-file",
+		Usage: "Path to a file outside of the wallet with the key signing a manifest of the checksums of the wallet files, " +
+			"created if it does not exist. The validator client refuses to start if a wallet file was modified other " +
+			"than by Prysm. Must be given to every command changing the wallet",
 	}
 	// MnemonicFileFlag is used to enter a file to mnemonic phrase for new wallet creation, non-interactively.
 	MnemonicFileFlag = &cli.StringFlag{
@@ -166,10 +280,80 @@ var (
 		Usage: "Display raw eth1 tx deposit data for validator accounts-v2",
 		Value: false,
 	}
-	// AccountsFlag for non-interactive usage of accounts exporting, sets a list of account names or all to be exported.
+	// ListOutputFlag defines the format accounts-v2 list displays accounts in.
+	ListOutputFlag = &cli.StringFlag{
+		Name:  "output",
+		Usage: "Format to list or report accounts in: text for a human readable listing, or json or yaml for a machine readable output suited to monitoring systems and configuration management",
+		Value: "text",
+	}
+	// ListSortFlag defines the order accounts-v2 list displays accounts in.
+	ListSortFlag = &cli.StringFlag{
+		Name:  "sort",
+		Usage: "Order to list accounts in: name, created or pubkey. Accounts are listed in the order of the wallet by default, the derivation order for HD wallets",
+	}
+	// ListPageFlag defines the page of accounts accounts-v2 list displays.
+	ListPageFlag = &cli.IntFlag{
+		Name:  "page",
+		Usage: "Page of accounts to list, numbered from 1, when listing --page-size accounts at a time",
+		Value: 1,
+	}
+	// ListPageSizeFlag defines the number of accounts accounts-v2 list displays at a time.
+	ListPageSizeFlag = &cli.IntFlag{
+		Name:  "page-size",
+		Usage: "Number of accounts to list at a time, 0 listing every account",
+		Value: 0,
+	}
+	// ListCountFlag makes accounts-v2 list only display the number of accounts.
+	ListCountFlag = &cli.BoolFlag{
+		Name:  "count",
+		Usage: "Only display the number of accounts in the wallet having the --with-labels",
+		Value: false,
+	}
+	// WalletStorageURLFlag selects the object storage the files of a new wallet are kept in.
+	WalletStorageURLFlag = &cli.StringFlag{
+		Name: "wallet-storage-url",
+		Usage: "Keep the keymanager config and account keystores of a new wallet in object storage, such as " +
+			"s3://bucket/prefix?region=us-east-1 or gs://bucket/prefix, instead of the wallet directory. S3 objects are " +
+			"encrypted server-side with keys managed by s3, or by kms with sse=aws:kms and an optional kms_key_id, and an " +
+			"endpoint parameter selects s3 compatible storage. GCS objects are encrypted with the cloud kms key of the " +
+			"kms_key_name parameter if given. bolt:// keeps them as records of a single database in the wallet " +
+			"directory, or at the path of a bolt:///path/to/wallet.db url, for wallets of many accounts. sqlite:// keeps " +
+			"them as rows of a sqlite database, likewise at sqlite:///path/to/wallet.sqlite, writing the files of every " +
+			"new account in a single transaction. encrypted:// keeps them in a single container encrypted with the wallet " +
+			"password, likewise at encrypted:///path/to/wallet.encrypted, only decrypted into memory when the wallet is " +
+			"opened. webdavs://user@host/path keeps them on a WebDAV server such as Nextcloud, authenticating with " +
+			"the password of PRYSM_WEBDAV_PASSWORD, or with the bearer token of PRYSM_WEBDAV_TOKEN if the url has no user. " +
+			"Storage of schemes registered by other packages is supported too. Account passwords are kept in " +
+			"the passwords directory",
+	}
+	// ShowLastSignedFlag makes accounts-v2 list display the last block and attestation every
+	// account signed, as recorded in the slashing protection history of the validator database.
+	ShowLastSignedFlag = &cli.BoolFlag{
+		Name:  "show-last-signed",
+		Usage: "Display the last slot every account proposed a block for and the last epoch it attested to, read from the validator database in --datadir",
+		Value: false,
+	}
+	// Web3SignerURLFlag defines the Web3Signer a remote wallet syncs its accounts with.
+	Web3SignerURLFlag = &cli.StringFlag{
+		Name:  "web3signer-url",
+		Usage: "Base url of a Web3Signer to sync the accounts of a remote wallet with, instead of the remote signer configured in the wallet",
+	}
+	// AccountsFlag for non-interactive usage of accounts exporting, sets a list of account names,
+	// 0x-prefixed public keys, or all to be exported.
 	AccountsFlag = &cli.StringSliceFlag{
 		Name:  "accounts",
-		Usage: "List of account names to export, or \"all\" to backup all accounts",
+		Usage: "List of account names or 0x-prefixed public keys to select, or \"all\" to select all accounts",
+	}
+	// ExportPasswordFileFlag is the path to a file containing the password used to encrypt exported keystores.
+	ExportPasswordFileFlag = &cli.StringFlag{
+		Name:  "export-password-file",
+		Usage: "Path to a plain-text, .txt file containing the password used to encrypt exported keystores, or set PRYSM_EXPORT_PASSWORD to the password",
+	}
+	// ExportFormatFlag defines the layout of exported accounts.
+	ExportFormatFlag = &cli.StringFlag{
+		Name:  "export-format",
+		Usage: "Layout of the exported accounts: prysm for standalone keystores, web3signer to add a key configuration per keystore for provisioning a Web3Signer remote signer or the signing hardware behind it, or web for a single accounts file importable by the Prysm web UI wallet",
+		Value: "prysm",
 	}
 	// NumAccountsFlag defines the amount of accounts to generate for derived wallets.
 	NumAccountsFlag = &cli.Int64Flag{
@@ -177,16 +361,281 @@ var (
 		Usage: "Number of accounts to generate for derived wallets",
 		Value: 1,
 	}
-	// BackupDirFlag defines the path for the zip backup of the wallet will be created.
+	// AccountIndicesFlag defines the derivation indices of the accounts to create in HD wallets.
+	AccountIndicesFlag = &cli.StringFlag{
+		Name:  "indices",
+		Usage: "Derivation indices of the accounts to create in derived wallets, as a comma separated list of indices and ranges such as 100-149",
+	}
+	// CreateCountFlag defines the amount of accounts to create at once.
+	CreateCountFlag = &cli.IntFlag{
+		Name:  "count",
+		Usage: "Number of accounts to create at once, summarized in a table along with a single deposit data file",
+		Value: 1,
+	}
+	// BackupPasswordFileFlag is the path to a file containing the password used to encrypt wallet backups.
+	BackupPasswordFileFlag = &cli.StringFlag{
+		Name:  "backup-password-file",
+		Usage: "Path to a plain-text, .txt file containing the password used to encrypt the wallet backup, or set PRYSM_BACKUP_PASSWORD to the password",
+	}
+	// BackupFileFlag is the path to a wallet backup file written by wallet-v2 backup.
+	BackupFileFlag = &cli.StringFlag{
+		Name:  "backup-file",
+		Usage: "Path to a wallet backup file written by wallet-v2 backup",
+	}
+	// MergeRestoreFlag restores the accounts of a backup into an existing wallet.
+	MergeRestoreFlag = &cli.BoolFlag{
+		Name:  "merge",
+		Usage: "Merge the accounts and slashing protection history of the backup into the existing non-HD wallet",
+	}
+	// ForceRestoreFlag allows a restore to replace accounts already in the wallet.
+	ForceRestoreFlag = &cli.BoolFlag{
+		Name:  "force",
+		Usage: "Replace accounts of the existing wallet holding the same validating public keys as the backup",
+	}
+	// BackupDirFlag defines the path where exported accounts of the wallet will be written.
 	BackupDirFlag = &cli.StringFlag{
 		Name:  "backup-dir",
-		Usage: "Path to a directory where accounts will be exported into a zip file",
+		Usage: "Path to a directory where accounts will be exported as EIP-2335 keystore files",
 		Value: DefaultValidatorDir(),
 	}
 	// KeysDirFlag defines the path for a directory where keystores to be imported at stored.
 	KeysDirFlag = &cli.StringFlag{
 		Name:  "keys-dir",
-		Usage: "Path to a directory where keystores to be imported are stored",
+		Usage: "Path to a directory, keystore file, or .zip/.tar.gz archive of keystores to be imported",
+	}
+	// KeystorePasswordsFileFlag is the path to a file mapping validating public keys to the passwords of the keystores to import.
+	KeystorePasswordsFileFlag = &cli.StringFlag{
+		Name:  "keystore-passwords-file",
+		Usage: "Path to a .csv file of pubkey,password records, or a .json object of pubkey to password, giving the password of each keystore to import from a --keys-dir directory",
+	}
+	// ImportFormatFlag defines the layout of the keystores to be imported.
+	ImportFormatFlag = &cli.StringFlag{
+		Name:  "format",
+		Usage: "Layout of the keystores to import: prysm, teku to pass --teku-keys and --teku-passwords, nimbus to pass a Nimbus data directory as --keys-dir, lighthouse to pass a Lighthouse validators directory as --keys-dir, ethdo to import --ethdo-accounts, or web to pass an accounts file of the Prysm web UI wallet as --keys-dir",
+		Value: "prysm",
+	}
+	// PrivateKeyFileFlag defines the path to a file of raw, unencrypted BLS secret keys to be imported.
+	PrivateKeyFileFlag = &cli.StringFlag{
+		Name:  "private-key-file",
+		Usage: "Path to a file of raw BLS secret keys, one hex encoded key per line, to encrypt into the wallet. Raw keys are unprotected, prefer keystores whenever possible",
+	}
+	// SkipPrivateKeyImportConfirmFlag is used to skip the confirmation prompt when importing raw private keys.
+	SkipPrivateKeyImportConfirmFlag = &cli.BoolFlag{
+		Name:  "skip-private-key-import-confirm",
+		Usage: "Skip the confirmation prompt when importing raw private keys with --private-key-file",
+	}
+	// ExpandWalletFlag writes the keystores of the accounts of a compacted wallet back.
+	ExpandWalletFlag = &cli.BoolFlag{
+		Name:  "expand",
+		Usage: "Write the keystores of the accounts of the accounts keystore of the wallet back, and remove it",
+	}
+	// AccountsKeystoreScryptNFlag defines the scrypt N cost parameter of the accounts keystore of a compacted wallet.
+	AccountsKeystoreScryptNFlag = &cli.IntFlag{
+		Name:  "accounts-keystore-scrypt-n",
+		Usage: "Scrypt N cost parameter to encrypt the accounts keystore with, a power of 2 of at least 262144",
+	}
+	// AccountsKeystoreScryptRFlag defines the scrypt r cost parameter of the accounts keystore of a compacted wallet.
+	AccountsKeystoreScryptRFlag = &cli.IntFlag{
+		Name:  "accounts-keystore-scrypt-r",
+		Usage: "Scrypt r block size parameter to encrypt the accounts keystore with, at least 8",
+	}
+	// AccountsKeystoreScryptPFlag defines the scrypt p cost parameter of the accounts keystore of a compacted wallet.
+	AccountsKeystoreScryptPFlag = &cli.IntFlag{
+		Name:  "accounts-keystore-scrypt-p",
+		Usage: "Scrypt p parallelization parameter to encrypt the accounts keystore with, at least 1",
+	}
+	// RemoveOrphansFlag removes the orphaned files of a wallet reported by wallet-v2 gc.
+	RemoveOrphansFlag = &cli.BoolFlag{
+		Name:  "remove-orphans",
+		Usage: "Remove the orphaned files of the wallet found, rather than only reporting them",
+	}
+	// SnapshotsDirFlag defines the directory wallet snapshots are kept in.
+	SnapshotsDirFlag = &cli.StringFlag{
+		Name:  "snapshots-dir",
+		Usage: "Directory wallet snapshots are kept in, wallet-snapshots in --datadir if not set",
+	}
+	// SnapshotLabelFlag defines the label of a wallet snapshot.
+	SnapshotLabelFlag = &cli.StringFlag{
+		Name:  "snapshot-label",
+		Usage: "Label of the wallet snapshot to take or restore, of letters, digits, dots, dashes and underscores",
+	}
+	// PasswordStdinFlag reads the passwords a command asks for from standard input instead of prompting for them.
+	PasswordStdinFlag = &cli.BoolFlag{
+		Name: "password-stdin",
+		Usage: "Read the passwords the command asks for from standard input, one per line in the order they are " +
+			"asked for, instead of prompting for them. Passwords given by a password file flag or by its PRYSM_ " +
+			"environment variable, such as PRYSM_WALLET_PASSWORD for --wallet-password-file, are not read",
+	}
+	// AllowWeakPasswordFlag accepts new passwords failing the password strength checks.
+	AllowWeakPasswordFlag = &cli.BoolFlag{
+		Name: "allow-weak-password",
+		Usage: "Accept new wallet, account, withdrawal, export and backup passwords which are too easy to guess. " +
+			"Keystores guard funds at stake, only use this for test networks",
+	}
+	// SkipConvertConfirmFlag is used to skip the confirmation prompt when converting a wallet.
+	SkipConvertConfirmFlag = &cli.BoolFlag{
+		Name:  "skip-convert-confirm",
+		Usage: "Skip the confirmation prompt when rebuilding a non-HD wallet as an HD wallet",
+	}
+	// WithLabelsFlag selects the accounts having all of the given labels.
+	WithLabelsFlag = &cli.StringSliceFlag{
+		Name:  "with-labels",
+		Usage: "Only select the accounts having all of these labels, each given as key=value",
+	}
+	// AddLabelsFlag defines the labels to attach to the selected accounts.
+	AddLabelsFlag = &cli.StringSliceFlag{
+		Name:  "add-labels",
+		Usage: "Labels to attach to the selected accounts, each given as key=value, replacing the value of a label they already have",
+	}
+	// RemoveLabelsFlag defines the keys of the labels to remove from the selected accounts.
+	RemoveLabelsFlag = &cli.StringSliceFlag{
+		Name:  "remove-labels",
+		Usage: "Keys of the labels to remove from the selected accounts",
+	}
+	// PubKeysFileFlag defines the path to a file listing the validating public keys of the accounts
+	// to act on.
+	PubKeysFileFlag = &cli.StringFlag{
+		Name:  "pubkeys-file",
+		Usage: "Path to a file listing the 0x-prefixed validating public keys of the accounts to select, one per line",
+	}
+	// DeletePublicKeysFlag defines the validating public keys of the accounts to delete.
+	DeletePublicKeysFlag = &cli.StringSliceFlag{
+		Name:  "delete-public-keys",
+		Usage: "List of 0x-prefixed validating public keys of the accounts to delete",
+	}
+	// SkipDeleteConfirmFlag is used to skip typing the public key of every account to delete.
+	SkipDeleteConfirmFlag = &cli.BoolFlag{
+		Name:  "skip-delete-confirm",
+		Usage: "Skip typing the public key of every account to confirm its deletion",
+	}
+	// ApprovalTokensFlag defines the approval tokens of the operators approving a destructive operation.
+	ApprovalTokensFlag = &cli.StringSliceFlag{
+		Name: "approval-tokens",
+		Usage: "Paths to the approval tokens written by wallet-v2 approve, required to delete, export or archive accounts " +
+			"of a wallet with an approval policy, back it up, convert it or restore a snapshot of it, from as many of its " +
+			"operators as it requires. Every token is accepted only once",
+	}
+	// OperatorKeyFileFlag defines the ed25519 key an operator of an approval policy signs approvals with.
+	OperatorKeyFileFlag = &cli.StringFlag{
+		Name:  "operator-key-file",
+		Usage: "Path to the key of an operator of approval policies, written by wallet-v2 generate-operator-key",
+	}
+	// ApprovalOperatorsFlag defines the operators of an approval policy.
+	ApprovalOperatorsFlag = &cli.StringSliceFlag{
+		Name:  "approval-operators",
+		Usage: "Operators of an approval policy, as name=0x<public key> of their operator keys",
+	}
+	// ApprovalsRequiredFlag defines how many operators of an approval policy must approve.
+	ApprovalsRequiredFlag = &cli.IntFlag{
+		Name:  "approvals-required",
+		Usage: "Number of operators of an approval policy whose approval tokens are required, at least 2",
+		Value: 2,
+	}
+	// ApprovalActionFlag defines the operation an approval token approves.
+	ApprovalActionFlag = &cli.StringFlag{
+		Name: "approval-action",
+		Usage: "Operation to approve: delete-accounts, export-accounts, archive-accounts, backup-wallet, convert-wallet, " +
+			"restore-snapshot or set-approval-policy",
+	}
+	// ApprovalPublicKeysFlag defines the validating public keys of the accounts an approval is for.
+	ApprovalPublicKeysFlag = &cli.StringSliceFlag{
+		Name:  "approval-public-keys",
+		Usage: "List of 0x-prefixed validating public keys of the accounts to approve the deletion, export or archival of",
+	}
+	// ApprovalValidityFlag defines how long an approval token is valid for.
+	ApprovalValidityFlag = &cli.DurationFlag{
+		Name:  "approval-validity",
+		Usage: "How long the approval token is valid for",
+		Value: 24 * time.Hour,
+	}
+	// ApprovalTokenFileFlag defines the file an approval token is written to.
+	ApprovalTokenFileFlag = &cli.StringFlag{
+		Name:  "approval-token-file",
+		Usage: "Path to write the approval token to",
+	}
+	// SecurityKeyDeviceFlag defines the FIDO2 security key a wallet password is wrapped with.
+	SecurityKeyDeviceFlag = &cli.StringFlag{
+		Name: "security-key-device",
+		Usage: "Path of the FIDO2 security key to wrap the wallet password with, such as /dev/hidraw0, " +
+			"the first security key listed by fido2-token -L if empty",
+	}
+	// TOTPCodeFlag defines the code of the authenticator app of a wallet with a TOTP secret.
+	TOTPCodeFlag = &cli.StringFlag{
+		Name:  "totp-code",
+		Usage: "Code of the authenticator app of a wallet with a TOTP secret, prompted for if not given",
+	}
+	// SkipExitConfirmFlag is used to skip typing the confirmation phrase of voluntary exits.
+	SkipExitConfirmFlag = &cli.BoolFlag{
+		Name:  "skip-exit-confirm",
+		Usage: "Skip typing the confirmation phrase to submit voluntary exits, which cannot be reversed",
+	}
+	// KeystoresURLFlag defines an https, s3 or gcs url to download the keystores to import from.
+	KeystoresURLFlag = &cli.StringFlag{
+		Name: "url",
+		Usage: "HTTPS, s3:// or gs:// url of a keystore archive (.zip or .tar.gz) or keystore json file to download and import, requires --sha256. " +
+			"Buckets are accessed with the credentials found by the default credential chain of the AWS or Google Cloud SDK",
+	}
+	// KeystoresSHA256Flag defines the expected sha256 digest of the keystores downloaded from --url.
+	KeystoresSHA256Flag = &cli.StringFlag{
+		Name:  "sha256",
+		Usage: "Hex encoded sha256 digest the keystores downloaded from --url must match",
+	}
+	// KeystoresSSEKeyFileFlag defines the customer supplied key the keystores in a bucket are encrypted with server-side.
+	KeystoresSSEKeyFileFlag = &cli.StringFlag{
+		Name:  "sse-key-file",
+		Usage: "Path to a file with the hex encoded 256-bit customer supplied key the s3:// (SSE-C) or gs:// (CSEK) object at --url is encrypted with",
+	}
+	// KeystoresDecryptionKeyFileFlag defines the key the keystores downloaded from --url were encrypted with client-side.
+	KeystoresDecryptionKeyFileFlag = &cli.StringFlag{
+		Name: "decryption-key-file",
+		Usage: "Path to a file with the hex encoded 256-bit AES-GCM key the keystores downloaded from --url were encrypted with " +
+			"before being uploaded, stored as a 12 byte nonce followed by the ciphertext. --sha256 is the digest of the decrypted keystores",
+	}
+	// IncludePubKeysFlag restricts an import to the listed validating public keys.
+	IncludePubKeysFlag = &cli.StringSliceFlag{
+		Name:  "include-pubkeys",
+		Usage: "Only import the keystores and slashing protection history of these validating public keys, each given as a 0x-prefixed hex public key or a path to a file with one public key per line",
+	}
+	// ExcludePubKeysFlag skips the listed validating public keys during an import.
+	ExcludePubKeysFlag = &cli.StringSliceFlag{
+		Name:  "exclude-pubkeys",
+		Usage: "Skip the keystores and slashing protection history of these validating public keys, each given as a 0x-prefixed hex public key or a path to a file with one public key per line",
+	}
+	// ReimportFlag overwrites accounts already in the wallet with the imported keys.
+	ReimportFlag = &cli.BoolFlag{
+		Name:  "reimport",
+		Usage: "Overwrite the accounts already in the wallet holding the same validating public keys as the imported keys, which are skipped by default. Overwritten accounts are moved to the trash of the wallet",
+	}
+	// EthdoAccountsFlag defines the ethdo accounts to import, as <wallet> or <wallet>/<account regex>.
+	EthdoAccountsFlag = &cli.StringSliceFlag{
+		Name:  "ethdo-accounts",
+		Usage: "List of ethdo accounts to import, each as <wallet> for all of its accounts or <wallet>/<account regex>",
+	}
+	// SlashingProtectionFileFlag defines the path of an EIP-3076 slashing protection interchange file
+	// carried along with exported or imported keystores.
+	SlashingProtectionFileFlag = &cli.StringFlag{
+		Name:  "slashing-protection-file",
+		Usage: "Path to an EIP-3076 slashing protection interchange file, written with the exported keystores on export and merged into the validator database before the keystores are imported on import",
+	}
+	// GenesisValidatorsRootFlag defines the genesis validators root of the network slashing protection history belongs to.
+	GenesisValidatorsRootFlag = &cli.StringFlag{
+		Name:  "genesis-validators-root",
+		Usage: "Hex encoded genesis validators root of the network, required to export slashing protection history and checked against imported history when set",
+	}
+	// LighthouseSecretsDirFlag defines the path to the Lighthouse secrets directory holding keystore passwords.
+	LighthouseSecretsDirFlag = &cli.StringFlag{
+		Name:  "lighthouse-secrets-dir",
+		Usage: "Path to the Lighthouse secrets directory of an import with --format=lighthouse, defaults to the secrets directory next to the validators directory",
+	}
+	// TekuKeysFlag defines the Teku keystore directories or keystore files to import.
+	TekuKeysFlag = &cli.StringSliceFlag{
+		Name:  "teku-keys",
+		Usage: "Teku keystore directory or keystore file to import with --format=teku, may be given several times along with --teku-passwords",
+	}
+	// TekuPasswordsFlag defines the password directories or password files of the Teku keys to import.
+	TekuPasswordsFlag = &cli.StringSliceFlag{
+		Name:  "teku-passwords",
+		Usage: "Password directory or password file of the --teku-keys given in the same position, as used by Teku",
 	}
 	// GrpcRemoteAddressFlag defines the host:port address for a remote keymanager to connect to.
 	GrpcRemoteAddressFlag = &cli.StringFlag{
@@ -215,6 +664,146 @@ var (
 		Usage: "/path/to/ca.crt for establishing a secure, TLS gRPC connection to a remote signer server",
 		Value: "",
 	}
+	// DepositDataFormatFlag defines the encoding used when writing deposit data for new
+	// direct keymanager accounts to disk.
+	DepositDataFormatFlag = &cli.StringFlag{
+		Name:  "deposit-data-format",
+		Usage: "Encoding of deposit data files written for new accounts: ssz, json, or all",
+		Value: "ssz",
+	}
+	// KeystoreFileNameFormatFlag defines the file name format of the keystores of the accounts of
+	// a new non-HD wallet.
+	KeystoreFileNameFormatFlag = &cli.StringFlag{
+		Name: "keystore-file-name-format",
+		Usage: "File name format of the keystores of the accounts of a new non-HD wallet, with a single %d for " +
+			"the creation time of the account, such as validator-keystore-%d.json",
+		Value: "keystore-%d.json",
+	}
+	// ArchiveExitedFlag selects every account which exit was submitted for archiving.
+	ArchiveExitedFlag = &cli.BoolFlag{
+		Name:  "exited",
+		Usage: "Archive every account of the wallet which exit was submitted with accounts-v2 exit",
+	}
+	// AccountNotesFlag defines the free-form notes attached to accounts.
+	AccountNotesFlag = &cli.StringFlag{
+		Name:  "notes",
+		Usage: "Free-form notes to attach to the selected accounts, such as the customer or deployment they belong to. Empty notes remove them",
+	}
+	// TrashRetentionFlag defines how long deleted accounts are kept in the trash of a wallet.
+	TrashRetentionFlag = &cli.DurationFlag{
+		Name:  "trash-retention",
+		Usage: "How long deleted accounts are kept in the trash of the wallet, from which they can be restored, before being erased",
+		Value: 30 * 24 * time.Hour,
+	}
+	// DeactivateAfterEpochFlag defines the epoch after which the selected accounts stop being served.
+	DeactivateAfterEpochFlag = &cli.Uint64Flag{
+		Name:  "deactivate-after-epoch",
+		Usage: "Last epoch the selected accounts are served in by the validator client",
+	}
+	// DeactivateAfterFlag defines the date after which the selected accounts stop being served.
+	DeactivateAfterFlag = &cli.StringFlag{
+		Name:  "deactivate-after",
+		Usage: "RFC 3339 date after which the selected accounts are no longer served by the validator client, such as 2020-10-01T12:00:00Z",
+	}
+	// CancelDeactivationFlag removes the scheduled deactivation of the selected accounts.
+	CancelDeactivationFlag = &cli.BoolFlag{
+		Name:  "cancel-deactivation",
+		Usage: "Remove the scheduled deactivation of the selected accounts",
+	}
+	// NewAccountPasswordFileFlag defines the path to a file containing the new password of accounts.
+	NewAccountPasswordFileFlag = &cli.StringFlag{
+		Name:  "new-account-password-file",
+		Usage: "Path to a plain-text, .txt file containing the new password to re-encrypt the keystores of the selected accounts with, or set PRYSM_NEW_ACCOUNT_PASSWORD to the password",
+	}
+	// NewWalletPasswordFileFlag defines the path to a file containing the new password of a wallet.
+	NewWalletPasswordFileFlag = &cli.StringFlag{
+		Name:  "new-wallet-password-file",
+		Usage: "Path to a plain-text, .txt file containing the new password to re-encrypt the wallet with, or set PRYSM_NEW_WALLET_PASSWORD to the password",
+	}
+	// MessageFlag defines the message accounts-v2 sign-message signs.
+	MessageFlag = &cli.StringFlag{
+		Name:  "message",
+		Usage: "Message to sign with the validating key of an account, to prove control of its public key",
+	}
+	// SourceWalletDirFlag defines the path to the wallet account metadata is copied from.
+	SourceWalletDirFlag = &cli.StringFlag{
+		Name:  "source-wallet-dir",
+		Usage: "Path to the wallet to copy the metadata of accounts from",
+	}
+	// SourceWalletPasswordFileFlag defines the path to a file containing the password of the wallet
+	// account metadata is copied from.
+	SourceWalletPasswordFileFlag = &cli.StringFlag{
+		Name:  "source-wallet-password-file",
+		Usage: "Path to a plain-text, .txt file containing the password of the wallet to copy the metadata of accounts from, or set PRYSM_SOURCE_WALLET_PASSWORD to the password",
+	}
+	// DryRunFlag generates the keys and deposit data of new accounts without writing anything to disk.
+	DryRunFlag = &cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "Generate the keys and deposit data of the new accounts and print what would be created, without writing anything to disk",
+	}
+	// StoreWithdrawalKeyFlag stores the withdrawal key of new direct keymanager accounts
+	// as an encrypted keystore in the wallet instead of writing it outside of the wallet.
+	StoreWithdrawalKeyFlag = &cli.BoolFlag{
+		Name:  "store-withdrawal-key",
+		Usage: "Store the withdrawal key of new accounts in the wallet as an EIP-2335 keystore encrypted with a separate withdrawal password, instead of writing it to --withdrawal-keystores-dir",
+	}
+	// PrintWithdrawalKeyFlag displays the random withdrawal key of new direct keymanager
+	// accounts once instead of writing it to an encrypted keystore.
+	PrintWithdrawalKeyFlag = &cli.BoolFlag{
+		Name:  "print-withdrawal-key",
+		Usage: "Print the withdrawal private key of new accounts once, as earlier versions did, instead of writing it to an EIP-2335 keystore in --withdrawal-keystores-dir",
+	}
+	// WithdrawalPasswordFileFlag defines the path to a file containing the password the withdrawal
+	// keystores of new accounts are encrypted with.
+	WithdrawalPasswordFileFlag = &cli.StringFlag{
+		Name:  "withdrawal-password-file",
+		Usage: "Path to a plain-text, .txt file containing the password to encrypt the withdrawal keystores of new accounts with, or set PRYSM_WITHDRAWAL_PASSWORD to the password",
+	}
+	// WithdrawalMnemonicFileFlag defines the path to a file containing a separate mnemonic the
+	// withdrawal keys of new direct keymanager accounts are derived from.
+	WithdrawalMnemonicFileFlag = &cli.StringFlag{
+		Name:  "withdrawal-mnemonic-file",
+		Usage: "Path to a plain-text file containing a separate mnemonic to derive the withdrawal keys of accounts from",
+	}
+	// WithdrawalCredentialsFlag defines externally supplied withdrawal credentials, or the withdrawal
+	// public key they are formed from, which the deposit data of new direct keymanager accounts is
+	// bound to.
+	WithdrawalCredentialsFlag = &cli.StringFlag{
+		Name:  "withdrawal-credentials",
+		Usage: "0x-prefixed withdrawal credentials, or BLS withdrawal public key, to bind the deposit data of new accounts to instead of generating a withdrawal key",
+	}
+	// WithdrawalKeystoresDirFlag defines the directory the withdrawal keystores of accounts are written to.
+	WithdrawalKeystoresDirFlag = &cli.StringFlag{
+		Name:  "withdrawal-keystores-dir",
+		Usage: "Path to a directory where the withdrawal keystores of accounts will be written",
+		Value: DefaultValidatorDir(),
+	}
+	// DepositDataOutputDirFlag defines the directory where the aggregated deposit data of accounts is written.
+	DepositDataOutputDirFlag = &cli.StringFlag{
+		Name:  "deposit-data-output-dir",
+		Usage: "Path to a directory where the launchpad-compatible deposit_data-<timestamp>.json of the selected accounts will be written",
+		Value: DefaultValidatorDir(),
+	}
+	// EncryptKeymanagerConfigFlag encrypts the keymanager config file with the wallet password.
+	EncryptKeymanagerConfigFlag = &cli.BoolFlag{
+		Name:  "encrypt-keymanager-config",
+		Usage: "Encrypt the keymanager config file, which may contain remote signer endpoints and credentials, with the wallet password",
+	}
+	// HTTPWeb3ProviderFlag defines the eth1 node the deposits of accounts are tracked with.
+	HTTPWeb3ProviderFlag = &cli.StringFlag{
+		Name:  "http-web3provider",
+		Usage: "An eth1 web3 provider string http endpoint to read the logs of the deposit contract from",
+	}
+	// DepositContractFlag defines the deposit contract the deposits of accounts are tracked in.
+	DepositContractFlag = &cli.StringFlag{
+		Name:  "deposit-contract",
+		Usage: "Address of the deposit contract to track the deposits of accounts in, the deposit contract of the beacon network by default",
+	}
+	// DepositFromBlockFlag defines the eth1 block the logs of the deposit contract are read from.
+	DepositFromBlockFlag = &cli.Uint64Flag{
+		Name:  "deposit-from-block",
+		Usage: "Eth1 block to read the logs of the deposit contract from, such as the block the contract was deployed in",
+	}
 	// KeymanagerKindFlag defines the kind of keymanager desired by a user during wallet creation.
 	KeymanagerKindFlag = &cli.StringFlag{
 		Name:  "keymanager-kind",
@@ -44,3 +44,12 @@ func (km *Direct) Sign(pubKey [48]byte, root [32]byte) (bls.Signature, error) {
 	}
 	return nil, ErrNoSuchKey
 }
+
+// SecretKeys returns the secret keys held by the key manager.
+func (km *Direct) SecretKeys() (map[[48]byte]bls.SecretKey, error) {
+	keys := make(map[[48]byte]bls.SecretKey, len(km.secretKeys))
+	for pubKey, secretKey := range km.secretKeys {
+		keys[pubKey] = secretKey
+	}
+	return keys, nil
+}
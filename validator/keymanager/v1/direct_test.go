@@ -56,3 +56,17 @@ func TestSign(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, true, sig.Verify(sks[0].PublicKey(), bytesutil.FromBytes32(msg)), "Failed to verify generated signature")
 }
+
+func TestDirectSecretKeys(t *testing.T) {
+	sks := []bls.SecretKey{bls.RandKey(), bls.RandKey()}
+	direct := keymanager.NewDirect(sks)
+	keys, err := direct.SecretKeys()
+	require.NoError(t, err)
+	require.Equal(t, len(sks), len(keys))
+	for _, sk := range sks {
+		pubKey := bytesutil.ToBytes48(sk.PublicKey().Marshal())
+		secretKey, ok := keys[pubKey]
+		require.Equal(t, true, ok, "Missing secret key for public key")
+		assert.DeepEqual(t, sk.Marshal(), secretKey.Marshal())
+	}
+}
@@ -2,6 +2,7 @@ package v1
 
 import (
 	"errors"
+	"fmt"
 
 	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
 	"github.com/prysmaticlabs/prysm/shared/bls"
@@ -37,3 +38,29 @@ type ProtectingKeyManager interface {
 	// SignAttestation signs an attestation for the validator to broadcast.
 	SignAttestation(pubKey [48]byte, domain [32]byte, data *ethpb.AttestationData) (bls.Signature, error)
 }
+
+// SecretKeyExporter provides access to the secret keys held by a keymanager, allowing
+// them to be migrated to a different key storage format.
+type SecretKeyExporter interface {
+	// SecretKeys returns the secret keys known to the keymanager, keyed by public key.
+	SecretKeys() (map[[48]byte]bls.SecretKey, error)
+}
+
+// NewKeyManager creates a keymanager of the given kind from its JSON options,
+// returning the help text for that keymanager alongside any error.
+func NewKeyManager(manager string, opts string) (KeyManager, string, error) {
+	switch manager {
+	case "interop":
+		return NewInterop(opts)
+	case "unencrypted":
+		return NewUnencrypted(opts)
+	case "keystore":
+		return NewKeystore(opts)
+	case "wallet":
+		return NewWallet(opts)
+	case "remote":
+		return NewRemoteWallet(opts)
+	default:
+		return nil, "", fmt.Errorf("unknown keymanager %q", manager)
+	}
+}
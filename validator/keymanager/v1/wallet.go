@@ -157,3 +157,25 @@ func (km *Wallet) Sign(pubKey [48]byte, root [32]byte) (bls.Signature, error) {
 	}
 	return bls.SignatureFromBytes(sig.Marshal())
 }
+
+// SecretKeys returns the secret keys of the unlocked accounts in the wallet.
+func (km *Wallet) SecretKeys() (map[[48]byte]bls.SecretKey, error) {
+	ctx := context.Background()
+	keys := make(map[[48]byte]bls.SecretKey, len(km.accounts))
+	for pubKey, account := range km.accounts {
+		provider, ok := account.(e2wtypes.AccountPrivateKeyProvider)
+		if !ok {
+			return nil, errors.New("account does not implement the AccountPrivateKeyProvider interface")
+		}
+		privateKey, err := provider.PrivateKey(ctx)
+		if err != nil {
+			return nil, err
+		}
+		secretKey, err := bls.SecretKeyFromBytes(privateKey.Marshal())
+		if err != nil {
+			return nil, err
+		}
+		keys[pubKey] = secretKey
+	}
+	return keys, nil
+}
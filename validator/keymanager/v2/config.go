@@ -0,0 +1,68 @@
+package v2
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+)
+
+// ErrConfigPasswordRequired is returned when attempting to read an encrypted
+// keymanager configuration file without providing the wallet password.
+var ErrConfigPasswordRequired = errors.New("keymanager config file is encrypted, a wallet password is required")
+
+// EncryptedConfig json file representation of a keymanager configuration
+// file encrypted with the wallet password as an EIP-2335 crypto payload.
+type EncryptedConfig struct {
+	Crypto  map[string]interface{} `json:"crypto"`
+	Version uint                   `json:"version"`
+}
+
+// EncryptConfig encrypts a marshaled keymanager configuration file using
+// the wallet password, returning the JSON encoded EncryptedConfig.
+func EncryptConfig(enc []byte, password string) ([]byte, error) {
+	if password == "" {
+		return nil, errors.New("cannot encrypt keymanager config with an empty password")
+	}
+	encryptor := keystorev4.New()
+	cryptoFields, err := encryptor.Encrypt(enc, password)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encrypt keymanager config")
+	}
+	return json.MarshalIndent(&EncryptedConfig{
+		Crypto:  cryptoFields,
+		Version: encryptor.Version(),
+	}, "", "\t")
+}
+
+// DecryptConfig returns the plaintext of a keymanager configuration file. Files
+// which are not encrypted are returned as-is, allowing callers to transparently
+// handle both encrypted and unencrypted configurations.
+func DecryptConfig(enc []byte, password string) ([]byte, error) {
+	if !IsEncryptedConfig(enc) {
+		return enc, nil
+	}
+	if password == "" {
+		return nil, ErrConfigPasswordRequired
+	}
+	encryptedCfg := &EncryptedConfig{}
+	if err := json.Unmarshal(enc, encryptedCfg); err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal encrypted keymanager config")
+	}
+	decrypted, err := keystorev4.New().Decrypt(encryptedCfg.Crypto, password)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decrypt keymanager config, wrong wallet password")
+	}
+	return decrypted, nil
+}
+
+// IsEncryptedConfig checks whether a keymanager configuration file
+// was written as an EncryptedConfig.
+func IsEncryptedConfig(enc []byte) bool {
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(enc, &fields); err != nil {
+		return false
+	}
+	_, ok := fields["crypto"]
+	return ok
+}
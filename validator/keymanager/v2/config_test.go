@@ -0,0 +1,58 @@
+package v2_test
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/remote"
+)
+
+func TestEncryptConfig_RoundTrip(t *testing.T) {
+	password := "Passw0rdz2020%"
+	plaintext := []byte(`{"remote_address":"localhost:4000"}`)
+	enc, err := v2keymanager.EncryptConfig(plaintext, password)
+	require.NoError(t, err)
+	assert.Equal(t, true, v2keymanager.IsEncryptedConfig(enc))
+	assert.Equal(t, false, v2keymanager.IsEncryptedConfig(plaintext))
+	assert.Equal(t, false, strings.Contains(string(enc), "localhost:4000"))
+
+	decrypted, err := v2keymanager.DecryptConfig(enc, password)
+	require.NoError(t, err)
+	assert.DeepEqual(t, plaintext, decrypted)
+
+	_, err = v2keymanager.DecryptConfig(enc, "wrongpassword")
+	assert.ErrorContains(t, "wrong wallet password", err)
+	_, err = v2keymanager.DecryptConfig(enc, "")
+	assert.ErrorContains(t, v2keymanager.ErrConfigPasswordRequired.Error(), err)
+
+	// Unencrypted files are returned untouched.
+	decrypted, err = v2keymanager.DecryptConfig(plaintext, "")
+	require.NoError(t, err)
+	assert.DeepEqual(t, plaintext, decrypted)
+}
+
+func TestUnmarshalConfigFile_Encrypted(t *testing.T) {
+	ctx := context.Background()
+	password := "Passw0rdz2020%"
+	cfg := &remote.Config{
+		RemoteAddr: "localhost:4000",
+		RemoteCertificate: &remote.CertificateConfig{
+			ClientCertPath: "/tmp/client.crt",
+			ClientKeyPath:  "/tmp/client.key",
+			CACertPath:     "/tmp/ca.crt",
+		},
+	}
+	plaintext, err := remote.MarshalConfigFile(ctx, cfg)
+	require.NoError(t, err)
+	enc, err := v2keymanager.EncryptConfig(plaintext, password)
+	require.NoError(t, err)
+
+	decoded, err := remote.UnmarshalConfigFile(ioutil.NopCloser(strings.NewReader(string(enc))), password)
+	require.NoError(t, err)
+	assert.DeepEqual(t, cfg, decoded)
+}
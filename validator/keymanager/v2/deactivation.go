@@ -0,0 +1,95 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	validatorpb "github.com/prysmaticlabs/prysm/proto/validator/accounts/v2"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
+)
+
+// Deactivation schedules when an account stops being served, either after an epoch or after a
+// point in time, whichever comes first.
+type Deactivation struct {
+	AfterEpoch *uint64    `json:"after_epoch,omitempty"`
+	After      *time.Time `json:"after,omitempty"`
+}
+
+// DeactivatingKeymanager stops serving the keys of a keymanager once their scheduled
+// deactivation is reached, such as keys being handed off to another operator or exited at a
+// planned time. Deactivated keys are no longer returned as validating keys and signing with
+// them fails.
+type DeactivatingKeymanager struct {
+	keymanager  IKeymanager
+	schedule    map[[48]byte]*Deactivation
+	genesisTime uint64
+	lock        sync.RWMutex
+}
+
+// NewDeactivatingKeymanager deactivates the keys of a keymanager on the given schedule.
+func NewDeactivatingKeymanager(keymanager IKeymanager, schedule map[[48]byte]*Deactivation) *DeactivatingKeymanager {
+	return &DeactivatingKeymanager{
+		keymanager: keymanager,
+		schedule:   schedule,
+	}
+}
+
+// SetGenesisTime sets the genesis time of the beacon chain, in unix seconds, which the current
+// epoch of deactivations after an epoch is computed from. Until it is set, keys scheduled for
+// deactivation after an epoch are not served, as it cannot be told whether the epoch was reached.
+func (m *DeactivatingKeymanager) SetGenesisTime(genesisTime uint64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.genesisTime = genesisTime
+}
+
+// FetchValidatingPublicKeys returns the validating public keys of the keymanager which are not
+// deactivated.
+func (m *DeactivatingKeymanager) FetchValidatingPublicKeys(ctx context.Context) ([][48]byte, error) {
+	keys, err := m.keymanager.FetchValidatingPublicKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	active := make([][48]byte, 0, len(keys))
+	for _, key := range keys {
+		if !m.deactivated(key) {
+			active = append(active, key)
+		}
+	}
+	return active, nil
+}
+
+// Sign signs a message with the keymanager, unless the public key of the request is deactivated.
+func (m *DeactivatingKeymanager) Sign(ctx context.Context, req *validatorpb.SignRequest) (bls.Signature, error) {
+	if m.deactivated(bytesutil.ToBytes48(req.PublicKey)) {
+		return nil, fmt.Errorf("public key %#x is deactivated", bytesutil.Trunc(req.PublicKey))
+	}
+	return m.keymanager.Sign(ctx, req)
+}
+
+func (m *DeactivatingKeymanager) deactivated(pubKey [48]byte) bool {
+	deactivation, ok := m.schedule[pubKey]
+	if !ok {
+		return false
+	}
+	now := roughtime.Now()
+	if deactivation.After != nil && !now.Before(*deactivation.After) {
+		return true
+	}
+	if deactivation.AfterEpoch == nil {
+		return false
+	}
+	m.lock.RLock()
+	genesisTime := m.genesisTime
+	m.lock.RUnlock()
+	if genesisTime == 0 {
+		return true
+	}
+	epochStart := genesisTime + (*deactivation.AfterEpoch+1)*params.BeaconConfig().SlotsPerEpoch*params.BeaconConfig().SecondsPerSlot
+	return uint64(now.Unix()) >= epochStart
+}
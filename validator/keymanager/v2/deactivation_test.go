@@ -0,0 +1,55 @@
+package v2_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	validatorpb "github.com/prysmaticlabs/prysm/proto/validator/accounts/v2"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/shared/roughtime"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+)
+
+func TestDeactivatingKeymanager(t *testing.T) {
+	ctx := context.Background()
+	keys := &keysKeymanager{keys: []bls.SecretKey{bls.RandKey(), bls.RandKey(), bls.RandKey(), bls.RandKey()}}
+	pubKeys := make([][48]byte, len(keys.keys))
+	for i, key := range keys.keys {
+		pubKeys[i] = bytesutil.ToBytes48(key.PublicKey().Marshal())
+	}
+	past := roughtime.Now().Add(-time.Minute)
+	future := roughtime.Now().Add(time.Hour)
+	pastEpoch, futureEpoch := uint64(10), uint64(1000)
+	km := v2keymanager.NewDeactivatingKeymanager(keys, map[[48]byte]*v2keymanager.Deactivation{
+		pubKeys[0]: {After: &past},
+		pubKeys[1]: {After: &future},
+		pubKeys[2]: {AfterEpoch: &pastEpoch},
+		pubKeys[3]: {AfterEpoch: &futureEpoch},
+	})
+
+	// Keys deactivated after an epoch are not served until the genesis time is known.
+	active, err := km.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	assert.DeepEqual(t, [][48]byte{pubKeys[1]}, active)
+
+	// The chain is 20 epochs old.
+	epochDuration := params.BeaconConfig().SlotsPerEpoch * params.BeaconConfig().SecondsPerSlot
+	km.SetGenesisTime(uint64(roughtime.Now().Unix()) - 20*epochDuration)
+	active, err = km.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	assert.DeepEqual(t, [][48]byte{pubKeys[1], pubKeys[3]}, active)
+
+	root := []byte("root")
+	sig, err := km.Sign(ctx, &validatorpb.SignRequest{PublicKey: pubKeys[3][:], SigningRoot: root})
+	require.NoError(t, err)
+	assert.DeepEqual(t, keys.keys[3].Sign(root).Marshal(), sig.Marshal())
+	for _, i := range []int{0, 2} {
+		_, err = km.Sign(ctx, &validatorpb.SignRequest{PublicKey: pubKeys[i][:], SigningRoot: root})
+		assert.ErrorContains(t, "is deactivated", err)
+	}
+}
@@ -7,10 +7,12 @@ import (
 	"io"
 	"io/ioutil"
 	"path"
+	"sort"
 	"sync"
 
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
 	"github.com/prysmaticlabs/go-ssz"
 	validatorpb "github.com/prysmaticlabs/prysm/proto/validator/accounts/v2"
 	"github.com/prysmaticlabs/prysm/shared/bls"
@@ -19,6 +21,7 @@ import (
 	"github.com/prysmaticlabs/prysm/shared/petnames"
 	"github.com/prysmaticlabs/prysm/shared/rand"
 	"github.com/prysmaticlabs/prysm/validator/accounts/v2/iface"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
 	"github.com/sirupsen/logrus"
 	"github.com/tyler-smith/go-bip39"
 	util "github.com/wealdtech/go-eth2-util"
@@ -46,6 +49,9 @@ const (
 type Config struct {
 	DerivedPathStructure string
 	DerivedEIPNumber     string
+	// TOTPEnrolled is true once the wallet has a TOTP secret, so its sensitive account commands
+	// require a code even if the TOTP file of the wallet is removed.
+	TOTPEnrolled bool `json:",omitempty"`
 }
 
 // Keymanager implementation for derived, HD keymanager using EIP-2333 and EIP-2334.
@@ -67,6 +73,9 @@ type SeedConfig struct {
 	NextAccount uint64                 `json:"next_account"`
 	Version     uint                   `json:"version"`
 	Name        string                 `json:"name"`
+	// Indices of the accounts of the wallet in ascending order, only set once they are
+	// not the consecutive indices below NextAccount.
+	Indices []uint64 `json:"indices,omitempty"`
 }
 
 // DefaultConfig for a derived keymanager implementation.
@@ -128,8 +137,9 @@ func NewKeymanager(
 }
 
 // UnmarshalConfigFile attempts to JSON unmarshal a derived keymanager
-// configuration file into the *Config{} struct.
-func UnmarshalConfigFile(r io.ReadCloser) (*Config, error) {
+// configuration file into the *Config{} struct. If the file was encrypted
+// with the wallet password, it is transparently decrypted first.
+func UnmarshalConfigFile(r io.ReadCloser, password string) (*Config, error) {
 	enc, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, err
@@ -139,6 +149,10 @@ func UnmarshalConfigFile(r io.ReadCloser) (*Config, error) {
 			log.Errorf("Could not close keymanager config file: %v", err)
 		}
 	}()
+	enc, err = v2keymanager.DecryptConfig(enc, password)
+	if err != nil {
+		return nil, err
+	}
 	cfg := &Config{}
 	if err := json.Unmarshal(enc, cfg); err != nil {
 		return nil, err
@@ -212,11 +226,65 @@ func SeedFileFromMnemonic(ctx context.Context, mnemonic string, password string)
 	}, nil
 }
 
+// ValidatingPublicKeysFromMnemonic derives the validating public keys of the first numAccounts
+// accounts of the derived wallet of a mnemonic, indexed by account number.
+func ValidatingPublicKeysFromMnemonic(mnemonic string, numAccounts uint64) ([][48]byte, error) {
+	if ok := bip39.IsMnemonicValid(mnemonic); !ok {
+		return nil, bip39.ErrInvalidMnemonic
+	}
+	seed := bip39.NewSeed(mnemonic, "")
+	publicKeys := make([][48]byte, numAccounts)
+	for i := uint64(0); i < numAccounts; i++ {
+		validatingKeyPath := fmt.Sprintf(ValidatingKeyDerivationPathTemplate, i)
+		validatingKey, err := util.PrivateKeyFromSeedAndPath(seed, validatingKeyPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to derive validating key for account %d", i)
+		}
+		publicKeys[i] = bytesutil.ToBytes48(validatingKey.PublicKey().Marshal())
+	}
+	return publicKeys, nil
+}
+
+// WithdrawalKeyFromMnemonic derives the withdrawal key of the given account number from a mnemonic,
+// at the withdrawal key path of EIP-2334.
+func WithdrawalKeyFromMnemonic(mnemonic string, accountNumber uint64) (bls.SecretKey, error) {
+	if ok := bip39.IsMnemonicValid(mnemonic); !ok {
+		return nil, bip39.ErrInvalidMnemonic
+	}
+	seed := bip39.NewSeed(mnemonic, "")
+	withdrawalKeyPath := fmt.Sprintf(WithdrawalKeyDerivationPathTemplate, accountNumber)
+	withdrawalKey, err := util.PrivateKeyFromSeedAndPath(seed, withdrawalKeyPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to derive withdrawal key for account %d", accountNumber)
+	}
+	return bls.SecretKeyFromBytes(withdrawalKey.Marshal())
+}
+
 // MarshalEncryptedSeedFile json encodes the seed configuration for a derived keymanager.
 func MarshalEncryptedSeedFile(ctx context.Context, seedCfg *SeedConfig) ([]byte, error) {
 	return json.MarshalIndent(seedCfg, "", "\t")
 }
 
+// ReencryptSeedConfig returns a copy of a seed configuration with its seed, which must decrypt with
+// the old password, encrypted with the new password instead. Its uuid and accounts are unchanged.
+func ReencryptSeedConfig(seedCfg *SeedConfig, oldPassword string, newPassword string) (*SeedConfig, error) {
+	encryptor := keystorev4.New()
+	seed, err := encryptor.Decrypt(seedCfg.Crypto, oldPassword)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decrypt seed configuration with password")
+	}
+	defer v2keymanager.ZeroSecret(seed)
+	cryptoFields, err := encryptor.Encrypt(seed, newPassword)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encrypt seed into keystore")
+	}
+	reencrypted := *seedCfg
+	reencrypted.Crypto = cryptoFields
+	reencrypted.Version = encryptor.Version()
+	reencrypted.Name = encryptor.Name()
+	return &reencrypted, nil
+}
+
 // Config returns the derived keymanager configuration.
 func (dr *Keymanager) Config() *Config {
 	return dr.cfg
@@ -230,7 +298,7 @@ func (dr *Keymanager) NextAccountNumber(ctx context.Context) uint64 {
 // ValidatingAccountNames for the derived keymanager.
 func (dr *Keymanager) ValidatingAccountNames(ctx context.Context) ([]string, error) {
 	names := make([]string, 0)
-	for i := uint64(0); i < dr.seedCfg.NextAccount; i++ {
+	for _, i := range dr.AccountIndices() {
 		validatingKeyPath := fmt.Sprintf(ValidatingKeyDerivationPathTemplate, i)
 		validatingKey, err := util.PrivateKeyFromSeedAndPath(dr.seed, validatingKeyPath)
 		if err != nil {
@@ -247,15 +315,95 @@ func (dr *Keymanager) ValidatingAccountNames(ctx context.Context) ([]string, err
 // persisting accounts to disk. Each account stores the generated keystore.json file.
 // The entire derived wallet seed phrase can be recovered from a BIP-39 english mnemonic.
 func (dr *Keymanager) CreateAccount(ctx context.Context, logAccountInfo bool) (string, error) {
-	withdrawalKeyPath := fmt.Sprintf(WithdrawalKeyDerivationPathTemplate, dr.seedCfg.NextAccount)
-	validatingKeyPath := fmt.Sprintf(ValidatingKeyDerivationPathTemplate, dr.seedCfg.NextAccount)
+	return dr.createAccount(ctx, dr.seedCfg.NextAccount, logAccountInfo)
+}
+
+// CreateAccountAtIndex creates the account of a specific derivation index, so ranges of accounts
+// of the same seed can be spread across wallets. Accounts created afterwards with CreateAccount
+// are derived at the indices following the highest index of the wallet.
+func (dr *Keymanager) CreateAccountAtIndex(ctx context.Context, index uint64, logAccountInfo bool) (string, error) {
+	for _, i := range dr.AccountIndices() {
+		if i == index {
+			return "", fmt.Errorf("wallet already has the account of index %d", index)
+		}
+	}
+	return dr.createAccount(ctx, index, logAccountInfo)
+}
+
+// PlannedAccount is the account of a derivation index as it would be created, for dry runs.
+type PlannedAccount struct {
+	Index       uint64
+	Name        string
+	PublicKey   [48]byte
+	DepositData *ethpb.Deposit_Data
+}
+
+// PlanAccounts derives the keys and computes the deposit data of the accounts of the given
+// derivation indices, as creating them would, without writing anything to disk.
+func (dr *Keymanager) PlanAccounts(indices []uint64) ([]*PlannedAccount, error) {
+	existing := make(map[uint64]bool)
+	for _, i := range dr.AccountIndices() {
+		existing[i] = true
+	}
+	accounts := make([]*PlannedAccount, len(indices))
+	for i, index := range indices {
+		if existing[index] {
+			return nil, fmt.Errorf("wallet already has the account of index %d", index)
+		}
+		withdrawalKey, err := util.PrivateKeyFromSeedAndPath(dr.seed, fmt.Sprintf(WithdrawalKeyDerivationPathTemplate, index))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create withdrawal key for account %d", index)
+		}
+		validatingKey, err := util.PrivateKeyFromSeedAndPath(dr.seed, fmt.Sprintf(ValidatingKeyDerivationPathTemplate, index))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create validating key for account %d", index)
+		}
+		blsValidatingKey, err := bls.SecretKeyFromBytes(validatingKey.Marshal())
+		if err != nil {
+			return nil, err
+		}
+		blsWithdrawalKey, err := bls.SecretKeyFromBytes(withdrawalKey.Marshal())
+		if err != nil {
+			return nil, err
+		}
+		_, depositData, err := depositutil.GenerateDepositTransaction(blsValidatingKey, blsWithdrawalKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not generate deposit transaction data")
+		}
+		accounts[i] = &PlannedAccount{
+			Index:       index,
+			Name:        petnames.DeterministicName(validatingKey.Marshal(), "-"),
+			PublicKey:   bytesutil.ToBytes48(validatingKey.PublicKey().Marshal()),
+			DepositData: depositData,
+		}
+	}
+	return accounts, nil
+}
+
+// AccountIndices returns the derivation indices of the accounts of the wallet in ascending order.
+func (dr *Keymanager) AccountIndices() []uint64 {
+	if dr.seedCfg.Indices != nil {
+		indices := make([]uint64, len(dr.seedCfg.Indices))
+		copy(indices, dr.seedCfg.Indices)
+		return indices
+	}
+	indices := make([]uint64, dr.seedCfg.NextAccount)
+	for i := range indices {
+		indices[i] = uint64(i)
+	}
+	return indices
+}
+
+func (dr *Keymanager) createAccount(ctx context.Context, index uint64, logAccountInfo bool) (string, error) {
+	withdrawalKeyPath := fmt.Sprintf(WithdrawalKeyDerivationPathTemplate, index)
+	validatingKeyPath := fmt.Sprintf(ValidatingKeyDerivationPathTemplate, index)
 	withdrawalKey, err := util.PrivateKeyFromSeedAndPath(dr.seed, withdrawalKeyPath)
 	if err != nil {
-		return "", errors.Wrapf(err, "failed to create withdrawal key for account %d", dr.seedCfg.NextAccount)
+		return "", errors.Wrapf(err, "failed to create withdrawal key for account %d", index)
 	}
 	validatingKey, err := util.PrivateKeyFromSeedAndPath(dr.seed, validatingKeyPath)
 	if err != nil {
-		return "", errors.Wrapf(err, "failed to create validating key for account %d", dr.seedCfg.NextAccount)
+		return "", errors.Wrapf(err, "failed to create validating key for account %d", index)
 	}
 
 	// Upon confirmation of the withdrawal key, proceed to display
@@ -289,17 +437,28 @@ func (dr *Keymanager) CreateAccount(ctx context.Context, logAccountInfo bool) (s
 	}
 
 	// Finally, write the account creation timestamps as a files.
-	newAccountNumber := dr.seedCfg.NextAccount
 	if logAccountInfo {
 		log.WithFields(logrus.Fields{
-			"accountNumber":       newAccountNumber,
+			"accountNumber":       index,
 			"withdrawalPublicKey": fmt.Sprintf("%#x", withdrawalKey.PublicKey().Marshal()),
 			"validatingPublicKey": fmt.Sprintf("%#x", validatingKey.PublicKey().Marshal()),
 			"withdrawalKeyPath":   path.Join(dr.wallet.AccountsDir(), withdrawalKeyPath),
 			"validatingKeyPath":   path.Join(dr.wallet.AccountsDir(), validatingKeyPath),
 		}).Info("Successfully created new validator account")
 	}
-	dr.seedCfg.NextAccount++
+	// Indices are only recorded once the accounts are not consecutive from 0.
+	if dr.seedCfg.Indices == nil && index == dr.seedCfg.NextAccount {
+		dr.seedCfg.NextAccount++
+	} else {
+		indices := append(dr.AccountIndices(), index)
+		sort.Slice(indices, func(i, j int) bool {
+			return indices[i] < indices[j]
+		})
+		dr.seedCfg.Indices = indices
+		if index >= dr.seedCfg.NextAccount {
+			dr.seedCfg.NextAccount = index + 1
+		}
+	}
 	encodedCfg, err := MarshalEncryptedSeedFile(ctx, dr.seedCfg)
 	if err != nil {
 		return "", errors.Wrap(err, "could not marshal encrypted seed file")
@@ -307,7 +466,7 @@ func (dr *Keymanager) CreateAccount(ctx context.Context, logAccountInfo bool) (s
 	if err := dr.wallet.WriteEncryptedSeedToDisk(ctx, encodedCfg); err != nil {
 		return "", errors.Wrap(err, "could not write encrypted seed file to disk")
 	}
-	return fmt.Sprintf("%d", newAccountNumber), nil
+	return fmt.Sprintf("%d", index), nil
 }
 
 // Sign signs a message using a validator key.
@@ -329,10 +488,11 @@ func (dr *Keymanager) Sign(ctx context.Context, req *validatorpb.SignRequest) (b
 func (dr *Keymanager) FetchValidatingPublicKeys(ctx context.Context) ([][48]byte, error) {
 	// Return the public keys from the cache if they match the
 	// number of accounts from the wallet.
-	publicKeys := make([][48]byte, dr.seedCfg.NextAccount)
+	indices := dr.AccountIndices()
+	publicKeys := make([][48]byte, len(indices))
 	dr.lock.RLock()
 	defer dr.lock.RUnlock()
-	if dr.keysCache != nil && uint64(len(dr.keysCache)) == dr.seedCfg.NextAccount {
+	if dr.keysCache != nil && len(dr.keysCache) == len(indices) {
 		var i int
 		for k := range dr.keysCache {
 			publicKeys[i] = k
@@ -340,7 +500,7 @@ func (dr *Keymanager) FetchValidatingPublicKeys(ctx context.Context) ([][48]byte
 		}
 		return publicKeys, nil
 	}
-	for i := uint64(0); i < dr.seedCfg.NextAccount; i++ {
+	for _, i := range indices {
 		validatingKeyPath := fmt.Sprintf(ValidatingKeyDerivationPathTemplate, i)
 		validatingKey, err := util.PrivateKeyFromSeedAndPath(dr.seed, validatingKeyPath)
 		if err != nil {
@@ -354,7 +514,7 @@ func (dr *Keymanager) FetchValidatingPublicKeys(ctx context.Context) ([][48]byte
 // FetchWithdrawalPublicKeys fetches the list of withdrawal public keys from keymanager
 func (dr *Keymanager) FetchWithdrawalPublicKeys(ctx context.Context) ([][48]byte, error) {
 	publicKeys := make([][48]byte, 0)
-	for i := uint64(0); i < dr.seedCfg.NextAccount; i++ {
+	for _, i := range dr.AccountIndices() {
 		withdrawalKeyPath := fmt.Sprintf(WithdrawalKeyDerivationPathTemplate, i)
 		withdrawalKey, err := util.PrivateKeyFromSeedAndPath(dr.seed, withdrawalKeyPath)
 		if err != nil {
@@ -365,6 +525,60 @@ func (dr *Keymanager) FetchWithdrawalPublicKeys(ctx context.Context) ([][48]byte
 	return publicKeys, nil
 }
 
+// PublicKeyForAccount returns the associated validating public key for an account name.
+func (dr *Keymanager) PublicKeyForAccount(accountName string) ([48]byte, error) {
+	for _, i := range dr.AccountIndices() {
+		validatingKeyPath := fmt.Sprintf(ValidatingKeyDerivationPathTemplate, i)
+		validatingKey, err := util.PrivateKeyFromSeedAndPath(dr.seed, validatingKeyPath)
+		if err != nil {
+			return [48]byte{}, errors.Wrapf(err, "failed to derive validating key for account %d", i)
+		}
+		if petnames.DeterministicName(validatingKey.Marshal(), "-") == accountName {
+			return bytesutil.ToBytes48(validatingKey.PublicKey().Marshal()), nil
+		}
+	}
+	return [48]byte{}, fmt.Errorf("no account found with name %s", accountName)
+}
+
+// ExportKeystores re-encrypts the derived secret keys for the requested public keys
+// into standalone EIP-2335 keystores protected by the export password. Each keystore
+// records the EIP-2334 derivation path of its key.
+func (dr *Keymanager) ExportKeystores(
+	ctx context.Context,
+	publicKeys [][48]byte,
+	exportPassword string,
+) ([]*v2keymanager.Keystore, error) {
+	indices := make(map[[48]byte]uint64, dr.seedCfg.NextAccount)
+	for _, i := range dr.AccountIndices() {
+		validatingKeyPath := fmt.Sprintf(ValidatingKeyDerivationPathTemplate, i)
+		validatingKey, err := util.PrivateKeyFromSeedAndPath(dr.seed, validatingKeyPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to derive validating key for account %d", i)
+		}
+		indices[bytesutil.ToBytes48(validatingKey.PublicKey().Marshal())] = i
+	}
+	dr.lock.RLock()
+	defer dr.lock.RUnlock()
+	keystores := make([]*v2keymanager.Keystore, len(publicKeys))
+	for i, pubKey := range publicKeys {
+		index, ok := indices[pubKey]
+		if !ok {
+			return nil, fmt.Errorf("no account found for public key %#x", pubKey)
+		}
+		secretKey, ok := dr.keysCache[pubKey]
+		if !ok {
+			return nil, fmt.Errorf("no secret key found for public key %#x", pubKey)
+		}
+		validatingKeyPath := fmt.Sprintf(ValidatingKeyDerivationPathTemplate, index)
+		keystore, err := v2keymanager.NewKeystore(secretKey, validatingKeyPath, exportPassword)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not export keystore for public key %#x", pubKey)
+		}
+		keystores[i] = keystore
+	}
+	return keystores, nil
+}
+
 // DepositDataForAccount with a given index returns and ssz-encoded deposit data object.
 func (dr *Keymanager) DepositDataForAccount(accountIndex uint64) ([]byte, error) {
 	withdrawalKeyPath := fmt.Sprintf(WithdrawalKeyDerivationPathTemplate, accountIndex)
@@ -398,7 +612,7 @@ func (dr *Keymanager) DepositDataForAccount(accountIndex uint64) ([]byte, error)
 func (dr *Keymanager) initializeSecretKeysCache() error {
 	dr.lock.Lock()
 	defer dr.lock.Unlock()
-	for i := uint64(0); i < dr.seedCfg.NextAccount; i++ {
+	for _, i := range dr.AccountIndices() {
 		validatingKeyPath := fmt.Sprintf(ValidatingKeyDerivationPathTemplate, i)
 		derivedKey, err := util.PrivateKeyFromSeedAndPath(dr.seed, validatingKeyPath)
 		if err != nil {
@@ -94,6 +94,53 @@ func TestDerivedKeymanager_CreateAccount(t *testing.T) {
 	testutil.AssertLogsContain(t, hook, "Successfully created new validator account")
 }
 
+func TestDerivedKeymanager_CreateAccountAtIndex(t *testing.T) {
+	wallet := &mock.Wallet{
+		Files:            make(map[string]map[string][]byte),
+		AccountPasswords: make(map[string]string),
+	}
+	dr := &Keymanager{
+		wallet:         wallet,
+		seed:           make([]byte, 32),
+		seedCfg:        &SeedConfig{},
+		walletPassword: "secretPassw0rd$1999",
+	}
+	ctx := context.Background()
+	for _, index := range []uint64{101, 100} {
+		accountName, err := dr.CreateAccountAtIndex(ctx, index, false /*logAccountInfo*/)
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("%d", index), accountName)
+	}
+	_, err := dr.CreateAccountAtIndex(ctx, 100, false /*logAccountInfo*/)
+	assert.ErrorContains(t, "already has the account of index 100", err)
+	assert.DeepEqual(t, []uint64{100, 101}, dr.AccountIndices())
+
+	// New accounts follow the highest index of the wallet.
+	accountName, err := dr.CreateAccount(ctx, false /*logAccountInfo*/)
+	require.NoError(t, err)
+	assert.Equal(t, "102", accountName)
+	assert.DeepEqual(t, []uint64{100, 101, 102}, dr.AccountIndices())
+
+	validatingKey, err := util.PrivateKeyFromSeedAndPath(dr.seed, fmt.Sprintf(ValidatingKeyDerivationPathTemplate, 100))
+	require.NoError(t, err)
+	names, err := dr.ValidatingAccountNames(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(names))
+	pubKey, err := dr.PublicKeyForAccount(names[0])
+	require.NoError(t, err)
+	assert.DeepEqual(t, bytesutil.ToBytes48(validatingKey.PublicKey().Marshal()), pubKey)
+
+	encryptedSeedFile, err := wallet.ReadEncryptedSeedFromDisk(ctx)
+	require.NoError(t, err)
+	enc, err := ioutil.ReadAll(encryptedSeedFile)
+	require.NoError(t, err)
+	require.NoError(t, encryptedSeedFile.Close())
+	seedConfig := &SeedConfig{}
+	require.NoError(t, json.Unmarshal(enc, seedConfig))
+	assert.Equal(t, uint64(103), seedConfig.NextAccount)
+	assert.DeepEqual(t, []uint64{100, 101, 102}, seedConfig.Indices)
+}
+
 func TestDerivedKeymanager_FetchValidatingPublicKeys(t *testing.T) {
 	wallet := &mock.Wallet{
 		Files:            make(map[string]map[string][]byte),
@@ -140,6 +187,28 @@ func TestDerivedKeymanager_FetchValidatingPublicKeys(t *testing.T) {
 	}
 }
 
+func TestValidatingPublicKeysFromMnemonic(t *testing.T) {
+	mnemonicEntropy := make([]byte, 32)
+	_, err := rand.NewGenerator().Read(mnemonicEntropy)
+	require.NoError(t, err)
+	mnemonic, err := bip39.NewMnemonic(mnemonicEntropy)
+	require.NoError(t, err)
+	seed := bip39.NewSeed(mnemonic, "")
+
+	numAccounts := 5
+	publicKeys, err := ValidatingPublicKeysFromMnemonic(mnemonic, uint64(numAccounts))
+	require.NoError(t, err)
+	require.Equal(t, numAccounts, len(publicKeys))
+	for i := 0; i < numAccounts; i++ {
+		validatingKey, err := util.PrivateKeyFromSeedAndPath(seed, fmt.Sprintf(ValidatingKeyDerivationPathTemplate, i))
+		require.NoError(t, err)
+		assert.DeepEqual(t, bytesutil.ToBytes48(validatingKey.PublicKey().Marshal()), publicKeys[i])
+	}
+
+	_, err = ValidatingPublicKeysFromMnemonic("not a mnemonic", 1)
+	assert.ErrorContains(t, bip39.ErrInvalidMnemonic.Error(), err)
+}
+
 func TestDerivedKeymanager_Sign(t *testing.T) {
 	wallet := &mock.Wallet{
 		Files:            make(map[string]map[string][]byte),
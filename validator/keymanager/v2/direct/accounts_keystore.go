@@ -0,0 +1,386 @@
+package direct
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+)
+
+// AccountsKeystoreFileName is the file in the accounts directory of a compacted non-HD wallet
+// holding the validating keys of its accounts in a single keystore encrypted with the wallet
+// password, so they are decrypted at once rather than one keystore at a time.
+const AccountsKeystoreFileName = "all-accounts.keystore.json"
+
+// AccountsKeystore is the keystore of the validating keys of the compacted accounts of a wallet.
+// Its crypto is that of an EIP-2335 keystore, encrypting the accounts rather than a single key.
+// The public keys of the accounts are kept in the clear, so they are known without decrypting it.
+type AccountsKeystore struct {
+	Crypto     map[string]interface{} `json:"crypto"`
+	ID         string                 `json:"uuid"`
+	Version    uint                   `json:"version"`
+	Name       string                 `json:"name"`
+	PublicKeys map[string]string      `json:"public_keys"`
+}
+
+// compactedAccount is an account of the accounts keystore, holding what is needed to write its
+// keystore again when the wallet is expanded.
+type compactedAccount struct {
+	Name             string `json:"name"`
+	KeystoreFileName string `json:"keystore_file_name"`
+	KeystoreID       string `json:"keystore_id"`
+	SecretKey        string `json:"secret_key"`
+}
+
+// CompactAccounts moves the validating keys of the accounts of the wallet having a keystore of
+// their own into the accounts keystore, encrypted with the given wallet password, along with the
+// accounts already compacted. The names of the accounts moved are returned, so the wallet can
+// remove their keystores, which are left in place.
+func (dr *Keymanager) CompactAccounts(ctx context.Context, walletPassword string) ([]string, error) {
+	accounts, err := dr.readCompactedAccounts(ctx, walletPassword)
+	if err != nil {
+		return nil, err
+	}
+	accountNames, err := dr.ValidatingAccountNames()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch account names")
+	}
+	compacted := make([]string, 0, len(accountNames))
+	for _, name := range accountNames {
+		matches, err := filepath.Glob(filepath.Join(dr.wallet.AccountsDir(), name, dr.keystoreFileGlob()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not find keystore file for account %s", name)
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		keystoreFileName := filepath.Base(matches[0])
+		encoded, err := dr.wallet.ReadFileAtPath(ctx, name, keystoreFileName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read keystore file for account %s", name)
+		}
+		keystoreFile := &v2keymanager.Keystore{}
+		if err := json.Unmarshal(encoded, keystoreFile); err != nil {
+			return nil, errors.Wrapf(err, "could not decode keystore file for account %s", name)
+		}
+		password, err := dr.wallet.ReadPasswordFromDisk(ctx, name+PasswordFileSuffix)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read password for account %s", name)
+		}
+		rawSigningKey, err := decryptSecret(keystoreFile.Crypto, password)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not decrypt signing key for account %s", name)
+		}
+		accounts[name] = &compactedAccount{
+			Name:             name,
+			KeystoreFileName: keystoreFileName,
+			KeystoreID:       keystoreFile.ID,
+			SecretKey:        hex.EncodeToString(rawSigningKey),
+		}
+		v2keymanager.ZeroSecret(rawSigningKey)
+		compacted = append(compacted, name)
+	}
+	if len(compacted) == 0 {
+		// An accounts keystore encrypted with another cost than configured is encrypted again.
+		stale, err := dr.accountsKeystoreKDFChanged(ctx)
+		if err != nil || !stale {
+			return compacted, err
+		}
+	}
+	if err := dr.writeCompactedAccounts(ctx, accounts, walletPassword); err != nil {
+		return nil, err
+	}
+	return compacted, nil
+}
+
+// ExpandAccounts writes the keystore of every account of the accounts keystore again, encrypted
+// with the password of the account, keeping its file name and uuid. The names of the accounts
+// written are returned, so the wallet can remove the accounts keystore, which is left in place.
+func (dr *Keymanager) ExpandAccounts(ctx context.Context, walletPassword string) ([]string, error) {
+	accounts, err := dr.readCompactedAccounts(ctx, walletPassword)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(accounts))
+	for name := range accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		account := accounts[name]
+		secretKey, err := account.secretKey()
+		if err != nil {
+			return nil, err
+		}
+		password, err := dr.wallet.ReadPasswordFromDisk(ctx, name+PasswordFileSuffix)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read password for account %s", name)
+		}
+		encoded, err := dr.generateKeystoreFile(secretKey, password)
+		if err != nil {
+			return nil, err
+		}
+		if account.KeystoreID != "" {
+			keystoreFile := &v2keymanager.Keystore{}
+			if err := json.Unmarshal(encoded, keystoreFile); err != nil {
+				return nil, errors.Wrap(err, "could not decode generated keystore")
+			}
+			keystoreFile.ID = account.KeystoreID
+			encoded, err = json.MarshalIndent(keystoreFile, "", "\t")
+			if err != nil {
+				return nil, errors.Wrap(err, "could not encode generated keystore")
+			}
+		}
+		if err := dr.wallet.WriteFileAtPath(ctx, name, account.KeystoreFileName, encoded); err != nil {
+			return nil, errors.Wrapf(err, "could not write keystore file for account %s", name)
+		}
+	}
+	return names, nil
+}
+
+// ReencryptAccountsKeystore returns a copy of an accounts keystore with its accounts, which must
+// decrypt with the old password, encrypted with the new password instead, with the scrypt cost it
+// was encrypted with if it meets the minimum of accounts keystores. Its uuid and public keys are
+// unchanged.
+func ReencryptAccountsKeystore(
+	accountsKeystore *AccountsKeystore,
+	oldPassword string,
+	newPassword string,
+) (*AccountsKeystore, error) {
+	encryptor := keystorev4.New()
+	accounts, err := decryptSecret(accountsKeystore.Crypto, oldPassword)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decrypt accounts keystore with password")
+	}
+	defer v2keymanager.ZeroSecret(accounts)
+	kdf, err := kdfConfigOf(accountsKeystore.Crypto)
+	if err != nil || kdf.ValidateAccountsKeystore() != nil {
+		kdf = &KDFConfig{Function: ScryptKDF}
+	}
+	cryptoFields, err := kdf.encrypt(accounts, newPassword)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encrypt accounts into accounts keystore")
+	}
+	reencrypted := *accountsKeystore
+	reencrypted.Crypto = cryptoFields
+	reencrypted.Version = encryptor.Version()
+	reencrypted.Name = encryptor.Name()
+	return &reencrypted, nil
+}
+
+// Returns whether the wallet has an accounts keystore.
+func (dr *Keymanager) hasAccountsKeystore() bool {
+	_, err := os.Stat(filepath.Join(dr.wallet.AccountsDir(), AccountsKeystoreFileName))
+	return err == nil
+}
+
+// Reads the accounts keystore of the wallet, which is nil if the wallet has none.
+func (dr *Keymanager) readAccountsKeystore(ctx context.Context) (*AccountsKeystore, error) {
+	if !dr.hasAccountsKeystore() {
+		return nil, nil
+	}
+	encoded, err := dr.wallet.ReadFileAtPath(ctx, "", AccountsKeystoreFileName)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read accounts keystore")
+	}
+	accountsKeystore := &AccountsKeystore{}
+	if err := json.Unmarshal(encoded, accountsKeystore); err != nil {
+		return nil, errors.Wrap(err, "could not decode accounts keystore")
+	}
+	return accountsKeystore, nil
+}
+
+// Returns the public keys of the compacted accounts of the wallet by account name, without
+// decrypting the accounts keystore.
+func (dr *Keymanager) compactedPublicKeys(ctx context.Context) (map[string][48]byte, error) {
+	accountsKeystore, err := dr.readAccountsKeystore(ctx)
+	if err != nil || accountsKeystore == nil {
+		return nil, err
+	}
+	pubKeys := make(map[string][48]byte, len(accountsKeystore.PublicKeys))
+	for name, encodedPubKey := range accountsKeystore.PublicKeys {
+		pubKey, err := hex.DecodeString(encodedPubKey)
+		if err != nil || len(pubKey) != 48 {
+			return nil, fmt.Errorf("accounts keystore has no valid public key for account %s", name)
+		}
+		pubKeys[name] = bytesutil.ToBytes48(pubKey)
+	}
+	return pubKeys, nil
+}
+
+// Decrypts the accounts keystore of the wallet with the wallet password, returning its accounts
+// by name, none if the wallet has no accounts keystore.
+func (dr *Keymanager) readCompactedAccounts(ctx context.Context, walletPassword string) (map[string]*compactedAccount, error) {
+	accounts := make(map[string]*compactedAccount)
+	accountsKeystore, err := dr.readAccountsKeystore(ctx)
+	if err != nil || accountsKeystore == nil {
+		return accounts, err
+	}
+	decrypted, err := decryptSecret(accountsKeystore.Crypto, walletPassword)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decrypt accounts keystore with the wallet password")
+	}
+	defer v2keymanager.ZeroSecret(decrypted)
+	if kdf, err := kdfConfigOf(accountsKeystore.Crypto); err != nil || kdf.ValidateAccountsKeystore() != nil {
+		log.Warn("Accounts keystore is encrypted with less than the minimum scrypt cost of accounts keystores, " +
+			"run wallet-v2 compact to encrypt it again")
+	}
+	compacted := make([]*compactedAccount, 0)
+	if err := json.Unmarshal(decrypted, &compacted); err != nil {
+		return nil, errors.Wrap(err, "could not decode accounts of accounts keystore")
+	}
+	for _, account := range compacted {
+		secretKey, err := account.secretKey()
+		if err != nil {
+			return nil, err
+		}
+		if fmt.Sprintf("%x", secretKey.PublicKey().Marshal()) != accountsKeystore.PublicKeys[account.Name] {
+			return nil, fmt.Errorf("accounts keystore holds another public key for account %s", account.Name)
+		}
+		accounts[account.Name] = account
+	}
+	return accounts, nil
+}
+
+// Writes the accounts keystore of the wallet holding the given accounts, encrypted with the wallet
+// password, once it decrypts back to them.
+func (dr *Keymanager) writeCompactedAccounts(
+	ctx context.Context,
+	accounts map[string]*compactedAccount,
+	walletPassword string,
+) error {
+	names := make([]string, 0, len(accounts))
+	for name := range accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	compacted := make([]*compactedAccount, 0, len(accounts))
+	pubKeys := make(map[string]string, len(accounts))
+	for _, name := range names {
+		secretKey, err := accounts[name].secretKey()
+		if err != nil {
+			return err
+		}
+		compacted = append(compacted, accounts[name])
+		pubKeys[name] = fmt.Sprintf("%x", secretKey.PublicKey().Marshal())
+	}
+	encodedAccounts, err := json.Marshal(compacted)
+	if err != nil {
+		return errors.Wrap(err, "could not encode accounts")
+	}
+	defer v2keymanager.ZeroSecret(encodedAccounts)
+	encryptor := keystorev4.New()
+	cryptoFields, err := dr.accountsKeystoreKDF().encrypt(encodedAccounts, walletPassword)
+	if err != nil {
+		return errors.Wrap(err, "could not encrypt accounts into accounts keystore")
+	}
+	// The keystores of the accounts are removed once they are compacted, so the accounts keystore
+	// must hold their keys before it is written.
+	decrypted, err := decryptSecret(cryptoFields, walletPassword)
+	if err != nil {
+		return errors.Wrap(err, "could not decrypt generated accounts keystore")
+	}
+	defer v2keymanager.ZeroSecret(decrypted)
+	if !bytes.Equal(decrypted, encodedAccounts) {
+		return errors.New("generated accounts keystore does not match the accounts")
+	}
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return err
+	}
+	encoded, err := json.MarshalIndent(&AccountsKeystore{
+		Crypto:     cryptoFields,
+		ID:         id.String(),
+		Version:    encryptor.Version(),
+		Name:       encryptor.Name(),
+		PublicKeys: pubKeys,
+	}, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "could not encode accounts keystore")
+	}
+	if err := dr.wallet.WriteFileAtPath(ctx, "", AccountsKeystoreFileName, encoded); err != nil {
+		return errors.Wrap(err, "could not write accounts keystore")
+	}
+	return nil
+}
+
+// Returns the KDF configuration the accounts keystore is encrypted with: the accounts keystore KDF
+// of the keymanager config, else its keystore KDF if it meets the minimum of accounts keystores,
+// else scrypt with the EIP-2335 default cost.
+func (dr *Keymanager) accountsKeystoreKDF() *KDFConfig {
+	if dr.cfg != nil && dr.cfg.AccountsKeystoreKDF != nil {
+		return dr.cfg.AccountsKeystoreKDF
+	}
+	if dr.cfg != nil && dr.cfg.KDF != nil && dr.cfg.KDF.ValidateAccountsKeystore() == nil {
+		return dr.cfg.KDF
+	}
+	return &KDFConfig{Function: ScryptKDF}
+}
+
+// Returns whether the accounts keystore of the wallet is encrypted with another scrypt cost than
+// the keymanager encrypts it with, false if the wallet has no accounts keystore.
+func (dr *Keymanager) accountsKeystoreKDFChanged(ctx context.Context) (bool, error) {
+	accountsKeystore, err := dr.readAccountsKeystore(ctx)
+	if err != nil || accountsKeystore == nil {
+		return false, err
+	}
+	current, err := kdfConfigOf(accountsKeystore.Crypto)
+	if err != nil {
+		return true, nil
+	}
+	want := dr.accountsKeystoreKDF()
+	return current.String() != want.String(), nil
+}
+
+// Loads the validating keys of the compacted accounts of the wallet among the given account names
+// into the keys cache, decrypting the accounts keystore once, and returns the names of the
+// accounts loaded.
+func (dr *Keymanager) loadCompactedAccounts(ctx context.Context, accountNames []string) (map[string]bool, error) {
+	loaded := make(map[string]bool)
+	if !dr.hasAccountsKeystore() {
+		return loaded, nil
+	}
+	accounts, err := dr.readCompactedAccounts(ctx, dr.wallet.Password())
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range accountNames {
+		account, ok := accounts[name]
+		if !ok {
+			continue
+		}
+		secretKey, err := account.secretKey()
+		if err != nil {
+			return nil, err
+		}
+		if err := dr.cacheSecretKey(secretKey); err != nil {
+			return nil, errors.Wrapf(err, "could not cache signing key for account %s", name)
+		}
+		loaded[name] = true
+	}
+	return loaded, nil
+}
+
+func (a *compactedAccount) secretKey() (bls.SecretKey, error) {
+	rawSecretKey, err := hex.DecodeString(a.SecretKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not decode signing key for account %s", a.Name)
+	}
+	defer v2keymanager.ZeroSecret(rawSecretKey)
+	secretKey, err := bls.SecretKeyFromBytes(rawSecretKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not determine signing key for account %s", a.Name)
+	}
+	return secretKey, nil
+}
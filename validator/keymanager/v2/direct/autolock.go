@@ -0,0 +1,102 @@
+package direct
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/validator/accounts/v2/iface"
+)
+
+// ErrKeymanagerLocked is returned for signing requests made while the keymanager is locked after
+// being idle.
+var ErrKeymanagerLocked = errors.New("keymanager is locked after being idle, unlock it with the wallet password")
+
+// AutoLock locks the keymanager once it has not signed anything for idleTimeout, until ctx is
+// done, clearing its keys cache and dropping the wallet password so neither is kept in memory while
+// it is idle. It then signs nothing until it is unlocked with UnlockKeys. onLock is called each time it locks, such as to
+// prompt for the wallet password, and idle time is only measured again once it returns.
+func (dr *Keymanager) AutoLock(ctx context.Context, idleTimeout time.Duration, onLock func(ctx context.Context)) error {
+	if idleTimeout <= 0 {
+		return errors.New("idle timeout must be positive")
+	}
+	if dr.wallet.Password() == "" {
+		return errors.New("only keymanagers of wallets with a wallet password can be locked")
+	}
+	if _, ok := dr.wallet.(iface.LockableWallet); !ok {
+		return errors.New("wallet cannot drop its wallet password, so its keymanager cannot be locked")
+	}
+	dr.touch()
+	timer := time.NewTimer(idleTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+			idle := time.Since(time.Unix(0, atomic.LoadInt64(&dr.lastUsed)))
+			if idle < idleTimeout {
+				timer.Reset(idleTimeout - idle)
+				continue
+			}
+			dr.LockKeys()
+			log.WithField("idleTimeout", idleTimeout).Warn("Locked keymanager after being idle, signing is paused")
+			if onLock != nil {
+				onLock(ctx)
+			}
+			dr.touch()
+			timer.Reset(idleTimeout)
+		}
+	}
+}
+
+// LockKeys clears the keys cache of the keymanager and drops the wallet password of a wallet able
+// to, and the keymanager signs nothing until it is unlocked.
+func (dr *Keymanager) LockKeys() {
+	dr.lock.Lock()
+	defer dr.lock.Unlock()
+	for pubKey := range dr.keysCache {
+		dr.evictSecretKey(pubKey)
+	}
+	if wallet, ok := dr.wallet.(iface.LockableWallet); ok {
+		wallet.LockPassword()
+	}
+	dr.locked = true
+}
+
+// UnlockKeys decrypts the keys of the accounts into the keys cache again, once the wallet verifies
+// the wallet password given by decrypting its files with it, so the keymanager resumes signing.
+func (dr *Keymanager) UnlockKeys(ctx context.Context, walletPassword string) error {
+	if !dr.Locked() {
+		return nil
+	}
+	wallet, ok := dr.wallet.(iface.LockableWallet)
+	if !ok {
+		return errors.New("wallet cannot verify its wallet password")
+	}
+	if err := wallet.UnlockPassword(ctx, walletPassword); err != nil {
+		return errors.Wrap(err, "wrong wallet password")
+	}
+	if err := dr.initializeSecretKeysCache(ctx); err != nil {
+		return errors.Wrap(err, "could not initialize keys cache")
+	}
+	dr.lock.Lock()
+	dr.locked = false
+	dr.lock.Unlock()
+	dr.touch()
+	log.Info("Unlocked keymanager, signing resumes")
+	return nil
+}
+
+// Locked returns whether the keymanager is locked after being idle.
+func (dr *Keymanager) Locked() bool {
+	dr.lock.RLock()
+	defer dr.lock.RUnlock()
+	return dr.locked
+}
+
+// Records the keymanager was just used, restarting its idle time.
+func (dr *Keymanager) touch() {
+	atomic.StoreInt64(&dr.lastUsed, time.Now().UnixNano())
+}
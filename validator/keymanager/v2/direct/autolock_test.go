@@ -0,0 +1,78 @@
+package direct
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	validatorpb "github.com/prysmaticlabs/prysm/proto/validator/accounts/v2"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	mock "github.com/prysmaticlabs/prysm/validator/accounts/v2/testing"
+)
+
+func TestDirectKeymanager_AutoLock(t *testing.T) {
+	wallet := &mock.Wallet{
+		Files:            make(map[string]map[string][]byte),
+		AccountPasswords: make(map[string]string),
+		WalletPassword:   "walletpassword",
+	}
+	dr := &Keymanager{
+		wallet:    wallet,
+		keysCache: make(map[[48]byte]bls.SecretKey),
+	}
+	accountNames, _ := generateAccounts(t, 2, dr)
+	wallet.Directories = accountNames
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, dr.initializeSecretKeysCache(ctx))
+	publicKeys, err := dr.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	signRequest := &validatorpb.SignRequest{
+		PublicKey:   publicKeys[0][:],
+		SigningRoot: []byte("hello world"),
+	}
+
+	locked := make(chan struct{})
+	unlock := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- dr.AutoLock(ctx, 50*time.Millisecond, func(ctx context.Context) {
+			locked <- struct{}{}
+			<-unlock
+		})
+	}()
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Keymanager did not lock after being idle")
+	}
+	assert.Equal(t, true, dr.Locked())
+	_, err = dr.Sign(ctx, signRequest)
+	assert.ErrorContains(t, ErrKeymanagerLocked.Error(), err)
+	assert.Equal(t, 0, len(dr.keysCache))
+	assert.Equal(t, "", wallet.Password())
+
+	assert.ErrorContains(t, "wrong wallet password", dr.UnlockKeys(ctx, "wrong"))
+	assert.Equal(t, true, dr.Locked())
+	assert.Equal(t, "", wallet.Password())
+	require.NoError(t, dr.UnlockKeys(ctx, "walletpassword"))
+	assert.Equal(t, false, dr.Locked())
+	assert.Equal(t, "walletpassword", wallet.Password())
+	assert.Equal(t, 2, len(dr.keysCache))
+	_, err = dr.Sign(ctx, signRequest)
+	require.NoError(t, err)
+	close(unlock)
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestDirectKeymanager_AutoLock_NoWalletPassword(t *testing.T) {
+	dr := &Keymanager{
+		wallet:    &mock.Wallet{},
+		keysCache: make(map[[48]byte]bls.SecretKey),
+	}
+	assert.ErrorContains(t, "wallet password", dr.AutoLock(context.Background(), time.Second, nil))
+	assert.ErrorContains(t, "must be positive", dr.AutoLock(context.Background(), 0, nil))
+}
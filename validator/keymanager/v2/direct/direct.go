@@ -1,6 +1,7 @@
 package direct
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"encoding/json"
@@ -9,6 +10,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
 
@@ -16,6 +18,7 @@ import (
 	"github.com/k0kubun/go-ansi"
 	"github.com/logrusorgru/aurora"
 	"github.com/pkg/errors"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
 	"github.com/prysmaticlabs/go-ssz"
 	validatorpb "github.com/prysmaticlabs/prysm/proto/validator/accounts/v2"
 	"github.com/prysmaticlabs/prysm/shared/bls"
@@ -35,32 +38,97 @@ import (
 var log = logrus.WithField("prefix", "direct-keymanager-v2")
 
 const (
-	// TimestampFileName stores a timestamp for account creation as a
-	// file for a direct keymanager account.
+	// TimestampFileName is the legacy file storing the creation timestamp of
+	// a direct keymanager account, only read when migrating an account to its
+	// metadata document.
 	TimestampFileName = "created_at.txt"
-	// KeystoreFileName exposes the expected filename for the keystore file for an account.
+	// KeystoreFileName exposes the expected filename for the keystore file for an account,
+	// unless the keymanager is configured with another keystore file name format.
 	KeystoreFileName = "keystore-*.json"
-	// KeystoreFileNameFormat exposes the filename the keystore should be formatted in.
+	// KeystoreFileNameFormat exposes the filename the keystore should be formatted in, with the
+	// creation time of the account in seconds, unless configured otherwise.
 	KeystoreFileNameFormat = "keystore-%d.json"
 	// PasswordFileSuffix for passwords persisted as text to disk.
 	PasswordFileSuffix = ".pass"
 	// DepositDataFileName for the ssz-encoded deposit.
 	DepositDataFileName = "deposit_data.ssz"
-	eipVersion          = "EIP-2335"
+	// DepositDataJSONFileName for the canonical JSON-encoded deposit.
+	DepositDataJSONFileName = "deposit_data.json"
+	// WithdrawalKeystoreFileName for the EIP-2335 keystore of the withdrawal key of an account,
+	// only written when withdrawal keys are stored in the wallet.
+	WithdrawalKeystoreFileName = "withdrawal-keystore.json"
+	eipVersion                 = "EIP-2335"
+)
+
+// StoredWithdrawalKeyWarning is logged for accounts storing their withdrawal key in the wallet.
+const StoredWithdrawalKeyWarning = "The withdrawal key of the account is stored in the wallet, encrypted with the " +
+	"withdrawal password. Anyone with both can withdraw the stake of the validator, and losing either loses it: " +
+	"back up the keystore and its password, and move them off this machine"
+
+// accountOutput selects what is displayed when creating an account.
+type accountOutput int
+
+const (
+	// Display the withdrawal key, deposit data and name of the new account.
+	fullAccountOutput accountOutput = iota
+	// Display everything but the withdrawal key, which is held elsewhere.
+	noWithdrawalKeyOutput
+	// Display nothing, for accounts created in batches and summarized by the caller.
+	noAccountOutput
+)
+
+// Creating an account is bound by encrypting its keystore, so batches are created by one
+// worker per core.
+var createAccountsWorkers = runtime.NumCPU()
+
+// decryptSecret decrypts the secret key material of a keystore with its password. The caller must
+// wipe the returned bytes with v2keymanager.ZeroSecret once done with them, on every path.
+var decryptSecret = func(cryptoFields map[string]interface{}, password string) ([]byte, error) {
+	return keystorev4.New().Decrypt(cryptoFields, password)
+}
+
+const (
+	// SSZDepositDataFormat writes deposit data for new accounts as a .ssz file.
+	SSZDepositDataFormat = "ssz"
+	// JSONDepositDataFormat writes deposit data for new accounts as a canonical .json file.
+	JSONDepositDataFormat = "json"
+	// AllDepositDataFormats writes deposit data for new accounts both as .ssz and .json files.
+	AllDepositDataFormats = "all"
 )
 
 // Config for a direct keymanager.
 type Config struct {
-	EIPVersion                string `json:"direct_eip_version"`
-	AccountPasswordsDirectory string `json:"direct_accounts_passwords_directory"`
+	EIPVersion                string     `json:"direct_eip_version"`
+	AccountPasswordsDirectory string     `json:"direct_accounts_passwords_directory"`
+	KDF                       *KDFConfig `json:"direct_kdf,omitempty"`
+	// AccountsKeystoreKDF is the scrypt cost of the accounts keystore of a compacted wallet, the
+	// KDF of the keystores if it is strong enough for it or else the EIP-2335 default if nil.
+	AccountsKeystoreKDF *KDFConfig `json:"direct_accounts_keystore_kdf,omitempty"`
+	DepositDataFormat   string     `json:"direct_deposit_data_format,omitempty"`
+	// KeystoreFileNameFormat is the file name format of the keystores of accounts, with a single
+	// %d for the creation time of the account, keystore-%d.json if empty.
+	KeystoreFileNameFormat string `json:"direct_keystore_file_name_format,omitempty"`
+	// TOTPEnrolled is true once the wallet has a TOTP secret, so its sensitive account commands
+	// require a code even if the TOTP file of the wallet is removed.
+	TOTPEnrolled bool `json:"direct_totp_enrolled,omitempty"`
 }
 
 // Keymanager implementation for direct keystores utilizing EIP-2335.
 type Keymanager struct {
-	wallet    iface.Wallet
-	cfg       *Config
-	keysCache map[[48]byte]bls.SecretKey
-	lock      sync.RWMutex
+	// lastUsed is when the keymanager last signed, in nanoseconds since the epoch, accessed
+	// atomically and first for its alignment.
+	lastUsed              int64
+	wallet                iface.Wallet
+	cfg                   *Config
+	keysCache             map[[48]byte]bls.SecretKey
+	lock                  sync.RWMutex
+	withdrawalKeyPassword string
+	// locked is true while the keymanager is locked after being idle, with an empty keys cache.
+	locked bool
+	// lockedMemory is true once the keys cache holds its secret keys in locked memory.
+	lockedMemory bool
+	// lockedKeys are the pages of locked memory the secret keys of the keys cache are held in.
+	lockedKeys *lockedKeyArena
 }
 
 // DefaultConfig for a direct keymanager implementation.
@@ -73,6 +141,22 @@ func DefaultConfig() *Config {
 
 // NewKeymanager instantiates a new direct keymanager from configuration options.
 func NewKeymanager(ctx context.Context, wallet iface.Wallet, cfg *Config) (*Keymanager, error) {
+	if cfg.KDF != nil {
+		if err := cfg.KDF.Validate(); err != nil {
+			return nil, errors.Wrap(err, "invalid kdf configuration")
+		}
+	}
+	if cfg.AccountsKeystoreKDF != nil {
+		if err := cfg.AccountsKeystoreKDF.ValidateAccountsKeystore(); err != nil {
+			return nil, errors.Wrap(err, "invalid accounts keystore kdf configuration")
+		}
+	}
+	if err := ValidateDepositDataFormat(cfg.DepositDataFormat); err != nil {
+		return nil, err
+	}
+	if err := ValidateKeystoreFileNameFormat(cfg.KeystoreFileNameFormat); err != nil {
+		return nil, err
+	}
 	k := &Keymanager{
 		wallet:    wallet,
 		cfg:       cfg,
@@ -89,8 +173,9 @@ func NewKeymanager(ctx context.Context, wallet iface.Wallet, cfg *Config) (*Keym
 }
 
 // UnmarshalConfigFile attempts to JSON unmarshal a direct keymanager
-// configuration file into the *Config{} struct.
-func UnmarshalConfigFile(r io.ReadCloser) (*Config, error) {
+// configuration file into the *Config{} struct. If the file was encrypted
+// with the wallet password, it is transparently decrypted first.
+func UnmarshalConfigFile(r io.ReadCloser, password string) (*Config, error) {
 	enc, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, err
@@ -100,6 +185,10 @@ func UnmarshalConfigFile(r io.ReadCloser) (*Config, error) {
 			log.Errorf("Could not close keymanager config file: %v", err)
 		}
 	}()
+	enc, err = v2keymanager.DecryptConfig(enc, password)
+	if err != nil {
+		return nil, err
+	}
 	cfg := &Config{}
 	if err := json.Unmarshal(enc, cfg); err != nil {
 		return nil, err
@@ -107,6 +196,72 @@ func UnmarshalConfigFile(r io.ReadCloser) (*Config, error) {
 	return cfg, nil
 }
 
+// ValidateDepositDataFormat checks a deposit data output format is one of
+// ssz, json, or all. An empty format is treated as ssz.
+func ValidateDepositDataFormat(format string) error {
+	switch format {
+	case "", SSZDepositDataFormat, JSONDepositDataFormat, AllDepositDataFormats:
+		return nil
+	default:
+		return fmt.Errorf(
+			"%s is not a valid deposit data format, expected %s, %s, or %s",
+			format, SSZDepositDataFormat, JSONDepositDataFormat, AllDepositDataFormats,
+		)
+	}
+}
+
+// ValidateKeystoreFileNameFormat checks a keystore file name format has a single %d for the
+// creation time of the account, and that the keystore files it names can be told apart from the
+// other files of an account. An empty format is treated as keystore-%d.json.
+func ValidateKeystoreFileNameFormat(format string) error {
+	if format == "" {
+		return nil
+	}
+	if strings.Count(format, "%") != 1 || strings.Count(format, "%d") != 1 {
+		return fmt.Errorf("keystore file name format %s must have a single %%d for the creation time of the account", format)
+	}
+	if strings.ContainsAny(format, "/\\*?[") {
+		return fmt.Errorf("keystore file name format %s must not contain path separators or glob characters", format)
+	}
+	glob := KeystoreFileGlob(format)
+	for _, fileName := range []string{
+		DepositDataFileName,
+		DepositDataJSONFileName,
+		WithdrawalKeystoreFileName,
+		TimestampFileName,
+	} {
+		if ok, err := filepath.Match(glob, fileName); err != nil || ok {
+			return fmt.Errorf("keystore file name format %s also names the %s file of accounts", format, fileName)
+		}
+	}
+	return nil
+}
+
+// KeystoreFileGlob returns the glob pattern matching the keystore files named after a keystore
+// file name format, the default KeystoreFileName if the format is empty.
+func KeystoreFileGlob(format string) string {
+	if format == "" {
+		return KeystoreFileName
+	}
+	return strings.Replace(format, "%d", "*", 1)
+}
+
+// Returns the file name format of the keystores of accounts.
+func (dr *Keymanager) keystoreFileNameFormat() string {
+	if dr.cfg == nil || dr.cfg.KeystoreFileNameFormat == "" {
+		return KeystoreFileNameFormat
+	}
+	return dr.cfg.KeystoreFileNameFormat
+}
+
+// Returns the glob pattern matching the keystore files of accounts.
+func (dr *Keymanager) keystoreFileGlob() string {
+	if dr.cfg == nil {
+		return KeystoreFileName
+	}
+	return KeystoreFileGlob(dr.cfg.KeystoreFileNameFormat)
+}
+
 // MarshalConfigFile returns a marshaled configuration file for a keymanager.
 func MarshalConfigFile(ctx context.Context, cfg *Config) ([]byte, error) {
 	return json.MarshalIndent(cfg, "", "\t")
@@ -133,6 +288,34 @@ func (c *Config) String() string {
 		log.Error(err)
 		return ""
 	}
+	if c.DepositDataFormat != "" {
+		strFmt := fmt.Sprintf("%s: %s\n", au.BrightMagenta("Deposit Data Format"), c.DepositDataFormat)
+		if _, err := b.WriteString(strFmt); err != nil {
+			log.Error(err)
+			return ""
+		}
+	}
+	if c.KeystoreFileNameFormat != "" {
+		strFmt := fmt.Sprintf("%s: %s\n", au.BrightMagenta("Keystore File Name Format"), c.KeystoreFileNameFormat)
+		if _, err := b.WriteString(strFmt); err != nil {
+			log.Error(err)
+			return ""
+		}
+	}
+	if c.KDF != nil {
+		strKDF := fmt.Sprintf("%s: %s\n", au.BrightMagenta("Keystore KDF"), c.KDF)
+		if _, err := b.WriteString(strKDF); err != nil {
+			log.Error(err)
+			return ""
+		}
+	}
+	if c.AccountsKeystoreKDF != nil {
+		strKDF := fmt.Sprintf("%s: %s\n", au.BrightMagenta("Accounts Keystore KDF"), c.AccountsKeystoreKDF)
+		if _, err := b.WriteString(strKDF); err != nil {
+			log.Error(err)
+			return ""
+		}
+	}
 	return b.String()
 }
 
@@ -147,12 +330,133 @@ func (dr *Keymanager) ValidatingAccountNames() ([]string, error) {
 // generates withdrawal credentials. At the end, it logs
 // the raw deposit data hex string for users to copy.
 func (dr *Keymanager) CreateAccount(ctx context.Context, password string) (string, error) {
+	withdrawalKey := bls.RandKey()
+	return dr.createAccount(ctx, password, withdrawalKey, depositutil.WithdrawalCredentialsHash(withdrawalKey), fullAccountOutput)
+}
+
+// CreateAccountWithWithdrawalKey creates a new account like CreateAccount, with deposit data
+// withdrawing to the given withdrawal key instead of a randomly generated one. The withdrawal key
+// is not displayed, as it is held elsewhere.
+func (dr *Keymanager) CreateAccountWithWithdrawalKey(
+	ctx context.Context,
+	password string,
+	withdrawalKey bls.SecretKey,
+) (string, error) {
+	return dr.createAccount(ctx, password, withdrawalKey, depositutil.WithdrawalCredentialsHash(withdrawalKey), noWithdrawalKeyOutput)
+}
+
+// CreateAccountWithWithdrawalCredentials creates a new account like CreateAccount, with deposit
+// data bound to the given withdrawal credentials, such as the ones of a custodian's cold key. No
+// withdrawal key is generated, so none can be displayed or stored in the wallet.
+func (dr *Keymanager) CreateAccountWithWithdrawalCredentials(
+	ctx context.Context,
+	password string,
+	withdrawalCredentials []byte,
+) (string, error) {
+	if dr.withdrawalKeyPassword != "" {
+		return "", errors.New("cannot store the withdrawal key of accounts created with withdrawal credentials")
+	}
+	return dr.createAccount(ctx, password, nil /* withdrawal key */, withdrawalCredentials, noWithdrawalKeyOutput)
+}
+
+// CreateAccounts creates an account for each of the given withdrawal keys in parallel, all
+// protected by the same password. Nothing is displayed for the accounts, whose names are
+// returned in the order of their withdrawal keys for the caller to summarize.
+func (dr *Keymanager) CreateAccounts(
+	ctx context.Context,
+	password string,
+	withdrawalKeys []bls.SecretKey,
+) ([]string, error) {
+	return dr.createAccounts(ctx, len(withdrawalKeys), func(ctx context.Context, i int) (string, error) {
+		withdrawalCredentials := depositutil.WithdrawalCredentialsHash(withdrawalKeys[i])
+		return dr.createAccount(ctx, password, withdrawalKeys[i], withdrawalCredentials, noAccountOutput)
+	})
+}
+
+// CreateAccountsWithWithdrawalCredentials creates count accounts like CreateAccounts, all with
+// deposit data bound to the given withdrawal credentials.
+func (dr *Keymanager) CreateAccountsWithWithdrawalCredentials(
+	ctx context.Context,
+	password string,
+	withdrawalCredentials []byte,
+	count int,
+) ([]string, error) {
+	if dr.withdrawalKeyPassword != "" {
+		return nil, errors.New("cannot store the withdrawal key of accounts created with withdrawal credentials")
+	}
+	return dr.createAccounts(ctx, count, func(ctx context.Context, _ int) (string, error) {
+		return dr.createAccount(ctx, password, nil /* withdrawal key */, withdrawalCredentials, noAccountOutput)
+	})
+}
+
+// Creates count accounts with one worker per core, returning their names in order.
+func (dr *Keymanager) createAccounts(
+	ctx context.Context,
+	count int,
+	create func(ctx context.Context, i int) (string, error),
+) ([]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	accountNames := make([]string, count)
+	indices := make(chan int, count)
+	for i := 0; i < count; i++ {
+		indices <- i
+	}
+	close(indices)
+	for w := 0; w < createAccountsWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				if ctx.Err() != nil {
+					return
+				}
+				accountName, err := create(ctx, i)
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					return
+				}
+				accountNames[i] = accountName
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return accountNames, nil
+}
+
+// Creates an account with deposit data bound to the given withdrawal credentials. The withdrawal
+// key the credentials were formed from is nil if it is held elsewhere.
+func (dr *Keymanager) createAccount(
+	ctx context.Context,
+	password string,
+	withdrawalKey bls.SecretKey,
+	withdrawalCredentials []byte,
+	output accountOutput,
+) (string, error) {
 	// Create a petname for an account from its public key and write its password to disk.
 	validatingKey := bls.RandKey()
 	accountName, err := dr.generateAccountName(validatingKey.PublicKey().Marshal())
 	if err != nil {
 		return "", errors.Wrap(err, "could not generate unique account name")
 	}
+	password, err = dr.accountPassword(accountName, password)
+	if err != nil {
+		return "", err
+	}
 	if err := dr.wallet.WritePasswordToDisk(ctx, accountName+".pass", password); err != nil {
 		return "", errors.Wrap(err, "could not write password to disk")
 	}
@@ -163,52 +467,81 @@ func (dr *Keymanager) CreateAccount(ctx context.Context, password string) (strin
 		return "", err
 	}
 
-	// Generate a withdrawal key and confirm user
-	// acknowledgement of a 256-bit entropy mnemonic phrase.
-	withdrawalKey := bls.RandKey()
-	log.Info(
-		"Write down the private key, as it is your unique " +
-			"withdrawal private key for eth2",
-	)
-	fmt.Printf(`
+	// The files of the account, starting with the keystore with its timestamp appended,
+	// are written together once all of them are generated, in a single transaction
+	// if the wallet is stored in a database.
+	createdAt := roughtime.Now().Unix()
+	files := map[string][]byte{
+		fmt.Sprintf(dr.keystoreFileNameFormat(), createdAt): encoded,
+	}
+
+	// Either store the withdrawal key encrypted in the account or
+	// display it once for the user to write down. There is neither
+	// for withdrawal credentials given without their key.
+	if withdrawalKey != nil && dr.withdrawalKeyPassword != "" {
+		encodedWithdrawalKey, err := dr.generateKeystoreFile(withdrawalKey, dr.withdrawalKeyPassword)
+		if err != nil {
+			return "", errors.Wrap(err, "could not encrypt withdrawal key")
+		}
+		files[WithdrawalKeystoreFileName] = encodedWithdrawalKey
+		if output != noAccountOutput {
+			log.WithField(
+				"path", filepath.Join(dr.wallet.AccountsDir(), accountName, WithdrawalKeystoreFileName),
+			).Warn(StoredWithdrawalKeyWarning)
+		}
+	} else if withdrawalKey != nil && output == fullAccountOutput {
+		log.Info(
+			"Write down the private key, as it is your unique " +
+				"withdrawal private key for eth2",
+		)
+		fmt.Printf(`
 ==========================Withdrawal Key===========================
 
 %#x
 
 ===================================================================
 	`, withdrawalKey.Marshal())
-	fmt.Println(" ")
+		fmt.Println(" ")
+	}
 
 	// Upon confirmation of the withdrawal key, proceed to display
-	// and write associated deposit data to disk.
-	_, depositData, err := depositutil.GenerateDepositTransaction(validatingKey, withdrawalKey)
+	// and write associated deposit data.
+	_, depositData, err := depositutil.GenerateDepositTransactionWithCredentials(validatingKey, withdrawalCredentials)
 	if err != nil {
 		return "", errors.Wrap(err, "could not generate deposit transaction data")
 	}
 
-	// We write the ssz-encoded deposit data to disk as a .ssz file.
+	// We write the ssz-encoded deposit data as a .ssz file
+	// and/or its canonical JSON encoding as a .json file.
 	encodedDepositData, err := ssz.Marshal(depositData)
 	if err != nil {
 		return "", errors.Wrap(err, "could not marshal deposit data")
 	}
-	if err := dr.wallet.WriteFileAtPath(ctx, accountName, DepositDataFileName, encodedDepositData); err != nil {
-		return "", errors.Wrapf(err, "could not write for account %s: %s", accountName, encodedDepositData)
+	depositDataFiles, err := dr.depositDataFiles(depositData, encodedDepositData)
+	if err != nil {
+		return "", err
+	}
+	for fileName, data := range depositDataFiles {
+		files[fileName] = data
 	}
 
 	// Log the deposit transaction data to the user.
-	fmt.Printf(`
+	if output != noAccountOutput {
+		fmt.Printf(`
 ========================SSZ Deposit Data===============================
 
 %#x
 
 ===================================================================`, encodedDepositData)
+	}
 
-	// Write the encoded keystore to disk with the timestamp appended
-	createdAt := roughtime.Now().Unix()
-	if err := dr.wallet.WriteFileAtPath(ctx, accountName, fmt.Sprintf(KeystoreFileNameFormat, createdAt), encoded); err != nil {
-		return "", errors.Wrapf(err, "could not write keystore file for account %s", accountName)
+	if err := dr.wallet.WriteFilesAtPath(ctx, accountName, files); err != nil {
+		return "", errors.Wrapf(err, "could not write files of account %s", accountName)
 	}
 
+	if output == noAccountOutput {
+		return accountName, nil
+	}
 	log.WithFields(logrus.Fields{
 		"name": accountName,
 		"path": dr.wallet.AccountsDir(),
@@ -216,6 +549,258 @@ func (dr *Keymanager) CreateAccount(ctx context.Context, password string) (strin
 	return accountName, nil
 }
 
+// PlannedAccount is an account as it would be created, for dry runs.
+type PlannedAccount struct {
+	Name        string
+	PublicKey   [48]byte
+	DepositData *ethpb.Deposit_Data
+	// Files are the paths of the files which would be written in the account directory.
+	Files []string
+}
+
+// PlanAccount generates a validating key and computes the keystore and deposit data of an account
+// withdrawing to the given withdrawal key, as creating the account would, without writing anything
+// to disk.
+func (dr *Keymanager) PlanAccount(password string, withdrawalKey bls.SecretKey) (*PlannedAccount, error) {
+	return dr.planAccount(password, withdrawalKey, depositutil.WithdrawalCredentialsHash(withdrawalKey))
+}
+
+// PlanAccountWithWithdrawalCredentials plans an account like PlanAccount, with deposit data bound
+// to the given withdrawal credentials.
+func (dr *Keymanager) PlanAccountWithWithdrawalCredentials(password string, withdrawalCredentials []byte) (*PlannedAccount, error) {
+	if dr.withdrawalKeyPassword != "" {
+		return nil, errors.New("cannot store the withdrawal key of accounts created with withdrawal credentials")
+	}
+	return dr.planAccount(password, nil /* withdrawal key */, withdrawalCredentials)
+}
+
+func (dr *Keymanager) planAccount(
+	password string,
+	withdrawalKey bls.SecretKey,
+	withdrawalCredentials []byte,
+) (*PlannedAccount, error) {
+	validatingKey := bls.RandKey()
+	accountName, err := dr.generateAccountName(validatingKey.PublicKey().Marshal())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not generate unique account name")
+	}
+	if _, err := dr.generateKeystoreFile(validatingKey, password); err != nil {
+		return nil, err
+	}
+	_, depositData, err := depositutil.GenerateDepositTransactionWithCredentials(validatingKey, withdrawalCredentials)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not generate deposit transaction data")
+	}
+	accountDir := filepath.Join(dr.wallet.AccountsDir(), accountName)
+	files := []string{filepath.Join(accountDir, fmt.Sprintf(dr.keystoreFileNameFormat(), roughtime.Now().Unix()))}
+	format := SSZDepositDataFormat
+	if dr.cfg != nil && dr.cfg.DepositDataFormat != "" {
+		format = dr.cfg.DepositDataFormat
+	}
+	if format == SSZDepositDataFormat || format == AllDepositDataFormats {
+		files = append(files, filepath.Join(accountDir, DepositDataFileName))
+	}
+	if format == JSONDepositDataFormat || format == AllDepositDataFormats {
+		files = append(files, filepath.Join(accountDir, DepositDataJSONFileName))
+	}
+	if withdrawalKey != nil && dr.withdrawalKeyPassword != "" {
+		if _, err := dr.generateKeystoreFile(withdrawalKey, dr.withdrawalKeyPassword); err != nil {
+			return nil, errors.Wrap(err, "could not encrypt withdrawal key")
+		}
+		files = append(files, filepath.Join(accountDir, WithdrawalKeystoreFileName))
+	}
+	return &PlannedAccount{
+		Name:        accountName,
+		PublicKey:   bytesutil.ToBytes48(validatingKey.PublicKey().Marshal()),
+		DepositData: depositData,
+		Files:       files,
+	}, nil
+}
+
+// StoreWithdrawalKeys makes the accounts created afterwards store their withdrawal key as an
+// EIP-2335 keystore encrypted with the given password, instead of displaying it once.
+func (dr *Keymanager) StoreWithdrawalKeys(password string) {
+	dr.withdrawalKeyPassword = password
+}
+
+// ImportSecretKey stores an existing validating secret key as a new EIP-2335 keystore
+// account in the wallet, protected by the given password. The encrypted keystore is
+// decrypted again before it is written to ensure it round-trips to the same key.
+func (dr *Keymanager) ImportSecretKey(ctx context.Context, secretKey bls.SecretKey, password string) (string, error) {
+	accountName, err := dr.generateAccountName(secretKey.PublicKey().Marshal())
+	if err != nil {
+		return "", errors.Wrap(err, "could not generate unique account name")
+	}
+	password, err = dr.accountPassword(accountName, password)
+	if err != nil {
+		return "", err
+	}
+	encoded, err := dr.generateKeystoreFile(secretKey, password)
+	if err != nil {
+		return "", err
+	}
+	keystoreFile := &v2keymanager.Keystore{}
+	if err := json.Unmarshal(encoded, keystoreFile); err != nil {
+		return "", errors.Wrap(err, "could not decode generated keystore")
+	}
+	rawSigningKey, err := decryptSecret(keystoreFile.Crypto, password)
+	if err != nil {
+		return "", errors.Wrap(err, "could not decrypt generated keystore")
+	}
+	defer v2keymanager.ZeroSecret(rawSigningKey)
+	rawSecretKey := secretKey.Marshal()
+	defer v2keymanager.ZeroSecret(rawSecretKey)
+	if !bytes.Equal(rawSigningKey, rawSecretKey) {
+		return "", fmt.Errorf("generated keystore for account %s does not match its secret key", accountName)
+	}
+	if err := dr.wallet.WritePasswordToDisk(ctx, accountName+PasswordFileSuffix, password); err != nil {
+		return "", errors.Wrap(err, "could not write password to disk")
+	}
+	createdAt := roughtime.Now().Unix()
+	if err := dr.wallet.WriteFileAtPath(ctx, accountName, fmt.Sprintf(dr.keystoreFileNameFormat(), createdAt), encoded); err != nil {
+		return "", errors.Wrapf(err, "could not write keystore file for account %s", accountName)
+	}
+	dr.lock.Lock()
+	err = dr.cacheSecretKey(secretKey)
+	dr.lock.Unlock()
+	if err != nil {
+		return "", errors.Wrapf(err, "could not cache signing key for account %s", accountName)
+	}
+	return accountName, nil
+}
+
+// ChangePassword re-encrypts the keystore of an account, which must decrypt with its old password,
+// with a new password and writes the new password to the password file of the account. The
+// validating key, uuid and file name of the keystore are left unchanged.
+func (dr *Keymanager) ChangePassword(ctx context.Context, accountName, oldPassword, newPassword string) error {
+	if _, ok, err := dr.derivedAccountPassword(accountName); err != nil || ok {
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("the password of account %s is derived from the wallet master password, change the wallet password instead", accountName)
+	}
+	keystoreFileName, err := dr.wallet.FileNameAtPath(ctx, accountName, dr.keystoreFileGlob())
+	if err != nil {
+		return errors.Wrapf(err, "no keystore found for account %s", accountName)
+	}
+	previous, err := dr.wallet.ReadFileAtPath(ctx, accountName, keystoreFileName)
+	if err != nil {
+		return errors.Wrapf(err, "could not read keystore file for account %s", accountName)
+	}
+	keystoreFile := &v2keymanager.Keystore{}
+	if err := json.Unmarshal(previous, keystoreFile); err != nil {
+		return errors.Wrapf(err, "could not decode keystore file for account %s", accountName)
+	}
+	rawSigningKey, err := decryptSecret(keystoreFile.Crypto, oldPassword)
+	if err != nil {
+		return errors.Wrapf(err, "could not decrypt keystore of account %s with its current password", accountName)
+	}
+	defer v2keymanager.ZeroSecret(rawSigningKey)
+	secretKey, err := bls.SecretKeyFromBytes(rawSigningKey)
+	if err != nil {
+		return errors.Wrapf(err, "could not determine signing key for account %s", accountName)
+	}
+	encoded, err := dr.generateKeystoreFile(secretKey, newPassword)
+	if err != nil {
+		return err
+	}
+	reencrypted := &v2keymanager.Keystore{}
+	if err := json.Unmarshal(encoded, reencrypted); err != nil {
+		return errors.Wrap(err, "could not decode generated keystore")
+	}
+	if keystoreFile.ID != "" {
+		reencrypted.ID = keystoreFile.ID
+	}
+	rawReencryptedKey, err := decryptSecret(reencrypted.Crypto, newPassword)
+	if err != nil {
+		return errors.Wrap(err, "could not decrypt generated keystore")
+	}
+	defer v2keymanager.ZeroSecret(rawReencryptedKey)
+	if !bytes.Equal(rawReencryptedKey, rawSigningKey) {
+		return fmt.Errorf("generated keystore for account %s does not match its secret key", accountName)
+	}
+	encoded, err = json.MarshalIndent(reencrypted, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "could not encode generated keystore")
+	}
+
+	if err := dr.wallet.WriteFileAtPath(ctx, accountName, keystoreFileName, encoded); err != nil {
+		return errors.Wrapf(err, "could not write keystore file for account %s", accountName)
+	}
+	if err := dr.wallet.WritePasswordToDisk(ctx, accountName+PasswordFileSuffix, newPassword); err != nil {
+		// The keystore must keep decrypting with the password on disk.
+		if err := dr.wallet.WriteFileAtPath(ctx, accountName, keystoreFileName, previous); err != nil {
+			log.WithError(err).Errorf("Could not restore keystore file for account %s", accountName)
+		}
+		return errors.Wrapf(err, "could not write password file for account %s", accountName)
+	}
+	return nil
+}
+
+// ReencryptKeystore returns a copy of the keystore of an account, which must decrypt with the old
+// password, encrypted with the new password instead, with the KDF it was encrypted with. Its uuid,
+// public key and path are unchanged.
+func ReencryptKeystore(keystore *v2keymanager.Keystore, oldPassword string, newPassword string) (*v2keymanager.Keystore, error) {
+	encryptor := keystorev4.New()
+	rawSigningKey, err := decryptSecret(keystore.Crypto, oldPassword)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decrypt keystore with password")
+	}
+	defer v2keymanager.ZeroSecret(rawSigningKey)
+	var cryptoFields map[string]interface{}
+	kdf, err := kdfConfigOf(keystore.Crypto)
+	if err == nil && kdf.Validate() == nil {
+		cryptoFields, err = kdf.encrypt(rawSigningKey, newPassword)
+	} else {
+		cryptoFields, err = encryptor.Encrypt(rawSigningKey, newPassword)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encrypt validating key into keystore")
+	}
+	reencrypted := *keystore
+	reencrypted.Crypto = cryptoFields
+	reencrypted.Version = encryptor.Version()
+	reencrypted.Name = encryptor.Name()
+	return &reencrypted, nil
+}
+
+// DeleteAccounts removes the accounts of the given validating public keys from the wallet, along
+// with their password files, and evicts their secret keys from the keys cache so they can no
+// longer sign.
+func (dr *Keymanager) DeleteAccounts(ctx context.Context, publicKeys [][48]byte) error {
+	accountNames, err := dr.ValidatingAccountNames()
+	if err != nil {
+		return errors.Wrap(err, "could not fetch account names")
+	}
+	byPubKey := make(map[[48]byte]string, len(accountNames))
+	for _, name := range accountNames {
+		pubKey, err := dr.PublicKeyForAccount(name)
+		if err != nil {
+			return errors.Wrapf(err, "could not get public key for account %s", name)
+		}
+		byPubKey[pubKey] = name
+	}
+	for _, pubKey := range publicKeys {
+		if _, ok := byPubKey[pubKey]; !ok {
+			return fmt.Errorf("no account found for public key %#x", pubKey)
+		}
+	}
+	for _, pubKey := range publicKeys {
+		accountName := byPubKey[pubKey]
+		if err := dr.wallet.DeleteAccountFiles(ctx, accountName, accountName+PasswordFileSuffix); err != nil {
+			return errors.Wrapf(err, "could not delete account %s", accountName)
+		}
+		dr.lock.Lock()
+		dr.evictSecretKey(pubKey)
+		dr.lock.Unlock()
+		log.WithFields(logrus.Fields{
+			"name":      accountName,
+			"publicKey": fmt.Sprintf("%#x", bytesutil.Trunc(pubKey[:])),
+		}).Info("Deleted validator account")
+	}
+	return nil
+}
+
 // FetchValidatingPublicKeys fetches the list of public keys from the direct account keystores.
 func (dr *Keymanager) FetchValidatingPublicKeys(ctx context.Context) ([][48]byte, error) {
 	accountNames, err := dr.ValidatingAccountNames()
@@ -237,8 +822,16 @@ func (dr *Keymanager) FetchValidatingPublicKeys(ctx context.Context) ([][48]byte
 		return publicKeys, nil
 	}
 
+	compacted, err := dr.compactedPublicKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
 	for i, name := range accountNames {
-		encoded, err := dr.wallet.ReadFileAtPath(ctx, name, KeystoreFileName)
+		if pubKey, ok := compacted[name]; ok {
+			publicKeys[i] = pubKey
+			continue
+		}
+		encoded, err := dr.wallet.ReadFileAtPath(ctx, name, dr.keystoreFileGlob())
 		if err != nil {
 			return nil, errors.Wrapf(err, "could not read keystore file for account %s", name)
 		}
@@ -263,10 +856,14 @@ func (dr *Keymanager) Sign(ctx context.Context, req *validatorpb.SignRequest) (b
 	}
 	dr.lock.RLock()
 	defer dr.lock.RUnlock()
+	if dr.locked {
+		return nil, ErrKeymanagerLocked
+	}
 	secretKey, ok := dr.keysCache[bytesutil.ToBytes48(rawPubKey)]
 	if !ok {
 		return nil, errors.New("no signing key found in keys cache")
 	}
+	dr.touch()
 	return secretKey.Sign(req.SigningRoot), nil
 }
 
@@ -283,9 +880,45 @@ func (dr *Keymanager) PublicKeyForAccount(accountName string) ([48]byte, error)
 	return bytesutil.ToBytes48(pubKey), nil
 }
 
+// ExportKeystores re-encrypts the secret keys for the requested public keys
+// into standalone EIP-2335 keystores protected by the export password.
+func (dr *Keymanager) ExportKeystores(
+	ctx context.Context,
+	publicKeys [][48]byte,
+	exportPassword string,
+) ([]*v2keymanager.Keystore, error) {
+	dr.lock.RLock()
+	defer dr.lock.RUnlock()
+	if dr.locked {
+		return nil, ErrKeymanagerLocked
+	}
+	keystores := make([]*v2keymanager.Keystore, len(publicKeys))
+	for i, pubKey := range publicKeys {
+		secretKey, ok := dr.keysCache[pubKey]
+		if !ok {
+			return nil, fmt.Errorf("no secret key found for public key %#x", pubKey)
+		}
+		keystore, err := v2keymanager.NewKeystore(secretKey, "" /* path */, exportPassword)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not export keystore for public key %#x", pubKey)
+		}
+		keystores[i] = keystore
+	}
+	return keystores, nil
+}
+
 func (dr *Keymanager) keystoreForAccount(accountName string) (*v2keymanager.Keystore, error) {
-	encoded, err := dr.wallet.ReadFileAtPath(context.Background(), accountName, KeystoreFileName)
+	encoded, err := dr.wallet.ReadFileAtPath(context.Background(), accountName, dr.keystoreFileGlob())
 	if err != nil {
+		if pubKeys, pubKeysErr := dr.compactedPublicKeys(context.Background()); pubKeysErr == nil {
+			if _, ok := pubKeys[accountName]; ok {
+				return nil, fmt.Errorf(
+					"account %s is compacted into %s, expand the wallet with wallet-v2 compact --expand first",
+					accountName,
+					AccountsKeystoreFileName,
+				)
+			}
+		}
 		return nil, errors.Wrap(err, "could not read keystore file")
 	}
 	keystoreJSON := &v2keymanager.Keystore{}
@@ -303,6 +936,25 @@ func (dr *Keymanager) initializeSecretKeysCache(ctx context.Context) error {
 	if len(accountNames) == 0 {
 		return nil
 	}
+	dr.lock.Lock()
+	compacted, err := dr.loadCompactedAccounts(ctx, accountNames)
+	dr.lock.Unlock()
+	if err != nil {
+		return errors.Wrap(err, "could not load compacted accounts")
+	}
+	if len(compacted) > 0 {
+		// Only the accounts not compacted yet have keystores of their own to decrypt.
+		remaining := make([]string, 0, len(accountNames)-len(compacted))
+		for _, name := range accountNames {
+			if !compacted[name] {
+				remaining = append(remaining, name)
+			}
+		}
+		accountNames = remaining
+		if len(accountNames) == 0 {
+			return nil
+		}
+	}
 	// We initialize a nice progress bar to offer the user feedback
 	// during this slow operation.
 	bar := initializeProgressBar(len(accountNames))
@@ -329,7 +981,7 @@ func (dr *Keymanager) initializeSecretKeysCache(ctx context.Context) error {
 			if err != nil {
 				return nil, errors.Wrapf(err, "could not read password for account %s", name)
 			}
-			encoded, err := dr.wallet.ReadFileAtPath(ctx, name, KeystoreFileName)
+			encoded, err := dr.wallet.ReadFileAtPath(ctx, name, dr.keystoreFileGlob())
 			if err != nil {
 				return nil, errors.Wrapf(err, "could not read keystore file for account %s", name)
 			}
@@ -340,18 +992,20 @@ func (dr *Keymanager) initializeSecretKeysCache(ctx context.Context) error {
 			// We extract the validator signing private key from the keystore
 			// by utilizing the password and initialize a new BLS secret key from
 			// its raw bytes.
-			decryptor := keystorev4.New()
-			rawSigningKey, err := decryptor.Decrypt(keystoreFile.Crypto, password)
+			rawSigningKey, err := decryptSecret(keystoreFile.Crypto, password)
 			if err != nil {
 				return nil, errors.Wrapf(err, "could not decrypt signing key for account %s", name)
 			}
 			validatorSigningKey, err := bls.SecretKeyFromBytes(rawSigningKey)
+			v2keymanager.ZeroSecret(rawSigningKey)
 			if err != nil {
 				return nil, errors.Wrapf(err, "could not determine signing key for account %s", name)
 			}
 			// Update a simple cache of public key -> secret key utilized
 			// for fast signing access in the direct keymanager.
-			dr.keysCache[bytesutil.ToBytes48(validatorSigningKey.PublicKey().Marshal())] = validatorSigningKey
+			if err := dr.cacheSecretKey(validatorSigningKey); err != nil {
+				return nil, errors.Wrapf(err, "could not cache signing key for account %s", name)
+			}
 			progressChan <- struct{}{}
 		}
 		return nil, nil
@@ -359,9 +1013,42 @@ func (dr *Keymanager) initializeSecretKeysCache(ctx context.Context) error {
 	return err
 }
 
+// Returns the deposit data files of an account by file name, in the deposit data format
+// of the keymanager config.
+func (dr *Keymanager) depositDataFiles(depositData *ethpb.Deposit_Data, encodedDepositData []byte) (map[string][]byte, error) {
+	format := SSZDepositDataFormat
+	if dr.cfg != nil && dr.cfg.DepositDataFormat != "" {
+		format = dr.cfg.DepositDataFormat
+	}
+	files := make(map[string][]byte)
+	if format == SSZDepositDataFormat || format == AllDepositDataFormats {
+		files[DepositDataFileName] = encodedDepositData
+	}
+	if format == JSONDepositDataFormat || format == AllDepositDataFormats {
+		depositJSON, err := depositutil.DepositDataJSONFromProto(depositData)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not convert deposit data to json")
+		}
+		encodedJSON, err := json.MarshalIndent(depositJSON, "", "\t")
+		if err != nil {
+			return nil, errors.Wrap(err, "could not marshal deposit data json")
+		}
+		files[DepositDataJSONFileName] = encodedJSON
+	}
+	return files, nil
+}
+
 func (dr *Keymanager) generateKeystoreFile(validatingKey bls.SecretKey, password string) ([]byte, error) {
 	encryptor := keystorev4.New()
-	cryptoFields, err := encryptor.Encrypt(validatingKey.Marshal(), password)
+	var cryptoFields map[string]interface{}
+	var err error
+	rawValidatingKey := validatingKey.Marshal()
+	defer v2keymanager.ZeroSecret(rawValidatingKey)
+	if dr.cfg != nil && dr.cfg.KDF != nil {
+		cryptoFields, err = dr.cfg.KDF.encrypt(rawValidatingKey, password)
+	} else {
+		cryptoFields, err = encryptor.Encrypt(rawValidatingKey, password)
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "could not encrypt validating key into keystore")
 	}
@@ -379,6 +1066,30 @@ func (dr *Keymanager) generateKeystoreFile(validatingKey bls.SecretKey, password
 	return json.MarshalIndent(keystoreFile, "", "\t")
 }
 
+// Returns the password to encrypt the keystore of an account with, which is derived from the
+// master password of the wallet if it has one, or else the given password.
+func (dr *Keymanager) accountPassword(accountName string, password string) (string, error) {
+	derived, ok, err := dr.derivedAccountPassword(accountName)
+	if err != nil || !ok {
+		return password, err
+	}
+	return derived, nil
+}
+
+// Returns the password of an account derived from the master password of the wallet, and false if
+// the wallet has none.
+func (dr *Keymanager) derivedAccountPassword(accountName string) (string, bool, error) {
+	deriver, ok := dr.wallet.(iface.AccountPasswordDeriver)
+	if !ok {
+		return "", false, nil
+	}
+	password, ok, err := deriver.DeriveAccountPassword(accountName)
+	if err != nil {
+		return "", false, errors.Wrapf(err, "could not derive password of account %s", accountName)
+	}
+	return password, ok, nil
+}
+
 func (dr *Keymanager) generateAccountName(pubKey []byte) (string, error) {
 	var accountExists bool
 	var accountName string
@@ -400,11 +1111,11 @@ func (dr *Keymanager) checkPasswordForAccount(accountName string, password strin
 	if err != nil {
 		return errors.Wrap(err, "could not get keystore")
 	}
-	decryptor := keystorev4.New()
-	_, err = decryptor.Decrypt(accountKeystore.Crypto, password)
+	rawSigningKey, err := decryptSecret(accountKeystore.Crypto, password)
 	if err != nil {
 		return errors.Wrap(err, "could not decrypt keystore")
 	}
+	v2keymanager.ZeroSecret(rawSigningKey)
 	return nil
 }
 
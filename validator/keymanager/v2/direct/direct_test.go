@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 
 	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
@@ -13,6 +15,7 @@ import (
 	validatorpb "github.com/prysmaticlabs/prysm/proto/validator/accounts/v2"
 	"github.com/prysmaticlabs/prysm/shared/bls"
 	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/depositutil"
 	"github.com/prysmaticlabs/prysm/shared/testutil"
 	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
 	"github.com/prysmaticlabs/prysm/shared/testutil/require"
@@ -205,3 +208,309 @@ func generateAccounts(t testing.TB, numAccounts int, dr *Keymanager) ([]string,
 	}
 	return accountNames, wantedPublicKeys
 }
+
+func TestDirectKeymanager_CreateAccount_DepositDataFormats(t *testing.T) {
+	tests := []struct {
+		format   string
+		wantSSZ  bool
+		wantJSON bool
+	}{
+		{format: "", wantSSZ: true},
+		{format: SSZDepositDataFormat, wantSSZ: true},
+		{format: JSONDepositDataFormat, wantJSON: true},
+		{format: AllDepositDataFormats, wantSSZ: true, wantJSON: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			wallet := &mock.Wallet{
+				Files:            make(map[string]map[string][]byte),
+				AccountPasswords: make(map[string]string),
+			}
+			dr := &Keymanager{
+				wallet: wallet,
+				cfg: &Config{
+					EIPVersion:        eipVersion,
+					DepositDataFormat: tt.format,
+				},
+			}
+			accountName, err := dr.CreateAccount(context.Background(), "secretPassw0rd$1999")
+			require.NoError(t, err)
+			encodedSSZ, hasSSZ := wallet.Files[accountName][DepositDataFileName]
+			encodedJSON, hasJSON := wallet.Files[accountName][DepositDataJSONFileName]
+			assert.Equal(t, tt.wantSSZ, hasSSZ)
+			assert.Equal(t, tt.wantJSON, hasJSON)
+			if hasSSZ {
+				depositData := &ethpb.Deposit_Data{}
+				require.NoError(t, ssz.Unmarshal(encodedSSZ, depositData))
+			}
+			if hasJSON {
+				depositJSON := &depositutil.DepositDataJSON{}
+				require.NoError(t, json.Unmarshal(encodedJSON, depositJSON))
+				assert.Equal(t, 96, len(depositJSON.PubKey))
+				assert.Equal(t, 192, len(depositJSON.Signature))
+			}
+		})
+	}
+}
+
+func TestDirectKeymanager_CreateAccount_KeystoreFileNameFormat(t *testing.T) {
+	wallet := &mock.Wallet{
+		Files:            make(map[string]map[string][]byte),
+		AccountPasswords: make(map[string]string),
+	}
+	dr := &Keymanager{
+		wallet: wallet,
+		cfg: &Config{
+			EIPVersion:             eipVersion,
+			KeystoreFileNameFormat: "validator-keystore-%d.json",
+		},
+	}
+	accountName, err := dr.CreateAccount(context.Background(), "secretPassw0rd$1999")
+	require.NoError(t, err)
+	keystoreFileNames := make([]string, 0)
+	for fileName := range wallet.Files[accountName] {
+		if ok, err := filepath.Match(dr.keystoreFileGlob(), fileName); err == nil && ok {
+			keystoreFileNames = append(keystoreFileNames, fileName)
+		}
+	}
+	require.Equal(t, 1, len(keystoreFileNames))
+	assert.Equal(t, true, strings.HasPrefix(keystoreFileNames[0], "validator-keystore-"))
+}
+
+func TestValidateKeystoreFileNameFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr string
+	}{
+		{format: ""},
+		{format: KeystoreFileNameFormat},
+		{format: "validator-keystore-%d.json"},
+		{format: "keystore.json", wantErr: "must have a single %d"},
+		{format: "keystore-%d-%d.json", wantErr: "must have a single %d"},
+		{format: "keystore-%s.json", wantErr: "must have a single %d"},
+		{format: "keys/keystore-%d.json", wantErr: "must not contain path separators"},
+		{format: "keystore-*-%d.json", wantErr: "must not contain path separators or glob characters"},
+		{format: "deposit_data%d.json", wantErr: "also names the deposit_data.json file"},
+		{format: "%d", wantErr: "also names the"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			err := ValidateKeystoreFileNameFormat(tt.format)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDirectKeymanager_CreateAccount_StoreWithdrawalKey(t *testing.T) {
+	hook := logTest.NewGlobal()
+	wallet := &mock.Wallet{
+		Files:            make(map[string]map[string][]byte),
+		AccountPasswords: make(map[string]string),
+	}
+	dr := &Keymanager{
+		wallet: wallet,
+	}
+	ctx := context.Background()
+	withdrawalPassword := "withdrawalPassw0rd$2020"
+	dr.StoreWithdrawalKeys(withdrawalPassword)
+	accountName, err := dr.CreateAccount(ctx, "secretPassw0rd$1999")
+	require.NoError(t, err)
+
+	encodedKeystore, ok := wallet.Files[accountName][WithdrawalKeystoreFileName]
+	require.Equal(t, true, ok, "Expected to have stored %s in wallet", WithdrawalKeystoreFileName)
+	keystoreFile := &v2keymanager.Keystore{}
+	require.NoError(t, json.Unmarshal(encodedKeystore, keystoreFile))
+	rawWithdrawalKey, err := keystorev4.New().Decrypt(keystoreFile.Crypto, withdrawalPassword)
+	require.NoError(t, err, "Could not decrypt withdrawal key")
+	withdrawalKey, err := bls.SecretKeyFromBytes(rawWithdrawalKey)
+	require.NoError(t, err)
+
+	// The deposit of the account withdraws to the stored withdrawal key.
+	depositData := &ethpb.Deposit_Data{}
+	require.NoError(t, ssz.Unmarshal(wallet.Files[accountName][DepositDataFileName], depositData))
+	assert.DeepEqual(t, depositutil.WithdrawalCredentialsHash(withdrawalKey), depositData.WithdrawalCredentials)
+	testutil.AssertLogsContain(t, hook, "withdrawal key of the account is stored in the wallet")
+	testutil.AssertLogsDoNotContain(t, hook, "Write down the private key")
+}
+
+func TestDirectKeymanager_CreateAccountWithWithdrawalCredentials(t *testing.T) {
+	hook := logTest.NewGlobal()
+	wallet := &mock.Wallet{
+		Files:            make(map[string]map[string][]byte),
+		AccountPasswords: make(map[string]string),
+	}
+	dr := &Keymanager{
+		wallet: wallet,
+	}
+	ctx := context.Background()
+	withdrawalCredentials := depositutil.WithdrawalCredentialsHash(bls.RandKey())
+	accountName, err := dr.CreateAccountWithWithdrawalCredentials(ctx, "secretPassw0rd$1999", withdrawalCredentials)
+	require.NoError(t, err)
+
+	depositData := &ethpb.Deposit_Data{}
+	require.NoError(t, ssz.Unmarshal(wallet.Files[accountName][DepositDataFileName], depositData))
+	assert.DeepEqual(t, withdrawalCredentials, depositData.WithdrawalCredentials)
+	testutil.AssertLogsDoNotContain(t, hook, "Write down the private key")
+
+	// There is no withdrawal key to store.
+	dr.StoreWithdrawalKeys("withdrawalPassw0rd$2020")
+	_, err = dr.CreateAccountWithWithdrawalCredentials(ctx, "secretPassw0rd$1999", withdrawalCredentials)
+	assert.ErrorContains(t, "cannot store the withdrawal key", err)
+}
+
+func TestDirectKeymanager_ImportSecretKey(t *testing.T) {
+	wallet := &mock.Wallet{
+		Files:            make(map[string]map[string][]byte),
+		AccountPasswords: make(map[string]string),
+	}
+	dr := &Keymanager{
+		wallet: wallet,
+	}
+	ctx := context.Background()
+	password := "secretPassw0rd$1999"
+	secretKey := bls.RandKey()
+	accountName, err := dr.ImportSecretKey(ctx, secretKey, password)
+	require.NoError(t, err)
+	assert.Equal(t, password, wallet.AccountPasswords[accountName+PasswordFileSuffix])
+
+	var encodedKeystore []byte
+	for k, v := range wallet.Files[accountName] {
+		if strings.Contains(k, "keystore") {
+			encodedKeystore = v
+		}
+	}
+	require.NotNil(t, encodedKeystore, "could not find keystore file")
+	keystoreFile := &v2keymanager.Keystore{}
+	require.NoError(t, json.Unmarshal(encodedKeystore, keystoreFile))
+	decryptor := keystorev4.New()
+	rawSigningKey, err := decryptor.Decrypt(keystoreFile.Crypto, password)
+	require.NoError(t, err, "Could not decrypt validator signing key")
+	assert.DeepEqual(t, secretKey.Marshal(), rawSigningKey)
+
+	// The imported key should be immediately available for signing.
+	pubKey := secretKey.PublicKey().Marshal()
+	sig, err := dr.Sign(ctx, &validatorpb.SignRequest{
+		PublicKey:   pubKey,
+		SigningRoot: []byte("hello world"),
+	})
+	require.NoError(t, err)
+	assert.DeepEqual(t, secretKey.Sign([]byte("hello world")).Marshal(), sig.Marshal())
+}
+
+func TestDirectKeymanager_DeleteAccounts(t *testing.T) {
+	wallet := &mock.Wallet{
+		Files:            make(map[string]map[string][]byte),
+		AccountPasswords: make(map[string]string),
+	}
+	dr := &Keymanager{
+		wallet: wallet,
+	}
+	ctx := context.Background()
+	password := "secretPassw0rd$1999"
+	secretKeys := []bls.SecretKey{bls.RandKey(), bls.RandKey()}
+	for _, secretKey := range secretKeys {
+		accountName, err := dr.ImportSecretKey(ctx, secretKey, password)
+		require.NoError(t, err)
+		wallet.Directories = append(wallet.Directories, accountName)
+	}
+	deleted := bytesutil.ToBytes48(secretKeys[0].PublicKey().Marshal())
+	require.NoError(t, dr.DeleteAccounts(ctx, [][48]byte{deleted}))
+
+	pubKeys, err := dr.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	assert.DeepEqual(t, [][48]byte{bytesutil.ToBytes48(secretKeys[1].PublicKey().Marshal())}, pubKeys)
+	assert.Equal(t, 1, len(wallet.AccountPasswords))
+	_, err = dr.Sign(ctx, &validatorpb.SignRequest{
+		PublicKey:   deleted[:],
+		SigningRoot: []byte("hello world"),
+	})
+	assert.ErrorContains(t, "no signing key found in keys cache", err)
+
+	err = dr.DeleteAccounts(ctx, [][48]byte{deleted})
+	assert.ErrorContains(t, "no account found for public key", err)
+}
+
+func TestDirectKeymanager_WipesDecryptedSecrets(t *testing.T) {
+	var lock sync.Mutex
+	var secrets [][]byte
+	decrypt := decryptSecret
+	decryptSecret = func(cryptoFields map[string]interface{}, password string) ([]byte, error) {
+		secret, err := decrypt(cryptoFields, password)
+		lock.Lock()
+		secrets = append(secrets, secret)
+		lock.Unlock()
+		return secret, err
+	}
+	defer func() {
+		decryptSecret = decrypt
+	}()
+	requireWiped := func(t *testing.T) {
+		require.NotEqual(t, 0, len(secrets), "Expected secrets to be decrypted")
+		for _, secret := range secrets {
+			assert.DeepEqual(t, make([]byte, len(secret)), secret, "Decrypted secret was not wiped")
+		}
+		secrets = nil
+	}
+
+	wallet := &mock.Wallet{
+		Files:            make(map[string]map[string][]byte),
+		AccountPasswords: make(map[string]string),
+	}
+	dr := &Keymanager{
+		wallet: wallet,
+	}
+	ctx := context.Background()
+	password := "secretPassw0rd$1999"
+	secretKey := bls.RandKey()
+	accountName, err := dr.ImportSecretKey(ctx, secretKey, password)
+	require.NoError(t, err)
+	requireWiped(t)
+
+	wallet.Directories = []string{accountName}
+	dr.keysCache = make(map[[48]byte]bls.SecretKey)
+	require.NoError(t, dr.initializeSecretKeysCache(ctx))
+	requireWiped(t)
+	sig, err := dr.Sign(ctx, &validatorpb.SignRequest{
+		PublicKey:   secretKey.PublicKey().Marshal(),
+		SigningRoot: []byte("hello world"),
+	})
+	require.NoError(t, err)
+	assert.DeepEqual(t, secretKey.Sign([]byte("hello world")).Marshal(), sig.Marshal())
+
+	require.NoError(t, dr.checkPasswordForAccount(accountName, password))
+	requireWiped(t)
+
+	newPassword := "n3wSecretPassw0rd$2020"
+	require.NoError(t, dr.ChangePassword(ctx, accountName, password, newPassword))
+	requireWiped(t)
+	require.NoError(t, dr.checkPasswordForAccount(accountName, newPassword))
+	requireWiped(t)
+}
+
+func TestDirectKeymanager_ChangePassword_NotOnDisk(t *testing.T) {
+	// The accounts of the wallet are only in its storage, its accounts directory is empty.
+	wallet := &mock.Wallet{
+		InnerAccountsDir: testutil.TempDir(),
+		Files:            make(map[string]map[string][]byte),
+		AccountPasswords: make(map[string]string),
+	}
+	dr := &Keymanager{
+		wallet: wallet,
+	}
+	ctx := context.Background()
+	password := "secretPassw0rd$1999"
+	accountName, err := dr.ImportSecretKey(ctx, bls.RandKey(), password)
+	require.NoError(t, err)
+
+	newPassword := "n3wSecretPassw0rd$2020"
+	require.NoError(t, dr.ChangePassword(ctx, accountName, password, newPassword))
+	require.NoError(t, dr.checkPasswordForAccount(accountName, newPassword))
+	assert.Equal(t, newPassword, wallet.AccountPasswords[accountName+PasswordFileSuffix])
+
+	err = dr.ChangePassword(ctx, "unknown-account", password, newPassword)
+	assert.ErrorContains(t, "no keystore found for account unknown-account", err)
+}
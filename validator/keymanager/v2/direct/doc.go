@@ -40,9 +40,19 @@ This direct keymanager can be customized via a keymanageropts.json file, which h
 JSON schema as its options:
 
  {
-   "direct_eip_version": "EIP-2335"
+   "direct_eip_version": "EIP-2335",
+   "direct_kdf": {
+     "function": "scrypt",
+     "scrypt_n": 262144,
+     "scrypt_r": 8,
+     "scrypt_p": 1
+   }
  }
 
-Currently, the only supported value for `direct_eip_version` is "EIP-2335".
+Currently, the only supported value for `direct_eip_version` is "EIP-2335". The optional
+`direct_kdf` object selects the key derivation function used to encrypt newly generated
+keystores, either "scrypt" (tuned via `scrypt_n`, `scrypt_r`, `scrypt_p`) or "pbkdf2"
+(tuned via `pbkdf2_iterations`), letting operators trade unlock speed against brute-force
+resistance. Parameters below a minimum cost are rejected.
 */
 package direct
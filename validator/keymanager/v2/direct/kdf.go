@@ -0,0 +1,253 @@
+package direct
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/rand"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// ScryptKDF is the scrypt key derivation function as defined in EIP-2335.
+	ScryptKDF = "scrypt"
+	// PBKDF2KDF is the pbkdf2 key derivation function as defined in EIP-2335.
+	PBKDF2KDF = "pbkdf2"
+
+	// Default cost parameters matching the EIP-2335 test vectors and keystorev4.
+	defaultScryptN          = 262144
+	defaultScryptR          = 8
+	defaultScryptP          = 1
+	defaultPBKDF2Iterations = 262144
+
+	// Minimum cost parameters we allow operators to configure, so keystores
+	// remain reasonably resistant to brute-force attacks.
+	minScryptN          = 1 << 14
+	minScryptR          = 8
+	minScryptP          = 1
+	minPBKDF2Iterations = 1 << 16
+	// The accounts keystore of a compacted wallet guards the keys of every account at once, so it
+	// is held to the default scrypt cost at least.
+	minAccountsKeystoreScryptN = defaultScryptN
+
+	keystoreDKLen    = 32
+	keystoreSaltSize = 32
+)
+
+// KDFConfig defines the key derivation function and cost parameters used
+// when encrypting newly generated EIP-2335 keystores. Fields left as zero
+// fall back to the EIP-2335 defaults for the chosen function.
+type KDFConfig struct {
+	Function         string `json:"function"`
+	ScryptN          int    `json:"scrypt_n,omitempty"`
+	ScryptR          int    `json:"scrypt_r,omitempty"`
+	ScryptP          int    `json:"scrypt_p,omitempty"`
+	PBKDF2Iterations int    `json:"pbkdf2_iterations,omitempty"`
+}
+
+// Validate checks the KDF configuration uses a supported function
+// and that its cost parameters meet the enforced minimums.
+func (k *KDFConfig) Validate() error {
+	switch k.Function {
+	case ScryptKDF:
+		n, r, p := k.scryptParams()
+		if n < minScryptN || n&(n-1) != 0 {
+			return fmt.Errorf("scrypt N must be a power of 2 and at least %d, received %d", minScryptN, n)
+		}
+		if r < minScryptR {
+			return fmt.Errorf("scrypt r must be at least %d, received %d", minScryptR, r)
+		}
+		if p < minScryptP {
+			return fmt.Errorf("scrypt p must be at least %d, received %d", minScryptP, p)
+		}
+	case PBKDF2KDF:
+		if c := k.pbkdf2Iterations(); c < minPBKDF2Iterations {
+			return fmt.Errorf("pbkdf2 iterations must be at least %d, received %d", minPBKDF2Iterations, c)
+		}
+	default:
+		return fmt.Errorf("%s is not a supported kdf, expected %s or %s", k.Function, ScryptKDF, PBKDF2KDF)
+	}
+	return nil
+}
+
+// ValidateAccountsKeystore checks the KDF configuration can encrypt the accounts keystore of a
+// compacted wallet, which must use scrypt with a cost of at least the EIP-2335 default.
+func (k *KDFConfig) ValidateAccountsKeystore() error {
+	if k.Function != ScryptKDF {
+		return fmt.Errorf("the accounts keystore must use the %s kdf, received %s", ScryptKDF, k.Function)
+	}
+	if err := k.Validate(); err != nil {
+		return err
+	}
+	if n, _, _ := k.scryptParams(); n < minAccountsKeystoreScryptN {
+		return fmt.Errorf("scrypt N of the accounts keystore must be at least %d, received %d", minAccountsKeystoreScryptN, n)
+	}
+	return nil
+}
+
+// String pretty-print of a KDF configuration.
+func (k *KDFConfig) String() string {
+	if k.Function == PBKDF2KDF {
+		return fmt.Sprintf("%s (c=%d)", k.Function, k.pbkdf2Iterations())
+	}
+	n, r, p := k.scryptParams()
+	return fmt.Sprintf("%s (n=%d, r=%d, p=%d)", k.Function, n, r, p)
+}
+
+func (k *KDFConfig) scryptParams() (int, int, int) {
+	n, r, p := k.ScryptN, k.ScryptR, k.ScryptP
+	if n == 0 {
+		n = defaultScryptN
+	}
+	if r == 0 {
+		r = defaultScryptR
+	}
+	if p == 0 {
+		p = defaultScryptP
+	}
+	return n, r, p
+}
+
+func (k *KDFConfig) pbkdf2Iterations() int {
+	if k.PBKDF2Iterations == 0 {
+		return defaultPBKDF2Iterations
+	}
+	return k.PBKDF2Iterations
+}
+
+// Returns the KDF configuration the crypto section of an EIP-2335 keystore was encrypted with.
+func kdfConfigOf(cryptoFields map[string]interface{}) (*KDFConfig, error) {
+	kdf, ok := cryptoFields["kdf"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("keystore has no kdf module")
+	}
+	function, ok := kdf["function"].(string)
+	if !ok {
+		return nil, errors.New("keystore kdf has no function")
+	}
+	params, ok := kdf["params"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("keystore kdf has no params")
+	}
+	cfg := &KDFConfig{Function: function}
+	switch function {
+	case ScryptKDF:
+		cfg.ScryptN, cfg.ScryptR, cfg.ScryptP = intParam(params, "n"), intParam(params, "r"), intParam(params, "p")
+	case PBKDF2KDF:
+		cfg.PBKDF2Iterations = intParam(params, "c")
+	}
+	return cfg, nil
+}
+
+// Returns an integer param of a keystore module, which is a float64 once decoded from JSON, or
+// zero if it has none.
+func intParam(params map[string]interface{}, name string) int {
+	switch value := params[name].(type) {
+	case float64:
+		return int(value)
+	case int:
+		return value
+	}
+	return 0
+}
+
+// encrypt a secret into the crypto section of an EIP-2335 keystore using the
+// configured KDF parameters. The output can be decrypted by keystorev4.
+func (k *KDFConfig) encrypt(secret []byte, password string) (map[string]interface{}, error) {
+	if err := k.Validate(); err != nil {
+		return nil, err
+	}
+	salt := make([]byte, keystoreSaltSize)
+	if _, err := rand.NewGenerator().Read(salt); err != nil {
+		return nil, errors.Wrap(err, "could not generate salt")
+	}
+	normedPassword := []byte(normalizePassword(password))
+	kdfParams := map[string]interface{}{
+		"dklen": keystoreDKLen,
+		"salt":  hex.EncodeToString(salt),
+	}
+	var decryptionKey []byte
+	var err error
+	switch k.Function {
+	case ScryptKDF:
+		n, r, p := k.scryptParams()
+		decryptionKey, err = scrypt.Key(normedPassword, salt, n, r, p, keystoreDKLen)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not derive scrypt key")
+		}
+		kdfParams["n"], kdfParams["r"], kdfParams["p"] = n, r, p
+	case PBKDF2KDF:
+		c := k.pbkdf2Iterations()
+		decryptionKey = pbkdf2.Key(normedPassword, salt, c, keystoreDKLen, sha256.New)
+		kdfParams["c"], kdfParams["prf"] = c, "hmac-sha256"
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.NewGenerator().Read(iv); err != nil {
+		return nil, errors.Wrap(err, "could not generate iv")
+	}
+	block, err := aes.NewCipher(decryptionKey[:16])
+	if err != nil {
+		return nil, errors.Wrap(err, "could not initialize cipher")
+	}
+	cipherMsg := make([]byte, len(secret))
+	cipher.NewCTR(block, iv).XORKeyStream(cipherMsg, secret)
+
+	h := sha256.New()
+	if _, err := h.Write(decryptionKey[16:32]); err != nil {
+		return nil, err
+	}
+	if _, err := h.Write(cipherMsg); err != nil {
+		return nil, err
+	}
+	cryptoFields := map[string]interface{}{
+		"kdf": map[string]interface{}{
+			"function": k.Function,
+			"params":   kdfParams,
+			"message":  "",
+		},
+		"checksum": map[string]interface{}{
+			"function": "sha256",
+			"params":   map[string]interface{}{},
+			"message":  hex.EncodeToString(h.Sum(nil)),
+		},
+		"cipher": map[string]interface{}{
+			"function": "aes-128-ctr",
+			"params": map[string]interface{}{
+				"iv": hex.EncodeToString(iv),
+			},
+			"message": hex.EncodeToString(cipherMsg),
+		},
+	}
+	// Round-trip through JSON so numeric fields have the same representation
+	// as keystores produced by unmarshaling from disk.
+	enc, err := json.Marshal(cryptoFields)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]interface{})
+	if err := json.Unmarshal(enc, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Passwords are converted to their NFKD representation and stripped
+// of control codes as required by EIP-2335.
+func normalizePassword(password string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, norm.NFKD.String(password))
+}
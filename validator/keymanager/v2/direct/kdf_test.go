@@ -0,0 +1,163 @@
+package direct
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	mock "github.com/prysmaticlabs/prysm/validator/accounts/v2/testing"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+)
+
+func TestKDFConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *KDFConfig
+		wantErr string
+	}{
+		{
+			name: "scrypt defaults",
+			cfg:  &KDFConfig{Function: ScryptKDF},
+		},
+		{
+			name: "pbkdf2 defaults",
+			cfg:  &KDFConfig{Function: PBKDF2KDF},
+		},
+		{
+			name:    "unsupported function",
+			cfg:     &KDFConfig{Function: "argon2"},
+			wantErr: "not a supported kdf",
+		},
+		{
+			name:    "scrypt n too low",
+			cfg:     &KDFConfig{Function: ScryptKDF, ScryptN: 1024},
+			wantErr: "scrypt N must be a power of 2",
+		},
+		{
+			name:    "scrypt n not power of two",
+			cfg:     &KDFConfig{Function: ScryptKDF, ScryptN: minScryptN + 1},
+			wantErr: "scrypt N must be a power of 2",
+		},
+		{
+			name:    "scrypt r too low",
+			cfg:     &KDFConfig{Function: ScryptKDF, ScryptR: 1},
+			wantErr: "scrypt r must be at least",
+		},
+		{
+			name:    "pbkdf2 iterations too low",
+			cfg:     &KDFConfig{Function: PBKDF2KDF, PBKDF2Iterations: 1000},
+			wantErr: "pbkdf2 iterations must be at least",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestKDFConfig_ValidateAccountsKeystore(t *testing.T) {
+	assert.NoError(t, (&KDFConfig{Function: ScryptKDF}).ValidateAccountsKeystore())
+	assert.NoError(t, (&KDFConfig{Function: ScryptKDF, ScryptN: 1 << 20, ScryptP: 2}).ValidateAccountsKeystore())
+	assert.ErrorContains(
+		t,
+		"must use the scrypt kdf",
+		(&KDFConfig{Function: PBKDF2KDF, PBKDF2Iterations: 1 << 20}).ValidateAccountsKeystore(),
+	)
+	assert.ErrorContains(
+		t,
+		"scrypt N of the accounts keystore must be at least",
+		(&KDFConfig{Function: ScryptKDF, ScryptN: minScryptN}).ValidateAccountsKeystore(),
+	)
+	assert.ErrorContains(t, "scrypt r must be at least", (&KDFConfig{Function: ScryptKDF, ScryptR: 1}).ValidateAccountsKeystore())
+}
+
+func TestKDFConfigOf(t *testing.T) {
+	for _, cfg := range []*KDFConfig{
+		{Function: ScryptKDF, ScryptN: minScryptN, ScryptR: 8, ScryptP: 2},
+		{Function: PBKDF2KDF, PBKDF2Iterations: minPBKDF2Iterations},
+	} {
+		cryptoFields, err := cfg.encrypt([]byte("secret"), "secretPassw0rd$1999")
+		require.NoError(t, err)
+		parsed, err := kdfConfigOf(cryptoFields)
+		require.NoError(t, err)
+		assert.DeepEqual(t, cfg, parsed)
+	}
+	_, err := kdfConfigOf(map[string]interface{}{})
+	assert.ErrorContains(t, "no kdf module", err)
+}
+
+func TestKDFConfig_EncryptDecryptsWithKeystorev4(t *testing.T) {
+	password := "secretPassw0rd$1999"
+	secret := bls.RandKey().Marshal()
+	for _, cfg := range []*KDFConfig{
+		{Function: ScryptKDF, ScryptN: minScryptN},
+		{Function: PBKDF2KDF, PBKDF2Iterations: minPBKDF2Iterations},
+	} {
+		cryptoFields, err := cfg.encrypt(secret, password)
+		require.NoError(t, err)
+		kdf, ok := cryptoFields["kdf"].(map[string]interface{})
+		require.Equal(t, true, ok)
+		assert.Equal(t, cfg.Function, kdf["function"])
+
+		decrypted, err := keystorev4.New().Decrypt(cryptoFields, password)
+		require.NoError(t, err)
+		assert.DeepEqual(t, secret, decrypted)
+
+		_, err = keystorev4.New().Decrypt(cryptoFields, "wrongPassword")
+		assert.NotNil(t, err)
+	}
+}
+
+func TestDirectKeymanager_CreateAccount_CustomKDF(t *testing.T) {
+	wallet := &mock.Wallet{
+		Files:            make(map[string]map[string][]byte),
+		AccountPasswords: make(map[string]string),
+	}
+	dr := &Keymanager{
+		wallet: wallet,
+		cfg: &Config{
+			EIPVersion: eipVersion,
+			KDF:        &KDFConfig{Function: PBKDF2KDF, PBKDF2Iterations: minPBKDF2Iterations},
+		},
+	}
+	password := "secretPassw0rd$1999"
+	accountName, err := dr.CreateAccount(context.Background(), password)
+	require.NoError(t, err)
+
+	var encodedKeystore []byte
+	for k, v := range wallet.Files[accountName] {
+		if strings.Contains(k, "keystore") {
+			encodedKeystore = v
+		}
+	}
+	require.NotNil(t, encodedKeystore, "could not find keystore file")
+	keystoreFile := &v2keymanager.Keystore{}
+	require.NoError(t, json.Unmarshal(encodedKeystore, keystoreFile))
+	kdf, ok := keystoreFile.Crypto["kdf"].(map[string]interface{})
+	require.Equal(t, true, ok)
+	assert.Equal(t, PBKDF2KDF, kdf["function"])
+	_, err = keystorev4.New().Decrypt(keystoreFile.Crypto, password)
+	require.NoError(t, err)
+}
+
+func TestNewKeymanager_RejectsWeakKDF(t *testing.T) {
+	wallet := &mock.Wallet{
+		Files:            make(map[string]map[string][]byte),
+		AccountPasswords: make(map[string]string),
+	}
+	cfg := DefaultConfig()
+	cfg.KDF = &KDFConfig{Function: ScryptKDF, ScryptN: 16}
+	_, err := NewKeymanager(context.Background(), wallet, cfg)
+	assert.ErrorContains(t, "invalid kdf configuration", err)
+}
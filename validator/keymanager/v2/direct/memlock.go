@@ -0,0 +1,287 @@
+package direct
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+)
+
+// lockedKeyCanarySize is the size of the random canary written right before each secret key held
+// in locked memory, whose corruption reveals writes running into the key from the memory before it.
+const lockedKeyCanarySize = 16
+
+// Size of the slot of a secret key in locked memory, its canary followed by the deserialized key,
+// kept 8-byte aligned.
+func lockedKeySlotSize() int {
+	return (lockedKeyCanarySize + bls.SecretKeyMemorySize + 7) &^ 7
+}
+
+// lockedKeyArena packs the secret keys of a keymanager into pages of locked memory shared by many
+// keys, each page between two inaccessible guard pages, so the memlock limit of the process is
+// spent on keys rather than on a page for each key. A page is released once none of its keys are
+// left.
+type lockedKeyArena struct {
+	lock  sync.Mutex
+	pages []*lockedKeyPage
+}
+
+// lockedKeyPage is a page of locked memory divided into slots of secret keys.
+type lockedKeyPage struct {
+	mem   []byte
+	data  []byte
+	used  []bool
+	inUse int
+}
+
+// Returns the number of bytes of locked memory holding a number of secret keys, as locked pages.
+func lockedMemoryForKeys(numKeys int) uint64 {
+	pageSize := os.Getpagesize()
+	keysPerPage := pageSize / lockedKeySlotSize()
+	pages := (numKeys + keysPerPage - 1) / keysPerPage
+	return uint64(pages * pageSize)
+}
+
+// Allocates the slot of a secret key in locked memory, locking a new page if every page is full.
+func (a *lockedKeyArena) alloc() (*lockedKeyPage, int, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	for _, page := range a.pages {
+		if page.inUse == len(page.used) {
+			continue
+		}
+		for slot, used := range page.used {
+			if !used {
+				page.used[slot] = true
+				page.inUse++
+				return page, slot, nil
+			}
+		}
+	}
+	mem, data, err := allocLockedMemory(lockedKeySlotSize())
+	if err != nil {
+		return nil, 0, err
+	}
+	page := &lockedKeyPage{mem: mem, data: data, used: make([]bool, len(data)/lockedKeySlotSize())}
+	page.used[0] = true
+	page.inUse = 1
+	a.pages = append(a.pages, page)
+	return page, 0, nil
+}
+
+// Wipes the slot of a secret key, releasing its page once none of its keys are left.
+func (a *lockedKeyArena) free(page *lockedKeyPage, slot int) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	v2keymanager.ZeroSecret(page.slot(slot))
+	page.used[slot] = false
+	page.inUse--
+	if page.inUse > 0 {
+		return
+	}
+	for i, p := range a.pages {
+		if p == page {
+			a.pages = append(a.pages[:i], a.pages[i+1:]...)
+			break
+		}
+	}
+	if err := freeLockedMemory(page.mem); err != nil {
+		log.WithError(err).Error("Could not release locked memory of secret keys")
+	}
+}
+
+func (p *lockedKeyPage) slot(slot int) []byte {
+	size := lockedKeySlotSize()
+	return p.data[slot*size : (slot+1)*size]
+}
+
+// lockedSecretKey is a BLS secret key kept in memory locked against being swapped to disk, in a
+// page shared with other keys between two inaccessible guard pages. The key is deserialized into
+// locked memory once, and signs in place without being copied out of it. It is preceded by a
+// canary checked each time the key is used.
+type lockedSecretKey struct {
+	arena        *lockedKeyArena
+	page         *lockedKeyPage
+	slot         int
+	key          bls.SecretKey
+	lockedCanary []byte
+	canary       [lockedKeyCanarySize]byte
+	pubKey       bls.PublicKey
+}
+
+// Copies a secret key into locked memory of an arena, failing if memory cannot be locked such as
+// beyond the memlock limit of the process.
+func newLockedSecretKey(arena *lockedKeyArena, secretKey bls.SecretKey) (*lockedSecretKey, error) {
+	rawSecretKey := secretKey.Marshal()
+	defer v2keymanager.ZeroSecret(rawSecretKey)
+	k := &lockedSecretKey{
+		arena:  arena,
+		pubKey: secretKey.PublicKey(),
+	}
+	if _, err := rand.Read(k.canary[:]); err != nil {
+		return nil, errors.Wrap(err, "could not generate canary")
+	}
+	page, slot, err := arena.alloc()
+	if err != nil {
+		return nil, err
+	}
+	mem := page.slot(slot)
+	key, err := bls.SecretKeyFromBytesInto(mem[lockedKeyCanarySize:], rawSecretKey)
+	if err != nil {
+		arena.free(page, slot)
+		return nil, err
+	}
+	k.page = page
+	k.slot = slot
+	k.key = key
+	k.lockedCanary = mem[:lockedKeyCanarySize]
+	copy(k.lockedCanary, k.canary[:])
+	return k, nil
+}
+
+// PublicKey of the secret key.
+func (k *lockedSecretKey) PublicKey() bls.PublicKey {
+	return k.pubKey
+}
+
+// Sign a message with the secret key, in place in locked memory.
+func (k *lockedSecretKey) Sign(msg []byte) bls.Signature {
+	k.checkCanary()
+	return k.key.Sign(msg)
+}
+
+// Marshal returns a copy of the secret key, which the caller must wipe once done with it.
+func (k *lockedSecretKey) Marshal() []byte {
+	k.checkCanary()
+	return k.key.Marshal()
+}
+
+// Panics if the canary before the secret key was overwritten, as the memory of the key can no
+// longer be trusted.
+func (k *lockedSecretKey) checkCanary() {
+	if k.key == nil {
+		panic("locked secret key is used after being destroyed")
+	}
+	if subtle.ConstantTimeCompare(k.lockedCanary, k.canary[:]) != 1 {
+		panic("memory of a locked secret key is corrupted")
+	}
+}
+
+// Wipes the secret key and releases its slot of locked memory, after which it must not be used.
+func (k *lockedSecretKey) destroy() {
+	if k.key == nil {
+		return
+	}
+	k.arena.free(k.page, k.slot)
+	k.page = nil
+	k.key = nil
+	k.lockedCanary = nil
+}
+
+// UseLockedMemory moves the secret keys of the keys cache, and those cached from then on, into
+// memory locked against being swapped to disk. Keys share pages of locked memory, so the default
+// memlock limit of 64KB of most systems holds over a thousand keys, and the limit is checked for
+// the keys of the cache before any is locked. It fails if memory cannot be locked, unless
+// allowUnlocked acknowledges keeping the keys in ordinary memory instead, with a warning.
+func (dr *Keymanager) UseLockedMemory(allowUnlocked bool) error {
+	dr.lock.Lock()
+	defer dr.lock.Unlock()
+	if dr.lockedMemory {
+		return nil
+	}
+	arena := &lockedKeyArena{}
+	lockedKeys := make(map[[48]byte]*lockedSecretKey, len(dr.keysCache))
+	err := checkMemoryLockable()
+	if err == nil {
+		err = checkMemlockLimit(len(dr.keysCache))
+	}
+	if err == nil {
+		for pubKey, secretKey := range dr.keysCache {
+			lockedKey, lockErr := newLockedSecretKey(arena, secretKey)
+			if lockErr != nil {
+				err = lockErr
+				break
+			}
+			lockedKeys[pubKey] = lockedKey
+		}
+	}
+	if err != nil {
+		for _, lockedKey := range lockedKeys {
+			lockedKey.destroy()
+		}
+		if !allowUnlocked {
+			return errors.Wrap(err, "could not lock secret keys into memory")
+		}
+		log.WithError(err).Warn("Could not lock secret keys into memory, they are kept in ordinary memory " +
+			"and may be swapped to disk")
+		return nil
+	}
+	for pubKey, lockedKey := range lockedKeys {
+		dr.keysCache[pubKey] = lockedKey
+	}
+	dr.lockedKeys = arena
+	dr.lockedMemory = true
+	return nil
+}
+
+// Caches a secret key for signing, in locked memory if the keymanager uses it. The caller must
+// hold the lock of the keymanager.
+func (dr *Keymanager) cacheSecretKey(secretKey bls.SecretKey) error {
+	if dr.keysCache == nil {
+		dr.keysCache = make(map[[48]byte]bls.SecretKey)
+	}
+	pubKey := bytesutil.ToBytes48(secretKey.PublicKey().Marshal())
+	if dr.lockedMemory {
+		lockedKey, err := newLockedSecretKey(dr.lockedKeys, secretKey)
+		if err != nil {
+			return errors.Wrap(err, "could not lock secret key into memory")
+		}
+		secretKey = lockedKey
+	}
+	dr.evictSecretKey(pubKey)
+	dr.keysCache[pubKey] = secretKey
+	return nil
+}
+
+// Removes a secret key from the keys cache, wiping it if it is held in locked memory. The caller
+// must hold the lock of the keymanager.
+func (dr *Keymanager) evictSecretKey(pubKey [48]byte) {
+	if lockedKey, ok := dr.keysCache[pubKey].(*lockedSecretKey); ok {
+		lockedKey.destroy()
+	}
+	delete(dr.keysCache, pubKey)
+}
+
+// Checks memory can be locked at all, so that failing to lock it is noticed on startup even
+// before any key is cached.
+func checkMemoryLockable() error {
+	mem, _, err := allocLockedMemory(1)
+	if err != nil {
+		return err
+	}
+	return freeLockedMemory(mem)
+}
+
+// Checks the memlock limit of the process is enough to lock a number of secret keys, failing with
+// the memory they need otherwise. Memory locked by anything else in the process counts against the
+// limit too, so locking them can still fail within it.
+func checkMemlockLimit(numKeys int) error {
+	limit, err := memlockLimit()
+	if err != nil {
+		return err
+	}
+	if needed := lockedMemoryForKeys(numKeys); needed > limit {
+		return fmt.Errorf(
+			"locking %d secret keys needs %d bytes of locked memory, above the memlock limit of the process of "+
+				"%d bytes, raise it with ulimit -l",
+			numKeys, needed, limit,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package direct
+
+import (
+	"context"
+	"testing"
+
+	validatorpb "github.com/prysmaticlabs/prysm/proto/validator/accounts/v2"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	mock "github.com/prysmaticlabs/prysm/validator/accounts/v2/testing"
+)
+
+func TestLockedSecretKey(t *testing.T) {
+	if err := checkMemoryLockable(); err != nil {
+		t.Skipf("Memory cannot be locked: %v", err)
+	}
+	arena := &lockedKeyArena{}
+	secretKey := bls.RandKey()
+	lockedKey, err := newLockedSecretKey(arena, secretKey)
+	require.NoError(t, err)
+	assert.DeepEqual(t, secretKey.Marshal(), lockedKey.Marshal())
+	assert.DeepEqual(t, secretKey.PublicKey().Marshal(), lockedKey.PublicKey().Marshal())
+	msg := []byte("hello world")
+	assert.DeepEqual(t, secretKey.Sign(msg).Marshal(), lockedKey.Sign(msg).Marshal())
+
+	// Writes running into the canary before the key are caught before it is used.
+	lockedKey.lockedCanary[lockedKeyCanarySize-1] ^= 0xff
+	func() {
+		defer func() {
+			assert.Equal(t, "memory of a locked secret key is corrupted", recover())
+		}()
+		lockedKey.Sign(msg)
+		t.Fatal("Expected signing with a corrupted locked secret key to panic")
+	}()
+	lockedKey.lockedCanary[lockedKeyCanarySize-1] ^= 0xff
+
+	// Keys share pages of locked memory, which are released once none of their keys are left.
+	otherKey, err := newLockedSecretKey(arena, bls.RandKey())
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(arena.pages))
+	assert.Equal(t, lockedKey.page, otherKey.page)
+	assert.Equal(t, 2, lockedKey.page.inUse)
+	otherKey.destroy()
+	assert.Equal(t, 1, len(arena.pages))
+
+	// Its memory is wiped and unmapped once destroyed, so it can no longer be used.
+	page := lockedKey.page
+	lockedKey.destroy()
+	lockedKey.destroy()
+	assert.Equal(t, 0, len(arena.pages))
+	assert.Equal(t, 0, page.inUse)
+	func() {
+		defer func() {
+			assert.Equal(t, "locked secret key is used after being destroyed", recover())
+		}()
+		lockedKey.Marshal()
+		t.Fatal("Expected using a destroyed locked secret key to panic")
+	}()
+}
+
+func TestDirectKeymanager_UseLockedMemory(t *testing.T) {
+	if err := checkMemoryLockable(); err != nil {
+		t.Skipf("Memory cannot be locked: %v", err)
+	}
+	wallet := &mock.Wallet{
+		Files:            make(map[string]map[string][]byte),
+		AccountPasswords: make(map[string]string),
+		WalletPassword:   "walletpassword",
+	}
+	dr := &Keymanager{
+		wallet:    wallet,
+		keysCache: make(map[[48]byte]bls.SecretKey),
+	}
+	accountNames, publicKeys := generateAccounts(t, 2, dr)
+	wallet.Directories = accountNames
+	ctx := context.Background()
+	require.NoError(t, dr.initializeSecretKeysCache(ctx))
+
+	require.NoError(t, dr.UseLockedMemory(false /* allowUnlocked */))
+	require.NoError(t, dr.UseLockedMemory(false /* allowUnlocked */))
+	assert.Equal(t, 2, len(dr.keysCache))
+	for _, secretKey := range dr.keysCache {
+		_, ok := secretKey.(*lockedSecretKey)
+		assert.Equal(t, true, ok, "Expected secret key to be held in locked memory")
+	}
+	sig, err := dr.Sign(ctx, &validatorpb.SignRequest{
+		PublicKey:   publicKeys[0][:],
+		SigningRoot: []byte("hello world"),
+	})
+	require.NoError(t, err)
+	pubKey, err := bls.PublicKeyFromBytes(publicKeys[0][:])
+	require.NoError(t, err)
+	assert.Equal(t, true, sig.Verify(pubKey, []byte("hello world")))
+
+	// Keys cached from then on are locked too, and evicted keys are destroyed.
+	secretKey := bls.RandKey()
+	accountName, err := dr.ImportSecretKey(ctx, secretKey, "secretPassw0rd$1999")
+	require.NoError(t, err)
+	lockedKey, ok := dr.keysCache[bytesutil.ToBytes48(secretKey.PublicKey().Marshal())].(*lockedSecretKey)
+	require.Equal(t, true, ok, "Expected imported secret key to be held in locked memory")
+	wallet.Directories = append(wallet.Directories, accountName)
+	require.NoError(t, dr.DeleteAccounts(ctx, [][48]byte{bytesutil.ToBytes48(secretKey.PublicKey().Marshal())}))
+	assert.Equal(t, true, lockedKey.key == nil, "Expected evicted secret key to be destroyed")
+	assert.Equal(t, 2, len(dr.keysCache))
+}
+
+func TestCheckMemlockLimit(t *testing.T) {
+	limit, err := memlockLimit()
+	if err != nil {
+		t.Skipf("Memlock limit is unknown: %v", err)
+	}
+	// A page of locked memory holds many keys.
+	assert.Equal(t, lockedMemoryForKeys(1), lockedMemoryForKeys(2))
+	assert.Equal(t, true, lockedMemoryForKeys(1) < lockedMemoryForKeys(1000))
+	if limit >= lockedMemoryForKeys(1<<40) {
+		t.Skip("Memlock limit is unlimited")
+	}
+	assert.ErrorContains(t, "above the memlock limit of the process", checkMemlockLimit(1<<40))
+}
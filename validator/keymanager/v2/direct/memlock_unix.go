@@ -0,0 +1,65 @@
+// +build !windows
+
+package direct
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/pkg/errors"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"golang.org/x/sys/unix"
+)
+
+// Maps locked memory of at least size bytes between two inaccessible guard pages, returning the
+// whole mapping and the usable memory between the guard pages.
+func allocLockedMemory(size int) ([]byte, []byte, error) {
+	pageSize := os.Getpagesize()
+	dataSize := (size + pageSize - 1) / pageSize * pageSize
+	mem, err := syscall.Mmap(
+		-1, 0, dataSize+2*pageSize, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_PRIVATE|syscall.MAP_ANON,
+	)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not map memory")
+	}
+	data := mem[pageSize : pageSize+dataSize]
+	if err := syscall.Mprotect(mem[:pageSize], syscall.PROT_NONE); err != nil {
+		return nil, nil, unmapAfter(mem, errors.Wrap(err, "could not protect guard page"))
+	}
+	if err := syscall.Mprotect(mem[pageSize+dataSize:], syscall.PROT_NONE); err != nil {
+		return nil, nil, unmapAfter(mem, errors.Wrap(err, "could not protect guard page"))
+	}
+	if err := syscall.Mlock(data); err != nil {
+		return nil, nil, unmapAfter(mem, errors.Wrap(
+			err, "could not lock memory, the memlock limit of the process (ulimit -l) may be too low",
+		))
+	}
+	return mem, data, nil
+}
+
+// Wipes and unlocks the usable memory of a mapping made by allocLockedMemory before unmapping it.
+func freeLockedMemory(mem []byte) error {
+	pageSize := os.Getpagesize()
+	data := mem[pageSize : len(mem)-pageSize]
+	v2keymanager.ZeroSecret(data)
+	if err := syscall.Munlock(data); err != nil {
+		return unmapAfter(mem, errors.Wrap(err, "could not unlock memory"))
+	}
+	return unmapAfter(mem, nil)
+}
+
+func unmapAfter(mem []byte, err error) error {
+	if unmapErr := syscall.Munmap(mem); unmapErr != nil && err == nil {
+		return errors.Wrap(unmapErr, "could not unmap memory")
+	}
+	return err
+}
+
+// Returns the memlock limit of the process, the bytes of memory it may lock.
+func memlockLimit() (uint64, error) {
+	var limit unix.Rlimit
+	if err := unix.Getrlimit(unix.RLIMIT_MEMLOCK, &limit); err != nil {
+		return 0, errors.Wrap(err, "could not get memlock limit")
+	}
+	return limit.Cur, nil
+}
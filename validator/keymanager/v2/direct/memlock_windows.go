@@ -0,0 +1,19 @@
+// +build windows
+
+package direct
+
+import (
+	"github.com/pkg/errors"
+)
+
+func allocLockedMemory(size int) ([]byte, []byte, error) {
+	return nil, nil, errors.New("locking memory is not supported on windows")
+}
+
+func freeLockedMemory(mem []byte) error {
+	return nil
+}
+
+func memlockLimit() (uint64, error) {
+	return 0, errors.New("locking memory is not supported on windows")
+}
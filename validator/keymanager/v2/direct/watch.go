@@ -0,0 +1,150 @@
+package direct
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// accountsWatchDebounce is how long file events have to settle, such as those of copying a
+// keystore and its password into a wallet, before new accounts are loaded.
+const accountsWatchDebounce = time.Second
+
+// WatchAccounts loads the accounts added to the wallet while the keymanager is running, such as
+// a keystore copied into a new account directory of the wallet along with its password file into
+// passwordsDir, until ctx is done. Accounts whose password file is not there yet are loaded once it
+// is. Only wallets kept on disk are watched, as other storage has no files to watch.
+func (dr *Keymanager) WatchAccounts(ctx context.Context, passwordsDir string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "could not create file watcher")
+	}
+	defer func() {
+		if err := watcher.Close(); err != nil {
+			log.WithError(err).Error("Could not close file watcher")
+		}
+	}()
+	accountsDir := dr.wallet.AccountsDir()
+	if err := watcher.Add(accountsDir); err != nil {
+		return errors.Wrapf(err, "could not watch accounts directory %s", accountsDir)
+	}
+	accountNames, err := dr.ValidatingAccountNames()
+	if err != nil {
+		return err
+	}
+	for _, name := range accountNames {
+		if err := watcher.Add(filepath.Join(accountsDir, name)); err != nil {
+			log.WithError(err).WithField("name", name).Error("Could not watch account directory")
+		}
+	}
+	if passwordsDir != "" {
+		if err := watcher.Add(passwordsDir); err != nil {
+			return errors.Wrapf(err, "could not watch passwords directory %s", passwordsDir)
+		}
+	}
+	log.WithField("path", accountsDir).Info("Watching wallet for new accounts")
+
+	var reload <-chan time.Time
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			// Keystores are written into account directories created after the watch started.
+			if event.Op&fsnotify.Create != 0 && filepath.Dir(event.Name) == accountsDir {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := watcher.Add(event.Name); err != nil {
+						log.WithError(err).WithField("path", event.Name).Error("Could not watch account directory")
+					}
+				}
+			}
+			reload = time.After(accountsWatchDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.WithError(err).Error("Could not watch wallet for new accounts")
+		case <-reload:
+			reload = nil
+			if _, err := dr.LoadNewAccounts(ctx); err != nil {
+				log.WithError(err).Error("Could not load new accounts")
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// LoadNewAccounts loads the accounts of the wallet whose keys are not in the keys cache yet,
+// returning how many were loaded. Accounts which cannot be loaded yet, such as those whose
+// keystore is still being written or whose password file is missing, are skipped.
+func (dr *Keymanager) LoadNewAccounts(ctx context.Context) (int, error) {
+	// Accounts added while the keymanager is locked are loaded along with the others once it is
+	// unlocked.
+	if dr.Locked() {
+		return 0, nil
+	}
+	accountNames, err := dr.ValidatingAccountNames()
+	if err != nil {
+		return 0, err
+	}
+	var loaded int
+	for _, name := range accountNames {
+		keystoreFile, err := dr.keystoreForAccount(name)
+		if err != nil {
+			log.WithError(err).WithField("name", name).Debug("Could not read keystore of account yet")
+			continue
+		}
+		pubKey, err := hex.DecodeString(keystoreFile.Pubkey)
+		if err != nil {
+			log.WithError(err).WithField("name", name).Error("Could not decode public key of account")
+			continue
+		}
+		dr.lock.RLock()
+		_, ok := dr.keysCache[bytesutil.ToBytes48(pubKey)]
+		dr.lock.RUnlock()
+		if ok {
+			continue
+		}
+		password, err := dr.wallet.ReadPasswordFromDisk(ctx, name+PasswordFileSuffix)
+		if err != nil {
+			log.WithField("name", name).Debug("Could not read password of new account yet")
+			continue
+		}
+		rawSigningKey, err := decryptSecret(keystoreFile.Crypto, password)
+		if err != nil {
+			log.WithError(err).WithField("name", name).Error("Could not decrypt signing key of new account")
+			continue
+		}
+		secretKey, err := bls.SecretKeyFromBytes(rawSigningKey)
+		v2keymanager.ZeroSecret(rawSigningKey)
+		if err != nil {
+			log.WithError(err).WithField("name", name).Error("Could not determine signing key of new account")
+			continue
+		}
+		dr.lock.Lock()
+		err = dr.cacheSecretKey(secretKey)
+		dr.lock.Unlock()
+		if err != nil {
+			log.WithError(err).WithField("name", name).Error("Could not cache signing key of new account")
+			continue
+		}
+		loaded++
+		log.WithFields(logrus.Fields{
+			"name":      name,
+			"publicKey": fmt.Sprintf("%#x", bytesutil.Trunc(secretKey.PublicKey().Marshal())),
+		}).Info("Loaded new validator account")
+	}
+	return loaded, nil
+}
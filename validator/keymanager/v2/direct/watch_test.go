@@ -0,0 +1,48 @@
+package direct
+
+import (
+	"context"
+	"testing"
+
+	validatorpb "github.com/prysmaticlabs/prysm/proto/validator/accounts/v2"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	mock "github.com/prysmaticlabs/prysm/validator/accounts/v2/testing"
+)
+
+func TestDirectKeymanager_LoadNewAccounts(t *testing.T) {
+	wallet := &mock.Wallet{
+		Files:            make(map[string]map[string][]byte),
+		AccountPasswords: make(map[string]string),
+	}
+	dr := &Keymanager{
+		wallet:    wallet,
+		keysCache: make(map[[48]byte]bls.SecretKey),
+	}
+	ctx := context.Background()
+	accountNames, publicKeys := generateAccounts(t, 2, dr)
+	wallet.Directories = accountNames
+	// The password of the second account has not been copied into the wallet yet.
+	password := wallet.AccountPasswords[accountNames[1]+PasswordFileSuffix]
+	delete(wallet.AccountPasswords, accountNames[1]+PasswordFileSuffix)
+
+	loaded, err := dr.LoadNewAccounts(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, loaded)
+	_, err = dr.Sign(ctx, &validatorpb.SignRequest{PublicKey: publicKeys[0][:], SigningRoot: []byte("root")})
+	require.NoError(t, err)
+	_, err = dr.Sign(ctx, &validatorpb.SignRequest{PublicKey: publicKeys[1][:], SigningRoot: []byte("root")})
+	assert.ErrorContains(t, "no signing key found", err)
+
+	wallet.AccountPasswords[accountNames[1]+PasswordFileSuffix] = password
+	loaded, err = dr.LoadNewAccounts(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, loaded)
+	_, err = dr.Sign(ctx, &validatorpb.SignRequest{PublicKey: publicKeys[1][:], SigningRoot: []byte("root")})
+	require.NoError(t, err)
+
+	loaded, err = dr.LoadNewAccounts(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, loaded)
+}
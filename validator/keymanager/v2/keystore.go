@@ -0,0 +1,44 @@
+package v2
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+)
+
+// NewKeystore encrypts a BLS secret key into a standalone EIP-2335 keystore
+// using the default keystorev4 parameters, so it can be imported by any
+// EIP-2335 compliant client. The path is the EIP-2334 derivation path of
+// the key, if any.
+func NewKeystore(secretKey bls.SecretKey, path string, password string) (*Keystore, error) {
+	encryptor := keystorev4.New()
+	rawSecretKey := secretKey.Marshal()
+	defer ZeroSecret(rawSecretKey)
+	cryptoFields, err := encryptor.Encrypt(rawSecretKey, password)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encrypt secret key into keystore")
+	}
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	return &Keystore{
+		Crypto:  cryptoFields,
+		ID:      id.String(),
+		Pubkey:  fmt.Sprintf("%x", secretKey.PublicKey().Marshal()),
+		Version: encryptor.Version(),
+		Name:    encryptor.Name(),
+		Path:    path,
+	}, nil
+}
+
+// ZeroSecret overwrites secret key material, such as the raw bytes decrypted from a keystore, with
+// zeroes once it is no longer used, so it does not linger on the heap until it is collected.
+func ZeroSecret(secret []byte) {
+	for i := range secret {
+		secret[i] = 0
+	}
+}
@@ -0,0 +1,24 @@
+package v2_test
+
+import (
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+)
+
+func TestZeroSecret(t *testing.T) {
+	secretKey := bls.RandKey()
+	keystore, err := v2keymanager.NewKeystore(secretKey, "", "Passw0rdz2020%")
+	require.NoError(t, err)
+	rawSecretKey, err := keystorev4.New().Decrypt(keystore.Crypto, "Passw0rdz2020%")
+	require.NoError(t, err)
+	require.DeepEqual(t, secretKey.Marshal(), rawSecretKey)
+
+	v2keymanager.ZeroSecret(rawSecretKey)
+	assert.DeepEqual(t, make([]byte, len(secretKey.Marshal())), rawSecretKey)
+	v2keymanager.ZeroSecret(nil)
+}
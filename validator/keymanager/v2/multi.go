@@ -0,0 +1,75 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+	validatorpb "github.com/prysmaticlabs/prysm/proto/validator/accounts/v2"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+)
+
+// MultiKeymanager merges the keys of several keymanagers, such as the keymanagers of
+// several wallets of different kinds loaded by a single validator client, and signs
+// with the keymanager holding the requested key.
+type MultiKeymanager struct {
+	keymanagers []IKeymanager
+	owners      map[[48]byte]IKeymanager
+	lock        sync.RWMutex
+}
+
+// NewMultiKeymanager merges the keys of the given keymanagers.
+func NewMultiKeymanager(keymanagers ...IKeymanager) *MultiKeymanager {
+	return &MultiKeymanager{
+		keymanagers: keymanagers,
+		owners:      make(map[[48]byte]IKeymanager),
+	}
+}
+
+// FetchValidatingPublicKeys returns the validating public keys of every keymanager, in
+// the order of the keymanagers. A key held by more than one keymanager is an error, as
+// it would be impossible to tell which keymanager should sign with it.
+func (m *MultiKeymanager) FetchValidatingPublicKeys(ctx context.Context) ([][48]byte, error) {
+	owners := make(map[[48]byte]IKeymanager)
+	var pubKeys [][48]byte
+	for i, km := range m.keymanagers {
+		keys, err := km.FetchValidatingPublicKeys(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not fetch validating public keys of keymanager %d", i)
+		}
+		for _, key := range keys {
+			if _, ok := owners[key]; ok {
+				return nil, fmt.Errorf("validating public key %#x is held by several wallets", bytesutil.Trunc(key[:]))
+			}
+			owners[key] = km
+			pubKeys = append(pubKeys, key)
+		}
+	}
+	m.lock.Lock()
+	m.owners = owners
+	m.lock.Unlock()
+	return pubKeys, nil
+}
+
+// Sign signs a message with the keymanager holding the public key of the request. Keys
+// added to a keymanager since the keys were last fetched are found by fetching them again.
+func (m *MultiKeymanager) Sign(ctx context.Context, req *validatorpb.SignRequest) (bls.Signature, error) {
+	pubKey := bytesutil.ToBytes48(req.PublicKey)
+	m.lock.RLock()
+	km, ok := m.owners[pubKey]
+	m.lock.RUnlock()
+	if !ok {
+		if _, err := m.FetchValidatingPublicKeys(ctx); err != nil {
+			return nil, err
+		}
+		m.lock.RLock()
+		km, ok = m.owners[pubKey]
+		m.lock.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("no wallet holds the public key %#x", bytesutil.Trunc(req.PublicKey))
+		}
+	}
+	return km.Sign(ctx, req)
+}
@@ -0,0 +1,69 @@
+package v2_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	validatorpb "github.com/prysmaticlabs/prysm/proto/validator/accounts/v2"
+	"github.com/prysmaticlabs/prysm/shared/bls"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+)
+
+// keysKeymanager is a keymanager holding its secret keys in memory.
+type keysKeymanager struct {
+	keys []bls.SecretKey
+}
+
+func (km *keysKeymanager) FetchValidatingPublicKeys(_ context.Context) ([][48]byte, error) {
+	pubKeys := make([][48]byte, len(km.keys))
+	for i, key := range km.keys {
+		pubKeys[i] = bytesutil.ToBytes48(key.PublicKey().Marshal())
+	}
+	return pubKeys, nil
+}
+
+func (km *keysKeymanager) Sign(_ context.Context, req *validatorpb.SignRequest) (bls.Signature, error) {
+	for _, key := range km.keys {
+		if bytesutil.ToBytes48(key.PublicKey().Marshal()) == bytesutil.ToBytes48(req.PublicKey) {
+			return key.Sign(req.SigningRoot), nil
+		}
+	}
+	return nil, errors.New("no such key")
+}
+
+func TestMultiKeymanager(t *testing.T) {
+	ctx := context.Background()
+	first := &keysKeymanager{keys: []bls.SecretKey{bls.RandKey(), bls.RandKey()}}
+	second := &keysKeymanager{keys: []bls.SecretKey{bls.RandKey()}}
+	km := v2keymanager.NewMultiKeymanager(first, second)
+
+	pubKeys, err := km.FetchValidatingPublicKeys(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(pubKeys))
+	assert.Equal(t, bytesutil.ToBytes48(second.keys[0].PublicKey().Marshal()), pubKeys[2])
+
+	root := []byte("root")
+	for i, key := range append(first.keys, second.keys...) {
+		sig, err := km.Sign(ctx, &validatorpb.SignRequest{PublicKey: pubKeys[i][:], SigningRoot: root})
+		require.NoError(t, err)
+		assert.DeepEqual(t, key.Sign(root).Marshal(), sig.Marshal())
+	}
+
+	// Keys added to a keymanager are found when signing with them.
+	second.keys = append(second.keys, bls.RandKey())
+	sig, err := km.Sign(ctx, &validatorpb.SignRequest{PublicKey: second.keys[1].PublicKey().Marshal(), SigningRoot: root})
+	require.NoError(t, err)
+	assert.DeepEqual(t, second.keys[1].Sign(root).Marshal(), sig.Marshal())
+
+	_, err = km.Sign(ctx, &validatorpb.SignRequest{PublicKey: bls.RandKey().PublicKey().Marshal(), SigningRoot: root})
+	assert.ErrorContains(t, "no wallet holds the public key", err)
+
+	// A key held by several keymanagers cannot be signed with unambiguously.
+	second.keys = append(second.keys, first.keys[0])
+	_, err = km.FetchValidatingPublicKeys(ctx)
+	assert.ErrorContains(t, "held by several wallets", err)
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"io/ioutil"
 	"strings"
+	"sync"
 
 	ptypes "github.com/gogo/protobuf/types"
 	"github.com/logrusorgru/aurora"
@@ -16,6 +17,7 @@ import (
 	validatorpb "github.com/prysmaticlabs/prysm/proto/validator/accounts/v2"
 	"github.com/prysmaticlabs/prysm/shared/bls"
 	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	v2keymanager "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -51,6 +53,10 @@ type Keymanager struct {
 	cfg              *Config
 	client           validatorpb.RemoteSignerClient
 	accountsByPubkey map[[48]byte]string
+	// unregisteredKeys are the keys of the signer not registered as accounts which were warned
+	// about, so each is only warned about once.
+	unregisteredKeys map[[48]byte]bool
+	lock             sync.Mutex
 }
 
 // NewKeymanager instantiates a new direct keymanager from configuration options.
@@ -108,8 +114,9 @@ func NewKeymanager(ctx context.Context, maxMessageSize int, cfg *Config) (*Keyma
 }
 
 // UnmarshalConfigFile attempts to JSON unmarshal a keymanager
-// configuration file into the *Config{} struct.
-func UnmarshalConfigFile(r io.ReadCloser) (*Config, error) {
+// configuration file into the *Config{} struct. If the file was encrypted
+// with the wallet password, it is transparently decrypted first.
+func UnmarshalConfigFile(r io.ReadCloser, password string) (*Config, error) {
 	enc, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not read config")
@@ -119,6 +126,10 @@ func UnmarshalConfigFile(r io.ReadCloser) (*Config, error) {
 			log.Errorf("Could not close keymanager config file: %v", err)
 		}
 	}()
+	enc, err = v2keymanager.DecryptConfig(enc, password)
+	if err != nil {
+		return nil, err
+	}
 	cfg := &Config{}
 	if err := json.Unmarshal(enc, cfg); err != nil {
 		return nil, errors.Wrap(err, "could not JSON unmarshal")
@@ -169,8 +180,49 @@ func (k *Keymanager) Config() *Config {
 	return k.cfg
 }
 
-// FetchValidatingPublicKeys fetches the list of public keys that should be used to validate with.
+// SetAccounts registers the accounts of the wallet by validating public key, as synced with the
+// keys of its remote signer. Once accounts are registered, only their keys are validated with, so
+// keys added to the signer are only used once they are synced into the wallet.
+func (k *Keymanager) SetAccounts(accounts map[[48]byte]string) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.accountsByPubkey = accounts
+}
+
+// FetchValidatingPublicKeys fetches the list of public keys that should be used to validate with,
+// the keys of the remote signer registered as accounts of the wallet if any are registered.
 func (k *Keymanager) FetchValidatingPublicKeys(ctx context.Context) ([][48]byte, error) {
+	pubKeys, err := k.FetchSignerPublicKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	if len(k.accountsByPubkey) == 0 {
+		return pubKeys, nil
+	}
+	registered := make([][48]byte, 0, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		if _, ok := k.accountsByPubkey[pubKey]; ok {
+			registered = append(registered, pubKey)
+			continue
+		}
+		if k.unregisteredKeys == nil {
+			k.unregisteredKeys = make(map[[48]byte]bool)
+		}
+		if !k.unregisteredKeys[pubKey] {
+			k.unregisteredKeys[pubKey] = true
+			log.WithField("publicKey", fmt.Sprintf("%#x", bytesutil.Trunc(pubKey[:]))).Warn(
+				"Remote signer holds a key which is not an account of the wallet, run accounts-v2 sync-remote to validate with it",
+			)
+		}
+	}
+	return registered, nil
+}
+
+// FetchSignerPublicKeys fetches the list of public keys the remote signer holds, whether or not
+// they are registered as accounts of the wallet.
+func (k *Keymanager) FetchSignerPublicKeys(ctx context.Context) ([][48]byte, error) {
 	resp, err := k.client.ListValidatingPublicKeys(ctx, &ptypes.Empty{})
 	if err != nil {
 		return nil, errors.Wrap(err, "could not list accounts from remote server")
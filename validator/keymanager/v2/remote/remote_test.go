@@ -311,3 +311,32 @@ func TestRemoteKeymanager_FetchValidatingPublicKeys(t *testing.T) {
 		t.Errorf("Wanted %v, received %v", pubKeys, rawKeys)
 	}
 }
+
+func TestRemoteKeymanager_FetchValidatingPublicKeys_RegisteredAccounts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	m := mock.NewMockRemoteSignerClient(ctrl)
+	k := &Keymanager{
+		client: m,
+	}
+	pubKeys := make([][]byte, 3)
+	for i := range pubKeys {
+		pubKeys[i] = bls.RandKey().PublicKey().Marshal()
+	}
+	m.EXPECT().ListValidatingPublicKeys(
+		gomock.Any(), // ctx
+		gomock.Any(), // epoch
+	).Return(&validatorpb.ListPublicKeysResponse{
+		ValidatingPublicKeys: pubKeys,
+	}, nil /*err*/).Times(2)
+
+	// Only the keys registered as accounts are validated with, every key of the signer is listed.
+	var registered [48]byte
+	copy(registered[:], pubKeys[1])
+	k.SetAccounts(map[[48]byte]string{registered: "registered-account"})
+	keys, err := k.FetchValidatingPublicKeys(context.Background())
+	require.NoError(t, err)
+	require.DeepEqual(t, [][48]byte{registered}, keys)
+	keys, err = k.FetchSignerPublicKeys(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, len(pubKeys), len(keys))
+}
@@ -11,4 +11,6 @@ var (
 	_ = v2keymanager.IKeymanager(&direct.Keymanager{})
 	_ = v2keymanager.IKeymanager(&derived.Keymanager{})
 	_ = v2keymanager.IKeymanager(&remote.Keymanager{})
+	_ = v2keymanager.IKeymanager(&v2keymanager.MultiKeymanager{})
+	_ = v2keymanager.IKeymanager(&v2keymanager.DeactivatingKeymanager{})
 )
@@ -73,7 +73,23 @@ var appFlags = []cli.Flag{
 	flags.SlasherCertFlag,
 	flags.WalletPasswordsDirFlag,
 	flags.WalletPasswordFileFlag,
+	flags.PasswordStdinFlag,
 	flags.WalletDirFlag,
+	flags.AdditionalWalletDirsFlag,
+	flags.WatchAccountsFlag,
+	flags.KeymanagerIdleTimeoutFlag,
+	flags.AllowUnlockedKeyMemoryFlag,
+	flags.WalletSecretsDirFlag,
+	flags.WalletSecretsFromEnvFlag,
+	flags.WalletSecretsKubernetesSelectorFlag,
+	flags.WalletSecretsKubernetesNamespaceFlag,
+	flags.WalletBundleFlag,
+	flags.WalletBundleExtractDirFlag,
+	flags.WalletStorageRetriesFlag,
+	flags.WalletStorageRetryDelayFlag,
+	flags.WalletReadOnlyFlag,
+	flags.WalletFixPermissionsFlag,
+	flags.WalletManifestKeyFileFlag,
 	cmd.MinimalConfigFlag,
 	cmd.E2EConfigFlag,
 	cmd.VerbosityFlag,
@@ -21,6 +21,7 @@ import (
 	"github.com/prysmaticlabs/prysm/shared/featureconfig"
 	"github.com/prysmaticlabs/prysm/shared/params"
 	"github.com/prysmaticlabs/prysm/shared/prometheus"
+	"github.com/prysmaticlabs/prysm/shared/promptutil"
 	"github.com/prysmaticlabs/prysm/shared/tracing"
 	"github.com/prysmaticlabs/prysm/shared/version"
 	accountsv2 "github.com/prysmaticlabs/prysm/validator/accounts/v2"
@@ -29,6 +30,7 @@ import (
 	"github.com/prysmaticlabs/prysm/validator/flags"
 	v1 "github.com/prysmaticlabs/prysm/validator/keymanager/v1"
 	v2 "github.com/prysmaticlabs/prysm/validator/keymanager/v2"
+	"github.com/prysmaticlabs/prysm/validator/keymanager/v2/direct"
 	slashing_protection "github.com/prysmaticlabs/prysm/validator/slashing-protection"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
@@ -43,6 +45,13 @@ type ValidatorClient struct {
 	services *shared.ServiceRegistry // Lifecycle and service store.
 	lock     sync.RWMutex
 	stop     chan struct{} // Channel to wait for termination notifications.
+	// walletLocks are the locks held on the wallets validated with, so no other
+	// validator client on the host validates with them at the same time.
+	walletLocks []*accountsv2.WalletLock
+	// stopWatchingAccounts stops watching the wallet for new accounts, if it is watched.
+	stopWatchingAccounts context.CancelFunc
+	// stopAutoLock stops locking the keymanager after being idle, if it is auto-locked.
+	stopAutoLock context.CancelFunc
 }
 
 // NewValidatorClient creates a new, Prysm validator client.
@@ -82,17 +91,134 @@ func NewValidatorClient(cliCtx *cli.Context) (*ValidatorClient, error) {
 	var keyManagerV1 v1.KeyManager
 	var keyManagerV2 v2.IKeymanager
 	if featureconfig.Get().EnableAccountsV2 {
-		// Read the wallet from the specified path.
-		wallet, err := accountsv2.OpenWallet(cliCtx)
-		if err != nil {
-			log.Fatalf("Could not open wallet: %v", err)
+		var wallet *accountsv2.Wallet
+		var err error
+		fromSecrets := cliCtx.IsSet(flags.WalletSecretsDirFlag.Name) ||
+			cliCtx.Bool(flags.WalletSecretsFromEnvFlag.Name) ||
+			cliCtx.IsSet(flags.WalletSecretsKubernetesSelectorFlag.Name)
+		if fromSecrets {
+			// Load a wallet kept in memory only from the mounted secrets, environment or Kubernetes Secrets.
+			secretsCfg := &accountsv2.SecretsWalletConfig{
+				Dir:                 cliCtx.String(flags.WalletSecretsDirFlag.Name),
+				KubernetesSelector:  cliCtx.String(flags.WalletSecretsKubernetesSelectorFlag.Name),
+				KubernetesNamespace: cliCtx.String(flags.WalletSecretsKubernetesNamespaceFlag.Name),
+			}
+			if cliCtx.Bool(flags.WalletSecretsFromEnvFlag.Name) {
+				secretsCfg.Environ = os.Environ()
+			}
+			wallet, err = accountsv2.OpenSecretsWallet(context.Background(), secretsCfg)
+			if err != nil {
+				log.Fatalf("Could not load wallet from secrets: %v", err)
+			}
+		} else if cliCtx.IsSet(flags.WalletBundleFlag.Name) {
+			// Open the wallet of a single-file bundle, kept in memory read-only unless it is extracted.
+			bundleCfg := &accountsv2.BundleWalletConfig{
+				Path:         cliCtx.String(flags.WalletBundleFlag.Name),
+				ExtractDir:   cliCtx.String(flags.WalletBundleExtractDirFlag.Name),
+				PasswordFile: cliCtx.String(flags.WalletPasswordFileFlag.Name),
+			}
+			wallet, err = accountsv2.OpenBundleWallet(context.Background(), bundleCfg)
+			if err != nil {
+				log.Fatalf("Could not open wallet bundle: %v", err)
+			}
+			if bundleCfg.ExtractDir != "" {
+				if err := ValidatorClient.lockWallet(wallet); err != nil {
+					log.Fatalf("Could not lock wallet: %v", err)
+				}
+			}
+		} else {
+			// Read the wallet from the specified path.
+			wallet, err = accountsv2.OpenWallet(cliCtx)
+			if err != nil {
+				log.Fatalf("Could not open wallet: %v", err)
+			}
+			if err := wallet.CheckPermissions(cliCtx.Bool(flags.WalletFixPermissionsFlag.Name)); err != nil {
+				log.Fatalf("Could not check wallet permissions: %v", err)
+			}
+			if err := wallet.VerifyManifest(context.Background()); err != nil {
+				log.Fatalf("Could not verify wallet manifest: %v", err)
+			}
+			if err := ValidatorClient.lockWallet(wallet); err != nil {
+				log.Fatalf("Could not lock wallet: %v", err)
+			}
 		}
+		wallet.SetStorageRetries(
+			cliCtx.Uint(flags.WalletStorageRetriesFlag.Name),
+			cliCtx.Duration(flags.WalletStorageRetryDelayFlag.Name),
+		)
 		keyManagerV2, err = wallet.InitializeKeymanager(
 			context.Background(), false, /* skipMnemonicConfirm */
 		)
 		if err != nil {
 			log.Fatalf("Could not read existing keymanager for wallet: %v", err)
 		}
+		if err := useLockedMemory(cliCtx, keyManagerV2); err != nil {
+			log.Fatalf("Could not lock validating keys into memory: %v", err)
+		}
+		if cliCtx.IsSet(flags.WalletSecretsKubernetesSelectorFlag.Name) {
+			directKeymanager, ok := keyManagerV2.(*direct.Keymanager)
+			if !ok {
+				log.Fatal("Could not assert keymanager of wallet loaded from secrets to a non-HD keymanager")
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			ValidatorClient.stopWatchingAccounts = cancel
+			go func() {
+				err := wallet.WatchKubernetesSecrets(ctx, func(ctx context.Context) {
+					if _, err := directKeymanager.LoadNewAccounts(ctx); err != nil {
+						log.WithError(err).Error("Could not load new accounts")
+					}
+				})
+				if err != nil {
+					log.WithError(err).Error("Could not watch Kubernetes secrets for new accounts")
+				}
+			}()
+		}
+		if cliCtx.Bool(flags.WatchAccountsFlag.Name) {
+			if fromSecrets {
+				log.Fatal("Wallets loaded from secrets cannot be watched for new accounts")
+			}
+			directKeymanager, ok := keyManagerV2.(*direct.Keymanager)
+			if !ok {
+				log.Fatalf("Only non-HD wallets can be watched for new accounts, not %s wallets", wallet.KeymanagerKind())
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			ValidatorClient.stopWatchingAccounts = cancel
+			go func() {
+				if err := directKeymanager.WatchAccounts(ctx, wallet.PasswordsDir()); err != nil {
+					log.WithError(err).Error("Could not watch wallet for new accounts")
+				}
+			}()
+		}
+		if idleTimeout := cliCtx.Duration(flags.KeymanagerIdleTimeoutFlag.Name); idleTimeout > 0 {
+			directKeymanager, ok := keyManagerV2.(*direct.Keymanager)
+			if !ok {
+				log.Fatalf("Only non-HD keymanagers can be locked after being idle, not %s keymanagers", wallet.KeymanagerKind())
+			}
+			if wallet.Password() == "" {
+				log.Fatal("Only keymanagers of wallets with a wallet password can be locked after being idle")
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			ValidatorClient.stopAutoLock = cancel
+			go func() {
+				if err := directKeymanager.AutoLock(ctx, idleTimeout, promptUnlockKeymanager(directKeymanager)); err != nil {
+					log.WithError(err).Error("Could not lock keymanager after being idle")
+				}
+			}()
+		}
+		schedule, err := wallet.DeactivationSchedule()
+		if err != nil {
+			log.Fatalf("Could not read account deactivations of wallet: %v", err)
+		}
+		if cliCtx.IsSet(flags.AdditionalWalletDirsFlag.Name) {
+			keyManagerV2, err = ValidatorClient.loadAdditionalWallets(keyManagerV2, schedule)
+			if err != nil {
+				log.Fatalf("Could not load additional wallets: %v", err)
+			}
+		}
+		if len(schedule) > 0 {
+			log.WithField("accounts", len(schedule)).Info("Accounts are scheduled for deactivation")
+			keyManagerV2 = v2.NewDeactivatingKeymanager(keyManagerV2, schedule)
+		}
 	} else {
 		keyManagerV1, err = selectV1Keymanager(cliCtx)
 		if err != nil {
@@ -188,6 +314,17 @@ func (s *ValidatorClient) Close() {
 	defer s.lock.Unlock()
 
 	s.services.StopAll()
+	if s.stopWatchingAccounts != nil {
+		s.stopWatchingAccounts()
+	}
+	if s.stopAutoLock != nil {
+		s.stopAutoLock()
+	}
+	for _, walletLock := range s.walletLocks {
+		if err := walletLock.Unlock(); err != nil {
+			log.WithError(err).Error("Could not unlock wallet")
+		}
+	}
 	log.Info("Stopping Prysm validator")
 
 	close(s.stop)
@@ -308,23 +445,7 @@ func selectV1Keymanager(ctx *cli.Context) (v1.KeyManager, error) {
 		}
 	}
 
-	var km v1.KeyManager
-	var help string
-	var err error
-	switch manager {
-	case "interop":
-		km, help, err = v1.NewInterop(opts)
-	case "unencrypted":
-		km, help, err = v1.NewUnencrypted(opts)
-	case "keystore":
-		km, help, err = v1.NewKeystore(opts)
-	case "wallet":
-		km, help, err = v1.NewWallet(opts)
-	case "remote":
-		km, help, err = v1.NewRemoteWallet(opts)
-	default:
-		return nil, fmt.Errorf("unknown keymanager %q", manager)
-	}
+	km, help, err := v1.NewKeyManager(manager, opts)
 	if err != nil {
 		if help != "" {
 			// Print help for the keymanager
@@ -364,6 +485,105 @@ func clearDB(dataDir string, pubkeys [][48]byte, force bool) error {
 	return nil
 }
 
+// Opens the --additional-wallet-dirs and merges their keymanagers with the keymanager of
+// the --wallet-dir, so the keys of wallets of different kinds can be validated with at once.
+// The scheduled account deactivations of the wallets are added to the schedule.
+func (s *ValidatorClient) loadAdditionalWallets(
+	keyManagerV2 v2.IKeymanager,
+	schedule map[[48]byte]*v2.Deactivation,
+) (v2.IKeymanager, error) {
+	keymanagers := []v2.IKeymanager{keyManagerV2}
+	for _, walletSpec := range s.cliCtx.StringSlice(flags.AdditionalWalletDirsFlag.Name) {
+		walletDir, passwordFile := walletSpec, ""
+		if i := strings.Index(walletSpec, "="); i >= 0 {
+			walletDir, passwordFile = walletSpec[:i], walletSpec[i+1:]
+		}
+		wallet, err := accountsv2.OpenWalletAtPath(walletDir, passwordFile, s.cliCtx.Bool(flags.WalletReadOnlyFlag.Name))
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not open wallet %s", walletDir)
+		}
+		if err := wallet.CheckPermissions(s.cliCtx.Bool(flags.WalletFixPermissionsFlag.Name)); err != nil {
+			return nil, errors.Wrapf(err, "could not check permissions of wallet %s", walletDir)
+		}
+		if s.cliCtx.IsSet(flags.WalletManifestKeyFileFlag.Name) {
+			if err := wallet.SetManifestKeyFile(s.cliCtx.String(flags.WalletManifestKeyFileFlag.Name)); err != nil {
+				return nil, errors.Wrapf(err, "could not read manifest key of wallet %s", walletDir)
+			}
+		}
+		if err := wallet.VerifyManifest(context.Background()); err != nil {
+			return nil, errors.Wrapf(err, "could not verify manifest of wallet %s", walletDir)
+		}
+		if err := s.lockWallet(wallet); err != nil {
+			return nil, errors.Wrapf(err, "could not lock wallet %s", walletDir)
+		}
+		wallet.SetStorageRetries(
+			s.cliCtx.Uint(flags.WalletStorageRetriesFlag.Name),
+			s.cliCtx.Duration(flags.WalletStorageRetryDelayFlag.Name),
+		)
+		km, err := wallet.InitializeKeymanager(context.Background(), false /* skipMnemonicConfirm */)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read existing keymanager for wallet %s", walletDir)
+		}
+		if err := useLockedMemory(s.cliCtx, km); err != nil {
+			return nil, errors.Wrapf(err, "could not lock validating keys of wallet %s into memory", walletDir)
+		}
+		keymanagers = append(keymanagers, km)
+		walletSchedule, err := wallet.DeactivationSchedule()
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read account deactivations of wallet %s", walletDir)
+		}
+		for pubKey, deactivation := range walletSchedule {
+			schedule[pubKey] = deactivation
+		}
+	}
+	return v2.NewMultiKeymanager(keymanagers...), nil
+}
+
+// Holds the secret keys of a non-HD keymanager in memory locked against being swapped to disk,
+// refusing to run with them in ordinary memory unless acknowledged with a flag.
+func useLockedMemory(cliCtx *cli.Context, keymanager v2.IKeymanager) error {
+	directKeymanager, ok := keymanager.(*direct.Keymanager)
+	if !ok {
+		return nil
+	}
+	err := directKeymanager.UseLockedMemory(cliCtx.Bool(flags.AllowUnlockedKeyMemoryFlag.Name))
+	if err != nil {
+		return errors.Wrapf(err, "pass --%s to run with them in ordinary memory", flags.AllowUnlockedKeyMemoryFlag.Name)
+	}
+	return nil
+}
+
+// Locks a wallet for as long as the validator client runs, refusing to validate with a
+// wallet another validator client is validating with already.
+func (s *ValidatorClient) lockWallet(wallet *accountsv2.Wallet) error {
+	walletLock, err := wallet.Lock()
+	if err != nil {
+		return err
+	}
+	s.walletLocks = append(s.walletLocks, walletLock)
+	return nil
+}
+
+// Returns the function prompting for the wallet password until it unlocks a keymanager locked
+// after being idle.
+func promptUnlockKeymanager(keymanager *direct.Keymanager) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		for ctx.Err() == nil && keymanager.Locked() {
+			walletPassword, err := promptutil.PasswordPrompt(
+				"Keymanager locked after being idle, enter the wallet password to resume signing",
+				promptutil.NotEmpty,
+			)
+			if err != nil {
+				log.WithError(err).Error("Could not read wallet password")
+				return
+			}
+			if err := keymanager.UnlockKeys(ctx, walletPassword); err != nil {
+				log.WithError(err).Error("Could not unlock keymanager")
+			}
+		}
+	}
+}
+
 // ExtractPublicKeysFromKeymanager extracts only the public keys from the specified key manager.
 func ExtractPublicKeysFromKeymanager(cliCtx *cli.Context, keyManagerV1 v1.KeyManager, keyManagerV2 v2.IKeymanager) ([][48]byte, error) {
 	var pubKeys [][48]byte
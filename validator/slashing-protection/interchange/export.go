@@ -0,0 +1,101 @@
+package interchange
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	slashpb "github.com/prysmaticlabs/prysm/proto/slashing"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/validator/db"
+)
+
+// ExportHistory builds an interchange document holding the slashing protection history stored
+// in the validator database for the given public keys. Signing roots are not kept by the
+// database, so they are left out of the document.
+func ExportHistory(
+	ctx context.Context,
+	validatorDB db.Database,
+	genesisValidatorsRoot [32]byte,
+	pubKeys [][48]byte,
+) (*Interchange, error) {
+	attestationHistory, err := validatorDB.AttestationHistoryForPubKeys(ctx, pubKeys)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read attestation history")
+	}
+	doc := &Interchange{
+		Metadata: Metadata{
+			InterchangeFormatVersion: FormatVersion,
+			GenesisValidatorsRoot:    fmt.Sprintf("%#x", genesisValidatorsRoot),
+		},
+		Data: make([]*ValidatorRecord, 0, len(pubKeys)),
+	}
+	for _, pubKey := range pubKeys {
+		signedBlocks, err := exportSignedBlocks(ctx, validatorDB, pubKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read proposal history for %#x", bytesutil.Trunc(pubKey[:]))
+		}
+		doc.Data = append(doc.Data, &ValidatorRecord{
+			Pubkey:             fmt.Sprintf("%#x", pubKey),
+			SignedBlocks:       signedBlocks,
+			SignedAttestations: exportSignedAttestations(attestationHistory[pubKey]),
+		})
+	}
+	return doc, nil
+}
+
+// Lists every slot marked as proposed in the stored proposal history, in increasing order.
+func exportSignedBlocks(ctx context.Context, validatorDB db.Database, pubKey [48]byte) ([]*SignedBlock, error) {
+	history, err := validatorDB.ProposalHistoryForPubKey(ctx, pubKey[:])
+	if err != nil {
+		return nil, err
+	}
+	epochs := make([]uint64, 0, len(history))
+	for epoch := range history {
+		epochs = append(epochs, epoch)
+	}
+	sort.Slice(epochs, func(i, j int) bool {
+		return epochs[i] < epochs[j]
+	})
+	slotsPerEpoch := params.BeaconConfig().SlotsPerEpoch
+	signedBlocks := make([]*SignedBlock, 0)
+	for _, epoch := range epochs {
+		slotBits := history[epoch]
+		for i := uint64(0); i < slotsPerEpoch && i < slotBits.Len(); i++ {
+			if slotBits.BitAt(i) {
+				signedBlocks = append(signedBlocks, &SignedBlock{
+					Slot: strconv.FormatUint(epoch*slotsPerEpoch+i, 10),
+				})
+			}
+		}
+	}
+	return signedBlocks, nil
+}
+
+// Lists the attestations recorded in the attestation history, which only covers the weak
+// subjectivity period up to its latest written target epoch, in increasing target order.
+func exportSignedAttestations(history *slashpb.AttestationHistory) []*SignedAttestation {
+	signedAttestations := make([]*SignedAttestation, 0)
+	if history == nil {
+		return signedAttestations
+	}
+	wsPeriod := params.BeaconConfig().WeakSubjectivityPeriod
+	oldestTarget := uint64(0)
+	if history.LatestEpochWritten >= wsPeriod {
+		oldestTarget = history.LatestEpochWritten - wsPeriod + 1
+	}
+	for target := oldestTarget; target <= history.LatestEpochWritten; target++ {
+		source, ok := history.TargetToSource[target%wsPeriod]
+		if !ok || source == params.BeaconConfig().FarFutureEpoch {
+			continue
+		}
+		signedAttestations = append(signedAttestations, &SignedAttestation{
+			SourceEpoch: strconv.FormatUint(source, 10),
+			TargetEpoch: strconv.FormatUint(target, 10),
+		})
+	}
+	return signedAttestations
+}
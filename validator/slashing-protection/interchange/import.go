@@ -0,0 +1,155 @@
+package interchange
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	slashpb "github.com/prysmaticlabs/prysm/proto/slashing"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/validator/db"
+)
+
+// ImportHistory merges the slashing protection history held by the document for the given
+// public keys into the validator database, and returns the public keys it found history for.
+// Records of any other key are ignored. History already in the database is never dropped, so
+// an import can only make the validator more conservative about what it signs.
+func ImportHistory(
+	ctx context.Context,
+	validatorDB db.Database,
+	doc *Interchange,
+	pubKeys [][48]byte,
+) ([][48]byte, error) {
+	wanted := make(map[[48]byte]bool, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		wanted[pubKey] = true
+	}
+	records := make(map[[48]byte][]*ValidatorRecord)
+	imported := make([][48]byte, 0)
+	for _, record := range doc.Data {
+		enc, err := decodeHex(record.Pubkey, 48)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not decode public key %s", record.Pubkey)
+		}
+		pubKey := bytesutil.ToBytes48(enc)
+		if !wanted[pubKey] {
+			continue
+		}
+		if _, ok := records[pubKey]; !ok {
+			imported = append(imported, pubKey)
+		}
+		records[pubKey] = append(records[pubKey], record)
+	}
+	if len(imported) == 0 {
+		return imported, nil
+	}
+
+	attestationHistory, err := validatorDB.AttestationHistoryForPubKeys(ctx, imported)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read attestation history")
+	}
+	for _, pubKey := range imported {
+		if err := importSignedBlocks(ctx, validatorDB, pubKey, records[pubKey]); err != nil {
+			return nil, errors.Wrapf(err, "could not import proposal history for %#x", bytesutil.Trunc(pubKey[:]))
+		}
+		history, err := mergeSignedAttestations(attestationHistory[pubKey], records[pubKey])
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not import attestation history for %#x", bytesutil.Trunc(pubKey[:]))
+		}
+		attestationHistory[pubKey] = history
+	}
+	if err := validatorDB.SaveAttestationHistoryForPubKeys(ctx, attestationHistory); err != nil {
+		return nil, errors.Wrap(err, "could not save attestation history")
+	}
+	return imported, nil
+}
+
+// Marks the slots of the signed blocks as proposed, one epoch at a time in increasing order
+// as the database prunes epochs older than the weak subjectivity period on every save.
+func importSignedBlocks(ctx context.Context, validatorDB db.Database, pubKey [48]byte, records []*ValidatorRecord) error {
+	slotsPerEpoch := params.BeaconConfig().SlotsPerEpoch
+	slotsByEpoch := make(map[uint64][]uint64)
+	for _, record := range records {
+		for _, block := range record.SignedBlocks {
+			slot, err := parseUint(block.Slot)
+			if err != nil {
+				return errors.Wrapf(err, "could not parse slot %q", block.Slot)
+			}
+			slotsByEpoch[slot/slotsPerEpoch] = append(slotsByEpoch[slot/slotsPerEpoch], slot)
+		}
+	}
+	epochs := make([]uint64, 0, len(slotsByEpoch))
+	for epoch := range slotsByEpoch {
+		epochs = append(epochs, epoch)
+	}
+	sort.Slice(epochs, func(i, j int) bool {
+		return epochs[i] < epochs[j]
+	})
+	for _, epoch := range epochs {
+		slotBits, err := validatorDB.ProposalHistoryForEpoch(ctx, pubKey[:], epoch)
+		if err != nil {
+			return err
+		}
+		for _, slot := range slotsByEpoch[epoch] {
+			slotBits.SetBitAt(slot%slotsPerEpoch, true)
+		}
+		if err := validatorDB.SaveProposalHistoryForEpoch(ctx, pubKey[:], epoch, slotBits); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Records the signed attestations in the attestation history in increasing target order,
+// keeping the source of any target epoch the history already holds.
+func mergeSignedAttestations(history *slashpb.AttestationHistory, records []*ValidatorRecord) (*slashpb.AttestationHistory, error) {
+	type sourceTarget struct {
+		source uint64
+		target uint64
+	}
+	attestations := make([]sourceTarget, 0)
+	for _, record := range records {
+		for _, att := range record.SignedAttestations {
+			source, err := parseUint(att.SourceEpoch)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not parse source epoch %q", att.SourceEpoch)
+			}
+			target, err := parseUint(att.TargetEpoch)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not parse target epoch %q", att.TargetEpoch)
+			}
+			attestations = append(attestations, sourceTarget{source: source, target: target})
+		}
+	}
+	sort.Slice(attestations, func(i, j int) bool {
+		return attestations[i].target < attestations[j].target
+	})
+
+	if history.TargetToSource == nil {
+		history.TargetToSource = make(map[uint64]uint64)
+	}
+	wsPeriod := params.BeaconConfig().WeakSubjectivityPeriod
+	farFutureEpoch := params.BeaconConfig().FarFutureEpoch
+	for _, att := range attestations {
+		if att.target <= history.LatestEpochWritten {
+			// Targets older than the weak subjectivity period can no longer be stored.
+			if history.LatestEpochWritten >= wsPeriod && att.target <= history.LatestEpochWritten-wsPeriod {
+				continue
+			}
+			if source, ok := history.TargetToSource[att.target%wsPeriod]; ok && source != farFutureEpoch {
+				continue
+			}
+			history.TargetToSource[att.target%wsPeriod] = att.source
+			continue
+		}
+		// Clear the targets skipped over, limited to one weak subjectivity period.
+		maxToWrite := history.LatestEpochWritten + wsPeriod
+		for i := history.LatestEpochWritten + 1; i < att.target && i <= maxToWrite; i++ {
+			history.TargetToSource[i%wsPeriod] = farFutureEpoch
+		}
+		history.LatestEpochWritten = att.target
+		history.TargetToSource[att.target%wsPeriod] = att.source
+	}
+	return history, nil
+}
@@ -0,0 +1,117 @@
+// Package interchange reads and writes the slashing protection history of validator keys in the
+// EIP-3076 interchange format, so keys can move between machines and clients together with it.
+package interchange
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/shared/bytesutil"
+)
+
+// FormatVersion is the version of the EIP-3076 interchange format written and read by this package.
+const FormatVersion = "5"
+
+// Interchange is an EIP-3076 slashing protection interchange document.
+type Interchange struct {
+	Metadata Metadata           `json:"metadata"`
+	Data     []*ValidatorRecord `json:"data"`
+}
+
+// Metadata identifies the interchange format version and the network the history belongs to.
+type Metadata struct {
+	InterchangeFormatVersion string `json:"interchange_format_version"`
+	GenesisValidatorsRoot    string `json:"genesis_validators_root"`
+}
+
+// ValidatorRecord holds the blocks and attestations signed by a single validator key.
+type ValidatorRecord struct {
+	Pubkey             string               `json:"pubkey"`
+	SignedBlocks       []*SignedBlock       `json:"signed_blocks"`
+	SignedAttestations []*SignedAttestation `json:"signed_attestations"`
+}
+
+// SignedBlock is a block proposal signed by a validator key.
+type SignedBlock struct {
+	Slot        string `json:"slot"`
+	SigningRoot string `json:"signing_root,omitempty"`
+}
+
+// SignedAttestation is an attestation signed by a validator key.
+type SignedAttestation struct {
+	SourceEpoch string `json:"source_epoch"`
+	TargetEpoch string `json:"target_epoch"`
+	SigningRoot string `json:"signing_root,omitempty"`
+}
+
+// ParseInterchange decodes an interchange document and checks it uses a supported format version.
+func ParseInterchange(enc []byte) (*Interchange, error) {
+	doc := &Interchange{}
+	if err := json.Unmarshal(enc, doc); err != nil {
+		return nil, errors.Wrap(err, "could not decode slashing protection interchange")
+	}
+	if doc.Metadata.InterchangeFormatVersion != FormatVersion {
+		return nil, fmt.Errorf(
+			"unsupported slashing protection interchange format version %q, expected %q",
+			doc.Metadata.InterchangeFormatVersion,
+			FormatVersion,
+		)
+	}
+	return doc, nil
+}
+
+// CheckGenesisValidatorsRoot ensures the history in the document was recorded on the network
+// with the given genesis validators root.
+func (doc *Interchange) CheckGenesisValidatorsRoot(genesisValidatorsRoot [32]byte) error {
+	root, err := decodeHex(doc.Metadata.GenesisValidatorsRoot, 32)
+	if err != nil {
+		return errors.Wrap(err, "could not decode genesis validators root")
+	}
+	if !bytes.Equal(root, genesisValidatorsRoot[:]) {
+		return fmt.Errorf(
+			"slashing protection history is for genesis validators root %#x, expected %#x",
+			root,
+			genesisValidatorsRoot,
+		)
+	}
+	return nil
+}
+
+// PublicKeys returns the distinct validator public keys the document holds history for.
+func (doc *Interchange) PublicKeys() ([][48]byte, error) {
+	seen := make(map[[48]byte]bool, len(doc.Data))
+	pubKeys := make([][48]byte, 0, len(doc.Data))
+	for _, record := range doc.Data {
+		enc, err := decodeHex(record.Pubkey, 48)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not decode public key %s", record.Pubkey)
+		}
+		pubKey := bytesutil.ToBytes48(enc)
+		if seen[pubKey] {
+			continue
+		}
+		seen[pubKey] = true
+		pubKeys = append(pubKeys, pubKey)
+	}
+	return pubKeys, nil
+}
+
+func decodeHex(s string, length int) ([]byte, error) {
+	enc, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	if len(enc) != length {
+		return nil, fmt.Errorf("expected %d bytes, received %d", length, len(enc))
+	}
+	return enc, nil
+}
+
+func parseUint(s string) (uint64, error) {
+	return strconv.ParseUint(s, 10, 64)
+}
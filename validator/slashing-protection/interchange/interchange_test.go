@@ -0,0 +1,132 @@
+package interchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"testing"
+
+	slashpb "github.com/prysmaticlabs/prysm/proto/slashing"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/prysmaticlabs/prysm/shared/testutil/assert"
+	"github.com/prysmaticlabs/prysm/shared/testutil/require"
+	dbTest "github.com/prysmaticlabs/prysm/validator/db/testing"
+)
+
+func TestExportImportHistory_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	pubKeys := [][48]byte{{1}, {2}}
+	genesisValidatorsRoot := [32]byte{'g', 'e', 'n', 'e', 's', 'i', 's'}
+	slotsPerEpoch := params.BeaconConfig().SlotsPerEpoch
+	sourceDB := dbTest.SetupDB(t, pubKeys)
+
+	// The first key proposed twice and attested to three targets, the second key never signed anything.
+	proposedSlots := []uint64{5, 3*slotsPerEpoch + 1}
+	for _, slot := range proposedSlots {
+		slotBits, err := sourceDB.ProposalHistoryForEpoch(ctx, pubKeys[0][:], slot/slotsPerEpoch)
+		require.NoError(t, err)
+		slotBits.SetBitAt(slot%slotsPerEpoch, true)
+		require.NoError(t, sourceDB.SaveProposalHistoryForEpoch(ctx, pubKeys[0][:], slot/slotsPerEpoch, slotBits))
+	}
+	history := &slashpb.AttestationHistory{TargetToSource: map[uint64]uint64{}}
+	history = mergeAttestation(t, history, 0, 1)
+	history = mergeAttestation(t, history, 1, 2)
+	history = mergeAttestation(t, history, 2, 4)
+	require.NoError(t, sourceDB.SaveAttestationHistoryForPubKeys(ctx, map[[48]byte]*slashpb.AttestationHistory{
+		pubKeys[0]: history,
+	}))
+
+	exported, err := ExportHistory(ctx, sourceDB, genesisValidatorsRoot, pubKeys)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(exported.Data))
+	assert.DeepEqual(t, []*SignedBlock{{Slot: "5"}, {Slot: "97"}}, exported.Data[0].SignedBlocks)
+	assert.DeepEqual(t, []*SignedAttestation{
+		{SourceEpoch: "0", TargetEpoch: "1"},
+		{SourceEpoch: "1", TargetEpoch: "2"},
+		{SourceEpoch: "2", TargetEpoch: "4"},
+	}, exported.Data[0].SignedAttestations)
+	assert.Equal(t, 0, len(exported.Data[1].SignedBlocks))
+	assert.Equal(t, 0, len(exported.Data[1].SignedAttestations))
+
+	enc, err := json.Marshal(exported)
+	require.NoError(t, err)
+	parsed, err := ParseInterchange(enc)
+	require.NoError(t, err)
+	require.NoError(t, parsed.CheckGenesisValidatorsRoot(genesisValidatorsRoot))
+	parsedPubKeys, err := parsed.PublicKeys()
+	require.NoError(t, err)
+	assert.DeepEqual(t, pubKeys, parsedPubKeys)
+
+	targetDB := dbTest.SetupDB(t, pubKeys)
+	imported, err := ImportHistory(ctx, targetDB, parsed, pubKeys)
+	require.NoError(t, err)
+	assert.DeepEqual(t, pubKeys, imported)
+	reexported, err := ExportHistory(ctx, targetDB, genesisValidatorsRoot, pubKeys)
+	require.NoError(t, err)
+	assert.DeepEqual(t, exported, reexported)
+}
+
+func TestImportHistory_KeepsExistingHistory(t *testing.T) {
+	ctx := context.Background()
+	pubKey := [48]byte{1}
+	otherPubKey := [48]byte{2}
+	validatorDB := dbTest.SetupDB(t, [][48]byte{pubKey})
+	history := &slashpb.AttestationHistory{TargetToSource: map[uint64]uint64{}}
+	history = mergeAttestation(t, history, 3, 5)
+	require.NoError(t, validatorDB.SaveAttestationHistoryForPubKeys(ctx, map[[48]byte]*slashpb.AttestationHistory{
+		pubKey: history,
+	}))
+
+	doc := &Interchange{
+		Metadata: Metadata{InterchangeFormatVersion: FormatVersion},
+		Data: []*ValidatorRecord{
+			{
+				Pubkey: fmt.Sprintf("%#x", pubKey),
+				SignedAttestations: []*SignedAttestation{
+					{SourceEpoch: "2", TargetEpoch: "3"},
+					{SourceEpoch: "4", TargetEpoch: "5"},
+				},
+			},
+			{
+				Pubkey:       fmt.Sprintf("%#x", otherPubKey),
+				SignedBlocks: []*SignedBlock{{Slot: "1"}},
+			},
+		},
+	}
+	imported, err := ImportHistory(ctx, validatorDB, doc, [][48]byte{pubKey})
+	require.NoError(t, err)
+	assert.DeepEqual(t, [][48]byte{pubKey}, imported)
+
+	histories, err := validatorDB.AttestationHistoryForPubKeys(ctx, [][48]byte{pubKey})
+	require.NoError(t, err)
+	wsPeriod := params.BeaconConfig().WeakSubjectivityPeriod
+	assert.Equal(t, uint64(5), histories[pubKey].LatestEpochWritten)
+	assert.Equal(t, uint64(2), histories[pubKey].TargetToSource[3%wsPeriod])
+	assert.Equal(t, uint64(3), histories[pubKey].TargetToSource[5%wsPeriod], "Expected the existing source to be kept")
+}
+
+func TestParseInterchange_UnsupportedVersion(t *testing.T) {
+	_, err := ParseInterchange([]byte(`{"metadata":{"interchange_format_version":"4"},"data":[]}`))
+	assert.ErrorContains(t, "unsupported slashing protection interchange format version", err)
+}
+
+func TestCheckGenesisValidatorsRoot_Mismatch(t *testing.T) {
+	doc := &Interchange{Metadata: Metadata{
+		InterchangeFormatVersion: FormatVersion,
+		GenesisValidatorsRoot:    "0x0400000000000000000000000000000000000000000000000000000000000000",
+	}}
+	require.NoError(t, doc.CheckGenesisValidatorsRoot([32]byte{4}))
+	assert.ErrorContains(t, "slashing protection history is for genesis validators root", doc.CheckGenesisValidatorsRoot([32]byte{5}))
+}
+
+func mergeAttestation(t *testing.T, history *slashpb.AttestationHistory, source, target uint64) *slashpb.AttestationHistory {
+	history, err := mergeSignedAttestations(history, []*ValidatorRecord{
+		{SignedAttestations: []*SignedAttestation{{
+			SourceEpoch: strconv.FormatUint(source, 10),
+			TargetEpoch: strconv.FormatUint(target, 10),
+		}}},
+	})
+	require.NoError(t, err)
+	return history
+}
@@ -98,6 +98,22 @@ var appHelpFlagGroups = []flagGroup{
 			flags.WalletDirFlag,
 			flags.WalletPasswordsDirFlag,
 			flags.WalletPasswordFileFlag,
+			flags.PasswordStdinFlag,
+			flags.AdditionalWalletDirsFlag,
+			flags.WatchAccountsFlag,
+			flags.KeymanagerIdleTimeoutFlag,
+			flags.AllowUnlockedKeyMemoryFlag,
+			flags.WalletSecretsDirFlag,
+			flags.WalletSecretsFromEnvFlag,
+			flags.WalletSecretsKubernetesSelectorFlag,
+			flags.WalletSecretsKubernetesNamespaceFlag,
+			flags.WalletBundleFlag,
+			flags.WalletBundleExtractDirFlag,
+			flags.WalletStorageRetriesFlag,
+			flags.WalletStorageRetryDelayFlag,
+			flags.WalletReadOnlyFlag,
+			flags.WalletFixPermissionsFlag,
+			flags.WalletManifestKeyFileFlag,
 		},
 	},
 	{